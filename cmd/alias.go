@@ -10,8 +10,8 @@ import (
 	"strings"
 	"text/tabwriter"
 
-	"github.com/grovetools/core/pkg/alias"
 	"github.com/grovetools/core/pkg/workspace"
+	"github.com/grovetools/cx/pkg/context"
 	"github.com/spf13/cobra"
 )
 
@@ -52,11 +52,9 @@ func newAliasListCmd() *cobra.Command {
 			}
 			workDir, _ = filepath.Abs(workDir)
 
-			resolver := alias.NewAliasResolver()
-			resolver.InitProvider()
-			if resolver.Provider == nil {
-				return fmt.Errorf("failed to initialize workspace provider")
-			}
+			// Without discovery only the current workspace is listed; the
+			// resolver has already warned about it.
+			resolver, _ := context.NewAliasResolver(workDir)
 
 			currentNode, _ := workspace.GetProjectByPath(workDir)
 
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewAnnotatePRCmd creates the annotate-pr command.
+func NewAnnotatePRCmd() *cobra.Command {
+	var jobFile, rulesFile, pr string
+	var maxFiles, excerpts, excerptLines int
+	var post bool
+
+	cmd := &cobra.Command{
+		Use:   "annotate-pr",
+		Short: "Summarize the context as a markdown pull request comment",
+		Long: `Prints a markdown summary of the current context for posting on a pull
+request, so reviewers can see exactly what an AI-assisted change was generated
+from. The summary covers provenance (rules file, commit, when the artifact was
+generated and whether its checksum still matches), token usage against
+cx.token_budget, and each file with the rules line that included it. The rules
+and the opening lines of the largest files follow in collapsed sections.
+
+--post hands the summary to 'gh pr comment' for the pull request of the
+current branch, or the one named with --pr.`,
+		Example: `  cx annotate-pr > context.md
+  cx annotate-pr --post
+  cx annotate-pr --post --pr 128 --excerpts 0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(cmd.Context())
+
+			targetRulesFile, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
+			if err != nil {
+				return err
+			}
+			var hot, cold []string
+			var rulesContent []byte
+			rulesPath := targetRulesFile
+			if targetRulesFile != "" {
+				hot, cold, err = mgr.ResolveFilesFromCustomRulesFile(targetRulesFile)
+				if err != nil {
+					return fmt.Errorf("failed to resolve files from rules file: %w", err)
+				}
+				rulesContent, _ = os.ReadFile(targetRulesFile)
+			} else {
+				session, err := mgr.NewResolutionSession()
+				if err != nil {
+					return err
+				}
+				hot, cold, rulesContent, rulesPath = session.Hot, session.Cold, session.RulesContent, session.RulesPath
+			}
+
+			summary := mgr.BuildPRSummary(rulesPath, rulesContent, hot, cold, context.PRSummaryOptions{
+				Excerpts:     excerpts,
+				ExcerptLines: excerptLines,
+			})
+			if node, wsErr := workspace.GetProjectByPath(mgr.GetWorkDir()); wsErr == nil && node.Kind != workspace.KindNonGroveRepo {
+				summary.Workspace = node.Identifier(":")
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, summary)
+			}
+			body := summary.Markdown(maxFiles)
+			if !post {
+				fmt.Fprint(cmd.OutOrStdout(), body)
+				return nil
+			}
+
+			ghArgs := []string{"pr", "comment"}
+			if pr != "" {
+				ghArgs = append(ghArgs, pr)
+			}
+			ghArgs = append(ghArgs, "--body-file", "-")
+			gh := exec.Command("gh", ghArgs...) //nolint:gosec // args are fixed apart from the PR reference
+			gh.Dir = mgr.GetWorkDir()
+			gh.Stdin = strings.NewReader(body)
+			gh.Stdout = cmd.OutOrStdout()
+			gh.Stderr = os.Stderr
+			if err := gh.Run(); err != nil {
+				return fmt.Errorf("gh pr comment failed: %w", err)
+			}
+			return nil
+		},
+	}
+
+	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().IntVar(&maxFiles, "max-files", 50, "Files listed in the table (0 for all); the rest are summarized")
+	cmd.Flags().IntVar(&excerpts, "excerpts", 3, "Largest hot files to quote the opening lines of")
+	cmd.Flags().IntVar(&excerptLines, "excerpt-lines", 15, "Lines quoted from each excerpted file")
+	cmd.Flags().BoolVar(&post, "post", false, "Post the summary as a pull request comment with gh")
+	cmd.Flags().StringVar(&pr, "pr", "", "Pull request number, URL or branch for --post (default: the current branch's)")
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/grovetools/core/cli"
+	"github.com/grovetools/core/version"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// benchOutput is the JSON form of cx bench: the report plus the build it
+// was measured with, so results from two cx versions can be compared.
+type benchOutput struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	*context.BenchReport
+}
+
+// NewBenchCmd creates the bench command.
+func NewBenchCmd() *cobra.Command {
+	var runs int
+	var cold bool
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Time rules resolution and context generation for this project",
+		Long: `Runs the work behind cx generate against the current project several times
+and reports min/median/p90/max/mean timings for each phase:
+
+  expand    load and expand the rules file (imports, aliases, directives)
+  resolve   resolve hot and cold rules to files
+  classify  classify the walked tree, as cx view and cx list do
+  generate  write hot and cold context
+
+along with the hit rates of the in-process caches (rules expansion memo,
+walk index, per-pattern matches, gitignore sets). Generated output goes to a
+scratch directory; the project's context files are not touched.
+
+By default the runs share one manager, as cx watch and cx serve do, so the
+first run is cold and later runs show warm-cache performance. --cold starts
+every run from a fresh manager. Use --json to save results and compare cx
+versions.`,
+		Example: `  cx bench
+  cx bench --runs 20 --cold
+  cx bench --json > bench-$(cx version --json | jq -r .version).json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := context.Bench(GetWorkDir(), context.BenchOptions{Runs: runs, Cold: cold})
+			if err != nil {
+				return err
+			}
+			info := version.GetInfo()
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, benchOutput{Version: info.Version, Commit: info.Commit, BenchReport: report})
+			}
+
+			out := cmd.OutOrStdout()
+			mode := "warm (shared manager)"
+			if report.Cold {
+				mode = "cold (fresh manager per run)"
+			}
+			fmt.Fprintf(out, "cx %s — %d runs, %s\n", info.Version, report.Runs, mode)
+			if report.RulesPath == "" {
+				fmt.Fprintln(out, "Rules: none (no active rules file)")
+			} else {
+				fmt.Fprintf(out, "Rules: %s (%d hot, %d cold files)\n", report.RulesPath, report.HotFiles, report.ColdFiles)
+			}
+
+			fmt.Fprintln(out)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(w, "phase\tmin ms\tmedian ms\tp90 ms\tmax ms\tmean ms\t")
+			for _, p := range report.Phases {
+				fmt.Fprintf(w, "%s\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n", p.Name, p.MinMs, p.MedianMs, p.P90Ms, p.MaxMs, p.MeanMs)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(out)
+			w = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(w, "cache\thits\tmisses\thit rate\t")
+			for _, c := range report.Caches {
+				rate := "-"
+				if c.Hits+c.Misses > 0 {
+					rate = fmt.Sprintf("%.0f%%", 100*c.HitRate)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t\n", c.Name, c.Hits, c.Misses, rate)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVarP(&runs, "runs", "n", 10, "Number of runs")
+	cmd.Flags().BoolVar(&cold, "cold", false, "Start every run from a fresh manager (no in-process caches)")
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func NewCleanCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove regenerable context artifacts",
+		Long: `Reports the size of each class of generated artifact (hot context, cached
+context, file lists, diffs, metrics, snapshots, shared git rule clones) and
+removes the regenerable ones.
+
+Cached context is kept while the rules file carries @freeze-cache. Snapshots
+saved under a name with 'cx stats --save-snapshot' are pinned; only scratch
+snapshots are removed. Of the shared clones, only those this workspace
+checked out are considered: one is removed once this workspace has not used it
+for 30 days and no other workspace references it. 'cx repo gc' collects the
+shared cache as a whole. Rules files and the usage metrics log are never
+removed.
+
+Examples:
+  cx clean --dry-run   # Report what would be removed
+  cx clean             # Remove regenerable artifacts`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+
+			artifacts, err := mgr.ScanArtifacts()
+			if err != nil {
+				return err
+			}
+
+			removed, freed := 0, int64(0)
+			if !dryRun {
+				removed, freed, err = mgr.Clean(artifacts)
+				if err != nil {
+					return err
+				}
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, struct {
+					DryRun    bool                        `json:"dry_run"`
+					Artifacts []context.CleanArtifact     `json:"artifacts"`
+					Classes   []context.ArtifactClassSize `json:"classes"`
+					Removed   int                         `json:"removed"`
+					Freed     int64                       `json:"freed"`
+				}{dryRun, artifacts, context.SummarizeArtifacts(artifacts), removed, freed})
+			}
+
+			out := cmd.OutOrStdout()
+			if len(artifacts) == 0 {
+				fmt.Fprintln(out, "No generated artifacts found.")
+				return nil
+			}
+
+			fmt.Fprintf(out, "%-16s  %5s  %10s  %10s\n", "CLASS", "COUNT", "SIZE", "REMOVABLE")
+			for _, s := range context.SummarizeArtifacts(artifacts) {
+				fmt.Fprintf(out, "%-16s  %5d  %10s  %10s\n", s.Class, s.Count,
+					context.FormatBytes(int(s.Size)), context.FormatBytes(int(s.Removable)))
+			}
+			fmt.Fprintln(out)
+
+			for _, a := range artifacts {
+				action := "remove"
+				switch {
+				case !a.Regenerable:
+					action = "keep"
+				case a.Protected != "":
+					action = "keep (" + a.Protected + ")"
+				case dryRun:
+					action = "would remove"
+				}
+				fmt.Fprintf(out, "  %-20s %s\n", action, a.Path)
+			}
+
+			if !dryRun {
+				fmt.Fprintf(out, "\nRemoved %d artifact(s), freed %s\n", removed, context.FormatBytes(int(freed)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report artifacts and sizes without removing anything")
+
+	return cmd
+}
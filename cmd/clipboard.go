@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands returns the copy and paste commands for the platform's
+// clipboard: pbcopy/pbpaste on macOS, wl-clipboard under Wayland, else
+// xclip or xsel, and clip/PowerShell on Windows.
+func clipboardCommands() (copyCmd, pasteCmd []string, err error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"pbcopy"}, []string{"pbpaste"}, nil
+	case "windows":
+		return []string{"cmd", "/c", "clip"}, []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}, nil
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if _, err := exec.LookPath("wl-copy"); err == nil {
+			return []string{"wl-copy"}, []string{"wl-paste", "--no-newline"}, nil
+		}
+	}
+	if _, err := exec.LookPath("xclip"); err == nil {
+		return []string{"xclip", "-selection", "clipboard"}, []string{"xclip", "-selection", "clipboard", "-o"}, nil
+	}
+	if _, err := exec.LookPath("xsel"); err == nil {
+		return []string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--output"}, nil
+	}
+	return nil, nil, fmt.Errorf("no clipboard utility found (install wl-clipboard, xclip or xsel)")
+}
+
+// readClipboard returns the clipboard's text.
+func readClipboard() (string, error) {
+	_, paste, err := clipboardCommands()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(paste[0], paste[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("reading clipboard: %w", err)
+	}
+	return string(out), nil
+}
+
+// writeClipboard replaces the clipboard's contents with text.
+func writeClipboard(text string) error {
+	copyCmd, _, err := clipboardCommands()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(copyCmd[0], copyCmd[1:]...)
+	c.Stdin = strings.NewReader(text)
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("writing clipboard: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
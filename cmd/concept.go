@@ -216,7 +216,7 @@ func detectDeadAliases(pm *context.Manager, presetContent []byte) []conceptFindi
 	for _, raw := range strings.Split(string(presetContent), "\n") {
 		lineNum++
 		trimmed := strings.TrimSpace(raw)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+		if _, sep := context.ParseSectionSeparator(trimmed); trimmed == "" || strings.HasPrefix(trimmed, "#") || sep {
 			continue
 		}
 		if strings.HasPrefix(trimmed, "!") {
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration cx runs with",
+	}
+
+	cmd.AddCommand(newConfigShowCmd())
+
+	return cmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	var effective bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the config files in effect, or with --effective every merged setting",
+		Long: `Lists the grove config files that are merged for the current directory, lowest
+precedence first: global, global override, GROVE_CONFIG_OVERLAY, ecosystem,
+project, then project override files.
+
+With --effective, prints every setting that shapes context resolution with its
+final value and the source it came from: a config layer, an environment
+override (CX_METRICS, CX_ACCESS_TRACKING), grove state (the active rule set) or
+"derived" for values computed from the rest, such as the allowed workspace
+roots. Settings no source sets are shown with their default.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			ec, err := mgr.EffectiveConfig()
+			if err != nil {
+				return err
+			}
+			if !effective {
+				ec.Settings = nil
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, ec)
+			}
+			printEffectiveConfig(cmd, ec)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&effective, "effective", false, "Print every merged setting with its source")
+
+	return cmd
+}
+
+func printEffectiveConfig(cmd *cobra.Command, ec *context.EffectiveConfig) {
+	out := cmd.OutOrStdout()
+	if len(ec.Layers) == 0 {
+		fmt.Fprintln(out, "No config files found; defaults apply.")
+	} else {
+		fmt.Fprintln(out, "Config files (lowest precedence first):")
+		for _, l := range ec.Layers {
+			fmt.Fprintf(out, "  %-16s %s\n", l.Source, l.Path)
+		}
+	}
+	if len(ec.Settings) == 0 {
+		return
+	}
+
+	keyWidth := 0
+	for _, s := range ec.Settings {
+		if w := lipgloss.Width(s.Key); w > keyWidth {
+			keyWidth = w
+		}
+	}
+	fmt.Fprintln(out)
+	for _, s := range ec.Settings {
+		value, _ := json.Marshal(s.Value)
+		source := s.Source
+		if s.Origin != "" {
+			source += " (" + s.Origin + ")"
+		}
+		fmt.Fprintf(out, "%s  %s  [%s]\n", context.PadWidth(s.Key, keyWidth), value, source)
+	}
+}
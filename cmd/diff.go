@@ -3,20 +3,35 @@ package cmd
 import (
 	stdctx "context"
 	"fmt"
+	"io"
 	"sort"
 
+	"github.com/grovetools/core/logging"
 	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/pkg/context"
 )
 
 func NewDiffCmd() *cobra.Command {
+	var filesOnly, exitCode bool
+
 	cmd := &cobra.Command{
 		Use:   "diff [ruleset-name]",
 		Short: "Compare the current context with a named rule set",
-		Long:  `Compare the current context with a named rule set from .cx/ or .cx.work/ to see added/removed files, token count changes, and size differences.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Compare the current context with a named rule set from .cx/ or .cx.work/ to see added/removed files, token count changes, and size differences.
+
+For scripts and git hooks, --files-only prints only the added and removed
+paths, one per line as "+ path" or "- path", and --exit-code exits with 1 when
+the contexts differ, 0 when they are identical and 2 on errors, like
+'git diff --exit-code'.`,
+		Example: `  cx diff dev
+  cx diff --files-only --exit-code dev || echo "context drifted"`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if filesOnly {
+				// Keep stdout for the paths alone.
+				logging.SetGlobalOutput(cmd.ErrOrStderr())
+			}
 			mgr := context.NewManager(GetWorkDir())
 
 			compareName := "empty"
@@ -26,17 +41,49 @@ func NewDiffCmd() *cobra.Command {
 
 			diff, err := mgr.DiffContext(compareName)
 			if err != nil {
+				if exitCode {
+					// Keep 1 for "differs", like git.
+					return &ExitError{Code: 2, Err: err}
+				}
 				return err
 			}
 
-			printDiff(diff, compareName)
+			if filesOnly {
+				printDiffFiles(cmd.OutOrStdout(), diff)
+			} else {
+				printDiff(diff, compareName)
+			}
+			if exitCode && len(diff.Added)+len(diff.Removed) > 0 {
+				return &ExitError{Code: 1}
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&filesOnly, "files-only", false, "Print only the added and removed paths (\"+ path\", \"- path\")")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with 1 when the contexts differ, 0 when identical")
+
 	return cmd
 }
 
+// printDiffFiles writes the added and removed paths of d, sorted, one per
+// line with no other output.
+func printDiffFiles(w io.Writer, d *context.DiffResult) {
+	for _, group := range []struct {
+		sign  string
+		files []context.FileInfo
+	}{{"+", d.Added}, {"-", d.Removed}} {
+		paths := make([]string, 0, len(group.files))
+		for _, f := range group.files {
+			paths = append(paths, f.Path)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			fmt.Fprintf(w, "%s %s\n", group.sign, p)
+		}
+	}
+}
+
 // printDiff displays the diff result using the pretty logger
 func printDiff(d *context.DiffResult, compareName string) {
 	ctx := stdctx.Background()
@@ -56,7 +103,7 @@ func printDiff(d *context.DiffResult, compareName string) {
 			return d.Added[i].Tokens > d.Added[j].Tokens
 		})
 		for _, f := range d.Added {
-			line := fmt.Sprintf("%-50s (%s tokens)", context.TruncatePath(f.Path, 50), context.FormatTokenCount(f.Tokens))
+			line := fmt.Sprintf("%s (%s tokens)", context.PadWidth(context.TruncatePath(f.Path, 50), 50), context.FormatTokenCount(f.Tokens))
 			ulog.Success("Added file").
 				Field("path", f.Path).
 				Field("tokens", f.Tokens).
@@ -75,7 +122,7 @@ func printDiff(d *context.DiffResult, compareName string) {
 			return d.Removed[i].Tokens > d.Removed[j].Tokens
 		})
 		for _, f := range d.Removed {
-			line := fmt.Sprintf("%-50s (%s tokens)", context.TruncatePath(f.Path, 50), context.FormatTokenCount(f.Tokens))
+			line := fmt.Sprintf("%s (%s tokens)", context.PadWidth(context.TruncatePath(f.Path, 50), 50), context.FormatTokenCount(f.Tokens))
 			ulog.Error("Removed file").
 				Field("path", f.Path).
 				Field("tokens", f.Tokens).
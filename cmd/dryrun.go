@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// dryRunFlagUsage is the --dry-run help shared by the mutating commands.
+const dryRunFlagUsage = "Show the files and state that would change, with diffs for rules files, without writing anything"
+
+// printPlannedChanges reports the changes a --dry-run command would have
+// made, as JSON with --json.
+func printPlannedChanges(cmd *cobra.Command, changes []context.PlannedChange) error {
+	if cli.GetOptions(cmd).JSONOutput {
+		if changes == nil {
+			changes = []context.PlannedChange{}
+		}
+		return writeJSON(cmd, map[string]interface{}{
+			"dry_run": true,
+			"changes": changes,
+		})
+	}
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "Nothing would change (dry run).")
+		return nil
+	}
+	for _, c := range changes {
+		path := c.Path
+		if c.Action != context.ActionState {
+			path = displayWorkPath(path)
+		}
+		line := fmt.Sprintf("%-9s %s", c.Action, path)
+		if c.Detail != "" {
+			line += " (" + c.Detail + ")"
+		}
+		fmt.Fprintln(out, line)
+		if c.Diff != "" {
+			for _, l := range strings.Split(strings.TrimSuffix(c.Diff, "\n"), "\n") {
+				fmt.Fprintf(out, "    %s\n", l)
+			}
+		}
+	}
+	fmt.Fprintln(out, "Dry run: nothing was written.")
+	return nil
+}
+
+// displayWorkPath shows path relative to the working directory when it is
+// inside it.
+func displayWorkPath(path string) string {
+	if rel, err := filepath.Rel(GetWorkDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
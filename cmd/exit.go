@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExitError ends a command with a specific exit status. With a nil Err the
+// command already reported everything it had to say, so nothing is printed;
+// `cx diff --exit-code` uses that to exit 1 when the contexts differ.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit status for a command's error: the code of an
+// ExitError, and 1 for any other error.
+func ExitCode(err error) int {
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+	return 1
+}
+
+// IsSilent reports whether err ends the command without an error message.
+func IsSilent(err error) bool {
+	var exit *ExitError
+	return errors.As(err, &exit) && exit.Err == nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		code   int
+		silent bool
+	}{
+		{errors.New("boom"), 1, false},
+		{&ExitError{Code: 1}, 1, true},
+		{&ExitError{Code: 2, Err: errors.New("unknown rule set")}, 2, false},
+		{fmt.Errorf("wrapped: %w", &ExitError{Code: 1}), 1, true},
+	} {
+		if got := ExitCode(tc.err); got != tc.code {
+			t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.code)
+		}
+		if got := IsSilent(tc.err); got != tc.silent {
+			t.Errorf("IsSilent(%v) = %v, want %v", tc.err, got, tc.silent)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func newRulesFreezeExpectationsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "freeze-expectations [ruleset...]",
+		Short: "Record each rule set's resolved files as committed fixtures",
+		Long: `Resolves each named rule set and writes the sorted hot and cold file lists to
+.cx/expectations/<name>.json. Commit these files and run
+'cx rules check-expectations' in CI to catch unexpected resolution changes
+after cx upgrades or rule refactors.
+
+With no arguments, every named rule set is frozen, along with the active
+rules under the name "` + context.ActiveExpectation + `".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(cmd.Context())
+
+			written, err := mgr.FreezeExpectations(args)
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, written)
+			}
+			for _, path := range written {
+				if rel, err := filepath.Rel(mgr.GetWorkDir(), path); err == nil {
+					path = rel
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Froze %s\n", path)
+			}
+			return nil
+		},
+	}
+}
+
+func newRulesCheckExpectationsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-expectations [ruleset...]",
+		Short: "Fail when rule sets no longer resolve to their recorded fixtures",
+		Long: `Re-resolves each rule set recorded by 'cx rules freeze-expectations' and
+compares it with its fixture, listing files added to or removed from the hot
+and cold sections. Exits non-zero when anything drifted.
+
+With no arguments, every recorded fixture is checked.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(cmd.Context())
+
+			drifts, err := mgr.CheckExpectations(args)
+			if err != nil {
+				return err
+			}
+			drifted := 0
+			for _, d := range drifts {
+				if d.Drifted() {
+					drifted++
+				}
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				if err := writeJSON(cmd, drifts); err != nil {
+					return err
+				}
+			} else {
+				out := cmd.OutOrStdout()
+				for _, d := range drifts {
+					switch {
+					case d.Missing:
+						fmt.Fprintf(out, "✗ %s: no expectation recorded\n", d.Ruleset)
+					case !d.Drifted():
+						fmt.Fprintf(out, "✓ %s\n", d.Ruleset)
+					default:
+						fmt.Fprintf(out, "✗ %s\n", d.Ruleset)
+						printExpectationFiles(cmd, "+ hot ", d.AddedHot)
+						printExpectationFiles(cmd, "- hot ", d.RemovedHot)
+						printExpectationFiles(cmd, "+ cold", d.AddedCold)
+						printExpectationFiles(cmd, "- cold", d.RemovedCold)
+					}
+				}
+			}
+
+			if drifted > 0 {
+				return fmt.Errorf("%d of %d rule sets drifted from their expectations; re-run 'cx rules freeze-expectations' if the change is intended", drifted, len(drifts))
+			}
+			return nil
+		},
+	}
+}
+
+func printExpectationFiles(cmd *cobra.Command, label string, files []string) {
+	for _, f := range files {
+		fmt.Fprintf(cmd.OutOrStdout(), "    %s %s\n", label, f)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewForTestCmd creates the for-test command.
+func NewForTestCmd() *cobra.Command {
+	var pkgDir, output string
+	var apply, force bool
+
+	cmd := &cobra.Command{
+		Use:   "for-test <TestName[/subtest]>",
+		Short: "Build a minimal rules file from the files a Go test executes",
+		Long: `Runs one Go test with coverage across its module and writes a rules file
+listing the test's file and every source file the run executed, plus an
+@fixtures: listing of the package's testdata directory if it has one. A
+failing test is traced like a passing one.
+
+The rules are printed to stdout unless --output or --apply is given; --apply
+replaces the active rules file (use --force if it already exists).`,
+		Example: `  cx for-test TestParseRules
+  cx for-test TestResolve/nested_excludes --pkg pkg/context --apply --force
+  cx for-test TestWatch -o .cx/watch-debug.rules`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apply && output != "" {
+				return fmt.Errorf("--apply and --output are mutually exclusive")
+			}
+			mgr := context.NewManager(GetWorkDir())
+			tc, err := mgr.TraceGoTest(args[0], pkgDir)
+			if err != nil {
+				return err
+			}
+
+			target := output
+			if apply {
+				target = mgr.ResolveRulesWritePath()
+			}
+			if target != "" {
+				if _, err := os.Stat(target); err == nil && apply && !force {
+					return fmt.Errorf("rules file %s already exists; use --force to replace it", target)
+				}
+				if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(target, []byte(tc.Rules()), 0o644); err != nil {
+					return fmt.Errorf("failed to write rules: %w", err)
+				}
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, tc)
+			}
+			if target == "" {
+				fmt.Fprint(cmd.OutOrStdout(), tc.Rules())
+				return nil
+			}
+			status := "passed"
+			if !tc.Passed {
+				status = "failed"
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s %s; wrote %d files to %s\n", tc.Test, status, len(tc.Files), target)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pkgDir, "pkg", "", "Directory to look for the test in (default: the working directory, recursively)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the rules to this file instead of stdout")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Write the rules to the active rules file")
+	cmd.Flags().BoolVar(&force, "force", false, "With --apply, replace an existing rules file")
+
+	return cmd
+}
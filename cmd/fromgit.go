@@ -21,7 +21,9 @@ func NewFromGitCmd() *cobra.Command {
 		Long: `Generate context from files in git history based on various criteria like commits, branches, or dates.
 
 If a rules file already exists and neither --append nor --force is specified,
-you will be prompted to overwrite, append, or cancel.
+you will be prompted to overwrite, append, or cancel. With --dry-run, the
+rules file change is shown as a diff (overwriting unless --append) and
+nothing is written.
 
 For dynamic git-aware rules that re-evaluate each time, use directives in your rules file instead:
   @changed: HEAD       — files with uncommitted changes
@@ -59,6 +61,20 @@ For dynamic git-aware rules that re-evaluate each time, use directives in your r
 				return fmt.Errorf("specify at least one option: --since, --branch, --staged, or --commits")
 			}
 
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				change, err := mgr.PlanFromGit(context.GitOptions{
+					Since:   since,
+					Branch:  branch,
+					Staged:  staged,
+					Commits: commits,
+					Append:  appendRules,
+				})
+				if err != nil {
+					return err
+				}
+				return printPlannedChanges(cmd, []context.PlannedChange{change})
+			}
+
 			fromGitLog.Info("Updating context from git history")
 			fromGitPrettyLog.InfoPretty("Updating context from git history...")
 
@@ -97,6 +113,7 @@ For dynamic git-aware rules that re-evaluate each time, use directives in your r
 	cmd.Flags().Int("commits", 0, "Include files from last N commits")
 	cmd.Flags().BoolP("append", "a", false, "Append to existing rules instead of overwriting")
 	cmd.Flags().BoolP("force", "f", false, "Force overwrite of existing rules without prompting")
+	cmd.Flags().Bool("dry-run", false, dryRunFlagUsage)
 
 	return cmd
 }
@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/grovetools/core/cli"
+	"github.com/grovetools/core/logging"
 	"github.com/grovetools/core/pkg/workspace"
 	"github.com/spf13/cobra"
 
@@ -13,23 +20,121 @@ var useXMLFormat bool = true
 
 func NewGenerateCmd() *cobra.Command {
 	var jobFile, rulesFile string
-	var stripComments bool
+	var stripComments, checksums, strict, dryRun, provenance bool
+	var onlyPatterns []string
+	var onlyTier string
+	var models []string
+	var allModels bool
+	var maxTokens string
+	var toStdout, toClipboard bool
+	var format string
 
 	cmd := &cobra.Command{
 		Use:   "generate",
 		Short: "Generate the context file from the active rules",
-		Long:  `Resolves the active rules file (run 'cx rules where' to see which one) and generates a concatenated context file with all matched files.`,
+		Long: `Resolves the active rules file (run 'cx rules where' to see which one) and generates a concatenated context file with all matched files.
+
+With --only, rules are still resolved in full but only files matching the
+given patterns are re-read; every other file is spliced from the existing
+artifact. With --only-tier, only the hot or the cold artifact is rewritten.
+
+With --model name=tokens (repeatable) or --all-models (every budget in
+cx.model_budgets), a hot context variant is also written per model, dropping
+the lowest-priority files until it fits; the omitted files are reported and
+saved alongside the variants.
+
+With --max-tokens (or an @budget: directive in the rules), the generated hot
+and cold files are trimmed to fit the token budget: cold files are dropped
+before hot ones, lowest-priority files first, and @require: files never.
+What was dropped is reported.
+
+With --strict, generation fails before writing anything when an @cmd: rule
+fails, instead of warning and generating without the command's files.
+
+With --dry-run, nothing is written: the artifacts and files lists that would
+be, with the files entering and leaving each one, are listed instead.
+
+With --stdout, the hot context is written to standard output instead of the
+context file, and with --clipboard it is copied to the clipboard. Neither
+touches the generated artifacts, files lists or cold context, so the output
+can be piped straight into another tool:
+
+  cx generate --stdout | llm chat
+
+With --format (default: cx.output_format, else xml), both artifacts are
+written as markdown (a fenced code block per file), jsonl (one JSON record
+per file) or through the Go template .cx/templates/<name>.tmpl. --only
+requires the XML format.
+
+With --provenance (or cx.provenance), each file is preceded by a comment
+naming the rules file line that included it and its tier.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
+			start := time.Now()
 			mgr := context.NewManager(GetWorkDir())
 			mgr.SetContext(ctx)
 			mgr.SetStripComments(stripComments)
+			mgr.SetChecksums(checksums || context.LoadCxConfig(mgr.GetWorkDir()).Checksums)
+			mgr.SetStrictCommands(strict)
+			mgr.SetProvenance(provenance)
+			if maxTokens != "" {
+				tokens, err := context.ParseTokenCount(maxTokens)
+				if err != nil {
+					return fmt.Errorf("invalid --max-tokens: %w", err)
+				}
+				mgr.SetMaxTokens(tokens)
+			}
+			if format != "" {
+				if cmd.Flags().Changed("xml") {
+					return fmt.Errorf("--format and --xml cannot be combined")
+				}
+				mgr.SetOutputFormat(format)
+			}
+
+			stream := toStdout || toClipboard
+			if stream {
+				if toStdout && toClipboard {
+					return fmt.Errorf("--stdout and --clipboard cannot be combined")
+				}
+				if dryRun || len(onlyPatterns) > 0 || onlyTier != "" || len(models) > 0 || allModels {
+					return fmt.Errorf("--stdout and --clipboard cannot be combined with --dry-run, --only, --only-tier, --model or --all-models")
+				}
+				if toStdout {
+					// Keep stdout for the context itself.
+					logging.SetGlobalOutput(cmd.ErrOrStderr())
+				}
+			}
+
+			if len(onlyPatterns) > 0 && (!useXMLFormat || mgr.OutputFormat() != context.FormatXML) {
+				return fmt.Errorf("--only requires the XML format")
+			}
+			if onlyTier != "" && onlyTier != "hot" && onlyTier != "cold" {
+				return fmt.Errorf("invalid --only-tier %q (expected hot or cold)", onlyTier)
+			}
+			mgr.SetOnlyPatterns(onlyPatterns)
+			defer mgr.SetOnlyPatterns(nil)
 
 			targetRulesFile, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
 			if err != nil {
 				return err
 			}
 
+			if targetRulesFile != "" && onlyTier != "" {
+				return fmt.Errorf("--only-tier applies to the active rules; it cannot be combined with --rules-file or --job")
+			}
+
+			budgets, err := modelBudgetsFromFlags(mgr, models, allModels)
+			if err != nil {
+				return err
+			}
+			if len(budgets) > 0 && (targetRulesFile != "" || onlyTier == "cold" || len(onlyPatterns) > 0) {
+				return fmt.Errorf("--model and --all-models apply to a full hot generation from the active rules")
+			}
+
+			if dryRun && len(budgets) > 0 {
+				return fmt.Errorf("--dry-run cannot be combined with --model or --all-models")
+			}
+
 			if targetRulesFile == "" {
 				if _, rulesPath, _ := mgr.LoadRulesContent(); rulesPath == "" {
 					fmt.Fprintln(cmd.ErrOrStderr(), "hint: no context rules found — create one with 'cx edit' (see 'cx rules where')")
@@ -49,22 +154,53 @@ func NewGenerateCmd() *cobra.Command {
 					Log(ctx)
 			}
 
-			ulog.Progress("Generating context file").Log(ctx)
-
-			if targetRulesFile != "" {
-				if err := mgr.GenerateContextFromRulesFile(targetRulesFile, useXMLFormat); err != nil {
+			if dryRun {
+				changes, err := mgr.PlanGeneration(targetRulesFile, onlyTier)
+				if err != nil {
 					return err
 				}
-			} else {
-				if err := mgr.GenerateContext(useXMLFormat); err != nil {
+				return printPlannedChanges(cmd, changes)
+			}
+
+			if stream {
+				if err := streamContext(cmd, mgr, targetRulesFile, toClipboard); err != nil {
 					return err
 				}
+				if err := mgr.RecordUsage("generate", start); err != nil {
+					ulog.Warn("Failed to record usage metrics").Err(err).Log(ctx)
+				}
+				return nil
 			}
 
-			ulog.Success("Context file generated successfully").Log(ctx)
+			if onlyTier != "cold" {
+				ulog.Progress("Generating context file").Log(ctx)
+
+				if targetRulesFile != "" {
+					if err := mgr.GenerateContextFromRulesFile(targetRulesFile, useXMLFormat); err != nil {
+						return err
+					}
+				} else {
+					if err := mgr.GenerateContext(useXMLFormat); err != nil {
+						return err
+					}
+				}
+
+				ulog.Success("Context file generated successfully").Log(ctx)
+			}
+
+			if len(budgets) > 0 {
+				ulog.Progress("Generating per-model context variants").Log(ctx)
+				variants, err := mgr.GenerateModelVariants(budgets, useXMLFormat)
+				if err != nil {
+					return err
+				}
+				if err := printModelVariants(cmd, mgr, variants); err != nil {
+					return err
+				}
+			}
 
 			// Only generate cached context for active scratchpad (not snapshot inspections)
-			if targetRulesFile == "" {
+			if targetRulesFile == "" && onlyTier != "hot" {
 				ulog.Progress("Generating cached context file").Log(ctx)
 
 				if err := mgr.GenerateCachedContext(); err != nil {
@@ -73,13 +209,183 @@ func NewGenerateCmd() *cobra.Command {
 
 				ulog.Success("Cached context file generated successfully").Log(ctx)
 			}
+
+			if trim := mgr.BudgetTrim(); trim != nil {
+				printBudgetTrim(cmd, trim)
+			}
+			warnLargeFiles(mgr)
+
+			if err := mgr.RecordUsage("generate", start); err != nil {
+				ulog.Warn("Failed to record usage metrics").Err(err).Log(ctx)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&useXMLFormat, "xml", true, "Use XML-style delimiters (default: true)")
 	cmd.Flags().BoolVar(&stripComments, "strip-comments", false, "Strip code comments from included files (go/rust/ts/js/html/css)")
+	cmd.Flags().StringSliceVar(&onlyPatterns, "only", nil, "Re-render only files matching these patterns; splice the rest from the existing artifact")
+	cmd.Flags().StringVar(&onlyTier, "only-tier", "", "Regenerate only the hot or the cold artifact")
+	cmd.Flags().BoolVar(&checksums, "checksum", false, "Write .sha256 sidecars next to generated artifacts (default: cx.checksums)")
+	cmd.Flags().StringArrayVar(&models, "model", nil, "Also write a hot context variant fitted to a model budget (name=tokens, e.g. claude=200k; a bare name uses cx.model_budgets)")
+	cmd.Flags().BoolVar(&allModels, "all-models", false, "Also write a hot context variant for every model in cx.model_budgets")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
+	cmd.Flags().StringVar(&maxTokens, "max-tokens", "", "Trim the generated files to this token budget (e.g. 120k), overriding @budget:")
+	cmd.Flags().BoolVar(&toStdout, "stdout", false, "Write the hot context to stdout instead of the context file")
+	cmd.Flags().BoolVar(&toClipboard, "clipboard", false, "Copy the hot context to the clipboard instead of writing the context file")
+	cmd.Flags().StringVar(&format, "format", "", "Output format: xml, markdown, jsonl or a template name from .cx/templates (default: cx.output_format)")
+	cmd.Flags().BoolVar(&provenance, "provenance", false, "Precede each file with the rules line that included it (default: cx.provenance)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when an @cmd: rule fails instead of generating without its files")
 	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
 
 	return cmd
 }
+
+// streamContext writes the hot context to stdout, or to the clipboard, in
+// place of the context file. Reports that normally go to stdout are sent to
+// stderr so they never mix with the context.
+func streamContext(cmd *cobra.Command, mgr *context.Manager, rulesFile string, toClipboard bool) error {
+	stdout := cmd.OutOrStdout()
+	cmd.SetOut(cmd.ErrOrStderr())
+	defer cmd.SetOut(stdout)
+
+	var buf bytes.Buffer
+	dest := stdout
+	if toClipboard {
+		dest = &buf
+	}
+	if err := mgr.StreamContext(dest, rulesFile, useXMLFormat); err != nil {
+		return err
+	}
+	if toClipboard {
+		if err := writeClipboard(buf.String()); err != nil {
+			return err
+		}
+		summary := mgr.LastGeneration()
+		fmt.Fprintf(stdout, "Copied context to clipboard (%d files, ~%s tokens)\n",
+			summary.HotFiles, context.FormatTokenCount(summary.HotTokens))
+	}
+	if trim := mgr.BudgetTrim(); trim != nil {
+		printBudgetTrim(cmd, trim)
+	}
+	warnLargeFiles(mgr)
+	return nil
+}
+
+// modelBudgetsFromFlags collects the budgets of --all-models and --model, in
+// that order; a --model value replaces a configured budget of the same name.
+func modelBudgetsFromFlags(mgr *context.Manager, models []string, allModels bool) ([]context.ModelBudget, error) {
+	configured := context.LoadCxConfig(mgr.GetWorkDir()).ModelBudgets
+	var budgets []context.ModelBudget
+	index := make(map[string]int)
+	add := func(b context.ModelBudget) {
+		if i, ok := index[b.Model]; ok {
+			budgets[i] = b
+			return
+		}
+		index[b.Model] = len(budgets)
+		budgets = append(budgets, b)
+	}
+	if allModels {
+		if len(configured) == 0 {
+			return nil, fmt.Errorf("--all-models: no budgets configured in cx.model_budgets")
+		}
+		for _, b := range context.ConfiguredModelBudgets(configured) {
+			add(b)
+		}
+	}
+	for _, spec := range models {
+		b, err := context.ParseModelBudget(spec, configured)
+		if err != nil {
+			return nil, err
+		}
+		add(b)
+	}
+	return budgets, nil
+}
+
+// printModelVariants reports each model variant and the files it omitted.
+func printModelVariants(cmd *cobra.Command, mgr *context.Manager, variants []context.ModelVariant) error {
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, variants)
+	}
+	rel := func(p string) string {
+		if r, err := filepath.Rel(mgr.GetWorkDir(), p); err == nil && !strings.HasPrefix(r, "..") {
+			return r
+		}
+		return p
+	}
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tBUDGET\tTOKENS\tFILES\tOMITTED\tPATH")
+	for _, v := range variants {
+		tokens := context.FormatTokenCount(v.Tokens)
+		if v.OverBudget {
+			tokens += " (over)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", v.Model, context.FormatTokenCount(v.Budget), tokens, v.Files, len(v.Omitted), rel(v.Path))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if v.OverBudget {
+			context.Warnf("%s: required files alone exceed the %s token budget", v.Model, context.FormatTokenCount(v.Budget))
+		}
+		if len(v.Omitted) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\nOmitted for %s:\n", v.Model)
+		for _, f := range v.Omitted {
+			fmt.Fprintf(out, "  %s (%s tokens)\n", rel(f.Path), context.FormatTokenCount(f.Tokens))
+		}
+	}
+	return nil
+}
+
+// printBudgetTrim reports the files the token budget dropped from the
+// generated context.
+func printBudgetTrim(cmd *cobra.Command, trim *context.BudgetTrim) {
+	budget := context.FormatTokenCount(trim.Budget)
+	if trim.OverBudget {
+		context.Warnf("required files alone exceed the %s token budget (%s)", budget, trim.Source)
+	}
+	if trim.Dropped() == 0 || cli.GetOptions(cmd).JSONOutput {
+		return
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "\nDropped %d file(s) (%s tokens) to fit the %s token budget (%s):\n",
+		trim.Dropped(), context.FormatTokenCount(trim.DroppedTokens()), budget, trim.Source)
+	for _, tier := range []struct {
+		name  string
+		files []context.OmittedFile
+	}{{"cold", trim.DroppedCold}, {"hot", trim.DroppedHot}} {
+		for _, f := range tier.files {
+			fmt.Fprintf(out, "  %-4s %s (%s tokens)\n", tier.name, displayWorkPath(f.Path), context.FormatTokenCount(f.Tokens))
+		}
+	}
+}
+
+// maxLargeFileWarnings caps the files warnLargeFiles lists.
+const maxLargeFileWarnings = 10
+
+// warnLargeFiles warns about generated files at or over the project's warn
+// token threshold (cx.token_thresholds).
+func warnLargeFiles(mgr *context.Manager) {
+	thresholds := mgr.TokenThresholds()
+	large := mgr.LargeGeneratedFiles(thresholds)
+	if len(large) == 0 {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d generated file(s) at or over %s tokens:", len(large), context.FormatTokenCount(thresholds.Warn))
+	for i, f := range large {
+		if i == maxLargeFileWarnings {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(large)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s (%s tokens)", displayWorkPath(f.Path), context.FormatTokenCount(f.Tokens))
+	}
+	context.Warnf("%s", b.String())
+}
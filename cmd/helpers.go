@@ -12,9 +12,46 @@ import (
 // GlobalWorkDir holds the value of the --dir / -C persistent flag.
 var GlobalWorkDir string
 
-// GetWorkDir returns the global --dir flag value, or empty string to let NewManager use CWD.
+// GlobalCwdRelative holds the value of the --cwd-relative persistent flag.
+var GlobalCwdRelative bool
+
+// GlobalStrictWalk holds the value of the --strict-walk persistent flag.
+var GlobalStrictWalk bool
+
+// GlobalStandalone holds the value of the --standalone persistent flag.
+var GlobalStandalone bool
+
+// GlobalNoCache holds the value of the --no-cache persistent flag.
+var GlobalNoCache bool
+
+// ApplyGlobalFlags pushes persistent flag values that configure the context
+// package process-wide, and starts collecting warnings for FlushWarnings.
+// It runs before every command.
+func ApplyGlobalFlags() {
+	context.SetStrictWalk(GlobalStrictWalk)
+	context.SetStandalone(GlobalStandalone)
+	context.SetNoCache(GlobalNoCache)
+	context.SetCollectWarnings(true)
+}
+
+// FlushWarnings prints the warnings collected while the command ran.
+func FlushWarnings() {
+	context.PrintWarnings(os.Stderr, context.TakeWarnings())
+}
+
+// GetWorkDir returns the global --dir flag value if set, otherwise the
+// project root above the current directory (see context.FindProjectRoot).
+// With --cwd-relative, or outside any project, it returns empty string to let
+// NewManager use CWD.
 func GetWorkDir() string {
-	return GlobalWorkDir
+	if GlobalWorkDir != "" || GlobalCwdRelative {
+		return GlobalWorkDir
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return context.FindProjectRoot(cwd)
 }
 
 // AddRulesFileFlags adds standard --job and --rules-file flags to a command.
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewImportIgnoresCmd creates the import-ignores command.
+func NewImportIgnoresCmd() *cobra.Command {
+	var dryRun, cold bool
+
+	cmd := &cobra.Command{
+		Use:   "import-ignores",
+		Short: "Turn .gitignore and similar files into exclusion rules",
+		Long: `Translates the patterns in the project's .gitignore, .dockerignore,
+.eslintignore and .prettierignore into cx exclusion rules and adds them to
+the active rules file, one commented block per ignore file.
+
+Patterns already excluded by default (node_modules, dist, .venv, .git and the
+other junk directories) or already present in the rules file are skipped, as
+are negated ('!pattern') lines, which re-include files and need a human
+decision. --dry-run lists the translation without writing anything.`,
+		Example: `  cx import-ignores --dry-run
+  cx import-ignores
+  cx import-ignores --cold`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			mgr := context.NewManager(GetWorkDir())
+			imported := mgr.ImportIgnoreRules()
+
+			if cli.GetOptions(cmd).JSONOutput && dryRun {
+				return writeJSON(cmd, imported)
+			}
+
+			var sources []string
+			bySource := make(map[string][]string)
+			for _, entry := range imported {
+				if entry.Rule == "" {
+					continue
+				}
+				if bySource[entry.Source] == nil {
+					sources = append(sources, entry.Source)
+				}
+				bySource[entry.Source] = append(bySource[entry.Source], entry.Rule)
+			}
+
+			contextType := "hot"
+			if cold {
+				contextType = "cold"
+			}
+			if !dryRun {
+				for _, source := range sources {
+					if err := mgr.AppendRuleGroup("imported from "+source, bySource[source], contextType); err != nil {
+						return err
+					}
+				}
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, imported)
+			}
+
+			if len(imported) == 0 {
+				fmt.Fprintln(out, "No ignore files with patterns found.")
+				return nil
+			}
+			added := 0
+			for _, entry := range imported {
+				if entry.Rule != "" {
+					added++
+					fmt.Fprintf(out, "+ %-30s  %s:%d %s\n", entry.Rule, entry.Source, entry.Line, entry.Pattern)
+				} else {
+					fmt.Fprintf(out, "  %-30s  %s:%d %s\n", "("+entry.Reason+")", entry.Source, entry.Line, entry.Pattern)
+				}
+			}
+			switch {
+			case dryRun:
+				fmt.Fprintf(out, "\n%d rules would be added to %s context (dry run)\n", added, contextType)
+			case added > 0:
+				fmt.Fprintf(out, "\n✓ Added %d exclusion rules to %s context\n", added, contextType)
+			default:
+				fmt.Fprintln(out, "\nNothing to add.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the translated rules without writing them")
+	cmd.Flags().BoolVar(&cold, "cold", false, "Add the rules to the cold section instead of hot")
+
+	return cmd
+}
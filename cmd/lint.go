@@ -11,11 +11,23 @@ import (
 
 func NewLintCmd() *cobra.Command {
 	var jobFile, rulesFile string
+	var content bool
 
 	cmd := &cobra.Command{
 		Use:   "lint",
 		Short: "Validate rules syntax and check for potential issues",
-		Long:  `Analyzes the active rules file for syntax errors, directive typos, overly broad patterns, and patterns that match zero files.`,
+		Long: `Analyzes the active rules file for syntax errors, directive typos, overly broad patterns, and patterns that match zero files.
+
+It also reports unreachable lines, whose every match is claimed by a later
+line; rules repeated within a section or in both the hot and cold sections;
+and patterns that traverse outside the workspace. Each issue names its line
+and, where there is an obvious one, a suggested fix.
+
+With --content, also screens the content of every file the rules include for
+text that can derail a model: ANSI escapes and other control characters,
+zero-width and bidi-override characters, extremely long lines, and
+prompt-injection markers in third-party files. cx.content_safety decides
+whether generation only warns about these or strips them.`,
 		Example: `  # Lint the active rules file
   cx lint
 
@@ -23,7 +35,10 @@ func NewLintCmd() *cobra.Command {
   cx lint --job 02-spec.md
 
   # Use in CI to catch unsafe rules (exits 1 on errors)
-  cx lint && echo "rules ok"`,
+  cx lint && echo "rules ok"
+
+  # Also screen the included files' content
+  cx lint --content`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := context.NewManager(GetWorkDir())
 
@@ -42,6 +57,12 @@ func NewLintCmd() *cobra.Command {
 				return fmt.Errorf("failed to lint rules: %w", err)
 			}
 
+			if content {
+				if err := lintContent(mgr, targetRulesFile); err != nil {
+					return err
+				}
+			}
+
 			if len(issues) == 0 {
 				fmt.Println("Rules look good! No issues found.")
 				return nil
@@ -51,7 +72,11 @@ func NewLintCmd() *cobra.Command {
 			fmt.Printf("Found %d issue(s) in rules file:\n\n", len(issues))
 			for _, issue := range issues {
 				fmt.Printf("[%s] Line %d: %s\n", issue.Severity, issue.LineNum, issue.Message)
-				fmt.Printf("    > %s\n\n", issue.Line)
+				fmt.Printf("    > %s\n", issue.Line)
+				if issue.Fix != "" {
+					fmt.Printf("    fix: %s\n", issue.Fix)
+				}
+				fmt.Println()
 				if issue.Severity == "Error" {
 					hasErrors = true
 				}
@@ -65,6 +90,38 @@ func NewLintCmd() *cobra.Command {
 	}
 
 	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().BoolVar(&content, "content", false, "Also screen included files for control characters, long lines and prompt-injection markers")
 
 	return cmd
 }
+
+// lintContent prints the content safety findings for the files the rules
+// include.
+func lintContent(mgr *context.Manager, rulesFile string) error {
+	var files []string
+	if rulesFile != "" {
+		hot, cold, err := mgr.ResolveFilesFromCustomRulesFile(rulesFile)
+		if err != nil {
+			return fmt.Errorf("failed to resolve files from rules file: %w", err)
+		}
+		files = append(hot, cold...)
+	} else {
+		session, err := mgr.NewResolutionSession()
+		if err != nil {
+			return err
+		}
+		files = append(append([]string{}, session.Hot...), session.Cold...)
+	}
+
+	findings := mgr.ScanContentSafety(files)
+	if len(findings) == 0 {
+		fmt.Printf("Content looks safe: no findings in %d file(s).\n\n", len(files))
+		return nil
+	}
+	fmt.Printf("Found %d content safety finding(s) in %d file(s):\n", len(findings), len(files))
+	for _, f := range findings {
+		fmt.Printf("  - %s\n", f)
+	}
+	fmt.Println()
+	return nil
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/grovetools/core/cli"
 	"github.com/grovetools/core/pkg/workspace"
@@ -13,13 +15,18 @@ import (
 )
 
 func NewListCmd() *cobra.Command {
-	var jobFile, rulesFile string
-	var relPaths bool
+	var jobFile, rulesFile, sortBy string
+	var relPaths, showTokens bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List files in context",
-		Long:  `Lists the absolute paths of all files in the context. Use --rel for paths relative to the rules base directory.`,
+		Long: `Lists the absolute paths of all files in the context. Use --rel for paths relative to the rules base directory.
+
+--tokens prefixes each path with its estimated token count, and --sort orders
+the list by tokens or size (largest first) or by path, to find the files
+bloating a context.`,
+		Example: `  cx list --tokens --sort=tokens --rel | head -20`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := context.NewManager(GetWorkDir())
 			mgr.SetContext(cmd.Context())
@@ -27,6 +34,14 @@ func NewListCmd() *cobra.Command {
 			if jsonOutput && relPaths {
 				return fmt.Errorf("--json cannot be combined with --rel; machine file identities are absolute")
 			}
+			switch sortBy {
+			case "", "path", "tokens", "size":
+			default:
+				return fmt.Errorf("invalid --sort %q: must be tokens, size or path", sortBy)
+			}
+			if jsonOutput && (showTokens || sortBy != "") {
+				return fmt.Errorf("--json cannot be combined with --tokens or --sort; use 'cx stats --json' for per-file tokens")
+			}
 
 			targetRulesFile, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
 			if err != nil {
@@ -72,19 +87,71 @@ func NewListCmd() *cobra.Command {
 			}
 
 			base := mgr.GetRulesBaseDir()
-			for _, file := range files {
-				fmt.Fprintln(cmd.OutOrStdout(), projectListPath(file, base, relPaths))
+			if !showTokens && sortBy == "" {
+				for _, file := range files {
+					fmt.Fprintln(cmd.OutOrStdout(), projectListPath(file, base, relPaths))
+				}
+				return nil
 			}
-			return nil
+
+			entries := listFileStats(files, base)
+			sortListEntries(entries, sortBy)
+			if !showTokens {
+				for _, e := range entries {
+					fmt.Fprintln(cmd.OutOrStdout(), projectListPath(e.Path, base, relPaths))
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', tabwriter.AlignRight)
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t %s\n", context.FormatTokenCount(e.Tokens), projectListPath(e.Path, base, relPaths))
+			}
+			return w.Flush()
 		},
 	}
 
 	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
 	cmd.Flags().BoolVar(&relPaths, "rel", false, "print paths relative to the rules base directory instead of absolute")
+	cmd.Flags().BoolVar(&showTokens, "tokens", false, "prefix each path with its estimated token count")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "sort by tokens, size (largest first) or path")
 
 	return cmd
 }
 
+// listFileStats returns the size and estimated tokens of each file, in
+// order. Files that cannot be read count as empty.
+func listFileStats(files []string, base string) []context.FileInfo {
+	provider := context.GetStatsProvider()
+	entries := make([]context.FileInfo, len(files))
+	for i, file := range files {
+		entries[i] = context.FileInfo{Path: file}
+		if info, err := provider.GetFileStats(projectListPath(file, base, false)); err == nil {
+			entries[i].Tokens, entries[i].Size = info.Tokens, info.Size
+		}
+	}
+	return entries
+}
+
+// sortListEntries orders entries for cx list --sort. Ties, and the default
+// order, are by path.
+func sortListEntries(entries []context.FileInfo, by string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		switch by {
+		case "tokens":
+			if entries[i].Tokens != entries[j].Tokens {
+				return entries[i].Tokens > entries[j].Tokens
+			}
+		case "size":
+			if entries[i].Size != entries[j].Size {
+				return entries[i].Size > entries[j].Size
+			}
+		case "":
+			return false
+		}
+		return entries[i].Path < entries[j].Path
+	})
+}
+
 // projectListPath renders a resolved file path in the requested form. Inputs
 // may be absolute or relative to base (the two branches above differ); this
 // normalizes both so a single --rel flag controls the whole output.
@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/grovetools/core/cli"
 	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/pkg/context"
@@ -10,13 +15,30 @@ import (
 
 func NewListCacheCmd() *cobra.Command {
 	var jobFile, rulesFile string
+	var variants, long bool
 
 	cmd := &cobra.Command{
 		Use:   "list-cache",
 		Short: "List cached cold context files",
-		Long:  `Lists the absolute paths of all files in the cached cold context.`,
+		Long: `Lists the absolute paths of all files in the cached cold context.
+
+With --variants, lists the generated artifacts on disk instead: with
+cx.artifact_name set (e.g. "{name}-{branch}-{ruleset}"), one set per branch
+and rule set generated in this checkout, marking the ones the current branch
+and rule set resolve to.
+
+With --long (-l), describes each cached context on disk instead: the rule
+set that produced it, its age against @expire-time, whether it is frozen,
+its file and token counts, and how many of its source files changed or
+disappeared since it was generated.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := context.NewManager(GetWorkDir())
+			if variants {
+				return listArtifactVariants(cmd, mgr)
+			}
+			if long {
+				return listCachedArtifacts(cmd, mgr)
+			}
 
 			targetRulesFile, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
 			if err != nil {
@@ -45,6 +67,118 @@ func NewListCacheCmd() *cobra.Command {
 	}
 
 	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().BoolVar(&variants, "variants", false, "List every generated artifact variant (per branch, rule set, ...) instead")
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "Describe each cached context: rule set, age and expiry, frozen status, tokens and changed sources")
 
 	return cmd
 }
+
+// listArtifactVariants prints the generated artifact variants on disk.
+func listArtifactVariants(cmd *cobra.Command, mgr *context.Manager) error {
+	variants, err := mgr.ListArtifactVariants()
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		if variants == nil {
+			variants = []context.ArtifactVariant{}
+		}
+		return writeJSON(cmd, variants)
+	}
+	if len(variants) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No generated artifacts found.")
+		return nil
+	}
+
+	workDir := mgr.GetWorkDir()
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tARTIFACT\tVARIANT\tSIZE\tMODIFIED\tPATH")
+	for _, v := range variants {
+		marker := ""
+		if v.Current {
+			marker = "*"
+		}
+		var keys []string
+		for k := range v.Vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, k+"="+v.Vars[k])
+		}
+		variant := strings.Join(parts, " ")
+		if variant == "" {
+			variant = "-"
+		}
+		path := v.Path
+		if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", marker, v.Artifact, variant,
+			context.FormatBytes(int(v.Size)), v.ModTime.Format("2006-01-02 15:04"), path)
+	}
+	return w.Flush()
+}
+
+// listCachedArtifacts prints the provenance and freshness of each cached
+// context on disk.
+func listCachedArtifacts(cmd *cobra.Command, mgr *context.Manager) error {
+	infos, err := mgr.ListCachedArtifacts()
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		if infos == nil {
+			infos = []context.CachedArtifactInfo{}
+		}
+		return writeJSON(cmd, infos)
+	}
+	if len(infos) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No cached context found (run 'cx generate').")
+		return nil
+	}
+
+	workDir := mgr.GetWorkDir()
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tRULESET\tAGE\tEXPIRY\tFILES\tTOKENS\tSOURCES\tPATH")
+	for _, a := range infos {
+		marker := ""
+		if a.Current {
+			marker = "*"
+		}
+		ruleset := a.Ruleset
+		if ruleset == "" {
+			ruleset = "?"
+		}
+		expiry := "-"
+		switch {
+		case a.Frozen:
+			expiry = "frozen"
+		case a.NoExpire:
+			expiry = "never"
+		case a.Expired:
+			expiry = "expired (" + a.ExpireTime.String() + ")"
+		case a.ExpireTime > 0:
+			expiry = "in " + formatAge(a.ExpireTime-a.Age)
+		}
+		sources := "unchanged"
+		switch {
+		case len(a.Changed) > 0 && len(a.Missing) > 0:
+			sources = fmt.Sprintf("%d changed, %d missing", len(a.Changed), len(a.Missing))
+		case len(a.Changed) > 0:
+			sources = fmt.Sprintf("%d changed", len(a.Changed))
+		case len(a.Missing) > 0:
+			sources = fmt.Sprintf("%d missing", len(a.Missing))
+		case a.Files == 0:
+			sources = "-"
+		}
+		path := a.Path
+		if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", marker, ruleset, formatAge(a.Age), expiry,
+			a.Files, context.FormatTokenCount(a.Tokens), sources, path)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func NewListSnapshotsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-snapshots",
+		Short: "List saved context snapshots",
+		Long: `Lists the snapshots saved under .grove/snapshots (by 'cx stats --save-snapshot'
+and 'cx scratch'), newest first, with their file and token counts.
+
+Any listed name can be passed to 'cx stats --compare'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			snapshots, err := mgr.ListSnapshots()
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				if snapshots == nil {
+					snapshots = []context.SnapshotInfo{}
+				}
+				return writeJSON(cmd, map[string]interface{}{
+					"schema_version": machineSchemaVersion,
+					"snapshots":      snapshots,
+				})
+			}
+			if len(snapshots) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No snapshots found (save one with 'cx stats --save-snapshot').")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCREATED\tFILES\tTOKENS")
+			for _, s := range snapshots {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", s.Name, s.CreatedAt.Local().Format("2006-01-02 15:04"),
+					s.Files, context.FormatTokenCount(s.Tokens))
+			}
+			return w.Flush()
+		},
+	}
+}
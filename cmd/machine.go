@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -17,10 +18,42 @@ func writeJSON(cmd *cobra.Command, value any) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal machine output: %w", err)
 	}
+	if data, err = withWarnings(data); err != nil {
+		return fmt.Errorf("failed to marshal machine output: %w", err)
+	}
 	fmt.Fprintln(cmd.OutOrStdout(), string(data))
 	return nil
 }
 
+// withWarnings adds the warnings collected so far to an indented JSON
+// object as a trailing "warnings" field, and takes them so they are not
+// printed again. Other JSON values are returned unchanged.
+func withWarnings(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != '{' {
+		return data, nil
+	}
+	warnings := context.TakeWarnings()
+	if len(warnings) == 0 {
+		return data, nil
+	}
+	list, err := json.MarshalIndent(warnings, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	body := bytes.TrimSpace(data[1 : len(data)-1])
+	var b bytes.Buffer
+	b.WriteString("{\n")
+	if len(body) > 0 {
+		b.WriteString("  ")
+		b.Write(body)
+		b.WriteString(",\n")
+	}
+	b.WriteString(`  "warnings": `)
+	b.Write(list)
+	b.WriteString("\n}")
+	return b.Bytes(), nil
+}
+
 // machineFileSet is one resolved hot or cold context. ResolvedFiles counts
 // every path produced by the rules engine; ReadableFiles counts only paths the
 // stats provider could inspect. Manifests and largest-file lists are bounded.
@@ -84,16 +117,12 @@ func resolveMachineFiles(mgr *context.Manager, targetRulesFile string) (hotFiles
 		return hotFiles, coldFiles, rulesPath, nil
 	}
 
-	hotFiles, err = mgr.ResolveFilesFromRules()
-	if err != nil {
-		return nil, nil, "", err
-	}
-	coldFiles, err = mgr.ResolveColdContextFiles()
+	session, err := mgr.NewResolutionSession()
 	if err != nil {
 		return nil, nil, "", err
 	}
 	rulesPath = mgr.ResolveRulesPath()
-	return hotFiles, coldFiles, rulesPath, nil
+	return session.Hot, session.Cold, rulesPath, nil
 }
 
 func absoluteMachinePaths(files []string, base string) []string {
@@ -180,3 +180,39 @@ func TestCanonicalConceptPresetPath(t *testing.T) {
 		t.Fatal("expected unsafe concept id rejection")
 	}
 }
+
+func TestWithWarnings(t *testing.T) {
+	context.SetCollectWarnings(true)
+	t.Cleanup(func() {
+		context.TakeWarnings()
+		context.SetCollectWarnings(false)
+	})
+
+	array := []byte("[\n  1\n]")
+	context.Warnf("first")
+	if got, err := withWarnings(array); err != nil || !bytes.Equal(got, array) {
+		t.Fatalf("withWarnings(array) = %s, %v; want it unchanged", got, err)
+	}
+
+	data, err := json.MarshalIndent(map[string]int{"files": 2}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := withWarnings(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Files    int               `json:"files"`
+		Warnings []context.Warning `json:"warnings"`
+	}
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if decoded.Files != 2 || len(decoded.Warnings) != 1 || decoded.Warnings[0].Message != "first" {
+		t.Errorf("withWarnings = %s", got)
+	}
+	if rest := context.TakeWarnings(); len(rest) != 0 {
+		t.Errorf("warnings left after withWarnings: %v", rest)
+	}
+}
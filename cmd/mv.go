@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewMvCmd creates the mv command.
+func NewMvCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "mv <old> <new>",
+		Short: "Move a file or directory and update the rules that point at it",
+		Long: `Moves a file or directory (with git mv when it is tracked) and rewrites every
+rule, in the active rules file and in each named rule set of the project,
+whose pattern names the old path or a path under it. Exclusions, "./" and
+absolute patterns keep their form; directive and comment lines are left alone.
+
+If <new> is an existing directory, <old> is moved into it.`,
+		Example: `  cx mv pkg/old pkg/new
+  cx mv internal/parser.go internal/parse/ --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			rename, rewrites, err := mgr.MovePath(args[0], args[1], dryRun)
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, map[string]interface{}{
+					"rename":   rename,
+					"rewrites": nonNilRewrites(rewrites),
+					"dry_run":  dryRun,
+				})
+			}
+			verb := "Moved"
+			if dryRun {
+				verb = "Would move"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s -> %s\n", verb, rename.Old, rename.New)
+			printRewrites(cmd, mgr, rewrites, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without moving or writing anything")
+
+	return cmd
+}
+
+// NewFixCmd creates the fix command.
+func NewFixCmd() *cobra.Command {
+	var paths, dryRun bool
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "fix --paths",
+		Short: "Repair rules broken by changes to the project",
+		Long: `Repairs rules that changes to the project have broken.
+
+--paths uses git rename detection between --since (default HEAD) and the
+working tree to find moved files and directories, then rewrites the rules that
+still point at the old paths, as cx mv would have. Moves must be staged (git mv
+or git add) or committed after --since for git to pair them up.`,
+		Example: `  cx fix --paths
+  cx fix --paths --since main --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !paths {
+				return fmt.Errorf("nothing to fix: pass --paths")
+			}
+			mgr := context.NewManager(GetWorkDir())
+			renames, err := mgr.DetectRenames(since)
+			if err != nil {
+				return err
+			}
+			rewrites, err := mgr.RewriteRulePaths(renames, dryRun)
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				if renames == nil {
+					renames = []context.PathRename{}
+				}
+				return writeJSON(cmd, map[string]interface{}{
+					"renames":  renames,
+					"rewrites": nonNilRewrites(rewrites),
+					"dry_run":  dryRun,
+				})
+			}
+			if len(renames) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No renames detected.")
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Detected %d renames.\n", len(renames))
+			printRewrites(cmd, mgr, rewrites, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&paths, "paths", false, "Rewrite rules pointing at paths git detects as renamed")
+	cmd.Flags().StringVar(&since, "since", "", "Detect renames since this ref (default HEAD)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the rewrites without writing them")
+
+	return cmd
+}
+
+// nonNilRewrites keeps an empty rewrite list a JSON array.
+func nonNilRewrites(rewrites []context.RuleRewrite) []context.RuleRewrite {
+	if rewrites == nil {
+		return []context.RuleRewrite{}
+	}
+	return rewrites
+}
+
+// printRewrites lists rule rewrites grouped by rules file.
+func printRewrites(cmd *cobra.Command, mgr *context.Manager, rewrites []context.RuleRewrite, dryRun bool) {
+	out := cmd.OutOrStdout()
+	if len(rewrites) == 0 {
+		fmt.Fprintln(out, "No rules reference the moved paths.")
+		return
+	}
+	file := ""
+	for _, rw := range rewrites {
+		if rw.File != file {
+			file = rw.File
+			display := file
+			if rel, err := filepath.Rel(mgr.GetWorkDir(), file); err == nil && !strings.HasPrefix(rel, "..") {
+				display = rel
+			}
+			fmt.Fprintf(out, "%s:\n", display)
+		}
+		fmt.Fprintf(out, "  %d: %s -> %s\n", rw.Line, strings.TrimSpace(rw.Old), strings.TrimSpace(rw.New))
+	}
+	if dryRun {
+		fmt.Fprintf(out, "%d rules would be rewritten (dry run).\n", len(rewrites))
+	} else {
+		fmt.Fprintf(out, "Rewrote %d rules.\n", len(rewrites))
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewNoteCmd creates the note command.
+func NewNoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "note",
+		Short: "Pin persistent notes to files and directories",
+		Long: `Attaches short notes to files and directories, e.g. why a package is in the
+hot context or why a generated directory is excluded. Notes are stored in
+` + context.NotesFile + ` and shown in the TUI tree view.
+
+Notes are not part of the context unless 'cx: {notes_in_context: true}' is set
+in grove.yml, in which case generation adds them to the hot context as a
+single markdown file (` + context.NotesExportFile + `).`,
+		Example: `  cx note set internal/billing "Current sprint focus; keep hot"
+  cx note set vendor/ "Excluded: third-party code, never edited"
+  cx note list
+  cx note rm internal/billing`,
+	}
+	cmd.AddCommand(newNoteSetCmd(), newNoteRmCmd(), newNoteListCmd(), newNoteExportCmd())
+	return cmd
+}
+
+func newNoteSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <path> <text...>",
+		Short: "Pin a note to a file or directory, replacing any existing note",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			text := strings.Join(args[1:], " ")
+			if strings.TrimSpace(text) == "" {
+				return fmt.Errorf("note text is empty (use 'cx note rm %s' to remove a note)", args[0])
+			}
+			if err := mgr.SetNote(args[0], text); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pinned note to %s\n", mgr.NoteKey(args[0]))
+			return nil
+		},
+	}
+}
+
+func newNoteRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <path>...",
+		Short: "Remove the notes pinned to files or directories",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			for _, path := range args {
+				removed, err := mgr.RemoveNote(path)
+				if err != nil {
+					return err
+				}
+				if !removed {
+					return fmt.Errorf("no note pinned to %s", mgr.NoteKey(path))
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed note from %s\n", mgr.NoteKey(path))
+			}
+			return nil
+		},
+	}
+}
+
+func newNoteListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List pinned notes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			notes, err := mgr.LoadNotes()
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, notes.Files)
+			}
+			out := cmd.OutOrStdout()
+			if len(notes.Files) == 0 {
+				fmt.Fprintln(out, "No notes pinned. Add one with 'cx note set <path> <text>'.")
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PATH\tUPDATED\tNOTE")
+			for _, p := range notes.Paths() {
+				n := notes.Files[p]
+				fmt.Fprintf(w, "%s\t%s\t%s\n", p, n.UpdatedAt.Local().Format("2006-01-02"), n.Text)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newNoteExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "Write the notes as a markdown file to " + context.NotesExportFile,
+		Long: `Writes the pinned notes as a single markdown document to
+` + context.NotesExportFile + `. Generation does this automatically when
+notes_in_context is enabled; run it to include the notes by hand, e.g. from a
+rules file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			path, err := mgr.ExportNotes()
+			if err != nil {
+				return err
+			}
+			if path == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), "No notes pinned; nothing exported.")
+				return nil
+			}
+			if rel, err := filepath.Rel(mgr.GetWorkDir(), path); err == nil {
+				path = rel
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported notes to %s\n", path)
+			return nil
+		},
+	}
+}
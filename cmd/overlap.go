@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func newRulesOverlapCmd() *cobra.Command {
+	var jobFile, rulesFile string
+
+	cmd := &cobra.Command{
+		Use:   "overlap",
+		Short: "Report files reached by several rules, imports or tiers",
+		Long: `Expands the active rules file (or --rules-file) and reports where its lines
+overlap:
+
+  - files matched from both the hot and cold sections (cold wins, so the hot
+    lines are dead weight for them)
+  - import lines (@a:project::ruleset, @include:, @default:) whose files other
+    lines already provide; an import whose files are all covered elsewhere is
+    suggested for removal
+  - expanded patterns produced more than once, e.g. by recursive @default chains`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(cmd.Context())
+
+			target, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				target = mgr.ResolveRulesPath()
+			}
+
+			report, err := mgr.AnalyzeOverlap(target)
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, report)
+			}
+			printOverlapReport(cmd, report)
+			return nil
+		},
+	}
+
+	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+
+	return cmd
+}
+
+func printOverlapReport(cmd *cobra.Command, report *context.OverlapReport) {
+	out := cmd.OutOrStdout()
+	if len(report.TierConflicts) == 0 && len(report.Imports) == 0 && len(report.DuplicatePatterns) == 0 {
+		fmt.Fprintln(out, "No overlapping rules found.")
+		return
+	}
+
+	if len(report.TierConflicts) > 0 {
+		fmt.Fprintf(out, "Files in both hot and cold sections (%d, kept cold):\n", len(report.TierConflicts))
+		for _, c := range report.TierConflicts {
+			fmt.Fprintf(out, "  %s\n", c.File)
+			fmt.Fprintf(out, "    hot:  %s\n", formatOverlapRules(c.HotRules))
+			fmt.Fprintf(out, "    cold: %s\n", formatOverlapRules(c.ColdRules))
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(report.Imports) > 0 {
+		fmt.Fprintln(out, "Imports sharing files with other rules:")
+		for _, imp := range report.Imports {
+			fmt.Fprintf(out, "  L%d %s: %d of %d files also matched by %s\n",
+				imp.LineNum, imp.Rule, imp.SharedFiles, imp.Files, formatOverlapRules(imp.SharedWith))
+			if imp.Droppable {
+				fmt.Fprintf(out, "    suggestion: drop line %d, every file it adds is already included\n", imp.LineNum)
+			}
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(report.DuplicatePatterns) > 0 {
+		fmt.Fprintln(out, "Patterns expanded more than once:")
+		for _, d := range report.DuplicatePatterns {
+			lines := make([]string, len(d.Lines))
+			for i, l := range d.Lines {
+				lines[i] = fmt.Sprintf("L%d", l)
+			}
+			fmt.Fprintf(out, "  %s (%dx, via %s)\n", d.Pattern, d.Occurrences, strings.Join(lines, ", "))
+		}
+	}
+}
+
+func formatOverlapRules(rules []context.OverlapRule) string {
+	parts := make([]string, len(rules))
+	for i, r := range rules {
+		parts[i] = fmt.Sprintf("L%d %s", r.LineNum, r.Rule)
+	}
+	return strings.Join(parts, "; ")
+}
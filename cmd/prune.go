@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func newRulesPruneCmd() *cobra.Command {
+	var jobFile, rulesFile string
+	var yes, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove rules whose @until: date has passed",
+		Long: `A rule can carry an expiration date, e.g. 'tmp-debug/** @until: 2025-07-01'.
+Once the date has passed the rule is ignored with a warning; prune deletes such
+rules from the active rules file (or --rules-file / --job), asking to confirm
+each one unless --yes is given. --dry-run shows the diff removing every
+expired rule without asking or writing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			mgr := context.NewManager(GetWorkDir())
+			target, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				target = mgr.ResolveRulesPath()
+			}
+			content, err := os.ReadFile(target)
+			if err != nil {
+				return fmt.Errorf("failed to read rules file: %w", err)
+			}
+			expired := context.ExpiredRules(content, time.Now())
+			if dryRun {
+				return printPlannedChanges(cmd, []context.PlannedChange{context.PlanFileWrite(target, context.WithoutExpiredRules(content, expired))})
+			}
+			if cli.GetOptions(cmd).JSONOutput && !yes {
+				return writeJSON(cmd, expired)
+			}
+			if len(expired) == 0 {
+				fmt.Fprintf(out, "%s has no expired rules.\n", target)
+				return nil
+			}
+
+			reader := bufio.NewReader(os.Stdin)
+			var remove []context.ExpiredRule
+			for _, r := range expired {
+				if !yes {
+					fmt.Fprintf(out, "Remove L%d %s (expired %s)? (y/N): ", r.LineNum, r.Line, r.Until.Format("2006-01-02"))
+					response, _ := reader.ReadString('\n')
+					response = strings.ToLower(strings.TrimSpace(response))
+					if response != "y" && response != "yes" {
+						fmt.Fprintf(out, "  kept L%d\n", r.LineNum)
+						continue
+					}
+				}
+				remove = append(remove, r)
+			}
+			if len(remove) == 0 {
+				return nil
+			}
+			if err := context.RemoveExpiredRules(target, remove); err != nil {
+				return err
+			}
+			for _, r := range remove {
+				fmt.Fprintf(out, "✓ L%d %s removed\n", r.LineNum, r.Line)
+			}
+			return nil
+		},
+	}
+
+	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove every expired rule without asking")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
+
+	return cmd
+}
@@ -9,7 +9,9 @@ import (
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/grovetools/core/cli"
 	"github.com/grovetools/core/config"
 	"github.com/grovetools/core/pkg/mux"
 	"github.com/grovetools/core/pkg/repo"
@@ -33,6 +35,7 @@ func NewRepoCmd() *cobra.Command {
 	repoCmd.AddCommand(newRepoSyncCmd())
 	repoCmd.AddCommand(newRepoAuditCmd())
 	repoCmd.AddCommand(newRepoRulesCmd())
+	repoCmd.AddCommand(newRepoGCCmd())
 
 	return repoCmd
 }
@@ -135,6 +138,60 @@ func newRepoListCmd() *cobra.Command {
 	return cmd
 }
 
+func newRepoGCCmd() *cobra.Command {
+	var dryRun bool
+	var maxAge time.Duration
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove shared clones no workspace references anymore",
+		Long: `Garbage-collect the shared clone cache used by git rules.
+
+Every generation records which workspace referenced which URL@version. gc drops
+references from workspaces that no longer exist or have not generated within
+--max-age, then removes worktrees left with no references. Bare clones are kept
+so later checkouts stay fast.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := repo.NewManager()
+			if err != nil {
+				return fmt.Errorf("failed to create repository manager: %w", err)
+			}
+			cache, err := context.NewCloneCache(manager)
+			if err != nil {
+				return err
+			}
+
+			removed, err := cache.GC(maxAge, dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to collect clone cache: %w", err)
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, removed)
+			}
+
+			if len(removed) == 0 {
+				fmt.Println("No unreferenced clones.")
+				return nil
+			}
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, ref := range removed {
+				label := ref.URL
+				if ref.Version != "" {
+					label += "@" + ref.Version
+				}
+				fmt.Printf("%s %s (%s)\n", verb, label, ref.Path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without removing anything")
+	cmd.Flags().DurationVar(&maxAge, "max-age", context.DefaultCloneRefMaxAge, "Drop workspace references not refreshed within this duration")
+	return cmd
+}
+
 func newRepoSyncCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "sync",
@@ -378,7 +435,7 @@ func setupDefaultAuditRules(repoPath string) error {
 	if err != nil {
 		// Non-fatal, just use a basic default
 		rulesContent = []byte("*\n")
-		fmt.Fprintf(os.Stderr, "Warning: could not load default rules for audit: %v\n", err)
+		context.Warnf("could not load default rules for audit: %v", err)
 	}
 	if rulesContent == nil {
 		rulesContent = []byte("*\n")
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/grovetools/core/cli"
 	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/pkg/context"
@@ -122,8 +123,10 @@ func NewResolveCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "resolve [rule]",
 		Short: "Resolve a single rule pattern to a list of files",
-		Long:  `Accepts a single inclusion rule (glob or alias) and prints the list of files it resolves to. Primarily for use by editor integrations.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Accepts a single inclusion rule (glob or alias) and prints the list of files it resolves to. Primarily for use by editor integrations.
+
+With --json, prints the rule's structured preview instead: each pattern it resolves to (aliases and ruleset imports can expand to several) with its matched file count and token estimate, plus the files and tokens of the line as a whole.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ruleLine := args[0]
 
@@ -151,6 +154,7 @@ func NewResolveCmd() *cobra.Command {
 			mgr.SetContext(cmd.Context())
 
 			var files []string
+			var preview *context.RuleLinePreview
 			var err error
 
 			// If rules file and line number are provided, use context-aware resolution
@@ -159,6 +163,16 @@ func NewResolveCmd() *cobra.Command {
 				if err != nil {
 					return fmt.Errorf("error resolving with context: %w", err)
 				}
+				if cli.GetOptions(cmd).JSONOutput {
+					tokens := mgr.EstimateFilesTokens(files)
+					preview = &context.RuleLinePreview{
+						Line:     trimmedLine,
+						Patterns: []context.PatternPreview{{Pattern: trimmedLine, Files: len(files), Tokens: tokens}},
+						Paths:    append([]string{}, files...),
+						Files:    len(files),
+						Tokens:   tokens,
+					}
+				}
 			} else {
 				// Original behavior: resolve pattern in isolation
 				// First, resolve the line. This one call now handles simple globs, aliases,
@@ -167,7 +181,7 @@ func NewResolveCmd() *cobra.Command {
 				if err != nil {
 					// If resolution fails, it's a non-fatal warning for the user.
 					// Print to stderr so it can be captured by the calling plugin.
-					fmt.Fprintf(os.Stderr, "Warning: could not resolve rule: %v\n", err)
+					context.Warnf("could not resolve rule: %v", err)
 					// Fallback to using the original line as the pattern.
 					resolvedPatternsStr = ruleLine
 				}
@@ -177,7 +191,11 @@ func NewResolveCmd() *cobra.Command {
 
 				// Use the manager's file resolution logic with the patterns.
 				// Note: ResolveFilesFromPatterns expects a slice and now handles brace expansion internally.
-				files, err = mgr.ResolveFilesFromPatterns(patterns)
+				if cli.GetOptions(cmd).JSONOutput {
+					preview, err = mgr.PreviewPatterns(trimmedLine, patterns)
+				} else {
+					files, err = mgr.ResolveFilesFromPatterns(patterns)
+				}
 				if err != nil {
 					return fmt.Errorf("error resolving files for rule '%s': %w", ruleLine, err)
 				}
@@ -194,6 +212,10 @@ func NewResolveCmd() *cobra.Command {
 				}
 			}
 
+			if preview != nil {
+				return writeJSON(cmd, preview)
+			}
+
 			// Print the list of files to stdout, one per line.
 			for _, file := range files {
 				fmt.Println(file)
@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/grovetools/core/pkg/alias"
 	"github.com/grovetools/core/pkg/workspace"
 	"github.com/grovetools/core/state"
 	"github.com/spf13/cobra"
@@ -39,6 +38,12 @@ func NewRulesCmd() *cobra.Command {
 	cmd.AddCommand(newRulesPrintPathCmd())
 	cmd.AddCommand(newRulesWhereCmd())
 	cmd.AddCommand(newRulesInitCmd())
+	cmd.AddCommand(newRulesFreezeExpectationsCmd())
+	cmd.AddCommand(newRulesCheckExpectationsCmd())
+	cmd.AddCommand(newRulesOverlapCmd())
+	cmd.AddCommand(newRulesTrustCmd())
+	cmd.AddCommand(newRulesUntrustCmd())
+	cmd.AddCommand(newRulesPruneCmd())
 
 	return cmd
 }
@@ -54,11 +59,19 @@ func newRulesSelectCmd() *cobra.Command {
 }
 
 func newRulesUnsetCmd() *cobra.Command {
-	return &cobra.Command{
+	var dryRun bool
+	cmd := &cobra.Command{
 		Use:   "unset",
 		Short: "Unset the active rule set and fall back to the default rules file",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := stdctx.Background()
+			if context.Standalone() {
+				return fmt.Errorf("cx rules unset: %w", context.ErrStandalone)
+			}
+			if dryRun {
+				active, _ := state.GetString(GetWorkDir(), context.StateSourceKey)
+				return printPlannedChanges(cmd, []context.PlannedChange{context.PlanStateChange(context.StateSourceKey, active, "")})
+			}
 			if err := state.Delete(GetWorkDir(), context.StateSourceKey); err != nil {
 				return fmt.Errorf("failed to update state: %w", err)
 			}
@@ -70,10 +83,13 @@ func newRulesUnsetCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
+	return cmd
 }
 
 func newRulesLoadCmd() *cobra.Command {
-	return &cobra.Command{
+	var dryRun bool
+	cmd := &cobra.Command{
 		Use:   "load <name-or-path>",
 		Short: "Copy a named preset to the active rules file as a modifiable working copy",
 		Long: `Copy a named rule set to the active rules location (plan-scoped, notebook, or .grove/rules).
@@ -118,6 +134,14 @@ Examples:
 			// Resolve the active rules write path (plan-scoped > notebook > local)
 			rulesPath := mgr.ResolveRulesWritePath()
 
+			if dryRun {
+				active, _ := state.GetString(GetWorkDir(), context.StateSourceKey)
+				return printPlannedChanges(cmd, []context.PlannedChange{
+					context.PlanFileWrite(rulesPath, content),
+					context.PlanStateChange(context.StateSourceKey, active, ""),
+				})
+			}
+
 			// Write to resolved rules path
 			if err := os.WriteFile(rulesPath, content, 0o644); err != nil { //nolint:gosec // rules file, not sensitive
 				return fmt.Errorf("failed to write rules: %w", err)
@@ -140,12 +164,13 @@ Examples:
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
+	return cmd
 }
 
 // listRulesForProject lists rule sets for a specific project alias.
 func listRulesForProject(projectAlias string, jsonOutput bool) error {
-	// Import the context package to use AliasResolver
-	resolver := alias.NewAliasResolver()
+	resolver, _ := context.NewAliasResolver(GetWorkDir())
 	projectPath, err := resolver.Resolve(projectAlias)
 	if err != nil {
 		return fmt.Errorf("failed to resolve project alias '%s': %w", projectAlias, err)
@@ -344,6 +369,7 @@ func newRulesListCmd() *cobra.Command {
 }
 
 func newRulesSetCmd() *cobra.Command {
+	var dryRun bool
 	cmd := &cobra.Command{
 		Use:   "set <name-or-path>",
 		Short: "Set a named rule set as active (read-only)",
@@ -354,6 +380,9 @@ You can also provide a direct file path to a rules file (including plan-specific
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := stdctx.Background()
+			if context.Standalone() {
+				return fmt.Errorf("cx rules set: %w (use CX_RULES_FILE or cx rules load instead)", context.ErrStandalone)
+			}
 			nameOrPath := args[0]
 			var sourcePath string
 
@@ -372,6 +401,11 @@ You can also provide a direct file path to a rules file (including plan-specific
 				}
 			}
 
+			if dryRun {
+				active, _ := state.GetString(GetWorkDir(), context.StateSourceKey)
+				return printPlannedChanges(cmd, []context.PlannedChange{context.PlanStateChange(context.StateSourceKey, active, sourcePath)})
+			}
+
 			if err := state.Set(GetWorkDir(), context.StateSourceKey, sourcePath); err != nil {
 				return fmt.Errorf("failed to update state: %w", err)
 			}
@@ -391,11 +425,12 @@ You can also provide a direct file path to a rules file (including plan-specific
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
 	return cmd
 }
 
 func newRulesSaveCmd() *cobra.Command {
-	var work bool
+	var work, dryRun bool
 	cmd := &cobra.Command{
 		Use:   "save <name>",
 		Short: "Save active rules to a named set in .cx/ or .cx.work/",
@@ -434,11 +469,15 @@ Use the --work flag to save to .cx.work/ for temporary, untracked sets.`,
 				}
 			}
 
+			destPath := filepath.Join(destDir, name+context.RulesExt)
+			if dryRun {
+				return printPlannedChanges(cmd, []context.PlannedChange{context.PlanFileWrite(destPath, content)})
+			}
+
 			if err := os.MkdirAll(destDir, 0o755); err != nil {
 				return fmt.Errorf("failed to create %s directory: %w", destDir, err)
 			}
 
-			destPath := filepath.Join(destDir, name+context.RulesExt)
 			if err := os.WriteFile(destPath, content, 0o644); err != nil { //nolint:gosec // rules file, not sensitive
 				return fmt.Errorf("failed to save rule set: %w", err)
 			}
@@ -453,11 +492,12 @@ Use the --work flag to save to .cx.work/ for temporary, untracked sets.`,
 		},
 	}
 	cmd.Flags().BoolVarP(&work, "work", "w", false, "Save to .cx.work/ for temporary, untracked rule sets")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
 	return cmd
 }
 
 func newRulesRmCmd() *cobra.Command {
-	var force bool
+	var force, dryRun bool
 	cmd := &cobra.Command{
 		Use:   "rm <name>",
 		Short: "Remove a named rule set",
@@ -487,6 +527,13 @@ Rule sets in .cx.work/ can be deleted without force.`,
 
 			// Check if this is the currently active rule set
 			activeSource, _ := state.GetString(GetWorkDir(), context.StateSourceKey)
+			if dryRun {
+				changes := []context.PlannedChange{context.PlanFileDelete(rulesPath)}
+				if activeSource == rulesPath {
+					changes = append(changes, context.PlanStateChange(context.StateSourceKey, activeSource, ""))
+				}
+				return printPlannedChanges(cmd, changes)
+			}
 			if activeSource == rulesPath {
 				// Unset it first before deleting
 				if err := state.Delete(GetWorkDir(), context.StateSourceKey); err != nil {
@@ -510,6 +557,7 @@ Rule sets in .cx.work/ can be deleted without force.`,
 		},
 	}
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force delete a version-controlled rule set from .cx/")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
 	return cmd
 }
 
@@ -653,6 +701,7 @@ func newRulesWhereCmd() *cobra.Command {
 }
 
 func newRulesInitCmd() *cobra.Command {
+	var dryRun bool
 	cmd := &cobra.Command{
 		Use:   "init [preset]",
 		Short: "Initialize plan-scoped rules for the active plan",
@@ -716,6 +765,10 @@ This isolates context edits to the current worktree/plan so they don't affect ot
 				}
 			}
 
+			if dryRun {
+				return printPlannedChanges(cmd, []context.PlannedChange{context.PlanFileWrite(planRulesPath, content)})
+			}
+
 			// Ensure directory exists
 			if err := os.MkdirAll(filepath.Dir(planRulesPath), 0o755); err != nil {
 				return fmt.Errorf("failed to create directory: %w", err)
@@ -733,6 +786,7 @@ This isolates context edits to the current worktree/plan so they don't affect ot
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunFlagUsage)
 	return cmd
 }
 
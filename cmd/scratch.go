@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewScratchCmd creates the scratch command.
+func NewScratchCmd() *cobra.Command {
+	var paste, copyOut, snapshot, useXML bool
+
+	cmd := &cobra.Command{
+		Use:   "scratch [files...]",
+		Short: "Build a throwaway context from pasted text and files",
+		Long: `Builds a one-off context from text piped on stdin (or, with --paste, the
+clipboard) followed by the named files, and prints it. No rules file, rule set
+or generated artifact is touched, so it suits quick prompts that do not
+deserve a rule set.
+
+Arguments may be files, directories (every file below them) or rules
+patterns. Named files are included as given; directories and patterns follow
+the usual gitignore and workspace rules.
+
+--copy puts the context on the clipboard instead of printing it, and
+--snapshot records its files as an unnamed snapshot (.grove/snapshots/
+scratch-<timestamp>.json) for a later 'cx stats --compare'.`,
+		Example: `  git diff | cx scratch pkg/api/handler.go
+  cx scratch --paste --copy internal/auth "docs/**/*.md"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var note string
+			if paste {
+				text, err := readClipboard()
+				if err != nil {
+					return err
+				}
+				note = text
+			} else if !isTerminal(os.Stdin) {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("reading stdin: %w", err)
+				}
+				note = string(data)
+			}
+			if note == "" && len(args) == 0 {
+				return fmt.Errorf("nothing to include: pipe text on stdin, use --paste, or name files")
+			}
+
+			// Paths are typed relative to where the command runs, which
+			// need not be the project root the manager is anchored at.
+			for i, arg := range args {
+				if _, err := os.Stat(arg); err == nil {
+					if abs, err := filepath.Abs(arg); err == nil {
+						args[i] = abs
+					}
+				}
+			}
+
+			mgr := context.NewManager(GetWorkDir())
+			files, err := mgr.ScratchFiles(args)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := mgr.WriteScratchContext(&buf, note, files, useXML); err != nil {
+				return err
+			}
+			if copyOut {
+				if err := writeClipboard(buf.String()); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Copied scratch context (%d files, %s) to the clipboard\n", len(files), context.FormatBytes(buf.Len()))
+			} else {
+				_, _ = cmd.OutOrStdout().Write(buf.Bytes())
+			}
+
+			if snapshot {
+				path, err := mgr.SaveScratchSnapshot(files)
+				if err != nil {
+					return fmt.Errorf("saving snapshot: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "Snapshot saved to %s\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&paste, "paste", false, "Read the text to include from the clipboard instead of stdin")
+	cmd.Flags().BoolVar(&copyOut, "copy", false, "Copy the context to the clipboard instead of printing it")
+	cmd.Flags().BoolVar(&snapshot, "snapshot", false, "Record the files as an unnamed snapshot for cx stats --compare")
+	cmd.Flags().BoolVar(&useXML, "xml", true, "Use XML-style delimiters (default: true)")
+
+	return cmd
+}
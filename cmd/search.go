@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewGrepCmd creates the grep command.
+func NewGrepCmd() *cobra.Command {
+	var ignoreCase, filesOnly, inContext bool
+	var finds []string
+
+	cmd := &cobra.Command{
+		Use:   "grep <query> [dir...]",
+		Short: "Search file contents across the allowed roots with @grep: semantics",
+		Long: `Searches every file a rules line could include, across all allowed roots (or
+the given directories, which must be allowed), using the same engine as the
+@grep: directive: the query is a regular expression, or a literal string if
+it does not compile. Gitignored, binary and junk-directory files are skipped
+just as they are in resolution.
+
+--find narrows the files with an @find: query first, and --in-context limits
+the search to files in the current hot and cold context.`,
+		Example: `  cx grep 'func New.*Manager'
+  cx grep -i todo --find '*.go' pkg
+  cx grep -l deprecated --in-context`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := "grep"
+			if ignoreCase {
+				name = "grep-i"
+			}
+			var directives []context.SearchDirective
+			for _, q := range finds {
+				directives = append(directives, context.SearchDirective{Name: "find", Query: q})
+			}
+			directives = append(directives, context.SearchDirective{Name: name, Query: args[0]})
+			return runSearch(cmd, directives, args[1:], inContext, !filesOnly)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "Match case-insensitively (@grep-i:)")
+	cmd.Flags().BoolVarP(&filesOnly, "files-with-matches", "l", false, "Print only the names of matching files")
+	cmd.Flags().StringSliceVar(&finds, "find", nil, "Only search files matching this @find: query (repeatable)")
+	cmd.Flags().BoolVar(&inContext, "in-context", false, "Only search files in the current hot and cold context")
+
+	return cmd
+}
+
+// NewFindCmd creates the find command.
+func NewFindCmd() *cobra.Command {
+	var greps []string
+	var inContext bool
+
+	cmd := &cobra.Command{
+		Use:   "find <query> [dir...]",
+		Short: "Find files across the allowed roots with @find: semantics",
+		Long: `Lists the files, across all allowed roots (or the given directories, which
+must be allowed), that match an @find: query exactly as the directive does: a
+path substring, basename or path glob, name: or path: term, or a regular
+expression; comma-separated terms match any of them.
+
+--grep additionally requires the content to match, and --in-context limits
+the search to files in the current hot and cold context.`,
+		Example: `  cx find '*_handler.go'
+  cx find 'name:config' --grep 'yaml' pkg
+  cx find '**/testdata/*.json' --in-context`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			directives := []context.SearchDirective{{Name: "find", Query: args[0]}}
+			for _, q := range greps {
+				directives = append(directives, context.SearchDirective{Name: "grep", Query: q})
+			}
+			return runSearch(cmd, directives, args[1:], inContext, false)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&greps, "grep", nil, "Only list files whose content matches this @grep: query (repeatable)")
+	cmd.Flags().BoolVar(&inContext, "in-context", false, "Only search files in the current hot and cold context")
+
+	return cmd
+}
+
+// runSearch runs a search and prints the matches, relative to the working
+// directory where possible.
+func runSearch(cmd *cobra.Command, directives []context.SearchDirective, dirs []string, inContext, lines bool) error {
+	mgr := context.NewManager(GetWorkDir())
+	matches, err := mgr.Search(context.SearchOptions{
+		Directives: directives,
+		Roots:      dirs,
+		InContext:  inContext,
+		Lines:      lines,
+	})
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		if matches == nil {
+			matches = []context.SearchMatch{}
+		}
+		return writeJSON(cmd, matches)
+	}
+
+	out := cmd.OutOrStdout()
+	workDir := mgr.GetWorkDir()
+	for _, match := range matches {
+		path := match.Path
+		if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		if match.Line > 0 {
+			fmt.Fprintf(out, "%s:%d:%s\n", path, match.Line, match.Text)
+		} else {
+			fmt.Fprintln(out, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,501 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+//go:embed webui/index.html
+var webUIPage []byte
+
+// maxRulesBody bounds PUT /v1/rules and POST /v1/preview request bodies.
+const maxRulesBody = 1 << 20
+
+// serveTokenEnv supplies the bearer token for `cx serve --http` without
+// putting it on the command line (and thus in shell history and ps output).
+const serveTokenEnv = "CX_SERVE_TOKEN"
+
+func NewServeCmd() *cobra.Command {
+	var addr, token string
+	var allow []string
+	var ui, idle, allowCmd bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve context state over a local HTTP API",
+		Long: `Starts a local HTTP API so web tools and browser extensions can query
+context state. Responses use the same JSON schema as the corresponding --json
+output.
+
+Endpoints (all require "Authorization: Bearer <token>"):
+  GET  /v1/list                         hot/cold file list (cx list --json)
+  GET  /v1/stats?top=5&manifest_limit=100  stats envelope (cx stats --format compact)
+  GET  /v1/classify[?path=<file>]       per-file status: hot, cold, excluded, omitted
+  POST /v1/generate                     regenerate hot and cold context
+  GET  /v1/treemap                      hot-context tokens by directory
+  GET  /v1/rules, PUT /v1/rules         read or replace the active rules file
+  POST /v1/preview                      resolve posted rules without saving
+  GET  /v1/diff?ruleset=<name>          compare against a named rule set
+  GET  /v1/diff?snapshot=<name>         compare against a saved snapshot
+  GET  /v1/snapshots                    saved snapshots (cx list-snapshots --json)
+
+With --ui, a single-page web UI (token treemap, rules editor with live preview,
+and rule set diff) is served at /. The page itself carries no data; it reads
+the token from the URL fragment printed at startup and calls the API above.
+
+Rules sent to PUT /v1/rules and POST /v1/preview must parse, and no pattern
+may reach system directories or climb too far above the workspace. They may
+not contain @cmd: directives, which run shell commands, unless --allow-cmd is
+given.
+
+The listener only accepts clients whose address matches --allow (loopback by
+default). The token comes from --token or $CX_SERVE_TOKEN; if neither is set a
+random token is generated and printed at startup.
+
+Examples:
+  cx serve --http 127.0.0.1:8417
+  cx serve --http 127.0.0.1:8417 --ui
+  CX_SERVE_TOKEN=secret cx serve --http :8417 --allow 10.0.0.0/8`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if addr == "" {
+				return fmt.Errorf("--http <addr> is required")
+			}
+			// A long-running command prints warnings as they happen.
+			context.SetCollectWarnings(false)
+			if idle {
+				context.SetIdle(true)
+			}
+			allowed, err := parseAllowlist(allow)
+			if err != nil {
+				return err
+			}
+			if token == "" {
+				token = os.Getenv(serveTokenEnv)
+			}
+			if token == "" {
+				buf := make([]byte, 16)
+				if _, err := rand.Read(buf); err != nil {
+					return fmt.Errorf("failed to generate token: %w", err)
+				}
+				token = hex.EncodeToString(buf)
+				fmt.Fprintf(cmd.ErrOrStderr(), "Generated API token: %s\n", token)
+			}
+
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           newServeHandler(GetWorkDir(), token, allowed, ui, allowCmd),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Serving cx API on http://%s\n", addr)
+			if ui {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Web UI: http://%s/#token=%s\n", addr, token)
+			}
+			return srv.ListenAndServe()
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "http", "", "Address to listen on (e.g. 127.0.0.1:8417)")
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token required on every request (default: $"+serveTokenEnv+" or generated)")
+	cmd.Flags().BoolVar(&ui, "ui", false, "Also serve the web UI at /")
+	cmd.Flags().BoolVar(&allowCmd, "allow-cmd", false, "Accept @cmd: directives in rules sent to PUT /v1/rules and POST /v1/preview")
+	cmd.Flags().BoolVar(&idle, "idle", false, "Use one worker per pool and the lowest CPU/IO priority")
+	cmd.Flags().StringSliceVar(&allow, "allow", []string{"127.0.0.0/8", "::1/128"}, "Client IPs or CIDRs allowed to connect")
+
+	return cmd
+}
+
+// parseAllowlist turns IPs and CIDRs into networks; a bare IP is treated as a
+// single-host network.
+func parseAllowlist(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid --allow entry %q", e)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			e = fmt.Sprintf("%s/%d", e, bits)
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --allow entry %q: %w", e, err)
+		}
+		nets = append(nets, n)
+	}
+	if len(nets) == 0 {
+		return nil, fmt.Errorf("--allow must name at least one IP or CIDR")
+	}
+	return nets, nil
+}
+
+// serveHandler answers API requests for one workspace. Each request gets its
+// own Manager; mu serializes writes (generation, rules edits) so two clients
+// can't interleave them.
+type serveHandler struct {
+	workDir  string
+	token    string
+	allowed  []*net.IPNet
+	ui       bool
+	allowCmd bool // accept @cmd: in posted rules
+	mux      *http.ServeMux
+	mu       sync.Mutex
+}
+
+func newServeHandler(workDir, token string, allowed []*net.IPNet, ui, allowCmd bool) *serveHandler {
+	h := &serveHandler{workDir: workDir, token: token, allowed: allowed, ui: ui, allowCmd: allowCmd, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/v1/list", h.method(http.MethodGet, h.handleList))
+	h.mux.HandleFunc("/v1/stats", h.method(http.MethodGet, h.handleStats))
+	h.mux.HandleFunc("/v1/classify", h.method(http.MethodGet, h.handleClassify))
+	h.mux.HandleFunc("/v1/generate", h.method(http.MethodPost, h.handleGenerate))
+	h.mux.HandleFunc("/v1/treemap", h.method(http.MethodGet, h.handleTreemap))
+	h.mux.HandleFunc("/v1/rules", h.handleRules)
+	h.mux.HandleFunc("/v1/preview", h.method(http.MethodPost, h.handlePreview))
+	h.mux.HandleFunc("/v1/diff", h.method(http.MethodGet, h.handleDiff))
+	h.mux.HandleFunc("/v1/snapshots", h.method(http.MethodGet, h.handleSnapshots))
+	return h
+}
+
+func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !h.clientAllowed(net.ParseIP(host)) {
+		writeAPIError(w, http.StatusForbidden, "client address not allowed")
+		return
+	}
+	// The UI shell is static and holds no workspace data, so it is served
+	// without a token; every API call it makes still needs one.
+	if h.ui && r.Method == http.MethodGet && r.URL.Path == "/" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(webUIPage)
+		return
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+		writeAPIError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *serveHandler) clientAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range h.allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *serveHandler) method(method string, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAPIError(w, http.StatusMethodNotAllowed, "use "+method)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (h *serveHandler) manager(r *http.Request) *context.Manager {
+	mgr := context.NewManager(h.workDir)
+	mgr.SetContext(r.Context())
+	return mgr
+}
+
+func serveWorkspaceName(mgr *context.Manager) string {
+	if node, err := workspace.GetProjectByPath(mgr.GetWorkDir()); err == nil && node.Kind != workspace.KindNonGroveRepo {
+		return node.Identifier(":")
+	}
+	return ""
+}
+
+func (h *serveHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	mgr := h.manager(r)
+	hotFiles, coldFiles, rulesPath, err := resolveMachineFiles(mgr, "")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, buildMachineList(mgr, serveWorkspaceName(mgr), rulesPath, hotFiles, coldFiles))
+}
+
+func (h *serveHandler) handleStats(w http.ResponseWriter, r *http.Request) {
+	top, manifestLimit := 5, 100
+	for name, dst := range map[string]*int{"top": &top, "manifest_limit": &manifestLimit} {
+		if v := r.URL.Query().Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s %q", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+
+	mgr := h.manager(r)
+	hotFiles, coldFiles, rulesPath, err := resolveMachineFiles(mgr, "")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	envelope, err := buildMachineStats(mgr, serveWorkspaceName(mgr), rulesPath, hotFiles, coldFiles, top, manifestLimit)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, envelope)
+}
+
+type classifiedFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+func (h *serveHandler) handleClassify(w http.ResponseWriter, r *http.Request) {
+	mgr := h.manager(r)
+	statuses, err := mgr.ClassifyAllProjectFiles(false)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if p := r.URL.Query().Get("path"); p != "" {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(mgr.GetWorkDir(), p)
+		}
+		p = filepath.Clean(p)
+		status, ok := statuses[p]
+		if !ok {
+			status = context.StatusOmittedNoMatch
+		}
+		writeAPIJSON(w, classifiedFile{Path: p, Status: status.String()})
+		return
+	}
+
+	files := make([]classifiedFile, 0, len(statuses))
+	for path, status := range statuses {
+		if status == context.StatusDirectory {
+			continue
+		}
+		files = append(files, classifiedFile{Path: path, Status: status.String()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	writeAPIJSON(w, map[string]any{"schema_version": machineSchemaVersion, "files": files})
+}
+
+func (h *serveHandler) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := time.Now()
+	mgr := h.manager(r)
+	mgr.SetChecksums(context.LoadCxConfig(mgr.GetWorkDir()).Checksums)
+	if err := mgr.GenerateContext(true); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := mgr.GenerateCachedContext(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := mgr.RecordUsage("serve:generate", start); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record usage metrics: %v\n", err)
+	}
+	writeAPIJSON(w, mgr.LastGeneration())
+}
+
+func (h *serveHandler) handleTreemap(w http.ResponseWriter, r *http.Request) {
+	mgr := h.manager(r)
+	files, err := mgr.ResolveFilesFromRules()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, mgr.TokensByDirectory(files))
+}
+
+type rulesDocument struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func (h *serveHandler) handleRules(w http.ResponseWriter, r *http.Request) {
+	mgr := h.manager(r)
+	switch r.Method {
+	case http.MethodGet:
+		content, path, err := mgr.LoadRulesContent()
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAPIJSON(w, rulesDocument{Path: path, Content: string(content)})
+	case http.MethodPut:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRulesBody))
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := mgr.ValidateRulesContent(body, h.allowCmd); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid rules: "+err.Error())
+			return
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		path := mgr.ResolveRulesWritePath()
+		if err := os.WriteFile(path, body, 0o644); err != nil { //nolint:gosec // rules files are not sensitive
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAPIJSON(w, rulesDocument{Path: path, Content: string(body)})
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeAPIError(w, http.StatusMethodNotAllowed, "use GET or PUT")
+	}
+}
+
+type rulesPreview struct {
+	Files  []string `json:"files"`
+	Tokens int      `json:"tokens"`
+}
+
+// handlePreview resolves the posted rules content without touching the
+// active rules file, for the editor's live preview.
+func (h *serveHandler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRulesBody))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mgr := h.manager(r)
+	if err := mgr.ValidateRulesContent(body, h.allowCmd); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid rules: "+err.Error())
+		return
+	}
+	attribution, _, _, _, _, err := mgr.ResolveFilesWithAttribution(string(body))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	seen := make(map[string]bool)
+	preview := rulesPreview{Files: []string{}}
+	for _, files := range attribution {
+		for _, f := range files {
+			if !seen[f] {
+				seen[f] = true
+				preview.Files = append(preview.Files, f)
+			}
+		}
+	}
+	sort.Strings(preview.Files)
+	preview.Tokens = mgr.TokensByDirectory(preview.Files).Tokens
+	writeAPIJSON(w, preview)
+}
+
+func (h *serveHandler) handleDiff(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("ruleset")
+	if snapshot := r.URL.Query().Get("snapshot"); snapshot != "" {
+		if name != "" {
+			writeAPIError(w, http.StatusBadRequest, "use either ruleset or snapshot")
+			return
+		}
+		h.handleSnapshotDiff(w, r, snapshot)
+		return
+	}
+	if name == "" {
+		name = "empty"
+	}
+	diff, err := h.manager(r).DiffContext(name)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeAPIJSON(w, diff)
+}
+
+// handleSnapshotDiff compares the current context with the snapshot saved
+// as name. Only names listed by /v1/snapshots are accepted, so a client
+// cannot have the server read an arbitrary file as a snapshot.
+func (h *serveHandler) handleSnapshotDiff(w http.ResponseWriter, r *http.Request, name string) {
+	mgr := h.manager(r)
+	snapshots, err := mgr.ListSnapshots()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, s := range snapshots {
+		if s.Name != name {
+			continue
+		}
+		snap, err := mgr.LoadSnapshot(s.Path)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		diff, err := mgr.DiffSnapshot(snap)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAPIJSON(w, diff)
+		return
+	}
+	writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no snapshot named %q", name))
+}
+
+func (h *serveHandler) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.manager(r).ListSnapshots()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if snapshots == nil {
+		snapshots = []context.SnapshotInfo{}
+	}
+	writeAPIJSON(w, map[string]any{"schema_version": machineSchemaVersion, "snapshots": snapshots})
+}
+
+func writeAPIJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
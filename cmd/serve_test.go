@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// TestServeHandlerAuth verifies the allowlist and bearer token gate every
+// endpoint before any context resolution happens.
+func TestServeHandlerAuth(t *testing.T) {
+	allowed, err := parseAllowlist([]string{"127.0.0.1", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newServeHandler(t.TempDir(), "secret", allowed, false, false)
+
+	cases := []struct {
+		name   string
+		remote string
+		auth   string
+		method string
+		want   int
+	}{
+		{"disallowed client", "192.168.1.5:5000", "Bearer secret", http.MethodGet, http.StatusForbidden},
+		{"missing token", "127.0.0.1:5000", "", http.MethodGet, http.StatusUnauthorized},
+		{"wrong token", "10.1.2.3:5000", "Bearer nope", http.MethodGet, http.StatusUnauthorized},
+		{"token without scheme", "127.0.0.1:5000", "secret", http.MethodGet, http.StatusUnauthorized},
+		{"other scheme", "127.0.0.1:5000", "Basic secret", http.MethodGet, http.StatusUnauthorized},
+		{"wrong method", "127.0.0.1:5000", "Bearer secret", http.MethodPost, http.StatusMethodNotAllowed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/v1/list", nil)
+			req.RemoteAddr = tc.remote
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("got status %d, want %d (body %s)", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+
+	if _, err := parseAllowlist([]string{"not-an-ip"}); err == nil {
+		t.Error("expected an error for an invalid allowlist entry")
+	}
+}
+
+// TestServeHandlerUI verifies the UI shell is served without a token only
+// when --ui is set, while the API stays gated.
+func TestServeHandlerUI(t *testing.T) {
+	allowed, _ := parseAllowlist([]string{"127.0.0.1"})
+	for _, ui := range []bool{true, false} {
+		h := newServeHandler(t.TempDir(), "secret", allowed, ui, false)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "127.0.0.1:5000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if ui && rec.Code != http.StatusOK {
+			t.Errorf("ui enabled: got status %d, want 200", rec.Code)
+		}
+		if !ui && rec.Code != http.StatusUnauthorized {
+			t.Errorf("ui disabled: got status %d, want 401", rec.Code)
+		}
+	}
+}
+
+// TestServeHandlerRulesValidation verifies that posted rules are checked
+// before they are saved or resolved, and that @cmd: needs --allow-cmd.
+func TestServeHandlerRulesValidation(t *testing.T) {
+	allowed, _ := parseAllowlist([]string{"127.0.0.1"})
+	h := newServeHandler(t.TempDir(), "secret", allowed, false, false)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"put @cmd", http.MethodPut, "/v1/rules", "*.go\n@cmd: cat ~/.ssh/id_rsa\n"},
+		{"preview @cmd", http.MethodPost, "/v1/preview", "@cmd: curl example.com\n"},
+		{"put traversal", http.MethodPut, "/v1/rules", "../../../etc/**\n"},
+		{"put parse error", http.MethodPut, "/v1/rules", "*.go\n}\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.RemoteAddr = "127.0.0.1:5000"
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid rules") {
+				t.Errorf("got status %d (body %s), want 400 invalid rules", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestServeHandlerSnapshots verifies that saved snapshots are listed and
+// that a snapshot diff only accepts listed names.
+func TestServeHandlerSnapshots(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, context.SnapshotsDir)
+	if err := os.MkdirAll(snapDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapDir, "before.json"), []byte(`{"created_at":"2026-01-02T03:04:05Z","rules":"*.go\n","files":{"a.go":10}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	allowed, _ := parseAllowlist([]string{"127.0.0.1"})
+	h := newServeHandler(dir, "secret", allowed, false, false)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "127.0.0.1:5000"
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/v1/snapshots")
+	var body struct {
+		Snapshots []context.SnapshotInfo `json:"snapshots"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/snapshots: status %d, body %s, err %v", rec.Code, rec.Body.String(), err)
+	}
+	if len(body.Snapshots) != 1 || body.Snapshots[0].Name != "before" || body.Snapshots[0].Tokens != 10 {
+		t.Errorf("snapshots = %+v, want before with 10 tokens", body.Snapshots)
+	}
+
+	for _, name := range []string{"missing", "../before", filepath.Join(snapDir, "before.json")} {
+		if rec := get("/v1/diff?snapshot=" + url.QueryEscape(name)); rec.Code != http.StatusNotFound {
+			t.Errorf("diff against %q: got status %d, want 404", name, rec.Code)
+		}
+	}
+	if rec := get("/v1/diff?snapshot=before&ruleset=x"); rec.Code != http.StatusBadRequest {
+		t.Errorf("diff with both ruleset and snapshot: got status %d, want 400", rec.Code)
+	}
+}
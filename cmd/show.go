@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"text/tabwriter"
 
+	"github.com/grovetools/core/cli"
 	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/pkg/context"
@@ -11,11 +13,16 @@ import (
 
 func NewShowCmd() *cobra.Command {
 	var jobFile, rulesFile string
+	var files bool
 
 	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Print the entire context file",
-		Long:  `Outputs the contents of .grove/context for piping to other applications.`,
+		Long: `Outputs the contents of .grove/context for piping to other applications.
+
+With --files, lists the files in the generated hot and cached (cold) context
+instead, with each file's estimated tokens; add --json for machine-readable
+output.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := context.NewManager(GetWorkDir())
 			mgr.SetContext(cmd.Context())
@@ -33,11 +40,15 @@ func NewShowCmd() *cobra.Command {
 			} else {
 				warnIfRulesStale(mgr)
 			}
+			if files {
+				return showContextFiles(cmd, mgr)
+			}
 			return mgr.ShowContext()
 		},
 	}
 
 	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().BoolVar(&files, "files", false, "List the files in the generated context with their token counts instead of printing it")
 
 	return cmd
 }
@@ -57,3 +68,55 @@ func warnIfRulesStale(mgr *context.Manager) {
 		fmt.Fprintln(os.Stderr, "⚠ rules edited since last generate — run `cx generate` to refresh")
 	}
 }
+
+// artifactFileSet is the --files listing of one generated artifact.
+type artifactFileSet struct {
+	ContextType string                 `json:"context_type"`
+	Artifact    string                 `json:"artifact"`
+	TotalTokens int                    `json:"total_tokens"`
+	Files       []context.ArtifactFile `json:"files"`
+}
+
+// showContextFiles lists the files in the generated hot and cold artifacts
+// that exist on disk.
+func showContextFiles(cmd *cobra.Command, mgr *context.Manager) error {
+	sets := []artifactFileSet{}
+	for _, a := range []struct{ contextType, path string }{
+		{"hot", mgr.ResolveContextPath()},
+		{"cold", mgr.ResolveCachedContextPath()},
+	} {
+		files, err := context.ArtifactFiles(a.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		set := artifactFileSet{ContextType: a.contextType, Artifact: a.path, Files: files}
+		for _, f := range files {
+			set.TotalTokens += f.Tokens
+		}
+		sets = append(sets, set)
+	}
+
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, map[string]interface{}{
+			"schema_version": machineSchemaVersion,
+			"contexts":       sets,
+		})
+	}
+	if len(sets) == 0 {
+		return fmt.Errorf("no generated context found. Run 'grove cx generate' to create it")
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTEXT\tTOKENS\tFILE")
+	for _, set := range sets {
+		for _, f := range set.Files {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", set.ContextType, context.FormatTokenCount(f.Tokens), f.Path)
+		}
+	}
+	for _, set := range sets {
+		fmt.Fprintf(w, "%s\t%s\t(%d files)\n", set.ContextType, context.FormatTokenCount(set.TotalTokens), len(set.Files))
+	}
+	return w.Flush()
+}
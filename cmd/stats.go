@@ -9,8 +9,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/grovetools/core/cli"
 	"github.com/grovetools/core/pkg/repo"
 	"github.com/grovetools/core/pkg/workspace"
@@ -33,8 +35,9 @@ func NewStatsCmd() *cobra.Command {
 		TotalTokens    int `json:"total_tokens"`
 	}
 
-	var jobFile, rulesFileFlag, outputFormat string
-	var manifestLimit int
+	var jobFile, rulesFileFlag, outputFormat, compareRef, saveSnapshot, rulesetPattern, tokenizerCmd string
+	var manifestLimit, calibrateSamples int
+	var usage, treemap, calibrate, bySection bool
 
 	cmd := &cobra.Command{
 		Use:   "stats [rules-file]",
@@ -47,10 +50,29 @@ Otherwise, stats will be computed from the active rules file (run 'cx rules wher
 Examples:
   cx stats                              # Use the active rules file
   cx stats plans/my-plan/rules/job.rules  # Use custom rules file
-  cx stats --job 02-spec.md             # Use job's saved rules`,
+  cx stats --job 02-spec.md             # Use job's saved rules
+  cx stats --usage                      # Weekly trends from .grove/metrics.jsonl
+  cx stats --treemap                    # Hot-context tokens by directory as a treemap
+  cx stats --save-snapshot monday       # Record the hot context for later comparison
+  cx stats --compare monday             # Attribute token growth since the snapshot
+  cx stats --ruleset "*"                # Compare every named rule set side by side
+  cx stats --calibrate --tokenizer-cmd "ttok -c"  # Learn per-extension token ratios
+  cx stats --sections                   # One breakdown per rules section ('--- <name>')`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := context.NewManager(GetWorkDir())
+			if usage {
+				return outputUsageStats(cmd, mgr)
+			}
+			if saveSnapshot != "" {
+				return saveStatsSnapshot(cmd, mgr, saveSnapshot)
+			}
+			if compareRef != "" {
+				return outputGrowth(cmd, mgr, compareRef)
+			}
+			if rulesetPattern != "" {
+				return outputRulesetComparison(cmd, mgr, rulesetPattern)
+			}
 			if outputFormat != "" && outputFormat != "compact" {
 				return fmt.Errorf("unsupported stats format %q (supported: compact)", outputFormat)
 			}
@@ -155,15 +177,11 @@ Examples:
 				}
 			} else {
 				// Use default behavior - resolve from active rules
-				hotFiles, err = mgr.ResolveFilesFromRules()
-				if err != nil {
-					return err
-				}
-
-				coldFiles, err = mgr.ResolveColdContextFiles()
+				session, err := mgr.NewResolutionSession()
 				if err != nil {
 					return err
 				}
+				hotFiles, coldFiles = session.Hot, session.Cold
 			}
 
 			// Populate workspace and rules identity for both legacy and machine output.
@@ -176,6 +194,16 @@ Examples:
 				rulesDisplay = mgr.ResolveRulesPath()
 			}
 
+			if treemap {
+				return outputTreemap(cmd, mgr, hotFiles)
+			}
+			if calibrate {
+				return outputCalibration(cmd, mgr, append(append([]string{}, hotFiles...), coldFiles...), tokenizerCmd, calibrateSamples)
+			}
+			if bySection {
+				return outputSectionStats(cmd, mgr, targetRulesFile, workspaceName, rulesDisplay)
+			}
+
 			// The compact form is an opt-in top-level machine envelope. Unlike
 			// legacy --json it always includes hot and cold records (including
 			// empty ones), honest resolved/readable counts, and unreadable paths.
@@ -253,6 +281,15 @@ Examples:
 	cmd.Flags().StringVar(&outputFormat, "format", "", "Machine output format (compact)")
 	cmd.Flags().IntVar(&manifestLimit, "manifest-limit", 100, "Maximum file and unreadable-file paths per context in compact output")
 	cmd.Flags().BoolVar(&perLine, "per-line", false, "Provide stats for each line in the rules file")
+	cmd.Flags().BoolVar(&treemap, "treemap", false, "Render hot-context token usage by directory as a treemap")
+	cmd.Flags().StringVar(&compareRef, "compare", "", "Attribute hot-context token growth since a snapshot (name or path) or named rule set")
+	cmd.Flags().StringVar(&rulesetPattern, "ruleset", "", "Compare named rule sets matching a glob (\"*\" for all): sizes and overlap")
+	cmd.Flags().StringVar(&saveSnapshot, "save-snapshot", "", "Save the current hot context as a snapshot (name or path) for --compare")
+	cmd.Flags().BoolVar(&calibrate, "calibrate", false, "Learn per-extension token ratios by running an exact tokenizer over sample context files")
+	cmd.Flags().StringVar(&tokenizerCmd, "tokenizer-cmd", "", "Command for --calibrate that reads a file on stdin and prints its token count (default cx.tokenizer_command)")
+	cmd.Flags().IntVar(&calibrateSamples, "samples", 5, "Files per extension to tokenize for --calibrate")
+	cmd.Flags().BoolVar(&bySection, "sections", false, "Break statistics down by rules section (hot, cold, and each '--- <name>' section)")
+	cmd.Flags().BoolVar(&usage, "usage", false, "Summarize recorded usage metrics by week (enable with cx.metrics or CX_METRICS=1)")
 	cmd.Flags().StringVar(&chatFile, "chat-file", "", "Legacy alias for --job")
 	_ = cmd.Flags().MarkHidden("chat-file")
 	AddRulesFileFlags(cmd, &jobFile, &rulesFileFlag)
@@ -260,6 +297,239 @@ Examples:
 	return cmd
 }
 
+// outputTreemap handles the --treemap flag. The map fills the terminal width
+// ($COLUMNS, default 80) at a fixed height; JSON output emits the underlying
+// directory token tree instead.
+func outputTreemap(cmd *cobra.Command, mgr *context.Manager, hotFiles []string) error {
+	tree := mgr.TokensByDirectory(hotFiles)
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, tree)
+	}
+	width := 80
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 20 {
+		width = cols
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Hot Context Token Treemap (~%s tokens)\n\n", context.FormatTokenCount(tree.Tokens))
+	fmt.Fprint(cmd.OutOrStdout(), context.RenderTreemap(tree, width, 16))
+	return nil
+}
+
+// outputSectionStats handles the --sections flag: one statistics block per
+// section of the rules file that contributed files.
+func outputSectionStats(cmd *cobra.Command, mgr *context.Manager, rulesFile, workspaceName, rulesDisplay string) error {
+	sections, err := mgr.ResolveSectionFiles(rulesFile)
+	if err != nil {
+		return err
+	}
+	allStats := []*context.ContextStats{}
+	var titles []string
+	for _, s := range sections {
+		if len(s.Files) == 0 {
+			continue
+		}
+		stats, err := mgr.GetStats(s.Name, s.Files, topN)
+		if err != nil {
+			return err
+		}
+		stats.WorkspaceName = workspaceName
+		stats.RulesPath = rulesDisplay
+		allStats = append(allStats, stats)
+		titles = append(titles, fmt.Sprintf("Section '%s' (%s) Statistics", s.Name, s.Tier))
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, allStats)
+	}
+	if len(allStats) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No files in context. Check your rules file.")
+		return nil
+	}
+	for i, stats := range allStats {
+		if i > 0 {
+			fmt.Print("\n──────────────────────────────────────────────────\n\n")
+		}
+		stats.Print(titles[i])
+	}
+	return nil
+}
+
+// outputCalibration handles the --calibrate flag: it tokenizes sample
+// files exactly and reports the learned ratios next to the built-in ones.
+func outputCalibration(cmd *cobra.Command, mgr *context.Manager, files []string, command string, samples int) error {
+	if command == "" {
+		command = context.LoadCxConfig(mgr.GetWorkDir()).TokenizerCommand
+	}
+	cal, err := mgr.CalibrateTokens(files, command, samples)
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, cal)
+	}
+
+	out := cmd.OutOrStdout()
+	exts := make([]string, 0, len(cal.Extensions))
+	for ext := range cal.Extensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	fmt.Fprintf(out, "%-12s  %5s  %10s  %13s\n", "EXTENSION", "FILES", "TOKENS", "BYTES/TOKEN")
+	for _, ext := range exts {
+		c := cal.Extensions[ext]
+		name := ext
+		if name == "" {
+			name = "(none)"
+		}
+		fmt.Fprintf(out, "%-12s  %5d  %10d  %13.2f\n", name, c.Files, c.Tokens, c.BytesPerToken)
+	}
+	fmt.Fprintf(out, "\nSaved to %s; estimates now use these ratios unless cx.token_ratios overrides them.\n", context.TokenCalibrationPath())
+	return nil
+}
+
+// outputRulesetComparison handles the --ruleset flag: one row per matching
+// rule set, then the files each pair has in common.
+func outputRulesetComparison(cmd *cobra.Command, mgr *context.Manager, pattern string) error {
+	comparison, err := mgr.CompareRulesets(pattern)
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, comparison)
+	}
+
+	out := cmd.OutOrStdout()
+	nameWidth := len("RULESET")
+	for _, rs := range comparison.Rulesets {
+		if w := lipgloss.Width(rs.Name); w > nameWidth {
+			nameWidth = w
+		}
+	}
+	fmt.Fprintf(out, "%s  %9s  %10s  %10s  %11s\n", context.PadWidth("RULESET", nameWidth), "HOT FILES", "HOT TOKENS", "COLD FILES", "COLD TOKENS")
+	for _, rs := range comparison.Rulesets {
+		if rs.Error != "" {
+			fmt.Fprintf(out, "%s  error: %s\n", context.PadWidth(rs.Name, nameWidth), rs.Error)
+			continue
+		}
+		fmt.Fprintf(out, "%s  %9d  %10s  %10d  %11s\n", context.PadWidth(rs.Name, nameWidth),
+			rs.HotFiles, "~"+context.FormatTokenCount(rs.HotTokens),
+			rs.ColdFiles, "~"+context.FormatTokenCount(rs.ColdTokens))
+	}
+
+	if len(comparison.Rulesets) < 2 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nOverlap:")
+	if len(comparison.Overlaps) == 0 {
+		fmt.Fprintln(out, "  No files shared between rule sets.")
+		return nil
+	}
+	for _, o := range comparison.Overlaps {
+		fmt.Fprintf(out, "  %s ∩ %s: %d files (~%s tokens)\n", o.A, o.B, o.SharedFiles, context.FormatTokenCount(o.SharedTokens))
+	}
+	return nil
+}
+
+// saveStatsSnapshot handles the --save-snapshot flag.
+func saveStatsSnapshot(cmd *cobra.Command, mgr *context.Manager, ref string) error {
+	snap, err := mgr.TakeSnapshot()
+	if err != nil {
+		return err
+	}
+	path, err := mgr.SaveSnapshot(snap, ref)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, map[string]interface{}{"path": path, "files": len(snap.Files), "tokens": snap.Tokens()})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved snapshot of %d files (~%s tokens) to %s\n", len(snap.Files), context.FormatTokenCount(snap.Tokens()), path)
+	return nil
+}
+
+// outputGrowth handles the --compare flag: which rules lines and directories
+// account for the change in hot-context tokens since a snapshot.
+func outputGrowth(cmd *cobra.Command, mgr *context.Manager, ref string) error {
+	base, err := mgr.LoadSnapshot(ref)
+	if err != nil {
+		return err
+	}
+	report, err := mgr.AttributeGrowth(base)
+	if err != nil {
+		return err
+	}
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, report)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "%s tokens since %s (%s → %s)\n", signedTokens(report.DeltaTokens), ref,
+		context.FormatTokenCount(report.BaselineTokens), context.FormatTokenCount(report.CurrentTokens))
+	if len(report.ByRule) == 0 {
+		fmt.Fprintln(out, "No change in the hot context.")
+		return nil
+	}
+
+	fmt.Fprintln(out, "\nBy rule:")
+	for _, s := range report.ByRule {
+		label := "files no longer matched by any rule"
+		if s.LineNumber > 0 {
+			label = fmt.Sprintf("%s (line %d", s.Rule, s.LineNumber)
+			if s.NewRule {
+				label += ", new rule"
+			}
+			label += ")"
+		}
+		fmt.Fprintf(out, "  %8s  %s%s\n", signedTokens(s.DeltaTokens), growthShare(s.DeltaTokens, report.DeltaTokens), label)
+	}
+	fmt.Fprintln(out, "\nBy directory:")
+	for _, s := range report.ByDirectory {
+		files := "files"
+		if s.Files == 1 {
+			files = "file"
+		}
+		fmt.Fprintf(out, "  %8s  %s%s (%d %s)\n", signedTokens(s.DeltaTokens), growthShare(s.DeltaTokens, report.DeltaTokens),
+			s.Directory, s.Files, files)
+	}
+	return nil
+}
+
+// signedTokens formats a token delta with an explicit sign, e.g. "+38k".
+func signedTokens(n int) string {
+	if n < 0 {
+		return "-" + context.FormatTokenCount(-n)
+	}
+	return "+" + context.FormatTokenCount(n)
+}
+
+// growthShare renders a bucket's share of the total change ("80% from ")
+// when both move in the same direction; otherwise it is omitted.
+func growthShare(delta, total int) string {
+	if total == 0 || (delta > 0) != (total > 0) {
+		return ""
+	}
+	return fmt.Sprintf("%d%% from ", delta*100/total)
+}
+
+// outputUsageStats handles the --usage flag: a weekly summary of the local
+// metrics log written by `cx generate` when metrics are enabled.
+func outputUsageStats(cmd *cobra.Command, mgr *context.Manager) error {
+	metrics, err := context.ReadUsageMetrics(mgr.MetricsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read usage metrics: %w", err)
+	}
+	weeks := context.SummarizeUsageByWeek(metrics)
+
+	if cli.GetOptions(cmd).JSONOutput {
+		return writeJSON(cmd, weeks)
+	}
+	if len(weeks) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No usage metrics recorded in %s.\n", context.MetricsFile)
+		fmt.Fprintln(cmd.OutOrStdout(), "Enable recording with 'cx: {metrics: true}' in grove.yml or CX_METRICS=1.")
+		return nil
+	}
+	context.PrintUsageSummary(cmd.OutOrStdout(), weeks)
+	return nil
+}
+
 // outputPerLineStats handles the --per-line flag logic
 func outputPerLineStats(args []string) error {
 	if len(args) == 0 {
@@ -293,7 +563,7 @@ func outputPerLineStats(args []string) error {
 	}
 
 	mgr := context.NewManager(workDir)
-	attribution, _, exclusions, filteredMatches, excludedByResult, err := mgr.ResolveFilesWithAttribution(string(rulesContent))
+	attribution, rawRules, exclusions, filteredMatches, excludedByResult, err := mgr.ResolveFilesWithAttribution(string(rulesContent))
 	if err != nil {
 		return fmt.Errorf("failed to analyze rules: %w", err)
 	}
@@ -318,19 +588,21 @@ func outputPerLineStats(args []string) error {
 	}
 
 	type PerLineStat struct {
-		LineNumber        int              `json:"lineNumber"`
-		Rule              string           `json:"rule"`
-		FileCount         int              `json:"fileCount"`
-		ExcludedFileCount int              `json:"excludedFileCount,omitempty"`
-		ExcludedTokens    int              `json:"excludedTokens,omitempty"`
-		FilteredByLine    []FilteredByLine `json:"filteredByLine,omitempty"`
-		ExcludedByLine    []ExcludedByLine `json:"excludedByLine,omitempty"`
-		TotalTokens       int              `json:"totalTokens"`
-		TotalSize         int64            `json:"totalSize"`
-		GitInfo           *GitInfo         `json:"gitInfo,omitempty"`
-		ResolvedPaths     []string         `json:"resolvedPaths"`
-		SkipReason        string           `json:"skipReason,omitempty"`
-		Severity          string           `json:"severity,omitempty"`
+		LineNumber        int                   `json:"lineNumber"`
+		Rule              string                `json:"rule"`
+		FileCount         int                   `json:"fileCount"`
+		ExcludedFileCount int                   `json:"excludedFileCount,omitempty"`
+		ExcludedTokens    int                   `json:"excludedTokens,omitempty"`
+		FilteredByLine    []FilteredByLine      `json:"filteredByLine,omitempty"`
+		ExcludedByLine    []ExcludedByLine      `json:"excludedByLine,omitempty"`
+		ClauseMatches     []context.ClauseMatch `json:"clauseMatches,omitempty"`
+		TotalTokens       int                   `json:"totalTokens"`
+		TotalSize         int64                 `json:"totalSize"`
+		GitInfo           *GitInfo              `json:"gitInfo,omitempty"`
+		ResolvedPaths     []string              `json:"resolvedPaths"`
+		Annotation        string                `json:"annotation,omitempty"`
+		SkipReason        string                `json:"skipReason,omitempty"`
+		Severity          string                `json:"severity,omitempty"`
 	}
 
 	statsProvider := context.GetStatsProvider()
@@ -348,14 +620,27 @@ func outputPerLineStats(args []string) error {
 			strings.HasPrefix(line, "@freeze-cache") ||
 			strings.HasPrefix(line, "@no-expire") || strings.HasPrefix(line, "@disable-cache") ||
 			strings.HasPrefix(line, "@expire-time") || strings.HasPrefix(line, "@find:") ||
-			strings.HasPrefix(line, "@grep:")
-
-		if line != "" && !strings.HasPrefix(line, "#") && !isConfigDirective && line != "---" {
-			ruleMap[lineNum] = line
+			strings.HasPrefix(line, "@grep:") || strings.HasPrefix(line, "@require:") ||
+			strings.HasPrefix(line, "@max-age:") || strings.HasPrefix(line, "@budget:") || strings.HasPrefix(line, "@allow-path:") ||
+			line == "@symbol-index"
+
+		_, isSeparator := context.ParseSectionSeparator(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !isConfigDirective && !isSeparator {
+			rule, _ := context.SplitRuleAnnotation(line)
+			ruleMap[lineNum] = rule
 		}
 		lineNum++
 	}
 
+	// Inline directives per line, for attributing files to the clause of an
+	// @or/@any-of filter that admitted them.
+	lineDirectives := make(map[int][]context.SearchDirective)
+	for _, r := range rawRules {
+		if _, ok := lineDirectives[r.LineNum]; !ok && len(r.Directives) > 0 {
+			lineDirectives[r.LineNum] = r.Directives
+		}
+	}
+
 	// Load repo manifest for git alias info
 	repoManager, err := repo.NewManager()
 	var manifest *repo.Manifest
@@ -472,6 +757,7 @@ func outputPerLineStats(args []string) error {
 			ExcludedFileCount: len(exclusions[lineNum]),
 			ExcludedTokens:    excludedTokens,
 			FilteredByLine:    filteredByLine,
+			ClauseMatches:     mgr.AttributeDirectiveClauses(files, lineDirectives[lineNum]),
 			TotalTokens:       totalTokens,
 			TotalSize:         totalSize,
 			GitInfo:           gitInfo,
@@ -747,6 +1033,12 @@ func outputPerLineStats(args []string) error {
 		}
 	}
 
+	// Attach `# why:` annotations so exports carry the rule's rationale.
+	annotations := context.RuleAnnotations(rulesContent)
+	for i := range results {
+		results[i].Annotation = annotations[results[i].LineNumber]
+	}
+
 	// Enrich results with excludedByLine data from the resolver
 	for i := range results {
 		if infos, ok := excludedByResult[results[i].LineNumber]; ok {
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewSuggestTieringCmd creates the suggest-tiering command.
+func NewSuggestTieringCmd() *cobra.Command {
+	var minTracked time.Duration
+	var minTouches int
+	var recordOpen bool
+
+	cmd := &cobra.Command{
+		Use:   "suggest-tiering [--record-open <file>...]",
+		Short: "Suggest hot/cold moves based on which files you actually touch",
+		Long: `Proposes demoting hot files you never edit or open to cold context, and
+promoting cold files you work on often to hot.
+
+Activity is recorded locally in .grove/access.json and only when enabled with
+'cx: {access_tracking: true}' in grove.yml or CX_ACCESS_TRACKING=1. Each
+generation (and each run of this command) counts an edit for every included
+file modified since it was last seen. Editors can also report opens:
+
+  cx suggest-tiering --record-open path/to/file.go
+
+Suggestions are only proposed; edit the rules file to apply them.`,
+		Example: `  cx suggest-tiering
+  cx suggest-tiering --min-tracked 168h --min-touches 5
+  cx suggest-tiering --record-open internal/api/handler.go`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			if recordOpen {
+				if len(args) == 0 {
+					return fmt.Errorf("--record-open needs at least one file")
+				}
+				return mgr.RecordFileOpens(args)
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("unexpected arguments %v (did you mean --record-open?)", args)
+			}
+
+			session, err := mgr.NewResolutionSession()
+			if err != nil {
+				return err
+			}
+			hot, cold := session.Hot, session.Cold
+
+			enabled := context.AccessTrackingEnabled(mgr.GetWorkDir())
+			if enabled {
+				if err := mgr.TrackAccess(append(append([]string{}, hot...), cold...)); err != nil {
+					return err
+				}
+			}
+			log, err := context.ReadAccessLog(mgr.AccessPath())
+			if err != nil {
+				return err
+			}
+			suggestions := mgr.SuggestTiering(log, hot, cold, minTracked, minTouches)
+
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, suggestions)
+			}
+			out := cmd.OutOrStdout()
+			if !enabled && len(log.Files) == 0 {
+				fmt.Fprintf(out, "No file activity recorded in %s.\n", context.AccessFile)
+				fmt.Fprintln(out, "Enable tracking with 'cx: {access_tracking: true}' in grove.yml or CX_ACCESS_TRACKING=1.")
+				return nil
+			}
+			if len(suggestions) == 0 {
+				fmt.Fprintln(out, "No tiering changes suggested.")
+				return nil
+			}
+			for _, s := range suggestions {
+				fmt.Fprintf(out, "%-4s → %-4s  %8s  %s (%s)\n", s.From, s.To,
+					"~"+context.FormatTokenCount(s.Tokens), s.File, s.Reason)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&minTracked, "min-tracked", 72*time.Hour, "Only suggest demoting hot files tracked for at least this long")
+	cmd.Flags().IntVar(&minTouches, "min-touches", 3, "Edits plus opens a cold file needs before promotion is suggested")
+	cmd.Flags().BoolVar(&recordOpen, "record-open", false, "Record the given files as opened (for editor integrations) and exit")
+
+	return cmd
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+const testPatternHelp = `Type a rule line (pattern plus optional directives) to see what it matches.
+
+  :n, <enter>       next page of the last result
+  :p                previous page
+  :global <line>    keep a global line (e.g. @grep: "TODO") in front of every rule
+  :globals          list the kept global lines
+  :clear            drop all kept global lines
+  :help             show this help
+  :q, :quit         exit
+`
+
+// patternResult is one evaluated rule: the matched files (relative to the
+// work dir where possible), their token total and how long resolution took.
+type patternResult struct {
+	files   []string
+	tokens  int
+	elapsed time.Duration
+}
+
+// patternREPL is the read-eval-print loop behind cx test-pattern. eval is
+// injected so the loop can be exercised without a project on disk.
+type patternREPL struct {
+	eval     func(rules string) (*patternResult, error)
+	pageSize int
+	globals  []string
+	last     *patternResult
+	page     int
+}
+
+func (r *patternREPL) run(in io.Reader, out io.Writer, prompt bool) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if prompt {
+			fmt.Fprint(out, "cx> ")
+		}
+		if !scanner.Scan() {
+			if prompt {
+				fmt.Fprintln(out)
+			}
+			return scanner.Err()
+		}
+		if quit := r.handle(strings.TrimSpace(scanner.Text()), out); quit {
+			return nil
+		}
+	}
+}
+
+// handle processes one input line and reports whether the loop should end.
+func (r *patternREPL) handle(line string, out io.Writer) bool {
+	switch {
+	case line == ":q" || line == ":quit":
+		return true
+	case line == "" || line == ":n":
+		if r.last != nil && (r.page+1)*r.pageSize < len(r.last.files) {
+			r.page++
+		}
+		r.printPage(out)
+	case line == ":p":
+		if r.page > 0 {
+			r.page--
+		}
+		r.printPage(out)
+	case line == ":help":
+		fmt.Fprint(out, testPatternHelp)
+	case line == ":globals":
+		if len(r.globals) == 0 {
+			fmt.Fprintln(out, "No global lines.")
+		}
+		for _, g := range r.globals {
+			fmt.Fprintln(out, g)
+		}
+	case line == ":clear":
+		r.globals = nil
+		fmt.Fprintln(out, "Cleared global lines.")
+	case strings.HasPrefix(line, ":global "):
+		g := strings.TrimSpace(strings.TrimPrefix(line, ":global "))
+		r.globals = append(r.globals, g)
+		fmt.Fprintf(out, "Added global line: %s\n", g)
+	case strings.HasPrefix(line, ":"):
+		fmt.Fprintf(out, "Unknown command %q (try :help)\n", line)
+	default:
+		rules := strings.Join(append(append([]string{}, r.globals...), line), "\n")
+		res, err := r.eval(rules)
+		if err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+			return false
+		}
+		r.last, r.page = res, 0
+		fmt.Fprintf(out, "%d files, ~%s tokens (%s)\n", len(res.files), context.FormatTokenCount(res.tokens), res.elapsed.Round(time.Millisecond))
+		r.printPage(out)
+	}
+	return false
+}
+
+func (r *patternREPL) printPage(out io.Writer) {
+	if r.last == nil || len(r.last.files) == 0 {
+		return
+	}
+	start := r.page * r.pageSize
+	end := start + r.pageSize
+	if end > len(r.last.files) {
+		end = len(r.last.files)
+	}
+	for _, f := range r.last.files[start:end] {
+		fmt.Fprintf(out, "  %s\n", f)
+	}
+	if len(r.last.files) > r.pageSize {
+		pages := (len(r.last.files) + r.pageSize - 1) / r.pageSize
+		fmt.Fprintf(out, "-- page %d/%d (:n next, :p previous) --\n", r.page+1, pages)
+	}
+}
+
+// evalPatternRules resolves a rules snippet against the project without
+// touching any rules file.
+func evalPatternRules(mgr *context.Manager, workDir string) func(string) (*patternResult, error) {
+	return func(rules string) (*patternResult, error) {
+		start := time.Now()
+		if _, errs := context.ParseToAST([]byte(rules)); len(errs) > 0 {
+			return nil, fmt.Errorf("%s", errs[0].Msg)
+		}
+		attribution, _, _, _, _, err := mgr.ResolveFilesWithAttribution(rules)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		res := &patternResult{}
+		stats := context.GetStatsProvider()
+		for _, files := range attribution {
+			for _, f := range files {
+				if seen[f] {
+					continue
+				}
+				seen[f] = true
+				if info, err := stats.GetFileStats(f); err == nil {
+					res.tokens += info.Tokens
+				}
+				if rel, err := filepath.Rel(workDir, f); err == nil && !strings.HasPrefix(rel, "..") {
+					f = rel
+				}
+				res.files = append(res.files, f)
+			}
+		}
+		sort.Strings(res.files)
+		res.elapsed = time.Since(start)
+		return res, nil
+	}
+}
+
+func NewTestPatternCmd() *cobra.Command {
+	var pageSize int
+
+	cmd := &cobra.Command{
+		Use:   "test-pattern [rule...]",
+		Short: "Interactively try patterns and directives against the project",
+		Long: `Opens a playground where each line typed is resolved as a rule against the
+current project, printing the matched files (paginated) with counts and timing.
+No rules file is read or modified.
+
+With arguments, each argument is evaluated once and the command exits.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workDir, err := filepath.Abs(GetWorkDir())
+			if err != nil {
+				return err
+			}
+			mgr := context.NewManager(workDir)
+			mgr.SetContext(cmd.Context())
+
+			if pageSize <= 0 {
+				pageSize = 20
+			}
+			repl := &patternREPL{eval: evalPatternRules(mgr, workDir), pageSize: pageSize}
+			out := cmd.OutOrStdout()
+
+			if len(args) > 0 {
+				repl.pageSize = math.MaxInt32 // print everything
+				for _, a := range args {
+					repl.handle(a, out)
+				}
+				return nil
+			}
+
+			fmt.Fprintf(out, "cx pattern playground in %s (:help for commands)\n", workDir)
+			return repl.run(os.Stdin, out, isTerminal(os.Stdin))
+		},
+	}
+
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Files shown per page")
+
+	return cmd
+}
+
+// isTerminal reports whether f is a character device, i.e. an interactive
+// session rather than piped input.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestPatternREPL drives the playground loop with a fake evaluator and
+// checks pagination and that kept global lines precede each rule.
+func TestPatternREPL(t *testing.T) {
+	var evaluated []string
+	repl := &patternREPL{
+		pageSize: 2,
+		eval: func(rules string) (*patternResult, error) {
+			evaluated = append(evaluated, rules)
+			if lines := strings.Split(rules, "\n"); lines[len(lines)-1] == "bad" {
+				return nil, fmt.Errorf("boom")
+			}
+			return &patternResult{files: []string{"a.go", "b.go", "c.go"}}, nil
+		},
+	}
+
+	var out bytes.Buffer
+	input := strings.Join([]string{
+		"*.go",
+		":n",
+		":global @grep: \"TODO\"",
+		"pkg/**",
+		"bad",
+		":q",
+		"never.go",
+	}, "\n")
+	if err := repl.run(strings.NewReader(input), &out, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"*.go", "@grep: \"TODO\"\npkg/**", "@grep: \"TODO\"\nbad"}; strings.Join(evaluated, "|") != strings.Join(want, "|") {
+		t.Errorf("evaluated = %q, want %q", evaluated, want)
+	}
+	got := out.String()
+	for _, want := range []string{"3 files", "-- page 1/2", "  c.go\n-- page 2/2", "Error: boom"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewToggleGroupCmd creates the toggle-group command.
+func NewToggleGroupCmd() *cobra.Command {
+	var on, off bool
+
+	cmd := &cobra.Command{
+		Use:   "toggle-group [name]",
+		Short: "Enable or disable a named group of rules",
+		Long: `Rules tagged into a group, inline ('tests/** @group: tests') or in a block
+('@group: tests {' ... '}'), can be switched off and on without editing the
+rules file. Disabled groups are remembered per project in grove state and
+apply to every rules file and ruleset used there.
+
+With a name, flips that group (or sets it with --on / --off). Without one,
+lists the groups in the active rules file and whether each is enabled.`,
+		Example: `  cx toggle-group tests
+  cx toggle-group tests --on
+  cx toggle-group`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if on && off {
+				return fmt.Errorf("--on and --off are mutually exclusive")
+			}
+			out := cmd.OutOrStdout()
+			workDir := GetWorkDir()
+			disabled := context.DisabledGroups(workDir)
+
+			if len(args) == 0 {
+				mgr := context.NewManager(workDir)
+				var content []byte
+				if path := mgr.ResolveRulesPath(); path != "" {
+					content, _ = os.ReadFile(path)
+				}
+				groups := context.RuleGroups(content, disabled)
+				for _, name := range disabled {
+					found := false
+					for _, g := range groups {
+						found = found || g.Name == name
+					}
+					if !found {
+						groups = append(groups, context.RuleGroup{Name: name, Disabled: true})
+					}
+				}
+				if cli.GetOptions(cmd).JSONOutput {
+					return writeJSON(cmd, groups)
+				}
+				if len(groups) == 0 {
+					fmt.Fprintln(out, "No rule groups. Tag rules with '@group: <name>'.")
+				}
+				for _, g := range groups {
+					status := "on "
+					if g.Disabled {
+						status = "off"
+					}
+					fmt.Fprintf(out, "%s  %s (%d rules)\n", status, g.Name, g.Rules)
+				}
+				return nil
+			}
+
+			name := args[0]
+			enable := on
+			if !on && !off {
+				// Flip: enable the group if it is currently disabled.
+				for _, g := range disabled {
+					enable = enable || g == name
+				}
+			}
+			if err := context.SetGroupEnabled(workDir, name, enable); err != nil {
+				return err
+			}
+			if enable {
+				fmt.Fprintf(out, "✓ Group '%s' enabled\n", name)
+			} else {
+				fmt.Fprintf(out, "✓ Group '%s' disabled\n", name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&on, "on", false, "Enable the group")
+	cmd.Flags().BoolVar(&off, "off", false, "Disable the group")
+
+	return cmd
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func newRulesTrustCmd() *cobra.Command {
+	var jobFile, rulesFile string
+	var yes, list bool
+
+	cmd := &cobra.Command{
+		Use:   "trust",
+		Short: "Approve a rules file's @allow-path requests",
+		Long: `A rules file can ask for a directory outside every workspace and
+context.allowed_paths entry with '@allow-path: <dir>'. Such requests are
+ignored (with a warning) until you trust them for that rules file; the
+approval is stored per rules file in ` + "`" + context.TrustStoreFile + "`" + ` under cx's state
+directory, never in the repository.
+
+Lists the requests of the active rules file (or --rules-file / --job) and asks
+to confirm each untrusted one; --yes trusts them all without asking. --list
+prints every grant in the store instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if list {
+				grants, err := context.PathGrants()
+				if err != nil {
+					return err
+				}
+				if cli.GetOptions(cmd).JSONOutput {
+					return writeJSON(cmd, grants)
+				}
+				if len(grants) == 0 {
+					fmt.Fprintln(out, "No trusted @allow-path requests.")
+				}
+				for _, g := range grants {
+					fmt.Fprintf(out, "%s\n  %s (since %s)\n", g.RulesFile, g.Path, g.GrantedAt.Local().Format("2006-01-02"))
+				}
+				return nil
+			}
+
+			mgr := context.NewManager(GetWorkDir())
+			target, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				target = mgr.ResolveRulesPath()
+			}
+			reqs, err := mgr.AllowPathRequests(target)
+			if err != nil {
+				return err
+			}
+			if len(reqs) == 0 {
+				fmt.Fprintf(out, "%s has no @allow-path requests.\n", target)
+				return nil
+			}
+
+			reader := bufio.NewReader(os.Stdin)
+			var approve []context.AllowPathRequest
+			for _, req := range reqs {
+				if req.Trusted {
+					fmt.Fprintf(out, "✓ L%d %s (already trusted)\n", req.LineNum, req.Path)
+					continue
+				}
+				if !yes {
+					fmt.Fprintf(out, "Allow %s to read %s (line %d)? (y/N): ", target, req.Path, req.LineNum)
+					response, _ := reader.ReadString('\n')
+					response = strings.ToLower(strings.TrimSpace(response))
+					if response != "y" && response != "yes" {
+						fmt.Fprintf(out, "  skipped %s\n", req.Path)
+						continue
+					}
+				}
+				approve = append(approve, req)
+			}
+			if len(approve) == 0 {
+				return nil
+			}
+			if err := context.TrustAllowPaths(approve); err != nil {
+				return err
+			}
+			for _, req := range approve {
+				fmt.Fprintf(out, "✓ L%d %s trusted\n", req.LineNum, req.Path)
+			}
+			return nil
+		},
+	}
+
+	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Trust every request without asking")
+	cmd.Flags().BoolVar(&list, "list", false, "List every trusted request instead")
+
+	return cmd
+}
+
+func newRulesUntrustCmd() *cobra.Command {
+	var jobFile, rulesFile string
+
+	cmd := &cobra.Command{
+		Use:   "untrust",
+		Short: "Revoke the trusted @allow-path requests of a rules file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			target, err := ResolveRulesFileFlag(mgr, jobFile, rulesFile)
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				target = mgr.ResolveRulesPath()
+			}
+			removed, err := context.RevokeAllowPaths(target)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Revoked %d trusted @allow-path request(s) for %s\n", removed, target)
+			return nil
+		},
+	}
+
+	AddRulesFileFlags(cmd, &jobFile, &rulesFile)
+
+	return cmd
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	stdctx "context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/grovetools/core/cli"
 	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/pkg/context"
@@ -15,7 +19,32 @@ func NewValidateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Verify context file integrity and accessibility",
-		Long:  `Check all files in .grove/context-files exist, verify file permissions, detect duplicates, and report any issues.`,
+		Long: `Check all files in .grove/context-files exist, verify file permissions, detect duplicates, and report any issues.
+
+The cached (cold) context is checked too: its checksum sidecar when one was
+written, that it agrees with .grove/cached-context-files, that it has not
+outlived the rules' @expire-time (unless @freeze-cache or @no-expire), that
+every cached file still exists, and that a frozen cache does not point into
+git rule checkouts that have since been deleted.
+
+Rules whose path (up to the first glob) does not exist are checked against git
+history and reported as renamed (with the new location), deleted, or never
+seen by git, which is either a typo or a path yet to be created.
+
+Failed @cmd: rules are reported with their exit code and stderr; they
+contribute no files, so they fail validation.
+
+cx's own artifacts (the generated context, the files lists, model variants,
+section outputs, snapshots) are always excluded from resolution. Walks skip
+.grove, but artifacts written elsewhere (a notebook or plan directory, a
+job-scoped path, a section output) can still be matched. The active rules and
+every named rule set are checked, and any whose patterns would otherwise
+include an artifact fail validation: the match is almost always a rule that
+would nest each generation in the next.
+
+With --json, prints every check as one JSON object with an overall "status"
+("ok" or "failed") and the "problems" that failed it; the exit code is the
+same as without --json.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := stdctx.Background()
 			mgr := context.NewManager(GetWorkDir())
@@ -26,19 +55,34 @@ func NewValidateCmd() *cobra.Command {
 			}
 
 			// Resolve files from rules
-			var files []string
+			var files, hotFiles, coldFiles []string
+			var rulesContent []byte
 			if targetRulesFile != "" {
-				hotFiles, coldFiles, resolveErr := mgr.ResolveFilesFromCustomRulesFile(targetRulesFile)
+				var resolveErr error
+				hotFiles, coldFiles, resolveErr = mgr.ResolveFilesFromCustomRulesFile(targetRulesFile)
 				if resolveErr != nil {
 					return fmt.Errorf("failed to resolve files from rules file: %w", resolveErr)
 				}
 				files = append(hotFiles, coldFiles...)
+				rulesContent, _ = os.ReadFile(targetRulesFile)
 			} else {
-				files, err = mgr.ResolveFilesFromRules()
+				session, err := mgr.NewResolutionSession()
 				if err != nil {
 					return err
 				}
+				files, hotFiles = session.Hot, session.Hot
+				rulesContent = session.RulesContent
+				if len(context.RequiredPaths(rulesContent)) > 0 || context.HasMaxAgeDirectives(rulesContent) {
+					coldFiles = session.Cold
+				}
 			}
+			requiredIssues := append(
+				mgr.RequiredFileIssues(rulesContent, "hot", hotFiles),
+				mgr.RequiredFileIssues(rulesContent, "cold", coldFiles)...)
+			staleFiles := mgr.StaleFiles(rulesContent, append(append([]string{}, hotFiles...), coldFiles...))
+			vanished := mgr.VanishedRulePaths(rulesContent)
+			cmdFailures := mgr.CommandFailures()
+			artifactMatches := mgr.ArtifactMatches()
 
 			// Then validate those files
 			result, err := mgr.ValidateContext(files)
@@ -46,14 +90,74 @@ func NewValidateCmd() *cobra.Command {
 				return err
 			}
 
+			if cli.GetOptions(cmd).JSONOutput {
+				cache, err := mgr.ValidateCachedContext()
+				if err != nil {
+					return err
+				}
+				return writeValidateJSON(cmd, validateReport{
+					SchemaVersion:   machineSchemaVersion,
+					Files:           result,
+					Cache:           cache,
+					ChecksumErrors:  checksumErrors(mgr),
+					VanishedRules:   vanished,
+					RequiredIssues:  requiredIssues,
+					StaleFiles:      staleFiles,
+					CommandFailures: cmdFailures,
+					ArtifactMatches: artifactMatches,
+				})
+			}
+
 			if result.TotalFiles == 0 {
 				ulog.Warn("No files in context").
 					Pretty("No files in context. Check your rules file.").
 					Log(ctx)
-				return nil
+				printVanishedRules(vanished)
+				if err := commandFailuresError(cmdFailures); err != nil {
+					return err
+				}
+				if err := artifactMatchesError(artifactMatches); err != nil {
+					return err
+				}
+				return requiredIssuesError(requiredIssues)
 			}
 
 			result.Print()
+
+			cache, err := mgr.ValidateCachedContext()
+			if err != nil {
+				return err
+			}
+			if cache.Exists {
+				fmt.Println()
+				cache.Print()
+			}
+
+			for _, msg := range checksumErrors(mgr) {
+				context.Warnf("%s", msg)
+			}
+
+			printVanishedRules(vanished)
+			if err := commandFailuresError(cmdFailures); err != nil {
+				return err
+			}
+			if err := artifactMatchesError(artifactMatches); err != nil {
+				return err
+			}
+
+			if err := requiredIssuesError(requiredIssues); err != nil {
+				return err
+			}
+			if len(staleFiles) > 0 {
+				fmt.Printf("\nFiles older than their @max-age: (%d):\n", len(staleFiles))
+				for _, stale := range staleFiles {
+					fmt.Printf("  - %s\n", stale)
+				}
+				return fmt.Errorf("%d file(s) exceed their @max-age:; rerun the generators that produce them", len(staleFiles))
+			}
+			if n := cache.Issues(); n > 0 {
+				return fmt.Errorf("cached context has %d issue(s); run 'cx generate' to rebuild it", n)
+			}
 			return nil
 		},
 	}
@@ -62,3 +166,157 @@ func NewValidateCmd() *cobra.Command {
 
 	return cmd
 }
+
+// checksumErrors verifies the sidecars of artifacts generated with
+// --checksum, describing each artifact edited or truncated since generation.
+// The cached artifact itself is covered by ValidateCachedContext.
+func checksumErrors(mgr *context.Manager) []string {
+	var errs []string
+	for _, artifact := range []string{
+		mgr.ResolveContextPath(),
+		mgr.ResolveContextFilesListPath(),
+		mgr.ResolveCachedContextFilesListPath(),
+	} {
+		if err := context.VerifyArtifactChecksum(artifact); err != nil && !errors.Is(err, context.ErrNoChecksum) {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
+
+// printVanishedRules lists rules whose paths no longer exist. They are
+// warnings, not failures: a path git has never seen may be one the user is
+// about to create.
+func printVanishedRules(vanished []context.VanishedRulePath) {
+	if len(vanished) == 0 {
+		return
+	}
+	fmt.Printf("\nRules referencing paths that do not exist (%d):\n", len(vanished))
+	for _, v := range vanished {
+		fmt.Printf("  - line %d: %s: %s\n", v.LineNum, v.Pattern, v.Describe())
+	}
+}
+
+// commandFailuresError lists the @cmd: rules that failed during resolution
+// and returns an error counting them, or nil when there were none.
+func commandFailuresError(failures []context.CommandFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	fmt.Printf("\nFailed @cmd: rules (%d):\n", len(failures))
+	for _, f := range failures {
+		fmt.Printf("  - line %d: %s (exit %d)\n", f.LineNum, f.Command, f.ExitCode)
+		for _, line := range strings.Split(f.Stderr, "\n") {
+			if line != "" {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+	return fmt.Errorf("%d @cmd: rule(s) failed", len(failures))
+}
+
+// artifactMatchesError lists the rule sets whose patterns match cx's own
+// artifacts and returns an error counting them, or nil when there were none.
+func artifactMatchesError(matches []context.ArtifactMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	fmt.Printf("\nRule sets matching cx's own artifacts (%d):\n", len(matches))
+	for _, match := range matches {
+		name := match.Ruleset
+		if name == context.ActiveExpectation {
+			name = "active rules"
+		}
+		fmt.Printf("  - %s:\n", name)
+		for _, f := range match.Files {
+			fmt.Printf("      %s\n", f)
+		}
+	}
+	return fmt.Errorf("%d rule set(s) match cx's own artifacts; narrow the patterns that include them", len(matches))
+}
+
+// requiredIssuesError lists the unsatisfied @require: directives and
+// returns an error counting them, or nil when there were none.
+func requiredIssuesError(issues []context.RequiredFileIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	fmt.Printf("\nRequired files not in context (%d):\n", len(issues))
+	for _, issue := range issues {
+		fmt.Printf("  - %s (line %d, %s): %s\n", issue.Path, issue.LineNum, issue.Section, issue.Reason)
+	}
+	return fmt.Errorf("%d @require: directive(s) not satisfied", len(issues))
+}
+
+// validateReport is the --json form of `cx validate`. Status is "ok" or
+// "failed"; Problems lists what failed validation, in the order the text
+// output reports them. Vanished rules are warnings and never fail it.
+type validateReport struct {
+	SchemaVersion   int                         `json:"schema_version"`
+	Status          string                      `json:"status"`
+	Problems        []string                    `json:"problems"`
+	Files           *context.ValidationResult   `json:"files"`
+	Cache           *context.CacheValidation    `json:"cache"`
+	ChecksumErrors  []string                    `json:"checksum_errors"`
+	VanishedRules   []context.VanishedRulePath  `json:"vanished_rules"`
+	RequiredIssues  []context.RequiredFileIssue `json:"required_issues"`
+	StaleFiles      []context.StaleFile         `json:"stale_files"`
+	CommandFailures []context.CommandFailure    `json:"command_failures"`
+	ArtifactMatches []context.ArtifactMatch     `json:"artifact_matches"`
+}
+
+// writeValidateJSON fills in the report's status and writes it, returning an
+// error (after the JSON) when validation failed so the exit code matches the
+// text output.
+func writeValidateJSON(cmd *cobra.Command, report validateReport) error {
+	if n := len(report.CommandFailures); n > 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d @cmd: rule(s) failed", n))
+	}
+	if n := len(report.ArtifactMatches); n > 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d rule set(s) match cx's own artifacts", n))
+	}
+	if n := len(report.RequiredIssues); n > 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d @require: directive(s) not satisfied", n))
+	}
+	if n := len(report.StaleFiles); n > 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d file(s) exceed their @max-age:", n))
+	}
+	if report.Cache != nil {
+		if n := report.Cache.Issues(); n > 0 {
+			report.Problems = append(report.Problems, fmt.Sprintf("cached context has %d issue(s)", n))
+		}
+	}
+	report.Status = "ok"
+	if len(report.Problems) > 0 {
+		report.Status = "failed"
+	}
+	// Empty lists encode as [] rather than null.
+	if report.Problems == nil {
+		report.Problems = []string{}
+	}
+	if report.ChecksumErrors == nil {
+		report.ChecksumErrors = []string{}
+	}
+	if report.VanishedRules == nil {
+		report.VanishedRules = []context.VanishedRulePath{}
+	}
+	if report.RequiredIssues == nil {
+		report.RequiredIssues = []context.RequiredFileIssue{}
+	}
+	if report.StaleFiles == nil {
+		report.StaleFiles = []context.StaleFile{}
+	}
+	if report.CommandFailures == nil {
+		report.CommandFailures = []context.CommandFailure{}
+	}
+	if report.ArtifactMatches == nil {
+		report.ArtifactMatches = []context.ArtifactMatch{}
+	}
+	if err := writeJSON(cmd, report); err != nil {
+		return err
+	}
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("validation failed: %s", strings.Join(report.Problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+func NewVerifyOutputCmd() *cobra.Command {
+	var require []string
+	var maxTokens int
+
+	cmd := &cobra.Command{
+		Use:   "verify-output [artifact...]",
+		Short: "Check generated context artifacts against output policies",
+		Long: `Scans the final generated context (after comment stripping and other
+transforms) and fails with a violation report if it contains:
+
+  - likely secrets (private keys, cloud/API tokens, credential assignments)
+  - files from paths outside the allowed workspace roots
+  - more tokens than the budget (--max-tokens, or cx.token_budget in grove.yml)
+  - missing required files (@require: in the active rules, or --require)
+
+This is distinct from 'cx validate', which checks the resolved file list
+before generation. With no arguments the hot and cached context artifacts are
+verified.
+
+Examples:
+  cx verify-output
+  cx verify-output --require README.md --max-tokens 200000
+  cx verify-output .grove/context`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+
+			artifacts := args
+			if len(artifacts) == 0 {
+				for _, p := range []string{mgr.ResolveContextPath(), mgr.ResolveCachedContextPath()} {
+					if _, err := os.Stat(p); err == nil {
+						artifacts = append(artifacts, p)
+					}
+				}
+				if len(artifacts) == 0 {
+					return fmt.Errorf("no generated context found; run 'cx generate' first")
+				}
+			}
+
+			if rulesContent, _, _ := mgr.LoadRulesContent(); rulesContent != nil {
+				require = append(require, context.RequiredPaths(rulesContent)...)
+			}
+
+			opts := context.VerifyOptions{TokenBudget: maxTokens, Require: require}
+			if !cmd.Flags().Changed("max-tokens") {
+				opts.TokenBudget = context.LoadCxConfig(mgr.GetWorkDir()).TokenBudget
+			}
+
+			var results []*context.OutputVerification
+			violations := 0
+			for _, artifact := range artifacts {
+				// Required files may live in either the hot or the cached
+				// artifact, so check them against the union below instead.
+				perArtifact := opts
+				perArtifact.Require = nil
+				result, err := mgr.VerifyOutput(artifact, perArtifact)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+			if len(require) > 0 {
+				missing := mgr.MissingRequiredFiles(results, require)
+				if len(missing) > 0 {
+					results[0].Violations = append(results[0].Violations, missing...)
+				}
+			}
+			for _, r := range results {
+				violations += len(r.Violations)
+			}
+
+			if cli.GetOptions(cmd).JSONOutput {
+				if err := writeJSON(cmd, results); err != nil {
+					return err
+				}
+			} else {
+				for _, r := range results {
+					r.Print(cmd.OutOrStdout())
+				}
+			}
+
+			if violations > 0 {
+				return fmt.Errorf("%d policy violation(s) found in generated context", violations)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&require, "require", nil, "Paths that must be present in the generated context (repeatable)")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Fail if an artifact exceeds this many estimated tokens (default: cx.token_budget)")
+
+	return cmd
+}
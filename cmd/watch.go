@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewWatchCmd creates the watch command.
+func NewWatchCmd() *cobra.Command {
+	var desktop, budgetOnly, rulesOnly, idle bool
+	var notifyCmd, webhook string
+	var debounce time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Regenerate context whenever the rules or the files they match change",
+		Long: `Generates hot and cold context, then regenerates it each time the active
+rules file is saved or a context source changes, until interrupted: an
+included file is edited or removed, or a file is added to or renamed in a
+directory the rules walk. Saving the project's grove.yml reloads workspace
+filters and aliases before the next regeneration. Regeneration is
+incremental: directory walks are kept between runs and replayed unless a
+directory changed, and only new or edited rule lines are matched again.
+With --rules-only, only the rules file and grove config are watched.
+--idle runs every worker pool with one worker at the lowest CPU (and, on
+Linux, I/O) priority, for watching alongside builds.
+
+After every regeneration cx can notify you, so context drift is visible while
+you work in your editor:
+  --notify          native desktop notification (notify-send / osascript)
+  --notify-cmd CMD  run CMD through the shell; the event is JSON on stdin and
+                    CX_EVENT, CX_HOT_TOKENS, CX_TOKEN_BUDGET are set
+  --webhook URL     POST the event as JSON
+
+Two events are sent: "regenerated" after each generation, and "over_budget"
+when the hot context first exceeds cx.token_budget (once per crossing).
+--budget-only sends just the latter. Defaults come from cx.notify in grove.yml.`,
+		Example: `  cx watch --notify
+  cx watch --budget-only --webhook http://127.0.0.1:9000/hooks/cx
+  cx watch --notify-cmd 'jq -r .event >> /tmp/cx-events'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// A long-running command prints warnings as they happen.
+			context.SetCollectWarnings(false)
+			if idle {
+				context.SetIdle(true)
+			}
+			ctx := cmd.Context()
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(ctx)
+			workDir := mgr.GetWorkDir()
+
+			notifyCfg := context.LoadCxConfig(workDir).Notify
+			if cmd.Flags().Changed("notify") {
+				notifyCfg.Desktop = desktop
+			}
+			if cmd.Flags().Changed("notify-cmd") {
+				notifyCfg.Command = notifyCmd
+			}
+			if cmd.Flags().Changed("webhook") {
+				notifyCfg.Webhook = webhook
+			}
+			if cmd.Flags().Changed("budget-only") {
+				notifyCfg.BudgetOnly = budgetOnly
+			}
+			notifier := context.NewNotifier(notifyCfg)
+
+			_, rulesPath, _ := mgr.LoadRulesContent()
+			if rulesPath == "" {
+				rulesPath = mgr.ResolveRulesPath()
+			}
+			rulesPath, err := filepath.Abs(rulesPath)
+			if err != nil {
+				return err
+			}
+
+			// Watch the parent directory: editors save via temp file + rename,
+			// which drops a watch on the file itself.
+			watcher, err := fsnotify.NewWatcher()
+			if err != nil {
+				return fmt.Errorf("failed to start file watcher: %w", err)
+			}
+			defer watcher.Close()
+			if err := watcher.Add(filepath.Dir(rulesPath)); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", filepath.Dir(rulesPath), err)
+			}
+			// grove.yml edits change workspace filters and aliases; pick them
+			// up without a restart.
+			if workDir != filepath.Dir(rulesPath) {
+				if err := watcher.Add(workDir); err != nil {
+					ulog.Warn("Cannot watch grove config").Err(err).Log(ctx)
+				}
+			}
+			configChanged := false
+
+			// Context sources: the directories the last resolution walked and
+			// the files it included, re-synced after every regeneration.
+			watchedDirs := make(map[string]bool)
+			sourceFiles := map[string]bool{}
+			watchLimitHit := false
+			syncSourceWatches := func() {
+				if rulesOnly {
+					return
+				}
+				dirs, files := mgr.WatchTargets()
+				sourceFiles = files
+				want := make(map[string]bool, len(dirs))
+				for _, dir := range dirs {
+					want[dir] = true
+					if watchedDirs[dir] || watchLimitHit {
+						continue
+					}
+					if err := watcher.Add(dir); err != nil {
+						// Usually the inotify watch limit; keep what is watched.
+						watchLimitHit = true
+						ulog.Warn("Cannot watch all context sources; some changes will be missed").
+							Field("dir", dir).
+							Err(err).
+							Log(ctx)
+						continue
+					}
+					watchedDirs[dir] = true
+				}
+				for dir := range watchedDirs {
+					if !want[dir] && dir != filepath.Dir(rulesPath) && dir != workDir {
+						_ = watcher.Remove(dir)
+						delete(watchedDirs, dir)
+					}
+				}
+			}
+
+			regenerate := func() {
+				start := time.Now()
+				if configChanged {
+					configChanged = false
+					context.ClearManagerCache()
+					mgr = context.NewManager(workDir)
+					mgr.SetContext(ctx)
+					ulog.Info("Grove config changed; reloaded").Log(ctx)
+				}
+				if err := mgr.GenerateContext(true); err != nil {
+					ulog.Error("Context generation failed").Err(err).Log(ctx)
+					return
+				}
+				if err := mgr.GenerateCachedContext(); err != nil {
+					ulog.Error("Cached context generation failed").Err(err).Log(ctx)
+					return
+				}
+				gen := mgr.LastGeneration()
+				ulog.Success("Context regenerated").
+					Field("hot_files", gen.HotFiles).
+					Field("hot_tokens", gen.HotTokens).
+					Field("cold_files", gen.ColdFiles).
+					Pretty(fmt.Sprintf("Context regenerated: %d hot files (~%s tokens), %d cold files",
+						gen.HotFiles, context.FormatTokenCount(gen.HotTokens), gen.ColdFiles)).
+					Log(ctx)
+
+				if notifyCfg.Enabled() {
+					budget := context.LoadCxConfig(mgr.GetWorkDir()).TokenBudget
+					if err := notifier.Generated(ctx, mgr.GetWorkDir(), gen, budget); err != nil {
+						ulog.Warn("Notification failed").Err(err).Log(ctx)
+					}
+				}
+				if err := mgr.RecordUsage("watch", start); err != nil {
+					ulog.Warn("Failed to record usage metrics").Err(err).Log(ctx)
+				}
+				syncSourceWatches()
+			}
+
+			regenerate()
+			if rulesOnly {
+				ulog.Info("Watching rules file").
+					Field("rules", rulesPath).
+					Pretty(fmt.Sprintf("Watching %s (Ctrl+C to stop)", rulesPath)).
+					Log(ctx)
+			} else {
+				ulog.Info("Watching rules file and context sources").
+					Field("rules", rulesPath).
+					Field("dirs", len(watchedDirs)).
+					Pretty(fmt.Sprintf("Watching %s and %d source directories (Ctrl+C to stop)", rulesPath, len(watchedDirs))).
+					Log(ctx)
+			}
+
+			var pending <-chan time.Time
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case event, ok := <-watcher.Events:
+					if !ok {
+						return nil
+					}
+					if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+						continue
+					}
+					name := filepath.Clean(event.Name)
+					switch {
+					case name == rulesPath && event.Op&fsnotify.Remove == 0:
+					case filepath.Dir(name) == workDir && context.IsConfigFile(name):
+						configChanged = true
+					case isSourceEvent(event, name, sourceFiles, watchedDirs):
+					default:
+						continue
+					}
+					pending = time.After(debounce)
+				case err, ok := <-watcher.Errors:
+					if !ok {
+						return nil
+					}
+					ulog.Warn("File watcher error").Err(err).Log(ctx)
+				case <-pending:
+					pending = nil
+					regenerate()
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&desktop, "notify", false, "Show a desktop notification after each regeneration")
+	cmd.Flags().StringVar(&notifyCmd, "notify-cmd", "", "Shell command to run after each regeneration (event JSON on stdin)")
+	cmd.Flags().StringVar(&webhook, "webhook", "", "URL to POST each event to as JSON")
+	cmd.Flags().BoolVar(&budgetOnly, "budget-only", false, "Only notify when the hot context crosses cx.token_budget")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "Wait this long after a change before regenerating")
+	cmd.Flags().BoolVar(&rulesOnly, "rules-only", false, "Only regenerate when the rules file or grove config changes, not the files they match")
+	cmd.Flags().BoolVar(&idle, "idle", false, "Use one worker per pool and the lowest CPU/IO priority")
+
+	return cmd
+}
+
+// isSourceEvent reports whether event, on the cleaned path name, changes the
+// context: an included file was written, removed or renamed, or an entry
+// other than an editor temp file appeared in or left a watched directory.
+func isSourceEvent(event fsnotify.Event, name string, files, dirs map[string]bool) bool {
+	if files[name] {
+		return true
+	}
+	if event.Op&fsnotify.Write != 0 || !dirs[filepath.Dir(name)] {
+		return false
+	}
+	return !context.IsEditorTempFile(name)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grovetools/core/cli"
+	"github.com/spf13/cobra"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// NewWhyBlockedCmd creates the why-blocked command.
+func NewWhyBlockedCmd() *cobra.Command {
+	var allRoots bool
+
+	cmd := &cobra.Command{
+		Use:   "why-blocked <path>",
+		Short: "Explain why a path is or is not allowed into context",
+		Long: `Shows the decision trail behind the workspace sandbox for one path: its
+absolute and canonical forms, whether included_workspaces (allowlist) or
+excluded_workspaces (denylist) is in effect, the discovered workspaces that
+contain it and whether one of them is excluded, the allowed roots compared,
+and, when the path is blocked, the config change that would allow it.
+
+Only allowed roots that contain the path are listed unless --all-roots is
+given; --json always includes every root.`,
+		Example: `  cx why-blocked ../legacy-service/main.go
+  cx why-blocked ~/notes/design.md --all-roots`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := context.NewManager(GetWorkDir())
+			mgr.SetContext(cmd.Context())
+
+			d, err := mgr.ExplainPathAccess(args[0])
+			if err != nil {
+				return err
+			}
+			if cli.GetOptions(cmd).JSONOutput {
+				return writeJSON(cmd, d)
+			}
+			printPathAccessDecision(cmd, d, allRoots)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&allRoots, "all-roots", false, "List every allowed root compared, not just those containing the path")
+
+	return cmd
+}
+
+func printPathAccessDecision(cmd *cobra.Command, d *context.PathAccessDecision, allRoots bool) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Path:      %s\n", d.Path)
+	fmt.Fprintf(out, "Absolute:  %s\n", d.AbsPath)
+	if d.CanonicalPath != d.AbsPath {
+		fmt.Fprintf(out, "Canonical: %s\n", d.CanonicalPath)
+	}
+	if d.Allowed {
+		fmt.Fprintln(out, "Verdict:   allowed")
+	} else {
+		fmt.Fprintf(out, "Verdict:   blocked (%s)\n", d.Reason)
+	}
+	fmt.Fprintln(out)
+
+	if d.Mode == "allowlist" {
+		fmt.Fprintf(out, "Mode: allowlist, included_workspaces: %s\n", strings.Join(d.IncludedWorkspaces, ", "))
+	} else {
+		excluded := "(none)"
+		if len(d.ExcludedWorkspaces) > 0 {
+			excluded = strings.Join(d.ExcludedWorkspaces, ", ")
+		}
+		fmt.Fprintf(out, "Mode: denylist, excluded_workspaces: %s\n", excluded)
+	}
+	fmt.Fprintln(out)
+
+	if len(d.Containing) == 0 {
+		fmt.Fprintln(out, "No discovered workspace contains this path.")
+	} else {
+		fmt.Fprintln(out, "Workspaces containing the path:")
+		for _, w := range d.Containing {
+			var notes []string
+			if w.Excluded {
+				notes = append(notes, "excluded")
+			}
+			if w.Included {
+				notes = append(notes, "included")
+			}
+			if w.CanonicalPath != w.Path {
+				notes = append(notes, "canonical "+w.CanonicalPath)
+			}
+			suffix := ""
+			if len(notes) > 0 {
+				suffix = " [" + strings.Join(notes, ", ") + "]"
+			}
+			fmt.Fprintf(out, "  %s  %s%s\n", w.Name, w.Path, suffix)
+		}
+	}
+	if d.ExcludedBy != nil {
+		fmt.Fprintf(out, "Exclusions are checked first: '%s' matched, so no allowed root is consulted.\n", d.ExcludedBy.Name)
+	}
+	fmt.Fprintln(out)
+
+	matching := 0
+	for _, r := range d.Roots {
+		if r.Contains {
+			matching++
+		}
+	}
+	fmt.Fprintf(out, "Allowed roots compared: %d, containing the path: %d\n", len(d.Roots), matching)
+	for _, r := range d.Roots {
+		if !r.Contains && !allRoots {
+			continue
+		}
+		mark := "  "
+		if r.Contains {
+			mark = "✓ "
+		}
+		origin := ""
+		if r.Origin != "" {
+			origin = "  (" + r.Origin + ")"
+		}
+		fmt.Fprintf(out, "  %s%s%s\n", mark, r.Root, origin)
+	}
+
+	if len(d.Suggestions) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "To allow it:")
+		for _, s := range d.Suggestions {
+			fmt.Fprintf(out, "  - %s\n", s)
+		}
+	}
+}
@@ -4,10 +4,19 @@ import (
 	gocontext "context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/grovetools/core/pkg/daemon"
+	"github.com/grovetools/core/pkg/models"
 	"github.com/grovetools/core/pkg/workspace"
 
+	"github.com/grovetools/cx/pkg/context"
 	"github.com/spf13/cobra"
 )
 
@@ -20,30 +29,75 @@ func NewWorkspaceCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(newWorkspaceListCmd())
+	cmd.AddCommand(newWorkspaceAddCmd())
 
 	return cmd
 }
 
+// workspaceStatus is the git and session state a workspace is enriched with
+// when listing by status.
+type workspaceStatus struct {
+	Branch      string     `json:"branch,omitempty"`
+	Dirty       bool       `json:"dirty"`
+	Ahead       int        `json:"ahead,omitempty"`
+	Behind      int        `json:"behind,omitempty"`
+	LastSession *time.Time `json:"lastSession,omitempty"`
+}
+
 // newWorkspaceListCmd creates the 'workspace list' command.
 func newWorkspaceListCmd() *cobra.Command {
 	var jsonOutput bool
+	var showStatus bool
+	var dirtyOnly bool
+	var sortBy string
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all discovered workspaces",
-		Long:  `Outputs a list of all projects, ecosystems, and worktrees discovered from your Grove configuration.`,
+		Long: `Outputs a list of all projects, ecosystems, and worktrees discovered from your Grove configuration.
+
+With --status each workspace is shown with its branch, ahead/behind counts,
+uncommitted changes and the time of its most recent Claude session, to help
+decide which repositories to pull into context. --sort dirty lists repos with
+uncommitted changes first, --sort session the most recently used first, and
+--dirty keeps only repos with uncommitted changes. Both imply --status.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch sortBy {
+			case "", "name", "dirty", "session":
+			default:
+				return fmt.Errorf("invalid --sort %q: must be name, dirty or session", sortBy)
+			}
+			if sortBy != "" || dirtyOnly {
+				showStatus = true
+			}
+
 			client := daemon.NewWithAutoStart()
 			projects, err := client.GetWorkspaces(gocontext.Background())
 			if err != nil {
 				return fmt.Errorf("failed to discover workspaces: %w", err)
 			}
 
+			var statuses map[string]*workspaceStatus
+			if showStatus {
+				statuses = loadWorkspaceStatuses(projects)
+				if dirtyOnly {
+					var dirty []*workspace.WorkspaceNode
+					for _, p := range projects {
+						if s := statuses[p.Path]; s != nil && s.Dirty {
+							dirty = append(dirty, p)
+						}
+					}
+					projects = dirty
+				}
+				sortWorkspaces(projects, statuses, sortBy)
+			}
+
 			if jsonOutput {
 				// Create a custom struct to include the identifier in the JSON output.
 				type workspaceJSON struct {
 					*workspace.WorkspaceNode
-					Identifier string `json:"identifier"`
+					Identifier string           `json:"identifier"`
+					Status     *workspaceStatus `json:"status,omitempty"`
 				}
 
 				jsonProjects := make([]workspaceJSON, len(projects))
@@ -51,6 +105,7 @@ func newWorkspaceListCmd() *cobra.Command {
 					jsonProjects[i] = workspaceJSON{
 						WorkspaceNode: p,
 						Identifier:    p.Identifier("_"),
+						Status:        statuses[p.Path],
 					}
 				}
 
@@ -62,6 +117,10 @@ func newWorkspaceListCmd() *cobra.Command {
 			} else {
 				// Simple text output for human consumption.
 				for _, p := range projects {
+					if s := statuses[p.Path]; s != nil {
+						fmt.Printf("- %s (%s) %s\n", p.Identifier("_"), p.Path, s.summary(time.Now()))
+						continue
+					}
 					fmt.Printf("- %s (%s)\n", p.Identifier("_"), p.Path)
 				}
 			}
@@ -70,6 +129,289 @@ func newWorkspaceListCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output workspace information in JSON format")
+	cmd.Flags().BoolVar(&showStatus, "status", false, "Show git status and last Claude session for each workspace")
+	cmd.Flags().BoolVar(&dirtyOnly, "dirty", false, "Only list workspaces with uncommitted changes")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort by name, dirty (uncommitted changes first) or session (most recent first)")
+
+	return cmd
+}
+
+// loadWorkspaceStatuses collects the daemon's cached git status and the last
+// Claude session time for each project, keyed by path. Projects the daemon
+// has no status for still get their session time.
+func loadWorkspaceStatuses(projects []*workspace.WorkspaceNode) map[string]*workspaceStatus {
+	statuses := make(map[string]*workspaceStatus, len(projects))
+	paths := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		s := &workspaceStatus{}
+		if t, ok := lastClaudeSession(p.Path); ok {
+			s.LastSession = &t
+		}
+		statuses[p.Path] = s
+		paths[p.Path] = true
+	}
+
+	opts := &models.EnrichmentOptions{
+		FetchGitStatus: true,
+		GitStatusPaths: paths,
+	}
+	client := daemon.NewWithAutoStart()
+	enriched, err := client.GetEnrichedWorkspaces(gocontext.Background(), opts)
+	if err != nil {
+		context.Warnf("failed to load git status: %v", err)
+		return statuses
+	}
+	for _, ws := range enriched {
+		s, ok := statuses[ws.Path]
+		if !ok || ws.GitStatus == nil {
+			continue
+		}
+		s.Branch = ws.GitStatus.Branch
+		s.Dirty = ws.GitStatus.IsDirty
+		s.Ahead = ws.GitStatus.AheadCount
+		s.Behind = ws.GitStatus.BehindCount
+	}
+	return statuses
+}
+
+// sortWorkspaces orders projects in place. "dirty" puts repos with
+// uncommitted changes first and "session" the most recently used first;
+// both break ties by name.
+func sortWorkspaces(projects []*workspace.WorkspaceNode, statuses map[string]*workspaceStatus, by string) {
+	if by == "" {
+		return
+	}
+	sessionTime := func(p *workspace.WorkspaceNode) time.Time {
+		if s := statuses[p.Path]; s != nil && s.LastSession != nil {
+			return *s.LastSession
+		}
+		return time.Time{}
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		switch by {
+		case "dirty":
+			ad := statuses[a.Path] != nil && statuses[a.Path].Dirty
+			bd := statuses[b.Path] != nil && statuses[b.Path].Dirty
+			if ad != bd {
+				return ad
+			}
+		case "session":
+			if at, bt := sessionTime(a), sessionTime(b); !at.Equal(bt) {
+				return at.After(bt)
+			}
+		}
+		return a.Identifier("_") < b.Identifier("_")
+	})
+}
+
+// summary renders the status as a compact suffix, e.g.
+// "[main ↑2 ↓1 *] session 3h ago".
+func (s *workspaceStatus) summary(now time.Time) string {
+	var parts []string
+	if s.Branch != "" {
+		git := s.Branch
+		if s.Ahead > 0 {
+			git += fmt.Sprintf(" ↑%d", s.Ahead)
+		}
+		if s.Behind > 0 {
+			git += fmt.Sprintf(" ↓%d", s.Behind)
+		}
+		if s.Dirty {
+			git += " *"
+		}
+		parts = append(parts, "["+git+"]")
+	}
+	if s.LastSession != nil {
+		parts = append(parts, "session "+formatAge(now.Sub(*s.LastSession))+" ago")
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatAge renders a duration at the coarsest useful unit.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+var claudeProjectDirRe = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// lastClaudeSession returns the modification time of the newest Claude
+// session transcript recorded for projectPath. Claude keeps transcripts under
+// ~/.claude/projects/, in a directory named after the project path with every
+// non-alphanumeric character replaced by '-'.
+func lastClaudeSession(projectPath string) (time.Time, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return newestTranscript(filepath.Join(home, ".claude", "projects", claudeProjectDirRe.ReplaceAllString(projectPath, "-")))
+}
+
+// newestTranscript returns the newest *.jsonl modification time in dir.
+func newestTranscript(dir string) (time.Time, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var newest time.Time
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, !newest.IsZero()
+}
+
+// newWorkspaceAddCmd creates the 'workspace add' command.
+func newWorkspaceAddCmd() *cobra.Command {
+	var filters []string
+	var hot bool
+
+	cmd := &cobra.Command{
+		Use:   "add <ecosystem-or-worktree>",
+		Short: "Add every sub-project of an ecosystem or ecosystem worktree to context",
+		Long: `Adds an alias rule for each sub-project of the given ecosystem (or ecosystem
+worktree) to the cold section of the active rules file, as one commented
+block, instead of adding each project individually.
+
+The workspace is matched by identifier (as shown by 'cx workspace list'),
+name or path. --filter keeps only sub-projects whose name matches one of the
+given globs.`,
+		Example: `  cx workspace add grove-ecosystem
+  cx workspace add grove-ecosystem --filter 'grove-*' --filter cx
+  cx workspace add grove-ecosystem:feature-x --hot`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, f := range filters {
+				if _, err := path.Match(f, ""); err != nil {
+					return fmt.Errorf("invalid --filter %q: %w", f, err)
+				}
+			}
+
+			client := daemon.NewWithAutoStart()
+			nodes, err := client.GetWorkspaces(gocontext.Background())
+			if err != nil {
+				return fmt.Errorf("failed to discover workspaces: %w", err)
+			}
+			provider := workspace.NewProviderFromNodes(nodes)
+
+			target := findWorkspaceNode(nodes, args[0])
+			if target == nil {
+				return fmt.Errorf("no workspace named %q", args[0])
+			}
+			if !target.IsEcosystem() {
+				return fmt.Errorf("%s is not an ecosystem or ecosystem worktree", target.Identifier("_"))
+			}
+
+			rules := ecosystemMemberRules(provider, target, filters)
+			if len(rules) == 0 {
+				return fmt.Errorf("no sub-projects of %s matched", target.Identifier("_"))
+			}
+
+			contextType := "cold"
+			if hot {
+				contextType = "hot"
+			}
+			mgr := context.NewManager(".")
+			comment := fmt.Sprintf("%s sub-projects", target.Identifier("_"))
+			if err := mgr.AppendRuleGroup(comment, rules, contextType); err != nil {
+				return err
+			}
+			fmt.Printf("Added %d sub-projects of %s to %s context\n", len(rules), target.Identifier("_"), contextType)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&filters, "filter", nil, "Only add sub-projects whose name matches this glob (repeatable)")
+	cmd.Flags().BoolVar(&hot, "hot", false, "Add to hot context instead of cold")
 
 	return cmd
 }
+
+// findWorkspaceNode looks a workspace up by identifier, name or path.
+func findWorkspaceNode(nodes []*workspace.WorkspaceNode, ref string) *workspace.WorkspaceNode {
+	for _, n := range nodes {
+		if n.Identifier("_") == ref || n.Identifier(":") == ref {
+			return n
+		}
+	}
+	for _, n := range nodes {
+		if n.Name == ref {
+			return n
+		}
+	}
+	if abs, err := filepath.Abs(ref); err == nil {
+		for _, n := range nodes {
+			if filepath.Clean(n.Path) == abs {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// ecosystemMemberRules returns an `@a:` rule for each sub-project directly
+// under target whose name matches one of filters (all when filters is
+// empty), in name order. For a root ecosystem this skips the sub-projects'
+// own worktrees; for an ecosystem worktree it lists the worktree's checkouts.
+func ecosystemMemberRules(provider *workspace.Provider, target *workspace.WorkspaceNode, filters []string) []string {
+	rootName := target.Name
+	if target.RootEcosystemPath != "" && target.RootEcosystemPath != target.Path {
+		root := provider.FindByPath(target.RootEcosystemPath)
+		if root == nil {
+			return nil
+		}
+		rootName = root.Name
+	}
+
+	var members []*workspace.WorkspaceNode
+	for _, n := range provider.All() {
+		if n.ParentEcosystemPath != target.Path || n.IsEcosystem() {
+			continue
+		}
+		if !target.IsWorktree() && n.IsWorktree() {
+			continue
+		}
+		if !matchesAnyGlob(n.Name, filters) {
+			continue
+		}
+		members = append(members, n)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	rules := make([]string, 0, len(members))
+	for _, n := range members {
+		if target.IsWorktree() {
+			rules = append(rules, fmt.Sprintf("@a:%s:%s:%s/**", rootName, target.Name, n.Name))
+		} else {
+			rules = append(rules, fmt.Sprintf("@a:%s:%s/**", rootName, n.Name))
+		}
+	}
+	return rules
+}
+
+// matchesAnyGlob reports whether name matches one of globs; no globs match
+// everything.
+func matchesAnyGlob(name string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, g := range globs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
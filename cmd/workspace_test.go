@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grovetools/core/pkg/workspace"
+)
+
+func TestSortWorkspaces(t *testing.T) {
+	now := time.Now()
+	older := now.Add(-time.Hour)
+	a := &workspace.WorkspaceNode{Name: "a", Path: "/a"}
+	b := &workspace.WorkspaceNode{Name: "b", Path: "/b"}
+	c := &workspace.WorkspaceNode{Name: "c", Path: "/c"}
+	statuses := map[string]*workspaceStatus{
+		"/a": {LastSession: &older},
+		"/b": {Dirty: true},
+		"/c": {LastSession: &now},
+	}
+
+	projects := []*workspace.WorkspaceNode{a, b, c}
+	sortWorkspaces(projects, statuses, "dirty")
+	if projects[0] != b {
+		t.Errorf("dirty sort should put the dirty repo first, got %s", projects[0].Name)
+	}
+
+	sortWorkspaces(projects, statuses, "session")
+	if projects[0] != c || projects[1] != a || projects[2] != b {
+		t.Errorf("session sort = %s %s %s, want c a b", projects[0].Name, projects[1].Name, projects[2].Name)
+	}
+}
+
+func TestWorkspaceStatusSummary(t *testing.T) {
+	now := time.Now()
+	last := now.Add(-3 * time.Hour)
+	s := &workspaceStatus{Branch: "main", Dirty: true, Ahead: 2, Behind: 1, LastSession: &last}
+	if got, want := s.summary(now), "[main ↑2 ↓1 *] session 3h ago"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestNewestTranscript(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := newestTranscript(filepath.Join(dir, "missing")); ok {
+		t.Error("a missing directory has no sessions")
+	}
+	want := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for name, mtime := range map[string]time.Time{
+		"old.jsonl": want.Add(-time.Hour),
+		"new.jsonl": want,
+		"notes.txt": want.Add(time.Hour),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, ok := newestTranscript(dir); !ok || !got.Equal(want) {
+		t.Errorf("newestTranscript = %v, %v; want %v", got, ok, want)
+	}
+}
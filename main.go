@@ -14,6 +14,7 @@ import (
 
 	// "github.com/grovetools/core/tui"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 
 	"github.com/grovetools/cx/cmd"
 	"github.com/grovetools/cx/cmd/view"
@@ -50,11 +51,18 @@ func main() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&cmd.GlobalWorkDir, "dir", "C", "", "Set working directory for context resolution")
+	rootCmd.PersistentFlags().BoolVar(&cmd.GlobalCwdRelative, "cwd-relative", false, "Resolve rules relative to the current directory instead of the project root")
+	rootCmd.PersistentFlags().BoolVar(&cmd.GlobalStrictWalk, "strict-walk", false, "Fail on the first unreadable file or directory instead of skipping it with a warning")
+	rootCmd.PersistentFlags().BoolVar(&cmd.GlobalStandalone, "standalone", false, "Use only local rules files, ignoring grove config, state and workspace discovery")
+	rootCmd.PersistentFlags().BoolVar(&cmd.GlobalNoCache, "no-cache", false, "Bypass the gitignore, walk and rules expansion caches (same as CX_NO_CACHE=1)")
 
 	// Setup profiling
 	profiler := profiling.NewCobraProfiler()
 	profiler.AddFlags(rootCmd)
-	rootCmd.PersistentPreRunE = profiler.PreRun
+	rootCmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		cmd.ApplyGlobalFlags()
+		return profiler.PreRun(c, args)
+	}
 	rootCmd.PersistentPostRun = profiler.PostRun
 
 	// Add subcommands
@@ -80,12 +88,50 @@ func main() {
 	rootCmd.AddCommand(cmd.NewLintCmd())
 	rootCmd.AddCommand(cmd.NewAliasCmd())
 	rootCmd.AddCommand(cmd.NewConceptCmd())
+	rootCmd.AddCommand(cmd.NewCleanCmd())
+	rootCmd.AddCommand(cmd.NewVerifyOutputCmd())
+	rootCmd.AddCommand(cmd.NewServeCmd())
+	rootCmd.AddCommand(cmd.NewTestPatternCmd())
+	rootCmd.AddCommand(cmd.NewWatchCmd())
+	rootCmd.AddCommand(cmd.NewSuggestTieringCmd())
+	rootCmd.AddCommand(cmd.NewConfigCmd())
+	rootCmd.AddCommand(cmd.NewWhyBlockedCmd())
+	rootCmd.AddCommand(cmd.NewScratchCmd())
+	rootCmd.AddCommand(cmd.NewAnnotatePRCmd())
+	rootCmd.AddCommand(cmd.NewToggleGroupCmd())
+	rootCmd.AddCommand(cmd.NewImportIgnoresCmd())
+	rootCmd.AddCommand(cmd.NewBenchCmd())
+	rootCmd.AddCommand(cmd.NewGrepCmd())
+	rootCmd.AddCommand(cmd.NewFindCmd())
+	rootCmd.AddCommand(cmd.NewForTestCmd())
+	rootCmd.AddCommand(cmd.NewMvCmd())
+	rootCmd.AddCommand(cmd.NewFixCmd())
+	rootCmd.AddCommand(cmd.NewListSnapshotsCmd())
+	rootCmd.AddCommand(cmd.NewNoteCmd())
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
 	defer cancel()
 	rootCmd.SetContext(ctx)
 
-	if err := cli.Execute(rootCmd); err != nil {
-		os.Exit(1)
+	err := execute(rootCmd)
+	cmd.FlushWarnings()
+	if err != nil {
+		os.Exit(cmd.ExitCode(err))
+	}
+}
+
+// execute is cli.Execute, except that it does not print the errors that
+// only carry an exit status (see cmd.ExitError).
+func execute(rootCmd *cobra.Command) error {
+	cli.ApplyStyledHelpRecursive(rootCmd)
+	rootCmd.SilenceErrors = true
+	err := rootCmd.Execute()
+	if err != nil && !cmd.IsSilent(err) {
+		target, _, _ := rootCmd.Find(os.Args[1:])
+		if target == nil {
+			target = rootCmd
+		}
+		cli.PrintError(target, err)
 	}
+	return err
 }
@@ -0,0 +1,212 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccessFile is the local, opt-in record of which context files were edited
+// or opened while they were included. It feeds `cx suggest-tiering`.
+const AccessFile = ".grove/access.json"
+
+// accessEnvVar force-enables access tracking regardless of config.
+const accessEnvVar = "CX_ACCESS_TRACKING"
+
+// FileAccess is the activity recorded for one context file. Edits are
+// counted when a generation (or `cx suggest-tiering`) sees the file's mtime
+// advance; Opens come from editor integrations via RecordFileOpens.
+type FileAccess struct {
+	FirstSeen    time.Time `json:"first_seen"`
+	LastMtime    time.Time `json:"last_mtime"`
+	LastActivity time.Time `json:"last_activity,omitempty"`
+	Edits        int       `json:"edits,omitempty"`
+	Opens        int       `json:"opens,omitempty"`
+}
+
+// Touches is the number of recorded edits and opens.
+func (a *FileAccess) Touches() int {
+	return a.Edits + a.Opens
+}
+
+// AccessLog maps workspace-relative paths to their recorded activity.
+type AccessLog struct {
+	Files map[string]*FileAccess `json:"files"`
+}
+
+// TieringSuggestion proposes moving File between the hot and cold sections.
+type TieringSuggestion struct {
+	File   string `json:"file"`
+	From   string `json:"from"` // "hot" or "cold"
+	To     string `json:"to"`
+	Tokens int    `json:"tokens"`
+	Edits  int    `json:"edits"`
+	Opens  int    `json:"opens"`
+	Reason string `json:"reason"`
+}
+
+// AccessTrackingEnabled reports whether file activity is tracked for
+// workDir, either via `cx: {access_tracking: true}` in grove.yml or
+// CX_ACCESS_TRACKING=1.
+func AccessTrackingEnabled(workDir string) bool {
+	return LoadCxConfig(workDir).AccessTracking
+}
+
+// AccessPath returns the absolute path of the access log.
+func (m *Manager) AccessPath() string {
+	return filepath.Join(m.workDir, AccessFile)
+}
+
+// accessKey maps a resolved path to its key in the access log.
+func (m *Manager) accessKey(file string) string {
+	abs := file
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(m.workDir, abs)
+	}
+	if rel, err := filepath.Rel(m.workDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return abs
+}
+
+// ReadAccessLog loads the access log at path. A missing log is empty.
+func ReadAccessLog(path string) (*AccessLog, error) {
+	log := &AccessLog{Files: make(map[string]*FileAccess)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return log, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, fmt.Errorf("invalid access log %s: %w", path, err)
+	}
+	if log.Files == nil {
+		log.Files = make(map[string]*FileAccess)
+	}
+	return log, nil
+}
+
+func writeAccessLog(path string, log *AccessLog) error {
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode access log: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create access log directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // local activity log, not sensitive
+}
+
+// TrackAccess samples the mtimes of files and counts an edit for each one
+// modified since it was last seen. It is a no-op unless access tracking is
+// enabled, and always for a WithNoState manager.
+func (m *Manager) TrackAccess(files []string) error {
+	if m.noState || len(files) == 0 || !AccessTrackingEnabled(m.workDir) {
+		return nil
+	}
+	m.accessMu.Lock()
+	defer m.accessMu.Unlock()
+
+	log, err := ReadAccessLog(m.AccessPath())
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, file := range files {
+		info, err := os.Stat(m.requirePathKey(file))
+		if err != nil {
+			continue
+		}
+		mtime := info.ModTime().UTC()
+		key := m.accessKey(file)
+		rec, ok := log.Files[key]
+		if !ok {
+			log.Files[key] = &FileAccess{FirstSeen: now, LastMtime: mtime}
+			continue
+		}
+		if mtime.After(rec.LastMtime) {
+			rec.Edits++
+			rec.LastMtime = mtime
+			rec.LastActivity = mtime
+		}
+	}
+	return writeAccessLog(m.AccessPath(), log)
+}
+
+// RecordFileOpens counts an open for each of files, for editor integrations
+// (e.g. a BufEnter hook running `cx suggest-tiering --record-open <file>`).
+// Unlike TrackAccess it records whenever called, since calling it is opt-in.
+func (m *Manager) RecordFileOpens(files []string) error {
+	m.accessMu.Lock()
+	defer m.accessMu.Unlock()
+
+	log, err := ReadAccessLog(m.AccessPath())
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, file := range files {
+		info, err := os.Stat(m.requirePathKey(file))
+		if err != nil {
+			continue
+		}
+		key := m.accessKey(file)
+		rec, ok := log.Files[key]
+		if !ok {
+			rec = &FileAccess{FirstSeen: now, LastMtime: info.ModTime().UTC()}
+			log.Files[key] = rec
+		}
+		rec.Opens++
+		rec.LastActivity = now
+	}
+	return writeAccessLog(m.AccessPath(), log)
+}
+
+// SuggestTiering proposes demoting hot files with no recorded activity
+// after being tracked for at least minTracked, and promoting cold files
+// with at least minTouches edits and opens. Demotions are ordered by tokens
+// saved, promotions by activity.
+func (m *Manager) SuggestTiering(log *AccessLog, hot, cold []string, minTracked time.Duration, minTouches int) []TieringSuggestion {
+	provider := GetStatsProvider()
+	tokensOf := func(file string) int {
+		if info, err := provider.GetFileStats(m.requirePathKey(file)); err == nil {
+			return info.Tokens
+		}
+		return 0
+	}
+	now := time.Now()
+
+	var demote, promote []TieringSuggestion
+	for _, file := range hot {
+		rec, ok := log.Files[m.accessKey(file)]
+		if !ok || rec.Touches() > 0 || now.Sub(rec.FirstSeen) < minTracked {
+			continue
+		}
+		demote = append(demote, TieringSuggestion{
+			File: m.accessKey(file), From: "hot", To: "cold", Tokens: tokensOf(file),
+			Reason: fmt.Sprintf("not edited or opened in %s of tracking", formatAge(now.Sub(rec.FirstSeen))),
+		})
+	}
+	for _, file := range cold {
+		rec, ok := log.Files[m.accessKey(file)]
+		if !ok || rec.Touches() < minTouches {
+			continue
+		}
+		promote = append(promote, TieringSuggestion{
+			File: m.accessKey(file), From: "cold", To: "hot", Tokens: tokensOf(file),
+			Edits: rec.Edits, Opens: rec.Opens,
+			Reason: fmt.Sprintf("%d edits, %d opens", rec.Edits, rec.Opens),
+		})
+	}
+	sort.SliceStable(demote, func(i, j int) bool { return demote[i].Tokens > demote[j].Tokens })
+	sort.SliceStable(promote, func(i, j int) bool {
+		return promote[i].Edits+promote[i].Opens > promote[j].Edits+promote[j].Opens
+	})
+	return append(demote, promote...)
+}
@@ -0,0 +1,74 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAccessTrackingSuggestions(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(name string, mtime time.Time) {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	for _, name := range []string{"idle.go", "busy.go", "ref.go", "used.go"} {
+		touch(name, past)
+	}
+	hot := []string{"idle.go", "busy.go"}
+	cold := []string{"ref.go", "used.go"}
+
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	t.Setenv(accessEnvVar, "0")
+	if err := m.TrackAccess(append(hot, cold...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(m.AccessPath()); !os.IsNotExist(err) {
+		t.Fatal("nothing should be recorded while tracking is disabled")
+	}
+
+	t.Setenv(accessEnvVar, "1")
+	if err := m.TrackAccess(append(hot, cold...)); err != nil {
+		t.Fatal(err)
+	}
+	touch("busy.go", past.Add(time.Minute))
+	touch("used.go", past.Add(time.Minute))
+	if err := m.TrackAccess(append(hot, cold...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RecordFileOpens([]string{"used.go", filepath.Join(dir, "used.go"), "missing.go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := ReadAccessLog(m.AccessPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := log.Files["used.go"]; rec == nil || rec.Edits != 1 || rec.Opens != 2 {
+		t.Fatalf("unexpected record for used.go: %+v", rec)
+	}
+	if _, ok := log.Files["missing.go"]; ok {
+		t.Error("files that do not exist should not be recorded")
+	}
+
+	if got := m.SuggestTiering(log, hot, cold, time.Hour, 3); len(got) != 1 || got[0].To != "hot" {
+		t.Errorf("files tracked for less than minTracked should not be demoted, got %+v", got)
+	}
+	got := m.SuggestTiering(log, hot, cold, 0, 3)
+	if len(got) != 2 {
+		t.Fatalf("expected two suggestions, got %+v", got)
+	}
+	if got[0].File != "idle.go" || got[0].To != "cold" {
+		t.Errorf("expected idle.go to be demoted, got %+v", got[0])
+	}
+	if got[1].File != "used.go" || got[1].To != "hot" {
+		t.Errorf("expected used.go to be promoted, got %+v", got[1])
+	}
+}
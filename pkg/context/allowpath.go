@@ -0,0 +1,287 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/pkg/paths"
+	"github.com/grovetools/core/util/pathutil"
+)
+
+// TrustStoreFile is the name of the user-level store of trusted
+// `@allow-path:` requests, kept in cx's state directory (see
+// TrustStorePath) rather than in any repository.
+const TrustStoreFile = "trusted-paths.json"
+
+// allowPathDirective is a single `@allow-path: <dir>` line. A rules file
+// uses it to ask for a directory outside every workspace and
+// context.allowed_paths entry, for one task-specific context, without
+// widening grove.yml for everything else. The request only takes effect once
+// the user trusts it for that rules file (cx rules trust), so a cloned or
+// generated rules file cannot widen access on its own.
+type allowPathDirective struct {
+	Path    string
+	LineNum int
+}
+
+// AllowPathRequest is one `@allow-path:` line of a rules file.
+type AllowPathRequest struct {
+	RulesFile string `json:"rulesFile"`
+	LineNum   int    `json:"line"`
+	Path      string `json:"path"` // absolute and canonical
+	Trusted   bool   `json:"trusted"`
+}
+
+// PathGrant is a trusted request in the trust store.
+type PathGrant struct {
+	RulesFile string    `json:"rulesFile"`
+	Path      string    `json:"path"`
+	GrantedAt time.Time `json:"grantedAt"`
+}
+
+type trustStore struct {
+	Grants []PathGrant `json:"grants"`
+}
+
+// TrustStorePath returns the absolute path of the trust store.
+func TrustStorePath() string {
+	return filepath.Join(paths.StateDir(), "cx", TrustStoreFile)
+}
+
+// parseAllowPathDirectives collects the `@allow-path:` lines of a rules file.
+func parseAllowPathDirectives(content []byte) []allowPathDirective {
+	var dirs []allowPathDirective
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text())))
+		if !strings.HasPrefix(line, "@allow-path:") {
+			continue
+		}
+		path := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "@allow-path:")), `"`)
+		if path == "" {
+			continue
+		}
+		dirs = append(dirs, allowPathDirective{Path: expandHomeAndDot(path), LineNum: lineNum})
+	}
+	return dirs
+}
+
+// canonicalGrantPath makes path absolute (relative to base) and normalizes
+// it the way allowed roots are, falling back to the absolute form for
+// paths that do not exist yet.
+func canonicalGrantPath(base, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	path = filepath.Clean(path)
+	if canonical, err := pathutil.NormalizeForLookup(path); err == nil {
+		return canonical
+	}
+	return path
+}
+
+// allowPathRequests lists the requests in content, the rules file at
+// absRulesPath. Relative paths are resolved against the rules file's
+// directory, like @default: paths.
+func allowPathRequests(absRulesPath string, content []byte, store *trustStore) []AllowPathRequest {
+	rulesFile := canonicalGrantPath("", absRulesPath)
+	var reqs []AllowPathRequest
+	for _, d := range parseAllowPathDirectives(content) {
+		req := AllowPathRequest{
+			RulesFile: rulesFile,
+			LineNum:   d.LineNum,
+			Path:      canonicalGrantPath(filepath.Dir(absRulesPath), d.Path),
+		}
+		req.Trusted = store.trusts(req.RulesFile, req.Path)
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// AllowPathRequests returns the `@allow-path:` requests of the rules file
+// at rulesPath and whether each is trusted. Imported rules files are not
+// followed; each carries its own requests.
+func (m *Manager) AllowPathRequests(rulesPath string) ([]AllowPathRequest, error) {
+	if !filepath.IsAbs(rulesPath) {
+		rulesPath = filepath.Join(m.workDir, rulesPath)
+	}
+	content, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	return allowPathRequests(rulesPath, content, store), nil
+}
+
+// TrustAllowPaths records reqs in the trust store.
+func TrustAllowPaths(reqs []AllowPathRequest) error {
+	store, err := loadTrustStore()
+	if err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if !store.trusts(req.RulesFile, req.Path) {
+			store.Grants = append(store.Grants, PathGrant{RulesFile: req.RulesFile, Path: req.Path, GrantedAt: time.Now().UTC()})
+		}
+	}
+	return store.save()
+}
+
+// RevokeAllowPaths removes every grant made to rulesFile and returns how
+// many there were.
+func RevokeAllowPaths(rulesFile string) (int, error) {
+	store, err := loadTrustStore()
+	if err != nil {
+		return 0, err
+	}
+	rulesFile = canonicalGrantPath("", rulesFile)
+	kept := store.Grants[:0]
+	for _, g := range store.Grants {
+		if g.RulesFile != rulesFile {
+			kept = append(kept, g)
+		}
+	}
+	removed := len(store.Grants) - len(kept)
+	store.Grants = kept
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, store.save()
+}
+
+// PathGrants returns every grant in the trust store, by rules file.
+func PathGrants() ([]PathGrant, error) {
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(store.Grants, func(i, j int) bool {
+		if store.Grants[i].RulesFile != store.Grants[j].RulesFile {
+			return store.Grants[i].RulesFile < store.Grants[j].RulesFile
+		}
+		return store.Grants[i].Path < store.Grants[j].Path
+	})
+	return store.Grants, nil
+}
+
+// applyAllowPaths adds the trusted `@allow-path:` requests of a rules file
+// being expanded to the grants of run and to the manager's allowed roots,
+// and warns once about each untrusted one. At the end of the top-level
+// expansion the manager keeps only run's grants (see setGrantedRoots).
+// Stateless managers ignore the directive: the trust store is user state.
+func (m *Manager) applyAllowPaths(run *expansionRun, absRulesPath string, content []byte) {
+	if m.noState || !bytes.Contains(content, []byte("@allow-path:")) {
+		return
+	}
+	run.recordAllowPaths(absRulesPath, content)
+	store, err := loadTrustStore()
+	if err != nil {
+		Warnf("ignoring @allow-path in %s: %v", absRulesPath, err)
+		return
+	}
+	m.allowPathMu.Lock()
+	defer m.allowPathMu.Unlock()
+	for _, req := range allowPathRequests(absRulesPath, content, store) {
+		if req.Trusted {
+			if m.grantedRoots == nil {
+				m.grantedRoots = make(map[string]string)
+			}
+			m.grantedRoots[req.Path] = req.RulesFile
+			run.grants[req.Path] = req.RulesFile
+			continue
+		}
+		key := req.RulesFile + "\x00" + req.Path
+		if m.allowPathWarned[key] {
+			continue
+		}
+		if m.allowPathWarned == nil {
+			m.allowPathWarned = make(map[string]bool)
+		}
+		m.allowPathWarned[key] = true
+		Warnf("%s:%d requests @allow-path: %s, which is not trusted; run 'cx rules trust' to allow it", absRulesPath, req.LineNum, req.Path)
+	}
+}
+
+// setGrantedRoots replaces the manager's granted roots with grants, those
+// of the rules files in the last top-level expansion.
+func (m *Manager) setGrantedRoots(grants map[string]string) {
+	m.allowPathMu.Lock()
+	defer m.allowPathMu.Unlock()
+	m.grantedRoots = make(map[string]string, len(grants))
+	for root, rulesFile := range grants {
+		m.grantedRoots[root] = rulesFile
+	}
+}
+
+// currentGrants returns a copy of the manager's granted roots.
+func (m *Manager) currentGrants() map[string]string {
+	m.allowPathMu.Lock()
+	defer m.allowPathMu.Unlock()
+	grants := make(map[string]string, len(m.grantedRoots))
+	for root, rulesFile := range m.grantedRoots {
+		grants[root] = rulesFile
+	}
+	return grants
+}
+
+// grantedRootFor returns the granted root containing canonicalPath and the
+// rules file that requested it.
+func (m *Manager) grantedRootFor(canonicalPath string) (root, rulesFile string, ok bool) {
+	m.allowPathMu.Lock()
+	defer m.allowPathMu.Unlock()
+	for root, rulesFile := range m.grantedRoots {
+		if pathWithin(canonicalPath, root) {
+			return root, rulesFile, true
+		}
+	}
+	return "", "", false
+}
+
+func loadTrustStore() (*trustStore, error) {
+	store := &trustStore{}
+	data, err := os.ReadFile(TrustStorePath())
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading trust store: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("parsing trust store %s: %w", TrustStorePath(), err)
+	}
+	return store, nil
+}
+
+func (s *trustStore) save() error {
+	path := TrustStorePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func (s *trustStore) trusts(rulesFile, path string) bool {
+	for _, g := range s.Grants {
+		if g.RulesFile == rulesFile && g.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAllowPathTrust(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	notes := t.TempDir()
+	rulesPath := filepath.Join(dir, ".grove", "rules")
+	fsWriteString(t, rulesPath, "*.go\n@allow-path: "+notes+"\n")
+
+	m := NewManager(dir)
+	reqs, err := m.AllowPathRequests(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 1 || reqs[0].LineNum != 2 || reqs[0].Trusted {
+		t.Fatalf("expected one untrusted request on line 2, got %+v", reqs)
+	}
+	content := []byte("*.go\n@allow-path: " + notes + "\n")
+	inside := filepath.Join(reqs[0].Path, "design.md")
+
+	m.applyAllowPaths(newExpansionRun(), rulesPath, content)
+	if _, _, ok := m.grantedRootFor(inside); ok {
+		t.Fatal("an untrusted request must not grant access")
+	}
+
+	if err := TrustAllowPaths(reqs); err != nil {
+		t.Fatal(err)
+	}
+	m.applyAllowPaths(newExpansionRun(), rulesPath, content)
+	if _, rulesFile, ok := m.grantedRootFor(inside); !ok || rulesFile != reqs[0].RulesFile {
+		t.Errorf("trusted request should grant %s, got %q %v", reqs[0].Path, rulesFile, ok)
+	}
+
+	// Trust is per rules file: the same request elsewhere stays untrusted.
+	other := filepath.Join(dir, "other.rules")
+	fsWriteString(t, other, "@allow-path: "+notes+"\n")
+	if reqs, _ := m.AllowPathRequests(other); len(reqs) != 1 || reqs[0].Trusted {
+		t.Errorf("another rules file should not inherit trust, got %+v", reqs)
+	}
+
+	if n, err := RevokeAllowPaths(rulesPath); err != nil || n != 1 {
+		t.Errorf("RevokeAllowPaths = %d, %v; want 1 grant removed", n, err)
+	}
+	if grants, _ := PathGrants(); len(grants) != 0 {
+		t.Errorf("expected an empty store after revoking, got %+v", grants)
+	}
+}
+
+// TestAllowPathGrantsScoped verifies that grants last only as long as the
+// rules file that requested them is expanded and trusted, memoized
+// expansions included.
+func TestAllowPathGrantsScoped(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	notes := t.TempDir()
+	rulesPath := filepath.Join(dir, ".grove", "rules")
+	fsWriteString(t, rulesPath, "*.go\n@allow-path: "+notes+"\n")
+	otherPath := filepath.Join(dir, "other.rules")
+	fsWriteString(t, otherPath, "*.go\n")
+
+	m := NewManager(dir)
+	reqs, err := m.AllowPathRequests(rulesPath)
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("AllowPathRequests = %+v, %v", reqs, err)
+	}
+	if err := TrustAllowPaths(reqs); err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(reqs[0].Path, "design.md")
+	granted := func(rules string) bool {
+		t.Helper()
+		if _, _, _, _, err := m.expandAllRules(rules, newExpansionRun(), 0); err != nil {
+			t.Fatal(err)
+		}
+		_, _, ok := m.grantedRootFor(inside)
+		return ok
+	}
+
+	if !granted(rulesPath) {
+		t.Fatal("a trusted request should grant its path")
+	}
+	if !granted(rulesPath) {
+		t.Error("a memoized expansion should grant its path again")
+	}
+	if granted(otherPath) {
+		t.Error("grants should not outlive a switch to another rules file")
+	}
+	if _, err := RevokeAllowPaths(rulesPath); err != nil {
+		t.Fatal(err)
+	}
+	if granted(rulesPath) {
+		t.Error("grants should not outlive their trust")
+	}
+}
@@ -0,0 +1,79 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// annotationPrefix introduces a structured rule annotation inside a trailing
+// comment: `services/billing/** # why: current sprint focus`.
+const annotationPrefix = "why:"
+
+// inlineCommentIndex returns the byte offset of a trailing " # ..." comment
+// in line, or -1. A # inside a double-quoted span (e.g. @grep: "foo # bar")
+// or not preceded by whitespace is not a comment.
+func inlineCommentIndex(line string) int {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '"' {
+			inQuote = !inQuote
+			continue
+		}
+		if !inQuote && c == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// SplitRuleAnnotation splits a rules line into the rule text and its
+// `# why:` annotation. Lines without an annotation return the trimmed line
+// and an empty annotation; ordinary trailing comments are left in place.
+func SplitRuleAnnotation(line string) (rule, annotation string) {
+	trimmed := strings.TrimSpace(line)
+	idx := inlineCommentIndex(trimmed)
+	if idx < 0 {
+		return trimmed, ""
+	}
+	comment := strings.TrimSpace(trimmed[idx+1:])
+	if !strings.HasPrefix(comment, annotationPrefix) {
+		return trimmed, ""
+	}
+	return strings.TrimRight(trimmed[:idx], " \t"), strings.TrimSpace(strings.TrimPrefix(comment, annotationPrefix))
+}
+
+// ruleText returns the rule portion of a rules line with any trailing comment
+// removed. Mutations compare on this so annotated lines still match.
+func ruleText(line string) string {
+	return strings.TrimSpace(stripInlineComments(strings.TrimSpace(line)))
+}
+
+// withAnnotation renders a rule line carrying annotation (if any).
+func withAnnotation(rule, annotation string) string {
+	if annotation == "" {
+		return rule
+	}
+	return rule + " # " + annotationPrefix + " " + annotation
+}
+
+// RuleAnnotations maps 1-based line numbers of rules content to their
+// `# why:` annotations. Only lines that carry an annotation are present.
+func RuleAnnotations(content []byte) map[int]string {
+	annotations := make(map[int]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if _, annotation := SplitRuleAnnotation(trimmed); annotation != "" {
+			annotations[lineNum] = annotation
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,77 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitRuleAnnotation(t *testing.T) {
+	tests := []struct {
+		line       string
+		rule       string
+		annotation string
+	}{
+		{"services/billing/** # why: current sprint focus", "services/billing/**", "current sprint focus"},
+		{"  !vendor/**   #why:noise", "!vendor/**", "noise"},
+		{"pkg/** # plain comment", "pkg/** # plain comment", ""},
+		{`*.go @grep: "a # why: b"`, `*.go @grep: "a # why: b"`, ""},
+		{"README.md", "README.md", ""},
+	}
+	for _, tt := range tests {
+		rule, annotation := SplitRuleAnnotation(tt.line)
+		if rule != tt.rule || annotation != tt.annotation {
+			t.Errorf("SplitRuleAnnotation(%q) = (%q, %q), want (%q, %q)", tt.line, rule, annotation, tt.rule, tt.annotation)
+		}
+	}
+
+	got := RuleAnnotations([]byte("# header\nmain.go # why: entrypoint\n---\ndocs/** # why: reference\n"))
+	if len(got) != 2 || got[2] != "entrypoint" || got[4] != "reference" {
+		t.Errorf("RuleAnnotations = %v, want lines 2 and 4 annotated", got)
+	}
+}
+
+// TestAppendRule_PreservesAnnotation verifies that moving an annotated rule
+// between hot and cold keeps its `# why:` text, and that RemoveRule still
+// matches the annotated line.
+func TestAppendRule_PreservesAnnotation(t *testing.T) {
+	testDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(testDir, ".grove"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(testDir)
+	rulesPath := mgr.ResolveRulesWritePath()
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rulesPath, []byte("main.go # why: entrypoint\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.AppendRule("main.go", "cold"); err != nil {
+		t.Fatalf("AppendRule: %v", err)
+	}
+	content, _ := os.ReadFile(rulesPath)
+	if got := strings.Count(string(content), "main.go"); got != 1 {
+		t.Fatalf("expected exactly one main.go rule, got %d:\n%s", got, content)
+	}
+	if !strings.Contains(string(content), "---\nmain.go # why: entrypoint") {
+		t.Errorf("annotation lost when moving rule to cold:\n%s", content)
+	}
+	if status := mgr.GetRuleStatus("main.go"); status != RuleCold {
+		t.Errorf("GetRuleStatus = %v, want RuleCold", status)
+	}
+
+	if err := mgr.RemoveRule("main.go"); err != nil {
+		t.Fatalf("RemoveRule: %v", err)
+	}
+	content, _ = os.ReadFile(rulesPath)
+	if strings.Contains(string(content), "main.go") {
+		t.Errorf("annotated rule not removed:\n%s", content)
+	}
+}
@@ -0,0 +1,267 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/state"
+)
+
+// Artifact name templates. cx.artifact_name (or CX_ARTIFACT_NAME) renames
+// the generated artifacts so checkouts that switch branches or rule sets
+// keep one set per combination instead of clobbering a single
+// .grove/context:
+//
+//	cx:
+//	  artifact_name: "{name}-{branch}-{ruleset}"
+//
+// gives .grove/context-main-default, .grove/cached-context-main-default and
+// so on. Only the file name is templated; the directory (.grove/, or the
+// notebook's generated and cache directories) is unchanged, as are job and
+// plan-scoped paths. WithNoState managers read neither setting and keep the
+// default names.
+var artifactTemplateVars = map[string]bool{
+	"name":     true, // the default artifact name: context, context-files, cached-context, cached-context-files
+	"branch":   true, // the current git branch ("/" becomes "-"), the short commit when detached, "nogit" outside git
+	"ruleset":  true, // the active named rule set, "default" for the plain rules file
+	"worktree": true, // the base name of the working directory
+}
+
+// artifactNames are the default base names of the generated artifacts,
+// longest first so a variant name is attributed to the most specific one.
+var artifactNames = []string{
+	filepath.Base(CachedContextFilesListFile),
+	filepath.Base(CachedContextFile),
+	filepath.Base(FilesListFile),
+	filepath.Base(ContextFile),
+}
+
+var artifactVarRegex = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// validateArtifactTemplate reports why tmpl cannot be used as an artifact
+// name template.
+func validateArtifactTemplate(tmpl string) error {
+	if strings.ContainsAny(tmpl, `/\`) {
+		return fmt.Errorf("artifact name %q must be a file name, not a path", tmpl)
+	}
+	if !strings.Contains(tmpl, "{name}") {
+		return fmt.Errorf("artifact name %q must contain {name}, or every artifact would share one file", tmpl)
+	}
+	for _, match := range artifactVarRegex.FindAllStringSubmatch(tmpl, -1) {
+		if !artifactTemplateVars[match[1]] {
+			return fmt.Errorf("artifact name %q uses unknown variable {%s}", tmpl, match[1])
+		}
+	}
+	return nil
+}
+
+// artifactTemplate returns the configured artifact name template, or ""
+// for the default names. An invalid template is reported once and
+// ignored.
+func (m *Manager) artifactTemplate() string {
+	m.artifactNameOnce.Do(func() {
+		tmpl := m.cxConfig().ArtifactName
+		if tmpl == "" {
+			return
+		}
+		if err := validateArtifactTemplate(tmpl); err != nil {
+			Warnf("ignoring %v", err)
+			return
+		}
+		m.artifactName = tmpl
+	})
+	return m.artifactName
+}
+
+// artifactPath applies the artifact name template, if any, to the file
+// name of the default artifact path p.
+func (m *Manager) artifactPath(p string) string {
+	tmpl := m.artifactTemplate()
+	if tmpl == "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(p), expandArtifactName(tmpl, filepath.Base(p), m.artifactVars(tmpl)))
+}
+
+// expandArtifactName fills in the template for artifact name.
+func expandArtifactName(tmpl, name string, vars map[string]string) string {
+	return artifactVarRegex.ReplaceAllStringFunc(tmpl, func(v string) string {
+		key := v[1 : len(v)-1]
+		if key == "name" {
+			return name
+		}
+		return vars[key]
+	})
+}
+
+// artifactVars computes the values of the variables tmpl uses.
+func (m *Manager) artifactVars(tmpl string) map[string]string {
+	vars := make(map[string]string)
+	if strings.Contains(tmpl, "{branch}") {
+		vars["branch"] = sanitizeArtifactVar(currentBranch(m.workDir))
+	}
+	if strings.Contains(tmpl, "{ruleset}") {
+		vars["ruleset"] = sanitizeArtifactVar(m.activeRulesetName())
+	}
+	if strings.Contains(tmpl, "{worktree}") {
+		vars["worktree"] = sanitizeArtifactVar(filepath.Base(m.workDir))
+	}
+	return vars
+}
+
+// sanitizeArtifactVar makes s safe to use in a file name.
+func sanitizeArtifactVar(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ', '\t':
+			return '-'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// currentBranch returns the checked-out branch of dir, the short commit
+// when HEAD is detached, or "nogit" outside a git repository.
+func currentBranch(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "nogit"
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		if out, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output(); err == nil {
+			branch = strings.TrimSpace(string(out))
+		}
+	}
+	return branch
+}
+
+// activeRulesetName names the rules in effect for {ruleset}: the rule set
+// picked by a rules file override, CX_RULES_FILE, CX_PROFILE or `cx rules
+// set`, without its extension, or "default" for the plain rules file.
+func (m *Manager) activeRulesetName() string {
+	trim := func(p string) string { return strings.TrimSuffix(filepath.Base(p), RulesExt) }
+	switch {
+	case m.rulesFileOverride != "":
+		return trim(m.rulesFileOverride)
+	case m.hasSuppliedRules || m.noState:
+		return "default"
+	}
+	if p := os.Getenv(RulesFileEnvVar); p != "" {
+		return trim(p)
+	}
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		return name
+	}
+	if source, _ := state.GetString(m.workDir, StateSourceKey); source != "" {
+		return trim(source)
+	}
+	return "default"
+}
+
+// ArtifactVariant is one generated artifact on disk, under its default or
+// a templated name.
+type ArtifactVariant struct {
+	Artifact string            `json:"artifact"` // default name: context, cached-context, ...
+	Path     string            `json:"path"`
+	Vars     map[string]string `json:"vars,omitempty"` // template variables recovered from the name
+	Size     int64             `json:"size"`
+	ModTime  time.Time         `json:"mod_time"`
+	Current  bool              `json:"current"` // the path this checkout resolves to now
+}
+
+// ListArtifactVariants lists every generated artifact in the default
+// artifact directories whose name is a default name or matches the
+// artifact name template, sorted by path.
+func (m *Manager) ListArtifactVariants() ([]ArtifactVariant, error) {
+	current := map[string]bool{
+		m.ResolveContextPath():                true,
+		m.ResolveContextFilesListPath():       true,
+		m.ResolveCachedContextPath():          true,
+		m.ResolveCachedContextFilesListPath(): true,
+	}
+	dirs := make(map[string]bool)
+	for p := range current {
+		dirs[filepath.Dir(p)] = true
+	}
+
+	tmpl := m.artifactTemplate()
+	var variants []ArtifactVariant
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasSuffix(e.Name(), ChecksumExt) {
+				continue
+			}
+			artifact, vars, ok := matchArtifactName(tmpl, e.Name())
+			if !ok {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			variants = append(variants, ArtifactVariant{
+				Artifact: artifact,
+				Path:     path,
+				Vars:     vars,
+				Size:     info.Size(),
+				ModTime:  info.ModTime(),
+				Current:  current[path],
+			})
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Path < variants[j].Path })
+	return variants, nil
+}
+
+// matchArtifactName reports which artifact file is a variant of: a default
+// name, or a name the template produces, with the variable values it
+// encodes. Variables are matched shortest-first, so values containing the
+// template's separators are attributed to the last variable.
+func matchArtifactName(tmpl, file string) (string, map[string]string, bool) {
+	for _, name := range artifactNames {
+		if file == name {
+			return name, nil, true
+		}
+	}
+	if tmpl == "" {
+		return "", nil, false
+	}
+	for _, name := range artifactNames {
+		var keys []string
+		pattern := regexp.QuoteMeta(tmpl)
+		pattern = regexp.MustCompile(`\\\{([a-z]+)\\\}`).ReplaceAllStringFunc(pattern, func(v string) string {
+			key := strings.Trim(v, `\{}`)
+			if key == "name" {
+				return regexp.QuoteMeta(name)
+			}
+			keys = append(keys, key)
+			return "(.+?)"
+		})
+		match := regexp.MustCompile("^" + pattern + "$").FindStringSubmatch(file)
+		if match == nil {
+			continue
+		}
+		vars := make(map[string]string, len(keys))
+		for i, key := range keys {
+			vars[key] = match[i+1]
+		}
+		return name, vars, true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,69 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArtifactNameTemplate(t *testing.T) {
+	for _, tmpl := range []string{"{name}-{branch}", "{name}.{worktree}.{ruleset}"} {
+		if err := validateArtifactTemplate(tmpl); err != nil {
+			t.Errorf("validateArtifactTemplate(%q): %v", tmpl, err)
+		}
+	}
+	for _, tmpl := range []string{"context-{branch}", "{name}/{branch}", "{name}-{user}"} {
+		if err := validateArtifactTemplate(tmpl); err == nil {
+			t.Errorf("validateArtifactTemplate(%q): expected an error", tmpl)
+		}
+	}
+
+	vars := map[string]string{"branch": sanitizeArtifactVar("feature/login"), "ruleset": "review"}
+	if got := expandArtifactName("{name}-{branch}-{ruleset}", "cached-context", vars); got != "cached-context-feature-login-review" {
+		t.Errorf("expandArtifactName = %q", got)
+	}
+}
+
+func TestMatchArtifactName(t *testing.T) {
+	tmpl := "{name}-{branch}-{ruleset}"
+	for file, want := range map[string]struct {
+		artifact string
+		vars     map[string]string
+	}{
+		"context":                         {"context", nil},
+		"context-main-default":            {"context", map[string]string{"branch": "main", "ruleset": "default"}},
+		"context-files-main-default":      {"context-files", map[string]string{"branch": "main", "ruleset": "default"}},
+		"cached-context-files-dev-review": {"cached-context-files", map[string]string{"branch": "dev", "ruleset": "review"}},
+	} {
+		artifact, vars, ok := matchArtifactName(tmpl, file)
+		if !ok || artifact != want.artifact || !reflect.DeepEqual(vars, want.vars) {
+			t.Errorf("matchArtifactName(%q) = %q %v %v, want %q %v", file, artifact, vars, ok, want.artifact, want.vars)
+		}
+	}
+	for _, file := range []string{"rules", "context-main"} {
+		if _, _, ok := matchArtifactName(tmpl, file); ok {
+			t.Errorf("matchArtifactName(%q) matched", file)
+		}
+	}
+}
+
+func TestArtifactPathFollowsTemplate(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(RulesFileEnvVar, "")
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv(ArtifactNameEnvVar, "{name}-{worktree}-{ruleset}")
+	dir := filepath.Join(t.TempDir(), "wt")
+	def := filepath.Join(dir, ContextFile)
+
+	m := &Manager{workDir: dir}
+	if got, want := m.artifactPath(def), filepath.Join(dir, GroveDir, "context-wt-default"); got != want {
+		t.Errorf("artifactPath = %s, want %s", got, want)
+	}
+
+	// WithNoState reads no environment overrides, so the name is unchanged.
+	m = &Manager{workDir: dir, noState: true}
+	if got := m.artifactPath(def); got != def {
+		t.Errorf("WithNoState artifactPath = %s, want %s", got, def)
+	}
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -75,7 +76,11 @@ func (m *Manager) ResolveFilesWithAttribution(rulesContent string) (AttributionR
 	tmpFile.Close()
 
 	// 2. Use expandAllRules to get all rules with proper import handling
-	hotRules, coldRules, _, _, err := m.expandAllRules(tmpFile.Name(), make(map[string]bool), 0)
+	// The content comes from a rules file the temporary file stands in for,
+	// so the expansion keeps the grants that file was last expanded with.
+	run := newExpansionRun()
+	run.grants = m.currentGrants()
+	hotRules, coldRules, _, _, err := m.expandAllRules(tmpFile.Name(), run, 0)
 	if err != nil {
 		return nil, nil, nil, nil, nil, fmt.Errorf("failed to expand rules: %w", err)
 	}
@@ -86,13 +91,13 @@ func (m *Manager) ResolveFilesWithAttribution(rulesContent string) (AttributionR
 	// 4. Parse the original rulesContent separately to build the RuleInfo list for backward compatibility
 	// This preserves the original line numbers from the input content
 	var rawRules []RuleInfo
-	scanner := bufio.NewScanner(strings.NewReader(rulesContent))
+	scanner := bufio.NewScanner(bytes.NewReader(m.preprocessRules([]byte(rulesContent))))
 	lineNum := 1
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip comments, directives, and separators
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") || line == "---" {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") || line == scopeEnd || sectionSeparatorRegex.MatchString(line) {
 			lineNum++
 			continue
 		}
@@ -167,11 +172,26 @@ func (m *Manager) ResolveFilesWithAttribution(rulesContent string) (AttributionR
 	return result, rawRules, exclusions, filtered, excludedBy, nil
 }
 
-// matchPattern matches a file path against a pattern using gitignore-style matching
+// matchPattern matches a file path against a pattern using gitignore-style
+// matching, ignoring case only where the path's filesystem does.
 func (m *Manager) matchPattern(pattern, relPath string) bool {
-	// Normalize for case-insensitive filesystems (macOS/Windows)
-	normalizedPattern := strings.ToLower(pattern)
-	normalizedPath := strings.ToLower(relPath)
+	return matchRulePatternCase(pattern, relPath, !m.caseSensitiveFor(relPath))
+}
+
+// matchRulePattern implements matchPattern, always ignoring case; it depends
+// on no Manager state so filesystem-independent resolvers share it.
+func matchRulePattern(pattern, relPath string) bool {
+	return matchRulePatternCase(pattern, relPath, true)
+}
+
+// matchRulePatternCase is matchRulePattern with the case comparison chosen
+// by the caller.
+func matchRulePatternCase(pattern, relPath string, foldCase bool) bool {
+	normalizedPattern, normalizedPath := pattern, relPath
+	if foldCase {
+		normalizedPattern = strings.ToLower(pattern)
+		normalizedPath = strings.ToLower(relPath)
+	}
 
 	// Handle ** patterns
 	if strings.Contains(normalizedPattern, "**") {
@@ -0,0 +1,218 @@
+package context
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// cacheCounter counts the hits and misses of one in-process cache.
+type cacheCounter struct {
+	hits, misses atomic.Int64
+}
+
+func (c *cacheCounter) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// cacheCounters are the per-Manager cache statistics cx bench reports.
+type cacheCounters struct {
+	expand     cacheCounter // rules expansion memo; see expandmemo.go
+	walk       cacheCounter // walk index recordings; see walkindex.go
+	patterns   cacheCounter // per-pattern matches of a walk index
+	gitIgnored cacheCounter // gitignored file sets (memory or disk)
+}
+
+// Bench phases, in the order they run.
+const (
+	BenchPhaseExpand   = "expand"   // load and expand the rules file
+	BenchPhaseResolve  = "resolve"  // resolve hot and cold rules to files
+	BenchPhaseClassify = "classify" // classify the walked tree, as cx view and cx list do
+	BenchPhaseGenerate = "generate" // write the hot and cold context (to a scratch directory)
+)
+
+// BenchOptions configures Bench.
+type BenchOptions struct {
+	Runs int  // number of runs; at least 1
+	Cold bool // a fresh Manager per run, so no in-process cache survives between runs
+}
+
+// BenchPhase is the timing distribution of one phase over all runs.
+type BenchPhase struct {
+	Name     string  `json:"name"`
+	Runs     int     `json:"runs"`
+	MinMs    float64 `json:"min_ms"`
+	MedianMs float64 `json:"median_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	MeanMs   float64 `json:"mean_ms"`
+}
+
+// BenchCache is the hit rate of one in-process cache over all runs.
+type BenchCache struct {
+	Name    string  `json:"name"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// BenchReport is the result of Bench.
+type BenchReport struct {
+	WorkDir   string       `json:"work_dir"`
+	RulesPath string       `json:"rules_path"`
+	Runs      int          `json:"runs"`
+	Cold      bool         `json:"cold"`
+	HotFiles  int          `json:"hot_files"`
+	ColdFiles int          `json:"cold_files"`
+	Phases    []BenchPhase `json:"phases"`
+	Caches    []BenchCache `json:"caches"`
+}
+
+// Bench resolves and generates the context of workDir opts.Runs times and
+// reports the timing of each phase and the hit rates of the in-process
+// caches. Generated output goes to a scratch directory, so the project's
+// context files are left alone. The rules file is expanded once more
+// inside resolve, as every real resolution does, so resolve times include
+// a (normally memoized) expansion.
+func Bench(workDir string, opts BenchOptions) (*BenchReport, error) {
+	if opts.Runs < 1 {
+		return nil, fmt.Errorf("runs must be at least 1, got %d", opts.Runs)
+	}
+	scratch, err := os.MkdirTemp("", "cx-bench-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	newBenchManager := func() *Manager {
+		var m *Manager
+		if Standalone() {
+			m = newStandaloneManager(workDir)
+		} else {
+			m = newManagerWithOptions(workDir, nil)
+		}
+		m.SetPathsOverride(filepath.Join(scratch, "context"), filepath.Join(scratch, "cached-context"),
+			filepath.Join(scratch, "context-files"), filepath.Join(scratch, "cached-context-files"))
+		return m
+	}
+
+	report := &BenchReport{Runs: opts.Runs, Cold: opts.Cold}
+	timings := make(map[string][]time.Duration)
+	var managers []*Manager
+	m := newBenchManager()
+	report.WorkDir = m.workDir
+	for run := 0; run < opts.Runs; run++ {
+		if opts.Cold && run > 0 {
+			m = newBenchManager()
+		}
+		if run == 0 || opts.Cold {
+			managers = append(managers, m)
+		}
+		timed := func(phase string, fn func() error) error {
+			start := time.Now()
+			err := fn()
+			timings[phase] = append(timings[phase], time.Since(start))
+			if err != nil {
+				return fmt.Errorf("%s: %w", phase, err)
+			}
+			return nil
+		}
+
+		var rulesPath string
+		if err := timed(BenchPhaseExpand, func() error {
+			_, path, err := m.LoadRulesContent()
+			if err != nil || path == "" {
+				return err
+			}
+			rulesPath = path
+			_, _, _, _, err = m.expandAllRules(path, newExpansionRun(), 0)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		report.RulesPath = rulesPath
+
+		var s *ResolutionSession
+		if err := timed(BenchPhaseResolve, func() error {
+			s, err = m.NewResolutionSession()
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		report.HotFiles, report.ColdFiles = len(s.Hot), len(s.Cold)
+
+		if err := timed(BenchPhaseClassify, func() error {
+			_, err := s.Classify(false)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := timed(BenchPhaseGenerate, func() error {
+			if err := m.generateContextFromFilesAndTrees(s.Hot, s.Trees, true); err != nil {
+				return err
+			}
+			return m.generateCachedContextFromFiles(s.Cold)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, phase := range []string{BenchPhaseExpand, BenchPhaseResolve, BenchPhaseClassify, BenchPhaseGenerate} {
+		report.Phases = append(report.Phases, benchPhase(phase, timings[phase]))
+	}
+	caches := []struct {
+		name    string
+		counter func(*cacheCounters) *cacheCounter
+	}{
+		{"rules expansion", func(c *cacheCounters) *cacheCounter { return &c.expand }},
+		{"walk index", func(c *cacheCounters) *cacheCounter { return &c.walk }},
+		{"pattern matches", func(c *cacheCounters) *cacheCounter { return &c.patterns }},
+		{"gitignore", func(c *cacheCounters) *cacheCounter { return &c.gitIgnored }},
+	}
+	for _, cache := range caches {
+		bc := BenchCache{Name: cache.name}
+		for _, m := range managers {
+			counter := cache.counter(&m.cacheStats)
+			bc.Hits += counter.hits.Load()
+			bc.Misses += counter.misses.Load()
+		}
+		if total := bc.Hits + bc.Misses; total > 0 {
+			bc.HitRate = float64(bc.Hits) / float64(total)
+		}
+		report.Caches = append(report.Caches, bc)
+	}
+	return report, nil
+}
+
+// benchPhase summarizes one phase's run times.
+func benchPhase(name string, times []time.Duration) BenchPhase {
+	p := BenchPhase{Name: name, Runs: len(times)}
+	if len(times) == 0 {
+		return p
+	}
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	p.MinMs = ms(sorted[0])
+	p.MaxMs = ms(sorted[len(sorted)-1])
+	p.MedianMs = ms(sorted[len(sorted)/2])
+	if len(sorted)%2 == 0 {
+		p.MedianMs = (ms(sorted[len(sorted)/2-1]) + p.MedianMs) / 2
+	}
+	p.P90Ms = ms(sorted[int(math.Ceil(0.9*float64(len(sorted))))-1])
+	p.MeanMs = ms(total / time.Duration(len(sorted)))
+	return p
+}
@@ -0,0 +1,54 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBenchPhase(t *testing.T) {
+	ms := time.Millisecond
+	p := benchPhase("resolve", []time.Duration{40 * ms, 10 * ms, 30 * ms, 20 * ms})
+	want := BenchPhase{Name: "resolve", Runs: 4, MinMs: 10, MedianMs: 25, P90Ms: 40, MaxMs: 40, MeanMs: 25}
+	if p != want {
+		t.Errorf("benchPhase() = %+v, want %+v", p, want)
+	}
+}
+
+func TestBench(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(NoCacheEnvVar, "")
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "notes.md"), "# notes\n")
+	fsWriteString(t, filepath.Join(dir, ActiveRulesFile), "main.go\n---\n*.md\n")
+
+	report, err := Bench(dir, BenchOptions{Runs: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HotFiles != 1 || report.ColdFiles != 1 {
+		t.Errorf("files = %d hot, %d cold; want 1 and 1", report.HotFiles, report.ColdFiles)
+	}
+	if len(report.Phases) != 4 {
+		t.Fatalf("got %d phases, want 4", len(report.Phases))
+	}
+	for _, p := range report.Phases {
+		if p.Runs != 3 {
+			t.Errorf("phase %s ran %d times, want 3", p.Name, p.Runs)
+		}
+	}
+	for _, c := range report.Caches {
+		if c.Name == "walk index" && c.Hits == 0 {
+			t.Errorf("warm runs never reused the walk index: %+v", c)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ContextFile)); !os.IsNotExist(err) {
+		t.Errorf("bench wrote the project's context file (stat err = %v)", err)
+	}
+
+	if _, err := Bench(dir, BenchOptions{Runs: 0}); err == nil {
+		t.Error("expected an error for zero runs")
+	}
+}
@@ -0,0 +1,183 @@
+package context
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Binary detection. Walks drop files cx classifies as binary: known binary
+// extensions (BinaryExtensions), then a content sniff of extensionless
+// files. cx.binary extends the classification:
+//
+//	cx:
+//	  binary:
+//	    extensions: [.parquet, .onnx]  # also binary
+//	    text_extensions: [.svg]        # never binary
+//	    max_size: 2MB                  # larger files count as binary
+//
+// and two rule modifiers override it for the files a rule matches:
+//
+//	notebooks/**/*.ipynb @text   matched even when classified as binary
+//	**/*.pb.go @binary           treated as binary: excluded from the tier
+//	                             whatever other rules include them
+//
+// A @binary rule is an exclusion evaluated after every other rule of its
+// tier, so its position in the rules does not matter.
+
+const (
+	directiveText   = "text"
+	directiveBinary = "binary"
+)
+
+// BinaryConfig is cx.binary.
+type BinaryConfig struct {
+	// Extensions are treated as binary in addition to BinaryExtensions.
+	Extensions []string `yaml:"extensions,omitempty" toml:"extensions,omitempty"`
+	// TextExtensions are never treated as binary, even when listed in
+	// BinaryExtensions.
+	TextExtensions []string `yaml:"text_extensions,omitempty" toml:"text_extensions,omitempty"`
+	// MaxSize treats larger files as binary, e.g. "2MB". Empty means no
+	// limit.
+	MaxSize string `yaml:"max_size,omitempty" toml:"max_size,omitempty"`
+}
+
+// binaryPolicy is a parsed BinaryConfig.
+type binaryPolicy struct {
+	binary  map[string]bool
+	text    map[string]bool
+	maxSize int64
+	key     string // identifies the policy for the walk index
+}
+
+// warnedMaxSizes holds the invalid max_size values already reported.
+var warnedMaxSizes sync.Map
+
+// binaryPolicy returns the binary classification configured for the
+// working directory. An invalid max_size is reported once and ignored.
+func (m *Manager) binaryPolicy() *binaryPolicy {
+	cfg := m.cxConfig().Binary
+	p := &binaryPolicy{binary: normalizeExtensions(cfg.Extensions), text: normalizeExtensions(cfg.TextExtensions)}
+	if cfg.MaxSize != "" {
+		size, err := parseByteSize(strings.ReplaceAll(cfg.MaxSize, " ", ""))
+		if err != nil {
+			if _, warned := warnedMaxSizes.LoadOrStore(cfg.MaxSize, true); !warned {
+				Warnf("ignoring cx.binary.max_size %q: %v", cfg.MaxSize, err)
+			}
+		} else {
+			p.maxSize = size
+		}
+	}
+	p.key = fmt.Sprintf("%s|%s|%d", strings.Join(sortedKeys(p.binary), ","), strings.Join(sortedKeys(p.text), ","), p.maxSize)
+	return p
+}
+
+// normalizeExtensions lowercases exts and adds missing leading dots.
+func normalizeExtensions(exts []string) map[string]bool {
+	set := make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		set[ext] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isBinary reports whether the walked file at path is binary under p.
+func (p *binaryPolicy) isBinary(path string, d fs.DirEntry) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if p.text[ext] {
+		return false
+	}
+	if p.binary[ext] {
+		return true
+	}
+	if p.maxSize > 0 {
+		if info, err := d.Info(); err == nil && info != nil && info.Size() > p.maxSize {
+			return true
+		}
+	}
+	return isBinaryFile(path)
+}
+
+// hasDirective reports whether directives include name in any clause.
+func hasDirective(directives []SearchDirective, name string) bool {
+	for _, d := range directives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryIncluder is implemented by resolution contexts that can walk
+// without dropping binary files, for rules marked @text.
+type binaryIncluder interface {
+	withBinaryFiles() ResolutionContext
+}
+
+func (c *prodResolutionContext) withBinaryFiles() ResolutionContext {
+	cc := *c
+	cc.keepBinary = true
+	return &cc
+}
+
+// hoistBinaryRules turns the rules marked @binary into exclusions and moves
+// them after every other rule, leaving nodes unchanged.
+func hoistBinaryRules(nodes []RuleNode) []RuleNode {
+	var rest, binary []RuleNode
+	for _, n := range nodes {
+		f, ok := n.(*FilterNode)
+		if !ok || !hasDirective(f.Directives, directiveBinary) {
+			rest = append(rest, n)
+			continue
+		}
+		if child := excludedCopy(f.Child); child != nil {
+			binary = append(binary, &FilterNode{Child: child, Directives: f.Directives, LineNum: f.LineNum, RawText: f.RawText, Excluded: true})
+		}
+	}
+	if len(binary) == 0 {
+		return nodes
+	}
+	return append(rest, binary...)
+}
+
+// excludedCopy returns an excluding copy of a pattern node.
+func excludedCopy(n RuleNode) RuleNode {
+	switch n := n.(type) {
+	case *GlobNode:
+		c := *n
+		c.Excluded = true
+		return &c
+	case *LiteralNode:
+		c := *n
+		c.Excluded = true
+		return &c
+	case *ImportNode:
+		c := *n
+		c.Excluded = true
+		return &c
+	case *CommandNode:
+		c := *n
+		c.Excluded = true
+		return &c
+	}
+	return nil
+}
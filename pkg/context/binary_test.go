@@ -0,0 +1,50 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestBinaryRuleModifiers verifies that @binary excludes wherever it
+// appears and @text includes files classified as binary.
+func TestBinaryRuleModifiers(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "api", "api.pb.go"), "package api\n")
+	fsWriteString(t, filepath.Join(dir, "fixture.dat"), "plain text\n")
+	rulesPath := filepath.Join(dir, RulesDir, "mods"+RulesExt)
+	fsWriteString(t, rulesPath, "**/*.pb.go @binary\n**/*.go\n*.dat @text\n")
+
+	m := NewManager(dir)
+	hot, _, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"fixture.dat", "main.go"}; !reflect.DeepEqual(hot, want) {
+		t.Errorf("resolved %v, want %v", hot, want)
+	}
+}
+
+func TestBinaryConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  binary:\n    extensions: [log]\n    text_extensions: [.DAT]\n    max_size: 16B\n")
+	fsWriteString(t, filepath.Join(dir, "small.go"), "package x\n")
+	fsWriteString(t, filepath.Join(dir, "large.go"), "package x\n\nvar big = 1\n")
+	fsWriteString(t, filepath.Join(dir, "run.log"), "ok\n")
+	fsWriteString(t, filepath.Join(dir, "table.dat"), "a,b\n")
+	rulesPath := filepath.Join(dir, RulesDir, "all"+RulesExt)
+	fsWriteString(t, rulesPath, "*\n")
+
+	m := NewManager(dir)
+	hot, _, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// `*` matches the rules file too; grove.yml and large.go exceed max_size.
+	if want := []string{".cx/all.rules", "small.go", "table.dat"}; !reflect.DeepEqual(hot, want) {
+		t.Errorf("resolved %v, want %v", hot, want)
+	}
+}
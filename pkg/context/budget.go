@@ -0,0 +1,170 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Token budgets. `@budget: <tokens>` in the rules, or `cx generate
+// --max-tokens` (which takes precedence), caps the estimated tokens of the
+// generated hot and cold files together:
+//
+//	@budget: 120k
+//
+// When the resolved files exceed it, generation trims them instead of
+// writing an oversized context. Cold files are dropped before hot ones, and
+// within a tier files are dropped from the end of the order they are
+// written in (frontmatter priority, then rule order), as for per-model
+// variants. Files declared with @require: are never dropped; when they alone
+// exceed the budget, generation proceeds over budget. Trees are not counted.
+
+// budgetDirective is the `@budget:` line of a rules file.
+type budgetDirective struct {
+	Tokens  int
+	Raw     string
+	LineNum int
+	Err     error // set when the value is not a token count
+}
+
+// parseBudgetDirective returns the last `@budget:` line of content, or nil
+// when there is none.
+func parseBudgetDirective(content []byte) *budgetDirective {
+	var d *budgetDirective
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text())))
+		if !strings.HasPrefix(line, "@budget:") {
+			continue
+		}
+		raw := strings.TrimSpace(strings.TrimPrefix(line, "@budget:"))
+		tokens, err := ParseTokenCount(raw)
+		d = &budgetDirective{Tokens: tokens, Raw: raw, LineNum: lineNum, Err: err}
+	}
+	return d
+}
+
+// BudgetTrim reports how a token budget trimmed a generation.
+type BudgetTrim struct {
+	Budget int    `json:"budget"`
+	Source string `json:"source"` // "--max-tokens" or "@budget: (line N)"
+	// Tokens is the estimated total of the kept files; OverBudget is set
+	// when required files kept it above Budget.
+	Tokens      int           `json:"tokens"`
+	OverBudget  bool          `json:"over_budget,omitempty"`
+	DroppedHot  []OmittedFile `json:"dropped_hot,omitempty"`
+	DroppedCold []OmittedFile `json:"dropped_cold,omitempty"`
+}
+
+// Dropped returns the number of files the budget dropped.
+func (t *BudgetTrim) Dropped() int {
+	return len(t.DroppedHot) + len(t.DroppedCold)
+}
+
+// DroppedTokens returns the estimated tokens of the dropped files.
+func (t *BudgetTrim) DroppedTokens() int {
+	total := 0
+	for _, f := range append(append([]OmittedFile{}, t.DroppedHot...), t.DroppedCold...) {
+		total += f.Tokens
+	}
+	return total
+}
+
+// SetMaxTokens sets a token budget for generation that takes precedence over
+// the rules' `@budget:`. Zero defers to the rules.
+func (m *Manager) SetMaxTokens(tokens int) {
+	m.maxTokens = tokens
+}
+
+// BudgetTrim returns how the token budget trimmed the most recent
+// generation, or nil when no budget applied.
+func (m *Manager) BudgetTrim() *BudgetTrim {
+	m.genMu.Lock()
+	defer m.genMu.Unlock()
+	return m.budgetTrim
+}
+
+// tokenBudget returns the budget in effect for rulesContent and where it
+// comes from; zero means no budget.
+func (m *Manager) tokenBudget(rulesContent []byte) (int, string, error) {
+	if m.maxTokens > 0 {
+		return m.maxTokens, "--max-tokens", nil
+	}
+	d := parseBudgetDirective(rulesContent)
+	if d == nil {
+		return 0, "", nil
+	}
+	if d.Err != nil {
+		return 0, "", fmt.Errorf("line %d: invalid @budget: %q: %w", d.LineNum, d.Raw, d.Err)
+	}
+	return d.Tokens, fmt.Sprintf("@budget: (line %d)", d.LineNum), nil
+}
+
+// budgetFiles trims hot and cold to the token budget of rulesContent. The
+// budget covers both tiers, so a caller generating only one of them sets
+// resolveHot or resolveCold to have the other resolved from the active
+// rules. Without a budget the files are returned unchanged.
+func (m *Manager) budgetFiles(rulesContent []byte, hot, cold []string, resolveHot, resolveCold bool) ([]string, []string, error) {
+	budget, source, err := m.tokenBudget(rulesContent)
+	if err != nil || budget == 0 {
+		return hot, cold, err
+	}
+	if resolveHot {
+		if hot, _, err = m.ResolveFilesAndTreesFromRules(); err != nil {
+			return nil, nil, fmt.Errorf("error resolving files from rules: %w", err)
+		}
+	}
+	if resolveCold {
+		if cold, err = m.ResolveColdContextFiles(); err != nil {
+			return nil, nil, fmt.Errorf("error resolving cold context files: %w", err)
+		}
+	}
+
+	required := make(map[string]bool)
+	for _, p := range RequiredPaths(rulesContent) {
+		required[m.requirePathKey(p)] = true
+	}
+	isRequired := func(f string) bool { return required[m.requirePathKey(f)] }
+
+	tokens := make(map[string]int, len(hot)+len(cold))
+	provider := GetStatsProvider()
+	hotTotal := 0
+	for _, f := range append(append([]string{}, hot...), cold...) {
+		if info, err := provider.GetFileStats(m.requirePathKey(f)); err == nil {
+			tokens[f] = info.Tokens
+		}
+	}
+	for _, f := range hot {
+		hotTotal += tokens[f]
+	}
+
+	// Cold files give way first: they get whatever the hot files leave.
+	keptCold, droppedCold, coldTotal := fitBudget(cold, tokens, isRequired, budget-hotTotal)
+	keptHot, droppedHot, hotTotal := fitBudget(hot, tokens, isRequired, budget-coldTotal)
+
+	trim := &BudgetTrim{
+		Budget:      budget,
+		Source:      source,
+		Tokens:      hotTotal + coldTotal,
+		OverBudget:  hotTotal+coldTotal > budget,
+		DroppedHot:  droppedHot,
+		DroppedCold: droppedCold,
+	}
+	m.genMu.Lock()
+	m.budgetTrim = trim
+	m.genMu.Unlock()
+
+	if trim.Dropped() > 0 {
+		m.log.WithFields(logrus.Fields{
+			"budget":  budget,
+			"dropped": trim.Dropped(),
+		}).Info("Trimmed context to token budget")
+	}
+	return keptHot, keptCold, nil
+}
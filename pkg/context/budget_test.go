@@ -0,0 +1,72 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseBudgetDirective(t *testing.T) {
+	if d := parseBudgetDirective([]byte("src/**\n")); d != nil {
+		t.Errorf("no @budget: line, got %+v", d)
+	}
+	d := parseBudgetDirective([]byte("@budget: 200k\nsrc/**\n@budget: 120k # tighter\n"))
+	if d == nil || d.Tokens != 120000 || d.LineNum != 3 || d.Err != nil {
+		t.Errorf("last @budget: should win, got %+v", d)
+	}
+	if d := parseBudgetDirective([]byte("@budget: lots\n")); d == nil || d.Err == nil {
+		t.Errorf("expected an invalid budget, got %+v", d)
+	}
+}
+
+// TestBudgetFiles verifies that cold files give way before hot ones, that
+// hot files are dropped from the end, and that --max-tokens overrides
+// @budget:.
+func TestBudgetFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a.go": 4000, "b.go": 400, "req.go": 400, "c.go": 4000} {
+		fsWriteString(t, filepath.Join(dir, name), strings.Repeat("x", size))
+	}
+	m := NewManager(dir, WithNoState())
+	tokens := func(name string) int {
+		info, err := GetStatsProvider().GetFileStats(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Tokens
+	}
+	hot := []string{"a.go", "req.go", "b.go"}
+	cold := []string{"c.go"}
+
+	m.SetMaxTokens(tokens("a.go") + tokens("b.go") + tokens("req.go"))
+	keptHot, keptCold, err := m.budgetFiles(nil, hot, cold, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keptHot, hot) || len(keptCold) != 0 {
+		t.Errorf("cold should go first: hot %v, cold %v", keptHot, keptCold)
+	}
+	trim := m.BudgetTrim()
+	if trim.Source != "--max-tokens" || len(trim.DroppedCold) != 1 || trim.DroppedCold[0].Path != "c.go" || trim.OverBudget {
+		t.Errorf("unexpected trim: %+v", trim)
+	}
+
+	m.SetMaxTokens(0)
+	rules := []byte("@budget: " + strconv.Itoa(tokens("req.go")) + "\n@require: req.go\n")
+	keptHot, _, err = m.budgetFiles(rules, hot, cold, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keptHot, []string{"req.go"}) {
+		t.Errorf("required file should be kept alone, got %v", keptHot)
+	}
+	if trim := m.BudgetTrim(); trim.Source != "@budget: (line 1)" || len(trim.DroppedHot) != 2 || trim.DroppedHot[0].Path != "b.go" {
+		t.Errorf("unexpected trim: %+v", trim)
+	}
+
+	if _, _, err := m.budgetFiles([]byte("@budget: lots\n"), hot, cold, false, false); err == nil {
+		t.Error("expected an error for an invalid @budget:")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -112,7 +113,7 @@ func GetStatsProvider() *StatsProvider {
 	providerOnce.Do(func() {
 		rm, err := repo.NewManager()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to initialize repo manager for stats provider: %v\n", err)
+			Warnf("failed to initialize repo manager for stats provider: %v", err)
 		}
 
 		provider := &StatsProvider{
@@ -133,7 +134,7 @@ func (sp *StatsProvider) GetFileStats(filePath string) (FileInfo, error) {
 
 	// Check if the file is inside a managed worktree
 	worktreePath, isWorktree := sp.findWorktreeForPath(absPath)
-	if isWorktree {
+	if isWorktree && !cachesDisabled() {
 		// Get the cache for this worktree (will generate if it doesn't exist)
 		cache, err := sp.getWorktreeCache(worktreePath)
 		if err != nil {
@@ -141,12 +142,13 @@ func (sp *StatsProvider) GetFileStats(filePath string) (FileInfo, error) {
 			return sp.getFileStatsUncached(filePath)
 		}
 
-		// Look up the file in the cache
+		// Look up the file in the cache. Tokens are re-estimated from the
+		// cached size so a calibration change applies to cached worktrees.
 		if stats, ok := cache.Files[absPath]; ok {
 			return FileInfo{
 				Path:   stats.Path,
 				Size:   stats.Size,
-				Tokens: stats.Tokens,
+				Tokens: EstimateTokens(stats.Path, stats.Size),
 			}, nil
 		}
 	}
@@ -272,7 +274,14 @@ func (sp *StatsProvider) generateCacheForWorktree(worktreePath string) (*RepoCac
 
 	err := filepath.WalkDir(worktreePath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
-			return err
+			// Skip unreadable entries below the root unless walks are strict
+			if path == worktreePath || strictWalkDefault.Load() {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
 		// Skip .git directories
@@ -280,7 +289,7 @@ func (sp *StatsProvider) generateCacheForWorktree(worktreePath string) (*RepoCac
 			return filepath.SkipDir
 		}
 
-		if d.IsDir() || IsBinaryFile(path) {
+		if d.IsDir() || specialEntryKind(path, d) != "" || IsBinaryFile(path) {
 			return nil
 		}
 
@@ -316,7 +325,7 @@ func (sp *StatsProvider) generateCacheForWorktree(worktreePath string) (*RepoCac
 	}
 	if err := os.WriteFile(cacheFilePath, data, 0o644); err != nil { //nolint:gosec // cache file, not sensitive
 		// Non-fatal, just log a warning
-		fmt.Fprintf(os.Stderr, "Warning: failed to save stats cache for %s: %v\n", worktreePath, err)
+		Warnf("failed to save stats cache for %s: %v", worktreePath, err)
 	}
 
 	return cache, nil
@@ -340,9 +349,10 @@ func IsBinaryFile(path string) bool {
 	if commonTextFiles[basename] {
 		return false
 	}
-	file, err := os.Open(path)
+	file, err := openRegular(path)
 	if err != nil {
-		return false
+		// Special files are never text context
+		return errors.Is(err, errSpecialFile)
 	}
 	defer file.Close()
 	buffer := make([]byte, 512)
@@ -0,0 +1,194 @@
+package context
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/state"
+)
+
+// Cache provenance. Each cold artifact generation records which rules
+// produced it, when, and its size, so `cx list-cache --long` can describe
+// every cached context on disk, including variants left by other branches
+// and rule sets (see artifactname.go). Records live in grove state under
+// CacheProvenanceStateKey, keyed by artifact path.
+
+// CacheProvenanceStateKey is the grove-core state key holding the JSON map
+// of cold artifact paths to their CacheProvenance.
+const CacheProvenanceStateKey = "cx.cache_provenance"
+
+// CacheProvenance records the generation of one cold artifact.
+type CacheProvenance struct {
+	Rules       string    `json:"rules,omitempty"` // the rules file generated from
+	Ruleset     string    `json:"ruleset,omitempty"`
+	FilesList   string    `json:"files_list,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       int       `json:"files"`
+	Tokens      int       `json:"tokens"`
+}
+
+// loadCacheProvenance returns the recorded provenance of every cold
+// artifact, or nil when there is none or state is unavailable.
+func (m *Manager) loadCacheProvenance() map[string]CacheProvenance {
+	if m.noState || Standalone() {
+		return nil
+	}
+	raw, err := state.GetString(m.workDir, CacheProvenanceStateKey)
+	if err != nil || raw == "" {
+		return nil
+	}
+	var records map[string]CacheProvenance
+	if err := json.Unmarshal([]byte(raw), &records); err != nil {
+		return nil
+	}
+	return records
+}
+
+// recordCacheProvenance stores the provenance of the cold artifact just
+// written to path, dropping records of artifacts no longer on disk.
+func (m *Manager) recordCacheProvenance(path, filesList string, files, tokens int) {
+	if m.noState || Standalone() {
+		return
+	}
+	_, rulesPath := m.generatingRules()
+	ruleset := m.activeRulesetName()
+	if m.explicitRulesPath != "" {
+		ruleset = strings.TrimSuffix(filepath.Base(m.explicitRulesPath), RulesExt)
+	}
+
+	records := m.loadCacheProvenance()
+	if records == nil {
+		records = make(map[string]CacheProvenance)
+	}
+	for p := range records {
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			delete(records, p)
+		}
+	}
+	records[path] = CacheProvenance{
+		Rules:       rulesPath,
+		Ruleset:     ruleset,
+		FilesList:   filesList,
+		GeneratedAt: time.Now().UTC(),
+		Files:       files,
+		Tokens:      tokens,
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	if err := state.Set(m.workDir, CacheProvenanceStateKey, string(data)); err != nil {
+		m.log.WithError(err).Warn("failed to record cache provenance")
+	}
+}
+
+// CachedArtifactInfo describes one cold artifact on disk.
+type CachedArtifactInfo struct {
+	Path    string `json:"path"`
+	Current bool   `json:"current"` // the artifact this checkout resolves to now
+	// Rules and Ruleset name the rules that produced the artifact; empty
+	// when it was generated before provenance was recorded and its name
+	// does not encode a rule set.
+	Rules       string        `json:"rules,omitempty"`
+	Ruleset     string        `json:"ruleset,omitempty"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Age         time.Duration `json:"age"`
+	// ExpireTime, Frozen and NoExpire are the producing rules' @expire-time,
+	// @freeze-cache and @no-expire.
+	ExpireTime time.Duration `json:"expire_time,omitempty"`
+	Expired    bool          `json:"expired"`
+	Frozen     bool          `json:"frozen"`
+	NoExpire   bool          `json:"no_expire,omitempty"`
+	Files      int           `json:"files"`
+	Tokens     int           `json:"tokens"`
+	// Changed lists the cached files modified since the artifact was
+	// generated, and Missing those deleted since; both are empty when the
+	// artifact's files list is unknown.
+	Changed []string `json:"changed,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Stale reports whether the artifact no longer reflects its sources.
+func (a CachedArtifactInfo) Stale() bool {
+	return a.Expired || len(a.Changed) > 0 || len(a.Missing) > 0
+}
+
+// ListCachedArtifacts describes every cold artifact in the default artifact
+// directories: the current one and the variants of other branches and rule
+// sets.
+func (m *Manager) ListCachedArtifacts() ([]CachedArtifactInfo, error) {
+	variants, err := m.ListArtifactVariants()
+	if err != nil {
+		return nil, err
+	}
+	records := m.loadCacheProvenance()
+	activeRules, activePath, _ := m.LoadRulesContent()
+
+	var infos []CachedArtifactInfo
+	now := time.Now()
+	for _, v := range variants {
+		if v.Artifact != filepath.Base(CachedContextFile) {
+			continue
+		}
+		info := CachedArtifactInfo{Path: v.Path, Current: v.Current, GeneratedAt: v.ModTime, Age: now.Sub(v.ModTime)}
+		rec, recorded := records[v.Path]
+		switch {
+		case recorded:
+			info.Rules, info.Ruleset, info.Files, info.Tokens = rec.Rules, rec.Ruleset, rec.Files, rec.Tokens
+		case v.Current:
+			info.Rules, info.Ruleset = activePath, m.activeRulesetName()
+			rec.FilesList = m.ResolveCachedContextFilesListPath()
+		default:
+			info.Ruleset = v.Vars["ruleset"]
+		}
+
+		rulesContent := activeRules
+		if info.Rules != "" && info.Rules != activePath {
+			rulesContent, _ = os.ReadFile(info.Rules)
+		}
+		if info.Rules != "" && rulesContent != nil {
+			if parsed, err := m.parseRulesFileContent(rulesContent); err == nil {
+				info.Frozen, info.NoExpire, info.ExpireTime = parsed.freezeCache, parsed.disableExpiration, parsed.expireTime
+			}
+		}
+		info.Expired = info.ExpireTime > 0 && !info.Frozen && !info.NoExpire && info.Age > info.ExpireTime
+
+		if rec.FilesList != "" {
+			if listed, err := m.ReadFilesList(rec.FilesList); err == nil {
+				m.checkCachedSources(&info, listed, !recorded)
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// checkCachedSources fills in the changed and missing files of info from
+// the files it lists, and, when count is set, its file and token counts.
+func (m *Manager) checkCachedSources(info *CachedArtifactInfo, listed []string, count bool) {
+	provider := GetStatsProvider()
+	for _, f := range listed {
+		path := m.requirePathKey(f)
+		st, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			info.Missing = append(info.Missing, f)
+			continue
+		case err != nil:
+			continue
+		case st.ModTime().After(info.GeneratedAt):
+			info.Changed = append(info.Changed, f)
+		}
+		if count {
+			if fi, err := provider.GetFileStats(path); err == nil {
+				info.Tokens += fi.Tokens
+			}
+		}
+	}
+	if count {
+		info.Files = len(listed)
+	}
+}
@@ -0,0 +1,52 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListCachedArtifacts(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	write := func(name, content string, mtime time.Time) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	now := time.Now()
+	write(ActiveRulesFile, "@expire-time 1h\n---\n*.go\n", now)
+	a := write("a.go", "package a\n", now)
+	c := write("c.go", "package c\n", now.Add(-3*time.Hour))
+	b := filepath.Join(dir, "b.go") // listed but deleted since
+	write(CachedContextFilesListFile, a+"\n"+b+"\n"+c+"\n", now.Add(-2*time.Hour))
+	artifact := write(CachedContextFile, "<context></context>\n", now.Add(-2*time.Hour))
+
+	infos, err := NewManager(dir, WithNoState()).ListCachedArtifacts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("got %d cached artifacts, want 1: %+v", len(infos), infos)
+	}
+	got := infos[0]
+	if got.Path != artifact || !got.Current || got.Ruleset != "default" || got.Files != 3 {
+		t.Errorf("artifact = %+v", got)
+	}
+	if got.ExpireTime != time.Hour || !got.Expired || got.Frozen {
+		t.Errorf("expiry: expire %s, expired %v, frozen %v; want 1h, expired", got.ExpireTime, got.Expired, got.Frozen)
+	}
+	if !reflect.DeepEqual(got.Changed, []string{a}) || !reflect.DeepEqual(got.Missing, []string{b}) || !got.Stale() {
+		t.Errorf("changed %v, missing %v; want [%s] and [%s]", got.Changed, got.Missing, a, b)
+	}
+}
@@ -0,0 +1,232 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/pkg/repo"
+)
+
+// CacheValidation reports on the cold (cached) context artifact and its
+// files list, as `cx validate` checks them.
+type CacheValidation struct {
+	Artifact  string `json:"artifact"`
+	FilesList string `json:"filesList"`
+	Exists    bool   `json:"exists"`
+	Frozen    bool   `json:"frozen"`
+	// ExpireTime is the @expire-time of the rules; zero means the cache
+	// never expires on its own.
+	ExpireTime time.Duration `json:"expireTime,omitempty"`
+	Age        time.Duration `json:"age,omitempty"`
+	Expired    bool          `json:"expired"`
+	// ChecksumError is set when the artifact's sidecar checksum no longer
+	// matches (see VerifyArtifactChecksum).
+	ChecksumError string `json:"checksumError,omitempty"`
+	// NotInArtifact lists files in the files list that the artifact has no
+	// block for, and NotInList the reverse.
+	NotInArtifact []string `json:"notInArtifact,omitempty"`
+	NotInList     []string `json:"notInList,omitempty"`
+	// MissingFiles lists files in the files list that no longer exist.
+	MissingFiles []string `json:"missingFiles,omitempty"`
+	// DeletedRepos lists the git rule checkouts a frozen cache still
+	// points into but that have been removed from disk.
+	DeletedRepos []DeletedRepo `json:"deletedRepos,omitempty"`
+}
+
+// DeletedRepo is a removed git rule checkout referenced by a frozen cache.
+type DeletedRepo struct {
+	Path  string `json:"path"`
+	URL   string `json:"url,omitempty"`
+	Files int    `json:"files"`
+}
+
+// Issues returns the number of problems found.
+func (v *CacheValidation) Issues() int {
+	n := len(v.NotInArtifact) + len(v.NotInList) + len(v.MissingFiles) + len(v.DeletedRepos)
+	if v.Expired {
+		n++
+	}
+	if v.ChecksumError != "" {
+		n++
+	}
+	return n
+}
+
+// ValidateCachedContext checks the cached context artifact: that its
+// checksum still matches when one was written, that it agrees with the
+// cached files list, that it has not outlived @expire-time (unless frozen or
+// @no-expire), and that every listed file still exists. Missing files of a
+// frozen cache are grouped by the git rule checkout they came from when
+// that checkout is gone. A workspace without a cached artifact yields a
+// result with Exists false.
+func (m *Manager) ValidateCachedContext() (*CacheValidation, error) {
+	v := &CacheValidation{
+		Artifact:  m.ResolveCachedContextPath(),
+		FilesList: m.ResolveCachedContextFilesListPath(),
+	}
+	info, err := os.Stat(v.Artifact)
+	if os.IsNotExist(err) {
+		return v, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", v.Artifact, err)
+	}
+	v.Exists = true
+	v.Age = time.Since(info.ModTime())
+
+	if v.Frozen, err = m.ShouldFreezeCache(); err != nil {
+		return nil, err
+	}
+	noExpire, err := m.ShouldDisableExpiration()
+	if err != nil {
+		return nil, err
+	}
+	if v.ExpireTime, err = m.GetExpireTime(); err != nil {
+		return nil, err
+	}
+	v.Expired = v.ExpireTime > 0 && !v.Frozen && !noExpire && v.Age > v.ExpireTime
+
+	if err := VerifyArtifactChecksum(v.Artifact); err != nil && !errors.Is(err, ErrNoChecksum) {
+		v.ChecksumError = err.Error()
+	}
+
+	listed, err := m.ReadFilesList(v.FilesList)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", v.FilesList, err)
+	}
+	if err := m.artifactsReadable(); err != nil {
+		Warnf("cached artifact not compared with %s: %v", v.FilesList, err)
+	} else if data, err := os.ReadFile(v.Artifact); err == nil {
+		if inArtifact, err := artifactFileSet(data); err == nil {
+			inList := make(map[string]bool, len(listed))
+			for _, f := range listed {
+				inList[f] = true
+				if !inArtifact[f] {
+					v.NotInArtifact = append(v.NotInArtifact, f)
+				}
+			}
+			for f := range inArtifact {
+				if !inList[f] {
+					v.NotInList = append(v.NotInList, f)
+				}
+			}
+			sort.Strings(v.NotInList)
+		}
+	}
+
+	for _, f := range listed {
+		path := f
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			v.MissingFiles = append(v.MissingFiles, f)
+		}
+	}
+	if v.Frozen && len(v.MissingFiles) > 0 {
+		v.DeletedRepos = deletedCheckouts(v.MissingFiles)
+	}
+	return v, nil
+}
+
+// deletedCheckouts groups missing files by the repo.Manager worktree
+// (<bare>/.grove-worktrees/<commit>) they lived in, keeping the worktrees
+// that no longer exist. URLs come from the clone cache registry when it
+// knows the worktree.
+func deletedCheckouts(missing []string) []DeletedRepo {
+	const marker = string(filepath.Separator) + ".grove-worktrees" + string(filepath.Separator)
+	counts := make(map[string]int)
+	for _, f := range missing {
+		i := strings.Index(f, marker)
+		if i < 0 {
+			continue
+		}
+		rest := f[i+len(marker):]
+		commit, _, _ := strings.Cut(rest, string(filepath.Separator))
+		worktree := f[:i+len(marker)] + commit
+		if _, err := os.Stat(worktree); os.IsNotExist(err) {
+			counts[worktree]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	urls := make(map[string]string)
+	if rm, err := repo.NewManager(); err == nil {
+		if cache, err := NewCloneCache(rm); err == nil {
+			if refs, err := cache.Refs(); err == nil {
+				for _, r := range refs {
+					urls[r.Path] = r.URL
+				}
+			}
+		}
+	}
+	repos := make([]DeletedRepo, 0, len(counts))
+	for path, n := range counts {
+		repos = append(repos, DeletedRepo{Path: path, URL: urls[path], Files: n})
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	return repos
+}
+
+// Print displays the cache validation in the style of ValidationResult.Print.
+func (v *CacheValidation) Print() {
+	fmt.Println("Validating cached context...")
+	fmt.Println()
+	if !v.Exists {
+		fmt.Println("No cached context artifact (run 'cx generate').")
+		return
+	}
+
+	if v.ChecksumError != "" {
+		fmt.Printf("Checksum mismatch:\n  - %s\n\n", v.ChecksumError)
+	}
+	if v.Expired {
+		fmt.Printf("Expired:\n  - generated %s ago, past @expire-time %s (regenerate, or add @freeze-cache / @no-expire)\n\n",
+			v.Age.Round(time.Minute), v.ExpireTime)
+	}
+	if len(v.NotInArtifact) > 0 || len(v.NotInList) > 0 {
+		fmt.Printf("Files list out of sync with artifact (%d):\n", len(v.NotInArtifact)+len(v.NotInList))
+		for _, f := range v.NotInArtifact {
+			fmt.Printf("  - %s (listed, not in artifact)\n", f)
+		}
+		for _, f := range v.NotInList {
+			fmt.Printf("  - %s (in artifact, not listed)\n", f)
+		}
+		fmt.Println()
+	}
+	if len(v.MissingFiles) > 0 {
+		fmt.Printf("Cached files no longer on disk (%d):\n", len(v.MissingFiles))
+		for _, f := range v.MissingFiles {
+			fmt.Printf("  - %s\n", f)
+		}
+		fmt.Println()
+	}
+	if len(v.DeletedRepos) > 0 {
+		fmt.Printf("Frozen cache references deleted repositories (%d):\n", len(v.DeletedRepos))
+		for _, r := range v.DeletedRepos {
+			name := r.Path
+			if r.URL != "" {
+				name = r.URL + " (" + r.Path + ")"
+			}
+			fmt.Printf("  - %s: %d file(s); unfreeze and regenerate to restore it\n", name, r.Files)
+		}
+		fmt.Println()
+	}
+
+	if n := v.Issues(); n > 0 {
+		fmt.Printf("Cached context issues found: %d\n", n)
+	} else {
+		state := ""
+		if v.Frozen {
+			state = " (frozen)"
+		}
+		fmt.Printf("Cached context is intact%s\n", state)
+	}
+}
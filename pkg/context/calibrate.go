@@ -0,0 +1,237 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grovetools/core/pkg/paths"
+)
+
+// TokenCalibrationFile is the name of the learned per-extension token
+// ratios, kept in cx's state directory (see TokenCalibrationPath). Ratios
+// depend on the tokenizer and the language, not on a project, so one file
+// serves every workspace.
+const TokenCalibrationFile = "token-calibration.json"
+
+// maxCalibrationFileSize skips files too large to be worth piping through
+// a tokenizer; a handful of ordinary files per extension is enough.
+const maxCalibrationFileSize = 1024 * 1024
+
+// ExtensionCalibration is the measured ratio for one extension. The key ""
+// stands for extensionless files.
+type ExtensionCalibration struct {
+	Files         int     `json:"files"`
+	Bytes         int64   `json:"bytes"`
+	Tokens        int     `json:"tokens"`
+	BytesPerToken float64 `json:"bytesPerToken"`
+}
+
+// TokenCalibration holds ratios learned by running an exact tokenizer over
+// sample files (cx stats --calibrate), so EstimateTokens can stay a size
+// division on every other run.
+type TokenCalibration struct {
+	Tokenizer  string                          `json:"tokenizer"`
+	UpdatedAt  time.Time                       `json:"updatedAt"`
+	Extensions map[string]ExtensionCalibration `json:"extensions"`
+}
+
+var (
+	learnedRatiosMu     sync.Mutex
+	learnedRatiosLoaded bool
+	learnedRatios       map[string]float64
+
+	// projectRatiosByDir caches cx.token_ratios by file directory, and
+	// projectRatiosByRoot by project root, so EstimateTokens stays cheap.
+	projectRatiosByDir  sync.Map
+	projectRatiosByRoot sync.Map
+)
+
+// TokenCalibrationPath returns the absolute path of the learned ratios.
+func TokenCalibrationPath() string {
+	return filepath.Join(paths.StateDir(), "cx", TokenCalibrationFile)
+}
+
+// LoadTokenCalibration reads the learned ratios. A missing file yields an
+// empty calibration.
+func LoadTokenCalibration() (*TokenCalibration, error) {
+	cal := &TokenCalibration{Extensions: map[string]ExtensionCalibration{}}
+	data, err := os.ReadFile(TokenCalibrationPath())
+	if os.IsNotExist(err) {
+		return cal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading token calibration: %w", err)
+	}
+	if err := json.Unmarshal(data, cal); err != nil {
+		return nil, fmt.Errorf("parsing token calibration %s: %w", TokenCalibrationPath(), err)
+	}
+	if cal.Extensions == nil {
+		cal.Extensions = map[string]ExtensionCalibration{}
+	}
+	return cal, nil
+}
+
+func (c *TokenCalibration) save() error {
+	path := TokenCalibrationPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// CalibrateTokens runs command (through sh, in the working directory) over
+// up to samples files per extension from files, piping each file to its
+// stdin and reading an exact token count from the first field of its
+// output. The measured ratios replace those already learned for the same
+// extensions and are saved for later estimates.
+func (m *Manager) CalibrateTokens(files []string, command string, samples int) (*TokenCalibration, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("no tokenizer command; pass --tokenizer-cmd or set cx.tokenizer_command")
+	}
+	if samples <= 0 {
+		samples = 5
+	}
+
+	byExt := make(map[string][]string)
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+	for _, file := range sorted {
+		ext := strings.ToLower(filepath.Ext(file))
+		if len(byExt[ext]) < samples {
+			if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() && info.Size() > 0 && info.Size() <= maxCalibrationFileSize {
+				byExt[ext] = append(byExt[ext], file)
+			}
+		}
+	}
+	if len(byExt) == 0 {
+		return nil, fmt.Errorf("no files to calibrate against")
+	}
+
+	cal, err := LoadTokenCalibration()
+	if err != nil {
+		return nil, err
+	}
+	for ext, sample := range byExt {
+		var measured ExtensionCalibration
+		for _, file := range sample {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			tokens, err := m.countTokens(command, content)
+			if err != nil {
+				return nil, fmt.Errorf("tokenizing %s: %w", file, err)
+			}
+			measured.Files++
+			measured.Bytes += int64(len(content))
+			measured.Tokens += tokens
+		}
+		if measured.Tokens == 0 {
+			continue
+		}
+		measured.BytesPerToken = float64(measured.Bytes) / float64(measured.Tokens)
+		cal.Extensions[ext] = measured
+	}
+	cal.Tokenizer = command
+	cal.UpdatedAt = time.Now().UTC()
+	if err := cal.save(); err != nil {
+		return nil, err
+	}
+	resetTokenRatios()
+	return cal, nil
+}
+
+// countTokens pipes content through command and parses its first output
+// field as a token count.
+func (m *Manager) countTokens(command string, content []byte) (int, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = m.workDir
+	cmd.Stdin = bytes.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("tokenizer printed nothing")
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("tokenizer printed %q, expected a token count", fields[0])
+	}
+	return n, nil
+}
+
+// tokenRatio returns the bytes-per-token ratio for ext configured in the
+// project containing path (cx.token_ratios) or, failing that, learned by
+// calibration. ok is false when neither has one.
+func tokenRatio(path, ext string) (float64, bool) {
+	if ratio, ok := projectTokenRatios(path)[ext]; ok {
+		return ratio, true
+	}
+	learnedRatiosMu.Lock()
+	defer learnedRatiosMu.Unlock()
+	if !learnedRatiosLoaded {
+		learnedRatiosLoaded = true
+		learnedRatios = map[string]float64{}
+		if cal, err := LoadTokenCalibration(); err == nil {
+			for e, c := range cal.Extensions {
+				if c.BytesPerToken > 0 {
+					learnedRatios[e] = c.BytesPerToken
+				}
+			}
+		}
+	}
+	ratio, ok := learnedRatios[ext]
+	return ratio, ok
+}
+
+// projectTokenRatios returns cx.token_ratios for the project containing
+// path, with extensions lowercased and non-positive ratios dropped.
+func projectTokenRatios(path string) map[string]float64 {
+	dir := filepath.Dir(path)
+	if v, ok := projectRatiosByDir.Load(dir); ok {
+		return v.(map[string]float64)
+	}
+	root := FindProjectRoot(dir)
+	if root == "" {
+		root = dir
+	}
+	ratios, ok := projectRatiosByRoot.Load(root)
+	if !ok {
+		loaded := map[string]float64{}
+		for ext, ratio := range LoadCxConfig(root).TokenRatios {
+			if ratio > 0 {
+				loaded[strings.ToLower(ext)] = ratio
+			}
+		}
+		ratios, _ = projectRatiosByRoot.LoadOrStore(root, loaded)
+	}
+	projectRatiosByDir.Store(dir, ratios)
+	return ratios.(map[string]float64)
+}
+
+// resetTokenRatios drops the cached ratios, so the next estimate rereads
+// the calibration file and cx.token_ratios.
+func resetTokenRatios() {
+	learnedRatiosMu.Lock()
+	learnedRatiosLoaded = false
+	learnedRatiosMu.Unlock()
+	projectRatiosByDir = sync.Map{}
+	projectRatiosByRoot = sync.Map{}
+}
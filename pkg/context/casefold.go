@@ -0,0 +1,117 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// caseProbes caches CaseSensitiveAt results by directory.
+var caseProbes sync.Map
+
+// CaseSensitiveAt reports whether the filesystem holding dir tells apart
+// names that differ only in case. pathutil.NormalizeForLookup assumes this
+// from the OS alone (lowercasing on macOS and Windows), which is wrong for
+// a case-sensitive APFS volume or a case-insensitive mount on Linux; cx
+// probes each root instead and caches the answer per directory.
+func CaseSensitiveAt(dir string) bool {
+	if v, ok := caseProbes.Load(dir); ok {
+		return v.(bool)
+	}
+	sensitive := probeCaseSensitive(dir)
+	caseProbes.Store(dir, sensitive)
+	return sensitive
+}
+
+// probeCaseSensitive stats a case-flipped variant of an existing name: a
+// missing or different file means the filesystem is case-sensitive. Entries
+// of dir itself are tried first since they live on the filesystem being
+// asked about; dir and its ancestors next. Without any name containing a
+// letter, it falls back to the OS default.
+func probeCaseSensitive(dir string) bool {
+	dir = filepath.Clean(dir)
+	if f, err := os.Open(dir); err == nil {
+		entries, _ := f.ReadDir(64)
+		f.Close()
+		for _, e := range entries {
+			if sensitive, ok := probeName(filepath.Join(dir, e.Name())); ok {
+				return sensitive
+			}
+		}
+	}
+	for p := dir; ; {
+		if sensitive, ok := probeName(p); ok {
+			return sensitive
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			break
+		}
+		p = parent
+	}
+	return runtime.GOOS != "darwin" && runtime.GOOS != "windows"
+}
+
+// probeName compares path with its case-flipped sibling. ok is false when
+// the name has no letters or either stat fails for another reason.
+func probeName(path string) (sensitive, ok bool) {
+	base := filepath.Base(path)
+	flipped := flipCase(base)
+	if flipped == base {
+		return false, false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, false
+	}
+	alt, err := os.Lstat(filepath.Join(filepath.Dir(path), flipped))
+	if os.IsNotExist(err) {
+		return true, true
+	}
+	if err != nil {
+		return false, false
+	}
+	return !os.SameFile(info, alt), true
+}
+
+func flipCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return unicode.ToLower(r)
+		case unicode.IsLower(r):
+			return unicode.ToUpper(r)
+		}
+		return r
+	}, s)
+}
+
+// caseSensitiveFor reports whether paths under path's root compare
+// case-sensitively. Relative paths are taken against workDir; absolute
+// ones use the innermost allowed root containing them.
+func (m *Manager) caseSensitiveFor(path string) bool {
+	if !filepath.IsAbs(path) || pathWithin(path, m.workDir) {
+		return CaseSensitiveAt(m.workDir)
+	}
+	m.initAllowedRoots()
+	root := m.workDir
+	best := 0
+	for _, r := range m.allowedRoots {
+		if pathWithin(path, r) && len(r) > best {
+			root, best = r, len(r)
+		}
+	}
+	return CaseSensitiveAt(root)
+}
+
+// foldKey returns path as a map key that treats case variants as one file
+// only where the filesystem does.
+func (m *Manager) foldKey(path string) string {
+	if m.caseSensitiveFor(path) {
+		return path
+	}
+	return strings.ToLower(path)
+}
@@ -0,0 +1,39 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaseSensitiveAt(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "Probe.txt"), "x")
+	_, err := os.Stat(filepath.Join(dir, "pROBE.TXT"))
+	want := os.IsNotExist(err)
+
+	if got := CaseSensitiveAt(dir); got != want {
+		t.Errorf("CaseSensitiveAt(%s) = %v, want %v", dir, got, want)
+	}
+	if flipCase("Probe-1.txt") != "pROBE-1.TXT" {
+		t.Errorf("flipCase = %q", flipCase("Probe-1.txt"))
+	}
+	if _, ok := probeName(filepath.Join(dir, "123")); ok {
+		t.Error("a name without letters should not decide the probe")
+	}
+}
+
+func TestMatchPatternFollowsFilesystemCase(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(dir)
+	if !mgr.matchPattern("*.md", "docs/guide.md") {
+		t.Error("*.md should match docs/guide.md")
+	}
+	got := mgr.matchPattern("*.md", "README.MD")
+	if want := !CaseSensitiveAt(dir); got != want {
+		t.Errorf("matchPattern(*.md, README.MD) = %v, want %v", got, want)
+	}
+	if !matchRulePattern("*.md", "README.MD") {
+		t.Error("matchRulePattern should keep ignoring case")
+	}
+}
@@ -0,0 +1,99 @@
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumExt is appended to a generated artifact's path to form its
+// checksum sidecar. The sidecar uses sha256sum's format ("<hex>  <name>"), so
+// `sha256sum -c .grove/context-files.sha256` works from the artifact's
+// directory.
+const ChecksumExt = ".sha256"
+
+// ErrNoChecksum is returned by VerifyArtifactChecksum when an artifact has no
+// sidecar, i.e. it was generated without checksums enabled.
+var ErrNoChecksum = errors.New("no checksum sidecar")
+
+// SetChecksums toggles writing checksum sidecars next to generated artifacts
+// (context, cached context, and both file lists). Like SetStripComments, set
+// this only on an instance you own exclusively.
+func (m *Manager) SetChecksums(v bool) {
+	m.checksums = v
+}
+
+// sealArtifact writes (or, when checksums are disabled, removes) the checksum
+// sidecar for a freshly written artifact. Removing a stale sidecar matters:
+// otherwise the next verification would flag a legitimately regenerated
+// artifact as hand-edited.
+func (m *Manager) sealArtifact(path string) error {
+	sidecar := path + ChecksumExt
+	if !m.checksums {
+		if err := os.Remove(sidecar); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale checksum %s: %w", sidecar, err)
+		}
+		return nil
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	if err := os.WriteFile(sidecar, []byte(line), 0o644); err != nil { //nolint:gosec // checksum, not sensitive
+		return fmt.Errorf("failed to write checksum %s: %w", sidecar, err)
+	}
+	return nil
+}
+
+// VerifyArtifactChecksum compares a generated artifact against its checksum
+// sidecar. It returns ErrNoChecksum when there is no sidecar, and a
+// descriptive error when the artifact was edited, truncated, or replaced
+// since it was generated.
+func VerifyArtifactChecksum(path string) error {
+	data, err := os.ReadFile(path + ChecksumExt)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoChecksum
+		}
+		return fmt.Errorf("failed to read checksum for %s: %w", path, err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("checksum sidecar for %s is empty", path)
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if sum != fields[0] {
+		return fmt.Errorf("%s does not match its checksum; it was edited or truncated after generation — run 'cx generate' to refresh", path)
+	}
+	return nil
+}
+
+// warnOnChecksumMismatch prints a stderr warning when path fails checksum
+// verification. Artifacts without a sidecar are not reported.
+func warnOnChecksumMismatch(path string) {
+	if err := VerifyArtifactChecksum(path); err != nil && !errors.Is(err, ErrNoChecksum) {
+		Warnf("%v", err)
+	}
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
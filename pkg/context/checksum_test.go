@@ -0,0 +1,47 @@
+package context
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestArtifactChecksum verifies that sealed artifacts round-trip, that edits
+// and truncation are detected, and that disabling checksums removes a stale
+// sidecar.
+func TestArtifactChecksum(t *testing.T) {
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "context-files")
+	m := &Manager{workDir: dir}
+
+	if err := m.WriteFilesList(artifact, []string{"a.go", "b.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifactChecksum(artifact); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("expected ErrNoChecksum without checksums enabled, got %v", err)
+	}
+
+	m.SetChecksums(true)
+	if err := m.WriteFilesList(artifact, []string{"a.go", "b.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifactChecksum(artifact); err != nil {
+		t.Fatalf("fresh artifact failed verification: %v", err)
+	}
+
+	if err := os.WriteFile(artifact, []byte("a.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifactChecksum(artifact); err == nil || errors.Is(err, ErrNoChecksum) {
+		t.Errorf("expected a mismatch for a truncated artifact, got %v", err)
+	}
+
+	m.SetChecksums(false)
+	if err := m.WriteFilesList(artifact, []string{"c.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(artifact + ChecksumExt); !os.IsNotExist(err) {
+		t.Errorf("expected stale sidecar to be removed, stat err = %v", err)
+	}
+}
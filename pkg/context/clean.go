@@ -0,0 +1,288 @@
+package context
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/pkg/repo"
+)
+
+// Artifact classes reported by ScanArtifacts.
+const (
+	ArtifactContext       = "context"
+	ArtifactCachedContext = "cached-context"
+	ArtifactFileLists     = "file-lists"
+	ArtifactDiffs         = "diffs"
+	ArtifactMetrics       = "metrics"
+	ArtifactSnapshots     = "snapshots"
+	ArtifactCloneCache    = "clone-cache"
+)
+
+// CleanArtifact is one generated file or directory cx knows how to account for.
+// Regenerable artifacts are rebuilt by `cx generate` (or on demand) and are safe
+// to delete; Protected names why a regenerable artifact is kept anyway.
+//
+// Scratch snapshots count as regenerable; snapshots saved under a chosen name
+// are pinned. Of the shared clone worktrees, only those this workspace
+// references are listed: removable once its reference has gone stale and no
+// other workspace holds them, protected otherwise. Clean removes them through
+// CloneCache.Release, which checks the references again; collecting the
+// rest of the shared cache is left to `cx repo gc`.
+type CleanArtifact struct {
+	Class       string `json:"class"`
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	Regenerable bool   `json:"regenerable"`
+	Protected   string `json:"protected,omitempty"`
+}
+
+// Removable reports whether Clean will delete this artifact.
+func (a CleanArtifact) Removable() bool {
+	return a.Regenerable && a.Protected == ""
+}
+
+// ScanArtifacts lists every cx-generated artifact for this workspace that
+// currently exists on disk, covering both the resolved (plan/notebook/job)
+// locations and the local .grove/ fallbacks. Rules files are never listed:
+// they are user-authored and not regenerable.
+func (m *Manager) ScanArtifacts() ([]CleanArtifact, error) {
+	frozen, err := m.ShouldFreezeCache()
+	if err != nil {
+		return nil, err
+	}
+	frozenReason := ""
+	if frozen {
+		frozenReason = "@freeze-cache"
+	}
+
+	candidates := []CleanArtifact{
+		{Class: ArtifactContext, Path: m.ResolveContextPath(), Regenerable: true},
+		{Class: ArtifactContext, Path: filepath.Join(m.workDir, ContextFile), Regenerable: true},
+		{Class: ArtifactCachedContext, Path: m.ResolveCachedContextPath(), Regenerable: true, Protected: frozenReason},
+		{Class: ArtifactCachedContext, Path: filepath.Join(m.workDir, CachedContextFile), Regenerable: true, Protected: frozenReason},
+		{Class: ArtifactFileLists, Path: m.ResolveContextFilesListPath(), Regenerable: true},
+		{Class: ArtifactFileLists, Path: m.ResolveCachedContextFilesListPath(), Regenerable: true, Protected: frozenReason},
+		{Class: ArtifactFileLists, Path: filepath.Join(m.workDir, CachedContextFilesListFile), Regenerable: true, Protected: frozenReason},
+		{Class: ArtifactDiffs, Path: filepath.Join(m.workDir, GroveDir, "diffs"), Regenerable: true},
+		{Class: ArtifactMetrics, Path: m.MetricsPath(), Regenerable: false},
+		{Class: ArtifactMetrics, Path: m.AccessPath(), Regenerable: false},
+	}
+	candidates = append(candidates, m.snapshotArtifacts()...)
+	if !m.noState {
+		if cache, err := openCloneCache(); err == nil {
+			clones, err := cache.cleanArtifacts(m.workDir)
+			if err != nil {
+				Warnf("could not read the clone cache: %v", err)
+			}
+			candidates = append(candidates, clones...)
+		}
+	}
+	// Variants other branches and rule sets left under cx.artifact_name.
+	if variants, err := m.ListArtifactVariants(); err == nil {
+		for _, v := range variants {
+			a := CleanArtifact{Class: ArtifactFileLists, Path: v.Path, Regenerable: true}
+			switch v.Artifact {
+			case filepath.Base(ContextFile):
+				a.Class = ArtifactContext
+			case filepath.Base(CachedContextFile):
+				a.Class, a.Protected = ArtifactCachedContext, frozenReason
+			case filepath.Base(CachedContextFilesListFile):
+				a.Protected = frozenReason
+			}
+			candidates = append(candidates, a)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var artifacts []CleanArtifact
+	for _, a := range candidates {
+		if a.Path == "" || seen[a.Path] {
+			continue
+		}
+		seen[a.Path] = true
+		size, err := pathSize(a.Path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to inspect %s: %w", a.Path, err)
+		}
+		a.Size = size
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, nil
+}
+
+// snapshotArtifacts lists the snapshots saved under SnapshotsDir. Only
+// scratch snapshots are removable.
+func (m *Manager) snapshotArtifacts() []CleanArtifact {
+	paths, _ := filepath.Glob(filepath.Join(m.workDir, SnapshotsDir, "*.json"))
+	artifacts := make([]CleanArtifact, 0, len(paths))
+	for _, path := range paths {
+		a := CleanArtifact{Class: ArtifactSnapshots, Path: path, Regenerable: true}
+		if !strings.HasPrefix(filepath.Base(path), ScratchSnapshotPrefix) {
+			a.Protected = "pinned"
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts
+}
+
+// openCloneCache opens the shared clone cache of git rules.
+func openCloneCache() (*CloneCache, error) {
+	rm, err := repo.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	return NewCloneCache(rm)
+}
+
+// cleanArtifacts lists the worktrees workspace references: those releasing
+// its stale references would remove, and the rest, which are protected.
+// Worktrees only other workspaces reference are not listed.
+func (c *CloneCache) cleanArtifacts(workspace string) ([]CleanArtifact, error) {
+	released, err := c.Release(workspace, DefaultCloneRefMaxAge, true)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := c.Refs()
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []CleanArtifact
+	removable := make(map[string]bool)
+	for _, ref := range released {
+		removable[ref.Path] = true
+		artifacts = append(artifacts, CleanArtifact{Class: ArtifactCloneCache, Path: ref.Path, Regenerable: true})
+	}
+	for _, ref := range refs {
+		if _, ok := ref.Workspaces[workspace]; ok && !removable[ref.Path] {
+			artifacts = append(artifacts, CleanArtifact{Class: ArtifactCloneCache, Path: ref.Path, Regenerable: true, Protected: "in use"})
+		}
+	}
+	return artifacts, nil
+}
+
+// cleanClones releases the stale references of workspace when artifacts
+// include removable worktrees, and returns how many worktrees were removed
+// and the bytes freed (as measured by ScanArtifacts).
+func (c *CloneCache) cleanClones(workspace string, artifacts []CleanArtifact) (removed int, freed int64, err error) {
+	sizes := make(map[string]int64)
+	for _, a := range artifacts {
+		if a.Class == ArtifactCloneCache && a.Removable() {
+			sizes[a.Path] = a.Size
+		}
+	}
+	if len(sizes) == 0 {
+		return 0, 0, nil
+	}
+	refs, err := c.Release(workspace, DefaultCloneRefMaxAge, false)
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if !seen[ref.Path] {
+			seen[ref.Path] = true
+			removed++
+			freed += sizes[ref.Path]
+		}
+	}
+	if err != nil {
+		return removed, freed, fmt.Errorf("failed to release shared clones: %w", err)
+	}
+	return removed, freed, nil
+}
+
+// Clean removes every removable artifact and returns how many were removed
+// and the bytes freed. Protected and non-regenerable artifacts are left alone.
+// Clone worktrees are left to CloneCache.Release, which keeps any a
+// generation has started using since the scan.
+func (m *Manager) Clean(artifacts []CleanArtifact) (removed int, freed int64, err error) {
+	clones := false
+	for _, a := range artifacts {
+		if !a.Removable() {
+			continue
+		}
+		if a.Class == ArtifactCloneCache {
+			clones = true
+			continue
+		}
+		if err := os.RemoveAll(a.Path); err != nil {
+			return removed, freed, fmt.Errorf("failed to remove %s: %w", a.Path, err)
+		}
+		// Drop the checksum sidecar with its artifact so it can't go stale.
+		_ = os.Remove(a.Path + ChecksumExt)
+		removed++
+		freed += a.Size
+	}
+	if clones {
+		cache, err := openCloneCache()
+		if err != nil {
+			return removed, freed, err
+		}
+		n, size, err := cache.cleanClones(m.workDir, artifacts)
+		removed += n
+		freed += size
+		if err != nil {
+			return removed, freed, err
+		}
+	}
+	return removed, freed, nil
+}
+
+// ArtifactClassSize is the aggregate size of one artifact class.
+type ArtifactClassSize struct {
+	Class     string `json:"class"`
+	Count     int    `json:"count"`
+	Size      int64  `json:"size"`
+	Removable int64  `json:"removable"`
+}
+
+// SummarizeArtifacts totals artifacts by class, sorted by class name.
+func SummarizeArtifacts(artifacts []CleanArtifact) []ArtifactClassSize {
+	byClass := make(map[string]*ArtifactClassSize)
+	for _, a := range artifacts {
+		s, ok := byClass[a.Class]
+		if !ok {
+			s = &ArtifactClassSize{Class: a.Class}
+			byClass[a.Class] = s
+		}
+		s.Count++
+		s.Size += a.Size
+		if a.Removable() {
+			s.Removable += a.Size
+		}
+	}
+	out := make([]ArtifactClassSize, 0, len(byClass))
+	for _, s := range byClass {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out
+}
+
+// pathSize returns the size of a file, or the total size of a directory tree.
+func pathSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return info.Size(), nil
+	}
+	var total int64
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			if fi, err := d.Info(); err == nil {
+				total += fi.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
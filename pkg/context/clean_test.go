@@ -0,0 +1,166 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestClean_RespectsProtection verifies that Clean removes only regenerable,
+// unprotected artifacts and reports the bytes it freed.
+func TestClean_RespectsProtection(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	hot := write("context", "12345")
+	cold := write("cached-context", "123")
+	metrics := write("metrics.jsonl", "{}")
+
+	artifacts := []CleanArtifact{
+		{Class: ArtifactContext, Path: hot, Size: 5, Regenerable: true},
+		{Class: ArtifactCachedContext, Path: cold, Size: 3, Regenerable: true, Protected: "@freeze-cache"},
+		{Class: ArtifactMetrics, Path: metrics, Size: 2},
+	}
+
+	removed, freed, err := (&Manager{}).Clean(artifacts)
+	if err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	if removed != 1 || freed != 5 {
+		t.Errorf("removed/freed = %d/%d, want 1/5", removed, freed)
+	}
+	if _, err := os.Stat(hot); !os.IsNotExist(err) {
+		t.Errorf("hot context should have been removed")
+	}
+	for _, kept := range []string{cold, metrics} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s should have been kept: %v", kept, err)
+		}
+	}
+
+	summary := SummarizeArtifacts(artifacts)
+	if len(summary) != 3 {
+		t.Fatalf("got %d classes, want 3", len(summary))
+	}
+	for _, s := range summary {
+		if s.Class == ArtifactCachedContext && s.Removable != 0 {
+			t.Errorf("frozen cached context reported as removable: %+v", s)
+		}
+	}
+}
+
+// TestCleanSnapshots verifies that scratch snapshots are removable and
+// snapshots saved under a chosen name are pinned.
+func TestCleanSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{workDir: dir}
+	pinned := filepath.Join(dir, SnapshotsDir, "monday.json")
+	scratch := filepath.Join(dir, SnapshotsDir, ScratchSnapshotPrefix+"20260102-030405.json")
+	fsWriteString(t, pinned, "{}\n")
+	fsWriteString(t, scratch, "{}\n")
+
+	artifacts := m.snapshotArtifacts()
+	if len(artifacts) != 2 {
+		t.Fatalf("got %d snapshot artifacts, want 2: %+v", len(artifacts), artifacts)
+	}
+	for _, a := range artifacts {
+		if want := a.Path == scratch; a.Removable() != want {
+			t.Errorf("%s removable = %v, want %v", a.Path, a.Removable(), want)
+		}
+	}
+	if removed, _, err := m.Clean(artifacts); err != nil || removed != 1 {
+		t.Fatalf("Clean = %d, %v; want 1 removed", removed, err)
+	}
+	if _, err := os.Stat(scratch); !os.IsNotExist(err) {
+		t.Error("scratch snapshot should have been removed")
+	}
+	if _, err := os.Stat(pinned); err != nil {
+		t.Errorf("pinned snapshot should have been kept: %v", err)
+	}
+}
+
+// TestCleanClones verifies that only the workspace's own clone worktrees are
+// listed, removable once its reference is stale and no other workspace holds
+// them, and that cleaning rechecks the references, so a worktree referenced
+// again since the scan is kept and other workspaces' worktrees are untouched.
+func TestCleanClones(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "locks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c := &CloneCache{dir: dir}
+	workspace, other := t.TempDir(), t.TempDir()
+	stale, used, again, shared, foreign := t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir()
+	old := time.Now().Add(-2 * DefaultCloneRefMaxAge)
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		refs[cloneKey("u1", "v1")] = &CloneRef{URL: "u1", Version: "v1", Path: stale, Workspaces: map[string]time.Time{workspace: old}}
+		refs[cloneKey("u2", "v1")] = &CloneRef{URL: "u2", Version: "v1", Path: used, Workspaces: map[string]time.Time{workspace: time.Now()}}
+		refs[cloneKey("u3", "v1")] = &CloneRef{URL: "u3", Version: "v1", Path: again, Workspaces: map[string]time.Time{workspace: old}}
+		refs[cloneKey("u4", "v1")] = &CloneRef{URL: "u4", Version: "v1", Path: shared, Workspaces: map[string]time.Time{workspace: old, other: time.Now()}}
+		// Collectable by `cx repo gc`, but not this workspace's to clean.
+		refs[cloneKey("u5", "v1")] = &CloneRef{URL: "u5", Version: "v1", Path: foreign, Workspaces: map[string]time.Time{other: old}}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := c.cleanArtifacts(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, removable := make(map[string]bool), make(map[string]bool)
+	for _, a := range artifacts {
+		if a.Class != ArtifactCloneCache {
+			t.Errorf("unexpected class %q", a.Class)
+		}
+		listed[a.Path] = true
+		if a.Removable() {
+			removable[a.Path] = true
+		}
+	}
+	if !removable[stale] || !removable[again] || removable[used] || removable[shared] {
+		t.Fatalf("removable = %v, want %s and %s", removable, stale, again)
+	}
+	if !listed[used] || !listed[shared] || listed[foreign] {
+		t.Errorf("listed = %v, want this workspace's worktrees only", listed)
+	}
+
+	// A generation references one of the worktrees again before cleaning.
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		refs[cloneKey("u3", "v1")].Workspaces[workspace] = time.Now()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	removed, _, err := c.cleanClones(workspace, artifacts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d worktrees, want 1", removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("unreferenced worktree should have been removed")
+	}
+	for _, kept := range []string{used, again, shared, foreign} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s should have been kept: %v", kept, err)
+		}
+	}
+	refs, err := c.Refs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range refs {
+		if ref.Path == foreign {
+			if _, ok := ref.Workspaces[other]; !ok {
+				t.Error("cleaning dropped another workspace's reference")
+			}
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package context
+
+import (
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/grovetools/core/pkg/repo"
+)
+
+const (
+	// cloneLockTimeout bounds how long Ensure waits for another process that
+	// is cloning or checking out the same URL+version.
+	cloneLockTimeout = 5 * time.Minute
+	// staleLockAge is the age after which a lock file is assumed to belong to
+	// a crashed process and is broken. Ensure holds a lock for the whole
+	// clone, so this must be well above the slowest clone of a large
+	// repository; a lock left by a crash blocks its URL+version until then.
+	staleLockAge = 2 * time.Hour
+	// DefaultCloneRefMaxAge is how long a workspace reference stays live
+	// without being refreshed by a generation before GC drops it.
+	DefaultCloneRefMaxAge = 30 * 24 * time.Hour
+)
+
+// CloneRef is one URL+version checked out in the shared ecosystem clone
+// cache, together with the workspaces that referenced it and when each last
+// did so.
+type CloneRef struct {
+	URL        string               `json:"url"`
+	Version    string               `json:"version,omitempty"`
+	Commit     string               `json:"commit"`
+	Path       string               `json:"path"`
+	Workspaces map[string]time.Time `json:"workspaces"`
+}
+
+// CloneCache deduplicates git rule checkouts across every workspace on the
+// machine. repo.Manager already stores worktrees in a single ecosystem
+// directory, but its locking is per process; CloneCache adds a cross-process
+// lock per URL+version so concurrent generations in different projects do
+// not race on the same clone, and a reference registry so unused worktrees
+// can be garbage collected.
+type CloneCache struct {
+	repo *repo.Manager
+	dir  string
+}
+
+// NewCloneCache returns the shared clone cache backed by rm.
+func NewCloneCache(rm *repo.Manager) (*CloneCache, error) {
+	cxPath, err := repo.GetCxEcosystemPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(cxPath, "clone-cache")
+	if err := os.MkdirAll(filepath.Join(dir, "locks"), 0o755); err != nil {
+		return nil, fmt.Errorf("creating clone cache directory: %w", err)
+	}
+	return &CloneCache{repo: rm, dir: dir}, nil
+}
+
+func (c *CloneCache) refsPath() string {
+	return filepath.Join(c.dir, "refs.json")
+}
+
+func cloneKey(url, version string) string {
+	sum := sha256.Sum256([]byte(url + "@" + version))
+	return hex.EncodeToString(sum[:8])
+}
+
+// keyLock is the lock file held while checking out url@version.
+func (c *CloneCache) keyLock(url, version string) string {
+	return filepath.Join(c.dir, "locks", cloneKey(url, version)+".lock")
+}
+
+// pathLock is the lock file held while registering a reference to the
+// worktree at path or removing it. Several versions can share a worktree,
+// so the URL+version lock alone does not keep GC off a worktree in use.
+func (c *CloneCache) pathLock(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, "locks", "path-"+hex.EncodeToString(sum[:8])+".lock")
+}
+
+// Ensure checks out url@version (reusing an existing worktree when one is
+// present) while holding the lock for that URL+version, and records that
+// workspace references it while holding the lock for the worktree's path.
+func (c *CloneCache) Ensure(ctx gocontext.Context, workspace, url, version string) (string, string, error) {
+	unlock, err := acquireFileLock(c.keyLock(url, version), cloneLockTimeout)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+	path, commit, err := c.repo.EnsureVersion(ctx, url, version)
+	if err != nil {
+		return "", "", err
+	}
+
+	unlockPath, err := acquireFileLock(c.pathLock(path), cloneLockTimeout)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlockPath()
+	// GC may have removed a worktree shared with another version between
+	// the checkout and taking the path lock; check it out again.
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if path, commit, err = c.repo.EnsureVersion(ctx, url, version); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		key := cloneKey(url, version)
+		ref, ok := refs[key]
+		if !ok {
+			ref = &CloneRef{URL: url, Version: version, Workspaces: make(map[string]time.Time)}
+			refs[key] = ref
+		}
+		ref.Commit = commit
+		ref.Path = path
+		ref.Workspaces[workspace] = time.Now()
+	}); err != nil {
+		// The checkout itself succeeded; a registry failure only costs GC
+		// accuracy, so don't fail resolution over it.
+		Warnf("could not record clone reference for %s: %v", url, err)
+	}
+	return path, commit, nil
+}
+
+// Refs returns every registered clone reference, sorted by URL then version.
+func (c *CloneCache) Refs() ([]CloneRef, error) {
+	refs, err := c.loadRefs()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CloneRef, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].URL != out[j].URL {
+			return out[i].URL < out[j].URL
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// GC drops workspace references that no longer exist on disk or have not
+// been refreshed within maxAge, then removes the worktrees that no live
+// reference points at. With dryRun, nothing is modified. It returns the
+// references whose worktrees were (or would be) removed. Worktrees created
+// before the registry existed are never touched.
+func (c *CloneCache) GC(maxAge time.Duration, dryRun bool) ([]CloneRef, error) {
+	now := time.Now()
+	return c.prune(func(ws string, lastUsed time.Time) bool {
+		_, err := os.Stat(ws)
+		return os.IsNotExist(err) || now.Sub(lastUsed) > maxAge
+	}, dryRun)
+}
+
+// Release is GC limited to one workspace: it drops only the references of
+// workspace that have not been refreshed within maxAge, so the worktrees it
+// removes are ones workspace used and no other workspace still references.
+func (c *CloneCache) Release(workspace string, maxAge time.Duration, dryRun bool) ([]CloneRef, error) {
+	now := time.Now()
+	return c.prune(func(ws string, lastUsed time.Time) bool {
+		return ws == workspace && now.Sub(lastUsed) > maxAge
+	}, dryRun)
+}
+
+// prune drops the workspace references drop selects and the registry
+// entries left without any, then removes the worktrees no remaining
+// reference points at; see GC.
+func (c *CloneCache) prune(drop func(ws string, lastUsed time.Time) bool, dryRun bool) ([]CloneRef, error) {
+	var removed []CloneRef
+	update := func(refs map[string]*CloneRef) {
+		for _, ref := range refs {
+			for ws, lastUsed := range ref.Workspaces {
+				if drop(ws, lastUsed) {
+					delete(ref.Workspaces, ws)
+				}
+			}
+		}
+
+		// Several versions (e.g. "main" and its commit) can share a worktree,
+		// so count live references per path.
+		live := make(map[string]int)
+		for _, ref := range refs {
+			if len(ref.Workspaces) > 0 {
+				live[ref.Path]++
+			}
+		}
+		for key, ref := range refs {
+			if len(ref.Workspaces) > 0 {
+				continue
+			}
+			if live[ref.Path] == 0 {
+				removed = append(removed, *ref)
+			}
+			delete(refs, key)
+		}
+	}
+
+	if dryRun {
+		refs, err := c.loadRefs()
+		if err != nil {
+			return nil, err
+		}
+		update(refs)
+		return removed, nil
+	}
+
+	if err := c.updateRefs(update); err != nil {
+		return nil, err
+	}
+	var done []CloneRef
+	for _, ref := range removed {
+		// Ensure registers references under the path lock, so with it held
+		// the registry is final for this worktree: a generation that
+		// re-registered it since the update above keeps it.
+		unlock, err := acquireFileLock(c.pathLock(ref.Path), cloneLockTimeout)
+		if err != nil {
+			return done, err
+		}
+		inUse, err := c.pathInUse(ref.Path)
+		if err == nil && !inUse {
+			err = removeWorktree(ref.Path)
+		}
+		unlock()
+		if err != nil {
+			return done, err
+		}
+		if !inUse {
+			done = append(done, ref)
+		}
+	}
+	return done, nil
+}
+
+// pathInUse reports whether a registered reference with workspaces points
+// at the worktree at path.
+func (c *CloneCache) pathInUse(path string) (bool, error) {
+	refs, err := c.loadRefs()
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range refs {
+		if ref.Path == path && len(ref.Workspaces) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// removeWorktree deletes a repo.Manager worktree, which lives at
+// <bare>/.grove-worktrees/<commit>. repo.Manager recreates it on the next
+// EnsureVersion if the path is missing.
+func removeWorktree(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	bare := filepath.Dir(filepath.Dir(path))
+	if out, err := exec.Command("git", "-C", bare, "worktree", "remove", "--force", path).CombinedOutput(); err != nil {
+		if rmErr := os.RemoveAll(path); rmErr != nil {
+			return fmt.Errorf("removing worktree %s: %w\nOutput: %s", path, err, out)
+		}
+		_ = exec.Command("git", "-C", bare, "worktree", "prune").Run()
+	}
+	return nil
+}
+
+func (c *CloneCache) loadRefs() (map[string]*CloneRef, error) {
+	refs := make(map[string]*CloneRef)
+	data, err := os.ReadFile(c.refsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return refs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", c.refsPath(), err)
+	}
+	for _, r := range refs {
+		if r.Workspaces == nil {
+			r.Workspaces = make(map[string]time.Time)
+		}
+	}
+	return refs, nil
+}
+
+// updateRefs applies fn to the registry under the registry lock and writes
+// the result atomically.
+func (c *CloneCache) updateRefs(fn func(map[string]*CloneRef)) error {
+	unlock, err := acquireFileLock(filepath.Join(c.dir, "locks", "refs.lock"), cloneLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	refs, err := c.loadRefs()
+	if err != nil {
+		return err
+	}
+	fn(refs)
+
+	data, err := json.MarshalIndent(refs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.refsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil { //nolint:gosec // registry of public clone paths
+		return err
+	}
+	return os.Rename(tmp, c.refsPath())
+}
+
+// acquireFileLock takes an exclusive lock by creating path with O_EXCL,
+// retrying until timeout. Locks older than staleLockAge are assumed to be
+// left behind by a crashed process and are broken. The returned func
+// releases the lock.
+func acquireFileLock(path string, timeout time.Duration) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("creating lock %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
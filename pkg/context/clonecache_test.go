@@ -0,0 +1,131 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCloneCacheGC verifies that GC drops stale and vanished workspace
+// references and only reports worktrees no live reference shares.
+func TestCloneCacheGC(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "locks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c := &CloneCache{dir: dir}
+	live := t.TempDir()
+	now := time.Now()
+
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		refs[cloneKey("u1", "main")] = &CloneRef{URL: "u1", Version: "main", Path: "/wt/a",
+			Workspaces: map[string]time.Time{live: now}}
+		// Same worktree as u1@main via a different version string.
+		refs[cloneKey("u1", "abc123")] = &CloneRef{URL: "u1", Version: "abc123", Path: "/wt/a",
+			Workspaces: map[string]time.Time{filepath.Join(dir, "gone"): now}}
+		refs[cloneKey("u2", "v1")] = &CloneRef{URL: "u2", Version: "v1", Path: "/wt/b",
+			Workspaces: map[string]time.Time{live: now.Add(-48 * time.Hour)}}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := c.GC(24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].URL != "u2" {
+		t.Fatalf("dry run removed %+v, want only u2", removed)
+	}
+	if refs, _ := c.Refs(); len(refs) != 3 {
+		t.Errorf("dry run modified the registry: %d refs left", len(refs))
+	}
+
+	if _, err := c.GC(24*time.Hour, false); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := c.Refs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Version != "main" {
+		t.Errorf("after GC got %+v, want only u1@main", refs)
+	}
+}
+
+// TestCloneCacheGCReregistered verifies that GC keeps a worktree that a
+// generation registered again, possibly under another version, after GC
+// dropped its references but before it took the worktree's lock.
+func TestCloneCacheGCReregistered(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "locks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c := &CloneCache{dir: dir}
+	worktree := t.TempDir()
+	live := t.TempDir()
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		refs[cloneKey("u", "v1")] = &CloneRef{URL: "u", Version: "v1", Path: worktree,
+			Workspaces: map[string]time.Time{live: time.Now().Add(-48 * time.Hour)}}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold the worktree's lock as Ensure does while it registers.
+	unlock, err := acquireFileLock(c.pathLock(worktree), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type gcResult struct {
+		removed []CloneRef
+		err     error
+	}
+	done := make(chan gcResult)
+	go func() {
+		removed, err := c.GC(24*time.Hour, false)
+		done <- gcResult{removed, err}
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if refs, _ := c.Refs(); len(refs) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("GC did not drop the stale reference")
+		}
+	}
+	if err := c.updateRefs(func(refs map[string]*CloneRef) {
+		refs[cloneKey("u", "main")] = &CloneRef{URL: "u", Version: "main", Path: worktree,
+			Workspaces: map[string]time.Time{live: time.Now()}}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	result := <-done
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if len(result.removed) != 0 {
+		t.Errorf("GC removed %+v, want nothing", result.removed)
+	}
+	if _, err := os.Stat(worktree); err != nil {
+		t.Errorf("re-registered worktree was removed: %v", err)
+	}
+}
+
+func TestAcquireFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	unlock, err := acquireFileLock(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquireFileLock(path, 100*time.Millisecond); err == nil {
+		t.Fatal("expected second acquire to time out while the lock is held")
+	}
+	unlock()
+	unlock2, err := acquireFileLock(path, time.Second)
+	if err != nil {
+		t.Fatalf("expected acquire after release to succeed: %v", err)
+	}
+	unlock2()
+}
@@ -0,0 +1,130 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Command failures. A failing `@cmd:` rule contributes no files; besides the
+// warning printed at the time, each failure is recorded with its exit code
+// and the tail of its stderr so `cx validate`, `cx stats --per-line` and the
+// TUI can report it after the fact, and `cx generate --strict` can refuse to
+// write a context that silently lacks the command's files.
+
+// maxStderrSnippet bounds the stderr kept for a failed command.
+const maxStderrSnippet = 512
+
+// CommandError is the error of an `@cmd:` expression that could not run or
+// exited non-zero.
+type CommandError struct {
+	Command  string
+	ExitCode int    // -1 when the command did not start or was killed
+	Stderr   string // tail of the command's stderr, trimmed
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("command failed: %v", e.Err)
+	if e.Stderr != "" {
+		msg += ": " + firstLine(e.Stderr)
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// newCommandError wraps the error of running command, keeping the tail of
+// its stderr.
+func newCommandError(command string, err error, stderr []byte) *CommandError {
+	ce := &CommandError{Command: command, ExitCode: -1, Stderr: stderrSnippet(stderr), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ce.ExitCode = exitErr.ExitCode()
+	}
+	return ce
+}
+
+// stderrSnippet trims stderr to its last maxStderrSnippet bytes, starting
+// at a line boundary where possible.
+func stderrSnippet(stderr []byte) string {
+	s := strings.TrimSpace(string(stderr))
+	if len(s) <= maxStderrSnippet {
+		return s
+	}
+	s = s[len(s)-maxStderrSnippet:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < len(s)-1 {
+		s = s[i+1:]
+	}
+	return "…" + s
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+// CommandFailure is an `@cmd:` rule that failed during resolution.
+type CommandFailure struct {
+	LineNum  int    `json:"line"`
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Stderr   string `json:"stderr,omitempty"`
+	Error    string `json:"error"`
+}
+
+// recordCommandFailure records that the `@cmd:` rule at lineNum failed with
+// err. Each rule is recorded once however many passes resolve it; the
+// failure is also added to the skipped rules.
+func (m *Manager) recordCommandFailure(lineNum int, command string, err error) {
+	f := CommandFailure{LineNum: lineNum, Command: command, ExitCode: -1, Error: err.Error()}
+	var ce *CommandError
+	if errors.As(err, &ce) {
+		f.ExitCode, f.Stderr = ce.ExitCode, ce.Stderr
+	}
+
+	m.skippedMutex.Lock()
+	for _, existing := range m.cmdFailures {
+		if existing.LineNum == lineNum && existing.Command == command {
+			m.skippedMutex.Unlock()
+			return
+		}
+	}
+	m.cmdFailures = append(m.cmdFailures, f)
+	m.skippedMutex.Unlock()
+
+	m.addSkippedRule(lineNum, "@cmd: "+command, err.Error())
+}
+
+// CommandFailures returns the `@cmd:` rules that failed since the last
+// ClearSkippedRules.
+func (m *Manager) CommandFailures() []CommandFailure {
+	m.skippedMutex.Lock()
+	defer m.skippedMutex.Unlock()
+	return append([]CommandFailure(nil), m.cmdFailures...)
+}
+
+// SetStrictCommands makes generation fail, before anything is written, when
+// an `@cmd:` rule failed during resolution.
+func (m *Manager) SetStrictCommands(strict bool) {
+	m.strictCommands = strict
+}
+
+// commandFailureError returns an error listing the failed `@cmd:` rules
+// under strict commands, and nil otherwise.
+func (m *Manager) commandFailureError() error {
+	if !m.strictCommands {
+		return nil
+	}
+	failures := m.CommandFailures()
+	if len(failures) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d @cmd: rule(s) failed (--strict):", len(failures))
+	for _, f := range failures {
+		fmt.Fprintf(&sb, "\n  line %d: @cmd: %s — %s", f.LineNum, f.Command, f.Error)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
@@ -0,0 +1,63 @@
+package context
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRecordCommandFailure verifies that a failing @cmd: expression is
+// recorded once with its exit code and stderr, shows up among the skipped
+// rules, and fails generation only under strict commands.
+func TestRecordCommandFailure(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+
+	cmdExpr := "echo 'no such ref: main' >&2; exit 3"
+	_, err := m.executeCommandExpression(cmdExpr)
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	m.recordCommandFailure(4, cmdExpr, err)
+	m.recordCommandFailure(4, cmdExpr, err) // a second resolution pass
+
+	failures := m.CommandFailures()
+	if len(failures) != 1 {
+		t.Fatalf("got %d failures, want 1: %+v", len(failures), failures)
+	}
+	f := failures[0]
+	if f.LineNum != 4 || f.ExitCode != 3 || f.Stderr != "no such ref: main" {
+		t.Errorf("unexpected failure: %+v", f)
+	}
+	if !strings.Contains(f.Error, "no such ref: main") {
+		t.Errorf("error %q does not carry the stderr", f.Error)
+	}
+
+	skipped := m.GetSkippedRules()
+	if len(skipped) != 1 || skipped[0].LineNum != 4 || skipped[0].Rule != "@cmd: "+cmdExpr {
+		t.Errorf("unexpected skipped rules: %+v", skipped)
+	}
+
+	if err := m.commandFailureError(); err != nil {
+		t.Errorf("non-strict generation failed: %v", err)
+	}
+	m.SetStrictCommands(true)
+	if err := m.commandFailureError(); err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("strict generation error = %v, want one naming line 4", err)
+	}
+
+	m.ClearSkippedRules()
+	if n := len(m.CommandFailures()); n != 0 {
+		t.Errorf("ClearSkippedRules left %d command failures", n)
+	}
+}
+
+func TestStderrSnippet(t *testing.T) {
+	long := strings.Repeat("noise line\n", 100) + "fatal: the real error"
+	got := stderrSnippet([]byte(long))
+	if len(got) > maxStderrSnippet+len("…") {
+		t.Errorf("snippet is %d bytes, want at most %d", len(got), maxStderrSnippet)
+	}
+	if !strings.HasPrefix(got, "…noise line") || !strings.HasSuffix(got, "fatal: the real error") {
+		t.Errorf("unexpected snippet %q", got)
+	}
+}
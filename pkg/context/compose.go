@@ -0,0 +1,193 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Directive composition.
+//
+// Directives on a rule line are AND-ed by default. Operators make the
+// composition explicit:
+//
+//	pkg/** @grep: "foo" @and @find: "handler"    both must hold (same as juxtaposition)
+//	pkg/** @grep: "foo" @or @find: "handler"     either may hold
+//	pkg/** @any-of @grep: "Foo" @grep: "Bar"     OR over every directive that follows
+//	pkg/** @all-of @grep: "Foo" @find: "api"     AND over every directive that follows
+//
+// @and binds tighter than @or, so a filter is a disjunction of clauses, each
+// a conjunction of directives. Internally a clause boundary is stored as a
+// SearchDirective named directiveOr.
+//
+// A line that starts its directives with @and or @or composes with the
+// active global filters (@find:/@grep: lines) instead of replacing them:
+//
+//	@grep: "TODO"
+//	src/** @and @find: "api"    TODO files whose path contains "api"
+//	docs/** @or @find: "guide"  TODO files, or anything with "guide" in the path
+const (
+	directiveOr  = "or"
+	directiveAnd = "and"
+)
+
+// directiveOperators maps operator tokens to their meaning. @and and
+// @all-of are no-ops between directives (juxtaposition is AND) but are
+// significant at the start of a line, where they compose with globals.
+var directiveOperators = []string{"@any-of", "@all-of", "@and", "@or"}
+
+// indexDirectiveOperator finds the earliest whitespace-delimited operator
+// token in s, returning its index (of the leading space) and the operator.
+func indexDirectiveOperator(s string) (int, string) {
+	best, bestOp := -1, ""
+	for _, op := range directiveOperators {
+		from := 0
+		for {
+			i := strings.Index(s[from:], " "+op)
+			if i == -1 {
+				break
+			}
+			i += from
+			end := i + 1 + len(op)
+			if end == len(s) || s[end] == ' ' || s[end] == '\t' {
+				if best == -1 || i < best {
+					best, bestOp = i, op
+				}
+				break
+			}
+			from = end
+		}
+	}
+	return best, bestOp
+}
+
+// directiveClauses splits a directive list into its OR-ed clauses. A
+// leading global-composition marker is dropped.
+func directiveClauses(directives []SearchDirective) [][]SearchDirective {
+	directives = stripComposeMarker(directives)
+	var clauses [][]SearchDirective
+	var cur []SearchDirective
+	for _, d := range directives {
+		if d.Name == directiveOr {
+			if len(cur) > 0 {
+				clauses = append(clauses, cur)
+			}
+			cur = nil
+			continue
+		}
+		cur = append(cur, d)
+	}
+	if len(cur) > 0 {
+		clauses = append(clauses, cur)
+	}
+	return clauses
+}
+
+// stripComposeMarker removes a leading @and/@or global-composition marker.
+func stripComposeMarker(directives []SearchDirective) []SearchDirective {
+	if len(directives) > 0 && (directives[0].Name == directiveAnd || directives[0].Name == directiveOr) {
+		return directives[1:]
+	}
+	return directives
+}
+
+// composeWithGlobals applies a rule's inline directives against the active
+// global directives. Without a leading marker the inline directives replace
+// the globals (the historical behavior); with @and the result is
+// globals AND inline, with @or it is globals OR inline.
+func composeWithGlobals(global, inline []SearchDirective) []SearchDirective {
+	// @text and @binary (binary.go) modify the rule, not its filters, so
+	// they neither replace nor combine with the globals.
+	var filters, modifiers []SearchDirective
+	for _, d := range inline {
+		if d.Name == directiveText || d.Name == directiveBinary {
+			modifiers = append(modifiers, d)
+		} else {
+			filters = append(filters, d)
+		}
+	}
+	if len(modifiers) > 0 {
+		return append(append([]SearchDirective{}, composeWithGlobals(global, filters)...), modifiers...)
+	}
+	if len(inline) == 0 {
+		return global
+	}
+	marker := inline[0].Name
+	if marker != directiveAnd && marker != directiveOr {
+		return inline
+	}
+	inline = inline[1:]
+	if len(global) == 0 {
+		return inline
+	}
+	if len(inline) == 0 {
+		return global
+	}
+	if marker == directiveOr {
+		out := append([]SearchDirective{}, global...)
+		out = append(out, SearchDirective{Name: directiveOr})
+		return append(out, inline...)
+	}
+	// AND distributes over OR: (g1 | g2) & (i1 | i2) = g1&i1 | g1&i2 | ...
+	var out []SearchDirective
+	for _, g := range directiveClauses(global) {
+		for _, i := range directiveClauses(inline) {
+			if len(out) > 0 {
+				out = append(out, SearchDirective{Name: directiveOr})
+			}
+			out = append(out, g...)
+			out = append(out, i...)
+		}
+	}
+	return out
+}
+
+// FormatDirectiveClause renders a clause back to rules syntax, e.g.
+// `@grep: "foo" @find: "api"`.
+func FormatDirectiveClause(clause []SearchDirective) string {
+	parts := make([]string, 0, len(clause))
+	for _, d := range clause {
+		parts = append(parts, fmt.Sprintf("@%s: %q", d.Name, d.Query))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ClauseMatch counts the files a rule line admitted through one clause of
+// its directives.
+type ClauseMatch struct {
+	Clause string `json:"clause"`
+	Count  int    `json:"count"`
+}
+
+// globalClause labels files admitted by the global filters of an @or line.
+const globalClause = "(global filters)"
+
+// AttributeDirectiveClauses reports, for a line's inline directives, which
+// clause admitted each of files. It returns nil when the directives leave
+// nothing to attribute: a single clause, not composed with globals by @or.
+func (m *Manager) AttributeDirectiveClauses(files []string, directives []SearchDirective) []ClauseMatch {
+	clauses := directiveClauses(directives)
+	orGlobals := len(directives) > 0 && directives[0].Name == directiveOr
+	if len(clauses) < 2 && !orGlobals {
+		return nil
+	}
+	counts := make(map[string]int)
+	var order []string
+	for _, file := range files {
+		label := globalClause
+		for _, clause := range clauses {
+			if m.matchClause(file, clause) {
+				label = FormatDirectiveClause(clause)
+				break
+			}
+		}
+		if _, seen := counts[label]; !seen {
+			order = append(order, label)
+		}
+		counts[label]++
+	}
+	matches := make([]ClauseMatch, 0, len(order))
+	for _, label := range order {
+		matches = append(matches, ClauseMatch{Clause: label, Count: counts[label]})
+	}
+	return matches
+}
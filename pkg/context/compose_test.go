@@ -0,0 +1,60 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComposeWithGlobals(t *testing.T) {
+	or := SearchDirective{Name: directiveOr}
+	todo := SearchDirective{Name: "grep", Query: "TODO"}
+	fixme := SearchDirective{Name: "grep", Query: "FIXME"}
+	api := SearchDirective{Name: "find", Query: "api"}
+
+	// Without a marker, inline directives replace the globals.
+	assert.Equal(t, []SearchDirective{api}, composeWithGlobals([]SearchDirective{todo}, []SearchDirective{api}))
+	// No inline directives: the globals apply.
+	assert.Equal(t, []SearchDirective{todo}, composeWithGlobals([]SearchDirective{todo}, nil))
+	// @and / @or compose; the marker is dropped when there are no globals.
+	assert.Equal(t, []SearchDirective{todo, api},
+		composeWithGlobals([]SearchDirective{todo}, []SearchDirective{{Name: directiveAnd}, api}))
+	assert.Equal(t, []SearchDirective{todo, or, api},
+		composeWithGlobals([]SearchDirective{todo}, []SearchDirective{{Name: directiveOr}, api}))
+	assert.Equal(t, []SearchDirective{api},
+		composeWithGlobals(nil, []SearchDirective{{Name: directiveAnd}, api}))
+	// AND distributes over OR.
+	assert.Equal(t, []SearchDirective{api, todo, or, api, fixme},
+		composeWithGlobals([]SearchDirective{api}, []SearchDirective{{Name: directiveAnd}, todo, or, fixme}))
+}
+
+func TestMatchDirectivesOr(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"handler.go": "func UserHandler() {}\n",
+		"model.go":   "type User struct{}\n",
+		"README.md":  "docs\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+
+	_, ds, _ := parseSearchDirectives(`** @grep: "Handler" @or @find: "model"`)
+	assert.True(t, m.matchDirectives("handler.go", ds))
+	assert.True(t, m.matchDirectives("model.go", ds))
+	assert.False(t, m.matchDirectives("README.md", ds))
+
+	got := m.AttributeDirectiveClauses([]string{"handler.go", "model.go"}, ds)
+	assert.Equal(t, []ClauseMatch{
+		{Clause: `@grep: "Handler"`, Count: 1},
+		{Clause: `@find: "model"`, Count: 1},
+	}, got)
+
+	// A single clause has nothing to attribute.
+	_, ds, _ = parseSearchDirectives(`** @grep: "User"`)
+	assert.Nil(t, m.AttributeDirectiveClauses([]string{"model.go"}, ds))
+}
@@ -0,0 +1,94 @@
+package context
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Concurrency limits. Walks of independent roots, content directive checks
+// and git subprocesses each run on a bounded pool, by default one worker
+// per CPU up to eight. cx.concurrency overrides each limit:
+//
+//	cx:
+//	  concurrency:
+//	    walkers: 4
+//	    grep: 2
+//	    git: 1
+//
+// Idle mode (`cx watch --idle`, `cx serve --idle`) is for daemons running
+// beside builds: every pool drops to one worker and the process lowers its
+// CPU priority, and its I/O priority where the OS allows it.
+
+// ConcurrencyConfig configures the worker pools. Zero keeps the default.
+type ConcurrencyConfig struct {
+	// Walkers is the number of roots walked at once.
+	Walkers int `yaml:"walkers,omitempty" toml:"walkers,omitempty"`
+	// Grep is the number of files @grep and the other content directives
+	// check at once.
+	Grep int `yaml:"grep,omitempty" toml:"grep,omitempty"`
+	// Git is the number of git subprocesses run at once.
+	Git int `yaml:"git,omitempty" toml:"git,omitempty"`
+}
+
+// maxDefaultWorkers caps the default pools; past it the disk, not the CPU,
+// is the bottleneck.
+const maxDefaultWorkers = 8
+
+var idleMode atomic.Bool
+
+// SetIdle turns idle mode on or off for the process. Turning it on also
+// lowers the process priority, which cannot be undone.
+func SetIdle(idle bool) {
+	idleMode.Store(idle)
+	if idle {
+		if err := lowerPriority(); err != nil {
+			Warnf("could not lower process priority: %v", err)
+		}
+	}
+}
+
+// Idle reports whether idle mode is on.
+func Idle() bool {
+	return idleMode.Load()
+}
+
+// workerLimit returns the size of a pool given its configured size, or the
+// default when it is not configured. Idle mode always yields one.
+func workerLimit(configured int) int {
+	if idleMode.Load() {
+		return 1
+	}
+	if configured > 0 {
+		return configured
+	}
+	n := runtime.GOMAXPROCS(0)
+	if n > maxDefaultWorkers {
+		n = maxDefaultWorkers
+	}
+	return n
+}
+
+// walkWorkers returns the number of roots to walk at once.
+func (m *Manager) walkWorkers() int {
+	return workerLimit(m.cxConfig().Concurrency.Walkers)
+}
+
+// grepWorkers returns the number of files to check content directives on
+// at once.
+func (m *Manager) grepWorkers() int {
+	return workerLimit(m.cxConfig().Concurrency.Grep)
+}
+
+func (c *prodResolutionContext) grepWorkers() int {
+	return c.m.grepWorkers()
+}
+
+// acquireGit blocks until a git subprocess may start and returns the
+// function that releases its slot.
+func (m *Manager) acquireGit() func() {
+	m.gitSlotsOnce.Do(func() {
+		m.gitSlots = make(chan struct{}, workerLimit(m.cxConfig().Concurrency.Git))
+	})
+	m.gitSlots <- struct{}{}
+	return func() { <-m.gitSlots }
+}
@@ -0,0 +1,36 @@
+package context
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWorkerLimits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	m := NewManager(dir)
+
+	def := runtime.GOMAXPROCS(0)
+	if def > maxDefaultWorkers {
+		def = maxDefaultWorkers
+	}
+	if got := m.walkWorkers(); got != def {
+		t.Errorf("default walkWorkers = %d, want %d", got, def)
+	}
+
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  concurrency:\n    walkers: 3\n    grep: 2\n")
+	if got := m.walkWorkers(); got != 3 {
+		t.Errorf("walkWorkers = %d, want 3", got)
+	}
+	if got := m.grepWorkers(); got != 2 {
+		t.Errorf("grepWorkers = %d, want 2", got)
+	}
+
+	// SetIdle would also renice the test binary; set the flag directly.
+	idleMode.Store(true)
+	defer idleMode.Store(false)
+	if got := m.walkWorkers(); got != 1 {
+		t.Errorf("idle walkWorkers = %d, want 1", got)
+	}
+}
@@ -0,0 +1,99 @@
+package context
+
+import (
+	"github.com/grovetools/core/config"
+)
+
+// CxConfig holds cx-only settings read from the "cx" extension key in
+// grove.yml/grove.toml. Unlike ContextConfig (which mirrors core's typed
+// "context" field), these keys are owned by cx and are unknown to core, so
+// they land in Extensions and are read via UnmarshalExtension("cx").
+//
+//	cx:
+//	  metrics: true
+//	  token_budget: 150000
+type CxConfig struct {
+	// Metrics opts in to local usage metrics (see metrics.go). Nothing is
+	// ever sent anywhere; records are appended to .grove/metrics.jsonl.
+	Metrics bool `yaml:"metrics,omitempty" toml:"metrics,omitempty"`
+	// TokenBudget is the hot-context token budget used to classify a
+	// generation as within or over budget. Zero means no budget.
+	TokenBudget int `yaml:"token_budget,omitempty" toml:"token_budget,omitempty"`
+	// Checksums writes a .sha256 sidecar next to each generated artifact so
+	// consumers can detect hand-edited or truncated files.
+	Checksums bool `yaml:"checksums,omitempty" toml:"checksums,omitempty"`
+	// Languages extends language detection (see pkg/lang): keys are
+	// extensions (".tpl") or exact filenames ("Brewfile"), values are
+	// code-fence tags ("gotmpl", "ruby").
+	Languages map[string]string `yaml:"languages,omitempty" toml:"languages,omitempty"`
+	// Notify configures `cx watch` notifications (see notify.go).
+	Notify NotifyConfig `yaml:"notify,omitempty" toml:"notify,omitempty"`
+	// AccessTracking opts in to recording which context files are edited
+	// between generations (see access.go), for `cx suggest-tiering`.
+	AccessTracking bool `yaml:"access_tracking,omitempty" toml:"access_tracking,omitempty"`
+	// TokenRatios overrides the bytes-per-token ratio EstimateTokens uses
+	// for an extension (".go": 2.4), ahead of learned calibration (see
+	// calibrate.go) and the built-in content classes.
+	TokenRatios map[string]float64 `yaml:"token_ratios,omitempty" toml:"token_ratios,omitempty"`
+	// TokenizerCommand is the default command for `cx stats --calibrate`:
+	// it reads a file on stdin and prints its exact token count.
+	TokenizerCommand string `yaml:"tokenizer_command,omitempty" toml:"tokenizer_command,omitempty"`
+	// Sections configures named rules sections (`--- <name>`, see
+	// sections.go): their tier and an optional artifact of their own.
+	Sections map[string]SectionConfig `yaml:"sections,omitempty" toml:"sections,omitempty"`
+	// ArtifactName templates the file names of the generated artifacts,
+	// e.g. "{name}-{branch}-{ruleset}" (see artifactname.go).
+	ArtifactName string `yaml:"artifact_name,omitempty" toml:"artifact_name,omitempty"`
+	// ContentSafety configures the screening of included content for text
+	// that can derail a model (see safety.go).
+	ContentSafety ContentSafetyConfig `yaml:"content_safety,omitempty" toml:"content_safety,omitempty"`
+	// ModelBudgets are per-model hot-context token budgets for `cx generate
+	// --model` and --all-models (see modelvariants.go).
+	ModelBudgets map[string]int `yaml:"model_budgets,omitempty" toml:"model_budgets,omitempty"`
+	// TokenThresholds are the token counts at which files and directories
+	// are flagged in the TUI, stats and generate (see thresholds.go).
+	TokenThresholds TokenThresholdsConfig `yaml:"token_thresholds,omitempty" toml:"token_thresholds,omitempty"`
+	// NotesInContext adds the project's pinned notes (see notes.go) to the
+	// hot context as a consolidated markdown file.
+	NotesInContext bool `yaml:"notes_in_context,omitempty" toml:"notes_in_context,omitempty"`
+	// Order is the file order within each tier: "alpha" (the default) or
+	// "stable", which keeps the previous generation's order (see order.go).
+	Order string `yaml:"order,omitempty" toml:"order,omitempty"`
+	// Concurrency sizes the walk, content directive and git worker pools
+	// (see concurrency.go).
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty" toml:"concurrency,omitempty"`
+	// Binary extends binary file detection (see binary.go).
+	Binary BinaryConfig `yaml:"binary,omitempty" toml:"binary,omitempty"`
+	// OutputFormat is the format of generated context: "xml" (the
+	// default), "markdown", "jsonl" or a template in .cx/templates (see
+	// outputformat.go).
+	OutputFormat string `yaml:"output_format,omitempty" toml:"output_format,omitempty"`
+	// Provenance precedes each generated file with the rules line that
+	// included it (see provenance.go).
+	Provenance bool `yaml:"provenance,omitempty" toml:"provenance,omitempty"`
+}
+
+// LoadCxConfig reads the "cx" extension from the merged grove config for
+// workDir, with the environment overrides in env.go applied on top. A
+// missing or unreadable config yields the zero value: every cx setting is
+// optional and defaults to off. Standalone runs skip the grove config.
+func LoadCxConfig(workDir string) CxConfig {
+	var cfg CxConfig
+	if !Standalone() {
+		if coreCfg, err := config.LoadFrom(workDir); err == nil && coreCfg != nil {
+			_ = coreCfg.UnmarshalExtension("cx", &cfg)
+		}
+	}
+	applyEnvOverrides(&cfg)
+	return cfg
+}
+
+// cxConfig returns the manager's cx settings: LoadCxConfig for workDir, or
+// the zero value for a WithNoState manager, which reads neither grove
+// config nor environment overrides.
+func (m *Manager) cxConfig() CxConfig {
+	if m.noState {
+		return CxConfig{}
+	}
+	return LoadCxConfig(m.workDir)
+}
@@ -0,0 +1,83 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/pkg/paths"
+)
+
+// configCheckInterval bounds how often a cached Manager checks whether its
+// grove config changed. One-shot commands finish well within it; the
+// long-running modes (cx watch, cx serve, cx view), which fetch their
+// manager through NewManager on every refresh, see an edited grove.yml at
+// most this late.
+const configCheckInterval = 2 * time.Second
+
+// configFileNames are the grove config and override files that can take
+// part in a merged config, in any directory from workDir up and in the
+// global config directory.
+var configFileNames = []string{
+	"grove.yml", "grove.yaml", "grove.toml",
+	".grove.yml", ".grove.yaml", ".grove.toml",
+	"grove.override.yml", "grove.override.yaml", "grove.override.toml",
+}
+
+// IsConfigFile reports whether path names a grove config or override file.
+func IsConfigFile(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range configFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configFingerprint identifies the config files that can affect workDir by
+// path, size and modification time. It only stats files, so it is cheap
+// enough to compute on the NewManager path; creating, editing or removing
+// any of them changes it.
+func configFingerprint(workDir string) string {
+	dirs := []string{}
+	for dir := workDir; ; {
+		dirs = append(dirs, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if global := paths.ConfigDir(); global != "" {
+		dirs = append(dirs, global)
+	}
+
+	var b strings.Builder
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				fmt.Fprintf(&b, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+			}
+		}
+	}
+	return b.String()
+}
+
+// configChanged reports whether the config files behind the manager changed
+// since it was built. Checks are throttled to one per configCheckInterval.
+func (m *Manager) configChanged() bool {
+	if m.noState {
+		return false
+	}
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	if time.Since(m.configCheckedAt) < configCheckInterval {
+		return false
+	}
+	m.configCheckedAt = time.Now()
+	return configFingerprint(m.workDir) != m.configStamp
+}
@@ -0,0 +1,57 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewManagerReloadsChangedConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	groveYml := filepath.Join(dir, "grove.yml")
+	fsWriteString(t, groveYml, "version: 1.0\n")
+
+	m := NewManager(dir)
+	if NewManager(dir) != m {
+		t.Fatal("expected the cached manager")
+	}
+
+	// Unchanged config keeps the instance even once the throttle lapses.
+	m.configMu.Lock()
+	m.configCheckedAt = time.Time{}
+	m.configMu.Unlock()
+	if NewManager(dir) != m {
+		t.Fatal("unchanged config should not replace the manager")
+	}
+
+	fsWriteString(t, groveYml, "version: 1.0\ncontext:\n  excluded_workspaces: [legacy]\n")
+	if NewManager(dir) != m {
+		t.Fatal("changes should be noticed at most once per configCheckInterval")
+	}
+	m.configMu.Lock()
+	m.configCheckedAt = time.Time{}
+	m.configMu.Unlock()
+	fresh := NewManager(dir)
+	if fresh == m {
+		t.Fatal("changed config should replace the cached manager")
+	}
+	if NewManager(dir) != fresh {
+		t.Error("the replacement should be cached")
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/p/grove.yml":          true,
+		"grove.toml":            true,
+		"/p/.grove.yaml":        true,
+		"/p/grove.override.yml": true,
+		"/p/.grove/rules":       false,
+		"/p/grove.yml.swp":      false,
+	} {
+		if got := IsConfigFile(path); got != want {
+			t.Errorf("IsConfigFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
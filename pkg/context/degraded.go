@@ -0,0 +1,66 @@
+package context
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/grovetools/core/pkg/alias"
+	"github.com/grovetools/core/pkg/workspace"
+)
+
+// Degraded discovery.
+//
+// Workspace discovery can fail outright, most often in minimal CI
+// containers without grove config or a reachable daemon. Rather than
+// failing every path check ("cannot validate path, workspace discovery
+// failed") and every alias, cx then treats the workspace it runs in as the
+// only one: rules inside it resolve as usual, @a: aliases naming it still
+// work, and aliases to any other workspace fail as unknown. The fallback is
+// reported once per workspace on stderr.
+
+// warnedDegraded records the workspaces whose degraded mode was reported.
+var warnedDegraded sync.Map
+
+// NewAliasResolver returns an alias resolver for workDir with workspaces
+// discovered from disk. When discovery fails it returns a resolver knowing
+// only the workspace containing workDir, along with the discovery error.
+func NewAliasResolver(workDir string) (*alias.AliasResolver, error) {
+	r := alias.NewAliasResolverWithWorkDir(workDir)
+	r.InitProvider()
+	if r.DiscoverErr == nil && r.Provider != nil {
+		return r, nil
+	}
+	err := r.DiscoverErr
+	if err == nil {
+		err = fmt.Errorf("workspace provider could not be initialized")
+	}
+	return degradedAliasResolver(workDir, err), err
+}
+
+// degradedAliasResolver builds a resolver whose only workspace is dir,
+// warning about discoverErr the first time dir degrades.
+func degradedAliasResolver(dir string, discoverErr error) *alias.AliasResolver {
+	if _, warned := warnedDegraded.LoadOrStore(dir, true); !warned {
+		Warnf("workspace discovery unavailable (%v); treating %s as the only workspace", discoverErr, dir)
+	}
+	r := alias.NewAliasResolverWithWorkDir(dir)
+	r.InitProviderFromNodes([]*workspace.WorkspaceNode{degradedWorkspaceNode(dir)})
+	return r
+}
+
+// degradedWorkspaceNode describes dir as a workspace without discovery:
+// the direct lookup when it succeeds, otherwise a plain repository named
+// after the directory.
+func degradedWorkspaceNode(dir string) *workspace.WorkspaceNode {
+	if node, err := workspace.GetProjectByPath(dir); err == nil && node != nil && node.Path == dir {
+		return node
+	}
+	return &workspace.WorkspaceNode{Name: filepath.Base(dir), Path: dir, Kind: workspace.KindNonGroveRepo}
+}
+
+// DiscoveryDegraded returns the workspace discovery error that put the
+// manager in degraded mode, or nil when discovery works (or has not run).
+func (m *Manager) DiscoveryDegraded() error {
+	return m.discoveryErr
+}
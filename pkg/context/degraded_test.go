@@ -0,0 +1,40 @@
+package context
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestManager_DegradedDiscovery verifies that a manager whose workspace
+// discovery failed treats its own workspace as the only one: paths inside it
+// are allowed, paths elsewhere are not, and aliases resolve to it alone.
+func TestManager_DegradedDiscovery(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	m := NewManager(dir)
+	m.discoveryErr = errors.New("discovery unavailable")
+
+	if allowed, reason := m.IsPathAllowed(filepath.Join(dir, "main.go")); !allowed {
+		t.Errorf("path inside the workspace blocked: %s", reason)
+	}
+	if allowed, _ := m.IsPathAllowed(filepath.Join(outside, "main.go")); allowed {
+		t.Error("path outside the workspace allowed")
+	}
+
+	resolver := m.getAliasResolver()
+	if resolver.Provider == nil || len(resolver.Provider.All()) != 1 {
+		t.Fatalf("degraded resolver should know exactly one workspace")
+	}
+	if got, err := resolver.Resolve(filepath.Base(dir)); err != nil || got != dir {
+		t.Errorf("Resolve(%q) = %q, %v; want %q", filepath.Base(dir), got, err, dir)
+	}
+	if _, err := resolver.Resolve("some-other-project"); err == nil {
+		t.Error("alias to another workspace resolved")
+	}
+	if m.DiscoveryDegraded() == nil {
+		t.Error("DiscoveryDegraded() = nil, want the discovery error")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // FileInfo represents information about a file
@@ -61,6 +63,45 @@ func (m *Manager) DiffContext(rulesetName string) (*DiffResult, error) {
 	return calculateDiff(currentFiles, compareFiles), nil
 }
 
+// DiffSnapshot compares the current context with snap. Files are keyed as
+// in snapshots (relative to the workspace), and the snapshot side carries
+// the token counts it recorded; snapshots record no sizes, so its sizes are
+// zero.
+func (m *Manager) DiffSnapshot(snap *ContextSnapshot) (*DiffResult, error) {
+	files, err := m.ResolveFilesFromRules()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving current context: %w", err)
+	}
+	result := &DiffResult{
+		CurrentFiles: make(map[string]FileInfo),
+		CompareFiles: make(map[string]FileInfo),
+	}
+	for file, tokens := range snap.Files {
+		result.CompareFiles[file] = FileInfo{Path: file, Tokens: tokens}
+		result.CompareTotalTokens += tokens
+	}
+	for _, file := range files {
+		abs := file
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(m.workDir, file)
+		}
+		info := getFileInfo(abs)
+		info.Path = m.snapshotKey(file)
+		result.CurrentFiles[info.Path] = info
+		result.CurrentTotalTokens += info.Tokens
+		result.CurrentTotalSize += info.Size
+		if _, exists := result.CompareFiles[info.Path]; !exists {
+			result.Added = append(result.Added, info)
+		}
+	}
+	for file, info := range result.CompareFiles {
+		if _, exists := result.CurrentFiles[file]; !exists {
+			result.Removed = append(result.Removed, info)
+		}
+	}
+	return result, nil
+}
+
 // calculateDiff computes the difference between two file lists
 func calculateDiff(currentFiles, compareFiles []string) *DiffResult {
 	result := &DiffResult{
@@ -122,7 +163,7 @@ func (d *DiffResult) Print(compareName string) {
 			return d.Added[i].Tokens > d.Added[j].Tokens
 		})
 		for _, f := range d.Added {
-			fmt.Printf("  + %-50s (%s tokens)\n", TruncatePath(f.Path, 50), FormatTokenCount(f.Tokens))
+			fmt.Printf("  + %s (%s tokens)\n", PadWidth(TruncatePath(f.Path, 50), 50), FormatTokenCount(f.Tokens))
 		}
 		fmt.Println()
 	}
@@ -134,7 +175,7 @@ func (d *DiffResult) Print(compareName string) {
 			return d.Removed[i].Tokens > d.Removed[j].Tokens
 		})
 		for _, f := range d.Removed {
-			fmt.Printf("  - %-50s (%s tokens)\n", TruncatePath(f.Path, 50), FormatTokenCount(f.Tokens))
+			fmt.Printf("  - %s (%s tokens)\n", PadWidth(TruncatePath(f.Path, 50), 50), FormatTokenCount(f.Tokens))
 		}
 		fmt.Println()
 	}
@@ -174,20 +215,20 @@ func (d *DiffResult) Print(compareName string) {
 
 // TruncatePath shortens a path for display
 func TruncatePath(path string, maxLen int) string {
-	if len(path) <= maxLen {
+	if lipgloss.Width(path) <= maxLen {
 		return path
 	}
 
 	// Try to keep the most important parts
 	parts := strings.Split(path, string(filepath.Separator))
 	if len(parts) <= 2 {
-		return path[:maxLen-3] + "..."
+		return TruncateWidth(path, maxLen, "...")
 	}
 
 	// Keep first and last parts
 	result := parts[0] + "/.../" + parts[len(parts)-1]
-	if len(result) > maxLen {
-		return "..." + path[len(path)-(maxLen-3):]
+	if lipgloss.Width(result) > maxLen {
+		return TruncateWidthLeft(path, maxLen, "...")
 	}
 
 	return result
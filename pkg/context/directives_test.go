@@ -129,3 +129,90 @@ func TestEncodeDecodeDirectivesRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSearchDirectivesComposition(t *testing.T) {
+	or := SearchDirective{Name: directiveOr}
+	tests := []struct {
+		name           string
+		input          string
+		wantBase       string
+		wantDirectives []SearchDirective
+	}{
+		{
+			name:           "explicit and",
+			input:          `pkg/** @grep: "foo" @and @find: "handler"`,
+			wantBase:       "pkg/**",
+			wantDirectives: []SearchDirective{{Name: "grep", Query: "foo"}, {Name: "find", Query: "handler"}},
+		},
+		{
+			name:           "or",
+			input:          `pkg/** @grep: "foo" @or @find: "handler"`,
+			wantBase:       "pkg/**",
+			wantDirectives: []SearchDirective{{Name: "grep", Query: "foo"}, or, {Name: "find", Query: "handler"}},
+		},
+		{
+			name:     "and binds tighter than or",
+			input:    `pkg/** @grep: "a" @find: "b" @or @grep: "c"`,
+			wantBase: "pkg/**",
+			wantDirectives: []SearchDirective{
+				{Name: "grep", Query: "a"}, {Name: "find", Query: "b"}, or, {Name: "grep", Query: "c"},
+			},
+		},
+		{
+			name:     "any-of",
+			input:    `pkg/** @any-of @grep: "Foo" @grep: "Bar" @find: "baz"`,
+			wantBase: "pkg/**",
+			wantDirectives: []SearchDirective{
+				{Name: "grep", Query: "Foo"}, or, {Name: "grep", Query: "Bar"}, or, {Name: "find", Query: "baz"},
+			},
+		},
+		{
+			name:     "any-of shares preceding directives",
+			input:    `pkg/** @find: "api" @any-of @grep: "Foo" @grep: "Bar"`,
+			wantBase: "pkg/**",
+			wantDirectives: []SearchDirective{
+				{Name: "find", Query: "api"}, {Name: "grep", Query: "Foo"}, or,
+				{Name: "find", Query: "api"}, {Name: "grep", Query: "Bar"},
+			},
+		},
+		{
+			name:           "all-of",
+			input:          `pkg/** @all-of @grep: "Foo" @find: "api"`,
+			wantBase:       "pkg/**",
+			wantDirectives: []SearchDirective{{Name: "grep", Query: "Foo"}, {Name: "find", Query: "api"}},
+		},
+		{
+			name:           "leading and composes with globals",
+			input:          `src/** @and @find: "api"`,
+			wantBase:       "src/**",
+			wantDirectives: []SearchDirective{{Name: directiveAnd}, {Name: "find", Query: "api"}},
+		},
+		{
+			name:           "unquoted query stops at operator",
+			input:          `src/** @changed: main @or @find: api`,
+			wantBase:       "src/**",
+			wantDirectives: []SearchDirective{{Name: "changed", Query: "main"}, or, {Name: "find", Query: "api"}},
+		},
+		{
+			name:           "operator inside quotes is literal",
+			input:          `src/** @grep: "a @or b"`,
+			wantBase:       "src/**",
+			wantDirectives: []SearchDirective{{Name: "grep", Query: "a @or b"}},
+		},
+		{
+			name:           "operator prefix in path is not an operator",
+			input:          `node_modules/@org/pkg/** @grep: "x"`,
+			wantBase:       "node_modules/@org/pkg/**",
+			wantDirectives: []SearchDirective{{Name: "grep", Query: "x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, directives, has := parseSearchDirectives(tt.input)
+			assert.Equal(t, tt.wantBase, base)
+			assert.Equal(t, tt.wantDirectives, directives)
+			assert.True(t, has)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package context
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirTokens is one node of a directory tree annotated with the tokens of the
+// context files beneath it. Leaves are files; Children are sorted by tokens,
+// largest first.
+type DirTokens struct {
+	Name     string       `json:"name"`
+	Path     string       `json:"path"`
+	Tokens   int          `json:"tokens"`
+	Files    int          `json:"files"`
+	IsDir    bool         `json:"is_dir"`
+	Children []*DirTokens `json:"children,omitempty"`
+}
+
+// TokensByDirectory builds a token tree for files. Paths under the workspace
+// are shown relative to it; anything else (git rule checkouts, sibling
+// projects) keeps its absolute path. Unreadable files are skipped.
+func (m *Manager) TokensByDirectory(files []string) *DirTokens {
+	root := &DirTokens{Name: ".", Path: ".", IsDir: true}
+	provider := GetStatsProvider()
+
+	for _, file := range files {
+		abs := file
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(m.workDir, file)
+		}
+		info, err := provider.GetFileStats(abs)
+		if err != nil {
+			continue
+		}
+
+		display := abs
+		if rel, err := filepath.Rel(m.workDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			display = rel
+		}
+		root.add(strings.Split(filepath.ToSlash(display), "/"), info.Tokens)
+	}
+
+	root.sort()
+	return root
+}
+
+func (d *DirTokens) add(parts []string, tokens int) {
+	d.Tokens += tokens
+	d.Files++
+	if len(parts) == 0 {
+		return
+	}
+	name := parts[0]
+	if name == "" {
+		// Leading "/" of an absolute path.
+		name = "/"
+	}
+	var child *DirTokens
+	for _, c := range d.Children {
+		if c.Name == name {
+			child = c
+			break
+		}
+	}
+	if child == nil {
+		childPath := name
+		if d.Path != "." {
+			childPath = strings.TrimSuffix(d.Path, "/") + "/" + name
+		}
+		child = &DirTokens{Name: name, Path: childPath, IsDir: len(parts) > 1}
+		d.Children = append(d.Children, child)
+	}
+	child.add(parts[1:], tokens)
+}
+
+func (d *DirTokens) sort() {
+	sort.SliceStable(d.Children, func(i, j int) bool {
+		if d.Children[i].Tokens != d.Children[j].Tokens {
+			return d.Children[i].Tokens > d.Children[j].Tokens
+		}
+		return d.Children[i].Name < d.Children[j].Name
+	})
+	for _, c := range d.Children {
+		c.sort()
+	}
+}
@@ -0,0 +1,43 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTokensByDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{"pkg/a/big.go": 4000, "pkg/a/small.go": 400, "pkg/b.go": 800, "main.go": 1200}
+	var paths []string
+	for name, size := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	m := &Manager{workDir: dir}
+	root := m.TokensByDirectory(paths)
+	if root.Files != 4 {
+		t.Fatalf("root files = %d, want 4", root.Files)
+	}
+	if len(root.Children) != 2 || root.Children[0].Path != "pkg" || !root.Children[0].IsDir {
+		t.Fatalf("expected pkg/ to be the largest top-level entry, got %+v", root.Children)
+	}
+	pkg := root.Children[0]
+	if pkg.Files != 3 || pkg.Children[0].Path != "pkg/a" {
+		t.Errorf("unexpected pkg subtree: %+v", pkg.Children)
+	}
+	sum := 0
+	for _, c := range root.Children {
+		sum += c.Tokens
+	}
+	if sum != root.Tokens {
+		t.Errorf("children tokens %d != root tokens %d", sum, root.Tokens)
+	}
+}
@@ -0,0 +1,383 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dry runs. Mutating commands take --dry-run to preview what they would
+// write: each file they would create, change or delete (with a unified diff
+// when the file is a rules file or a files list), and the grove state they
+// would change. Nothing is written while planning.
+
+// Planned change actions.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionDelete    = "delete"
+	ActionState     = "state" // a grove state key would change; Path is the key
+)
+
+// PlannedChange is one write a command would make.
+type PlannedChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// PlanFileWrite plans writing content to path, diffing it against the
+// file's current content.
+func PlanFileWrite(path string, content []byte) PlannedChange {
+	old, err := os.ReadFile(path)
+	switch {
+	case err != nil:
+		return PlannedChange{Path: path, Action: ActionCreate, Diff: UnifiedDiff("/dev/null", path, nil, content)}
+	case bytes.Equal(old, content):
+		return PlannedChange{Path: path, Action: ActionUnchanged}
+	default:
+		return PlannedChange{Path: path, Action: ActionUpdate, Diff: UnifiedDiff(path, path, old, content)}
+	}
+}
+
+// PlanFileDelete plans removing the file at path.
+func PlanFileDelete(path string) PlannedChange {
+	old, _ := os.ReadFile(path)
+	return PlannedChange{Path: path, Action: ActionDelete, Diff: UnifiedDiff(path, "/dev/null", old, nil)}
+}
+
+// PlanStateChange plans setting the grove state key from its current value
+// to value; an empty value deletes the key.
+func PlanStateChange(key, current, value string) PlannedChange {
+	show := func(v string) string {
+		if v == "" {
+			return "(unset)"
+		}
+		return v
+	}
+	if current == value {
+		return PlannedChange{Path: key, Action: ActionUnchanged, Detail: show(current)}
+	}
+	return PlannedChange{Path: key, Action: ActionState, Detail: show(current) + " -> " + show(value)}
+}
+
+// filesListContent renders files as WriteFilesList writes them.
+func filesListContent(files []string) []byte {
+	var b bytes.Buffer
+	for _, f := range files {
+		b.WriteString(f + "\n")
+	}
+	return b.Bytes()
+}
+
+// planArtifact plans regenerating the context artifact at path with files.
+// Artifacts are too large to diff, so the plan lists the files that would
+// enter or leave it instead, when the output format can be read back.
+func (m *Manager) planArtifact(path string, files []string) PlannedChange {
+	change := PlannedChange{Path: path, Action: ActionCreate, Detail: fmt.Sprintf("%d files", len(files))}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return change
+	}
+	change.Action = ActionUpdate
+	if err := m.artifactsReadable(); err != nil {
+		change.Detail += ", files not compared: " + err.Error()
+		return change
+	}
+	existing, err := artifactFileSet(data)
+	if err != nil {
+		return change
+	}
+	included := make(map[string]bool, len(files))
+	var diff strings.Builder
+	added, removed := 0, 0
+	for _, f := range files {
+		included[f] = true
+		if _, ok := existing[f]; !ok {
+			fmt.Fprintf(&diff, "+%s\n", f)
+			added++
+		}
+	}
+	var gone []string
+	for f := range existing {
+		if !included[f] {
+			gone = append(gone, f)
+		}
+	}
+	sort.Strings(gone)
+	for _, f := range gone {
+		fmt.Fprintf(&diff, "-%s\n", f)
+		removed++
+	}
+	change.Detail += fmt.Sprintf(", +%d -%d", added, removed)
+	change.Diff = diff.String()
+	return change
+}
+
+// PlanGeneration plans `cx generate`: the artifacts and files lists that
+// would be written from rulesFile (or, when empty, the active rules),
+// limited to onlyTier ("hot" or "cold") when set. Generation errors that
+// would stop the real run, such as unmet @require: directives, are returned.
+func (m *Manager) PlanGeneration(rulesFile, onlyTier string) ([]PlannedChange, error) {
+	var hot, cold, treePaths []string
+	var rulesContent []byte
+	var err error
+	if rulesFile != "" {
+		if hot, cold, err = m.ResolveFilesFromCustomRulesFile(rulesFile); err != nil {
+			return nil, fmt.Errorf("failed to resolve files from rules file: %w", err)
+		}
+		if rulesContent, err = os.ReadFile(rulesFile); err != nil {
+			return nil, fmt.Errorf("failed to read rules file: %w", err)
+		}
+	} else {
+		if onlyTier != "cold" {
+			if hot, treePaths, err = m.ResolveFilesAndTreesFromRules(); err != nil {
+				return nil, fmt.Errorf("error resolving files from rules: %w", err)
+			}
+		}
+		if onlyTier != "hot" {
+			if cold, err = m.ResolveColdContextFiles(); err != nil {
+				return nil, fmt.Errorf("error resolving cold context files: %w", err)
+			}
+		}
+		rulesContent, _, _ = m.LoadRulesContent()
+	}
+	hot, cold, err = m.budgetFiles(rulesContent, hot, cold, rulesFile == "" && onlyTier == "cold", rulesFile == "" && onlyTier == "hot")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []PlannedChange
+	seal := func(path string) {
+		if m.checksums {
+			action := ActionCreate
+			if _, err := os.Stat(path + ChecksumExt); err == nil {
+				action = ActionUpdate
+			}
+			changes = append(changes, PlannedChange{Path: path + ChecksumExt, Action: action})
+		}
+	}
+	if rulesFile != "" || onlyTier != "cold" {
+		if err := m.checkRequiredFiles(rulesContent, "hot", hot); err != nil {
+			return nil, err
+		}
+		path := m.ResolveContextWritePath()
+		change := m.planArtifact(path, hot)
+		if len(treePaths) > 0 {
+			change.Detail += fmt.Sprintf(", %d trees", len(treePaths))
+		}
+		changes = append(changes, change)
+		seal(path)
+	}
+	if rulesFile != "" || onlyTier != "hot" {
+		if err := m.checkRequiredFiles(rulesContent, "cold", cold); err != nil {
+			return nil, err
+		}
+		path := m.ResolveCachedContextWritePath()
+		changes = append(changes, m.planArtifact(path, cold))
+		seal(path)
+		listPath := m.ResolveCachedContextFilesListWritePath()
+		changes = append(changes, PlanFileWrite(listPath, filesListContent(cold)))
+		seal(listPath)
+	}
+	if err := m.commandFailureError(); err != nil {
+		return nil, err
+	}
+	if rulesFile != "" {
+		listPath := m.ResolveContextFilesListWritePath()
+		changes = append(changes, PlanFileWrite(listPath, filesListContent(hot)))
+		seal(listPath)
+	}
+
+	if rulesFile != "" || onlyTier != "hot" {
+		sectionChanges, err := m.planSectionArtifacts(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, sectionChanges...)
+	}
+	return changes, nil
+}
+
+// planSectionArtifacts plans the section artifacts generateSectionArtifacts
+// would write.
+func (m *Manager) planSectionArtifacts(rulesPath string) ([]PlannedChange, error) {
+	configured := false
+	for _, sc := range m.cxConfig().Sections {
+		configured = configured || sc.Output != ""
+	}
+	if !configured {
+		return nil, nil
+	}
+	sections, err := m.ResolveSectionFiles(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	var changes []PlannedChange
+	for _, s := range sections {
+		if s.Output == "" {
+			continue
+		}
+		path := s.Output
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		change := m.planArtifact(path, s.Files)
+		change.Detail = fmt.Sprintf("section %s, %s", s.Name, change.Detail)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// diffContextLines is how many unchanged lines surround each hunk.
+const diffContextLines = 3
+
+// maxDiffCells bounds the line-by-line comparison table; larger changes are
+// shown as a whole replacement of the differing region.
+const maxDiffCells = 4 << 20
+
+// diffOp is one line of a diff: ' ' kept, '-' removed or '+' added.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns a unified diff turning a into b, labelled with
+// oldName and newName, or "" when they are equal.
+func UnifiedDiff(oldName, newName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	ops := diffLines(splitDiffLines(a), splitDiffLines(b))
+
+	// Line numbers before each op, to label hunks.
+	oldNo := make([]int, len(ops)+1)
+	newNo := make([]int, len(ops)+1)
+	for k, op := range ops {
+		oldNo[k+1], newNo[k+1] = oldNo[k], newNo[k]
+		if op.kind != '+' {
+			oldNo[k+1]++
+		}
+		if op.kind != '-' {
+			newNo[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Extend the hunk while the next change is close enough to share
+		// context with this one.
+		start := max(0, k-diffContextLines)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				break
+			}
+			end = next
+		}
+		end = min(len(ops), end+diffContextLines)
+
+		oldStart, oldCount := oldNo[start]+1, oldNo[end]-oldNo[start]
+		newStart, newCount := newNo[start]+1, newNo[end]-newNo[start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+func splitDiffLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines computes a line diff of x and y from their longest common
+// subsequence, after trimming the common prefix and suffix.
+func diffLines(x, y []string) []diffOp {
+	pre := 0
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+
+	ops := make([]diffOp, 0, len(x)+len(y))
+	for _, l := range x[:pre] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	mx, my := x[pre:len(x)-suf], y[pre:len(y)-suf]
+	if len(mx)*len(my) > maxDiffCells {
+		for _, l := range mx {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range my {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the length of the LCS of mx[i:] and my[j:].
+		lcs := make([][]int, len(mx)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(my)+1)
+		}
+		for i := len(mx) - 1; i >= 0; i-- {
+			for j := len(my) - 1; j >= 0; j-- {
+				if mx[i] == my[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(mx) || j < len(my) {
+			switch {
+			case i < len(mx) && j < len(my) && mx[i] == my[j]:
+				ops = append(ops, diffOp{' ', mx[i]})
+				i++
+				j++
+			case i < len(mx) && (j == len(my) || lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, diffOp{'-', mx[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', my[j]})
+				j++
+			}
+		}
+	}
+	for _, l := range x[len(x)-suf:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
@@ -0,0 +1,100 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, "line"+string(rune('a'+i)))
+	}
+	b = append(b, a...)
+	b[1] = "changed"              // first hunk
+	b = append(b[:15], b[16:]...) // second hunk: a deletion
+	old := []byte(strings.Join(a, "\n") + "\n")
+	updated := []byte(strings.Join(b, "\n") + "\n")
+
+	want := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ lineb
+-linec
++changed
+ lined
+ linee
+ linef
+@@ -13,7 +13,6 @@
+ linen
+ lineo
+ linep
+-lineq
+ liner
+ lines
+ linet
+`
+	if got := UnifiedDiff("old", "new", old, updated); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("old", "new", old, old); got != "" {
+		t.Errorf("diff of equal content = %q, want empty", got)
+	}
+	if got := UnifiedDiff("/dev/null", "new", nil, []byte("src/**\n")); got != "--- /dev/null\n+++ new\n@@ -0,0 +1,1 @@\n+src/**\n" {
+		t.Errorf("creation diff = %q", got)
+	}
+}
+
+func TestPlanFileWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.rules")
+	if c := PlanFileWrite(path, []byte("src/**\n")); c.Action != ActionCreate || !strings.Contains(c.Diff, "+src/**") {
+		t.Errorf("plan for a new file = %+v", c)
+	}
+	if err := os.WriteFile(path, []byte("src/**\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if c := PlanFileWrite(path, []byte("src/**\n")); c.Action != ActionUnchanged || c.Diff != "" {
+		t.Errorf("plan for identical content = %+v", c)
+	}
+	c := PlanFileWrite(path, []byte("src/**\n!src/gen/**\n"))
+	if c.Action != ActionUpdate || !strings.Contains(c.Diff, "+!src/gen/**") {
+		t.Errorf("plan for changed content = %+v", c)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "src/**\n" {
+		t.Errorf("planning wrote the file: %q", data)
+	}
+}
+
+// TestPlanArtifact verifies that an artifact plan lists the files entering
+// and leaving it.
+func TestPlanArtifact(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{workDir: dir}
+	path := filepath.Join(dir, "context")
+	if c := m.planArtifact(path, []string{"a.go"}); c.Action != ActionCreate || c.Detail != "1 files" {
+		t.Errorf("plan for a new artifact = %+v", c)
+	}
+	artifact := "<context>\n  <hot-context files=\"2\">\n" +
+		"    <file path=\"a.go\">\nx\n    </file>\n" +
+		"    <file path=\"b.go\">\ny\n    </file>\n" +
+		"  </hot-context>\n</context>\n"
+	if err := os.WriteFile(path, []byte(artifact), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := m.planArtifact(path, []string{"a.go", "c.go"})
+	if c.Action != ActionUpdate || c.Detail != "2 files, +1 -1" || c.Diff != "+c.go\n-b.go\n" {
+		t.Errorf("plan for an existing artifact = %+v", c)
+	}
+
+	markdown := "## a.go\n\n```go\nx\n```\n\n## b.go\n\n```go\ny\n```\n\n"
+	if err := os.WriteFile(path, []byte(markdown), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m.SetOutputFormat(FormatMarkdown)
+	c = m.planArtifact(path, []string{"a.go", "c.go"})
+	if c.Detail != "2 files, +1 -1" || c.Diff != "+c.go\n-b.go\n" {
+		t.Errorf("plan for an existing markdown artifact = %+v", c)
+	}
+}
@@ -0,0 +1,208 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/grovetools/core/config"
+	"github.com/grovetools/core/state"
+)
+
+// Sources reported in EffectiveSetting.Source besides the config layers
+// (config.SourceGlobal, config.SourceProject, ...).
+const (
+	SourceEnv     = "env"     // an environment variable overrides the config
+	SourceState   = "state"   // grove-core state (.grove/state)
+	SourceDerived = "derived" // computed from other settings
+)
+
+// EffectiveSetting is one resolved setting and where its value came from.
+type EffectiveSetting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+	Origin string      `json:"origin,omitempty"` // file or variable the value was read from
+}
+
+// ConfigLayer is a config file that takes part in the merge.
+type ConfigLayer struct {
+	Source string `json:"source"`
+	Path   string `json:"path"`
+}
+
+// EffectiveConfig is the merged configuration cx runs with for a workDir.
+type EffectiveConfig struct {
+	WorkDir  string             `json:"workDir"`
+	Layers   []ConfigLayer      `json:"layers"` // lowest precedence first
+	Settings []EffectiveSetting `json:"settings"`
+}
+
+// configLayer is one parsed config file, in merge order.
+type configLayer struct {
+	source string
+	path   string
+	cfg    *config.Config
+}
+
+// layersInMergeOrder lists the layers of lc in the order config.LoadFrom
+// merges them; later layers win. Project override files are skipped while
+// GROVE_CONFIG_OVERLAY is active, as they are by the loader.
+func layersInMergeOrder(lc *config.LayeredConfig) []configLayer {
+	var layers []configLayer
+	add := func(source config.ConfigSource, path string, cfg *config.Config) {
+		if cfg != nil {
+			layers = append(layers, configLayer{source: string(source), path: path, cfg: cfg})
+		}
+	}
+	add(config.SourceGlobal, lc.FilePaths[config.SourceGlobal], lc.Global)
+	if lc.GlobalOverride != nil {
+		add(config.SourceGlobalOverride, lc.GlobalOverride.Path, lc.GlobalOverride.Config)
+	}
+	if lc.EnvOverlay != nil {
+		add(config.SourceEnvOverlay, lc.EnvOverlay.Path, lc.EnvOverlay.Config)
+	}
+	add(config.SourceEcosystem, lc.FilePaths[config.SourceEcosystem], lc.Ecosystem)
+	add(config.SourceProject, lc.FilePaths[config.SourceProject], lc.Project)
+	if lc.EnvOverlay == nil {
+		for _, o := range lc.Overrides {
+			add(config.SourceOverride, o.Path, o.Config)
+		}
+	}
+	return layers
+}
+
+// EffectiveConfig reports every setting that shapes context resolution for
+// the manager's workDir: the grove.yml "context" section, the cx extension,
+// environment overrides and state such as the active rule set. Each value
+// is attributed to the highest-precedence source that sets it; unset keys
+// are reported with their default.
+func (m *Manager) EffectiveConfig() (*EffectiveConfig, error) {
+	ec := &EffectiveConfig{WorkDir: m.workDir, Layers: []ConfigLayer{}}
+	var layers []configLayer
+	if !m.noState {
+		lc, err := config.LoadLayered(m.workDir)
+		if err != nil {
+			return nil, fmt.Errorf("loading config layers: %w", err)
+		}
+		layers = layersInMergeOrder(lc)
+	}
+	for _, l := range layers {
+		ec.Layers = append(ec.Layers, ConfigLayer{Source: l.source, Path: l.path})
+	}
+
+	// set records key from the last layer whose get reports it as set.
+	set := func(key string, def interface{}, get func(*config.Config) (interface{}, bool)) {
+		s := EffectiveSetting{Key: key, Value: def, Source: string(config.SourceDefault)}
+		for _, l := range layers {
+			if v, ok := get(l.cfg); ok {
+				s.Value, s.Source, s.Origin = v, l.source, l.path
+			}
+		}
+		ec.Settings = append(ec.Settings, s)
+	}
+	contextKey := func(get func(*config.ContextConfig) (interface{}, bool)) func(*config.Config) (interface{}, bool) {
+		return func(c *config.Config) (interface{}, bool) {
+			if c.Context == nil {
+				return nil, false
+			}
+			return get(c.Context)
+		}
+	}
+	nonEmpty := func(s string) (interface{}, bool) { return s, s != "" }
+	nonEmptyList := func(l []string) (interface{}, bool) { return l, len(l) > 0 }
+
+	set("context.default_rules", "", contextKey(func(c *config.ContextConfig) (interface{}, bool) { return nonEmpty(c.DefaultRules) }))
+	set("context.default_rules_path", "", contextKey(func(c *config.ContextConfig) (interface{}, bool) { return nonEmpty(c.DefaultRulesPath) }))
+	set("context.included_workspaces", []string{}, contextKey(func(c *config.ContextConfig) (interface{}, bool) { return nonEmptyList(c.IncludedWorkspaces) }))
+	set("context.excluded_workspaces", []string{}, contextKey(func(c *config.ContextConfig) (interface{}, bool) { return nonEmptyList(c.ExcludedWorkspaces) }))
+	set("context.allowed_paths", []string{}, contextKey(func(c *config.ContextConfig) (interface{}, bool) { return nonEmptyList(c.AllowedPaths) }))
+
+	// cx extension keys, by their YAML names
+	cxKeys := []struct {
+		name string
+		def  interface{}
+	}{
+		{"metrics", false},
+		{"token_budget", 0},
+		{"checksums", false},
+		{"access_tracking", false},
+		{"languages", map[string]string{}},
+		{"notify", map[string]interface{}{}},
+		{"token_ratios", map[string]float64{}},
+		{"tokenizer_command", ""},
+		{"sections", map[string]interface{}{}},
+		{"token_thresholds", map[string]interface{}{}},
+		{"notes_in_context", false},
+		{"order", OrderAlpha},
+		{"concurrency", map[string]interface{}{}},
+		{"binary", map[string]interface{}{}},
+		{"output_format", FormatXML},
+		{"provenance", false},
+	}
+	for _, k := range cxKeys {
+		name := k.name
+		set("cx."+name, k.def, func(c *config.Config) (interface{}, bool) {
+			var ext map[string]interface{}
+			if err := c.UnmarshalExtension("cx", &ext); err != nil || ext == nil {
+				return nil, false
+			}
+			v, ok := ext[name]
+			return v, ok
+		})
+	}
+	boolEnv := func(name string) func() (interface{}, bool) {
+		return func() (interface{}, bool) { return envBool(name) }
+	}
+	for _, env := range []struct {
+		key, name string
+		value     func() (interface{}, bool)
+	}{
+		{"cx.metrics", metricsEnvVar, boolEnv(metricsEnvVar)},
+		{"cx.access_tracking", accessEnvVar, boolEnv(accessEnvVar)},
+		{"cx.checksums", ChecksumsEnvVar, boolEnv(ChecksumsEnvVar)},
+		{"cx.token_budget", BudgetEnvVar, func() (interface{}, bool) { return envBudget() }},
+	} {
+		value, ok := env.value()
+		if !ok {
+			continue
+		}
+		for i := range ec.Settings {
+			if ec.Settings[i].Key == env.key {
+				ec.Settings[i] = EffectiveSetting{Key: env.key, Value: value, Source: SourceEnv, Origin: env.name}
+			}
+		}
+	}
+	// Overrides with no config equivalent are reported only when set.
+	if !m.noState {
+		for _, env := range []struct{ key, name string }{
+			{"rules.file", RulesFileEnvVar},
+			{"rules.profile", ProfileEnvVar},
+		} {
+			if v := os.Getenv(env.name); v != "" {
+				ec.Settings = append(ec.Settings, EffectiveSetting{Key: env.key, Value: v, Source: SourceEnv, Origin: env.name})
+			}
+		}
+	}
+	if v, _ := envBool(NoCacheEnvVar); v {
+		ec.Settings = append(ec.Settings, EffectiveSetting{Key: "cache.disabled", Value: true, Source: SourceEnv, Origin: NoCacheEnvVar})
+	}
+
+	// State and the values derived from everything above
+	if !m.noState {
+		if source, _ := state.GetString(m.workDir, StateSourceKey); source != "" {
+			ec.Settings = append(ec.Settings, EffectiveSetting{Key: StateSourceKey, Value: source, Source: SourceState})
+		}
+	}
+	if _, rulesPath, err := m.LoadRulesContent(); err == nil && rulesPath != "" {
+		ec.Settings = append(ec.Settings, EffectiveSetting{Key: "rules.active_file", Value: rulesPath, Source: SourceDerived})
+	}
+	if roots, err := m.GetAllowedRoots(); err == nil {
+		sort.Strings(roots)
+		ec.Settings = append(ec.Settings, EffectiveSetting{Key: "workspaces.allowed_roots", Value: roots, Source: SourceDerived})
+	}
+	if err := m.DiscoveryDegraded(); err != nil {
+		ec.Settings = append(ec.Settings, EffectiveSetting{Key: "workspaces.degraded", Value: err.Error(), Source: SourceDerived})
+	}
+	return ec, nil
+}
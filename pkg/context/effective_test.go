@@ -0,0 +1,44 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(metricsEnvVar, "1")
+	t.Setenv(accessEnvVar, "")
+
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), `version: 1.0
+context:
+  excluded_workspaces: [legacy]
+cx:
+  token_budget: 50000
+  metrics: false
+`)
+
+	ec, err := NewManager(dir).EffectiveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := make(map[string]EffectiveSetting)
+	for _, s := range ec.Settings {
+		settings[s.Key] = s
+	}
+
+	project := filepath.Join(dir, "grove.yml")
+	if s := settings["context.excluded_workspaces"]; s.Source != "project" || s.Origin != project {
+		t.Errorf("excluded_workspaces = %+v, want it from the project file", s)
+	}
+	if s := settings["cx.token_budget"]; s.Source != "project" {
+		t.Errorf("token_budget = %+v, want it from the project file", s)
+	}
+	if s := settings["cx.metrics"]; s.Source != SourceEnv || s.Value != true || s.Origin != metricsEnvVar {
+		t.Errorf("metrics = %+v, want the CX_METRICS override", s)
+	}
+	if s := settings["cx.checksums"]; s.Source != "default" || s.Value != false {
+		t.Errorf("checksums = %+v, want the default", s)
+	}
+}
@@ -0,0 +1,120 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+)
+
+// Environment overrides. They are layered over grove.yml and the active rule
+// set in state, so CI jobs and scripts can adjust a run without editing
+// either. CX_METRICS and CX_ACCESS_TRACKING are declared beside the features
+// they control (metrics.go, access.go).
+const (
+	// RulesFileEnvVar names a rules file to use instead of the active one.
+	// Relative paths are resolved against the working directory.
+	RulesFileEnvVar = "CX_RULES_FILE"
+	// ProfileEnvVar names a rule set (as for `cx rules set`) to use
+	// instead of the active one. CX_RULES_FILE wins when both are set.
+	ProfileEnvVar = "CX_PROFILE"
+	// BudgetEnvVar overrides cx.token_budget; 0 disables the budget.
+	BudgetEnvVar = "CX_BUDGET"
+	// ChecksumsEnvVar overrides cx.checksums.
+	ChecksumsEnvVar = "CX_CHECKSUMS"
+	// NoCacheEnvVar disables the on-disk gitignore, worktree stats and
+	// walk caches and the in-process rules expansion memo and walk index.
+	NoCacheEnvVar = "CX_NO_CACHE"
+	// ArtifactNameEnvVar overrides cx.artifact_name.
+	ArtifactNameEnvVar = "CX_ARTIFACT_NAME"
+	// StandaloneEnvVar runs cx without grove config or state; see
+	// standalone.go.
+	StandaloneEnvVar = "CX_STANDALONE"
+)
+
+// envBool parses a boolean environment variable. ok is false when the
+// variable is unset or not one of 1, true, 0 or false.
+func envBool(name string) (value, ok bool) {
+	switch os.Getenv(name) {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// envBudget parses CX_BUDGET. ok is false when it is unset or invalid.
+func envBudget() (int, bool) {
+	s := os.Getenv(BudgetEnvVar)
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		Warnf("ignoring %s=%q: expected a non-negative token count", BudgetEnvVar, s)
+		return 0, false
+	}
+	return n, true
+}
+
+// applyEnvOverrides layers the cx environment overrides over cfg.
+func applyEnvOverrides(cfg *CxConfig) {
+	if v, ok := envBool(metricsEnvVar); ok {
+		cfg.Metrics = v
+	}
+	if v, ok := envBool(accessEnvVar); ok {
+		cfg.AccessTracking = v
+	}
+	if v, ok := envBool(ChecksumsEnvVar); ok {
+		cfg.Checksums = v
+	}
+	if n, ok := envBudget(); ok {
+		cfg.TokenBudget = n
+	}
+	if name := os.Getenv(ArtifactNameEnvVar); name != "" {
+		cfg.ArtifactName = name
+	}
+}
+
+// noCacheDefault disables the caches process-wide; see SetNoCache.
+var noCacheDefault atomic.Bool
+
+// SetNoCache sets the process-wide default for CX_NO_CACHE. The CLI sets it
+// from --no-cache.
+func SetNoCache(noCache bool) {
+	noCacheDefault.Store(noCache)
+}
+
+// cachesDisabled reports whether CX_NO_CACHE is set, or --no-cache when it
+// is not.
+func cachesDisabled() bool {
+	if v, ok := envBool(NoCacheEnvVar); ok {
+		return v
+	}
+	return noCacheDefault.Load()
+}
+
+// envRulesFile returns the rules file CX_RULES_FILE or CX_PROFILE selects,
+// or "" when neither is set. Managers with an explicit override, supplied
+// rules or WithNoState ignore both.
+func (m *Manager) envRulesFile() (string, error) {
+	if m.rulesFileOverride != "" || m.hasSuppliedRules || m.noState {
+		return "", nil
+	}
+	if path := os.Getenv(RulesFileEnvVar); path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		return path, nil
+	}
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		path, err := m.FindRulesetFile(m.workDir, name)
+		if err != nil {
+			return "", fmt.Errorf("%s=%s: %w", ProfileEnvVar, name, err)
+		}
+		return path, nil
+	}
+	return "", nil
+}
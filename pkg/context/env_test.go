@@ -0,0 +1,54 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCxConfigEnvOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), `version: 1.0
+cx:
+  token_budget: 50000
+  checksums: true
+`)
+
+	t.Setenv(BudgetEnvVar, "1200")
+	t.Setenv(ChecksumsEnvVar, "0")
+	cfg := LoadCxConfig(dir)
+	if cfg.TokenBudget != 1200 || cfg.Checksums {
+		t.Errorf("got budget %d checksums %v, want the environment to win", cfg.TokenBudget, cfg.Checksums)
+	}
+
+	t.Setenv(BudgetEnvVar, "lots")
+	t.Setenv(ChecksumsEnvVar, "")
+	cfg = LoadCxConfig(dir)
+	if cfg.TokenBudget != 50000 || !cfg.Checksums {
+		t.Errorf("got budget %d checksums %v, want invalid or empty overrides ignored", cfg.TokenBudget, cfg.Checksums)
+	}
+}
+
+func TestRulesFileEnvOverride(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, ActiveRulesFile), "*.go\n")
+	fsWriteString(t, filepath.Join(dir, "ci.rules"), "*.md\n")
+	t.Setenv(RulesFileEnvVar, "ci.rules")
+
+	content, path, err := NewManager(dir).LoadRulesContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "ci.rules" || string(content) != "*.md\n" {
+		t.Errorf("LoadRulesContent = %q from %s, want ci.rules", content, path)
+	}
+
+	// Library-mode managers do not read the environment.
+	_, path, err = NewManager(dir, WithNoState()).LoadRulesContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != filepath.Base(ActiveRulesFile) {
+		t.Errorf("WithNoState manager loaded %s, want %s", path, ActiveRulesFile)
+	}
+}
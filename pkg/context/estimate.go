@@ -42,9 +42,14 @@ var proseExtensions = map[string]bool{
 // EstimateTokens estimates the token count for a file from its size,
 // keyed by extension content class: code/structured data ≈ bytes/2,
 // prose (and extensionless files like Makefile, README) ≈ bytes/4,
-// unknown extensions ≈ bytes/3 as a middle ground.
+// unknown extensions ≈ bytes/3 as a middle ground. A per-extension ratio
+// from cx.token_ratios or from `cx stats --calibrate` takes precedence
+// (see tokenRatio).
 func EstimateTokens(path string, size int64) int {
 	ext := strings.ToLower(filepath.Ext(path))
+	if ratio, ok := tokenRatio(path, ext); ok {
+		return int(float64(size) / ratio)
+	}
 	switch {
 	case codeExtensions[ext]:
 		return int(size / codeTokenDivisor)
@@ -1,8 +1,15 @@
 package context
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+)
 
 func TestEstimateTokens(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	resetTokenRatios()
+	t.Cleanup(resetTokenRatios)
+
 	tests := []struct {
 		name string
 		path string
@@ -28,3 +35,45 @@ func TestEstimateTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimateTokensCalibration(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	resetTokenRatios()
+	t.Cleanup(resetTokenRatios)
+
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), `version: 1.0
+cx:
+  token_ratios:
+    ".MD": 5
+`)
+	fsWriteString(t, filepath.Join(dir, "a.json"), "0123456789")
+	fsWriteString(t, filepath.Join(dir, "b.json"), "0123456789")
+	fsWriteString(t, filepath.Join(dir, "notes.md"), "0123456789")
+
+	if got := EstimateTokens(filepath.Join(dir, "notes.md"), 1000); got != 200 {
+		t.Errorf("configured .md ratio: got %d, want 200", got)
+	}
+	if got := EstimateTokens(filepath.Join(dir, "a.json"), 1000); got != 500 {
+		t.Errorf("uncalibrated .json: got %d, want 500", got)
+	}
+
+	// A "tokenizer" counting one token per 4 bytes teaches .json a ratio of 4.
+	mgr := NewManager(dir)
+	cal, err := mgr.CalibrateTokens([]string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")}, "wc -c | awk '{print int($1/4)}'", 1)
+	if err != nil {
+		t.Fatalf("CalibrateTokens: %v", err)
+	}
+	if c := cal.Extensions[".json"]; c.Files != 1 || c.Tokens != 2 || c.BytesPerToken != 5 {
+		t.Errorf("calibration = %+v, want 1 file, 2 tokens, 5 bytes/token", c)
+	}
+	if got := EstimateTokens(filepath.Join(dir, "a.json"), 1000); got != 200 {
+		t.Errorf("calibrated .json: got %d, want 200", got)
+	}
+	if got := EstimateTokens(filepath.Join(dir, "main.go"), 1000); got != 500 {
+		t.Errorf("uncalibrated .go: got %d, want 500", got)
+	}
+	if _, err := mgr.CalibrateTokens([]string{filepath.Join(dir, "a.json")}, "echo nope", 1); err == nil {
+		t.Error("expected an error for non-numeric tokenizer output")
+	}
+}
@@ -0,0 +1,144 @@
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxRulesImportDepth bounds how deeply rules files may import one another
+// (@default:, @include:, ruleset imports). Cycles are cut separately; this
+// catches chains that are merely pathological.
+const maxRulesImportDepth = 32
+
+// expandMemoTTL bounds how long a memoized expansion is reused. A single cx
+// command resolves hot and cold context within it; long-running processes
+// (cx watch, cx serve) re-expand after it lapses, which refreshes inputs the
+// dependency hashes cannot see (grove config, aliases, generated @git:
+// files).
+const expandMemoTTL = 10 * time.Second
+
+// expansionRun carries the state of one top-level rules expansion through
+// expandAllRules and the import, include and concept resolvers it calls.
+type expansionRun struct {
+	visited    map[string]bool // rules files being expanded (the import stack) and concepts already resolved
+	depth      int
+	tainted    int                 // bumped when a result depends on more than its rules files; such results are not memoized
+	deps       []map[string]string // per open expansion: rules file -> content hash
+	allowPaths []map[string][]byte // per open expansion: rules file with @allow-path lines -> content
+	grants     map[string]string   // trusted @allow-path root -> requesting rules file, for the files expanded so far
+}
+
+func newExpansionRun() *expansionRun {
+	return &expansionRun{visited: make(map[string]bool), grants: make(map[string]string)}
+}
+
+// recordDeps adds rules files to every open expansion's dependency set.
+func (r *expansionRun) recordDeps(deps map[string]string) {
+	for _, set := range r.deps {
+		for path, hash := range deps {
+			set[path] = hash
+		}
+	}
+}
+
+// recordAllowPaths adds a rules file with @allow-path lines to every open
+// expansion, so a memoized expansion can grant its roots again.
+func (r *expansionRun) recordAllowPaths(absRulesPath string, content []byte) {
+	for _, set := range r.allowPaths {
+		set[absRulesPath] = content
+	}
+}
+
+// volatileRulePrefixes are directives whose expansion runs commands or git
+// and so can change while the rules files do not.
+var volatileRulePrefixes = []string{"@changed:", "@cmd:", "@diff:", "@git:", "@tasks", "@tree-only:", "@pkg:", "@fixtures:"}
+
+// hasVolatileRules reports whether rules content uses any volatile
+// directive; expansions of such files are never memoized.
+func hasVolatileRules(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range volatileRulePrefixes {
+			if strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandResult is the output of one expandAllRules call.
+type expandResult struct {
+	hot, cold  []RuleInfo
+	view, tree []string
+}
+
+// clone returns a deep enough copy for callers, which rewrite patterns,
+// paths and attribution in place.
+func (r expandResult) clone() expandResult {
+	return expandResult{
+		hot:  append([]RuleInfo(nil), r.hot...),
+		cold: append([]RuleInfo(nil), r.cold...),
+		view: append([]string(nil), r.view...),
+		tree: append([]string(nil), r.tree...),
+	}
+}
+
+type expandMemoEntry struct {
+	result     expandResult
+	deps       map[string]string // every rules file read, with its content hash ("" when missing)
+	allowPaths map[string][]byte // the rules files read that have @allow-path lines, with their content
+	created    time.Time
+}
+
+// expandMemoKey identifies an expansion. The import line matters because it
+// decides attribution and preset re-rooting.
+func (m *Manager) expandMemoKey(absRulesPath string, importLineNum int) string {
+	return fmt.Sprintf("%s\x00%d\x00%s", absRulesPath, importLineNum, m.aliasWorkDir)
+}
+
+func rulesContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// lookupExpandMemo returns a memoized expansion whose rules files are all
+// unchanged since it was computed. CX_NO_CACHE makes every lookup a miss.
+func (m *Manager) lookupExpandMemo(key string) (expandMemoEntry, bool) {
+	if cachesDisabled() {
+		return expandMemoEntry{}, false
+	}
+	m.expandMemoMu.Lock()
+	entry, ok := m.expandMemo[key]
+	m.expandMemoMu.Unlock()
+	if !ok || time.Since(entry.created) > expandMemoTTL {
+		return expandMemoEntry{}, false
+	}
+	for path, want := range entry.deps {
+		got := ""
+		content, err := m.readRulesFile(path)
+		if err == nil {
+			got = rulesContentHash(content)
+		} else if !os.IsNotExist(err) {
+			return expandMemoEntry{}, false
+		}
+		if got != want {
+			return expandMemoEntry{}, false
+		}
+	}
+	entry.result = entry.result.clone()
+	return entry, true
+}
+
+func (m *Manager) storeExpandMemo(key string, result expandResult, deps map[string]string, allowPaths map[string][]byte) {
+	m.expandMemoMu.Lock()
+	defer m.expandMemoMu.Unlock()
+	if m.expandMemo == nil {
+		m.expandMemo = make(map[string]expandMemoEntry)
+	}
+	m.expandMemo[key] = expandMemoEntry{result: result.clone(), deps: deps, allowPaths: allowPaths, created: time.Now()}
+}
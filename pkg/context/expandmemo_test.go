@@ -0,0 +1,74 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandAllRulesMemoizesSharedImports(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("shared.rules", "shared/*.go\n")
+	write("a.rules", "@include: shared.rules\na/*.go\n")
+	write("b.rules", "@include: shared.rules\nb/*.go\n")
+	root := write("rules", "@include: a.rules\n@include: b.rules\n")
+
+	m := NewManager(dir, WithNoState())
+	patterns := func() map[string]int {
+		hot, _, _, _, err := m.expandAllRules(root, newExpansionRun(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]int)
+		for _, r := range hot {
+			got[r.Pattern]++
+		}
+		return got
+	}
+
+	got := patterns()
+	if got["shared/*.go"] != 2 || got["a/*.go"] != 1 || got["b/*.go"] != 1 {
+		t.Fatalf("a ruleset imported from two parents should expand under both, got %v", got)
+	}
+	if _, ok := m.expandMemo[m.expandMemoKey(filepath.Join(dir, "shared.rules"), 1)]; !ok {
+		t.Error("shared import should be memoized")
+	}
+
+	// Editing a nested file invalidates every expansion that read it.
+	write("shared.rules", "lib/*.go\n")
+	got = patterns()
+	if got["lib/*.go"] != 2 || got["shared/*.go"] != 0 {
+		t.Errorf("memo should be invalidated by a changed import, got %v", got)
+	}
+}
+
+func TestExpandAllRulesBoundsDepth(t *testing.T) {
+	dir := t.TempDir()
+	const chain = maxRulesImportDepth + 8
+	for i := 0; i < chain; i++ {
+		content := fmt.Sprintf("p%d.go\n", i)
+		if i+1 < chain {
+			content += fmt.Sprintf("@include: f%d.rules\n", i+1)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d.rules", i)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager(dir, WithNoState())
+	hot, _, _, _, err := m.expandAllRules(filepath.Join(dir, "f0.rules"), newExpansionRun(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hot) != maxRulesImportDepth {
+		t.Errorf("expected expansion to stop after %d levels, got %d rules", maxRulesImportDepth, len(hot))
+	}
+}
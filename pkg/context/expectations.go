@@ -0,0 +1,217 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExpectationsDir holds committed golden files recording what each rule set
+// resolved to, relative to the project root.
+var ExpectationsDir = filepath.Join(RulesDir, "expectations")
+
+// ActiveExpectation is the reserved name under which the active rules file
+// (rather than a named rule set) is frozen.
+const ActiveExpectation = "_active"
+
+// Expectation is the recorded resolution of one rule set. Paths are relative
+// to the project root and sorted, so fixtures diff cleanly in review.
+type Expectation struct {
+	Ruleset string   `json:"ruleset"`
+	Hot     []string `json:"hot"`
+	Cold    []string `json:"cold"`
+}
+
+// ExpectationDrift describes how a rule set's current resolution differs
+// from its fixture.
+type ExpectationDrift struct {
+	Ruleset     string   `json:"ruleset"`
+	Missing     bool     `json:"missing,omitempty"` // no fixture recorded
+	AddedHot    []string `json:"added_hot,omitempty"`
+	RemovedHot  []string `json:"removed_hot,omitempty"`
+	AddedCold   []string `json:"added_cold,omitempty"`
+	RemovedCold []string `json:"removed_cold,omitempty"`
+}
+
+// Drifted reports whether the resolution no longer matches the fixture.
+func (d ExpectationDrift) Drifted() bool {
+	return d.Missing || len(d.AddedHot)+len(d.RemovedHot)+len(d.AddedCold)+len(d.RemovedCold) > 0
+}
+
+// ListRulesetNames returns the named rule sets available to the project,
+// from notebook presets and the legacy .cx/ and .cx.work/ directories.
+func (m *Manager) ListRulesetNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range m.rulesetDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), RulesExt) {
+				continue
+			}
+			name := strings.TrimSuffix(e.Name(), RulesExt)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rulesetDirs returns the directories that hold named rule sets, in lookup
+// order.
+func (m *Manager) rulesetDirs() []string {
+	var dirs []string
+	if node, err := m.projectNode(); err == nil {
+		if dir, err := m.locator.GetContextPresetsDir(node); err == nil {
+			dirs = append(dirs, dir)
+		}
+		if dir, err := m.locator.GetContextPresetsWorkDir(node); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, filepath.Join(m.workDir, RulesDir), filepath.Join(m.workDir, RulesWorkDir))
+	return dirs
+}
+
+// ResolveExpectation resolves a named rule set (or ActiveExpectation) to its
+// current Expectation.
+func (m *Manager) ResolveExpectation(name string) (*Expectation, error) {
+	var rulesPath string
+	if name == ActiveExpectation {
+		rulesPath = m.ResolveRulesPath()
+	} else {
+		path, err := m.FindRulesetFile(m.workDir, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not find rule set '%s': %w", name, err)
+		}
+		rulesPath = path
+	}
+	hot, cold, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving rule set '%s': %w", name, err)
+	}
+	return &Expectation{Ruleset: name, Hot: m.expectationPaths(hot), Cold: m.expectationPaths(cold)}, nil
+}
+
+func (m *Manager) expectationPaths(files []string) []string {
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		if filepath.IsAbs(f) {
+			if rel, err := filepath.Rel(m.workDir, f); err == nil {
+				f = rel
+			}
+		}
+		out = append(out, filepath.ToSlash(f))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (m *Manager) expectationPath(name string) string {
+	return filepath.Join(m.workDir, ExpectationsDir, name+".json")
+}
+
+// FreezeExpectations records the current resolution of each named rule set.
+// With no names, every named rule set and the active rules are frozen. It
+// returns the fixture paths written.
+func (m *Manager) FreezeExpectations(names []string) ([]string, error) {
+	if len(names) == 0 {
+		names = append(m.ListRulesetNames(), ActiveExpectation)
+	}
+	if err := os.MkdirAll(filepath.Join(m.workDir, ExpectationsDir), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create expectations directory: %w", err)
+	}
+	var written []string
+	for _, name := range names {
+		exp, err := m.ResolveExpectation(name)
+		if err != nil {
+			return written, err
+		}
+		data, err := json.MarshalIndent(exp, "", "  ")
+		if err != nil {
+			return written, err
+		}
+		path := m.expectationPath(name)
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// CheckExpectations compares the current resolution of each rule set with
+// its fixture. With no names, every recorded fixture is checked.
+func (m *Manager) CheckExpectations(names []string) ([]ExpectationDrift, error) {
+	if len(names) == 0 {
+		entries, err := os.ReadDir(filepath.Join(m.workDir, ExpectationsDir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("no expectations recorded in %s; run 'cx rules freeze-expectations' first", ExpectationsDir)
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+			}
+		}
+	}
+
+	var drifts []ExpectationDrift
+	for _, name := range names {
+		drift := ExpectationDrift{Ruleset: name}
+		data, err := os.ReadFile(m.expectationPath(name))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			drift.Missing = true
+			drifts = append(drifts, drift)
+			continue
+		}
+		var want Expectation
+		if err := json.Unmarshal(data, &want); err != nil {
+			return nil, fmt.Errorf("invalid expectation file for '%s': %w", name, err)
+		}
+		got, err := m.ResolveExpectation(name)
+		if err != nil {
+			return nil, err
+		}
+		drift.AddedHot, drift.RemovedHot = diffSorted(want.Hot, got.Hot)
+		drift.AddedCold, drift.RemovedCold = diffSorted(want.Cold, got.Cold)
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// diffSorted returns the entries only in got (added) and only in want
+// (removed).
+func diffSorted(want, got []string) (added, removed []string) {
+	inWant := make(map[string]bool, len(want))
+	for _, f := range want {
+		inWant[f] = true
+	}
+	inGot := make(map[string]bool, len(got))
+	for _, f := range got {
+		inGot[f] = true
+		if !inWant[f] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range want {
+		if !inGot[f] {
+			removed = append(removed, f)
+		}
+	}
+	return added, removed
+}
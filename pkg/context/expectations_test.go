@@ -0,0 +1,51 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFreezeAndCheckExpectations(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".cx/go.rules", "*.go\n")
+	write("a.go", "package a\n")
+	write("b.go", "package a\n")
+
+	m := NewManager(dir)
+	if _, err := m.FreezeExpectations([]string{"go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err := m.CheckExpectations(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 1 || drifts[0].Drifted() {
+		t.Fatalf("expected one clean rule set, got %+v", drifts)
+	}
+
+	write("c.go", "package a\n")
+	if err := os.Remove(filepath.Join(dir, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err = m.CheckExpectations([]string{"go", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := drifts[0]; len(got.AddedHot) != 1 || got.AddedHot[0] != "c.go" || len(got.RemovedHot) != 1 || got.RemovedHot[0] != "a.go" {
+		t.Errorf("unexpected drift for go: %+v", got)
+	}
+	if !drifts[1].Missing {
+		t.Errorf("expected missing fixture for 'missing', got %+v", drifts[1])
+	}
+}
@@ -0,0 +1,165 @@
+package context
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// The @fixtures: directive includes a directory of test fixtures or golden
+// files by reference: one generated markdown file lists every file's path,
+// size and first line, and the fixture bodies themselves are dropped from
+// the same tier, as if `!testdata/**` were written on the directive's line:
+//
+//	@fixtures: list testdata
+//	@fixtures: list internal/parser/golden
+
+// maxFixtureEntries caps a fixtures listing; the rest are only counted.
+const maxFixtureEntries = 1000
+
+// maxFixtureFirstLine is how much of a fixture's first line is shown.
+const maxFixtureFirstLine = 100
+
+// FixtureEntry is one file of a fixtures listing.
+type FixtureEntry struct {
+	Path      string // relative to the fixtures directory, slash-separated
+	Size      int64
+	FirstLine string // empty for binary or empty files
+	Binary    bool
+}
+
+// ListFixtures walks dir and describes every regular file in it, skipping
+// VCS and junk directories.
+func ListFixtures(dir string) ([]FixtureEntry, error) {
+	var entries []FixtureEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == GroveDir || isJunkDir(d.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		entry := FixtureEntry{Path: filepath.ToSlash(rel), Size: info.Size(), Binary: isBinaryFile(path)}
+		if !entry.Binary {
+			entry.FirstLine = fixtureFirstLine(path)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// fixtureFirstLine returns the first non-blank line of a text file,
+// shortened to maxFixtureFirstLine runes.
+func fixtureFirstLine(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxFixtureFirstLine {
+			line = string([]rune(line)[:maxFixtureFirstLine]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// FormatFixturesListing renders a fixtures listing as markdown.
+func FormatFixturesListing(name string, entries []FixtureEntry) string {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Fixtures in %s (%s)\n\n", name, treeDirSummary(len(entries), total))
+	sb.WriteString("File contents are omitted; only names, sizes and first lines are listed.\n\n")
+	for i, e := range entries {
+		if i == maxFixtureEntries {
+			fmt.Fprintf(&sb, "- … and %d more\n", len(entries)-maxFixtureEntries)
+			break
+		}
+		switch {
+		case e.Binary:
+			fmt.Fprintf(&sb, "- `%s` (%s, binary)\n", e.Path, FormatBytes(int(e.Size)))
+		case e.FirstLine == "":
+			fmt.Fprintf(&sb, "- `%s` (%s)\n", e.Path, FormatBytes(int(e.Size)))
+		default:
+			fmt.Fprintf(&sb, "- `%s` (%s): %s\n", e.Path, FormatBytes(int(e.Size)), e.FirstLine)
+		}
+	}
+	return sb.String()
+}
+
+// generateFixturesFile writes the @fixtures: listing for spec ("list"
+// followed by a directory, relative to the working directory or absolute)
+// to .grove/fixtures and returns its absolute path along with the exclusion
+// pattern that drops the fixture bodies.
+func (m *Manager) generateFixturesFile(spec string) (string, string, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 || fields[0] != "list" {
+		return "", "", fmt.Errorf("expected `@fixtures: list <dir>`, got %q", spec)
+	}
+	dir := expandHomeAndDot(strings.Trim(fields[1], `"`))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(m.workDir, dir)
+	}
+	dir = filepath.Clean(dir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	name := dir
+	if rel, err := filepath.Rel(m.workDir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		name = filepath.ToSlash(rel)
+	}
+	if name == "." {
+		return "", "", fmt.Errorf("the working directory itself cannot be listed as fixtures")
+	}
+	entries, err := ListFixtures(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list %s: %w", name, err)
+	}
+
+	fixturesDir := filepath.Join(m.workDir, GroveDir, "fixtures")
+	if err := os.MkdirAll(fixturesDir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	safeName := strings.Trim(strings.NewReplacer("/", "-", "\\", "-", "~", "home", "..", "up").Replace(filepath.ToSlash(name)), "-")
+	outPath := filepath.Join(fixturesDir, "fixtures-"+safeName+".md")
+	if err := os.WriteFile(outPath, []byte(FormatFixturesListing(name, entries)), 0o644); err != nil { //nolint:gosec // fixture listing, not sensitive
+		return "", "", fmt.Errorf("failed to write fixtures listing: %w", err)
+	}
+
+	exclude := name + "/**"
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return outPath, exclude, nil
+	}
+	return absPath, exclude, nil
+}
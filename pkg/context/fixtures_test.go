@@ -0,0 +1,52 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFixturesFile(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "testdata", "golden", "parse.json"), "\n{\"tokens\": 12}\n")
+	fsWriteString(t, filepath.Join(dir, "testdata", "input.txt"), strings.Repeat("x", 150)+"\nsecond line\n")
+	fsWriteString(t, filepath.Join(dir, "testdata", "empty.txt"), "")
+	m := &Manager{workDir: dir}
+
+	path, exclude, err := m.generateFixturesFile("list testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, GroveDir, "fixtures", "fixtures-testdata.md"); path != want {
+		t.Errorf("listing path = %s, want %s", path, want)
+	}
+	if exclude != "testdata/**" {
+		t.Errorf("exclude = %q, want testdata/**", exclude)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listing := string(content)
+	for _, want := range []string{
+		"# Fixtures in testdata (3 files,",
+		"- `empty.txt` (0 bytes)\n",
+		"- `golden/parse.json` (16 bytes): {\"tokens\": 12}\n",
+		"- `input.txt` (163 bytes): " + strings.Repeat("x", 100) + "…\n",
+	} {
+		if !strings.Contains(listing, want) {
+			t.Errorf("listing missing %q:\n%s", want, listing)
+		}
+	}
+	if strings.Contains(listing, "second line") {
+		t.Error("listing includes more than the first line")
+	}
+
+	for _, spec := range []string{"testdata", "list", "list .", "list missing"} {
+		if _, _, err := m.generateFixturesFile(spec); err == nil {
+			t.Errorf("generateFixturesFile(%q): expected an error", spec)
+		}
+	}
+}
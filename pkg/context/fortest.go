@@ -0,0 +1,235 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TestContext is the minimal context of one Go test: the test file and
+// every source file its run executed, taken from a coverage profile.
+type TestContext struct {
+	Test     string   `json:"test"`
+	Package  string   `json:"package"`   // the test's package directory
+	TestFile string   `json:"test_file"` // file declaring the test
+	Files    []string `json:"files"`     // executed source files, sorted
+	Fixtures string   `json:"fixtures,omitempty"`
+	Passed   bool     `json:"passed"`
+	Output   string   `json:"output,omitempty"` // go test output
+}
+
+// TraceGoTest runs the Go test name (a top-level test, optionally with a
+// /subtest path) once with coverage across its module and returns the files
+// it touched. pkgDir narrows the search for the test's declaration when
+// several packages declare one by that name. A failing test is not an
+// error: tracing a failure is the point. Paths are relative to the working
+// directory where possible.
+func (m *Manager) TraceGoTest(name, pkgDir string) (*TestContext, error) {
+	topLevel, _, _ := strings.Cut(name, "/")
+	searchDir := m.workDir
+	if pkgDir != "" {
+		searchDir = pkgDir
+		if !filepath.IsAbs(searchDir) {
+			searchDir = filepath.Join(m.workDir, searchDir)
+		}
+	}
+	decls, err := findGoTestDecls(searchDir, topLevel)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(decls) == 0:
+		return nil, fmt.Errorf("no Go test named %s under %s", topLevel, searchDir)
+	case len(decls) > 1:
+		var dirs []string
+		for _, d := range decls {
+			dirs = append(dirs, m.displayPath(filepath.Dir(d)))
+		}
+		return nil, fmt.Errorf("%s is declared in several packages (%s); choose one with --pkg", topLevel, strings.Join(dirs, ", "))
+	}
+	testFile := decls[0]
+	testPkg := filepath.Dir(testFile)
+
+	modRoot := findGoModRoot(testPkg)
+	if modRoot == "" {
+		return nil, fmt.Errorf("no go.mod above %s", testPkg)
+	}
+	mod := parseGoModManifest(modRoot)
+	if mod == nil || mod.Name == "" {
+		return nil, fmt.Errorf("cannot read the module path from %s", filepath.Join(modRoot, "go.mod"))
+	}
+
+	profile, err := os.CreateTemp("", "cx-for-test-*.cover")
+	if err != nil {
+		return nil, err
+	}
+	profilePath := profile.Name()
+	profile.Close()
+	defer os.Remove(profilePath)
+
+	rel, _ := filepath.Rel(modRoot, testPkg)
+	cmd := exec.CommandContext(m.Context(), "go", "test", "-count=1", "-run", goTestRunPattern(name),
+		"-coverpkg=./...", "-coverprofile="+profilePath, "./"+filepath.ToSlash(rel))
+	cmd.Dir = modRoot
+	output, runErr := cmd.CombinedOutput()
+
+	touched, err := parseCoverProfile(profilePath, mod.Name, modRoot)
+	if err != nil || (runErr != nil && len(touched) == 0) {
+		return nil, fmt.Errorf("go test did not produce coverage for %s: %v\n%s", name, runErr, output)
+	}
+
+	tc := &TestContext{
+		Test:     name,
+		Package:  m.displayPath(testPkg),
+		TestFile: m.displayPath(testFile),
+		Passed:   runErr == nil,
+		Output:   string(output),
+	}
+	for _, f := range touched {
+		tc.Files = append(tc.Files, m.displayPath(f))
+	}
+	sort.Strings(tc.Files)
+	if info, err := os.Stat(filepath.Join(testPkg, "testdata")); err == nil && info.IsDir() {
+		tc.Fixtures = m.displayPath(filepath.Join(testPkg, "testdata"))
+	}
+	return tc, nil
+}
+
+// Rules renders the test context as a rules file.
+func (tc *TestContext) Rules() string {
+	var sb strings.Builder
+	status := "passing"
+	if !tc.Passed {
+		status = "failing"
+	}
+	fmt.Fprintf(&sb, "# Minimal context for %s in %s (%s when traced), from cx for-test\n", tc.Test, tc.Package, status)
+	fmt.Fprintln(&sb, tc.TestFile)
+	for _, f := range tc.Files {
+		if f != tc.TestFile {
+			fmt.Fprintln(&sb, f)
+		}
+	}
+	if tc.Fixtures != "" {
+		fmt.Fprintf(&sb, "@fixtures: list %s\n", tc.Fixtures)
+	}
+	return sb.String()
+}
+
+// displayPath returns path relative to the working directory when it is
+// inside it, and unchanged otherwise.
+func (m *Manager) displayPath(path string) string {
+	if rel, err := filepath.Rel(m.workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// findGoTestDecls returns the _test.go files under dir that declare the
+// top-level test, benchmark, fuzz test or example name.
+func findGoTestDecls(dir, name string) ([]string, error) {
+	decl := regexp.MustCompile(`^func ` + regexp.QuoteMeta(name) + `\(`)
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			switch base := d.Name(); {
+			case path == dir:
+			case base == ".git" || base == GroveDir || base == ".grove-worktrees" || base == "vendor" || base == "testdata" || isJunkDir(base):
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if decl.Match(scanner.Bytes()) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// findGoModRoot returns the nearest directory at or above dir holding a
+// go.mod, or "".
+func findGoModRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// goTestRunPattern builds a -run pattern matching exactly name and, for a
+// subtest path, exactly each of its levels.
+func goTestRunPattern(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+// parseCoverProfile returns the files of module modPath (rooted at modRoot)
+// with at least one executed block in a Go coverage profile.
+func parseCoverProfile(path, modPath, modRoot string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	executed := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// import/path/file.go:12.34,15.2 3 1
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line)
+		if colon < 0 || len(fields) != 3 {
+			continue
+		}
+		if count, err := strconv.Atoi(fields[2]); err != nil || count == 0 {
+			continue
+		}
+		file := line[:colon]
+		rel, ok := strings.CutPrefix(file, modPath+"/")
+		if !ok {
+			continue
+		}
+		executed[filepath.Join(modRoot, filepath.FromSlash(rel))] = true
+	}
+	files := make([]string, 0, len(executed))
+	for f := range executed {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,73 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGoTestRunPattern(t *testing.T) {
+	for name, want := range map[string]string{
+		"TestParse":             "^TestParse$",
+		"TestParse/empty_input": "^TestParse$/^empty_input$",
+		"TestX/a.b":             `^TestX$/^a\.b$`,
+	} {
+		if got := goTestRunPattern(name); got != want {
+			t.Errorf("goTestRunPattern(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "cover.out")
+	fsWriteString(t, profile, `mode: set
+example.com/mod/pkg/a/a.go:3.14,5.2 1 1
+example.com/mod/pkg/a/a.go:7.14,9.2 1 0
+example.com/mod/pkg/b/b.go:3.14,5.2 2 0
+example.com/mod/main.go:5.13,7.2 1 1
+example.com/other/x.go:1.1,2.2 1 1
+`)
+	got, err := parseCoverProfile(profile, "example.com/mod", "/src/mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.FromSlash("/src/mod/main.go"), filepath.FromSlash("/src/mod/pkg/a/a.go")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCoverProfile = %v, want %v", got, want)
+	}
+}
+
+func TestFindGoTestDecls(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "a", "a_test.go"), "package a\n\nfunc TestParse(t *testing.T) {}\nfunc TestParseAll(t *testing.T) {}\n")
+	fsWriteString(t, filepath.Join(dir, "b", "b_test.go"), "package b\n\nfunc TestParseAll(t *testing.T) {}\n")
+	fsWriteString(t, filepath.Join(dir, "b", "testdata", "x_test.go"), "package x\n\nfunc TestParse(t *testing.T) {}\n")
+	fsWriteString(t, filepath.Join(dir, "c", "c.go"), "package c\n\nfunc TestParse(t *testing.T) {}\n")
+
+	got, err := findGoTestDecls(dir, "TestParse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "a", "a_test.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TestParse declared in %v, want %v", got, want)
+	}
+	if got, _ := findGoTestDecls(dir, "TestParseAll"); len(got) != 2 {
+		t.Errorf("TestParseAll declared in %v, want both packages", got)
+	}
+}
+
+func TestTestContextRules(t *testing.T) {
+	tc := &TestContext{
+		Test:     "TestParse/empty",
+		Package:  "pkg/a",
+		TestFile: "pkg/a/a_test.go",
+		Files:    []string{"pkg/a/a.go", "pkg/a/a_test.go", "pkg/b/b.go"},
+		Fixtures: "pkg/a/testdata",
+	}
+	want := "# Minimal context for TestParse/empty in pkg/a (failing when traced), from cx for-test\n" +
+		"pkg/a/a_test.go\npkg/a/a.go\npkg/b/b.go\n@fixtures: list pkg/a/testdata\n"
+	if got := tc.Rules(); got != want {
+		t.Errorf("Rules() =\n%s\nwant\n%s", got, want)
+	}
+}
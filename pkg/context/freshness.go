@@ -0,0 +1,155 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxAgeDirective is a single `@max-age: <duration> <pattern>` line. Every
+// included file matching pattern must have been modified within duration;
+// durations accept d (days) and w (weeks) on top of Go's units:
+//
+//	@max-age: 1d docs/api/generated/**
+type maxAgeDirective struct {
+	MaxAge  time.Duration
+	Raw     string // duration as written
+	Pattern string
+	LineNum int
+	Err     error // set when the line cannot be parsed
+}
+
+// StaleFile is an included file older than the `@max-age:` that covers it,
+// or a `@max-age:` line that could not be parsed (Path empty).
+type StaleFile struct {
+	Path    string        `json:"path,omitempty"`
+	LineNum int           `json:"line"`
+	Pattern string        `json:"pattern,omitempty"`
+	MaxAge  string        `json:"maxAge"`
+	Age     time.Duration `json:"age,omitempty"`
+	Reason  string        `json:"reason,omitempty"`
+}
+
+// String renders the issue the way validate and generate report it.
+func (s StaleFile) String() string {
+	if s.Path == "" {
+		return fmt.Sprintf("line %d: @max-age: %s — %s", s.LineNum, s.MaxAge, s.Reason)
+	}
+	return fmt.Sprintf("%s is %s old (line %d: @max-age: %s %s)", s.Path, formatAge(s.Age), s.LineNum, s.MaxAge, s.Pattern)
+}
+
+// parseMaxAgeDirectives collects the `@max-age:` lines of a rules file.
+func parseMaxAgeDirectives(content []byte) []maxAgeDirective {
+	var dirs []maxAgeDirective
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text())))
+		if !strings.HasPrefix(line, "@max-age:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "@max-age:"))
+		d := maxAgeDirective{LineNum: lineNum}
+		if len(fields) != 2 {
+			d.Raw = strings.Join(fields, " ")
+			d.Err = fmt.Errorf("expected a duration and a pattern")
+		} else {
+			d.Raw = fields[0]
+			d.Pattern = expandHomeAndDot(strings.Trim(fields[1], `"`))
+			if strings.HasSuffix(d.Pattern, "/") {
+				d.Pattern += "**"
+			}
+			d.MaxAge, d.Err = parseExtendedDuration(d.Raw)
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// HasMaxAgeDirectives reports whether rulesContent declares any `@max-age:`.
+func HasMaxAgeDirectives(rulesContent []byte) bool {
+	return len(parseMaxAgeDirectives(rulesContent)) > 0
+}
+
+// StaleFiles checks files against the `@max-age:` directives of
+// rulesContent and returns every included file modified longer ago than the
+// directive matching it allows (the first matching directive wins), plus any
+// malformed directives. Files that cannot be stat'ed are skipped.
+func (m *Manager) StaleFiles(rulesContent []byte, files []string) []StaleFile {
+	dirs := parseMaxAgeDirectives(rulesContent)
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	var issues []StaleFile
+	for _, d := range dirs {
+		if d.Err != nil {
+			issues = append(issues, StaleFile{LineNum: d.LineNum, MaxAge: d.Raw, Reason: d.Err.Error()})
+		}
+	}
+
+	base := m.rulesBaseDir
+	if base == "" {
+		base = m.workDir
+	}
+	now := time.Now()
+	for _, f := range files {
+		abs := m.requirePathKey(f)
+		rel := abs
+		if r, err := filepath.Rel(base, abs); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+		}
+		for _, d := range dirs {
+			if d.Err != nil {
+				continue
+			}
+			target := rel
+			if filepath.IsAbs(d.Pattern) {
+				target = abs
+			}
+			if !matchRulePattern(d.Pattern, target) {
+				continue
+			}
+			if info, err := os.Stat(abs); err == nil {
+				if age := now.Sub(info.ModTime()); age > d.MaxAge {
+					issues = append(issues, StaleFile{Path: rel, LineNum: d.LineNum, Pattern: d.Pattern, MaxAge: d.Raw, Age: age})
+				}
+			}
+			break
+		}
+	}
+	return issues
+}
+
+// warnStaleFiles prints a warning for each file that violates a
+// `@max-age:` directive. Generation still proceeds; `cx validate` fails.
+func (m *Manager) warnStaleFiles(rulesContent []byte, files []string) {
+	issues := m.StaleFiles(rulesContent, files)
+	if len(issues) == 0 {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d file(s) older than their @max-age: — rerun the generators that produce them:", len(issues))
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "\n  %s", issue)
+	}
+	Warnf("%s", b.String())
+}
+
+// formatAge renders a file age at a human granularity ("3d", "5h", "12m").
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
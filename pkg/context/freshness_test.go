@@ -0,0 +1,52 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, age time.Duration) {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("docs/api/old.md", 48*time.Hour)
+	write("docs/api/fresh.md", time.Hour)
+	write("docs/guide.md", 30*24*time.Hour)
+
+	rules := []byte(`@max-age: 1d docs/api/ # regenerated by make docs
+@max-age: 2w docs/**
+@max-age: soon docs/guide.md
+docs/**
+`)
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	issues := m.StaleFiles(rules, []string{"docs/api/old.md", "docs/api/fresh.md", filepath.Join(dir, "docs/guide.md")})
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3: %+v", len(issues), issues)
+	}
+	if issues[0].Path != "" || issues[0].LineNum != 3 {
+		t.Errorf("expected the malformed duration to be reported first, got %+v", issues[0])
+	}
+	if issues[1].Path != "docs/api/old.md" || issues[1].LineNum != 1 || issues[1].Pattern != "docs/api/**" {
+		t.Errorf("unexpected stale api doc: %+v", issues[1])
+	}
+	if issues[2].Path != "docs/guide.md" || issues[2].LineNum != 2 {
+		t.Errorf("the first matching directive should apply, got %+v", issues[2])
+	}
+
+	if HasMaxAgeDirectives([]byte("docs/**\n")) {
+		t.Error("rules without @max-age: should report none")
+	}
+}
@@ -0,0 +1,130 @@
+package context
+
+import (
+	"bufio"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxFrontmatterLines bounds how far a markdown file is read looking for the
+// closing '---' of its frontmatter.
+const maxFrontmatterLines = 200
+
+// FileDirectives are the cx instructions an included markdown file carries in
+// its own YAML frontmatter, letting a doc's owner influence how it is
+// included without editing every consumer's rules file:
+//
+//	---
+//	title: Architecture
+//	cx: {tier: hot, priority: 5}
+//	---
+//
+// Tier moves the file into the hot or cold section when a rule already
+// includes it; it never pulls in a file no rule matched. Files with a higher
+// Priority are written first within their section; ties keep rule order.
+type FileDirectives struct {
+	Tier     string `yaml:"tier,omitempty" json:"tier,omitempty"`
+	Priority int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// isFrontmatterDoc reports whether path is a document that may carry
+// FileDirectives.
+func isFrontmatterDoc(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mdx":
+		return true
+	}
+	return false
+}
+
+// ReadFileDirectives returns the cx frontmatter of the markdown file at path,
+// and whether it had any.
+func ReadFileDirectives(path string) (FileDirectives, bool) {
+	var fd FileDirectives
+	if !isFrontmatterDoc(path) {
+		return fd, false
+	}
+	f, err := openRegular(path)
+	if err != nil {
+		return fd, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return fd, false
+	}
+	var body strings.Builder
+	closed := false
+	for i := 0; i < maxFrontmatterLines && scanner.Scan(); i++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "---" {
+			closed = true
+			break
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if !closed {
+		return fd, false
+	}
+
+	var front struct {
+		Cx *FileDirectives `yaml:"cx"`
+	}
+	if err := yaml.Unmarshal([]byte(body.String()), &front); err != nil || front.Cx == nil {
+		return fd, false
+	}
+	fd = *front.Cx
+	fd.Tier = strings.ToLower(strings.TrimSpace(fd.Tier))
+	return fd, true
+}
+
+// applyFileDirectives honors the frontmatter of included markdown files: a
+// doc asking for tier hot or cold is moved to that section, and each section
+// is then ordered by descending priority (stable, so rule order breaks ties).
+func (m *Manager) applyFileDirectives(hot, cold []string) ([]string, []string) {
+	directives := make(map[string]FileDirectives)
+	for _, list := range [][]string{hot, cold} {
+		for _, f := range list {
+			path := f
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(m.workDir, path)
+			}
+			if fd, ok := ReadFileDirectives(path); ok {
+				directives[f] = fd
+			}
+		}
+	}
+	if len(directives) == 0 {
+		return hot, cold
+	}
+
+	var newHot, newCold []string
+	for _, f := range hot {
+		if directives[f].Tier == "cold" {
+			newCold = append(newCold, f)
+		} else {
+			newHot = append(newHot, f)
+		}
+	}
+	for _, f := range cold {
+		if directives[f].Tier == "hot" {
+			newHot = append(newHot, f)
+		} else {
+			newCold = append(newCold, f)
+		}
+	}
+
+	byPriority := func(files []string) {
+		sort.SliceStable(files, func(i, j int) bool {
+			return directives[files[i]].Priority > directives[files[j]].Priority
+		})
+	}
+	byPriority(newHot)
+	byPriority(newCold)
+	return newHot, newCold
+}
@@ -0,0 +1,65 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadFileDirectives(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	fd, ok := ReadFileDirectives(write("a.md", "---\ntitle: A\ncx: {tier: Cold, priority: 5}\n---\n# A\n"))
+	if !ok || fd.Tier != "cold" || fd.Priority != 5 {
+		t.Errorf("got %+v, %v", fd, ok)
+	}
+	if _, ok := ReadFileDirectives(write("b.md", "---\ntitle: B\n---\n")); ok {
+		t.Error("frontmatter without a cx key should carry no directives")
+	}
+	if _, ok := ReadFileDirectives(write("c.md", "# no frontmatter\ncx: {tier: hot}\n")); ok {
+		t.Error("cx key outside frontmatter must be ignored")
+	}
+	if _, ok := ReadFileDirectives(write("d.go", "---\ncx: {tier: hot}\n---\n")); ok {
+		t.Error("only markdown files carry directives")
+	}
+}
+
+func TestFileDirectivesTierAndPriority(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("docs/a.md", "# plain\n")
+	write("docs/b.md", "---\ncx:\n  priority: 5\n---\n# first\n")
+	write("docs/c.md", "---\ncx: {tier: cold}\n---\n# demoted\n")
+	write("ref/d.md", "---\ncx: {tier: hot, priority: 1}\n---\n# promoted\n")
+	write("ref/e.md", "# stays cold\n")
+	write("rules", "docs/*.md\n---\nref/*.md\n")
+
+	m := NewManager(dir)
+	hot, cold, err := m.ResolveFilesFromCustomRulesFile(filepath.Join(dir, "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Resolved paths are relative to the rules base directory.
+	if want := []string{"docs/b.md", "ref/d.md", "docs/a.md"}; !reflect.DeepEqual(hot, want) {
+		t.Errorf("hot = %v, want %v", hot, want)
+	}
+	if want := []string{"docs/c.md", "ref/e.md"}; !reflect.DeepEqual(cold, want) {
+		t.Errorf("cold = %v, want %v", cold, want)
+	}
+}
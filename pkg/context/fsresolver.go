@@ -0,0 +1,432 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver resolves rules content to files. Unlike Manager, which reads the
+// real filesystem, git, grove state and aliases, a Resolver created with
+// NewResolver works only against the fs.FS and providers it is given, so it
+// can be embedded in other programs and driven by in-memory filesystems.
+type Resolver interface {
+	Resolve(rules []byte) (*Resolution, error)
+}
+
+// Resolution is the outcome of resolving a rules file with a Resolver.
+// Paths are slash-separated and relative to the root of the fs.FS.
+type Resolution struct {
+	Hot         []string
+	Cold        []string
+	Attribution AttributionResult
+	Exclusions  ExclusionResult
+	Filtered    FilteredResult
+	ExcludedBy  ExcludedByResult
+	// Errors lists parse problems and lines the resolver could not honor
+	// (for example @include:, which needs a Manager).
+	Errors []ParseError
+}
+
+// GitProvider supplies the git state @changed: directives query.
+type GitProvider interface {
+	// ChangedFiles returns the slash-separated paths, relative to the root
+	// of the fs.FS, that differ from ref.
+	ChangedFiles(ref string) ([]string, error)
+}
+
+// IgnoreProvider reports whether a slash-separated path, relative to the
+// root of the fs.FS, is ignored (the role .gitignore plays for Manager).
+type IgnoreProvider interface {
+	IsIgnored(path string) bool
+}
+
+// IgnoreFunc adapts a function to IgnoreProvider.
+type IgnoreFunc func(path string) bool
+
+func (f IgnoreFunc) IsIgnored(path string) bool { return f(path) }
+
+// ResolverOption configures a Resolver created by NewResolver.
+type ResolverOption func(*fsResolver)
+
+// WithGitProvider enables @changed: directives.
+func WithGitProvider(g GitProvider) ResolverOption {
+	return func(r *fsResolver) { r.git = g }
+}
+
+// WithIgnoreProvider hides ignored paths from resolution.
+func WithIgnoreProvider(p IgnoreProvider) ResolverOption {
+	return func(r *fsResolver) { r.ignore = p }
+}
+
+// WithCommandRunner enables @cmd: rules; run returns the files a command
+// lists.
+func WithCommandRunner(run func(cmd string) ([]string, error)) ResolverOption {
+	return func(r *fsResolver) { r.exec = run }
+}
+
+// WithAliasResolver enables @a:/@alias: rules; resolve maps a rule line to
+// the pattern it stands for.
+func WithAliasResolver(resolve func(line string) (string, error)) ResolverOption {
+	return func(r *fsResolver) { r.alias = resolve }
+}
+
+// WithDisabledGroups drops the rules of the named @group: groups.
+func WithDisabledGroups(groups ...string) ResolverOption {
+	return func(r *fsResolver) {
+		if r.disabled == nil {
+			r.disabled = make(map[string]bool, len(groups))
+		}
+		for _, g := range groups {
+			r.disabled[g] = true
+		}
+	}
+}
+
+// NewResolver returns a Resolver over fsys. Without options, git, ignore,
+// command and alias lookups are disabled: @changed: matches nothing, nothing
+// is ignored, and @cmd:/@a: rules resolve to no files.
+func NewResolver(fsys fs.FS, opts ...ResolverOption) Resolver {
+	r := &fsResolver{fsys: fsys}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type fsResolver struct {
+	fsys   fs.FS
+	git    GitProvider
+	ignore IgnoreProvider
+	exec   func(cmd string) ([]string, error)
+	alias  func(line string) (string, error)
+
+	disabled map[string]bool
+}
+
+// globalDirectivePrefixes start lines that set global search directives.
+var globalDirectivePrefixes = []string{"@find:", "@find!:", "@grep:", "@grep!:", "@grep-i:", "@grep-re:", "@grep-re!:", "@recent:"}
+
+// managerOnlyDirectives add files through Manager state (rulesets, git,
+// concepts, notebooks) and are reported as unsupported by a Resolver.
+var managerOnlyDirectives = []string{"@include:", "@default:", "@concept:", "@changed:", "@diff:", "@git:", "@tasks", "@tree-only:", "@pkg:", "@fixtures:"}
+
+func (r *fsResolver) Resolve(rules []byte) (*Resolution, error) {
+	// Pre-scan: apply global directives and scoping, blanking the lines
+	// ParseToAST would otherwise misread as patterns. Line numbers are kept.
+	var (
+		lines     []string
+		globals   []SearchDirective
+		scopes    [][]SearchDirective
+		globalsAt = make(map[int][]SearchDirective)
+		coldFrom  = 0
+		errs      []ParseError
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(applyUntil(applyGroups(rules, r.disabled))))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		switch {
+		case sectionSeparatorRegex.MatchString(line):
+			// Without a Manager there is no cx.sections config, so every
+			// section after the first separator is cold.
+			if coldFrom == 0 {
+				coldFrom = lineNum
+			}
+		case line == clearFiltersDirective:
+			globals = nil
+		case line == scopeEnd:
+			if n := len(scopes); n > 0 {
+				globals, scopes = scopes[n-1], scopes[:n-1]
+			}
+		case hasAnyPrefix(line, globalDirectivePrefixes):
+			if _, ds, ok := parseSearchDirectives(" " + line); ok {
+				globals = append(globals, ds...)
+			}
+			raw = ""
+		case hasAnyPrefix(line, managerOnlyDirectives):
+			errs = append(errs, ParseError{Line: lineNum, Msg: fmt.Sprintf("%s is not supported by an embedded Resolver", strings.SplitN(line, ":", 2)[0])})
+			raw = ""
+		case strings.HasPrefix(line, "@") && !strings.HasPrefix(line, "@a:") && !strings.HasPrefix(line, "@alias:") && !strings.HasPrefix(line, "@cmd:"):
+			if scoped, ok, err := parseWithBlock(line); ok {
+				scopes = append(scopes, globals)
+				if err == nil {
+					globals = composeWithGlobals(globals, scoped)
+				}
+				break
+			}
+			// Remaining @ lines (@view:, @freeze-cache, ...) configure
+			// generation, not resolution.
+			raw = ""
+		default:
+			globalsAt[lineNum] = globals
+		}
+		lines = append(lines, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	nodes, parseErrs := ParseToAST([]byte(strings.Join(lines, "\n")))
+	errs = append(errs, parseErrs...)
+
+	var hotNodes, coldNodes []RuleNode
+	for _, n := range nodes {
+		if g := globalsAt[n.Line()]; len(g) > 0 {
+			if fn, ok := n.(*FilterNode); ok {
+				fn.Directives = composeWithGlobals(g, fn.Directives)
+			} else {
+				n = &FilterNode{Child: n, Directives: g, LineNum: n.Line(), RawText: n.Raw(), Excluded: n.IsExclude()}
+			}
+		}
+		if coldFrom > 0 && n.Line() > coldFrom {
+			coldNodes = append(coldNodes, n)
+		} else {
+			hotNodes = append(hotNodes, n)
+		}
+	}
+
+	ctx := &fsResolutionContext{r: r, changed: make(map[string]map[string]bool)}
+	res := &Resolution{Errors: errs}
+	var hotAttr, coldAttr AttributionResult
+	hotAttr, res.Exclusions, res.Filtered, res.ExcludedBy = ResolveAST(hotNodes, ctx)
+	coldAttr, coldExcl, coldFilt, coldEby := ResolveAST(coldNodes, ctx)
+	for line, files := range coldExcl {
+		res.Exclusions[line] = files
+	}
+	for line, files := range coldFilt {
+		res.Filtered[line] = files
+	}
+	for line, files := range coldEby {
+		res.ExcludedBy[line] = files
+	}
+
+	// A file listed in both sections is cold, as with Manager.
+	res.Attribution = make(AttributionResult)
+	cold := make(map[string]bool)
+	for line, files := range coldAttr {
+		for _, f := range files {
+			f = strings.TrimPrefix(f, "/")
+			cold[f] = true
+			res.Cold = append(res.Cold, f)
+			res.Attribution[line] = append(res.Attribution[line], f)
+		}
+	}
+	for line, files := range hotAttr {
+		for _, f := range files {
+			f = strings.TrimPrefix(f, "/")
+			if cold[f] {
+				continue
+			}
+			res.Hot = append(res.Hot, f)
+			res.Attribution[line] = append(res.Attribution[line], f)
+		}
+	}
+	sort.Strings(res.Hot)
+	sort.Strings(res.Cold)
+	for _, files := range res.Exclusions {
+		for i, f := range files {
+			files[i] = strings.TrimPrefix(f, "/")
+		}
+	}
+	for _, infos := range res.Filtered {
+		for i := range infos {
+			infos[i].File = strings.TrimPrefix(infos[i].File, "/")
+		}
+	}
+	for _, infos := range res.ExcludedBy {
+		for i := range infos {
+			infos[i].File = strings.TrimPrefix(infos[i].File, "/")
+		}
+	}
+	return res, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// fsResolutionContext is the ResolutionContext behind NewResolver. Paths
+// are presented to the AST as rooted ("/pkg/a.go") with BaseDir "/", and
+// mapped to fs.FS names by dropping the leading slash.
+type fsResolutionContext struct {
+	r        *fsResolver
+	matchers sync.Map
+	mu       sync.Mutex
+	changed  map[string]map[string]bool
+}
+
+func fsName(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(p, "\\", "/")), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func (c *fsResolutionContext) Stat(p string) (fs.FileInfo, error) {
+	return fs.Stat(c.r.fsys, fsName(p))
+}
+
+func (c *fsResolutionContext) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(c.r.fsys, fsName(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".grove", ".grove-worktrees":
+				return fs.SkipDir
+			}
+		}
+		if p != "." && c.r.ignore != nil && c.r.ignore.IsIgnored(p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && c.isBinary(p) {
+			return nil
+		}
+		if p == "." {
+			return fn("/", d, nil)
+		}
+		return fn("/"+p, d, nil)
+	})
+}
+
+func (c *fsResolutionContext) isBinary(name string) bool {
+	f, err := c.r.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, binarySniffLen)
+	n, _ := f.Read(head)
+	return bytes.IndexByte(head[:n], 0) >= 0
+}
+
+func (c *fsResolutionContext) MatchDirective(file, directive, query string) bool {
+	return c.MatchDirectives(file, []SearchDirective{{Name: directive, Query: query}})
+}
+
+// MatchDirectives mirrors Manager.matchDirectives: any clause may hold, and
+// a clause's grep directives share one read of the file.
+func (c *fsResolutionContext) MatchDirectives(file string, directives []SearchDirective) bool {
+	clauses := directiveClauses(directives)
+	if len(clauses) == 0 {
+		return true
+	}
+	for _, clause := range clauses {
+		if c.matchClause(file, clause) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fsResolutionContext) matchClause(file string, directives []SearchDirective) bool {
+	var greps []grepClause
+	for _, d := range directives {
+		name := strings.TrimSuffix(d.Name, "!")
+		negate := strings.HasSuffix(d.Name, "!")
+		if isGrepDirective(name) {
+			greps = append(greps, grepClause{matcher: compileGrepMatcher(&c.matchers, name, d.Query), negate: negate})
+			continue
+		}
+		if c.matchPathDirective(file, name, d.Query) == negate {
+			return false
+		}
+	}
+	if len(greps) == 0 {
+		return true
+	}
+	f, err := c.r.fsys.Open(fsName(file))
+	if err != nil {
+		return clausesHold(greps, make([]bool, len(greps)))
+	}
+	defer f.Close()
+	return grepReader(f, greps)
+}
+
+func (c *fsResolutionContext) matchPathDirective(file, name, query string) bool {
+	switch name {
+	case "find":
+		return matchFindQuery(file, query)
+	case directiveText, directiveBinary:
+		return true
+	case "changed":
+		return c.changedFiles(query)[fsName(file)]
+	case "recent":
+		duration, err := parseExtendedDuration(query)
+		if err != nil {
+			return false
+		}
+		info, err := fs.Stat(c.r.fsys, fsName(file))
+		return err == nil && info.ModTime().After(time.Now().Add(-duration))
+	}
+	if metadataDirectives[name] {
+		info, err := fs.Stat(c.r.fsys, fsName(file))
+		return err == nil && matchMetadata(info, name, query, time.Now())
+	}
+	return false
+}
+
+func (c *fsResolutionContext) changedFiles(ref string) map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if set, ok := c.changed[ref]; ok {
+		return set
+	}
+	set := make(map[string]bool)
+	if c.r.git != nil {
+		if files, err := c.r.git.ChangedFiles(ref); err == nil {
+			for _, f := range files {
+				set[fsName(f)] = true
+			}
+		}
+	}
+	c.changed[ref] = set
+	return set
+}
+
+func (c *fsResolutionContext) MatchPattern(pattern, p string) bool {
+	return matchRulePattern(pattern, p)
+}
+
+func (c *fsResolutionContext) IsGitIgnored(p string) bool {
+	return c.r.ignore != nil && c.r.ignore.IsIgnored(fsName(p))
+}
+
+func (c *fsResolutionContext) BaseDir() string { return "/" }
+
+func (c *fsResolutionContext) ExecCommand(cmd string) ([]string, error) {
+	if c.r.exec == nil {
+		return nil, fmt.Errorf("@cmd: rules need WithCommandRunner")
+	}
+	return c.r.exec(cmd)
+}
+
+func (c *fsResolutionContext) ResolveAliasLine(line string) (string, error) {
+	if c.r.alias == nil {
+		return "", fmt.Errorf("alias rules need WithAliasResolver")
+	}
+	return c.r.alias(line)
+}
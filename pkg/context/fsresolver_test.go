@@ -0,0 +1,68 @@
+package context
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubGit map[string][]string
+
+func (g stubGit) ChangedFiles(ref string) ([]string, error) { return g[ref], nil }
+
+func TestResolverInMemory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":             {Data: []byte("module x\n")},
+		"pkg/api/handler.go": {Data: []byte("package api\n// TODO: auth\n")},
+		"pkg/api/model.go":   {Data: []byte("package api\n")},
+		"pkg/api/blob.bin":   {Data: []byte("TODO\x00\x01")},
+		"docs/guide.md":      {Data: []byte("# Guide\n")},
+		"vendor/dep/dep.go":  {Data: []byte("package dep\n")},
+	}
+	rules := strings.Join([]string{
+		"@grep: \"TODO\"",
+		"pkg/**",
+		"@clear-filters",
+		"go.mod",
+		"!vendor/**",
+		"---",
+		"docs/**",
+		"@include: other",
+	}, "\n")
+
+	r := NewResolver(fsys, WithIgnoreProvider(IgnoreFunc(func(p string) bool {
+		return strings.HasPrefix(p, "vendor")
+	})))
+	res, err := r.Resolve([]byte(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"go.mod", "pkg/api/handler.go"}, res.Hot)
+	assert.Equal(t, []string{"docs/guide.md"}, res.Cold)
+	assert.Equal(t, []string{"pkg/api/handler.go"}, res.Attribution[2])
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, 8, res.Errors[0].Line)
+	}
+}
+
+func TestResolverChangedDirective(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go": {Data: []byte("package a\n")},
+		"b.go": {Data: []byte("package a\n")},
+	}
+	r := NewResolver(fsys, WithGitProvider(stubGit{"main": {"b.go"}}))
+	res, err := r.Resolve([]byte("*.go @changed: main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"b.go"}, res.Hot)
+
+	// Without a provider @changed: matches nothing.
+	res, err = NewResolver(fsys).Resolve([]byte("*.go @changed: main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, res.Hot)
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -46,9 +47,11 @@ func (m *Manager) GenerateContextFromRulesFile(rulesFilePath string, useXMLForma
 	}).Info("Generating context from custom rules file")
 
 	// Ensure .grove directory exists for output files
-	groveDir := filepath.Join(m.workDir, GroveDir)
-	if err := os.MkdirAll(groveDir, 0o755); err != nil {
-		return fmt.Errorf("error creating %s directory: %w", groveDir, err)
+	if m.stream == nil {
+		groveDir := filepath.Join(m.workDir, GroveDir)
+		if err := os.MkdirAll(groveDir, 0o755); err != nil {
+			return fmt.Errorf("error creating %s directory: %w", groveDir, err)
+		}
 	}
 
 	absRulesFilePath, err := filepath.Abs(rulesFilePath)
@@ -82,41 +85,50 @@ func (m *Manager) GenerateContextFromRulesFile(rulesFilePath string, useXMLForma
 		Field("lines", lineCount).
 		Log(context.Background())
 
-	hotRules, coldRules, _, treePaths, err := m.expandAllRules(absRulesFilePath, make(map[string]bool), 0)
+	m.explicitRulesPath, m.explicitRules = absRulesFilePath, rulesContent
+	defer func() { m.explicitRulesPath, m.explicitRules = "", nil }()
+
+	hotRules, coldRules, _, treePaths, err := m.expandAllRules(absRulesFilePath, newExpansionRun(), 0)
 	if err != nil {
 		return fmt.Errorf("failed to resolve patterns from rules file %s: %w", rulesFilePath, err)
 	}
 
-	hotFiles, err := m.resolveFilesViaAST(hotRules)
+	finalHotFiles, coldFiles, err := m.resolveHotCold(hotRules, coldRules)
 	if err != nil {
-		return fmt.Errorf("error resolving hot context files: %w", err)
+		return err
 	}
+	finalHotFiles, coldFiles = m.applyFileDirectives(finalHotFiles, coldFiles)
 
-	coldFiles, err := m.resolveFilesViaAST(coldRules)
-	if err != nil {
-		return fmt.Errorf("error resolving cold context files: %w", err)
+	if err := m.checkRequiredFiles(rulesContent, "hot", finalHotFiles); err != nil {
+		return err
 	}
-
-	coldFilesMap := make(map[string]bool)
-	for _, file := range coldFiles {
-		coldFilesMap[file] = true
+	if err := m.checkRequiredFiles(rulesContent, "cold", coldFiles); err != nil {
+		return err
 	}
-
-	var finalHotFiles []string
-	for _, file := range hotFiles {
-		if !coldFilesMap[file] {
-			finalHotFiles = append(finalHotFiles, file)
-		}
+	if err := m.commandFailureError(); err != nil {
+		return err
 	}
+	finalHotFiles, coldFiles, err = m.budgetFiles(rulesContent, finalHotFiles, coldFiles, false, false)
+	if err != nil {
+		return err
+	}
+	m.warnStaleFiles(rulesContent, append(append([]string{}, finalHotFiles...), coldFiles...))
+	finalHotFiles = m.orderLikeArtifact(m.withNotes(finalHotFiles), m.ResolveContextWritePath())
 
 	// Generate context files
 	if err := m.generateContextFromFilesAndTrees(finalHotFiles, treePaths, useXMLFormat); err != nil {
 		return err
 	}
+	if m.stream != nil {
+		return nil
+	}
 
 	if err := m.generateCachedContextFromFiles(coldFiles); err != nil {
 		return err
 	}
+	if err := m.generateSectionArtifacts(absRulesFilePath); err != nil {
+		return err
+	}
 
 	// Mirror GenerateContext: persist the resolved hot files list so callers
 	// (e.g. flow's oneshot executor) can call GetStats to surface a context
@@ -137,9 +149,11 @@ func (m *Manager) GenerateContext(useXMLFormat bool) error {
 	}).Debug("Generating hot context file")
 
 	// Ensure .grove directory exists
-	groveDir := filepath.Join(m.workDir, GroveDir)
-	if err := os.MkdirAll(groveDir, 0o755); err != nil {
-		return fmt.Errorf("error creating %s directory: %w", groveDir, err)
+	if m.stream == nil {
+		groveDir := filepath.Join(m.workDir, GroveDir)
+		if err := os.MkdirAll(groveDir, 0o755); err != nil {
+			return fmt.Errorf("error creating %s directory: %w", groveDir, err)
+		}
 	}
 
 	// Use ResolveFilesAndTreesFromRules which handles @default and @tree directives
@@ -148,9 +162,21 @@ func (m *Manager) GenerateContext(useXMLFormat bool) error {
 		return fmt.Errorf("error resolving files from rules: %w", err)
 	}
 
+	rulesContent, _, _ := m.LoadRulesContent()
+	if err := m.checkRequiredFiles(rulesContent, "hot", filesToInclude); err != nil {
+		return err
+	}
+	if err := m.commandFailureError(); err != nil {
+		return err
+	}
+	if filesToInclude, _, err = m.budgetFiles(rulesContent, filesToInclude, nil, false, true); err != nil {
+		return err
+	}
+	m.warnStaleFiles(rulesContent, filesToInclude)
+	filesToInclude = m.orderLikeArtifact(m.withNotes(filesToInclude), m.ResolveContextWritePath())
+
 	// Handle case where no rules file exists
 	if len(filesToInclude) == 0 && len(treePaths) == 0 {
-		rulesContent, _, _ := m.LoadRulesContent()
 		if rulesContent == nil {
 			// Log warning using structured logging (respects TUI mode)
 			m.ulog.Warn("No rules file found").
@@ -164,27 +190,91 @@ func (m *Manager) GenerateContext(useXMLFormat bool) error {
 
 // generateContextFromFilesAndTrees is a private helper that writes trees and a list of files to the hot context file.
 func (m *Manager) generateContextFromFilesAndTrees(files, treePaths []string, useXMLFormat bool) error {
+	defer m.useTierTransforms(HotSection)()
+	defer m.useProvenance(HotSection)()
+	tmpl, err := m.outputTemplate()
+	if err != nil {
+		return err
+	}
+	if m.stream != nil {
+		bw := bufio.NewWriter(m.stream)
+		if tmpl != nil {
+			if err := m.writeTemplated(bw, tmpl, HotSection, files, treePaths); err != nil {
+				return err
+			}
+		} else {
+			m.writeHotContext(bw, files, treePaths, useXMLFormat, nil)
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("error streaming context: %w", err)
+		}
+		m.recordGeneration("hot", files)
+		return nil
+	}
+
 	// Resolve context file path (plan-scoped > notebook > local)
 	contextPath := m.ResolveContextWritePath()
+	var reuse map[string][]byte
+	if useXMLFormat && tmpl == nil {
+		reuse = m.spliceSource(contextPath) // read before os.Create truncates it
+	}
 	ctxFile, err := os.Create(contextPath)
 	if err != nil {
 		return fmt.Errorf("error creating %s: %w", contextPath, err)
 	}
 	defer ctxFile.Close()
 
+	reused := 0
+	if tmpl != nil {
+		if err := m.writeTemplated(ctxFile, tmpl, HotSection, files, treePaths); err != nil {
+			return err
+		}
+	} else {
+		reused = m.writeHotContext(ctxFile, files, treePaths, useXMLFormat, reuse)
+	}
+
+	if err := ctxFile.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", contextPath, err)
+	}
+	if err := m.sealArtifact(contextPath); err != nil {
+		return err
+	}
+
+	m.recordGeneration("hot", files)
+
+	m.log.WithFields(logrus.Fields{
+		"file_count":  len(files),
+		"reused":      reused,
+		"output_path": contextPath,
+	}).Info("Generated hot context file")
+
+	m.ulog.Success("Generated context file").
+		Field("path", contextPath).
+		Field("file_count", len(files)).
+		Log(context.Background())
+
+	return nil
+}
+
+// writeHotContext writes the hot context document for trees and files to
+// w, splicing unchanged blocks from reuse, and returns the number of
+// blocks spliced.
+func (m *Manager) writeHotContext(w io.Writer, files, treePaths []string, useXMLFormat bool, reuse map[string][]byte) int {
+	reused := 0
+
 	// Write XML header and opening tags if using XML format
 	if useXMLFormat {
-		fmt.Fprintf(ctxFile, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
-		fmt.Fprintf(ctxFile, "<context>\n")
-		fmt.Fprintf(ctxFile, "  <hot-context files=\"%d\" description=\"Files to be used for reference/background context to carry out the user's question/task to be provided later\">\n", len(files))
+		fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		fmt.Fprintf(w, "<context>\n")
+		fmt.Fprintf(w, "  <hot-context files=\"%d\" description=\"Files to be used for reference/background context to carry out the user's question/task to be provided later\">\n", len(files))
 	}
 
 	// If no files or trees to include, write a comment explaining why
 	if len(files) == 0 && len(treePaths) == 0 {
 		if useXMLFormat {
-			fmt.Fprintf(ctxFile, "    <!-- No rules file found. Create %s with patterns to include files. -->\n", ActiveRulesFile)
+			fmt.Fprintf(w, "    <!-- No rules file found. Create %s with patterns to include files. -->\n", ActiveRulesFile)
 		} else {
-			fmt.Fprintf(ctxFile, "# No rules file found. Create %s with patterns to include files.\n", ActiveRulesFile)
+			fmt.Fprintf(w, "# No rules file found. Create %s with patterns to include files.\n", ActiveRulesFile)
 		}
 	}
 
@@ -200,69 +290,47 @@ func (m *Manager) generateContextFromFilesAndTrees(files, treePaths []string, us
 		}
 
 		if useXMLFormat {
-			fmt.Fprintf(ctxFile, "    <tree path=\"%s\">\n%s    </tree>\n", tp, treeStr)
+			fmt.Fprintf(w, "    <tree path=\"%s\">\n%s    </tree>\n", tp, treeStr)
 		} else {
-			fmt.Fprintf(ctxFile, "=== TREE: %s ===\n%s=== END TREE: %s ===\n\n", tp, treeStr, tp)
+			fmt.Fprintf(w, "=== TREE: %s ===\n%s=== END TREE: %s ===\n\n", tp, treeStr, tp)
 		}
 	}
 
 	// Write concatenated content
 	for _, file := range files {
+		m.writeProvenance(w, file, useXMLFormat)
+		if block, ok := m.splicedBlock(reuse, file); ok {
+			_, _ = w.Write(block)
+			reused++
+			continue
+		}
 		if useXMLFormat {
 			// Use the existing writeFileToXML method for consistency
-			if err := m.writeFileToXML(ctxFile, file, "    "); err != nil {
+			if err := m.writeFileToXML(w, file, "    "); err != nil {
 				m.ulog.Warn("Error writing file to context").
 					Field("file", file).
 					Err(err).
 					Log(context.Background())
 			}
 		} else {
-			// Classic delimiter style
-			fmt.Fprintf(ctxFile, "=== FILE: %s ===\n", file)
-
-			// Read and write file content
-			filePath := file
-			if !filepath.IsAbs(file) {
-				filePath = filepath.Join(m.workDir, file)
-			}
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				fmt.Fprintf(ctxFile, "Error reading file: %v\n", err)
-				fmt.Fprintf(ctxFile, "=== END FILE: %s ===\n\n", file)
-				continue
-			}
-
-			if m.stripComments {
-				content = StripComments(file, content)
-			}
-
-			_, _ = ctxFile.Write(content)
-
-			// Write end marker
-			fmt.Fprintf(ctxFile, "\n=== END FILE: %s ===\n\n", file)
+			m.writeFileClassic(w, file)
 		}
 	}
 
+	if rulesContent, _ := m.generatingRules(); hasSymbolIndexDirective(rulesContent) {
+		m.writeSymbolIndex(w, files, useXMLFormat)
+	}
+
 	// Close XML tags if using XML format
 	if useXMLFormat {
-		fmt.Fprintf(ctxFile, "  </hot-context>\n")
-		fmt.Fprintf(ctxFile, "</context>\n")
+		fmt.Fprintf(w, "  </hot-context>\n")
+		fmt.Fprintf(w, "</context>\n")
 	}
-
-	m.log.WithFields(logrus.Fields{
-		"file_count":  len(files),
-		"output_path": contextPath,
-	}).Info("Generated hot context file")
-
-	m.ulog.Success("Generated context file").
-		Field("path", contextPath).
-		Field("file_count", len(files)).
-		Log(context.Background())
-
-	return nil
+	return reused
 }
 
-// GenerateCachedContext generates .grove/cached-context with only the cold context files.
+// GenerateCachedContext generates .grove/cached-context with only the cold
+// context files, and the artifacts of sections configured with an output.
 func (m *Manager) GenerateCachedContext() error {
 	// Ensure .grove directory exists
 	groveDir := filepath.Join(m.workDir, GroveDir)
@@ -276,7 +344,22 @@ func (m *Manager) GenerateCachedContext() error {
 		return fmt.Errorf("error resolving cold context files: %w", err)
 	}
 
-	return m.generateCachedContextFromFiles(coldFiles)
+	rulesContent, _, _ := m.LoadRulesContent()
+	if err := m.checkRequiredFiles(rulesContent, "cold", coldFiles); err != nil {
+		return err
+	}
+	if err := m.commandFailureError(); err != nil {
+		return err
+	}
+	if _, coldFiles, err = m.budgetFiles(rulesContent, nil, coldFiles, true, false); err != nil {
+		return err
+	}
+	m.warnStaleFiles(rulesContent, coldFiles)
+
+	if err := m.generateCachedContextFromFiles(coldFiles); err != nil {
+		return err
+	}
+	return m.generateSectionArtifacts("")
 }
 
 // generateCachedContextFromFiles is a private helper that writes a list of files to the cold context files.
@@ -284,38 +367,73 @@ func (m *Manager) generateCachedContextFromFiles(coldFiles []string) error {
 	// Resolve cached context file paths (plan-scoped > notebook > local)
 	cachedPath := m.ResolveCachedContextWritePath()
 	cachedListPath := m.ResolveCachedContextFilesListWritePath()
+	defer m.useTierTransforms(ColdSection)()
+	defer m.useProvenance(ColdSection)()
+	tmpl, err := m.outputTemplate()
+	if err != nil {
+		return err
+	}
+	coldFiles = m.orderLikeArtifact(coldFiles, cachedPath)
+	var reuse map[string][]byte
+	if tmpl == nil {
+		reuse = m.spliceSource(cachedPath) // read before os.Create truncates it
+	}
+	reused := 0
 	cachedFile, err := os.Create(cachedPath)
 	if err != nil {
 		return fmt.Errorf("error creating %s: %w", cachedPath, err)
 	}
 	defer cachedFile.Close()
 
-	// If no cold files, we can just create an empty file or a small XML structure.
-	// Let's keep the structure for consistency.
-	fmt.Fprintf(cachedFile, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
-	fmt.Fprintf(cachedFile, "<context>\n")
-	fmt.Fprintf(cachedFile, "  <cold-context files=\"%d\">\n", len(coldFiles))
-
-	// Write cold context files
-	for _, file := range coldFiles {
-		if err := m.writeFileToXML(cachedFile, file, "    "); err != nil {
-			m.ulog.Warn("Error writing file to cached context").
-				Field("file", file).
-				Err(err).
-				Log(context.Background())
+	if tmpl != nil {
+		if err := m.writeTemplated(cachedFile, tmpl, ColdSection, coldFiles, nil); err != nil {
+			return err
+		}
+	} else {
+		// If no cold files, we can just create an empty file or a small XML structure.
+		// Let's keep the structure for consistency.
+		fmt.Fprintf(cachedFile, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		fmt.Fprintf(cachedFile, "<context>\n")
+		fmt.Fprintf(cachedFile, "  <cold-context files=\"%d\">\n", len(coldFiles))
+
+		// Write cold context files
+		for _, file := range coldFiles {
+			m.writeProvenance(cachedFile, file, true)
+			if block, ok := m.splicedBlock(reuse, file); ok {
+				_, _ = cachedFile.Write(block)
+				reused++
+				continue
+			}
+			if err := m.writeFileToXML(cachedFile, file, "    "); err != nil {
+				m.ulog.Warn("Error writing file to cached context").
+					Field("file", file).
+					Err(err).
+					Log(context.Background())
+			}
 		}
+
+		fmt.Fprintf(cachedFile, "  </cold-context>\n")
+		fmt.Fprintf(cachedFile, "</context>\n")
 	}
 
-	fmt.Fprintf(cachedFile, "  </cold-context>\n")
-	fmt.Fprintf(cachedFile, "</context>\n")
+	if err := cachedFile.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", cachedPath, err)
+	}
+	if err := m.sealArtifact(cachedPath); err != nil {
+		return err
+	}
 
 	// Write the list of cached context files
 	if err := m.WriteFilesList(cachedListPath, coldFiles); err != nil {
 		return err
 	}
 
+	m.recordGeneration("cold", coldFiles)
+	m.recordCacheProvenance(cachedPath, cachedListPath, len(coldFiles), m.LastGeneration().ColdTokens)
+
 	m.log.WithFields(logrus.Fields{
 		"file_count":  len(coldFiles),
+		"reused":      reused,
 		"output_path": cachedPath,
 		"list_path":   cachedListPath,
 	}).Info("Generated cold context artifacts")
@@ -336,6 +454,32 @@ func (m *Manager) generateCachedContextFromFiles(coldFiles []string) error {
 	return nil
 }
 
+// writeFileClassic writes a file's content between classic === FILE ===
+// delimiters.
+func (m *Manager) writeFileClassic(w io.Writer, file string) {
+	fmt.Fprintf(w, "=== FILE: %s ===\n", file)
+
+	// Read and write file content
+	filePath := file
+	if !filepath.IsAbs(file) {
+		filePath = filepath.Join(m.workDir, file)
+	}
+	content, err := readRegularFile(filePath)
+	if err != nil {
+		fmt.Fprintf(w, "Error reading file: %v\n", err)
+		fmt.Fprintf(w, "=== END FILE: %s ===\n\n", file)
+		return
+	}
+
+	content = m.transformContent(file, content)
+	content = m.screenContent(file, content)
+
+	_, _ = w.Write(content)
+
+	// Write end marker
+	fmt.Fprintf(w, "\n=== END FILE: %s ===\n\n", file)
+}
+
 // writeFileToXML writes a file's content to the XML output with proper indentation
 func (m *Manager) writeFileToXML(w io.Writer, file, indent string) error {
 	fmt.Fprintf(w, "%s<file path=\"%s\">\n", indent, file)
@@ -346,16 +490,15 @@ func (m *Manager) writeFileToXML(w io.Writer, file, indent string) error {
 		filePath = filepath.Join(m.workDir, file)
 	}
 
-	content, err := os.ReadFile(filePath)
+	content, err := readRegularFile(filePath)
 	if err != nil {
 		fmt.Fprintf(w, "%s  <error>%v</error>\n", indent, err)
 		fmt.Fprintf(w, "%s</file>\n", indent)
 		return err
 	}
 
-	if m.stripComments {
-		content = StripComments(file, content)
-	}
+	content = m.transformContent(file, content)
+	content = m.screenContent(file, content)
 
 	// Write content directly without extra indentation (content already has its own)
 	_, _ = w.Write(content)
@@ -394,12 +537,46 @@ func (m *Manager) GenerateTreeString(rootPath string) (string, error) {
 }
 
 func (m *Manager) buildTreeString(sb *strings.Builder, dirPath, prefix string, gitIgnored map[string]bool) error {
-	entries, err := os.ReadDir(dirPath)
+	validEntries, err := m.treeEntries(dirPath, gitIgnored)
 	if err != nil {
 		return err
 	}
 
-	// Filter entries to drop gitignored items and internal Grove/git directories
+	for i, entry := range validEntries {
+		isLast := i == len(validEntries)-1
+
+		connector := "├── "
+		childPrefix := prefix + "│   "
+		if isLast {
+			connector = "└── "
+			childPrefix = prefix + "    "
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		sb.WriteString(prefix + connector + name + "\n")
+
+		if entry.IsDir() {
+			if err := m.buildTreeString(sb, filepath.Join(dirPath, entry.Name()), childPrefix, gitIgnored); err != nil {
+				sb.WriteString(childPrefix + "[error reading directory]\n")
+			}
+		}
+	}
+
+	return nil
+}
+
+// treeEntries lists dirPath for tree rendering, dropping gitignored items and
+// internal Grove/git directories.
+func (m *Manager) treeEntries(dirPath string, gitIgnored map[string]bool) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
 	var validEntries []os.DirEntry
 	for _, entry := range entries {
 		name := entry.Name()
@@ -418,30 +595,120 @@ func (m *Manager) buildTreeString(sb *strings.Builder, dirPath, prefix string, g
 		}
 		validEntries = append(validEntries, entry)
 	}
+	return validEntries, nil
+}
 
-	for i, entry := range validEntries {
-		isLast := i == len(validEntries)-1
+// GenerateSizedTreeString is GenerateTreeString with each file's size and
+// each directory's file count and total size, for @tree-only: listings.
+func (m *Manager) GenerateSizedTreeString(rootPath string) (string, error) {
+	absPath := rootPath
+	if !filepath.IsAbs(rootPath) {
+		absPath = filepath.Join(m.workDir, rootPath)
+	}
+	absPath = filepath.Clean(absPath)
+
+	gitIgnored, err := m.getGitIgnoredFiles(absPath)
+	if err != nil {
+		gitIgnored = make(map[string]bool)
+	}
+
+	var body strings.Builder
+	files, size, err := m.buildSizedTreeString(&body, absPath, "", gitIgnored)
+	if err != nil {
+		return "", err
+	}
 
+	baseName := filepath.Base(absPath)
+	if baseName == "." || baseName == string(filepath.Separator) {
+		baseName = filepath.Base(m.workDir)
+	}
+	return fmt.Sprintf("%s/ (%s)\n%s", baseName, treeDirSummary(files, size), body.String()), nil
+}
+
+// buildSizedTreeString renders dirPath's subtree into sb and returns the
+// number of files and bytes beneath it. Each directory's children are
+// rendered first so its line can carry their totals.
+func (m *Manager) buildSizedTreeString(sb *strings.Builder, dirPath, prefix string, gitIgnored map[string]bool) (int, int64, error) {
+	entries, err := m.treeEntries(dirPath, gitIgnored)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var files int
+	var size int64
+	for i, entry := range entries {
 		connector := "├── "
 		childPrefix := prefix + "│   "
-		if isLast {
+		if i == len(entries)-1 {
 			connector = "└── "
 			childPrefix = prefix + "    "
 		}
 
-		name := entry.Name()
-		if entry.IsDir() {
-			name += "/"
-		}
-
-		sb.WriteString(prefix + connector + name + "\n")
-
 		if entry.IsDir() {
-			if err := m.buildTreeString(sb, filepath.Join(dirPath, entry.Name()), childPrefix, gitIgnored); err != nil {
+			var children strings.Builder
+			n, bytes, err := m.buildSizedTreeString(&children, filepath.Join(dirPath, entry.Name()), childPrefix, gitIgnored)
+			if err != nil {
+				sb.WriteString(prefix + connector + entry.Name() + "/\n")
 				sb.WriteString(childPrefix + "[error reading directory]\n")
+				continue
 			}
+			sb.WriteString(fmt.Sprintf("%s%s%s/ (%s)\n", prefix, connector, entry.Name(), treeDirSummary(n, bytes)))
+			sb.WriteString(children.String())
+			files += n
+			size += bytes
+			continue
+		}
+
+		var fileSize int64
+		if info, err := entry.Info(); err == nil {
+			fileSize = info.Size()
 		}
+		sb.WriteString(fmt.Sprintf("%s%s%s (%s)\n", prefix, connector, entry.Name(), FormatBytes(int(fileSize))))
+		files++
+		size += fileSize
 	}
+	return files, size, nil
+}
 
-	return nil
+func treeDirSummary(files int, size int64) string {
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%d %s, %s", files, noun, FormatBytes(int(size)))
+}
+
+// generateTreeOnlyFile writes the sized tree listing of an @tree-only:
+// directory to .grove/trees and returns its absolute path, so the listing
+// is included as a single file in whichever section declared it.
+func (m *Manager) generateTreeOnlyFile(dir string) (string, error) {
+	tree, err := m.GenerateSizedTreeString(dir)
+	if err != nil {
+		return "", err
+	}
+
+	treesDir := filepath.Join(m.workDir, GroveDir, "trees")
+	if err := os.MkdirAll(treesDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create trees directory: %w", err)
+	}
+
+	name := dir
+	if rel, relErr := filepath.Rel(m.workDir, dir); relErr == nil && filepath.IsAbs(dir) && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	safeName := strings.Trim(strings.NewReplacer("/", "-", "\\", "-", "~", "home", "..", "up").Replace(filepath.ToSlash(filepath.Clean(name))), "-")
+	if safeName == "" || safeName == "." {
+		safeName = "root"
+	}
+
+	outPath := filepath.Join(treesDir, fmt.Sprintf("tree-%s.txt", safeName))
+	if err := os.WriteFile(outPath, []byte(tree), 0o644); err != nil { //nolint:gosec // directory listing, not sensitive
+		return "", fmt.Errorf("failed to write tree file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return outPath, nil
+	}
+	return absPath, nil
 }
@@ -0,0 +1,45 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTreeOnlyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("vendor/lib/a.go", 100)
+	write("vendor/lib/b.go", 2048)
+	write("vendor/README", 10)
+
+	m := NewManager(dir, WithNoState())
+	path, err := m.generateTreeOnlyFile("vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "tree-vendor.txt" {
+		t.Errorf("unexpected file name %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "vendor/ (3 files, 2.1 KB)\n" +
+		"├── README (10 bytes)\n" +
+		"└── lib/ (2 files, 2.1 KB)\n" +
+		"    ├── a.go (100 bytes)\n" +
+		"    └── b.go (2.0 KB)\n"
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
@@ -25,11 +25,12 @@ type GitOptions struct {
 	Force   bool   // Force overwrite of existing rules without prompting
 }
 
-// UpdateFromGit updates the context files list based on git history
-func (m *Manager) UpdateFromGit(opts GitOptions) error {
+// gitFileList collects the existing files matching the git criteria of
+// opts.
+func (m *Manager) gitFileList(opts GitOptions) ([]string, error) {
 	// Ensure we're in a git repository
 	if err := checkGitRepo(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Collect files based on options
@@ -46,15 +47,15 @@ func (m *Manager) UpdateFromGit(opts GitOptions) error {
 	case opts.Commits > 0:
 		files, err = getGitFilesFromCommits(opts.Commits)
 	default:
-		return fmt.Errorf("no git option specified")
+		return nil, fmt.Errorf("no git option specified")
 	}
 
 	if err != nil {
-		return fmt.Errorf("error getting git files: %w", err)
+		return nil, fmt.Errorf("error getting git files: %w", err)
 	}
 
 	if len(files) == 0 {
-		return fmt.Errorf("no files found matching git criteria")
+		return nil, fmt.Errorf("no files found matching git criteria")
 	}
 
 	// Remove duplicates
@@ -81,7 +82,17 @@ func (m *Manager) UpdateFromGit(opts GitOptions) error {
 	}
 
 	if len(fileList) == 0 {
-		return fmt.Errorf("no existing files found matching git criteria")
+		return nil, fmt.Errorf("no existing files found matching git criteria")
+	}
+
+	return fileList, nil
+}
+
+// UpdateFromGit updates the context files list based on git history
+func (m *Manager) UpdateFromGit(opts GitOptions) error {
+	fileList, err := m.gitFileList(opts)
+	if err != nil {
+		return err
 	}
 
 	rulesPath := m.ResolveRulesWritePath()
@@ -124,6 +135,24 @@ func (m *Manager) UpdateFromGit(opts GitOptions) error {
 	return nil
 }
 
+// PlanFromGit plans UpdateFromGit without prompting: the rules file is
+// overwritten unless opts.Append is set.
+func (m *Manager) PlanFromGit(opts GitOptions) (PlannedChange, error) {
+	fileList, err := m.gitFileList(opts)
+	if err != nil {
+		return PlannedChange{}, err
+	}
+	rulesPath := m.ResolveRulesWritePath()
+	content := filesListContent(fileList)
+	if opts.Append {
+		existing, _ := os.ReadFile(rulesPath)
+		content = append(existing, content...)
+	}
+	change := PlanFileWrite(rulesPath, content)
+	change.Detail = fmt.Sprintf("%d explicit file paths from git", len(fileList))
+	return change, nil
+}
+
 // checkGitRepo verifies we're in a git repository
 func checkGitRepo() error {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
@@ -316,6 +345,113 @@ func (m *Manager) generateDiffFile(ref string) (string, error) {
 	return absPath, nil
 }
 
+// gitLogFormats maps the named formats accepted by `@git: log` to git flags.
+var gitLogFormats = map[string][]string{
+	"oneline": {"--oneline", "--decorate"},
+	"short":   {"--pretty=short"},
+	"medium":  {"--pretty=medium"},
+	"full":    {"--pretty=full"},
+	"stat":    {"--stat"},
+}
+
+// gitMetadataArgs maps an `@git:` spec to the git command that produces it:
+//
+//	@git: log [count] [oneline|short|medium|full|stat|format:<pretty>]
+//	@git: status
+//	@git: branches [count]
+//
+// log defaults to the last 20 commits in oneline form; branches draws the
+// commit graph of all local branches (default 30 commits).
+func gitMetadataArgs(spec string) ([]string, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("expected log, status or branches")
+	}
+	kind, rest := fields[0], fields[1:]
+	count := 0
+	if len(rest) > 0 {
+		if n, err := strconv.Atoi(rest[0]); err == nil && n > 0 {
+			count, rest = n, rest[1:]
+		}
+	}
+
+	switch kind {
+	case "log":
+		if count == 0 {
+			count = 20
+		}
+		args := []string{"log", "-n", strconv.Itoa(count)}
+		format := strings.Join(rest, " ")
+		switch {
+		case format == "":
+			args = append(args, gitLogFormats["oneline"]...)
+		case strings.HasPrefix(format, "format:"):
+			args = append(args, "--pretty="+format)
+		case gitLogFormats[format] != nil:
+			args = append(args, gitLogFormats[format]...)
+		default:
+			return nil, fmt.Errorf("unknown log format %q (use oneline, short, medium, full, stat or format:<pretty>)", format)
+		}
+		return args, nil
+	case "status":
+		if len(rest) > 0 || count > 0 {
+			return nil, fmt.Errorf("status takes no arguments")
+		}
+		return []string{"status", "--short", "--branch"}, nil
+	case "branches":
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("branches takes only a commit count")
+		}
+		if count == 0 {
+			count = 30
+		}
+		return []string{"log", "--graph", "--oneline", "--decorate", "--branches", "-n", strconv.Itoa(count)}, nil
+	}
+	return nil, fmt.Errorf("unknown git metadata %q (use log, status or branches)", kind)
+}
+
+// generateGitMetadataFile writes the output of an `@git:` directive to
+// .grove/git so it can be included like any other file, and returns its
+// absolute path.
+func (m *Manager) generateGitMetadataFile(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	args, err := gitMetadataArgs(spec)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", args...) //nolint:gosec // args built from a fixed set of flags
+	cmd.Dir = m.workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git %s: %w", strings.Join(args, " "), err)
+	}
+
+	gitDir := filepath.Join(m.workDir, GroveDir, "git")
+	if err := os.MkdirAll(gitDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create git metadata directory: %w", err)
+	}
+
+	safeSpec := strings.Join(strings.Fields(spec), "-")
+	safeSpec = strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == '%' || r == '"' || r == '\'' {
+			return '_'
+		}
+		return r
+	}, safeSpec)
+
+	outPath := filepath.Join(gitDir, fmt.Sprintf("git-%s.txt", safeSpec))
+	if err := os.WriteFile(outPath, output, 0o644); err != nil { //nolint:gosec // git metadata, not sensitive
+		return "", fmt.Errorf("failed to write git metadata file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return outPath, nil
+	}
+	return absPath, nil
+}
+
 // GetGitInfo returns information about the current git state
 func GetGitInfo() (branch string, hasChanges bool, err error) {
 	// Try daemon's cached git status first
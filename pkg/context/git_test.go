@@ -192,3 +192,74 @@ func TestCheckGitRepo(t *testing.T) {
 		}
 	})
 }
+
+func TestGitMetadataArgs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "log", want: "log -n 20 --oneline --decorate"},
+		{spec: "log 5 stat", want: "log -n 5 --stat"},
+		{spec: "log 10 format:%h %an %s", want: "log -n 10 --pretty=format:%h %an %s"},
+		{spec: "status", want: "status --short --branch"},
+		{spec: "branches 50", want: "log --graph --oneline --decorate --branches -n 50"},
+		{spec: "log 5 fancy", wantErr: true},
+		{spec: "status 3", wantErr: true},
+		{spec: "tags", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			args, err := gitMetadataArgs(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateGitMetadataFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	_ = os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o644)
+	git("add", "a.go")
+	git("commit", "-qm", "Add package a")
+
+	m := &Manager{workDir: dir}
+	path, err := m.generateGitMetadataFile("log 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "git-log-5.txt" {
+		t.Errorf("unexpected file name %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Add package a") {
+		t.Errorf("log file should list the commit, got %q", data)
+	}
+}
@@ -0,0 +1,259 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+)
+
+// binarySniffLen is how much of a file is inspected for NUL bytes before a
+// grep directive reads it. Binary files never satisfy @grep.
+const binarySniffLen = 8000
+
+// grepMatcher is a compiled @grep/@grep-i/@grep-re query. @grep and @grep-i
+// queries that are not valid regular expressions fall back to a literal
+// substring match, as before; an invalid @grep-re query matches nothing (and
+// is rejected before resolution, see resolver.go). Multiline matchers need
+// the whole file; the rest are evaluated line by line so a scan can stop at
+// the first matching line.
+type grepMatcher struct {
+	re        *regexp.Regexp
+	literal   []byte
+	fold      bool
+	multiline bool
+	invalid   bool
+}
+
+func (g *grepMatcher) match(b []byte) bool {
+	if g.invalid {
+		return false
+	}
+	if g.re != nil {
+		return g.re.Match(b)
+	}
+	if g.fold {
+		return bytes.Contains(bytes.ToLower(b), g.literal)
+	}
+	return bytes.Contains(b, g.literal)
+}
+
+// grepMatcherFor returns the compiled matcher for a grep directive, caching
+// it on the manager so a query is compiled once per resolution rather than
+// once per file.
+func (m *Manager) grepMatcherFor(directive, query string) *grepMatcher {
+	return compileGrepMatcher(&m.grepMatchers, directive, query)
+}
+
+// compileGrepMatcher compiles a grep directive's query, memoized in cache.
+func compileGrepMatcher(cache *sync.Map, directive, query string) *grepMatcher {
+	key := directive + "\x00" + query
+	if cached, ok := cache.Load(key); ok {
+		return cached.(*grepMatcher)
+	}
+	g := &grepMatcher{fold: directive == "grep-i"}
+	pattern := query
+	if g.fold {
+		pattern = "(?i)" + query
+	}
+	if re, err := regexp.Compile(pattern); err == nil {
+		g.re = re
+		g.multiline = !lineSafe(pattern)
+	} else if directive == "grep-re" {
+		g.invalid = true
+	} else {
+		g.literal = []byte(query)
+		if g.fold {
+			g.literal = bytes.ToLower(g.literal)
+		}
+		g.multiline = strings.Contains(query, "\n")
+	}
+	actual, _ := cache.LoadOrStore(key, g)
+	return actual.(*grepMatcher)
+}
+
+// lineSafe reports whether a regex gives the same answer when run against
+// each line separately as against the whole file: it must not be able to
+// match a newline, and must not use whole-text anchors (^ and $ without (?m),
+// \A, \z), which mean something different per line.
+func lineSafe(pattern string) bool {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	var walk func(*syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		switch r.Op {
+		case syntax.OpAnyChar, syntax.OpBeginText, syntax.OpEndText:
+			return false
+		case syntax.OpLiteral:
+			for _, c := range r.Rune {
+				if c == '\n' {
+					return false
+				}
+			}
+		case syntax.OpCharClass:
+			for i := 0; i+1 < len(r.Rune); i += 2 {
+				if r.Rune[i] <= '\n' && '\n' <= r.Rune[i+1] {
+					return false
+				}
+			}
+		}
+		for _, sub := range r.Sub {
+			if !walk(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return walk(re)
+}
+
+// grepClause is one grep directive of a filter, possibly negated (@grep!).
+type grepClause struct {
+	matcher *grepMatcher
+	negate  bool
+}
+
+// grepFile evaluates every clause against file in a single read and reports
+// whether all of them hold. Line-oriented clauses stream the file and stop
+// as soon as the outcome is decided: every positive clause has matched, or
+// a negated clause has. Multiline clauses share one full read.
+func grepFile(path string, clauses []grepClause) bool {
+	f, err := openRegular(path)
+	if err != nil {
+		// Unreadable files match nothing, as with the old whole-file read.
+		return clausesHold(clauses, make([]bool, len(clauses)))
+	}
+	defer f.Close()
+	return grepReader(f, clauses)
+}
+
+// grepReader is grepFile over an already-open file.
+func grepReader(r io.Reader, clauses []grepClause) bool {
+	matched := make([]bool, len(clauses))
+	br := bufio.NewReaderSize(r, 64*1024)
+	if head, _ := br.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		// Binary: nothing matches, so only negated clauses hold.
+		return clausesHold(clauses, matched)
+	}
+
+	needWhole := false
+	for _, c := range clauses {
+		if c.matcher.multiline {
+			needWhole = true
+			break
+		}
+	}
+
+	if needWhole {
+		content, err := io.ReadAll(br)
+		if err != nil {
+			return clausesHold(clauses, make([]bool, len(clauses)))
+		}
+		for i, c := range clauses {
+			matched[i] = c.matcher.match(content)
+		}
+		return clausesHold(clauses, matched)
+	}
+
+	pending := 0
+	for _, c := range clauses {
+		if !c.negate {
+			pending++
+		}
+	}
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		for i, c := range clauses {
+			if matched[i] || !c.matcher.match(line) {
+				continue
+			}
+			matched[i] = true
+			if c.negate {
+				return false
+			}
+			pending--
+		}
+		if pending == 0 && !hasNegated(clauses) {
+			return true
+		}
+	}
+	if scanner.Err() != nil {
+		return clausesHold(clauses, make([]bool, len(clauses)))
+	}
+	return clausesHold(clauses, matched)
+}
+
+func clausesHold(clauses []grepClause, matched []bool) bool {
+	for i, c := range clauses {
+		if matched[i] == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func hasNegated(clauses []grepClause) bool {
+	for _, c := range clauses {
+		if c.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDirectives reports whether file satisfies the directives: any of
+// their @or-separated clauses must hold (see compose.go).
+func (m *Manager) matchDirectives(file string, directives []SearchDirective) bool {
+	clauses := directiveClauses(directives)
+	if len(clauses) == 0 {
+		return true
+	}
+	for _, clause := range clauses {
+		if m.matchClause(file, clause) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchClause reports whether file satisfies every directive of one clause.
+// Cheap path-based directives run first; all grep directives then share a
+// single streaming read of the file.
+func (m *Manager) matchClause(file string, directives []SearchDirective) bool {
+	var clauses []grepClause
+	for _, d := range directives {
+		name := strings.TrimSuffix(d.Name, "!")
+		if isGrepDirective(name) {
+			clauses = append(clauses, grepClause{
+				matcher: m.grepMatcherFor(name, d.Query),
+				negate:  strings.HasSuffix(d.Name, "!"),
+			})
+			continue
+		}
+		if !m.matchDirective(file, d.Name, d.Query) {
+			return false
+		}
+	}
+	if len(clauses) == 0 {
+		return true
+	}
+	filePath := file
+	if !filepath.IsAbs(file) {
+		filePath = filepath.Join(m.rulesBaseDir, file)
+	}
+	return grepFile(filePath, clauses)
+}
+
+// isGrepDirective reports whether name (without a trailing !) filters on
+// file content.
+func isGrepDirective(name string) bool {
+	return name == "grep" || name == "grep-i" || name == "grep-re"
+}
@@ -0,0 +1,92 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLineSafe(t *testing.T) {
+	cases := map[string]bool{
+		`func \w+Handler`: true,
+		`(?i)todo`:        true,
+		`(?m)^package`:    true,
+		`^package main`:   false, // whole-text anchor
+		`foo\s+bar`:       false, // \s matches newline
+		`a.*b`:            true,  // . excludes newline without (?s)
+		`(?s)a.*b`:        false,
+		`[^;]*`:           false,
+	}
+	for pattern, want := range cases {
+		if got := lineSafe(pattern); got != want {
+			t.Errorf("lineSafe(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+// TestMatchDirectivesSharedRead verifies that several grep directives are
+// evaluated together with AND semantics, including negation, multiline
+// patterns, and binary skip.
+func TestMatchDirectivesSharedRead(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("handler.go", "package api\n\nfunc UserHandler() {}\n// TODO: auth\n")
+	write("model.go", "package api\n\ntype User struct{}\n")
+	write("blob", "func UserHandler\x00\x01\x02")
+
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	cases := []struct {
+		file string
+		ds   []SearchDirective
+		want bool
+	}{
+		{"handler.go", []SearchDirective{{Name: "grep", Query: "Handler"}, {Name: "grep-i", Query: "todo"}}, true},
+		{"handler.go", []SearchDirective{{Name: "grep", Query: "Handler"}, {Name: "grep!", Query: "TODO"}}, false},
+		{"model.go", []SearchDirective{{Name: "grep", Query: "User"}, {Name: "grep!", Query: "Handler"}}, true},
+		{"model.go", []SearchDirective{{Name: "grep", Query: `^package api\n\ntype`}}, true},
+		{"handler.go", []SearchDirective{{Name: "find", Query: "model"}, {Name: "grep", Query: "Handler"}}, false},
+		{"blob", []SearchDirective{{Name: "grep", Query: "UserHandler"}}, false},
+		{"blob", []SearchDirective{{Name: "grep!", Query: "UserHandler"}}, true},
+		{"missing.go", []SearchDirective{{Name: "grep", Query: "x"}}, false},
+	}
+	for _, tc := range cases {
+		if got := m.matchDirectives(tc.file, tc.ds); got != tc.want {
+			t.Errorf("matchDirectives(%s, %+v) = %v, want %v", tc.file, tc.ds, got, tc.want)
+		}
+	}
+}
+
+// TestGrepReDirective verifies that @grep-re: is parsed inline, matches
+// content as a regular expression, and never falls back to a literal match
+// for an invalid expression.
+func TestGrepReDirective(t *testing.T) {
+	base, ds, ok := parseSearchDirectives(`pkg/**/*.go @grep-re: "func \w+Handler\(" @grep-re!: "TODO|FIXME"`)
+	if !ok || base != "pkg/**/*.go" || len(ds) != 2 || ds[0].Name != "grep-re" || ds[1].Name != "grep-re!" {
+		t.Fatalf("parseSearchDirectives = %q, %+v, %v", base, ds, ok)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte("package api\n\nfunc UserHandler() {}\nvar x = \"a(b\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	cases := []struct {
+		ds   []SearchDirective
+		want bool
+	}{
+		{ds, true},
+		{[]SearchDirective{{Name: "grep-re", Query: `^func`}}, false}, // ^ anchors the whole file
+		{[]SearchDirective{{Name: "grep-re", Query: `(?m)^func`}}, true},
+		{[]SearchDirective{{Name: "grep-re", Query: "a(b"}}, false}, // invalid: no literal fallback
+		{[]SearchDirective{{Name: "grep", Query: "a(b"}}, true},
+	}
+	for _, tc := range cases {
+		if got := m.matchDirectives("handler.go", tc.ds); got != tc.want {
+			t.Errorf("matchDirectives(%+v) = %v, want %v", tc.ds, got, tc.want)
+		}
+	}
+}
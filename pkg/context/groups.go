@@ -0,0 +1,236 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/state"
+)
+
+// Named rule groups.
+//
+// Rules can be tagged into groups and switched off without editing the
+// rules file (cx toggle-group). A single line is tagged inline, a run of
+// lines with a block:
+//
+//	internal/**/*_test.go @group: tests
+//
+//	@group: tests {
+//	    testdata/**
+//	    e2e/**
+//	}
+//
+// Groups are on unless disabled; the disabled ones are kept per project in
+// grove state under DisabledGroupsStateKey.
+const (
+	groupDirective = "@group:"
+
+	// DisabledGroupsStateKey is the grove-core state key holding the JSON
+	// list of disabled rule groups.
+	DisabledGroupsStateKey = "cx.disabled_groups"
+)
+
+var (
+	groupNameRegex  = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	inlineGroupTags = regexp.MustCompile(`\s+@group:\s*([A-Za-z0-9_.,-]+)`)
+)
+
+// RuleGroup is a group found in a rules file.
+type RuleGroup struct {
+	Name     string `json:"name"`
+	Rules    int    `json:"rules"`
+	Disabled bool   `json:"disabled"`
+}
+
+// parseGroupBlock parses an `@group: <name> {` line. ok reports whether
+// line opens a group block at all; err describes an invalid name.
+func parseGroupBlock(line string) (name string, ok bool, err error) {
+	if !strings.HasPrefix(line, groupDirective) || !strings.HasSuffix(line, "{") {
+		return "", false, nil
+	}
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, groupDirective), "{"))
+	if !groupNameRegex.MatchString(name) {
+		return name, true, fmt.Errorf("invalid group name %q", name)
+	}
+	return name, true, nil
+}
+
+// splitGroupTags removes inline `@group: a,b` tags from line and returns
+// the named groups.
+func splitGroupTags(line string) (string, []string) {
+	var groups []string
+	for _, m := range inlineGroupTags.FindAllStringSubmatch(line, -1) {
+		for _, g := range strings.Split(m[1], ",") {
+			if g != "" {
+				groups = append(groups, g)
+			}
+		}
+	}
+	if groups == nil {
+		return line, nil
+	}
+	return inlineGroupTags.ReplaceAllString(line, ""), groups
+}
+
+// applyGroups removes group markup from rules content, blanking the rules
+// of disabled groups (and every group directive line) so line numbers are
+// kept. Braces are matched against @with blocks so a `}` closes whichever
+// block is innermost.
+func applyGroups(content []byte, disabled map[string]bool) []byte {
+	if !strings.Contains(string(content), groupDirective) {
+		return content
+	}
+	lines := strings.Split(string(content), "\n")
+	var blocks []string // group name, or withDirective for an @with block
+	off := 0            // enclosing disabled groups
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if name, ok, _ := parseGroupBlock(trimmed); ok {
+			blocks = append(blocks, name)
+			if disabled[name] {
+				off++
+			}
+			lines[i] = ""
+			continue
+		}
+		if trimmed == scopeEnd && len(blocks) > 0 {
+			name := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			if name != withDirective {
+				if disabled[name] {
+					off--
+				}
+				lines[i] = ""
+				continue
+			}
+		} else if _, ok, _ := parseWithBlock(trimmed); ok {
+			blocks = append(blocks, withDirective)
+		}
+
+		rule, groups := splitGroupTags(trimmed)
+		drop := off > 0
+		for _, g := range groups {
+			drop = drop || disabled[g]
+		}
+		switch {
+		case drop && trimmed != scopeEnd && !strings.HasPrefix(trimmed, withDirective):
+			lines[i] = ""
+		case groups != nil:
+			lines[i] = rule
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// RuleGroups lists the groups used in rules content with how many rule
+// lines each tags, sorted by name, marking those in disabled.
+func RuleGroups(content []byte, disabled []string) []RuleGroup {
+	off := make(map[string]bool, len(disabled))
+	for _, d := range disabled {
+		off[d] = true
+	}
+	counts := make(map[string]int)
+	var blocks []string
+	for _, raw := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if name, ok, err := parseGroupBlock(trimmed); ok {
+			if _, seen := counts[name]; err == nil && !seen {
+				counts[name] = 0
+			}
+			if err != nil {
+				name = withDirective
+			}
+			blocks = append(blocks, name)
+			continue
+		}
+		if trimmed == scopeEnd && len(blocks) > 0 {
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+		if _, ok, _ := parseWithBlock(trimmed); ok {
+			blocks = append(blocks, withDirective)
+			continue
+		}
+		_, groups := splitGroupTags(trimmed)
+		seen := make(map[string]bool)
+		for _, g := range append(append([]string(nil), blocks...), groups...) {
+			if g != withDirective && !seen[g] {
+				seen[g] = true
+				counts[g]++
+			}
+		}
+	}
+	groups := make([]RuleGroup, 0, len(counts))
+	for name, n := range counts {
+		groups = append(groups, RuleGroup{Name: name, Rules: n, Disabled: off[name]})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// DisabledGroups returns the rule groups disabled for workDir. Standalone
+// runs have no state, so every group is enabled.
+func DisabledGroups(workDir string) []string {
+	if Standalone() {
+		return nil
+	}
+	raw, err := state.GetString(workDir, DisabledGroupsStateKey)
+	if err != nil || raw == "" {
+		return nil
+	}
+	var groups []string
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil
+	}
+	return groups
+}
+
+// SetGroupEnabled enables or disables the rule group name for workDir.
+func SetGroupEnabled(workDir, name string, enabled bool) error {
+	if Standalone() {
+		return ErrStandalone
+	}
+	if !groupNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid group name %q", name)
+	}
+	var groups []string
+	for _, g := range DisabledGroups(workDir) {
+		if g != name {
+			groups = append(groups, g)
+		}
+	}
+	if !enabled {
+		groups = append(groups, name)
+		sort.Strings(groups)
+	}
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	if err := state.Set(workDir, DisabledGroupsStateKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save disabled groups: %w", err)
+	}
+	return nil
+}
+
+// preprocessRules applies rule groups and @until: expirations to rules
+// content ahead of parsing.
+func (m *Manager) preprocessRules(content []byte) []byte {
+	var disabled map[string]bool
+	if groups := DisabledGroups(m.workDir); len(groups) > 0 {
+		disabled = make(map[string]bool, len(groups))
+		for _, g := range groups {
+			disabled[g] = true
+		}
+	}
+	return applyUntil(applyGroups(content, disabled))
+}
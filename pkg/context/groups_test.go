@@ -0,0 +1,83 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyGroups(t *testing.T) {
+	content := `src/**
+docs/** @group: docs
+@group: tests {
+    testdata/**
+    @with @grep: "x" {
+        e2e/**
+    }
+}
+!vendor/** @group: tests,docs`
+
+	tests := []struct {
+		name     string
+		disabled map[string]bool
+		want     string
+	}{
+		{
+			name: "all enabled",
+			want: "src/**\ndocs/**\n\n    testdata/**\n    @with @grep: \"x\" {\n        e2e/**\n    }\n\n!vendor/**",
+		},
+		{
+			name:     "tests disabled",
+			disabled: map[string]bool{"tests": true},
+			want:     "src/**\ndocs/**\n\n\n    @with @grep: \"x\" {\n\n    }\n\n",
+		},
+		{
+			name:     "docs disabled",
+			disabled: map[string]bool{"docs": true},
+			want:     "src/**\n\n\n    testdata/**\n    @with @grep: \"x\" {\n        e2e/**\n    }\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(applyGroups([]byte(content), tt.disabled)); got != tt.want {
+				t.Errorf("applyGroups() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleGroups(t *testing.T) {
+	content := []byte("src/**\ndocs/** @group: docs\n@group: tests {\n  testdata/**\n  e2e/** @group: docs\n}\n@group: empty {\n}\n")
+	got := RuleGroups(content, []string{"tests"})
+	want := []RuleGroup{
+		{Name: "docs", Rules: 2},
+		{Name: "empty", Rules: 0},
+		{Name: "tests", Rules: 2, Disabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RuleGroups() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSetGroupEnabled(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("GROVE_HOME", t.TempDir())
+
+	if err := SetGroupEnabled(workDir, "tests", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetGroupEnabled(workDir, "docs", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := DisabledGroups(workDir); !reflect.DeepEqual(got, []string{"docs", "tests"}) {
+		t.Errorf("DisabledGroups() = %v, want [docs tests]", got)
+	}
+	if err := SetGroupEnabled(workDir, "tests", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := DisabledGroups(workDir); !reflect.DeepEqual(got, []string{"docs"}) {
+		t.Errorf("DisabledGroups() = %v, want [docs]", got)
+	}
+	if err := SetGroupEnabled(workDir, "bad name", false); err == nil {
+		t.Error("SetGroupEnabled accepted an invalid name")
+	}
+}
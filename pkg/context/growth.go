@@ -0,0 +1,299 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotsDir holds named snapshots written by `cx stats --save-snapshot`.
+const SnapshotsDir = ".grove/snapshots"
+
+// ContextSnapshot records the hot context at a point in time: the rules that
+// produced it and each file's token count, keyed by path relative to the
+// workspace (absolute for files outside it).
+type ContextSnapshot struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Rules     string         `json:"rules"`
+	Files     map[string]int `json:"files"`
+}
+
+// Tokens returns the snapshot's total token count.
+func (s *ContextSnapshot) Tokens() int {
+	total := 0
+	for _, t := range s.Files {
+		total += t
+	}
+	return total
+}
+
+// GrowthSource is one bucket of token change between a snapshot and the
+// current context: a rules line, or a directory.
+type GrowthSource struct {
+	LineNumber  int    `json:"lineNumber,omitempty"`
+	Rule        string `json:"rule,omitempty"`
+	NewRule     bool   `json:"newRule,omitempty"` // the rule is not in the snapshot's rules
+	Directory   string `json:"directory,omitempty"`
+	DeltaTokens int    `json:"deltaTokens"`
+	Files       int    `json:"files"` // files whose tokens changed
+}
+
+// GrowthReport attributes the change in hot-context tokens since a snapshot
+// to the rules lines and directories responsible. Files no rule matches any
+// more are grouped under a bucket with LineNumber 0.
+type GrowthReport struct {
+	BaselineTokens int            `json:"baselineTokens"`
+	CurrentTokens  int            `json:"currentTokens"`
+	DeltaTokens    int            `json:"deltaTokens"`
+	ByRule         []GrowthSource `json:"byRule"`
+	ByDirectory    []GrowthSource `json:"byDirectory"`
+}
+
+// snapshotKey is the path a file is recorded under in a snapshot.
+func (m *Manager) snapshotKey(file string) string {
+	abs := file
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(m.workDir, file)
+	}
+	if rel, err := filepath.Rel(m.workDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return abs
+}
+
+// snapshotOf records files (with current token counts) and rules.
+func (m *Manager) snapshotOf(rules []byte, files []string) *ContextSnapshot {
+	snap := &ContextSnapshot{
+		CreatedAt: time.Now().UTC(),
+		Rules:     string(rules),
+		Files:     make(map[string]int, len(files)),
+	}
+	provider := GetStatsProvider()
+	for _, file := range files {
+		abs := file
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(m.workDir, file)
+		}
+		if info, err := provider.GetFileStats(abs); err == nil {
+			snap.Files[m.snapshotKey(file)] = info.Tokens
+		}
+	}
+	return snap
+}
+
+// TakeSnapshot records the current hot context.
+func (m *Manager) TakeSnapshot() (*ContextSnapshot, error) {
+	rules, _, err := m.LoadRulesContent()
+	if err != nil {
+		return nil, err
+	}
+	files, err := m.ResolveFilesFromRules()
+	if err != nil {
+		return nil, err
+	}
+	return m.snapshotOf(rules, files), nil
+}
+
+// SaveSnapshot writes snap as JSON. A bare name is stored as
+// .grove/snapshots/<name>.json; anything with a path separator or .json
+// suffix is used as the file path. It returns the path written.
+func (m *Manager) SaveSnapshot(snap *ContextSnapshot, ref string) (string, error) {
+	path := m.snapshotPath(ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // snapshot, not sensitive
+}
+
+func (m *Manager) snapshotPath(ref string) string {
+	if strings.ContainsRune(ref, filepath.Separator) || strings.Contains(ref, "/") || strings.HasSuffix(ref, ".json") {
+		if filepath.IsAbs(ref) {
+			return ref
+		}
+		return filepath.Join(m.workDir, ref)
+	}
+	return filepath.Join(m.workDir, SnapshotsDir, ref+".json")
+}
+
+// LoadSnapshot loads the baseline named by ref: a snapshot file, a snapshot
+// saved under .grove/snapshots, or a named rule set (resolved now, so only
+// rule changes show up as growth).
+func (m *Manager) LoadSnapshot(ref string) (*ContextSnapshot, error) {
+	if data, err := os.ReadFile(m.snapshotPath(ref)); err == nil {
+		var snap ContextSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, fmt.Errorf("invalid snapshot %s: %w", ref, err)
+		}
+		return &snap, nil
+	}
+
+	rulesetPath, err := m.FindRulesetFile(m.workDir, ref)
+	if err != nil {
+		return nil, fmt.Errorf("no snapshot or rule set named %q", ref)
+	}
+	rules, err := os.ReadFile(rulesetPath)
+	if err != nil {
+		return nil, err
+	}
+	hot, _, err := m.ResolveFilesFromCustomRulesFile(rulesetPath)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving rule set %q: %w", ref, err)
+	}
+	return m.snapshotOf(rules, hot), nil
+}
+
+// SnapshotInfo summarizes a snapshot saved under .grove/snapshots.
+type SnapshotInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+	Tokens    int       `json:"tokens"`
+}
+
+// ListSnapshots describes the snapshots saved under .grove/snapshots, newest
+// first. Files that are not valid snapshots are skipped.
+func (m *Manager) ListSnapshots() ([]SnapshotInfo, error) {
+	paths, err := filepath.Glob(filepath.Join(m.workDir, SnapshotsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var infos []SnapshotInfo
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var snap ContextSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			continue
+		}
+		infos = append(infos, SnapshotInfo{
+			Name:      strings.TrimSuffix(filepath.Base(path), ".json"),
+			Path:      path,
+			CreatedAt: snap.CreatedAt,
+			Files:     len(snap.Files),
+			Tokens:    snap.Tokens(),
+		})
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.After(infos[j].CreatedAt)
+	})
+	return infos, nil
+}
+
+// AttributeGrowth compares the current hot context with base and attributes
+// each file's token change to the rules line that now includes it and to its
+// top-level directory (two path segments deep). Buckets are sorted by the
+// size of their change, largest growth first.
+func (m *Manager) AttributeGrowth(base *ContextSnapshot) (*GrowthReport, error) {
+	current, err := m.TakeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	attribution, _, _, _, _, err := m.ResolveFilesWithAttribution(current.Rules)
+	if err != nil {
+		return nil, err
+	}
+	lineOf := make(map[string]int)
+	for line, files := range attribution {
+		for _, f := range files {
+			lineOf[m.snapshotKey(f)] = line
+		}
+	}
+
+	baseRules := make(map[string]bool)
+	for _, line := range strings.Split(base.Rules, "\n") {
+		if rule, _ := SplitRuleAnnotation(strings.TrimSpace(line)); rule != "" {
+			baseRules[rule] = true
+		}
+	}
+	currentLines := strings.Split(current.Rules, "\n")
+
+	report := &GrowthReport{
+		BaselineTokens: base.Tokens(),
+		CurrentTokens:  current.Tokens(),
+	}
+	report.DeltaTokens = report.CurrentTokens - report.BaselineTokens
+
+	byRule := make(map[int]*GrowthSource)
+	byDir := make(map[string]*GrowthSource)
+	add := func(file string, delta int) {
+		line := lineOf[file]
+		rs, ok := byRule[line]
+		if !ok {
+			rs = &GrowthSource{LineNumber: line}
+			if line > 0 && line <= len(currentLines) {
+				rs.Rule, _ = SplitRuleAnnotation(strings.TrimSpace(currentLines[line-1]))
+				rs.NewRule = !baseRules[rs.Rule]
+			}
+			byRule[line] = rs
+		}
+		rs.DeltaTokens += delta
+		rs.Files++
+
+		dir := growthDirectory(file)
+		ds, ok := byDir[dir]
+		if !ok {
+			ds = &GrowthSource{Directory: dir}
+			byDir[dir] = ds
+		}
+		ds.DeltaTokens += delta
+		ds.Files++
+	}
+	for file, tokens := range current.Files {
+		if delta := tokens - base.Files[file]; delta != 0 {
+			add(file, delta)
+		}
+	}
+	for file, tokens := range base.Files {
+		if _, ok := current.Files[file]; !ok && tokens != 0 {
+			lineOf[file] = 0 // no longer in the hot context
+			add(file, -tokens)
+		}
+	}
+
+	for _, s := range byRule {
+		report.ByRule = append(report.ByRule, *s)
+	}
+	for _, s := range byDir {
+		report.ByDirectory = append(report.ByDirectory, *s)
+	}
+	sortGrowth(report.ByRule)
+	sortGrowth(report.ByDirectory)
+	return report, nil
+}
+
+// growthDirectory buckets a snapshot path by its first two directories.
+func growthDirectory(file string) string {
+	dir := filepath.ToSlash(filepath.Dir(file))
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	prefix := ""
+	if strings.HasPrefix(dir, "/") {
+		prefix = "/"
+	}
+	return prefix + strings.Join(parts, "/") + "/"
+}
+
+func sortGrowth(sources []GrowthSource) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		if sources[i].DeltaTokens != sources[j].DeltaTokens {
+			return sources[i].DeltaTokens > sources[j].DeltaTokens
+		}
+		return sources[i].LineNumber < sources[j].LineNumber
+	})
+}
@@ -0,0 +1,102 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttributeGrowth(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("web/app.go", "package web\n")
+	write("services/api/big.go", "package api\n"+strings.Repeat("// padding padding padding\n", 200))
+	write(".grove/rules", "web/*.go\n")
+
+	m := NewManager(dir, WithNoState())
+	base, err := m.TakeSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SaveSnapshot(base, "monday"); err != nil {
+		t.Fatal(err)
+	}
+
+	write(".grove/rules", "web/*.go\nservices/api/**\n")
+	m = NewManager(dir, WithNoState())
+	loaded, err := m.LoadSnapshot("monday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := m.AttributeGrowth(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.DeltaTokens <= 0 || report.CurrentTokens-report.BaselineTokens != report.DeltaTokens {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+	if len(report.ByRule) != 1 {
+		t.Fatalf("expected growth from one rule, got %+v", report.ByRule)
+	}
+	top := report.ByRule[0]
+	if top.LineNumber != 2 || top.Rule != "services/api/**" || !top.NewRule || top.DeltaTokens != report.DeltaTokens {
+		t.Errorf("unexpected attribution: %+v", top)
+	}
+	if len(report.ByDirectory) != 1 || report.ByDirectory[0].Directory != "services/api/" {
+		t.Errorf("unexpected directories: %+v", report.ByDirectory)
+	}
+}
+
+func TestGrowthDirectory(t *testing.T) {
+	for file, want := range map[string]string{
+		"main.go":                 ".",
+		"cmd/main.go":             "cmd/",
+		"services/api/v1/x.go":    "services/api/",
+		"/opt/shared/lib/util.go": "/opt/shared/",
+	} {
+		if got := growthDirectory(file); got != want {
+			t.Errorf("growthDirectory(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, WithNoState())
+	if infos, err := m.ListSnapshots(); err != nil || len(infos) != 0 {
+		t.Fatalf("ListSnapshots with no snapshots = %v, %v", infos, err)
+	}
+
+	older := &ContextSnapshot{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Files: map[string]int{"a.go": 10}}
+	newer := &ContextSnapshot{CreatedAt: older.CreatedAt.Add(time.Hour), Files: map[string]int{"a.go": 10, "b.go": 5}}
+	for name, snap := range map[string]*ContextSnapshot{"monday": older, "tuesday": newer} {
+		if _, err := m.SaveSnapshot(snap, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, SnapshotsDir, "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := m.ListSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name != "tuesday" || infos[1].Name != "monday" {
+		t.Fatalf("ListSnapshots = %+v, want tuesday then monday", infos)
+	}
+	if infos[0].Files != 2 || infos[0].Tokens != 15 {
+		t.Errorf("tuesday = %+v, want 2 files and 15 tokens", infos[0])
+	}
+}
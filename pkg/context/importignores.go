@@ -0,0 +1,113 @@
+package context
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFiles are the ignore files `cx import-ignores` reads, in order. All
+// but .dockerignore use gitignore semantics; .dockerignore patterns are
+// always relative to the root.
+var IgnoreFiles = []string{".gitignore", ".dockerignore", ".eslintignore", ".prettierignore"}
+
+// ImportedIgnore is one pattern from an ignore file and the cx exclusion it
+// translates to. Rule is empty when the pattern was skipped, with Reason
+// saying why.
+type ImportedIgnore struct {
+	Source  string `json:"source"`
+	Line    int    `json:"line"`
+	Pattern string `json:"pattern"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ImportIgnoreRules translates the working directory's ignore files into cx
+// exclusion rules. Patterns already covered by the default exclusions (the
+// junk directories and .git/.grove), by a line of the active rules file, or
+// by an earlier ignore file are reported as skipped rather than repeated.
+func (m *Manager) ImportIgnoreRules() []ImportedIgnore {
+	seen := make(map[string]string)
+	if path := m.findActiveRulesFile(); path != "" {
+		if content, err := os.ReadFile(path); err == nil {
+			for _, line := range strings.Split(string(content), "\n") {
+				seen[strings.TrimSpace(line)] = "already in the rules file"
+			}
+		}
+	}
+
+	var imported []ImportedIgnore
+	for _, name := range IgnoreFiles {
+		data, err := os.ReadFile(filepath.Join(m.workDir, name))
+		if err != nil {
+			continue
+		}
+		anchored := name == ".dockerignore"
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			pattern := strings.TrimSpace(scanner.Text())
+			if pattern == "" || strings.HasPrefix(pattern, "#") {
+				continue
+			}
+			entry := ImportedIgnore{Source: name, Line: lineNum, Pattern: pattern}
+			rule, reason := m.translateIgnorePattern(pattern, anchored)
+			switch {
+			case reason != "":
+				entry.Reason = reason
+			case seen[rule] != "":
+				entry.Reason = seen[rule]
+			default:
+				entry.Rule = rule
+				seen[rule] = "duplicate of " + name + " pattern"
+			}
+			imported = append(imported, entry)
+		}
+	}
+	return imported
+}
+
+// translateIgnorePattern turns one ignore-file pattern into a `!` exclusion
+// rule, or returns a reason it has no useful translation. A slash-free
+// pattern matches at any depth in gitignore, as it does in cx; a pattern
+// with a leading or inner slash is relative to the root, and one naming a
+// directory (a trailing slash, or an existing directory) excludes its
+// contents.
+func (m *Manager) translateIgnorePattern(pattern string, anchored bool) (string, string) {
+	if strings.HasPrefix(pattern, "!") {
+		return "", "negated pattern (re-includes files); review by hand"
+	}
+	pattern = strings.TrimPrefix(pattern, `\`)
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "/") {
+		anchored = true
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok && !strings.Contains(rest, "/") {
+		pattern, anchored = rest, false
+	}
+	if pattern == "" || pattern == "*" || pattern == "**" {
+		return "", "matches everything"
+	}
+
+	if !strings.Contains(pattern, "/") && (isJunkDir(pattern) || pattern == ".git" || pattern == GroveDir || pattern == ".grove-worktrees") {
+		return "", "excluded by default"
+	}
+
+	if !dirOnly && !strings.ContainsAny(pattern, "*?[") && !strings.HasSuffix(pattern, "/**") {
+		if info, err := os.Stat(filepath.Join(m.workDir, pattern)); err == nil && info.IsDir() {
+			dirOnly = true
+		}
+	}
+	switch {
+	case !anchored && dirOnly:
+		return "!**/" + pattern + "/**", ""
+	case !anchored:
+		return "!" + pattern, ""
+	case dirOnly:
+		return "!" + pattern + "/**", ""
+	default:
+		return "!" + pattern, ""
+	}
+}
@@ -0,0 +1,57 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportIgnoreRules(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "coverage"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fsWriteString(t, filepath.Join(dir, ".gitignore"), `# build output
+node_modules/
+*.log
+build/
+/coverage
+docs/generated/
+!keep.log
+\#notes
+`)
+	fsWriteString(t, filepath.Join(dir, ".dockerignore"), "*.log\ntmp\n.git\n")
+	fsWriteString(t, filepath.Join(dir, ActiveRulesFile), "*.go\n!**/build/**\n")
+
+	m := &Manager{workDir: dir}
+	got := make(map[string]ImportedIgnore)
+	for _, entry := range m.ImportIgnoreRules() {
+		got[entry.Source+" "+entry.Pattern] = entry
+	}
+
+	for key, want := range map[string]string{
+		".gitignore *.log":           "!*.log",
+		".gitignore /coverage":       "!coverage/**",
+		".gitignore docs/generated/": "!docs/generated/**",
+		`.gitignore \#notes`:         "!#notes",
+		".dockerignore tmp":          "!tmp",
+		".gitignore node_modules/":   "",
+		".gitignore build/":          "",
+		".gitignore !keep.log":       "",
+		".dockerignore *.log":        "",
+		".dockerignore .git":         "",
+	} {
+		entry, ok := got[key]
+		if !ok {
+			t.Errorf("%s: pattern not reported", key)
+			continue
+		}
+		if entry.Rule != want {
+			t.Errorf("%s: rule = %q (%s), want %q", key, entry.Rule, entry.Reason, want)
+		}
+		if want == "" && entry.Reason == "" {
+			t.Errorf("%s: skipped without a reason", key)
+		}
+	}
+}
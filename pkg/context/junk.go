@@ -1,7 +1,6 @@
 package context
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -95,8 +94,7 @@ func warnOversizedRules(rules []RuleInfo, attr AttributionResult) {
 		if count <= oversizeFileThreshold && totalBytes <= oversizeByteThreshold {
 			continue
 		}
-		fmt.Fprintf(os.Stderr,
-			"Warning: rule '%s' (line %d) expanded to %d files (%s) — exceeds the %d-file / %s guard; narrow the glob or add an exclusion\n",
+		Warnf("rule '%s' (line %d) expanded to %d files (%s) — exceeds the %d-file / %s guard; narrow the glob or add an exclusion",
 			info.pattern, info.lineNum, count, FormatBytes(int(totalBytes)),
 			oversizeFileThreshold, FormatBytes(oversizeByteThreshold))
 	}
@@ -7,7 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // LintIssue represents a single issue found in the rules file.
@@ -16,6 +19,7 @@ type LintIssue struct {
 	Line     string
 	Severity string
 	Message  string
+	Fix      string // suggested fix, when there is an obvious one
 }
 
 var validDirectives = map[string]bool{
@@ -25,8 +29,15 @@ var validDirectives = map[string]bool{
 	"@default": true, "@concept": true,
 	"@freeze-cache": true, "@no-expire": true,
 	"@disable-cache": true, "@expire-time": true,
-	"@include": true, "@changed": true, "@diff": true, "@tree": true,
-	"@find!": true, "@grep!": true, "@grep-i": true, "@recent": true,
+	"@include": true, "@changed": true, "@diff": true, "@git": true,
+	"@tasks": true, "@tree": true, "@tree-only": true, "@pkg": true, "@fixtures": true,
+	"@find!": true, "@grep!": true, "@grep-i": true, "@grep-re": true, "@grep-re!": true, "@recent": true,
+	"@require": true, "@max-age": true, "@budget": true, "@allow-path": true, "@and": true, "@or": true,
+	"@hot-transform": true, "@cold-transform": true, "@symbol-index": true,
+	"@newer-than": true, "@older-than": true, "@size": true, "@executable": true, "@owner-uid": true,
+	"@text": true, "@binary": true,
+	"@any-of": true, "@all-of": true,
+	"@with": true, "@clear-filters": true, "@until": true, "@group": true,
 }
 
 var directiveRegex = regexp.MustCompile(`@[a-zA-Z][a-zA-Z0-9-]*!?`)
@@ -69,17 +80,79 @@ func (m *Manager) lintRulesContent(content []byte) ([]LintIssue, error) {
 		}
 		for _, tok := range directiveRegex.FindAllString(trimmed, -1) {
 			if !validDirectives[tok] {
-				issues = append(issues, LintIssue{
+				issue := LintIssue{
 					LineNum:  lineNum,
 					Line:     trimmed,
 					Severity: "Warning",
 					Message:  fmt.Sprintf("Unrecognized directive '%s' - possible typo", tok),
-				})
+				}
+				if suggestion := closestDirective(tok); suggestion != "" {
+					issue.Fix = fmt.Sprintf("did you mean '%s'?", suggestion)
+				}
+				issues = append(issues, issue)
 			}
 		}
 	}
 
-	nodes, parseErrs := ParseToAST(content)
+	for _, req := range parseRequireDirectives(content) {
+		if _, err := os.Stat(m.requirePathKey(req.Path)); os.IsNotExist(err) {
+			issues = append(issues, LintIssue{
+				LineNum:  req.LineNum,
+				Line:     "@require: " + req.Path,
+				Severity: "Error",
+				Message:  fmt.Sprintf("Required file '%s' does not exist", req.Path),
+			})
+		}
+	}
+
+	if d := parseBudgetDirective(content); d != nil && d.Err != nil {
+		issues = append(issues, LintIssue{
+			LineNum:  d.LineNum,
+			Line:     "@budget: " + d.Raw,
+			Severity: "Error",
+			Message:  fmt.Sprintf("Invalid @budget: %q: %v", d.Raw, d.Err),
+		})
+	}
+
+	for _, d := range parseTierTransformDirectives(content) {
+		if len(d.Unknown) > 0 {
+			issues = append(issues, LintIssue{
+				LineNum:  d.LineNum,
+				Line:     fmt.Sprintf("@%s-transform: %s", d.Tier, strings.Join(append(d.Transforms, d.Unknown...), ", ")),
+				Severity: "Warning",
+				Message:  fmt.Sprintf("Unknown %s transform(s) %s; expected %s or %s", d.Tier, strings.Join(d.Unknown, ", "), TransformStripComments, TransformCollapseBlankLines),
+			})
+		}
+	}
+
+	now := time.Now()
+	scanner = bufio.NewScanner(bytes.NewReader(content))
+	lineNum = 0
+	for scanner.Scan() {
+		lineNum++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if _, ok, err := parseGroupBlock(trimmed); ok && err != nil {
+			issues = append(issues, LintIssue{LineNum: lineNum, Line: trimmed, Severity: "Error", Message: err.Error()})
+		}
+		_, until, ok, err := splitUntil(trimmed)
+		switch {
+		case !ok:
+		case err != nil:
+			issues = append(issues, LintIssue{LineNum: lineNum, Line: trimmed, Severity: "Error", Message: err.Error()})
+		case untilExpired(until, now):
+			issues = append(issues, LintIssue{
+				LineNum:  lineNum,
+				Line:     trimmed,
+				Severity: "Warning",
+				Message:  fmt.Sprintf("Rule expired on %s and is ignored; remove it with 'cx rules prune'", until.Format(untilDateLayout)),
+			})
+		}
+	}
+
+	nodes, parseErrs := ParseToAST(stripUntil(applyGroups(content, nil), true))
 
 	for _, pe := range parseErrs {
 		issues = append(issues, LintIssue{
@@ -88,6 +161,10 @@ func (m *Manager) lintRulesContent(content []byte) ([]LintIssue, error) {
 			Message:  pe.Msg,
 		})
 	}
+	if len(parseErrs) == 0 {
+		issues = append(issues, m.lintDuplicateRules(content)...)
+		issues = append(issues, m.lintSupersededRules(content)...)
+	}
 
 	for _, n := range nodes {
 		raw := strings.TrimSpace(n.Raw())
@@ -100,16 +177,26 @@ func (m *Manager) lintRulesContent(content []byte) ([]LintIssue, error) {
 			issues = appendPatternIssues(issues, m, node.ExpectedPath, raw, line)
 		case *FilterNode:
 			for _, d := range node.Directives {
-				if d.Name == "grep" || d.Name == "grep!" || d.Name == "grep-i" {
+				if isGrepDirective(strings.TrimSuffix(d.Name, "!")) {
 					if _, err := regexp.Compile(d.Query); err != nil {
+						// @grep-re: has no literal fallback.
+						severity := "Warning"
+						if strings.HasPrefix(d.Name, "grep-re") {
+							severity = "Error"
+						}
 						issues = append(issues, LintIssue{
 							LineNum:  line,
 							Line:     raw,
-							Severity: "Warning",
+							Severity: severity,
 							Message:  fmt.Sprintf("Invalid regex in @%s directive: %s", d.Name, err),
 						})
 					}
 				}
+				if metadataDirectives[d.Name] {
+					if err := validateMetadataDirective(d.Name, d.Query); err != nil {
+						issues = append(issues, LintIssue{LineNum: line, Line: raw, Severity: "Error", Message: err.Error()})
+					}
+				}
 			}
 			switch child := node.Child.(type) {
 			case *GlobNode:
@@ -134,6 +221,7 @@ func appendPatternIssues(issues []LintIssue, m *Manager, pattern, raw string, li
 			Line:     raw,
 			Severity: "Error",
 			Message:  fmt.Sprintf("Pattern '%s' attempts to traverse outside the workspace", pattern),
+			Fix:      "reference the other project with an @a: alias, or grant the directory with @allow-path:",
 		})
 	}
 
@@ -143,6 +231,7 @@ func appendPatternIssues(issues []LintIssue, m *Manager, pattern, raw string, li
 			Line:     raw,
 			Severity: "Warning",
 			Message:  "Pattern is overly broad and may match too many files",
+			Fix:      "narrow it to the directories you need, e.g. 'src/**/*.go'",
 		})
 	}
 
@@ -171,12 +260,165 @@ func appendPatternIssues(issues []LintIssue, m *Manager, pattern, raw string, li
 			Line:     raw,
 			Severity: "Warning",
 			Message:  "Pattern matches 0 files in the workspace",
+			Fix:      "check the path for typos, or remove the line",
 		})
 	}
 
 	return issues
 }
 
+// lintDuplicateRules reports rules listed more than once. A rule repeated in
+// the cold section is a warning: cold wins, so its hot line includes nothing.
+func (m *Manager) lintDuplicateRules(content []byte) []LintIssue {
+	parsed, err := m.parseRulesFileContent(content)
+	if err != nil {
+		return nil
+	}
+	ruleKey := func(r RuleInfo) string {
+		key := encodeDirectives(r.Pattern, r.Directives)
+		if r.IsExclude {
+			key = "!" + key
+		}
+		return key
+	}
+	lines := strings.Split(string(content), "\n")
+	lineText := func(n int) string {
+		if n < 1 || n > len(lines) {
+			return ""
+		}
+		return strings.TrimSpace(lines[n-1])
+	}
+
+	var issues []LintIssue
+	firstHot := make(map[string]int)
+	for _, r := range parsed.hotRules {
+		if r.LineNum == 0 {
+			continue
+		}
+		key := ruleKey(r)
+		if first, ok := firstHot[key]; ok && first != r.LineNum {
+			issues = append(issues, LintIssue{
+				LineNum:  r.LineNum,
+				Line:     lineText(r.LineNum),
+				Severity: "Notice",
+				Message:  fmt.Sprintf("Duplicate of line %d", first),
+				Fix:      "remove this line",
+			})
+			continue
+		}
+		firstHot[key] = r.LineNum
+	}
+	firstCold := make(map[string]int)
+	for _, r := range parsed.coldRules {
+		if r.LineNum == 0 {
+			continue
+		}
+		key := ruleKey(r)
+		if first, ok := firstCold[key]; ok && first != r.LineNum {
+			issues = append(issues, LintIssue{
+				LineNum:  r.LineNum,
+				Line:     lineText(r.LineNum),
+				Severity: "Notice",
+				Message:  fmt.Sprintf("Duplicate of line %d", first),
+				Fix:      "remove this line",
+			})
+			continue
+		}
+		firstCold[key] = r.LineNum
+		if hot, ok := firstHot[key]; ok && !r.IsExclude {
+			issues = append(issues, LintIssue{
+				LineNum:  hot,
+				Line:     lineText(hot),
+				Severity: "Warning",
+				Message:  fmt.Sprintf("Rule is also in the cold section (line %d); cold wins, so this line adds nothing to hot", r.LineNum),
+				Fix:      fmt.Sprintf("keep it in one section: remove line %d or line %d", hot, r.LineNum),
+			})
+		}
+	}
+	return issues
+}
+
+// lintSupersededRules reports lines that match files but contribute none,
+// because a later line claims every one of them (last match wins).
+func (m *Manager) lintSupersededRules(content []byte) []LintIssue {
+	attribution, rules, _, filtered, _, err := m.ResolveFilesWithAttribution(string(content))
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(content), "\n")
+	rootLines := make(map[int]bool)
+	for _, r := range rules {
+		if r.LineNum == r.EffectiveLineNum {
+			rootLines[r.LineNum] = true
+		}
+	}
+
+	var issues []LintIssue
+	for lineNum, infos := range filtered {
+		if len(attribution[lineNum]) > 0 || !rootLines[lineNum] || lineNum > len(lines) {
+			continue
+		}
+		seen := make(map[int]bool)
+		var winners []int
+		for _, info := range infos {
+			if info.WinningLineNum != lineNum && !seen[info.WinningLineNum] {
+				seen[info.WinningLineNum] = true
+				winners = append(winners, info.WinningLineNum)
+			}
+		}
+		if len(winners) == 0 {
+			continue
+		}
+		sort.Ints(winners)
+		var winning []string
+		for _, n := range winners {
+			winning = append(winning, strconv.Itoa(n))
+		}
+		issues = append(issues, LintIssue{
+			LineNum:  lineNum,
+			Line:     strings.TrimSpace(lines[lineNum-1]),
+			Severity: "Warning",
+			Message:  fmt.Sprintf("Unreachable: all %d matched file(s) are claimed by line(s) %s", len(infos), strings.Join(winning, ", ")),
+			Fix:      "remove this line, or merge it into the later rule",
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].LineNum < issues[j].LineNum })
+	return issues
+}
+
+// closestDirective returns the known directive nearest to an unrecognized
+// one, or "" when none is close enough to be a likely typo.
+func closestDirective(tok string) string {
+	best, bestDist := "", 3
+	for d := range validDirectives {
+		if dist := editDistance(tok, d); dist < bestDist || (dist == bestDist && best != "" && d < best) {
+			best, bestDist = d, dist
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
 func FormatLintIssue(issue LintIssue) string {
 	return fmt.Sprintf("[%s] Line %d: %s", issue.Severity, issue.LineNum, issue.Message)
 }
@@ -1,6 +1,8 @@
 package context
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -91,3 +93,44 @@ func TestLintIssuesByLine(t *testing.T) {
 		t.Errorf("expected 1 issue on line 5, got %d", len(byLine[5]))
 	}
 }
+
+func TestClosestDirective(t *testing.T) {
+	for tok, want := range map[string]string{
+		"@grpe":    "@grep",
+		"@requre":  "@require",
+		"@xyzzy42": "",
+	} {
+		if got := closestDirective(tok); got != want {
+			t.Errorf("closestDirective(%q) = %q, want %q", tok, got, want)
+		}
+	}
+}
+
+// TestLintDuplicateRules verifies that repeated rules are reported, within a
+// section and across the hot and cold sections.
+func TestLintDuplicateRules(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "a.go"), "package a\n")
+	fsWriteString(t, filepath.Join(dir, "b.go"), "package a\n")
+	m := NewManager(dir)
+
+	issues, err := m.lintRulesContent([]byte("a.go\n*.go\na.go\n---\nb.go\n*.go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLine := LintIssuesByLine(issues)
+	has := func(line int, substr string) bool {
+		for _, issue := range byLine[line] {
+			if strings.Contains(issue.Message, substr) && issue.Fix != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if !has(3, "Duplicate of line 1") {
+		t.Errorf("line 3 should be reported as a duplicate: %+v", byLine[3])
+	}
+	if !has(2, "also in the cold section (line 6)") {
+		t.Errorf("line 2 should be reported as duplicated in cold: %+v", byLine[2])
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/grovetools/core/config"
 	grovelogging "github.com/grovetools/core/logging"
@@ -72,25 +74,61 @@ type Manager struct {
 	rulesBaseDir      string                     // Base directory for resolving relative patterns in rules files
 	rulesFileOverride string                     // Instance-level override for rules file path (absolute)
 	locator           *workspace.NotebookLocator // Notebook locator for centralized context paths
-	gitIgnoredCache   map[string]map[string]bool // Cache for gitignored files by repository root
+	gitIgnoredCache   map[string]gitIgnoredEntry // Cache for gitignored files by repository root
 	gitIgnoredMutex   sync.RWMutex               // Mutex to protect gitIgnoredCache
 	changedFilesCache map[string]map[string]bool // Cache for changed files by git ref
 	changedFilesMutex sync.Mutex                 // Mutex to protect changedFilesCache
 	aliasResolver     *alias.AliasResolver       // Lazily initialized alias resolver
 	allowedRoots      []string
+	rootOrigins       map[string]string // allowed root -> why it is allowed; see ExplainPathAccess
+	grantedRoots      map[string]string // trusted @allow-path root -> requesting rules file; see allowpath.go
+	allowPathWarned   map[string]bool   // Untrusted @allow-path requests already warned about
+	allowPathMu       sync.Mutex        // Protects grantedRoots and allowPathWarned
 	allowedRootsErr   error
+	discoveryErr      error // Set when discovery failed and aliasResolver is degraded; see degraded.go
 	rootsOnce         sync.Once
-	skippedRules      []SkippedRule   // Rules that were skipped during parsing with reasons
-	skippedMutex      sync.Mutex      // Protects skippedRules
-	aliasNotices      map[string]bool // Dedup set for cross-worktree @a: root notices (one per alias)
-	aliasNoticeMutex  sync.Mutex      // Protects aliasNotices
-	aliasWorkDir      string          // Optional override rooting alias resolution (job worktree: frontmatter)
+	skippedRules      []SkippedRule    // Rules that were skipped during parsing with reasons
+	skippedMutex      sync.Mutex       // Protects skippedRules, skippedTotal, cmdFailures and artifactHits
+	skippedTotal      int              // Rules skipped over the manager's lifetime; see expandAllRules
+	cmdFailures       []CommandFailure // Failed @cmd: rules; see cmdfailures.go
+	artifactHits      []string         // cx artifacts dropped from resolution; see ownartifacts.go
+	strictCommands    bool             // Fail generation on @cmd: failures
+	maxTokens         int              // Token budget overriding @budget:; see budget.go
+	stream            io.Writer        // Hot context destination while streaming; see stream.go
+	outputFormat      string           // Overrides cx.output_format; see outputformat.go
+	aliasNotices      map[string]bool  // Dedup set for cross-worktree @a: root notices (one per alias)
+	aliasNoticeMutex  sync.Mutex       // Protects aliasNotices
+	aliasWorkDir      string           // Optional override rooting alias resolution (job worktree: frontmatter)
 	log               *logrus.Entry
 	ulog              *grovelogging.UnifiedLogger
 	daemonClient      daemon.Client // Lazily initialized connect-only client; see getDaemonClient
 	daemonClientOnce  sync.Once     // Guards daemonClient initialization
 	ctxMu             sync.RWMutex
 	ctx               gocontext.Context
+	grepMatchers      sync.Map                   // directive+query -> *grepMatcher
+	genMu             sync.Mutex                 // Protects lastGeneration and budgetTrim
+	budgetTrim        *BudgetTrim                // Files the token budget dropped; see budget.go
+	lastGeneration    GenerationSummary          // Size of the most recent generation; see metrics.go
+	lastGenerated     map[string][]string        // Files of the most recent generation by tier; see watchset.go
+	accessMu          sync.Mutex                 // Serializes access log updates; see access.go
+	expandMemo        map[string]expandMemoEntry // Memoized rules expansions; see expandmemo.go
+	expandMemoMu      sync.Mutex                 // Protects expandMemo
+	strictWalk        bool                       // Fail fast on walk errors; see walkerrors.go
+	walkWarnings      []WalkWarning              // Entries walks skipped
+	walkSeen          map[string]bool            // Paths already in walkWarnings
+	walkMu            sync.Mutex                 // Protects walkWarnings and walkSeen
+	walkIndexes       map[string]*walkIndex      // Recorded walks by root; see walkindex.go
+	walkIndexMu       sync.Mutex                 // Protects walkIndexes and their pattern matches
+	gitSlots          chan struct{}              // Running git subprocesses; see concurrency.go
+	gitSlotsOnce      sync.Once                  // Sizes gitSlots
+	cacheStats        cacheCounters              // Cache hits and misses; see bench.go
+	configStamp       string                     // configFingerprint when built; see configwatch.go
+	configCheckedAt   time.Time                  // Last configChanged check
+	configMu          sync.Mutex                 // Protects configStamp and configCheckedAt
+	artifactName      string                     // Artifact name template; see artifactname.go
+	artifactNameOnce  sync.Once                  // Guards artifactName
+	safety            *contentScreen             // Content safety settings; see safety.go
+	safetyOnce        sync.Once                  // Guards safety
 
 	// Job-scoped output path overrides. When non-empty, the corresponding
 	// Resolve*Path / Resolve*WritePath methods return these absolute paths
@@ -114,6 +152,40 @@ type Manager struct {
 	// exclusively (a fresh NewManagerWithPathsOverride), never on a shared
 	// cached Manager.
 	stripComments bool
+
+	// onlyPatterns, when non-empty, limits generation to re-rendering files
+	// matching these patterns and splices the rest from the existing
+	// artifact (cx generate --only; see splice.go). Plain state like
+	// stripComments.
+	onlyPatterns []string
+
+	// transforms are the tier transforms (@hot-transform:, @cold-transform:)
+	// of the artifact being written (see transforms.go). explicitRulesPath
+	// and explicitRules, while GenerateContextFromRulesFile runs, are the
+	// rules being generated from instead of the active rules, for the
+	// transforms and the cache provenance (see cacheinfo.go). Plain state
+	// like stripComments.
+	transforms        []string
+	explicitRulesPath string
+	explicitRules     []byte
+
+	// provenance turns on rule provenance comments; fileProvenance is the
+	// attribution of the artifact being written (see provenance.go).
+	provenance     bool
+	fileProvenance *ruleProvenance
+
+	// Library-mode inputs (see options.go). noState cuts every read of
+	// grove config, state, plans, notebooks and workspace discovery;
+	// suppliedRules, when hasSuppliedRules is set, replaces the active rules
+	// file. Fixed at construction, so safe to read without locking.
+	noState          bool
+	suppliedRules    []byte
+	hasSuppliedRules bool
+
+	// checksums, when true, writes a sha256 sidecar next to every generated
+	// artifact so consumers can detect hand-edited or truncated blobs (see
+	// checksum.go). Same concurrency caveat as stripComments.
+	checksums bool
 }
 
 // SetPathsOverride forces the generated/cached context output (and the
@@ -179,16 +251,26 @@ func (m *Manager) Context() gocontext.Context {
 var managerCache sync.Map
 
 // ClearManagerCache evicts all cached Manager instances. Intended for
-// tests and long-running processes that need to re-read config after
-// a grove.toml change.
+// tests, and for long-running processes that must see a grove config
+// change immediately; otherwise cached instances are replaced on their own
+// within configCheckInterval of a change (see configChanged).
 func ClearManagerCache() {
 	managerCache = sync.Map{}
+	resetTokenRatios()
 }
 
 // NewManager creates (or returns a cached) context manager for the
 // given workDir. Instances are memoized by absolute workDir — see
-// managerCache.
-func NewManager(workDir string) *Manager {
+// managerCache. With options (WithRules, WithNoState, WithAllowedRoots) a
+// fresh, uncached instance is returned instead, and standalone runs always
+// get a fresh WithNoState instance (see standalone.go).
+func NewManager(workDir string, opts ...ManagerOption) *Manager {
+	if len(opts) == 0 && Standalone() {
+		return newStandaloneManager(workDir)
+	}
+	if len(opts) > 0 {
+		return newManagerWithOptions(workDir, opts)
+	}
 	return NewManagerWithOverride(workDir, "")
 }
 
@@ -209,10 +291,27 @@ func NewManagerWithOverride(workDir, rulesFileOverride string) *Manager {
 	}
 
 	if cached, ok := managerCache.Load(cacheKey); ok {
-		return cached.(*Manager)
-	}
-
-	mgr := newManagerInstance(workDir, rulesFileOverride)
+		if !cached.(*Manager).configChanged() {
+			return cached.(*Manager)
+		}
+		// grove config changed since the cached instance was built: replace
+		// it, so allowed roots, workspace filters, alias resolution and the
+		// notebook locator are rebuilt from the new config, and token ratios
+		// are reread. Callers still holding the old instance keep using it
+		// undisturbed.
+		resetTokenRatios()
+		mgr := newStampedManager(workDir, rulesFileOverride)
+		mgr.ulog.Info("Grove config changed; reloading").
+			Field("workDir", workDir).
+			Log(gocontext.Background())
+		if managerCache.CompareAndSwap(cacheKey, cached, mgr) {
+			return mgr
+		}
+		actual, _ := managerCache.Load(cacheKey)
+		return actual.(*Manager)
+	}
+
+	mgr := newStampedManager(workDir, rulesFileOverride)
 	// LoadOrStore resolves the rare race where two goroutines miss the
 	// cache simultaneously — the first to store wins, and every caller
 	// returns the same Manager instance.
@@ -220,6 +319,16 @@ func NewManagerWithOverride(workDir, rulesFileOverride string) *Manager {
 	return actual.(*Manager)
 }
 
+// newStampedManager is newManagerInstance for the manager cache: it records
+// the config fingerprint first, so an edit made while the config loads is
+// still picked up by the next configChanged check.
+func newStampedManager(workDir, rulesFileOverride string) *Manager {
+	stamp := configFingerprint(workDir)
+	mgr := newManagerInstance(workDir, rulesFileOverride)
+	mgr.configStamp, mgr.configCheckedAt = stamp, time.Now()
+	return mgr
+}
+
 // NewManagerWithPathsOverride returns a fresh, UNCACHED Manager whose
 // generated/cached context output paths are pinned to the given job-scoped
 // absolute paths. Each call returns a distinct instance, so concurrent jobs
@@ -261,7 +370,12 @@ func newManagerInstance(workDir, rulesFileOverride string) *Manager {
 	if err != nil {
 		cfg, _ = config.LoadDefault()
 	}
+	return newManagerFromConfig(workDir, rulesFileOverride, cfg)
+}
 
+// newManagerFromConfig is newManagerInstance with the grove config already
+// loaded (or, for WithNoState, deliberately empty).
+func newManagerFromConfig(workDir, rulesFileOverride string, cfg *config.Config) *Manager {
 	// Determine the base directory for resolving relative patterns.
 	// When the rules file lives inside the workDir tree (e.g.
 	// .cx.work/plan/rules/job.rules alongside project files), use
@@ -285,7 +399,7 @@ func newManagerInstance(workDir, rulesFileOverride string) *Manager {
 		rulesBaseDir:      rulesBaseDir,
 		rulesFileOverride: rulesFileOverride,
 		locator:           workspace.NewNotebookLocator(cfg),
-		gitIgnoredCache:   make(map[string]map[string]bool),
+		gitIgnoredCache:   make(map[string]gitIgnoredEntry),
 		changedFilesCache: make(map[string]map[string]bool),
 		aliasResolver:     nil, // Lazily initialized
 		log:               grovelogging.NewLogger("grove-context"),
@@ -326,6 +440,9 @@ func (m *Manager) Locator() *workspace.NotebookLocator {
 // GetActivePlanName returns the currently active flow plan name.
 // Delegates to core/pkg/plan for shared detection logic.
 func (m *Manager) GetActivePlanName() string {
+	if m.noState {
+		return ""
+	}
 	return plan.ActivePlan(m.workDir)
 }
 
@@ -347,6 +464,12 @@ func (m *Manager) ResolveRulesPath() string {
 	if m.rulesFileOverride != "" {
 		return m.rulesFileOverride
 	}
+	if m.hasSuppliedRules {
+		return m.suppliedRulesPath()
+	}
+	if envPath, _ := m.envRulesFile(); envPath != "" {
+		return envPath
+	}
 	// Plan-scoped rules — preferred and exclusive when a plan is active.
 	if planName := m.GetActivePlanName(); planName != "" {
 		if planRulesPath := m.GetPlanRulesPath(planName); planRulesPath != "" {
@@ -355,7 +478,7 @@ func (m *Manager) ResolveRulesPath() string {
 	}
 
 	// Check notebook location
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if nbRulesFile, err := m.locator.GetContextRulesFile(node); err == nil {
 			if _, statErr := os.Stat(nbRulesFile); statErr == nil {
 				return nbRulesFile
@@ -373,7 +496,7 @@ func (m *Manager) ResolveRulesPath() string {
 		return legacyPath
 	}
 	// Nothing exists — return notebook path as preferred for creation
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if nbRulesFile, err := m.locator.GetContextRulesFile(node); err == nil {
 			return nbRulesFile
 		}
@@ -482,12 +605,12 @@ func (m *Manager) ResolveContextPath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if genDir, err := m.locator.GetContextGeneratedDir(node); err == nil {
-			return filepath.Join(genDir, "context")
+			return m.artifactPath(filepath.Join(genDir, "context"))
 		}
 	}
-	return filepath.Join(m.workDir, ContextFile)
+	return m.artifactPath(filepath.Join(m.workDir, ContextFile))
 }
 
 // ResolveContextWritePath returns the preferred path for writing the generated context file.
@@ -504,13 +627,13 @@ func (m *Manager) ResolveContextWritePath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if genDir, err := m.locator.GetContextGeneratedDir(node); err == nil {
 			_ = os.MkdirAll(genDir, 0o755)
-			return filepath.Join(genDir, "context")
+			return m.artifactPath(filepath.Join(genDir, "context"))
 		}
 	}
-	return filepath.Join(m.workDir, ContextFile)
+	return m.artifactPath(filepath.Join(m.workDir, ContextFile))
 }
 
 // ResolveCachedContextPath returns the path to the cached context file.
@@ -528,12 +651,12 @@ func (m *Manager) ResolveCachedContextPath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if cacheDir, err := m.locator.GetContextCacheDir(node); err == nil {
-			return filepath.Join(cacheDir, "cached-context")
+			return m.artifactPath(filepath.Join(cacheDir, "cached-context"))
 		}
 	}
-	return filepath.Join(m.workDir, CachedContextFile)
+	return m.artifactPath(filepath.Join(m.workDir, CachedContextFile))
 }
 
 // ResolveCachedContextWritePath returns the preferred path for writing the cached context file.
@@ -550,13 +673,13 @@ func (m *Manager) ResolveCachedContextWritePath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if cacheDir, err := m.locator.GetContextCacheDir(node); err == nil {
 			_ = os.MkdirAll(cacheDir, 0o755)
-			return filepath.Join(cacheDir, "cached-context")
+			return m.artifactPath(filepath.Join(cacheDir, "cached-context"))
 		}
 	}
-	return filepath.Join(m.workDir, CachedContextFile)
+	return m.artifactPath(filepath.Join(m.workDir, CachedContextFile))
 }
 
 // ResolveCachedContextFilesListPath returns the path to the cached context files list.
@@ -574,12 +697,12 @@ func (m *Manager) ResolveCachedContextFilesListPath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if cacheDir, err := m.locator.GetContextCacheDir(node); err == nil {
-			return filepath.Join(cacheDir, "cached-context-files")
+			return m.artifactPath(filepath.Join(cacheDir, "cached-context-files"))
 		}
 	}
-	return filepath.Join(m.workDir, CachedContextFilesListFile)
+	return m.artifactPath(filepath.Join(m.workDir, CachedContextFilesListFile))
 }
 
 // ResolveCachedContextFilesListWritePath returns the preferred path for writing the cached context files list.
@@ -596,13 +719,13 @@ func (m *Manager) ResolveCachedContextFilesListWritePath() string {
 		}
 	}
 
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if cacheDir, err := m.locator.GetContextCacheDir(node); err == nil {
 			_ = os.MkdirAll(cacheDir, 0o755)
-			return filepath.Join(cacheDir, "cached-context-files")
+			return m.artifactPath(filepath.Join(cacheDir, "cached-context-files"))
 		}
 	}
-	return filepath.Join(m.workDir, CachedContextFilesListFile)
+	return m.artifactPath(filepath.Join(m.workDir, CachedContextFilesListFile))
 }
 
 // ResolveContextFilesListPath returns the path to the hot context files list
@@ -612,7 +735,7 @@ func (m *Manager) ResolveContextFilesListPath() string {
 	if m.hotListPathOverride != "" {
 		return m.hotListPathOverride
 	}
-	return filepath.Join(m.workDir, FilesListFile)
+	return m.artifactPath(filepath.Join(m.workDir, FilesListFile))
 }
 
 // ResolveContextFilesListWritePath returns the preferred write path for the hot
@@ -622,11 +745,14 @@ func (m *Manager) ResolveContextFilesListWritePath() string {
 		_ = os.MkdirAll(filepath.Dir(m.hotListPathOverride), 0o755)
 		return m.hotListPathOverride
 	}
-	return filepath.Join(m.workDir, FilesListFile)
+	return m.artifactPath(filepath.Join(m.workDir, FilesListFile))
 }
 
 // ListPlanRules discovers and returns all rules files from grove-flow plans across all workspaces.
 func (m *Manager) ListPlanRules() ([]PlanRule, error) {
+	if m.noState {
+		return nil, nil
+	}
 	// 1. Load the default grove-core configuration to find notebook and plan locations.
 	//    Try to load from the working directory first (to pick up workspace-specific config),
 	//    then fall back to LoadDefault
@@ -690,9 +816,19 @@ func (m *Manager) ListPlanRules() ([]PlanRule, error) {
 // arbitrary root — the exact "wrong rooting from inside the worktree" defect.
 // When the daemon graph can't locate m.workDir, we discard it and use the disk
 // scan, so daemon-reachable and daemon-absent runs resolve identically.
+//
+// When the disk scan fails as well, the resolver degrades to knowing only
+// the current workspace (see degraded.go) instead of failing every lookup.
 func (m *Manager) getAliasResolver() *alias.AliasResolver {
+	if m.noState {
+		return nil // no workspace discovery; call sites treat nil as "no aliases"
+	}
 	rootDir := m.aliasResolutionDir()
 	if m.aliasResolver == nil {
+		if m.discoveryErr != nil {
+			m.aliasResolver = degradedAliasResolver(rootDir, m.discoveryErr)
+			return m.aliasResolver
+		}
 		m.aliasResolver = alias.NewAliasResolverWithWorkDir(rootDir)
 		// Try daemon's cached workspace graph first to avoid expensive disk
 		// scan — but only when a daemon is actually running. When it isn't,
@@ -713,18 +849,40 @@ func (m *Manager) getAliasResolver() *alias.AliasResolver {
 			}
 		}
 	}
+	if m.discoveryErr != nil {
+		return m.aliasResolver
+	}
 	m.aliasResolver.InitProvider() // Fallback to disk scan (idempotent via sync.Once)
+	if err := m.aliasResolver.DiscoverErr; err != nil {
+		// Discovery is unavailable; carry on with this workspace alone.
+		m.discoveryErr = err
+		m.aliasResolver = degradedAliasResolver(rootDir, err)
+	}
 	return m.aliasResolver
 }
 
 // aliasResolutionDir is the directory used to root alias resolution. It is
-// m.workDir by default, but the job's `worktree:` frontmatter can override it
-// (see rerootAliasesToWorktree) so `cx <cmd> --job` resolves @a: aliases into
-// the job's declared worktree regardless of the invoking cwd.
+// the workspace containing m.workDir by default (see workspaceDir), but the
+// job's `worktree:` frontmatter can override it (see rerootAliasesToWorktree)
+// so `cx <cmd> --job` resolves @a: aliases into the job's declared worktree
+// regardless of the invoking cwd.
 func (m *Manager) aliasResolutionDir() string {
 	if m.aliasWorkDir != "" {
 		return m.aliasWorkDir
 	}
+	return m.workspaceDir()
+}
+
+// workspaceDir is the root of the workspace m.workDir sits in: the nearest
+// directory at or above it with grove config, a .grove directory or a git
+// checkout, or m.workDir itself when there is none. Workspace lookups are
+// rooted here rather than at m.workDir, so running from a plain
+// subdirectory of a project (or of an ecosystem worktree) finds the same
+// current node, and so the same siblings, as running from its root.
+func (m *Manager) workspaceDir() string {
+	if root := findWorkspaceRoot(m.workDir); root != "" {
+		return root
+	}
 	return m.workDir
 }
 
@@ -741,7 +899,7 @@ func (m *Manager) rerootAliasesToWorktree(worktreeName string) {
 	if resolver == nil || resolver.Provider == nil {
 		return
 	}
-	baseNode := resolver.Provider.FindByPath(m.workDir)
+	baseNode := resolver.Provider.FindByPath(m.workspaceDir())
 	if baseNode == nil {
 		return
 	}
@@ -803,6 +961,9 @@ func (m *Manager) noticeAliasRoot(aliasName string, info alias.ResolveInfo) {
 // cross-worktree notice (see noticeAliasRoot). It is the wrapper the rule-import
 // call sites use instead of getAliasResolver().Resolve.
 func (m *Manager) resolveProjectAlias(aliasName string) (string, error) {
+	if m.noState {
+		return "", errNoStateAliases
+	}
 	info, err := m.getAliasResolver().ResolveWithInfo(aliasName)
 	if err != nil {
 		return "", err
@@ -863,6 +1024,9 @@ func (m *Manager) resolveConcept(conceptID string, visited map[string]bool) ([]s
 		return nil, nil
 	}
 	visited[conceptID] = true
+	if m.noState {
+		return nil, fmt.Errorf("concepts live in the notebook, which is not consulted (manager built WithNoState)")
+	}
 
 	// 1. Initialize NotebookLocator
 	cfg, err := config.LoadFrom(m.workDir)
@@ -876,7 +1040,7 @@ func (m *Manager) resolveConcept(conceptID string, visited map[string]bool) ([]s
 	locator := workspace.NewNotebookLocator(cfg)
 
 	// 2. Find the current workspace context to locate the concepts directory
-	currentWS, err := workspace.GetProjectByPath(m.workDir)
+	currentWS, err := m.projectNode()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workspace: %w", err)
 	}
@@ -917,7 +1081,7 @@ func (m *Manager) resolveConcept(conceptID string, visited map[string]bool) ([]s
 	for _, relatedConceptID := range manifest.RelatedConcepts {
 		relatedFiles, err := m.resolveConcept(relatedConceptID, visited)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve related concept '%s': %v\n", relatedConceptID, err)
+			Warnf("could not resolve related concept '%s': %v", relatedConceptID, err)
 			continue
 		}
 		files = append(files, relatedFiles...)
@@ -925,10 +1089,13 @@ func (m *Manager) resolveConcept(conceptID string, visited map[string]bool) ([]s
 
 	// 8. Resolve related plans using notebook locator
 	resolver := m.getAliasResolver()
+	if resolver == nil {
+		return files, nil
+	}
 	for _, planAlias := range manifest.RelatedPlans {
 		resolvedPath, err := resolver.Resolve(planAlias)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve plan alias '%s': %v\n", planAlias, err)
+			Warnf("could not resolve plan alias '%s': %v", planAlias, err)
 			continue
 		}
 
@@ -943,7 +1110,7 @@ func (m *Manager) resolveConcept(conceptID string, visited map[string]bool) ([]s
 	for _, noteAlias := range manifest.RelatedNotes {
 		resolvedPath, err := resolver.Resolve(noteAlias)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve note alias '%s': %v\n", noteAlias, err)
+			Warnf("could not resolve note alias '%s': %v", noteAlias, err)
 			continue
 		}
 		files = append(files, resolvedPath)
@@ -989,6 +1156,81 @@ func (m *Manager) ResolveLineForRulePreview(line string) (string, error) {
 	return line, nil
 }
 
+// PatternPreview is one pattern a previewed rule line resolves to, with
+// the number of files it matches and their estimated tokens.
+type PatternPreview struct {
+	Pattern string `json:"pattern"`
+	Files   int    `json:"files"`
+	Tokens  int    `json:"tokens"`
+}
+
+// RuleLinePreview is the structured form of ResolveLineForRulePreview: each
+// resolved pattern with its own file count and token estimate, and the
+// files the line matches as a whole (patterns may overlap), so an editor
+// can annotate a rule with "adds 42 files / ~18k tokens" while it is typed.
+type RuleLinePreview struct {
+	Line     string           `json:"line"`
+	Patterns []PatternPreview `json:"patterns"`
+	Paths    []string         `json:"paths"`
+	Files    int              `json:"files"`
+	Tokens   int              `json:"tokens"`
+}
+
+// PreviewRuleLine resolves line like ResolveLineForRulePreview and reports
+// the files and tokens of every resolved pattern.
+func (m *Manager) PreviewRuleLine(line string) (*RuleLinePreview, error) {
+	resolved, err := m.ResolveLineForRulePreview(line)
+	if err != nil {
+		return nil, err
+	}
+	return m.PreviewPatterns(line, strings.Split(resolved, "\n"))
+}
+
+// PreviewPatterns is PreviewRuleLine for patterns already resolved from
+// line.
+func (m *Manager) PreviewPatterns(line string, patterns []string) (*RuleLinePreview, error) {
+	preview := &RuleLinePreview{Line: strings.TrimSpace(line), Patterns: []PatternPreview{}}
+	for _, pattern := range patterns {
+		files, err := m.ResolveFilesFromPatterns([]string{pattern})
+		if err != nil {
+			return nil, fmt.Errorf("error resolving files for pattern '%s': %w", pattern, err)
+		}
+		preview.Patterns = append(preview.Patterns, PatternPreview{
+			Pattern: pattern,
+			Files:   len(files),
+			Tokens:  m.EstimateFilesTokens(files),
+		})
+		preview.Paths = files
+	}
+	if len(patterns) > 1 {
+		var err error
+		if preview.Paths, err = m.ResolveFilesFromPatterns(patterns); err != nil {
+			return nil, fmt.Errorf("error resolving files for rule '%s': %w", line, err)
+		}
+	}
+	if preview.Paths == nil {
+		preview.Paths = []string{}
+	}
+	preview.Files, preview.Tokens = len(preview.Paths), m.EstimateFilesTokens(preview.Paths)
+	return preview, nil
+}
+
+// EstimateFilesTokens sums the estimated tokens of files; relative paths
+// are taken against the working directory.
+func (m *Manager) EstimateFilesTokens(files []string) int {
+	provider := GetStatsProvider()
+	total := 0
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(m.workDir, file)
+		}
+		if info, err := provider.GetFileStats(file); err == nil {
+			total += info.Tokens
+		}
+	}
+	return total
+}
+
 // resolvePatternsFromRulesetImport resolves a ruleset import string (e.g., "proj::rules") into a slice of patterns.
 func (m *Manager) resolvePatternsFromRulesetImport(importRule string) ([]string, error) {
 	// 1. Parse the import string
@@ -1011,7 +1253,11 @@ func (m *Manager) resolvePatternsFromRulesetImport(importRule string) ([]string,
 	projectAlias, rulesetName := parts[0], parts[1]
 
 	// 2. Resolve the project alias to its absolute path
-	projectPath, err := m.getAliasResolver().Resolve(projectAlias)
+	resolver := m.getAliasResolver()
+	if resolver == nil {
+		return nil, fmt.Errorf("could not resolve project alias '%s': %w", projectAlias, errNoStateAliases)
+	}
+	projectPath, err := resolver.Resolve(projectAlias)
 	if err != nil {
 		return nil, fmt.Errorf("could not resolve project alias '%s': %w", projectAlias, err)
 	}
@@ -1023,7 +1269,7 @@ func (m *Manager) resolvePatternsFromRulesetImport(importRule string) ([]string,
 	}
 
 	// 4. Expand all rules from that file
-	hotRules, coldRules, _, _, err := m.expandAllRules(rulesFilePath, make(map[string]bool), 0)
+	hotRules, coldRules, _, _, err := m.expandAllRules(rulesFilePath, newExpansionRun(), 0)
 	if err != nil {
 		return nil, fmt.Errorf("could not expand ruleset '%s' from project '%s': %w", rulesetName, projectAlias, err)
 	}
@@ -1107,8 +1353,11 @@ func (m *Manager) WriteFilesList(filename string, files []string) error {
 	for _, f := range files {
 		fmt.Fprintln(file, f)
 	}
+	if err := file.Close(); err != nil {
+		return err
+	}
 
-	return nil
+	return m.sealArtifact(filename)
 }
 
 // AppendFilesList appends a list of files to a file
@@ -1141,7 +1390,9 @@ func (m *Manager) getGitIgnoredFiles(forDir string) (map[string]bool, error) {
 
 	// Find the root of the git repository for the given directory.
 	gitRootCmd := exec.Command("git", "-C", absForDir, "rev-parse", "--show-toplevel")
+	release := m.acquireGit()
 	gitRootOutput, err := gitRootCmd.Output()
+	release()
 	if err != nil {
 		// This directory is not in a git repository, so no files are gitignored.
 		return make(map[string]bool), nil
@@ -1156,17 +1407,24 @@ func (m *Manager) getGitIgnoredFiles(forDir string) (map[string]bool, error) {
 	}
 
 	// Check if we have a cached result for this repository in memory
+	// The entry is dropped once an ignore file it was built from changes, as
+	// it can in place under a long-lived manager.
 	m.gitIgnoredMutex.RLock()
-	cachedResult, found := m.gitIgnoredCache[cacheKey]
+	cached, found := m.gitIgnoredCache[cacheKey]
 	m.gitIgnoredMutex.RUnlock()
-	if found {
-		return cachedResult, nil
+	if found && cached.stamp == ignoreFilesStamp(cached.files) {
+		m.cacheStats.gitIgnored.record(true)
+		return cached.ignored, nil
 	}
+	excludeFiles := gitExcludeFiles(gitRootPath)
+	stamp := ignoreFilesStamp(excludeFiles)
 
 	// Try to load from disk cache
-	if diskCached, found := m.loadGitIgnoredFromDiskCache(gitRootPath); found {
+	diskCached, found := m.loadGitIgnoredFromDiskCache(gitRootPath)
+	m.cacheStats.gitIgnored.record(found)
+	if found {
 		m.gitIgnoredMutex.Lock()
-		m.gitIgnoredCache[cacheKey] = diskCached
+		m.gitIgnoredCache[cacheKey] = gitIgnoredEntry{ignored: diskCached, files: excludeFiles, stamp: stamp}
 		m.gitIgnoredMutex.Unlock()
 		return diskCached, nil
 	}
@@ -1175,6 +1433,8 @@ func (m *Manager) getGitIgnoredFiles(forDir string) (map[string]bool, error) {
 	ignoredFiles := make(map[string]bool)
 
 	// Get all tracked files to correctly handle cases where an ignored file is explicitly tracked.
+	release = m.acquireGit()
+	defer release()
 	trackedCmd := exec.Command("git", "ls-files")
 	trackedCmd.Dir = gitRootPath
 	trackedOutput, _ := trackedCmd.Output()
@@ -1222,7 +1482,7 @@ func (m *Manager) getGitIgnoredFiles(forDir string) (map[string]bool, error) {
 
 	// Cache the result in memory (use normalized key)
 	m.gitIgnoredMutex.Lock()
-	m.gitIgnoredCache[cacheKey] = ignoredFiles
+	m.gitIgnoredCache[cacheKey] = gitIgnoredEntry{ignored: ignoredFiles, files: excludeFiles, stamp: stamp}
 	m.gitIgnoredMutex.Unlock()
 
 	// Cache the result on disk for future invocations
@@ -1231,23 +1491,86 @@ func (m *Manager) getGitIgnoredFiles(forDir string) (map[string]bool, error) {
 	return ignoredFiles, nil
 }
 
-// computeGitignoreHash computes a hash of all .gitignore files in the repository
+// computeGitignoreHash computes a hash of the ignore files `git ls-files
+// --exclude-standard` reads for the repository: its root .gitignore, its
+// info/exclude and the user's global excludes file. Each path is hashed even
+// when the file is missing, so creating one invalidates the cache too.
 func (m *Manager) computeGitignoreHash(gitRootPath string) (string, error) {
 	hasher := sha256.New()
+	for _, path := range gitExcludeFiles(gitRootPath) {
+		hasher.Write([]byte(path + "\x00"))
+		if data, err := os.ReadFile(path); err == nil {
+			hasher.Write(data)
+		}
+		hasher.Write([]byte{0})
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16], nil
+}
+
+// gitIgnoredEntry is a cached gitignored set and the stamp of the ignore
+// files it was built from.
+type gitIgnoredEntry struct {
+	ignored map[string]bool
+	files   []string
+	stamp   string
+}
+
+// ignoreFilesStamp identifies the current state of files by their size and
+// modification time. Missing files count too.
+func ignoreFilesStamp(files []string) string {
+	var b strings.Builder
+	for _, path := range files {
+		b.WriteString(path)
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "\x00%d\x00%d", info.Size(), info.ModTime().UnixNano())
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
 
-	// Find all .gitignore files in the repository
-	gitignorePath := filepath.Join(gitRootPath, ".gitignore")
-	if data, err := os.ReadFile(gitignorePath); err == nil {
-		hasher.Write(data)
+// gitExcludeFiles lists the ignore files git consults for the repository at
+// gitRootPath besides nested .gitignore files.
+func gitExcludeFiles(gitRootPath string) []string {
+	files := []string{filepath.Join(gitRootPath, ".gitignore")}
+
+	// info/exclude lives in the common git directory, which is not
+	// <root>/.git in a linked worktree or a submodule.
+	exclude := filepath.Join(gitRootPath, ".git", "info", "exclude")
+	if out, err := exec.Command("git", "-C", gitRootPath, "rev-parse", "--git-path", "info/exclude").Output(); err == nil {
+		if p := strings.TrimSpace(string(out)); p != "" {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(gitRootPath, p)
+			}
+			exclude = p
+		}
 	}
+	files = append(files, exclude)
 
-	// Also hash .git/info/exclude if it exists
-	excludePath := filepath.Join(gitRootPath, ".git", "info", "exclude")
-	if data, err := os.ReadFile(excludePath); err == nil {
-		hasher.Write(data)
+	if global := globalExcludesFile(gitRootPath); global != "" {
+		files = append(files, global)
 	}
+	return files
+}
 
-	return hex.EncodeToString(hasher.Sum(nil))[:16], nil
+// globalExcludesFile returns core.excludesFile, or git's default of
+// $XDG_CONFIG_HOME/git/ignore (~/.config/git/ignore) when it is unset.
+func globalExcludesFile(gitRootPath string) string {
+	if out, err := exec.Command("git", "-C", gitRootPath, "config", "--path", "--get", "core.excludesFile").Output(); err == nil {
+		if p := strings.TrimSpace(string(out)); p != "" {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(gitRootPath, p)
+			}
+			return p
+		}
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "git", "ignore")
+	}
+	return ""
 }
 
 // getCacheDir returns the directory for storing git ignore caches
@@ -1274,6 +1597,9 @@ func (m *Manager) getCacheFilePath(gitRootPath string) (string, error) {
 
 // loadGitIgnoredFromDiskCache attempts to load cached git ignored files from disk
 func (m *Manager) loadGitIgnoredFromDiskCache(gitRootPath string) (map[string]bool, bool) {
+	if cachesDisabled() {
+		return nil, false
+	}
 	cacheFile, err := m.getCacheFilePath(gitRootPath)
 	if err != nil {
 		return nil, false
@@ -1307,6 +1633,9 @@ func (m *Manager) loadGitIgnoredFromDiskCache(gitRootPath string) (map[string]bo
 
 // saveGitIgnoredToDiskCache saves git ignored files to disk cache
 func (m *Manager) saveGitIgnoredToDiskCache(gitRootPath string, ignoredFiles map[string]bool) {
+	if cachesDisabled() {
+		return
+	}
 	cacheFile, err := m.getCacheFilePath(gitRootPath)
 	if err != nil {
 		return // Silently fail if we can't compute cache path
@@ -1525,6 +1854,7 @@ func (m *Manager) ShowContext() error {
 		return fmt.Errorf("error reading %s: %w", contextPath, err)
 	}
 
+	warnOnChecksumMismatch(contextPath)
 	fmt.Print(string(content))
 	return nil
 }
@@ -1553,8 +1883,8 @@ func (m *Manager) ListFiles() ([]string, error) {
 			continue
 		}
 
-		// On case-insensitive filesystems, normalize to lowercase for comparison
-		normalizedKey := strings.ToLower(absPath)
+		// Case variants are one file only where the filesystem says so
+		normalizedKey := m.foldKey(absPath)
 
 		// Only add if we haven't seen this normalized path before
 		if _, seen := seenPaths[normalizedKey]; !seen {
@@ -1617,7 +1947,7 @@ func (m *Manager) initAllowedRoots() {
 
 		mergedCfg, err := config.LoadFrom(m.workDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not load grove configuration to apply workspace filters: %v\n", err)
+			Warnf("could not load grove configuration to apply workspace filters: %v", err)
 		}
 
 		// Read context-specific configuration from the config's typed Context
@@ -1631,6 +1961,7 @@ func (m *Manager) initAllowedRoots() {
 		}
 
 		var allowed []string
+		origins := make(map[string]string)
 
 		if len(ctxCfg.IncludedWorkspaces) > 0 {
 			// --- ALLOWLIST MODE ---
@@ -1646,6 +1977,7 @@ func (m *Manager) initAllowedRoots() {
 						canonicalPath = node.Path
 					}
 					allowed = append(allowed, canonicalPath)
+					origins[canonicalPath] = fmt.Sprintf("workspace '%s' (in included_workspaces)", node.Name)
 				}
 			}
 		} else {
@@ -1662,6 +1994,7 @@ func (m *Manager) initAllowedRoots() {
 						canonicalPath = node.Path
 					}
 					allowed = append(allowed, canonicalPath)
+					origins[canonicalPath] = fmt.Sprintf("workspace '%s'", node.Name)
 				}
 			}
 		}
@@ -1681,6 +2014,7 @@ func (m *Manager) initAllowedRoots() {
 					canonicalGroveDir = groveDir
 				}
 				allowed = append(allowed, canonicalGroveDir)
+				origins[canonicalGroveDir] = "grove directory"
 			}
 		}
 
@@ -1690,7 +2024,7 @@ func (m *Manager) initAllowedRoots() {
 				if notebook.RootDir != "" {
 					notebookRootDir, err := pathutil.Expand(notebook.RootDir)
 					if err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: could not expand notebook '%s' root_dir '%s': %v\n", notebookName, notebook.RootDir, err)
+						Warnf("could not expand notebook '%s' root_dir '%s': %v", notebookName, notebook.RootDir, err)
 					} else {
 						// Canonicalize notebook root path
 						canonicalNotebookRoot, err := pathutil.NormalizeForLookup(notebookRootDir)
@@ -1707,6 +2041,7 @@ func (m *Manager) initAllowedRoots() {
 						}
 						if !isAlreadyAllowed {
 							allowed = append(allowed, canonicalNotebookRoot)
+							origins[canonicalNotebookRoot] = fmt.Sprintf("notebook '%s' root_dir", notebookName)
 						}
 					}
 				}
@@ -1718,14 +2053,14 @@ func (m *Manager) initAllowedRoots() {
 			// Expand ~ and environment variables
 			expandedPath, err := pathutil.Expand(allowedPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not expand allowed_path '%s': %v\n", allowedPath, err)
+				Warnf("could not expand allowed_path '%s': %v", allowedPath, err)
 				continue
 			}
 
 			// Convert to absolute path
 			absPath, err := filepath.Abs(expandedPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not resolve absolute path for '%s': %v\n", expandedPath, err)
+				Warnf("could not resolve absolute path for '%s': %v", expandedPath, err)
 				continue
 			}
 
@@ -1733,7 +2068,7 @@ func (m *Manager) initAllowedRoots() {
 			canonicalPath, err := pathutil.NormalizeForLookup(absPath)
 			if err != nil {
 				// Fallback to absolute path if normalization fails
-				fmt.Fprintf(os.Stderr, "Warning: could not normalize allowed_path '%s': %v\n", absPath, err)
+				Warnf("could not normalize allowed_path '%s': %v", absPath, err)
 				canonicalPath = absPath
 			}
 
@@ -1747,10 +2082,12 @@ func (m *Manager) initAllowedRoots() {
 			}
 			if !isAlreadyAllowed {
 				allowed = append(allowed, canonicalPath)
+				origins[canonicalPath] = fmt.Sprintf("context.allowed_paths entry '%s'", allowedPath)
 			}
 		}
 
 		m.allowedRoots = allowed
+		m.rootOrigins = origins
 	})
 }
 
@@ -1880,11 +2217,12 @@ func (m *Manager) GetSkippedRules() []SkippedRule {
 	return result
 }
 
-// ClearSkippedRules clears the list of skipped rules
+// ClearSkippedRules clears the list of skipped rules and of failed @cmd: rules
 func (m *Manager) ClearSkippedRules() {
 	m.skippedMutex.Lock()
 	defer m.skippedMutex.Unlock()
 	m.skippedRules = nil
+	m.cmdFailures = nil
 }
 
 // AddSkippedRule adds a skipped rule to the list
@@ -1896,6 +2234,15 @@ func (m *Manager) addSkippedRule(lineNum int, rule, reason string) {
 		Rule:    rule,
 		Reason:  reason,
 	})
+	m.skippedTotal++
+}
+
+// skippedRuleTotal returns how many rules have ever been skipped; unlike
+// len(GetSkippedRules()) it is not reset by ClearSkippedRules.
+func (m *Manager) skippedRuleTotal() int {
+	m.skippedMutex.Lock()
+	defer m.skippedMutex.Unlock()
+	return m.skippedTotal
 }
 
 // EnsureAndGetRulesPath finds the active rules file, creates it with boilerplate if it doesn't exist,
@@ -1909,7 +2256,10 @@ func (m *Manager) EnsureAndGetRulesPath() (string, error) {
 	// If LoadRulesContent didn't return a path, determine where to create the file
 	if rulesPath == "" {
 		// Check if there's an active rule set in state
-		activeSource, _ := state.GetString(m.workDir, StateSourceKey)
+		var activeSource string
+		if !m.noState {
+			activeSource, _ = state.GetString(m.workDir, StateSourceKey)
+		}
 		if activeSource != "" {
 			// Use the active source path from state
 			rulesPath = filepath.Join(m.workDir, activeSource)
@@ -1923,7 +2273,7 @@ func (m *Manager) EnsureAndGetRulesPath() (string, error) {
 
 			if rulesPath == "" {
 				// Default to notebook if centralized, else local
-				if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+				if node, err := m.projectNode(); err == nil {
 					if nbRulesFile, locErr := m.locator.GetContextRulesFile(node); locErr == nil {
 						rulesPath = nbRulesFile
 					}
@@ -2033,7 +2383,7 @@ func (m *Manager) IsPathAllowed(path string) (bool, string) {
 	// FIRST, check if this path or any containing directory is an excluded workspace
 	// (higher priority than parent allowance)
 	resolver := m.getAliasResolver()
-	if resolver.Provider != nil {
+	if resolver != nil && resolver.Provider != nil {
 		// Load config to check exclusions
 		mergedCfg, _ := config.LoadFrom(m.workDir)
 		var ctxCfg ContextConfig
@@ -2083,6 +2433,10 @@ func (m *Manager) IsPathAllowed(path string) (bool, string) {
 			return true, ""
 		}
 	}
+	// Finally, roots granted by trusted @allow-path requests
+	if _, _, ok := m.grantedRootFor(canonicalPath); ok {
+		return true, ""
+	}
 
 	// If not found in allowed roots, return error
 	return false, fmt.Sprintf("path '%s' is outside of any known or allowed workspace", path)
@@ -2091,137 +2445,45 @@ func (m *Manager) IsPathAllowed(path string) (bool, string) {
 // ClassifyAllProjectFiles is the unified, deterministic classification engine that
 // resolves and classifies all files based on context rules. It returns a map of file
 // paths to their NodeStatus. This method ensures consistency across all views (tree, stats, list).
+// Callers that also need the hot or cold lists should use a ResolutionSession.
 func (m *Manager) ClassifyAllProjectFiles(showGitIgnored bool) (map[string]NodeStatus, error) {
 	defer profiling.Start("context.ClassifyAllProjectFiles").Stop()
-	result := make(map[string]NodeStatus)
-
-	// Step 1: Get the definitive sets of hot and cold files using the existing stable methods
-	hotFiles, err := m.ResolveFilesFromRules()
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve hot files: %w", err)
-	}
-
-	coldFiles, err := m.ResolveColdContextFiles()
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve cold files: %w", err)
-	}
-
-	// Step 2: Load rules content for attribution
-	rulesContent, _, err := m.LoadRulesContent()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load rules content: %w", err)
-	}
-
-	// Get attribution to identify explicitly excluded files
-	_, _, exclusionResult, _, _, err := m.ResolveFilesWithAttribution(string(rulesContent))
+	s, err := m.NewResolutionSession()
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve attribution: %w", err)
-	}
-
-	// Step 3: Create maps for efficient lookup and convert relative paths to absolute
-	// Normalize paths for case-insensitive filesystems and symlink resolution
-	hotFilesMap := make(map[string]bool)
-	for _, f := range hotFiles {
-		absPath := f
-		if !filepath.IsAbs(f) {
-			absPath = filepath.Join(m.workDir, f)
-		}
-		// Normalize the path to handle case-insensitive filesystems
-		if normalizedPath, err := pathutil.NormalizeForLookup(absPath); err == nil {
-			absPath = normalizedPath
-		}
-		hotFilesMap[absPath] = true
-	}
-
-	coldFilesMap := make(map[string]bool)
-	for _, f := range coldFiles {
-		absPath := f
-		if !filepath.IsAbs(f) {
-			absPath = filepath.Join(m.workDir, f)
-		}
-		// Normalize the path to handle case-insensitive filesystems
-		if normalizedPath, err := pathutil.NormalizeForLookup(absPath); err == nil {
-			absPath = normalizedPath
-		}
-		coldFilesMap[absPath] = true
+		return nil, err
 	}
+	return m.classifyProjectFiles(s, showGitIgnored)
+}
 
-	// Extract excluded files from exclusionResult (which is a map[int][]string)
-	excludedFilesMap := make(map[string]bool)
-	for _, files := range exclusionResult {
-		for _, f := range files {
-			absPath := f
-			if !filepath.IsAbs(f) {
-				absPath = filepath.Join(m.workDir, f)
-			}
-			// Normalize the path to handle case-insensitive filesystems
-			if normalizedPath, err := pathutil.NormalizeForLookup(absPath); err == nil {
-				absPath = normalizedPath
-			}
-			excludedFilesMap[absPath] = true
-		}
-	}
+// classifyProjectFiles implements ClassifyAllProjectFiles over an existing
+// session, reusing its expanded rules and resolved files.
+func (m *Manager) classifyProjectFiles(s *ResolutionSession, showGitIgnored bool) (map[string]NodeStatus, error) {
+	result := make(map[string]NodeStatus)
 
-	// Step 4: Classify the resolved files (now all with absolute paths)
-	// Hot files (but not if they're also in cold - cold takes precedence)
-	for file := range hotFilesMap {
-		if !coldFilesMap[file] {
-			result[file] = StatusIncludedHot
-		}
+	// Step 1-4: The definitive sets of hot, cold and explicitly excluded
+	// files come from the session, already canonicalized and with one
+	// spelling per file (see unifySpellings), so no file can carry two
+	// statuses.
+	for _, file := range s.hotKeys {
+		result[file] = StatusIncludedHot
 	}
-
-	// Cold files
-	for file := range coldFilesMap {
+	for _, file := range s.coldKeys {
 		result[file] = StatusIncludedCold
 	}
-
-	// Excluded files
-	for file := range excludedFilesMap {
+	for _, file := range s.excludedKeys {
 		result[file] = StatusExcludedByRule
 	}
 
 	// Step 5: Extract root paths from the rules to know what directories to walk
 	// We need to walk these to find all files for the tree view
-	activeRulesFile := m.findActiveRulesFile()
-	if activeRulesFile == "" {
-		// If no rules file, check for defaults
-		_, defaultRulesFile := m.LoadDefaultRulesContent()
-		if _, err := os.Stat(defaultRulesFile); !os.IsNotExist(err) {
-			activeRulesFile = defaultRulesFile
-		} else {
-			// No active or default rules found - just return what we have
-			return result, nil
-		}
-	}
-
-	hotRules, coldRules, _, _, err := m.expandAllRules(activeRulesFile, make(map[string]bool), 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to expand rules: %w", err)
+	if s.RulesContent == nil {
+		// No active or default rules found - just return what we have
+		return result, nil
 	}
+	hotRules, coldRules := s.hotRules, s.coldRules
 
 	// Extract patterns for root path discovery
-	var allPatterns []string
-	for _, rule := range hotRules {
-		pattern := rule.Pattern
-		pattern = encodeDirectives(pattern, rule.Directives)
-		if rule.IsExclude {
-			allPatterns = append(allPatterns, "!"+pattern)
-		} else {
-			allPatterns = append(allPatterns, pattern)
-		}
-	}
-	for _, rule := range coldRules {
-		pattern := rule.Pattern
-		pattern = encodeDirectives(pattern, rule.Directives)
-		if rule.IsExclude {
-			allPatterns = append(allPatterns, "!"+pattern)
-		} else {
-			allPatterns = append(allPatterns, pattern)
-		}
-	}
-
-	// Pre-process patterns
-	allPatterns = m.preProcessPatterns(allPatterns)
+	allPatterns := m.walkPatterns(append(append([]RuleInfo{}, hotRules...), coldRules...), s.stats)
 	rootPaths := m.extractRootPaths(allPatterns)
 
 	// Ensure working directory is in result. Use the same normalization
@@ -2268,10 +2530,21 @@ func (m *Manager) ClassifyAllProjectFiles(showGitIgnored bool) (map[string]NodeS
 			}
 		}
 
-		// Walk the directory tree
-		err = filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+		// Walk the directory tree. WalkDir does not follow a symlinked root
+		// (a workspace entered through its symlink), so walk its target.
+		walkRoot := rootPath
+		if resolved, evalErr := filepath.EvalSymlinks(rootPath); evalErr == nil {
+			walkRoot = resolved
+		}
+		err = filepath.WalkDir(walkRoot, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
-				return err
+				if werr := m.walkError(path, err); werr != nil {
+					return werr
+				}
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
 			}
 
 			// Canonicalize the path to match how files were stored in result
@@ -2291,6 +2564,18 @@ func (m *Manager) ClassifyAllProjectFiles(showGitIgnored bool) (map[string]NodeS
 				return filepath.SkipDir
 			}
 
+			// A symlink to a directory (a symlinked workspace inside the
+			// root) canonicalizes to its target directory. WalkDir does not
+			// descend into it, so it must not be classified as a file.
+			if d.Type()&os.ModeSymlink != 0 {
+				if info, statErr := os.Stat(path); statErr == nil && info.IsDir() {
+					if _, exists := result[canonicalPath]; !exists {
+						result[canonicalPath] = StatusDirectory
+					}
+					return nil
+				}
+			}
+
 			// Check if already classified
 			if _, exists := result[canonicalPath]; exists {
 				return nil
@@ -2322,7 +2607,7 @@ func (m *Manager) ClassifyAllProjectFiles(showGitIgnored bool) (map[string]NodeS
 
 	// Step 7: Ensure all parent directories of classified files exist in result
 	for path := range result {
-		if path == m.workDir {
+		if path == workDirCanonical {
 			continue
 		}
 
@@ -2,6 +2,7 @@ package context
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -425,6 +426,51 @@ func TestManager_GitignoreCompatibility(t *testing.T) {
 	}
 }
 
+// TestManager_GitIgnoredHonorsExcludeFiles verifies that gitignored
+// detection picks up .git/info/exclude and the global excludes file, and that
+// editing either invalidates the on-disk cache.
+func TestManager_GitIgnoredHonorsExcludeFiles(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	testDir := t.TempDir()
+	if out, err := exec.Command("git", "-C", testDir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	fsWriteString(t, filepath.Join(testDir, "debug.log"), "log")
+	fsWriteString(t, filepath.Join(testDir, "notes.txt"), "notes")
+	root, err := filepath.EvalSymlinks(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isIgnored := func(name string) bool {
+		t.Helper()
+		ignored, err := NewManager(testDir, WithNoState()).getGitIgnoredFiles(testDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := pathutil.NormalizeForLookup(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ignored[key]
+	}
+
+	if isIgnored("debug.log") || isIgnored("notes.txt") {
+		t.Fatal("nothing should be ignored yet")
+	}
+	fsWriteString(t, filepath.Join(configHome, "git", "ignore"), "*.log\n")
+	if !isIgnored("debug.log") {
+		t.Error("global excludes file not honored")
+	}
+	fsWriteString(t, filepath.Join(testDir, ".git", "info", "exclude"), "*.txt\n")
+	if !isIgnored("notes.txt") {
+		t.Error(".git/info/exclude not honored")
+	}
+}
+
 func slicesEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -910,3 +956,39 @@ func TestResolveLineForRulePreview_BraceInAlias(t *testing.T) {
 		t.Errorf("lines[1] = %q, want /fake/eco/beta/main.go", lines[1])
 	}
 }
+
+func TestPreviewPatterns(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	resetTokenRatios()
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "a.go"), strings.Repeat("x", 100))
+	fsWriteString(t, filepath.Join(dir, "b.go"), strings.Repeat("x", 50))
+	fsWriteString(t, filepath.Join(dir, "notes.md"), strings.Repeat("x", 40))
+
+	m := NewManager(dir)
+	preview, err := m.PreviewPatterns("@a:whatever", []string{"*.go", "a.go"})
+	if err != nil {
+		t.Fatalf("PreviewPatterns failed: %v", err)
+	}
+	if len(preview.Patterns) != 2 {
+		t.Fatalf("expected 2 patterns, got %+v", preview.Patterns)
+	}
+	if p := preview.Patterns[0]; p.Files != 2 || p.Tokens != 75 {
+		t.Errorf("*.go = %+v, want 2 files / 75 tokens", p)
+	}
+	if p := preview.Patterns[1]; p.Files != 1 || p.Tokens != 50 {
+		t.Errorf("a.go = %+v, want 1 file / 50 tokens", p)
+	}
+	// Overlapping patterns count each file once for the line.
+	if preview.Files != 2 || preview.Tokens != 75 {
+		t.Errorf("line = %d files / %d tokens, want 2 / 75", preview.Files, preview.Tokens)
+	}
+
+	single, err := m.PreviewRuleLine("*.md")
+	if err != nil {
+		t.Fatalf("PreviewRuleLine failed: %v", err)
+	}
+	if single.Files != 1 || single.Tokens != 10 || len(single.Paths) != 1 {
+		t.Errorf("*.md = %+v, want 1 file / 10 tokens", single)
+	}
+}
@@ -0,0 +1,173 @@
+package context
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata filters. These directives match a file's metadata rather than
+// its path or content, inline with a pattern like @find: and @grep:, and
+// compose with them the same way (@or, @any-of, @with blocks):
+//
+//	docs/** @newer-than: 14d        modified within the window (or after a date)
+//	src/** @older-than: 2025-01-01  modified before a date (or a window ago)
+//	assets/** @size: <50KB          also >1MB, >=1KB, <=2MB and 1KB..100KB
+//	scripts/* @executable           executable bit set; @executable: false for unset
+//	/srv/** @owner-uid: 1000        owned by a user id (Unix only)
+//
+// Sizes take B, KB, MB or GB (powers of 1024); dates are 2006-01-02 or
+// RFC 3339.
+var metadataDirectives = map[string]bool{
+	"newer-than": true,
+	"older-than": true,
+	"size":       true,
+	"executable": true,
+	"owner-uid":  true,
+}
+
+// bareDirectiveRegex finds @executable and the @text/@binary modifiers
+// (binary.go) written without a query.
+var bareDirectiveRegex = regexp.MustCompile(`(\s@(?:executable|text|binary))(\s|$)`)
+
+// expandBareDirectives rewrites directives that may be written without a
+// query into their marker form, so parseSearchDirectives sees
+// "@executable: true" for a bare "@executable".
+func expandBareDirectives(line string) string {
+	if !strings.Contains(line, "@executable") && !strings.Contains(line, "@text") && !strings.Contains(line, "@binary") {
+		return line
+	}
+	return bareDirectiveRegex.ReplaceAllString(line, "$1: true$2")
+}
+
+// validateMetadataDirective reports why query is not a valid value for
+// the metadata directive name.
+func validateMetadataDirective(name, query string) error {
+	var err error
+	switch name {
+	case "newer-than", "older-than":
+		_, err = parseTimeBound(query, time.Now())
+	case "size":
+		_, _, err = parseSizeRange(query)
+	case "executable":
+		_, err = strconv.ParseBool(unquoteQuery(query))
+	case "owner-uid":
+		_, err = strconv.Atoi(unquoteQuery(query))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid @%s: %q: %w", name, query, err)
+	}
+	return nil
+}
+
+// matchMetadata reports whether info satisfies the metadata directive name.
+// A malformed query matches nothing.
+func matchMetadata(info fs.FileInfo, name, query string, now time.Time) bool {
+	switch name {
+	case "newer-than", "older-than":
+		cutoff, err := parseTimeBound(query, now)
+		if err != nil {
+			return false
+		}
+		if name == "newer-than" {
+			return info.ModTime().After(cutoff)
+		}
+		return info.ModTime().Before(cutoff)
+	case "size":
+		lo, hi, err := parseSizeRange(query)
+		return err == nil && info.Size() >= lo && (hi < 0 || info.Size() <= hi)
+	case "executable":
+		want, err := strconv.ParseBool(unquoteQuery(query))
+		return err == nil && (info.Mode().Perm()&0o111 != 0) == want
+	case "owner-uid":
+		want, err := strconv.Atoi(unquoteQuery(query))
+		uid, ok := fileOwnerUID(info)
+		return err == nil && ok && uid == want
+	}
+	return false
+}
+
+// parseTimeBound turns a window ("14d", "36h", "2w") into the time that
+// long before now, or parses a date ("2025-01-01") or RFC 3339 time.
+func parseTimeBound(query string, now time.Time) (time.Time, error) {
+	s := unquoteQuery(query)
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	d, err := parseExtendedDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a duration (14d, 36h) or a date (2006-01-02)")
+	}
+	return now.Add(-d), nil
+}
+
+// parseSizeRange parses a size bound into an inclusive byte range; hi is -1
+// when unbounded.
+func parseSizeRange(query string) (lo, hi int64, err error) {
+	s := strings.ReplaceAll(unquoteQuery(query), " ", "")
+	if a, b, ok := strings.Cut(s, ".."); ok {
+		if lo, err = parseByteSize(a); err == nil {
+			hi, err = parseByteSize(b)
+		}
+		if err == nil && hi < lo {
+			err = fmt.Errorf("empty range")
+		}
+		return lo, hi, err
+	}
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		rest, ok := strings.CutPrefix(s, op)
+		if !ok {
+			continue
+		}
+		n, err := parseByteSize(rest)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch op {
+		case "<=":
+			return 0, n, nil
+		case ">=":
+			return n, -1, nil
+		case "<":
+			return 0, n - 1, nil
+		default:
+			return n + 1, -1, nil
+		}
+	}
+	n, err := parseByteSize(s)
+	return n, n, err
+}
+
+// parseByteSize parses "512", "512B", "10KB", "1.5MB" or "2GB".
+func parseByteSize(s string) (int64, error) {
+	upper := strings.ToUpper(s)
+	mult := 1.0
+	for _, u := range []struct {
+		suffix string
+		mult   float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper, mult = strings.TrimSuffix(upper, u.suffix), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size such as 10KB or a range such as <1MB or 1KB..100KB")
+	}
+	return int64(n * mult), nil
+}
+
+// unquoteQuery trims whitespace and one pair of surrounding double quotes.
+func unquoteQuery(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
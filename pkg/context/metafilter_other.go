@@ -0,0 +1,10 @@
+//go:build !unix
+
+package context
+
+import "io/fs"
+
+// fileOwnerUID reports no owner: @owner-uid matches nothing off Unix.
+func fileOwnerUID(fs.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,68 @@
+package context
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizeRange(t *testing.T) {
+	for query, want := range map[string][2]int64{
+		"<10KB":      {0, 10239},
+		"<=1K":       {0, 1024},
+		">1MB":       {1<<20 + 1, -1},
+		">= 512":     {512, -1},
+		"1KB..1.5MB": {1024, 3 << 19},
+		`"2GB"`:      {2 << 30, 2 << 30},
+	} {
+		lo, hi, err := parseSizeRange(query)
+		if err != nil || lo != want[0] || hi != want[1] {
+			t.Errorf("parseSizeRange(%q) = %d, %d, %v; want %d, %d", query, lo, hi, err, want[0], want[1])
+		}
+	}
+	for _, query := range []string{"", "big", "<", "-1KB", "10KB..1KB"} {
+		if _, _, err := parseSizeRange(query); err == nil {
+			t.Errorf("parseSizeRange(%q): expected an error", query)
+		}
+	}
+}
+
+func TestParseSearchDirectivesMetadata(t *testing.T) {
+	base, ds, ok := parseSearchDirectives("scripts/* @executable @size: <1MB")
+	assert.True(t, ok)
+	assert.Equal(t, "scripts/*", base)
+	assert.Equal(t, []SearchDirective{{Name: "executable", Query: "true"}, {Name: "size", Query: "<1MB"}}, ds)
+
+	_, ds, _ = parseSearchDirectives("docs/** @newer-than: 14d @executable: false")
+	assert.Equal(t, []SearchDirective{{Name: "newer-than", Query: "14d"}, {Name: "executable", Query: "false"}}, ds)
+}
+
+func TestResolverMetadataDirectives(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"bin/run.sh":     {Data: []byte("#!/bin/sh\n"), Mode: 0o755, ModTime: now},
+		"bin/env.sh":     {Data: []byte("X=1\n"), Mode: 0o644, ModTime: now},
+		"docs/new.md":    {Data: []byte("new\n"), ModTime: now.Add(-time.Hour)},
+		"docs/old.md":    {Data: []byte("old\n"), ModTime: now.Add(-60 * 24 * time.Hour)},
+		"assets/big.svg": {Data: []byte(strings.Repeat("x", 4096)), ModTime: now},
+		"assets/ico.svg": {Data: []byte("<svg/>"), ModTime: now},
+	}
+	rules := strings.Join([]string{
+		"bin/* @executable",
+		"docs/** @newer-than: 7d",
+		"assets/** @size: <1KB",
+		"---",
+		"docs/** @older-than: 30d",
+	}, "\n")
+
+	res, err := NewResolver(fsys).Resolve([]byte(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"assets/ico.svg", "bin/run.sh", "docs/new.md"}, res.Hot)
+	assert.Equal(t, []string{"docs/old.md"}, res.Cold)
+	assert.Empty(t, res.Errors)
+}
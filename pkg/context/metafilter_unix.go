@@ -0,0 +1,17 @@
+//go:build unix
+
+package context
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwnerUID returns the user id owning the file described by info.
+func fileOwnerUID(info fs.FileInfo) (int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Uid), true
+}
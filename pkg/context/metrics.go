@@ -0,0 +1,243 @@
+package context
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MetricsFile is the local, opt-in usage log. One JSON object per line is
+// appended per recorded invocation; nothing is ever sent off the machine.
+const MetricsFile = ".grove/metrics.jsonl"
+
+// metricsEnvVar force-enables metrics recording regardless of config.
+const metricsEnvVar = "CX_METRICS"
+
+// Budget outcomes recorded in UsageMetric.BudgetOutcome.
+const (
+	BudgetWithin   = "within"
+	BudgetExceeded = "exceeded"
+)
+
+// UsageMetric is a single recorded cx invocation.
+type UsageMetric struct {
+	Timestamp     time.Time `json:"ts"`
+	Command       string    `json:"command"`
+	DurationMs    int64     `json:"duration_ms"`
+	HotFiles      int       `json:"hot_files"`
+	ColdFiles     int       `json:"cold_files"`
+	HotTokens     int       `json:"hot_tokens"`
+	ColdTokens    int       `json:"cold_tokens"`
+	TokenBudget   int       `json:"token_budget,omitempty"`
+	BudgetOutcome string    `json:"budget_outcome,omitempty"`
+}
+
+// GenerationSummary captures the size of the most recent generation on a
+// Manager so callers can report on it without resolving the rules again.
+type GenerationSummary struct {
+	HotFiles   int `json:"hot_files"`
+	ColdFiles  int `json:"cold_files"`
+	HotTokens  int `json:"hot_tokens"`
+	ColdTokens int `json:"cold_tokens"`
+}
+
+// WeeklyUsage aggregates UsageMetric records for one ISO week.
+type WeeklyUsage struct {
+	Week          string `json:"week"` // e.g. "2026-W07"
+	Runs          int    `json:"runs"`
+	AvgDurationMs int64  `json:"avg_duration_ms"`
+	MaxDurationMs int64  `json:"max_duration_ms"`
+	AvgHotTokens  int    `json:"avg_hot_tokens"`
+	MaxHotTokens  int    `json:"max_hot_tokens"`
+	AvgFiles      int    `json:"avg_files"`
+	OverBudget    int    `json:"over_budget"`
+}
+
+// MetricsEnabled reports whether usage metrics are recorded for workDir,
+// either via `cx: {metrics: true}` in grove.yml or CX_METRICS=1.
+func MetricsEnabled(workDir string) bool {
+	return LoadCxConfig(workDir).Metrics
+}
+
+// MetricsPath returns the absolute path of the usage metrics log.
+func (m *Manager) MetricsPath() string {
+	return filepath.Join(m.workDir, MetricsFile)
+}
+
+// LastGeneration returns the summary of the most recent generation run on
+// this Manager. It is the zero value if nothing has been generated yet.
+func (m *Manager) LastGeneration() GenerationSummary {
+	m.genMu.Lock()
+	defer m.genMu.Unlock()
+	return m.lastGeneration
+}
+
+// recordGeneration stores the hot or cold half of the generation summary
+// and, when access tracking is on, samples the files' edit activity.
+func (m *Manager) recordGeneration(contextType string, files []string) {
+	tokens := 0
+	provider := GetStatsProvider()
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		if info, err := provider.GetFileStats(path); err == nil {
+			tokens += info.Tokens
+		}
+	}
+
+	if err := m.TrackAccess(files); err != nil {
+		m.log.WithError(err).Warn("failed to update access log")
+	}
+
+	m.genMu.Lock()
+	defer m.genMu.Unlock()
+	if m.lastGenerated == nil {
+		m.lastGenerated = make(map[string][]string)
+	}
+	m.lastGenerated[contextType] = files
+	if contextType == "cold" {
+		m.lastGeneration.ColdFiles = len(files)
+		m.lastGeneration.ColdTokens = tokens
+	} else {
+		m.lastGeneration.HotFiles = len(files)
+		m.lastGeneration.HotTokens = tokens
+	}
+}
+
+// RecordUsage appends a metric for command (started at start) to the local
+// metrics log, using the last generation summary for file and token counts.
+// It is a no-op unless metrics are enabled for this workspace, and always
+// for a WithNoState manager.
+func (m *Manager) RecordUsage(command string, start time.Time) error {
+	if m.noState || !MetricsEnabled(m.workDir) {
+		return nil
+	}
+
+	gen := m.LastGeneration()
+	metric := UsageMetric{
+		Timestamp:  start.UTC(),
+		Command:    command,
+		DurationMs: time.Since(start).Milliseconds(),
+		HotFiles:   gen.HotFiles,
+		ColdFiles:  gen.ColdFiles,
+		HotTokens:  gen.HotTokens,
+		ColdTokens: gen.ColdTokens,
+	}
+	if budget := m.cxConfig().TokenBudget; budget > 0 {
+		metric.TokenBudget = budget
+		metric.BudgetOutcome = BudgetWithin
+		if gen.HotTokens > budget {
+			metric.BudgetOutcome = BudgetExceeded
+		}
+	}
+
+	return AppendUsageMetric(m.MetricsPath(), metric)
+}
+
+// AppendUsageMetric appends metric as a single JSON line to path.
+func AppendUsageMetric(path string, metric UsageMetric) error {
+	data, err := json.Marshal(metric)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage metric: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage metric: %w", err)
+	}
+	return nil
+}
+
+// ReadUsageMetrics loads every record from a metrics log. Malformed lines
+// (e.g. a partial write from an interrupted run) are skipped.
+func ReadUsageMetrics(path string) ([]UsageMetric, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var metrics []UsageMetric
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var metric UsageMetric
+		if err := json.Unmarshal(scanner.Bytes(), &metric); err != nil {
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, scanner.Err()
+}
+
+// SummarizeUsageByWeek groups metrics by ISO week, oldest first.
+func SummarizeUsageByWeek(metrics []UsageMetric) []WeeklyUsage {
+	type acc struct {
+		WeeklyUsage
+		totalDuration int64
+		totalTokens   int
+		totalFiles    int
+	}
+	byWeek := make(map[string]*acc)
+	for _, metric := range metrics {
+		year, week := metric.Timestamp.ISOWeek()
+		key := fmt.Sprintf("%04d-W%02d", year, week)
+		a, ok := byWeek[key]
+		if !ok {
+			a = &acc{WeeklyUsage: WeeklyUsage{Week: key}}
+			byWeek[key] = a
+		}
+		a.Runs++
+		a.totalDuration += metric.DurationMs
+		a.totalTokens += metric.HotTokens
+		a.totalFiles += metric.HotFiles + metric.ColdFiles
+		if metric.DurationMs > a.MaxDurationMs {
+			a.MaxDurationMs = metric.DurationMs
+		}
+		if metric.HotTokens > a.MaxHotTokens {
+			a.MaxHotTokens = metric.HotTokens
+		}
+		if metric.BudgetOutcome == BudgetExceeded {
+			a.OverBudget++
+		}
+	}
+
+	weeks := make([]WeeklyUsage, 0, len(byWeek))
+	for _, a := range byWeek {
+		a.AvgDurationMs = a.totalDuration / int64(a.Runs)
+		a.AvgHotTokens = a.totalTokens / a.Runs
+		a.AvgFiles = a.totalFiles / a.Runs
+		weeks = append(weeks, a.WeeklyUsage)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Week < weeks[j].Week })
+	return weeks
+}
+
+// PrintUsageSummary writes a plain-text weekly usage table to w.
+func PrintUsageSummary(w io.Writer, weeks []WeeklyUsage) {
+	fmt.Fprintf(w, "%-9s  %5s  %9s  %9s  %10s  %10s  %6s  %11s\n",
+		"WEEK", "RUNS", "AVG TIME", "MAX TIME", "AVG TOKENS", "MAX TOKENS", "FILES", "OVER BUDGET")
+	for _, wk := range weeks {
+		fmt.Fprintf(w, "%-9s  %5d  %9s  %9s  %10s  %10s  %6d  %11d\n",
+			wk.Week, wk.Runs,
+			formatDurationMs(wk.AvgDurationMs), formatDurationMs(wk.MaxDurationMs),
+			FormatTokenCount(wk.AvgHotTokens), FormatTokenCount(wk.MaxHotTokens),
+			wk.AvgFiles, wk.OverBudget)
+	}
+}
+
+func formatDurationMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(10 * time.Millisecond).String()
+}
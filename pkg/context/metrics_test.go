@@ -0,0 +1,72 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestUsageMetrics_RoundTripSkipsMalformed verifies that appended metrics
+// read back intact and that a truncated line does not poison the log.
+func TestUsageMetrics_RoundTripSkipsMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".grove", "metrics.jsonl")
+	ts := time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	if err := AppendUsageMetric(path, UsageMetric{Timestamp: ts, Command: "generate", HotTokens: 100}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{\"ts\":\"2026-02\n")
+	f.Close()
+	if err := AppendUsageMetric(path, UsageMetric{Timestamp: ts, Command: "generate", HotTokens: 300}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	metrics, err := ReadUsageMetrics(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("got %d metrics, want 2 (malformed line skipped)", len(metrics))
+	}
+	if metrics[1].HotTokens != 300 || metrics[1].Command != "generate" {
+		t.Errorf("unexpected second metric: %+v", metrics[1])
+	}
+}
+
+// TestSummarizeUsageByWeek verifies ISO-week grouping, averages, maxima and
+// over-budget counting, with weeks ordered oldest first.
+func TestSummarizeUsageByWeek(t *testing.T) {
+	week1 := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC) // 2026-W07
+	week2 := week1.AddDate(0, 0, 7)                       // 2026-W08
+
+	weeks := SummarizeUsageByWeek([]UsageMetric{
+		{Timestamp: week2, DurationMs: 50, HotTokens: 10, HotFiles: 1},
+		{Timestamp: week1, DurationMs: 100, HotTokens: 1000, HotFiles: 4, BudgetOutcome: BudgetWithin},
+		{Timestamp: week1, DurationMs: 300, HotTokens: 3000, HotFiles: 6, BudgetOutcome: BudgetExceeded},
+	})
+
+	if len(weeks) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(weeks))
+	}
+	first := weeks[0]
+	if first.Week != "2026-W07" || first.Runs != 2 {
+		t.Fatalf("unexpected first week: %+v", first)
+	}
+	if first.AvgDurationMs != 200 || first.MaxDurationMs != 300 {
+		t.Errorf("duration avg/max = %d/%d, want 200/300", first.AvgDurationMs, first.MaxDurationMs)
+	}
+	if first.AvgHotTokens != 2000 || first.MaxHotTokens != 3000 {
+		t.Errorf("tokens avg/max = %d/%d, want 2000/3000", first.AvgHotTokens, first.MaxHotTokens)
+	}
+	if first.AvgFiles != 5 || first.OverBudget != 1 {
+		t.Errorf("files/over-budget = %d/%d, want 5/1", first.AvgFiles, first.OverBudget)
+	}
+	if weeks[1].Week != "2026-W08" || weeks[1].Runs != 1 {
+		t.Errorf("unexpected second week: %+v", weeks[1])
+	}
+}
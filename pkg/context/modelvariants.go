@@ -0,0 +1,213 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Per-model variants. With budgets for several models, one generation run
+// writes a hot context variant per model next to the regular artifact,
+// dropping the lowest-priority files until each fits its budget. Files are
+// ranked as they are generated: by frontmatter priority, then rule order, so
+// the last files are dropped first. Files declared with @require: are never
+// dropped; a variant whose required files alone exceed the budget is written
+// anyway and reported as over budget.
+//
+//	cx:
+//	  model_budgets:
+//	    claude: 200000
+//	    gpt-4o: 128000
+//
+// `cx generate --all-models` then writes .grove/context.claude,
+// .grove/context.gpt-4o and .grove/context.models.json, the report of what
+// each variant omitted.
+
+// ModelBudgetsReportExt is appended to the hot context path to name the
+// per-model variant report.
+const ModelBudgetsReportExt = ".models.json"
+
+// ModelBudget is the hot-context token budget of one model.
+type ModelBudget struct {
+	Model  string `json:"model"`
+	Tokens int    `json:"tokens"`
+}
+
+// ParseModelBudget parses a --model value: "name=tokens", where tokens may
+// use a k or m suffix ("claude=200k"), or a bare name looked up in the
+// configured budgets.
+func ParseModelBudget(spec string, configured map[string]int) (ModelBudget, error) {
+	name, value, hasValue := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ModelBudget{}, fmt.Errorf("invalid model budget %q: missing model name", spec)
+	}
+	if !hasValue {
+		tokens, ok := configured[name]
+		if !ok {
+			return ModelBudget{}, fmt.Errorf("no budget for model %q: pass %s=<tokens> or add it to cx.model_budgets", name, name)
+		}
+		return ModelBudget{Model: name, Tokens: tokens}, nil
+	}
+	tokens, err := ParseTokenCount(value)
+	if err != nil {
+		return ModelBudget{}, fmt.Errorf("invalid model budget %q: %w", spec, err)
+	}
+	return ModelBudget{Model: name, Tokens: tokens}, nil
+}
+
+// ConfiguredModelBudgets returns cx.model_budgets sorted by model name.
+func ConfiguredModelBudgets(configured map[string]int) []ModelBudget {
+	budgets := make([]ModelBudget, 0, len(configured))
+	for name, tokens := range configured {
+		budgets = append(budgets, ModelBudget{Model: name, Tokens: tokens})
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Model < budgets[j].Model })
+	return budgets
+}
+
+// ParseTokenCount parses a positive token count such as 128000, 128k or
+// 1.5m.
+func ParseTokenCount(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		mult, s = 1e6, strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a positive token count such as 128000 or 128k")
+	}
+	return int(n * mult), nil
+}
+
+// OmittedFile is a file dropped from a model variant.
+type OmittedFile struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+}
+
+// ModelVariant describes the hot context written for one model.
+type ModelVariant struct {
+	Model      string        `json:"model"`
+	Budget     int           `json:"budget"`
+	Tokens     int           `json:"tokens"` // estimated tokens of the kept files and trees
+	Files      int           `json:"files"`
+	Path       string        `json:"path"`
+	OverBudget bool          `json:"over_budget,omitempty"` // required files alone exceed the budget
+	Omitted    []OmittedFile `json:"omitted,omitempty"`
+}
+
+// ModelVariantPath returns where the variant for model is written: the hot
+// context path with the model name appended.
+func (m *Manager) ModelVariantPath(model string) string {
+	return m.ResolveContextWritePath() + "." + sanitizeArtifactVar(model)
+}
+
+// GenerateModelVariants writes a hot context variant per budget from the
+// active rules, and a JSON report of the variants next to the hot context.
+// The regular hot context and the last generation summary are unchanged.
+func (m *Manager) GenerateModelVariants(budgets []ModelBudget, useXMLFormat bool) ([]ModelVariant, error) {
+	files, treePaths, err := m.ResolveFilesAndTreesFromRules()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving files from rules: %w", err)
+	}
+	rulesContent, _, _ := m.LoadRulesContent()
+	if err := m.checkRequiredFiles(rulesContent, "hot", files); err != nil {
+		return nil, err
+	}
+	required := make(map[string]bool)
+	for _, p := range RequiredPaths(rulesContent) {
+		required[m.requirePathKey(p)] = true
+	}
+	isRequired := func(f string) bool { return required[m.requirePathKey(f)] }
+
+	tokens := make(map[string]int, len(files))
+	provider := GetStatsProvider()
+	for _, f := range files {
+		if info, err := provider.GetFileStats(m.requirePathKey(f)); err == nil {
+			tokens[f] = info.Tokens
+		}
+	}
+	treeTokens := 0
+	for _, tp := range treePaths {
+		if tree, err := m.GenerateTreeString(tp); err == nil {
+			treeTokens += EstimateTokens("", int64(len(tree)))
+		}
+	}
+
+	reportPath := m.ResolveContextWritePath() + ModelBudgetsReportExt
+	savedPath, savedGen := m.hotPathOverride, m.LastGeneration()
+	defer func() {
+		m.hotPathOverride = savedPath
+		m.genMu.Lock()
+		m.lastGeneration = savedGen
+		m.genMu.Unlock()
+	}()
+
+	variants := make([]ModelVariant, 0, len(budgets))
+	for _, b := range budgets {
+		path := m.ModelVariantPath(b.Model)
+		kept, omitted, total := fitBudget(files, tokens, isRequired, b.Tokens-treeTokens)
+		m.hotPathOverride = path
+		if err := m.generateContextFromFilesAndTrees(kept, treePaths, useXMLFormat); err != nil {
+			return nil, fmt.Errorf("error generating context for model %s: %w", b.Model, err)
+		}
+		variants = append(variants, ModelVariant{
+			Model:      b.Model,
+			Budget:     b.Tokens,
+			Tokens:     total + treeTokens,
+			Files:      len(kept),
+			Path:       path,
+			OverBudget: total+treeTokens > b.Tokens,
+			Omitted:    omitted,
+		})
+	}
+
+	data, err := json.MarshalIndent(variants, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(reportPath, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", reportPath, err)
+	}
+	return variants, nil
+}
+
+// fitBudget drops files from the end of files, skipping required ones,
+// until their tokens fit budget. It returns the kept files in their
+// original order, the omitted ones in the order they were dropped, and the
+// tokens of the kept files.
+func fitBudget(files []string, tokens map[string]int, required func(string) bool, budget int) ([]string, []OmittedFile, int) {
+	total := 0
+	for _, f := range files {
+		total += tokens[f]
+	}
+	drop := make(map[int]bool)
+	var omitted []OmittedFile
+	for i := len(files) - 1; i >= 0 && total > budget; i-- {
+		if required(files[i]) {
+			continue
+		}
+		drop[i] = true
+		total -= tokens[files[i]]
+		omitted = append(omitted, OmittedFile{Path: files[i], Tokens: tokens[files[i]]})
+	}
+	kept := make([]string, 0, len(files)-len(drop))
+	for i, f := range files {
+		if !drop[i] {
+			kept = append(kept, f)
+		}
+	}
+	return kept, omitted, total
+}
@@ -0,0 +1,51 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseModelBudget(t *testing.T) {
+	configured := map[string]int{"claude": 200000}
+	for spec, want := range map[string]ModelBudget{
+		"claude":        {"claude", 200000},
+		"claude=150k":   {"claude", 150000},
+		"gpt-4o=128000": {"gpt-4o", 128000},
+		"gemini=1.5m":   {"gemini", 1500000},
+		" local = 32K ": {"local", 32000},
+	} {
+		got, err := ParseModelBudget(spec, configured)
+		if err != nil || got != want {
+			t.Errorf("ParseModelBudget(%q) = %+v, %v; want %+v", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"gpt-4o", "=100", "claude=", "claude=-5", "claude=lots"} {
+		if _, err := ParseModelBudget(spec, configured); err == nil {
+			t.Errorf("ParseModelBudget(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestFitBudget(t *testing.T) {
+	files := []string{"a.go", "b.go", "req.go", "c.go", "d.go"}
+	tokens := map[string]int{"a.go": 100, "b.go": 50, "req.go": 400, "c.go": 200, "d.go": 30}
+	required := func(f string) bool { return f == "req.go" }
+
+	kept, omitted, total := fitBudget(files, tokens, required, 600)
+	if want := []string{"a.go", "b.go", "req.go"}; !reflect.DeepEqual(kept, want) || total != 550 {
+		t.Errorf("kept %v (%d tokens), want %v (550)", kept, total, want)
+	}
+	if want := []OmittedFile{{"d.go", 30}, {"c.go", 200}}; !reflect.DeepEqual(omitted, want) {
+		t.Errorf("omitted %v, want %v", omitted, want)
+	}
+
+	kept, omitted, total = fitBudget(files, tokens, required, 1000)
+	if len(kept) != len(files) || omitted != nil || total != 780 {
+		t.Errorf("within budget: kept %v, omitted %v, total %d", kept, omitted, total)
+	}
+
+	kept, _, total = fitBudget(files, tokens, required, 100)
+	if !reflect.DeepEqual(kept, []string{"req.go"}) || total != 400 {
+		t.Errorf("required over budget: kept %v (%d tokens), want only req.go", kept, total)
+	}
+}
@@ -0,0 +1,177 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NotesFile holds pinned notes: short, persistent annotations on files and
+// directories recording why they are included or excluded. Notes are shown in
+// the TUI tree and `cx note list`; they only enter the context when
+// `cx: {notes_in_context: true}` is set, as the consolidated NotesExportFile.
+const NotesFile = ".grove/notes.json"
+
+// NotesExportFile is the consolidated markdown rendering of the notes that
+// generation adds to the hot context when notes_in_context is enabled.
+const NotesExportFile = ".grove/notes.md"
+
+// Note is a pinned note on one file or directory.
+type Note struct {
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Notes maps workspace-relative slash paths to their pinned notes.
+type Notes struct {
+	Files map[string]*Note `json:"files"`
+}
+
+// NotesPath returns the absolute path of the notes file.
+func (m *Manager) NotesPath() string {
+	return filepath.Join(m.workDir, NotesFile)
+}
+
+// NoteKey maps a file or directory path to its key in the notes file.
+func (m *Manager) NoteKey(path string) string {
+	return strings.TrimSuffix(m.accessKey(path), "/")
+}
+
+// ReadNotes loads the notes file at path. A missing file has no notes.
+func ReadNotes(path string) (*Notes, error) {
+	notes := &Notes{Files: make(map[string]*Note)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, notes); err != nil {
+		return nil, fmt.Errorf("invalid notes file %s: %w", path, err)
+	}
+	if notes.Files == nil {
+		notes.Files = make(map[string]*Note)
+	}
+	return notes, nil
+}
+
+func writeNotes(path string, notes *Notes) error {
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notes: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // project notes, not sensitive
+}
+
+// LoadNotes returns the project's pinned notes.
+func (m *Manager) LoadNotes() (*Notes, error) {
+	return ReadNotes(m.NotesPath())
+}
+
+// SetNote pins text to path, replacing any existing note. Empty text removes
+// the note.
+func (m *Manager) SetNote(path, text string) error {
+	notes, err := m.LoadNotes()
+	if err != nil {
+		return err
+	}
+	key := m.NoteKey(path)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if _, ok := notes.Files[key]; !ok {
+			return nil
+		}
+		delete(notes.Files, key)
+	} else {
+		notes.Files[key] = &Note{Text: text, UpdatedAt: time.Now().UTC()}
+	}
+	return writeNotes(m.NotesPath(), notes)
+}
+
+// RemoveNote removes the note pinned to path, reporting whether there was one.
+func (m *Manager) RemoveNote(path string) (bool, error) {
+	notes, err := m.LoadNotes()
+	if err != nil {
+		return false, err
+	}
+	key := m.NoteKey(path)
+	if _, ok := notes.Files[key]; !ok {
+		return false, nil
+	}
+	delete(notes.Files, key)
+	return true, writeNotes(m.NotesPath(), notes)
+}
+
+// Paths returns the noted paths, sorted.
+func (n *Notes) Paths() []string {
+	paths := make([]string, 0, len(n.Files))
+	for p := range n.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Markdown renders the notes as a single markdown document, one section per
+// path. It returns nil when there are no notes.
+func (n *Notes) Markdown() []byte {
+	if len(n.Files) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Project notes\n\nPinned notes on files and directories in this project (`cx note`).\n")
+	for _, p := range n.Paths() {
+		fmt.Fprintf(&buf, "\n## %s\n\n%s\n", p, n.Files[p].Text)
+	}
+	return buf.Bytes()
+}
+
+// ExportNotes writes the consolidated notes to NotesExportFile and returns
+// its path, or "" (removing a stale export) when there are no notes.
+func (m *Manager) ExportNotes() (string, error) {
+	notes, err := m.LoadNotes()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(m.workDir, NotesExportFile)
+	data := notes.Markdown()
+	if data == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		return "", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // project notes, not sensitive
+		return "", err
+	}
+	return path, nil
+}
+
+// withNotes appends the exported notes to the hot files when notes_in_context
+// is enabled. Failing to export only warns: notes never block generation.
+func (m *Manager) withNotes(hot []string) []string {
+	if !m.cxConfig().NotesInContext {
+		return hot
+	}
+	path, err := m.ExportNotes()
+	if err != nil {
+		m.log.WithError(err).Warn("failed to export pinned notes")
+		return hot
+	}
+	if path == "" {
+		return hot
+	}
+	return append(hot, path)
+}
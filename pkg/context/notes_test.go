@@ -0,0 +1,85 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNotesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir)
+
+	if notes, err := m.LoadNotes(); err != nil || len(notes.Files) != 0 {
+		t.Fatalf("missing notes file should be empty, got %+v, %v", notes, err)
+	}
+	if err := m.SetNote(filepath.Join(dir, "internal", "billing")+"/", "sprint focus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetNote("vendor", "  third-party  "); err != nil {
+		t.Fatal(err)
+	}
+	notes, err := m.LoadNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := notes.Paths(); !reflect.DeepEqual(got, []string{"internal/billing", "vendor"}) {
+		t.Errorf("Paths() = %v", got)
+	}
+	if notes.Files["vendor"].Text != "third-party" {
+		t.Errorf("note text should be trimmed, got %q", notes.Files["vendor"].Text)
+	}
+
+	if removed, err := m.RemoveNote("vendor"); err != nil || !removed {
+		t.Errorf("RemoveNote(vendor) = %v, %v", removed, err)
+	}
+	if removed, _ := m.RemoveNote("vendor"); removed {
+		t.Error("removing a missing note should report false")
+	}
+	if err := m.SetNote("internal/billing", ""); err != nil {
+		t.Fatal(err)
+	}
+	if notes, _ := m.LoadNotes(); len(notes.Files) != 0 {
+		t.Errorf("empty text should remove the note, got %v", notes.Paths())
+	}
+}
+
+// TestWithNotes verifies that notes only enter the hot files when
+// notes_in_context is enabled.
+func TestWithNotes(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	m := NewManager(dir)
+	if err := m.SetNote("main.go", "entry point"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.withNotes([]string{"main.go"}); len(got) != 1 {
+		t.Errorf("notes added without notes_in_context: %v", got)
+	}
+
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  notes_in_context: true\n")
+	got := m.withNotes([]string{"main.go"})
+	want := filepath.Join(dir, NotesExportFile)
+	if len(got) != 2 || got[1] != want {
+		t.Fatalf("withNotes = %v, want the export appended", got)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "## main.go\n\nentry point\n") {
+		t.Errorf("unexpected export:\n%s", data)
+	}
+
+	if _, err := m.RemoveNote("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.withNotes(nil); len(got) != 0 {
+		t.Errorf("no notes should add nothing, got %v", got)
+	}
+	if _, err := os.Stat(want); !os.IsNotExist(err) {
+		t.Error("a stale export should be removed")
+	}
+}
@@ -0,0 +1,184 @@
+package context
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Notification events sent by `cx watch`.
+const (
+	EventRegenerated = "regenerated"
+	EventOverBudget  = "over_budget"
+)
+
+// NotifyConfig selects where watch-mode notifications go. It is read from
+// `cx.notify` in grove.yml; command-line flags override it.
+//
+//	cx:
+//	  notify:
+//	    desktop: true
+//	    command: ./scripts/on-context.sh
+//	    webhook: http://127.0.0.1:9000/hooks/cx
+//	    budget_only: true
+type NotifyConfig struct {
+	// Desktop shows a native notification (notify-send or osascript).
+	Desktop bool `yaml:"desktop,omitempty" toml:"desktop,omitempty"`
+	// Command is run through the shell with the event as JSON on stdin and
+	// CX_EVENT, CX_HOT_TOKENS and CX_TOKEN_BUDGET in the environment.
+	Command string `yaml:"command,omitempty" toml:"command,omitempty"`
+	// Webhook receives the event as a JSON POST.
+	Webhook string `yaml:"webhook,omitempty" toml:"webhook,omitempty"`
+	// BudgetOnly suppresses regenerated events, notifying only when the hot
+	// context crosses the token budget.
+	BudgetOnly bool `yaml:"budget_only,omitempty" toml:"budget_only,omitempty"`
+}
+
+// Enabled reports whether any notification target is configured.
+func (c NotifyConfig) Enabled() bool {
+	return c.Desktop || c.Command != "" || c.Webhook != ""
+}
+
+// NotifyEvent is the payload delivered to every notification target.
+type NotifyEvent struct {
+	Event       string    `json:"event"`
+	Timestamp   time.Time `json:"ts"`
+	WorkDir     string    `json:"work_dir"`
+	HotFiles    int       `json:"hot_files"`
+	ColdFiles   int       `json:"cold_files"`
+	HotTokens   int       `json:"hot_tokens"`
+	ColdTokens  int       `json:"cold_tokens"`
+	TokenBudget int       `json:"token_budget,omitempty"`
+}
+
+// Message is the one-line human summary used for desktop notifications.
+func (e NotifyEvent) Message() string {
+	if e.Event == EventOverBudget {
+		return fmt.Sprintf("Hot context is ~%s tokens, over the %s budget",
+			FormatTokenCount(e.HotTokens), FormatTokenCount(e.TokenBudget))
+	}
+	return fmt.Sprintf("Context regenerated: %d hot files (~%s tokens), %d cold files",
+		e.HotFiles, FormatTokenCount(e.HotTokens), e.ColdFiles)
+}
+
+// Notifier delivers watch-mode events. It remembers whether the last
+// generation was over budget so over_budget fires once per crossing rather
+// than on every regeneration.
+type Notifier struct {
+	cfg        NotifyConfig
+	client     *http.Client
+	overBudget bool
+}
+
+// NewNotifier returns a Notifier for cfg.
+func NewNotifier(cfg NotifyConfig) *Notifier {
+	return &Notifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Generated reports a finished regeneration of workDir. It sends a
+// regenerated event (unless BudgetOnly) and, when the hot tokens newly exceed
+// budget, an over_budget event. Delivery failures are returned joined; they
+// never stop the watch.
+func (n *Notifier) Generated(ctx gocontext.Context, workDir string, gen GenerationSummary, budget int) error {
+	base := NotifyEvent{
+		Timestamp:   time.Now().UTC(),
+		WorkDir:     workDir,
+		HotFiles:    gen.HotFiles,
+		ColdFiles:   gen.ColdFiles,
+		HotTokens:   gen.HotTokens,
+		ColdTokens:  gen.ColdTokens,
+		TokenBudget: budget,
+	}
+
+	var errs []error
+	if !n.cfg.BudgetOnly {
+		e := base
+		e.Event = EventRegenerated
+		errs = append(errs, n.send(ctx, e))
+	}
+	over := budget > 0 && gen.HotTokens > budget
+	if over && !n.overBudget {
+		e := base
+		e.Event = EventOverBudget
+		errs = append(errs, n.send(ctx, e))
+	}
+	n.overBudget = over
+	return errors.Join(errs...)
+}
+
+// send delivers e to every configured target.
+func (n *Notifier) send(ctx gocontext.Context, e NotifyEvent) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if n.cfg.Desktop {
+		if err := desktopNotify(ctx, "cx", e.Message()); err != nil {
+			errs = append(errs, fmt.Errorf("desktop notification: %w", err))
+		}
+	}
+	if n.cfg.Command != "" {
+		if err := n.runCommand(ctx, e, payload); err != nil {
+			errs = append(errs, fmt.Errorf("notify command: %w", err))
+		}
+	}
+	if n.cfg.Webhook != "" {
+		if err := n.postWebhook(ctx, payload); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) runCommand(ctx gocontext.Context, e NotifyEvent, payload []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.cfg.Command)
+	cmd.Dir = e.WorkDir
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CX_EVENT="+e.Event,
+		"CX_HOT_TOKENS="+strconv.Itoa(e.HotTokens),
+		"CX_TOKEN_BUDGET="+strconv.Itoa(e.TokenBudget),
+	)
+	return cmd.Run()
+}
+
+func (n *Notifier) postWebhook(ctx gocontext.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// desktopNotify shows a native notification on macOS and Linux.
+func desktopNotify(ctx gocontext.Context, title, message string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		return exec.CommandContext(ctx, "osascript", "-e", script).Run()
+	case "linux":
+		return exec.CommandContext(ctx, "notify-send", title, message).Run()
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+}
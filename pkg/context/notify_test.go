@@ -0,0 +1,80 @@
+package context
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNotifierWebhookAndBudgetCrossing(t *testing.T) {
+	var mu sync.Mutex
+	var events []NotifyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e NotifyEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("decoding webhook body: %v", err)
+		}
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	n := NewNotifier(NotifyConfig{Webhook: srv.URL})
+	ctx := gocontext.Background()
+	for _, hot := range []int{50, 150, 200, 80, 120} {
+		if err := n.Generated(ctx, "/work", GenerationSummary{HotTokens: hot}, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Event)
+	}
+	want := []string{
+		EventRegenerated,
+		EventRegenerated, EventOverBudget,
+		EventRegenerated,
+		EventRegenerated,
+		EventRegenerated, EventOverBudget,
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestNotifierCommandBudgetOnly(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "events")
+	n := NewNotifier(NotifyConfig{
+		Command:    `printf '%s %s\n' "$CX_EVENT" "$CX_HOT_TOKENS" >> events`,
+		BudgetOnly: true,
+	})
+	ctx := gocontext.Background()
+	for _, hot := range []int{10, 500} {
+		if err := n.Generated(ctx, dir, GenerationSummary{HotTokens: hot}, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "over_budget 500\n" {
+		t.Errorf("command output = %q", got)
+	}
+}
+
+func TestNotifierReportsDeliveryErrors(t *testing.T) {
+	n := NewNotifier(NotifyConfig{Command: "exit 3"})
+	if err := n.Generated(gocontext.Background(), t.TempDir(), GenerationSummary{}, 0); err == nil {
+		t.Error("expected the failing command to be reported")
+	}
+}
@@ -0,0 +1,148 @@
+package context
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/grovetools/core/config"
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/grovetools/core/util/pathutil"
+)
+
+// ManagerOption configures a Manager built for library use. Passing any
+// option to NewManager returns a fresh, uncached instance: options are
+// per-embedder and must never leak into the shared per-workDir cache.
+type ManagerOption func(*managerOptions)
+
+type managerOptions struct {
+	rules        []byte
+	hasRules     bool
+	noState      bool
+	allowedRoots []string
+	hasRoots     bool
+	strictWalk   bool
+}
+
+// WithRules supplies the rules content directly. It stands in for the
+// project's active rules file (.grove/rules under workDir), so every
+// resolution method works from the given bytes and no rules-file discovery
+// runs. @import and @include lines are still read from disk.
+func WithRules(content []byte) ManagerOption {
+	return func(o *managerOptions) {
+		o.rules = append([]byte(nil), content...)
+		o.hasRules = true
+	}
+}
+
+// WithNoState makes the Manager a pure function of its inputs: it skips
+// grove.yml/grove.toml, .grove/state, the active plan, notebook lookups,
+// workspace discovery and environment overrides. Aliases (@a:) cannot be
+// resolved in this mode, and without WithAllowedRoots only workDir is an
+// allowed root. Intended for the LSP and server modes, which must classify
+// deterministically.
+func WithNoState() ManagerOption {
+	return func(o *managerOptions) {
+		o.noState = true
+	}
+}
+
+// WithAllowedRoots fixes the directories context may be drawn from,
+// replacing workspace discovery and the included/excluded workspace and
+// allowed_paths settings.
+func WithAllowedRoots(roots ...string) ManagerOption {
+	return func(o *managerOptions) {
+		o.allowedRoots = append([]string(nil), roots...)
+		o.hasRoots = true
+	}
+}
+
+// WithStrictWalk makes resolution fail on the first file or directory a
+// walk cannot read, instead of skipping it with a warning.
+func WithStrictWalk() ManagerOption {
+	return func(o *managerOptions) {
+		o.strictWalk = true
+	}
+}
+
+// errNoStateAliases is returned when an alias is resolved on a WithNoState
+// Manager, which has no workspace discovery to resolve it against.
+var errNoStateAliases = errors.New("aliases cannot be resolved without workspace discovery (manager built WithNoState)")
+
+// errNoStateWorkspace is returned by projectNode on a WithNoState Manager.
+var errNoStateWorkspace = errors.New("workspace lookup is disabled (manager built WithNoState)")
+
+// newManagerWithOptions builds the uncached Manager behind NewManager's
+// option form.
+func newManagerWithOptions(workDir string, opts []ManagerOption) *Manager {
+	var o managerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	workDir, _ = normalizeManagerInputs(workDir, "")
+
+	var mgr *Manager
+	if o.noState {
+		mgr = newManagerFromConfig(workDir, "", &config.Config{})
+	} else {
+		mgr = newManagerInstance(workDir, "")
+	}
+	mgr.noState = o.noState
+	mgr.suppliedRules = o.rules
+	mgr.hasSuppliedRules = o.hasRules
+	mgr.strictWalk = o.strictWalk
+
+	if o.hasRoots || o.noState {
+		roots, origin := o.allowedRoots, "WithAllowedRoots"
+		if !o.hasRoots {
+			roots, origin = []string{workDir}, "working directory (WithNoState)"
+		}
+		mgr.rootsOnce.Do(func() {
+			mgr.allowedRoots = canonicalRoots(workDir, roots)
+			mgr.rootOrigins = make(map[string]string)
+			for _, root := range mgr.allowedRoots {
+				mgr.rootOrigins[root] = origin
+			}
+		})
+	}
+	return mgr
+}
+
+// canonicalRoots makes roots absolute (relative ones against workDir) and
+// normalized the same way discovered workspace roots are.
+func canonicalRoots(workDir string, roots []string) []string {
+	out := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(workDir, root)
+		}
+		if canonical, err := pathutil.NormalizeForLookup(root); err == nil {
+			root = canonical
+		}
+		out = append(out, filepath.Clean(root))
+	}
+	return out
+}
+
+// suppliedRulesPath is the path the WithRules content stands in for.
+func (m *Manager) suppliedRulesPath() string {
+	return filepath.Join(m.workDir, ActiveRulesFile)
+}
+
+// readRulesFile reads a rules file, serving the WithRules content for the
+// path it stands in for.
+func (m *Manager) readRulesFile(path string) ([]byte, error) {
+	if m.hasSuppliedRules && path == m.suppliedRulesPath() {
+		return m.suppliedRules, nil
+	}
+	return os.ReadFile(path)
+}
+
+// projectNode looks up the workspace node for workDir. A WithNoState manager
+// has no workspace discovery, so it never finds one.
+func (m *Manager) projectNode() (*workspace.WorkspaceNode, error) {
+	if m.noState {
+		return nil, errNoStateWorkspace
+	}
+	return workspace.GetProjectByPath(m.workDir)
+}
@@ -0,0 +1,94 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestNewManagerLibraryOptions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".grove/rules", "*.md\n")
+	write("a.go", "package a\n")
+	write("b.go", "package a\n")
+	write("README.md", "# readme\n")
+
+	m := NewManager(dir, WithRules([]byte("*.go\n")), WithNoState())
+	if m == NewManager(dir, WithNoState()) || m == NewManager(dir) {
+		t.Fatal("managers built with options must not be cached")
+	}
+	if plan := m.GetActivePlanName(); plan != "" {
+		t.Errorf("expected no active plan without state, got %q", plan)
+	}
+
+	files, err := m.ResolveFilesFromRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "a.go" || names[1] != "b.go" {
+		t.Errorf("supplied rules should replace .grove/rules, got %v", names)
+	}
+
+	roots, err := m.GetAllowedRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || filepath.Base(roots[0]) != filepath.Base(dir) {
+		t.Errorf("expected workDir as the only allowed root, got %v", roots)
+	}
+
+	other := t.TempDir()
+	m = NewManager(dir, WithNoState(), WithAllowedRoots(dir, other))
+	if roots, _ := m.GetAllowedRoots(); len(roots) != 2 {
+		t.Errorf("expected the two supplied roots, got %v", roots)
+	}
+	if ok, reason := m.IsPathAllowed(filepath.Join(other, "x.go")); !ok {
+		t.Errorf("path under a supplied root should be allowed: %s", reason)
+	}
+	if content, _, err := m.LoadRulesContent(); err != nil || string(content) != "*.md\n" {
+		t.Errorf("without WithRules a stateless manager reads workDir's rules, got %q (%v)", content, err)
+	}
+	if _, err := m.ResolveProjectAlias("some-project"); err == nil {
+		t.Error("expected alias resolution to fail without state")
+	}
+}
+
+// TestNoStateIgnoresCxConfig verifies that a WithNoState manager ignores the
+// cx settings of grove.yml and their environment overrides.
+func TestNoStateIgnoresCxConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ContentSafetyEnvVar, SafetyOff)
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  order: stable\n  binary:\n    extensions: [log]\n")
+
+	m := NewManager(dir)
+	if !m.stableOrderEnabled() || !m.binaryPolicy().binary[".log"] {
+		t.Fatal("expected a stateful manager to read cx.order and cx.binary")
+	}
+
+	m = NewManager(dir, WithNoState())
+	if m.stableOrderEnabled() {
+		t.Error("WithNoState manager read cx.order")
+	}
+	if m.binaryPolicy().binary[".log"] {
+		t.Error("WithNoState manager read cx.binary")
+	}
+	if policy := m.contentScreen().policy; policy != SafetyWarn {
+		t.Errorf("WithNoState content safety policy = %q, want %q", policy, SafetyWarn)
+	}
+}
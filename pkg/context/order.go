@@ -0,0 +1,74 @@
+package context
+
+// File order. Resolution sorts each tier alphabetically, so adding one file
+// shifts every file after it and a provider's prompt cache, which matches on
+// the longest unchanged prefix, misses from that point on. With `order:
+// stable` the files of each tier keep the order of the artifact they
+// replace: files still in the tier stay where they were, new files are
+// appended at the end of the tier in alphabetical order, and removed files
+// leave the others in place. A first generation, or one whose previous
+// artifact is unreadable, is alphabetical.
+
+const (
+	// OrderAlpha sorts each tier alphabetically (the default).
+	OrderAlpha = "alpha"
+	// OrderStable keeps the previous artifact's order; see above.
+	OrderStable = "stable"
+)
+
+// stableOrderEnabled reports whether cx.order asks for stable ordering.
+func (m *Manager) stableOrderEnabled() bool {
+	switch order := m.cxConfig().Order; order {
+	case OrderStable:
+		return true
+	case "", OrderAlpha:
+	default:
+		Warnf("unknown cx.order %q; using %q", order, OrderAlpha)
+	}
+	return false
+}
+
+// orderLikeArtifact orders files as they appear in the artifact at path when
+// stable ordering is on, and returns them unchanged otherwise.
+func (m *Manager) orderLikeArtifact(files []string, path string) []string {
+	if len(files) == 0 || !m.stableOrderEnabled() {
+		return files
+	}
+	if err := m.artifactsReadable(); err != nil {
+		Warnf("cx.order %q ignored: %v", OrderStable, err)
+		return files
+	}
+	previous, err := ArtifactFiles(path)
+	if err != nil {
+		return files
+	}
+	prev := make([]string, len(previous))
+	for i, f := range previous {
+		prev[i] = f.Path
+	}
+	return stableOrder(files, prev)
+}
+
+// stableOrder returns files with those in previous first, in previous's
+// order, followed by the rest in their order in files.
+func stableOrder(files, previous []string) []string {
+	current := make(map[string]bool, len(files))
+	for _, f := range files {
+		current[f] = true
+	}
+	ordered := make([]string, 0, len(files))
+	placed := make(map[string]bool, len(files))
+	for _, f := range previous {
+		if current[f] && !placed[f] {
+			placed[f] = true
+			ordered = append(ordered, f)
+		}
+	}
+	for _, f := range files {
+		if !placed[f] {
+			placed[f] = true
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered
+}
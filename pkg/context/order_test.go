@@ -0,0 +1,41 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStableOrder(t *testing.T) {
+	previous := []string{"b.go", "d.go", "a.go"}
+	got := stableOrder([]string{"a.go", "c.go", "d.go", "e.go"}, previous)
+	want := []string{"d.go", "a.go", "c.go", "e.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stableOrder = %v, want %v", got, want)
+	}
+}
+
+// TestOrderLikeArtifact verifies that stable ordering follows the previous
+// artifact and is off by default.
+func TestOrderLikeArtifact(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "context")
+	fsWriteString(t, artifact, "<context>\n  <hot-context files=\"2\">\n"+
+		"    <file path=\"z.go\">\n    </file>\n"+
+		"    <file path=\"a.go\">\n    </file>\n"+
+		"  </hot-context>\n</context>\n")
+	m := NewManager(dir)
+	files := []string{"a.go", "m.go", "z.go"}
+
+	if got := m.orderLikeArtifact(files, artifact); !reflect.DeepEqual(got, files) {
+		t.Errorf("default order = %v, want %v", got, files)
+	}
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  order: stable\n")
+	if got, want := m.orderLikeArtifact(files, artifact), []string{"z.go", "a.go", "m.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stable order = %v, want %v", got, want)
+	}
+	if got := m.orderLikeArtifact(files, filepath.Join(dir, "missing")); !reflect.DeepEqual(got, files) {
+		t.Errorf("without a previous artifact = %v, want %v", got, files)
+	}
+}
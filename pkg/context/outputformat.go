@@ -0,0 +1,221 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/grovetools/cx/pkg/lang"
+)
+
+// Output formats. Generated context is XML by default: a <context> document
+// with one <file path="..."> block per file, which the other cx commands
+// (diff, verify-output, stable ordering, splicing) read back. cx.output_format,
+// or `cx generate --format`, renders both tiers through a Go template
+// instead:
+//
+//	markdown  a heading and a fenced code block per file
+//	jsonl     one JSON record per tree and per file
+//	<name>    the user template .cx/templates/<name>.tmpl
+//
+// A template is executed once per tier with an OutputDocument. Besides the
+// text/template builtins it can call json (a JSON string literal), fence (a
+// backtick fence longer than any inside its argument) and newline (its
+// argument, with a trailing newline when non-empty and missing one).
+// Templated artifacts are written whole on every generation, and the
+// @symbol-index block is only written in XML. verify-output, stable ordering
+// and the dry-run and cache checks read markdown and jsonl artifacts back
+// too; a user template has no fixed shape, so they refuse its artifacts.
+
+const (
+	// FormatXML is the default XML document.
+	FormatXML = "xml"
+	// FormatMarkdown renders each file as a fenced Markdown code block.
+	FormatMarkdown = "markdown"
+	// FormatJSONL renders one JSON record per line.
+	FormatJSONL = "jsonl"
+)
+
+// TemplatesDir holds user output templates, relative to the working
+// directory.
+const TemplatesDir = RulesDir + "/templates"
+
+// TemplateExt is the extension of user output templates.
+const TemplateExt = ".tmpl"
+
+// OutputDocument is what an output template renders: one tier of the
+// generated context.
+type OutputDocument struct {
+	Tier  string // "hot" or "cold"
+	Trees []OutputTree
+	Files []OutputFile
+}
+
+// OutputTree is a directory tree requested with @tree.
+type OutputTree struct {
+	Path string
+	Tree string
+}
+
+// OutputFile is a file of the tier, after tier transforms and content
+// screening. Error is set, and Content empty, when it could not be read.
+type OutputFile struct {
+	Path     string
+	Language string // code fence tag, "" when unknown
+	Content  string
+	Error    string
+	Rule     string // "<rules file>:<line>" that included it, with provenance on
+}
+
+var builtinOutputTemplates = map[string]string{
+	FormatMarkdown: `{{range .Trees}}## Tree: {{.Path}}
+
+{{fence .Tree}}
+{{newline .Tree}}{{fence .Tree}}
+
+{{end}}{{range .Files}}{{with .Rule}}<!-- included by {{.}} ({{$.Tier}}) -->
+{{end}}## {{.Path}}
+
+{{if .Error}}Error reading file: {{.Error}}
+{{else}}{{fence .Content}}{{.Language}}
+{{newline .Content}}{{fence .Content}}
+{{end}}
+{{end}}`,
+	FormatJSONL: `{{range .Trees}}{"type":"tree","tier":{{json $.Tier}},"path":{{json .Path}},"tree":{{json .Tree}}}
+{{end}}{{range .Files}}{"type":"file","tier":{{json $.Tier}},"path":{{json .Path}},"language":{{json .Language}},"content":{{json .Content}}{{with .Rule}},"rule":{{json .}}{{end}}{{if .Error}},"error":{{json .Error}}{{end}}}
+{{end}}`,
+}
+
+var outputTemplateFuncs = template.FuncMap{
+	"json": func(s string) (string, error) {
+		data, err := json.Marshal(s)
+		return string(data), err
+	},
+	"fence": func(s string) string {
+		fence := "```"
+		for strings.Contains(s, fence) {
+			fence += "`"
+		}
+		return fence
+	},
+	"newline": func(s string) string {
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		return s
+	},
+}
+
+// SetOutputFormat overrides cx.output_format for this manager's
+// generations. Empty restores the configured format.
+func (m *Manager) SetOutputFormat(format string) {
+	m.outputFormat = format
+}
+
+// OutputFormat returns the format generations are written in.
+func (m *Manager) OutputFormat() string {
+	if m.outputFormat != "" {
+		return m.outputFormat
+	}
+	if format := m.cxConfig().OutputFormat; format != "" {
+		return format
+	}
+	return FormatXML
+}
+
+// artifactsReadable returns an error when the artifacts of the output
+// format cannot be split back into files, which is the case for user
+// templates.
+func (m *Manager) artifactsReadable() error {
+	switch format := m.OutputFormat(); format {
+	case FormatXML, FormatMarkdown, FormatJSONL:
+		return nil
+	default:
+		return fmt.Errorf("reading back a generated artifact requires xml output (or %s or %s); output format %q is a user template", FormatMarkdown, FormatJSONL, format)
+	}
+}
+
+// OutputFormats lists the built-in formats followed by the user templates
+// in TemplatesDir.
+func (m *Manager) OutputFormats() []string {
+	formats := []string{FormatXML, FormatMarkdown, FormatJSONL}
+	entries, _ := os.ReadDir(filepath.Join(m.workDir, TemplatesDir))
+	var user []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), TemplateExt); ok && !e.IsDir() {
+			user = append(user, name)
+		}
+	}
+	sort.Strings(user)
+	return append(formats, user...)
+}
+
+// outputTemplate returns the template of the output format, or nil for XML.
+func (m *Manager) outputTemplate() (*template.Template, error) {
+	format := m.OutputFormat()
+	if format == FormatXML {
+		return nil, nil
+	}
+	text, ok := builtinOutputTemplates[format]
+	if !ok {
+		if strings.ContainsAny(format, `/\`) {
+			return nil, fmt.Errorf("invalid output format %q", format)
+		}
+		path := filepath.Join(m.workDir, TemplatesDir, format+TemplateExt)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unknown output format %q: expected %s or a template in %s", format, strings.Join([]string{FormatXML, FormatMarkdown, FormatJSONL}, ", "), TemplatesDir)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading output template %s: %w", path, err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New(format).Funcs(outputTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing output template %q: %w", format, err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplated renders one tier of files and trees to w with tmpl.
+func (m *Manager) writeTemplated(w io.Writer, tmpl *template.Template, tier string, files, treePaths []string) error {
+	doc := OutputDocument{Tier: tier}
+	for _, tp := range treePaths {
+		tree, err := m.GenerateTreeString(tp)
+		if err != nil {
+			Warnf("could not generate tree %s: %v", tp, err)
+			continue
+		}
+		doc.Trees = append(doc.Trees, OutputTree{Path: tp, Tree: tree})
+	}
+	detector := m.LanguageDetector()
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		content, err := readRegularFile(path)
+		if err != nil {
+			doc.Files = append(doc.Files, OutputFile{Path: file, Error: err.Error(), Rule: m.ruleOf(file)})
+			continue
+		}
+		content = m.transformContent(file, content)
+		content = m.screenContent(file, content)
+		head := content
+		if len(head) > lang.HeadSize {
+			head = head[:lang.HeadSize]
+		}
+		doc.Files = append(doc.Files, OutputFile{Path: file, Language: detector.Fence(path, head), Content: string(content), Rule: m.ruleOf(file)})
+	}
+	if err := tmpl.Execute(w, doc); err != nil {
+		return fmt.Errorf("rendering output template %q: %w", tmpl.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func streamFormat(t *testing.T, dir, format string) (string, error) {
+	t.Helper()
+	rulesPath := filepath.Join(dir, "format.rules")
+	grove := filepath.Join(dir, ".grove")
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(grove, "context"), filepath.Join(grove, "cached-context"),
+		filepath.Join(grove, "context-files"), filepath.Join(grove, "cached-context-files"))
+	m.SetOutputFormat(format)
+	var buf bytes.Buffer
+	err := m.StreamContext(&buf, rulesPath, true)
+	return buf.String(), err
+}
+
+// TestOutputFormats verifies the built-in and user output templates.
+func TestOutputFormats(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "README.md"), "Run:\n```sh\ngo run .\n```")
+	fsWriteString(t, filepath.Join(dir, "format.rules"), "main.go\nREADME.md\n")
+
+	md, err := streamFormat(t, dir, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md, "## main.go\n\n```go\npackage main\n```\n") {
+		t.Errorf("markdown output missing main.go block:\n%s", md)
+	}
+	if !strings.Contains(md, "## README.md\n\n````markdown\nRun:\n```sh\ngo run .\n```\n````\n") {
+		t.Errorf("markdown output should lengthen the fence around README.md:\n%s", md)
+	}
+
+	jsonl, err := streamFormat(t, dir, FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(jsonl), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("jsonl output has %d records, want 2:\n%s", len(lines), jsonl)
+	}
+	var rec struct {
+		Type, Tier, Path, Content string
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("invalid record %s: %v", lines[1], err)
+	}
+	if rec.Type != "file" || rec.Tier != "hot" || rec.Path != "main.go" || rec.Content != "package main\n" {
+		t.Errorf("unexpected record %+v", rec)
+	}
+
+	fsWriteString(t, filepath.Join(dir, TemplatesDir, "paths"+TemplateExt), "{{range .Files}}{{.Path}};{{end}}")
+	if out, err := streamFormat(t, dir, "paths"); err != nil || out != "README.md;main.go;" {
+		t.Errorf("user template = %q, %v", out, err)
+	}
+	if _, err := streamFormat(t, dir, "missing"); err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("unknown format error = %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OverlapRule identifies one line of the root rules file.
+type OverlapRule struct {
+	LineNum int    `json:"lineNum"`
+	Rule    string `json:"rule"`
+}
+
+// TierConflict is a file that hot and cold lines both reach. Cold wins, so
+// the hot lines contribute nothing for it.
+type TierConflict struct {
+	File      string        `json:"file"`
+	HotRules  []OverlapRule `json:"hotRules"`
+	ColdRules []OverlapRule `json:"coldRules"`
+}
+
+// ImportOverlap describes how much of what an import line (@a:proj::set,
+// @include:, @default:) contributes is also reached by other lines.
+// Droppable is set when every file it matches is covered elsewhere.
+type ImportOverlap struct {
+	OverlapRule
+	Files       int           `json:"files"`
+	SharedFiles int           `json:"sharedFiles"`
+	SharedWith  []OverlapRule `json:"sharedWith,omitempty"`
+	Droppable   bool          `json:"droppable"`
+}
+
+// DuplicatePattern is an expanded pattern that more than one rule produces,
+// typically through overlapping imports or recursive @default chains.
+type DuplicatePattern struct {
+	Pattern     string `json:"pattern"`
+	Occurrences int    `json:"occurrences"`
+	Lines       []int  `json:"lines"`
+}
+
+// OverlapReport is the result of AnalyzeOverlap.
+type OverlapReport struct {
+	RulesFile         string             `json:"rulesFile"`
+	TierConflicts     []TierConflict     `json:"tierConflicts"`
+	Imports           []ImportOverlap    `json:"imports"`
+	DuplicatePatterns []DuplicatePattern `json:"duplicatePatterns"`
+}
+
+// AnalyzeOverlap expands the rules file at rulesPath and reports where its
+// lines overlap: files reached from both the hot and cold sections, import
+// lines whose files other lines already provide, and patterns that the
+// expansion produces more than once. Attribution is by root-file line, so
+// every rule pulled in through an import counts against the import's line.
+func (m *Manager) AnalyzeOverlap(rulesPath string) (*OverlapReport, error) {
+	if !filepath.IsAbs(rulesPath) {
+		rulesPath = filepath.Join(m.workDir, rulesPath)
+	}
+	content, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	ruleAt := func(lineNum int) OverlapRule {
+		r := OverlapRule{LineNum: lineNum}
+		if lineNum > 0 && lineNum <= len(lines) {
+			r.Rule = strings.TrimSpace(lines[lineNum-1])
+		}
+		return r
+	}
+
+	hotRules, coldRules, _, _, err := m.expandAllRules(rulesPath, newExpansionRun(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve patterns from rules file: %w", err)
+	}
+	hotLines := m.contributingLines(hotRules)
+	coldLines := m.contributingLines(coldRules)
+
+	report := &OverlapReport{
+		RulesFile:         rulesPath,
+		TierConflicts:     []TierConflict{},
+		Imports:           []ImportOverlap{},
+		DuplicatePatterns: []DuplicatePattern{},
+	}
+
+	for file, hl := range hotLines {
+		cl, ok := coldLines[file]
+		if !ok {
+			continue
+		}
+		conflict := TierConflict{File: file}
+		for _, l := range hl {
+			conflict.HotRules = append(conflict.HotRules, ruleAt(l))
+		}
+		for _, l := range cl {
+			conflict.ColdRules = append(conflict.ColdRules, ruleAt(l))
+		}
+		report.TierConflicts = append(report.TierConflicts, conflict)
+	}
+	sort.Slice(report.TierConflicts, func(i, j int) bool {
+		return report.TierConflicts[i].File < report.TierConflicts[j].File
+	})
+
+	// Files per line and lines per file, across both sections.
+	byLine := make(map[int][]string)
+	byFile := make(map[string]map[int]bool)
+	for _, section := range []map[string][]int{hotLines, coldLines} {
+		for file, ls := range section {
+			for _, l := range ls {
+				if byFile[file] == nil {
+					byFile[file] = make(map[int]bool)
+				}
+				if !byFile[file][l] {
+					byFile[file][l] = true
+					byLine[l] = append(byLine[l], file)
+				}
+			}
+		}
+	}
+	for lineNum, files := range byLine {
+		rule := ruleAt(lineNum)
+		if !isImportLine(rule.Rule) {
+			continue
+		}
+		imp := ImportOverlap{OverlapRule: rule, Files: len(files)}
+		others := make(map[int]bool)
+		for _, f := range files {
+			shared := false
+			for l := range byFile[f] {
+				if l != lineNum {
+					others[l] = true
+					shared = true
+				}
+			}
+			if shared {
+				imp.SharedFiles++
+			}
+		}
+		if imp.SharedFiles == 0 {
+			continue
+		}
+		for _, l := range sortedLineNums(others) {
+			imp.SharedWith = append(imp.SharedWith, ruleAt(l))
+		}
+		imp.Droppable = imp.SharedFiles == imp.Files
+		report.Imports = append(report.Imports, imp)
+	}
+	sort.Slice(report.Imports, func(i, j int) bool {
+		return report.Imports[i].LineNum < report.Imports[j].LineNum
+	})
+
+	occurrences := make(map[string]int)
+	patternLines := make(map[string]map[int]bool)
+	for _, r := range append(append([]RuleInfo{}, hotRules...), coldRules...) {
+		if r.IsExclude {
+			continue
+		}
+		key := r.Pattern
+		for _, d := range r.Directives {
+			key += fmt.Sprintf(" @%s: %s", d.Name, d.Query)
+		}
+		occurrences[key]++
+		if patternLines[key] == nil {
+			patternLines[key] = make(map[int]bool)
+		}
+		patternLines[key][r.EffectiveLineNum] = true
+	}
+	for key, n := range occurrences {
+		if n < 2 {
+			continue
+		}
+		report.DuplicatePatterns = append(report.DuplicatePatterns, DuplicatePattern{
+			Pattern:     key,
+			Occurrences: n,
+			Lines:       sortedLineNums(patternLines[key]),
+		})
+	}
+	sort.Slice(report.DuplicatePatterns, func(i, j int) bool {
+		return report.DuplicatePatterns[i].Pattern < report.DuplicatePatterns[j].Pattern
+	})
+
+	return report, nil
+}
+
+// contributingLines resolves rules and maps each resulting file to every
+// root line that matched it: the winning line plus the lines it superseded.
+// Like resolveFilesViaAST, exclusions are evaluated against the full set of
+// files the inclusions discovered.
+func (m *Manager) contributingLines(rules []RuleInfo) map[string][]int {
+	out := make(map[string][]int)
+	if len(rules) == 0 {
+		return out
+	}
+	var inclRules []RuleInfo
+	for _, r := range rules {
+		if !r.IsExclude {
+			inclRules = append(inclRules, r)
+		}
+	}
+	inclAttr, _, _, _ := ResolveAST(ruleInfosToNodes(inclRules), newProdResolutionContext(m))
+	var discovered []string
+	for _, paths := range inclAttr {
+		discovered = append(discovered, paths...)
+	}
+	attr, _, filt, _ := ResolveAST(ruleInfosToNodes(rules), newProdResolutionContext(m).withFileSet(discovered))
+
+	rel := func(p string) string {
+		if r, err := filepath.Rel(m.rulesBaseDir, p); err == nil && !strings.HasPrefix(r, "..") {
+			return r
+		}
+		return p
+	}
+	final := make(map[string]bool)
+	for line, paths := range attr {
+		for _, p := range paths {
+			out[rel(p)] = append(out[rel(p)], line)
+			final[p] = true
+		}
+	}
+	for line, infos := range filt {
+		for _, info := range infos {
+			if final[info.File] {
+				out[rel(info.File)] = append(out[rel(info.File)], line)
+			}
+		}
+	}
+	for file, ls := range out {
+		sort.Ints(ls)
+		out[file] = ls
+	}
+	return out
+}
+
+// isImportLine reports whether a root rules line pulls in another rule set.
+func isImportLine(line string) bool {
+	switch {
+	case strings.HasPrefix(line, "@include:"), strings.HasPrefix(line, "@default:"):
+		return true
+	case strings.HasPrefix(line, "@a:"), strings.HasPrefix(line, "@alias:"):
+		return strings.Contains(line, "::")
+	}
+	return false
+}
+
+func sortedLineNums(set map[int]bool) []int {
+	out := make([]int, 0, len(set))
+	for l := range set {
+		out = append(out, l)
+	}
+	sort.Ints(out)
+	return out
+}
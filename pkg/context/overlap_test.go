@@ -0,0 +1,57 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzeOverlap(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("pkg/a.go", "package pkg\n")
+	write("pkg/b.go", "package pkg\n")
+	write("docs/x.md", "# x\n")
+	write("extra.rules", "pkg/a.go\n")
+	write("rules", "pkg/*.go\n@include: extra.rules\ndocs/*.md\n---\ndocs/*.md\n")
+
+	m := NewManager(dir, WithNoState())
+	report, err := m.AnalyzeOverlap(filepath.Join(dir, "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.TierConflicts) != 1 || report.TierConflicts[0].File != "docs/x.md" {
+		t.Fatalf("tier conflicts = %+v", report.TierConflicts)
+	}
+	if c := report.TierConflicts[0]; len(c.HotRules) != 1 || c.HotRules[0].LineNum != 3 ||
+		len(c.ColdRules) != 1 || c.ColdRules[0].LineNum != 5 {
+		t.Errorf("conflict attribution = %+v", c)
+	}
+
+	if len(report.Imports) != 1 {
+		t.Fatalf("imports = %+v", report.Imports)
+	}
+	imp := report.Imports[0]
+	if imp.LineNum != 2 || imp.Rule != "@include: extra.rules" || !imp.Droppable {
+		t.Errorf("import overlap = %+v", imp)
+	}
+	if len(imp.SharedWith) != 1 || imp.SharedWith[0].LineNum != 1 {
+		t.Errorf("import should be covered by line 1, got %+v", imp.SharedWith)
+	}
+
+	if len(report.DuplicatePatterns) != 1 || report.DuplicatePatterns[0].Pattern != "docs/*.md" {
+		t.Fatalf("duplicate patterns = %+v", report.DuplicatePatterns)
+	}
+	if d := report.DuplicatePatterns[0]; d.Occurrences != 2 || len(d.Lines) != 2 {
+		t.Errorf("duplicate = %+v", d)
+	}
+}
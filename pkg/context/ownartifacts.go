@@ -0,0 +1,146 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cx never includes its own artifacts. Directory walks skip .grove, so a
+// rule like `.grove/context` matches nothing, but a rule can still reach
+// artifacts that live elsewhere: the notebook or plan directory the
+// generated context is written to, a job-scoped output path, or a section's
+// output. Including one would nest the previous generation in the next and
+// double the output every run, so resolution always drops them and warns;
+// `cx validate` fails on rule sets that match any.
+
+// ArtifactMatch lists the cx artifacts a rule set's patterns match.
+type ArtifactMatch struct {
+	Ruleset string   `json:"ruleset"`
+	Files   []string `json:"files"`
+}
+
+// ownArtifacts describes the artifacts of the current configuration.
+type ownArtifacts struct {
+	dirs        map[string]bool // directories holding the generated artifacts
+	tmpl        string          // cx.artifact_name
+	hotPrefix   string          // base name of the hot context plus ".", for model variants
+	paths       map[string]bool // section outputs
+	snapshotDir string          // `cx snapshot` output, with a trailing separator
+}
+
+func (m *Manager) ownArtifacts() *ownArtifacts {
+	a := &ownArtifacts{
+		dirs:        map[string]bool{filepath.Join(m.workDir, GroveDir): true},
+		tmpl:        m.artifactTemplate(),
+		hotPrefix:   filepath.Base(m.ResolveContextPath()) + ".",
+		paths:       make(map[string]bool),
+		snapshotDir: filepath.Join(m.workDir, SnapshotsDir) + string(filepath.Separator),
+	}
+	for _, p := range []string{
+		m.ResolveContextPath(),
+		m.ResolveContextFilesListPath(),
+		m.ResolveCachedContextPath(),
+		m.ResolveCachedContextFilesListPath(),
+	} {
+		a.dirs[filepath.Dir(p)] = true
+	}
+	for _, s := range m.cxConfig().Sections {
+		if s.Output == "" {
+			continue
+		}
+		path := s.Output
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		a.paths[filepath.Clean(path)] = true
+	}
+	return a
+}
+
+// contains reports whether the absolute path is one of the artifacts.
+func (a *ownArtifacts) contains(path string) bool {
+	if a.paths[path] || strings.HasPrefix(path, a.snapshotDir) {
+		return true
+	}
+	if !a.dirs[filepath.Dir(path)] {
+		return false
+	}
+	base := strings.TrimSuffix(filepath.Base(path), ChecksumExt)
+	if strings.HasPrefix(base, a.hotPrefix) {
+		return true
+	}
+	_, _, ok := matchArtifactName(a.tmpl, base)
+	return ok
+}
+
+// dropOwnArtifacts removes cx's own artifacts from files, resolved against
+// the rules base directory, and records them for ArtifactMatches.
+func (m *Manager) dropOwnArtifacts(files []string) []string {
+	if len(files) == 0 {
+		return files
+	}
+	artifacts := m.ownArtifacts()
+	kept := files[:0:0]
+	var dropped []string
+	for _, f := range files {
+		abs := f
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(m.rulesBaseDir, abs)
+		}
+		if artifacts.contains(filepath.Clean(abs)) {
+			dropped = append(dropped, f)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if len(dropped) == 0 {
+		return files
+	}
+	Warnf("excluded %d cx artifact(s) matched by the rules: %s",
+		len(dropped), strings.Join(dropped, ", "))
+	m.skippedMutex.Lock()
+	m.artifactHits = append(m.artifactHits, dropped...)
+	m.skippedMutex.Unlock()
+	return kept
+}
+
+// takeArtifactHits returns and clears the artifacts dropped so far.
+func (m *Manager) takeArtifactHits() []string {
+	m.skippedMutex.Lock()
+	defer m.skippedMutex.Unlock()
+	hits := deduplicateStrings(m.artifactHits)
+	m.artifactHits = nil
+	sort.Strings(hits)
+	return hits
+}
+
+// ArtifactMatches resolves the active rules and every named rule set and
+// returns those whose patterns match cx's own artifacts. Rule sets that fail
+// to resolve are skipped; `cx validate` reports the active rules' errors
+// itself.
+func (m *Manager) ArtifactMatches() []ArtifactMatch {
+	var matches []ArtifactMatch
+	m.takeArtifactHits()
+	for _, name := range append([]string{ActiveExpectation}, m.ListRulesetNames()...) {
+		rulesPath := m.ResolveRulesPath()
+		if name != ActiveExpectation {
+			path, err := m.FindRulesetFile(m.workDir, name)
+			if err != nil {
+				continue
+			}
+			rulesPath = path
+		}
+		if _, err := os.Stat(rulesPath); err != nil {
+			continue
+		}
+		_, _, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+		hits := m.takeArtifactHits()
+		if err != nil || len(hits) == 0 {
+			continue
+		}
+		matches = append(matches, ArtifactMatch{Ruleset: name, Files: hits})
+	}
+	return matches
+}
@@ -0,0 +1,48 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestOwnArtifactsExcluded verifies that rules reaching cx's artifacts
+// outside .grove — a section output and a generated directory like a
+// notebook's — do not include them, and that ArtifactMatches reports the
+// rule set that tried.
+func TestOwnArtifactsExcluded(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  sections:\n    api:\n      output: out/api.xml\n")
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "out", "api.xml"), "<context></context>\n")
+	gen := filepath.Join(dir, "notebook", "generated")
+	fsWriteString(t, filepath.Join(gen, "context"), "<context></context>\n")
+	fsWriteString(t, filepath.Join(gen, "context.claude"), "<context></context>\n")
+	fsWriteString(t, filepath.Join(gen, "notes.md"), "# Project notes\n")
+	rulesPath := filepath.Join(dir, RulesDir, "greedy"+RulesExt)
+	// out/api.xml is stat'ed as a file and notebook/generated as a directory;
+	// .grove/context never matches, since walks skip .grove.
+	fsWriteString(t, rulesPath, "main.go\nout/api.xml\nnotebook/generated\n.grove/context\n")
+
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(gen, "context"), filepath.Join(gen, "cached-context"),
+		filepath.Join(gen, "context-files"), filepath.Join(gen, "cached-context-files"))
+	hot, _, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(hot)
+	if want := []string{"main.go", "notebook/generated/notes.md"}; !reflect.DeepEqual(hot, want) {
+		t.Errorf("resolved %v, want %v", hot, want)
+	}
+
+	matches := m.ArtifactMatches()
+	if len(matches) != 1 || matches[0].Ruleset != "greedy" {
+		t.Fatalf("ArtifactMatches() = %+v", matches)
+	}
+	if want := []string{"notebook/generated/context", "notebook/generated/context.claude", "out/api.xml"}; !reflect.DeepEqual(matches[0].Files, want) {
+		t.Errorf("matched %v, want %v", matches[0].Files, want)
+	}
+}
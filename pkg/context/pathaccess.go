@@ -0,0 +1,176 @@
+package context
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/grovetools/core/config"
+	"github.com/grovetools/core/util/pathutil"
+)
+
+// WorkspaceMatch is a discovered workspace that contains the checked path.
+type WorkspaceMatch struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	CanonicalPath string `json:"canonicalPath"`
+	Excluded      bool   `json:"excluded"` // listed in context.excluded_workspaces
+	Included      bool   `json:"included"` // listed in context.included_workspaces
+}
+
+// RootCheck is one allowed root compared against the checked path.
+type RootCheck struct {
+	Root     string `json:"root"`
+	Origin   string `json:"origin,omitempty"` // why the root is allowed
+	Contains bool   `json:"contains"`
+}
+
+// PathAccessDecision is the full trail behind IsPathAllowed's verdict for
+// one path; see ExplainPathAccess.
+type PathAccessDecision struct {
+	Path               string           `json:"path"`
+	AbsPath            string           `json:"absPath"`
+	CanonicalPath      string           `json:"canonicalPath"`
+	Allowed            bool             `json:"allowed"`
+	Reason             string           `json:"reason,omitempty"` // IsPathAllowed's reason when blocked
+	Mode               string           `json:"mode"`             // "allowlist" or "denylist"
+	IncludedWorkspaces []string         `json:"includedWorkspaces"`
+	ExcludedWorkspaces []string         `json:"excludedWorkspaces"`
+	Containing         []WorkspaceMatch `json:"containing"` // workspaces containing the path
+	ExcludedBy         *WorkspaceMatch  `json:"excludedBy,omitempty"`
+	AllowedBy          *RootCheck       `json:"allowedBy,omitempty"`
+	Roots              []RootCheck      `json:"roots"`
+	Suggestions        []string         `json:"suggestions,omitempty"` // config changes that would allow the path
+}
+
+// ExplainPathAccess reports how IsPathAllowed decides on path: the
+// canonical forms compared, the workspace filters in effect, the
+// workspaces containing the path (and which exclusion matched), every
+// allowed root considered, and, when the path is blocked, the config
+// changes that would allow it.
+func (m *Manager) ExplainPathAccess(path string) (*PathAccessDecision, error) {
+	d := &PathAccessDecision{
+		Path:               path,
+		IncludedWorkspaces: []string{},
+		ExcludedWorkspaces: []string{},
+		Containing:         []WorkspaceMatch{},
+		Roots:              []RootCheck{},
+	}
+	d.Allowed, d.Reason = m.IsPathAllowed(path)
+	if m.allowedRootsErr != nil {
+		return nil, fmt.Errorf("workspace discovery failed: %w", m.allowedRootsErr)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workDir, path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve absolute path for '%s': %w", path, err)
+	}
+	d.AbsPath = absPath
+	d.CanonicalPath = absPath
+	if canonical, err := pathutil.NormalizeForLookup(absPath); err == nil {
+		d.CanonicalPath = canonical
+	}
+
+	var ctxCfg ContextConfig
+	if !m.noState {
+		if mergedCfg, _ := config.LoadFrom(m.workDir); mergedCfg != nil && mergedCfg.Context != nil {
+			ctxCfg.IncludedWorkspaces = mergedCfg.Context.IncludedWorkspaces
+			ctxCfg.ExcludedWorkspaces = mergedCfg.Context.ExcludedWorkspaces
+		}
+	}
+	d.IncludedWorkspaces = append(d.IncludedWorkspaces, ctxCfg.IncludedWorkspaces...)
+	d.ExcludedWorkspaces = append(d.ExcludedWorkspaces, ctxCfg.ExcludedWorkspaces...)
+	d.Mode = "denylist"
+	if len(ctxCfg.IncludedWorkspaces) > 0 {
+		d.Mode = "allowlist"
+	}
+
+	if resolver := m.getAliasResolver(); resolver != nil && resolver.Provider != nil {
+		for _, node := range resolver.Provider.All() {
+			nodePath, err := pathutil.NormalizeForLookup(node.Path)
+			if err != nil || !pathWithin(d.CanonicalPath, nodePath) {
+				continue
+			}
+			match := WorkspaceMatch{
+				Name:          node.Name,
+				Path:          node.Path,
+				CanonicalPath: nodePath,
+				Excluded:      slices.Contains(ctxCfg.ExcludedWorkspaces, node.Name),
+				Included:      slices.Contains(ctxCfg.IncludedWorkspaces, node.Name),
+			}
+			d.Containing = append(d.Containing, match)
+			if match.Excluded && d.ExcludedBy == nil {
+				d.ExcludedBy = &match
+			}
+		}
+	}
+
+	for _, root := range m.allowedRoots {
+		check := RootCheck{Root: root, Origin: m.rootOrigins[root], Contains: pathWithin(d.CanonicalPath, root)}
+		d.Roots = append(d.Roots, check)
+		if check.Contains && d.AllowedBy == nil {
+			c := check
+			d.AllowedBy = &c
+		}
+	}
+	m.allowPathMu.Lock()
+	for root, rulesFile := range m.grantedRoots {
+		check := RootCheck{Root: root, Origin: "trusted @allow-path in " + rulesFile, Contains: pathWithin(d.CanonicalPath, root)}
+		d.Roots = append(d.Roots, check)
+		if check.Contains && d.AllowedBy == nil {
+			c := check
+			d.AllowedBy = &c
+		}
+	}
+	m.allowPathMu.Unlock()
+	if !d.Allowed {
+		d.Suggestions = m.pathAccessSuggestions(d)
+	}
+	return d, nil
+}
+
+// pathAccessSuggestions lists config changes that would allow a blocked
+// path, naming the config file that sets an exclusion where it is known.
+func (m *Manager) pathAccessSuggestions(d *PathAccessDecision) []string {
+	if d.ExcludedBy != nil {
+		where := "your grove config"
+		if file := m.excludedWorkspaceSource(d.ExcludedBy.Name); file != "" {
+			where = file
+		}
+		return []string{fmt.Sprintf("remove '%s' from context.excluded_workspaces in %s", d.ExcludedBy.Name, where)}
+	}
+	var out []string
+	if d.Mode == "allowlist" {
+		for _, w := range d.Containing {
+			if !w.Included {
+				out = append(out, fmt.Sprintf("add '%s' to context.included_workspaces", w.Name))
+			}
+		}
+	}
+	if len(d.Containing) == 0 {
+		dir := filepath.Dir(d.AbsPath)
+		out = append(out,
+			fmt.Sprintf("add '%s' (or a parent directory) to context.allowed_paths", dir),
+			fmt.Sprintf("or, for one rules file only, add '@allow-path: %s' to it and run 'cx rules trust'", dir))
+	}
+	return out
+}
+
+// excludedWorkspaceSource returns the highest-precedence config file whose
+// context.excluded_workspaces lists name, or "" when it cannot be told.
+func (m *Manager) excludedWorkspaceSource(name string) string {
+	lc, err := config.LoadLayered(m.workDir)
+	if err != nil || lc == nil {
+		return ""
+	}
+	source := ""
+	for _, l := range layersInMergeOrder(lc) {
+		if l.cfg.Context != nil && slices.Contains(l.cfg.Context.ExcludedWorkspaces, name) {
+			source = l.path
+		}
+	}
+	return source
+}
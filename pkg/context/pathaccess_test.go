@@ -0,0 +1,40 @@
+package context
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExplainPathAccess(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	m := NewManager(dir, WithNoState(), WithAllowedRoots(dir))
+
+	d, err := m.ExplainPathAccess("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Allowed || d.AllowedBy == nil || d.AllowedBy.Origin != "WithAllowedRoots" {
+		t.Errorf("expected main.go allowed by the supplied root, got %+v", d)
+	}
+	if d.AbsPath != filepath.Join(dir, "main.go") || d.Mode != "denylist" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if len(d.Suggestions) != 0 {
+		t.Errorf("allowed paths need no suggestions, got %v", d.Suggestions)
+	}
+
+	d, err = m.ExplainPathAccess(filepath.Join(other, "x.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Allowed || d.Reason == "" || d.AllowedBy != nil {
+		t.Errorf("expected a path outside every root to be blocked, got %+v", d)
+	}
+	if len(d.Roots) != 1 || d.Roots[0].Contains {
+		t.Errorf("expected the one root to be compared and not match, got %+v", d.Roots)
+	}
+	if len(d.Suggestions) != 2 || d.Suggestions[0] != "add '"+other+"' (or a parent directory) to context.allowed_paths" {
+		t.Errorf("unexpected suggestions %v", d.Suggestions)
+	}
+}
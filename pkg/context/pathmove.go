@@ -0,0 +1,250 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathRename is a file or directory moved from Old to New. Both are
+// slash-separated and relative to the rules base directory.
+type PathRename struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	Dir bool   `json:"dir,omitempty"`
+}
+
+// RuleRewrite is one rules line rewritten to follow a rename.
+type RuleRewrite struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// ProjectRulesFiles returns the active rules file (if any) and every named
+// rule set of the project.
+func (m *Manager) ProjectRulesFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	add(m.findActiveRulesFile())
+	for _, dir := range m.rulesetDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), RulesExt) {
+				add(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	return files
+}
+
+// MovePath moves src to dst (into dst when it is an existing directory),
+// with git mv when src is tracked, then rewrites the rules of every project
+// rules file that pointed at or under src. With dryRun nothing is moved or
+// written; the rewrites are only reported.
+func (m *Manager) MovePath(src, dst string, dryRun bool) (PathRename, []RuleRewrite, error) {
+	abs := func(p string) string {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(m.workDir, p)
+		}
+		return filepath.Clean(p)
+	}
+	src, dst = abs(src), abs(dst)
+	info, err := os.Lstat(src)
+	if err != nil {
+		return PathRename{}, nil, err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil {
+		if !dstInfo.IsDir() {
+			return PathRename{}, nil, fmt.Errorf("%s already exists", dst)
+		}
+		dst = filepath.Join(dst, filepath.Base(src))
+		if _, err := os.Lstat(dst); err == nil {
+			return PathRename{}, nil, fmt.Errorf("%s already exists", dst)
+		}
+	}
+	if dst == src || strings.HasPrefix(dst, src+string(filepath.Separator)) {
+		return PathRename{}, nil, fmt.Errorf("cannot move %s into itself", src)
+	}
+
+	rename := PathRename{Old: m.rulesRelPath(src), New: m.rulesRelPath(dst), Dir: info.IsDir()}
+	if !dryRun {
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return rename, nil, err
+		}
+		if exec.Command("git", "-C", filepath.Dir(src), "ls-files", "--error-unmatch", "--", src).Run() == nil {
+			if out, err := exec.Command("git", "-C", filepath.Dir(src), "mv", "--", src, dst).CombinedOutput(); err != nil {
+				return rename, nil, fmt.Errorf("git mv failed: %s", strings.TrimSpace(string(out)))
+			}
+		} else if err := os.Rename(src, dst); err != nil {
+			return rename, nil, err
+		}
+	}
+	rewrites, err := m.RewriteRulePaths([]PathRename{rename}, dryRun)
+	return rename, rewrites, err
+}
+
+// DetectRenames returns the renames git sees between since (HEAD when
+// empty) and the working tree. Only moves git can pair up are found, so
+// moved files must be staged (git mv or git add) or committed after since.
+// When every file of a directory moved together, the directory rename is
+// reported as well, so directory patterns can follow it.
+func (m *Manager) DetectRenames(since string) ([]PathRename, error) {
+	if since == "" {
+		since = "HEAD"
+	}
+	cmd := exec.Command("git", "diff", "-M", "--name-status", "--relative", since) //nolint:gosec // ref from internal CLI flags
+	cmd.Dir = m.rulesBaseDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", since, err)
+	}
+
+	var renames []PathRename
+	seen := make(map[string]bool)
+	add := func(r PathRename) {
+		if !seen[r.Old] {
+			seen[r.Old] = true
+			renames = append(renames, r)
+		}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 || !strings.HasPrefix(fields[0], "R") {
+			continue
+		}
+		add(PathRename{Old: fields[1], New: fields[2]})
+		if oldDir, newDir := renamedDirs(fields[1], fields[2]); oldDir != "" {
+			if _, err := os.Stat(filepath.Join(m.rulesBaseDir, filepath.FromSlash(oldDir))); os.IsNotExist(err) {
+				add(PathRename{Old: oldDir, New: newDir, Dir: true})
+			}
+		}
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].Old < renames[j].Old })
+	return renames, nil
+}
+
+// renamedDirs strips the path components old and new share at the end and
+// returns the directories that remain, or "" when only the file name
+// changed or either side reaches the top level.
+func renamedDirs(old, new string) (string, string) {
+	if path.Base(old) != path.Base(new) {
+		return "", ""
+	}
+	for path.Base(old) == path.Base(new) {
+		old, new = path.Dir(old), path.Dir(new)
+		if old == "." || new == "." {
+			return "", ""
+		}
+	}
+	return old, new
+}
+
+// RewriteRulePaths rewrites, in every project rules file, each rule whose
+// pattern names or lies under the old path of a rename. Directive,
+// comment and separator lines are left alone, as is anything after the
+// pattern on a line (inline directives, comments). The most specific
+// rename wins. With dryRun the files are not written.
+func (m *Manager) RewriteRulePaths(renames []PathRename, dryRun bool) ([]RuleRewrite, error) {
+	renames = append([]PathRename(nil), renames...)
+	sort.SliceStable(renames, func(i, j int) bool { return len(renames[i].Old) > len(renames[j].Old) })
+
+	var rewrites []RuleRewrite
+	for _, file := range m.ProjectRulesFiles() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return rewrites, err
+		}
+		lines := strings.Split(string(data), "\n")
+		changed := false
+		for i, line := range lines {
+			if rewritten, ok := m.rewriteRuleLine(line, renames); ok {
+				rewrites = append(rewrites, RuleRewrite{File: file, Line: i + 1, Old: line, New: rewritten})
+				lines[i] = rewritten
+				changed = true
+			}
+		}
+		if changed && !dryRun {
+			if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+				return rewrites, fmt.Errorf("failed to update %s: %w", file, err)
+			}
+		}
+	}
+	return rewrites, nil
+}
+
+// rewriteRuleLine returns line with its pattern moved by the first rename
+// that applies.
+func (m *Manager) rewriteRuleLine(line string, renames []PathRename) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(strings.TrimPrefix(trimmed, "!"), "@") {
+		return line, false
+	}
+	if _, ok := ParseSectionSeparator(trimmed); ok {
+		return line, false
+	}
+	pattern := strings.Fields(trimmed)[0]
+	for _, r := range renames {
+		if moved, ok := m.movePattern(pattern, r); ok {
+			start := strings.Index(line, pattern)
+			return line[:start] + moved + line[start+len(pattern):], true
+		}
+	}
+	return line, false
+}
+
+// movePattern rewrites a rule pattern that names r.Old or a path under it.
+// Exclusion, "./" and absolute forms are kept.
+func (m *Manager) movePattern(pattern string, r PathRename) (string, bool) {
+	prefix := ""
+	if strings.HasPrefix(pattern, "!") {
+		prefix, pattern = "!", pattern[1:]
+	}
+	oldPath, newPath := r.Old, r.New
+	switch {
+	case filepath.IsAbs(pattern):
+		oldPath = filepath.ToSlash(filepath.Join(m.rulesBaseDir, filepath.FromSlash(r.Old)))
+		newPath = filepath.ToSlash(filepath.Join(m.rulesBaseDir, filepath.FromSlash(r.New)))
+		pattern = filepath.ToSlash(pattern)
+	case strings.HasPrefix(pattern, "./"):
+		prefix += "./"
+		pattern = pattern[2:]
+	}
+	if pattern == oldPath {
+		return prefix + newPath, true
+	}
+	if rest, ok := strings.CutPrefix(pattern, oldPath+"/"); ok {
+		return prefix + newPath + "/" + rest, true
+	}
+	return "", false
+}
+
+// rulesRelPath returns p relative to the rules base directory,
+// slash-separated.
+func (m *Manager) rulesRelPath(p string) string {
+	if rel, err := filepath.Rel(m.rulesBaseDir, p); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(p)
+}
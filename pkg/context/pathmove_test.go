@@ -0,0 +1,72 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewriteRulePaths(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	rules := `# parser rules
+pkg/old/**/*.go
+!./pkg/old/gen.go
+pkg/oldish/x.go
+pkg/old/api.go @grep: Handle
+@find: pkg/old
+` + filepath.Join(dir, "pkg", "old", "doc.md") + `
+---
+pkg/old
+`
+	fsWriteString(t, filepath.Join(dir, ActiveRulesFile), rules)
+	fsWriteString(t, filepath.Join(dir, RulesDir, "review"+RulesExt), "pkg/old/api.go\nREADME.md\n")
+
+	m := NewManager(dir)
+	renames := []PathRename{{Old: "pkg/old", New: "pkg/new", Dir: true}, {Old: "pkg/old/api.go", New: "pkg/new/server.go"}}
+
+	rewrites, err := m.RewriteRulePaths(renames, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewrites) != 6 {
+		t.Fatalf("got %d rewrites, want 6: %+v", len(rewrites), rewrites)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ActiveRulesFile)); string(data) != rules {
+		t.Error("dry run modified the rules file")
+	}
+
+	if _, err := m.RewriteRulePaths(renames, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `# parser rules
+pkg/new/**/*.go
+!./pkg/new/gen.go
+pkg/oldish/x.go
+pkg/new/server.go @grep: Handle
+@find: pkg/old
+` + filepath.Join(dir, "pkg", "new", "doc.md") + `
+---
+pkg/new
+`
+	if data, _ := os.ReadFile(filepath.Join(dir, ActiveRulesFile)); string(data) != want {
+		t.Errorf("rules file =\n%s\nwant\n%s", data, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, RulesDir, "review"+RulesExt)); string(data) != "pkg/new/server.go\nREADME.md\n" {
+		t.Errorf("rule set = %q", data)
+	}
+}
+
+func TestRenamedDirs(t *testing.T) {
+	for _, tc := range []struct{ old, new, wantOld, wantNew string }{
+		{"pkg/old/a.go", "pkg/new/a.go", "pkg/old", "pkg/new"},
+		{"a/x/f.go", "b/x/f.go", "a", "b"},
+		{"pkg/a.go", "pkg/b.go", "", ""},
+		{"a.go", "pkg/a.go", "", ""},
+	} {
+		gotOld, gotNew := renamedDirs(tc.old, tc.new)
+		if gotOld != tc.wantOld || gotNew != tc.wantNew {
+			t.Errorf("renamedDirs(%q, %q) = %q, %q; want %q, %q", tc.old, tc.new, gotOld, gotNew, tc.wantOld, tc.wantNew)
+		}
+	}
+}
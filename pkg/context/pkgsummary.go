@@ -0,0 +1,323 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// The @pkg: directive condenses a project's package manifests — go.mod,
+// package.json and pyproject.toml — into one generated markdown file listing
+// the module path, tool versions and direct dependencies, and drops the raw
+// manifests from the same tier, as if `!go.mod` and so on were written on
+// the directive's line:
+//
+//	@pkg: summary            # manifests in the project root
+//	@pkg: summary tools/cli  # manifests in a subdirectory
+
+// PackageDep is one dependency or tool requirement and its version
+// constraint.
+type PackageDep struct {
+	Name    string
+	Version string
+}
+
+// PackageManifest is the overview of one package manifest.
+type PackageManifest struct {
+	File    string // manifest name, e.g. "go.mod"
+	Name    string // module path or package name
+	Version string
+	Tools   []PackageDep // language and tool versions (go, toolchain, node, python)
+	Deps    []PackageDep // direct dependencies
+	DevDeps []PackageDep // development-only and optional dependencies
+}
+
+// DiscoverManifests parses the package manifests in dir, in a fixed order
+// (go.mod, package.json, pyproject.toml). Unreadable or malformed manifests
+// are skipped.
+func DiscoverManifests(dir string) []PackageManifest {
+	var manifests []PackageManifest
+	for _, parse := range []func(string) *PackageManifest{parseGoModManifest, parsePackageJSONManifest, parsePyprojectManifest} {
+		if mf := parse(dir); mf != nil {
+			manifests = append(manifests, *mf)
+		}
+	}
+	return manifests
+}
+
+// parseGoModManifest lists the module path, go and toolchain versions and
+// the requirements not marked // indirect.
+func parseGoModManifest(dir string) *PackageManifest {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil
+	}
+	mf := &PackageManifest{File: "go.mod"}
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+			} else if len(fields) >= 2 && strings.TrimSpace(comment) != "indirect" {
+				mf.Deps = append(mf.Deps, PackageDep{Name: fields[0], Version: fields[1]})
+			}
+			continue
+		}
+		switch {
+		case fields[0] == "module" && len(fields) >= 2:
+			mf.Name = strings.Trim(fields[1], `"`)
+		case (fields[0] == "go" || fields[0] == "toolchain") && len(fields) >= 2:
+			mf.Tools = append(mf.Tools, PackageDep{Name: fields[0], Version: fields[1]})
+		case fields[0] == "require" && len(fields) >= 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3 && strings.TrimSpace(comment) != "indirect":
+			mf.Deps = append(mf.Deps, PackageDep{Name: fields[1], Version: fields[2]})
+		}
+	}
+	return mf
+}
+
+// parsePackageJSONManifest lists the package name and version, engines and
+// packageManager as tools, and dependencies and devDependencies.
+func parsePackageJSONManifest(dir string) *PackageManifest {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Name            string            `json:"name"`
+		Version         string            `json:"version"`
+		Engines         map[string]string `json:"engines"`
+		PackageManager  string            `json:"packageManager"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	mf := &PackageManifest{File: "package.json", Name: pkg.Name, Version: pkg.Version}
+	mf.Tools = sortedDeps(pkg.Engines)
+	if name, version, ok := strings.Cut(pkg.PackageManager, "@"); ok {
+		mf.Tools = append(mf.Tools, PackageDep{Name: name, Version: version})
+	}
+	mf.Deps = sortedDeps(pkg.Dependencies)
+	mf.DevDeps = sortedDeps(pkg.DevDependencies)
+	return mf
+}
+
+// parsePyprojectManifest reads PEP 621 [project] metadata, falling back to
+// [tool.poetry] for the fields it leaves empty.
+func parsePyprojectManifest(dir string) *PackageManifest {
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	if err != nil {
+		return nil
+	}
+	var py struct {
+		Project struct {
+			Name                 string              `toml:"name"`
+			Version              string              `toml:"version"`
+			RequiresPython       string              `toml:"requires-python"`
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Name            string         `toml:"name"`
+				Version         string         `toml:"version"`
+				Dependencies    map[string]any `toml:"dependencies"`
+				DevDependencies map[string]any `toml:"dev-dependencies"`
+				Group           map[string]struct {
+					Dependencies map[string]any `toml:"dependencies"`
+				} `toml:"group"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &py); err != nil {
+		return nil
+	}
+	project, poetry := py.Project, py.Tool.Poetry
+	mf := &PackageManifest{File: "pyproject.toml", Name: project.Name, Version: project.Version}
+	if mf.Name == "" {
+		mf.Name = poetry.Name
+	}
+	if mf.Version == "" {
+		mf.Version = poetry.Version
+	}
+	if project.RequiresPython != "" {
+		mf.Tools = append(mf.Tools, PackageDep{Name: "python", Version: project.RequiresPython})
+	}
+	for _, req := range project.Dependencies {
+		mf.Deps = append(mf.Deps, parsePEP508(req))
+	}
+	for _, reqs := range project.OptionalDependencies {
+		for _, req := range reqs {
+			mf.DevDeps = append(mf.DevDeps, parsePEP508(req))
+		}
+	}
+
+	for _, dep := range poetryDeps(poetry.Dependencies) {
+		if dep.Name == "python" {
+			if project.RequiresPython == "" {
+				mf.Tools = append(mf.Tools, dep)
+			}
+			continue
+		}
+		mf.Deps = append(mf.Deps, dep)
+	}
+	mf.DevDeps = append(mf.DevDeps, poetryDeps(poetry.DevDependencies)...)
+	for _, group := range poetry.Group {
+		mf.DevDeps = append(mf.DevDeps, poetryDeps(group.Dependencies)...)
+	}
+	sort.Slice(mf.DevDeps, func(i, j int) bool { return mf.DevDeps[i].Name < mf.DevDeps[j].Name })
+	return mf
+}
+
+// parsePEP508 splits a PEP 508 requirement ("requests[socks]>=2.31; python_version>'3.8'")
+// into its name and version constraint, dropping extras and markers.
+func parsePEP508(req string) PackageDep {
+	req, _, _ = strings.Cut(req, ";")
+	req = strings.TrimSpace(req)
+	i := strings.IndexAny(req, "<>=!~[( ")
+	if i < 0 {
+		return PackageDep{Name: req}
+	}
+	name, rest := req[:i], req[i:]
+	if strings.HasPrefix(rest, "[") {
+		if _, after, ok := strings.Cut(rest, "]"); ok {
+			rest = after
+		}
+	}
+	return PackageDep{Name: name, Version: strings.Trim(strings.TrimSpace(rest), "()")}
+}
+
+// poetryDeps lists Poetry dependencies, whose values are either a version
+// string or a table with a version key.
+func poetryDeps(deps map[string]any) []PackageDep {
+	versions := make(map[string]string, len(deps))
+	for name, spec := range deps {
+		switch v := spec.(type) {
+		case string:
+			versions[name] = v
+		case map[string]any:
+			version, _ := v["version"].(string)
+			versions[name] = version
+		default:
+			versions[name] = ""
+		}
+	}
+	return sortedDeps(versions)
+}
+
+// sortedDeps turns a name -> version map into a list sorted by name.
+func sortedDeps(versions map[string]string) []PackageDep {
+	deps := make([]PackageDep, 0, len(versions))
+	for name, version := range versions {
+		deps = append(deps, PackageDep{Name: name, Version: version})
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+// FormatPackageSummary renders parsed manifests as a condensed markdown
+// overview.
+func FormatPackageSummary(dir string, manifests []PackageManifest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Dependencies of %s\n", filepath.Base(dir))
+	if len(manifests) == 0 {
+		sb.WriteString("\nNo go.mod, package.json or pyproject.toml found.\n")
+		return sb.String()
+	}
+	for _, mf := range manifests {
+		fmt.Fprintf(&sb, "\n## %s\n\n", mf.File)
+		if mf.Name != "" {
+			if mf.Version != "" {
+				fmt.Fprintf(&sb, "- Name: `%s` %s\n", mf.Name, mf.Version)
+			} else {
+				fmt.Fprintf(&sb, "- Name: `%s`\n", mf.Name)
+			}
+		}
+		for _, tool := range mf.Tools {
+			fmt.Fprintf(&sb, "- %s: %s\n", tool.Name, tool.Version)
+		}
+		writeDepList(&sb, "Dependencies", mf.Deps)
+		writeDepList(&sb, "Dev and optional dependencies", mf.DevDeps)
+	}
+	return sb.String()
+}
+
+func writeDepList(sb *strings.Builder, title string, deps []PackageDep) {
+	if len(deps) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n### %s (%d)\n\n", title, len(deps))
+	for _, dep := range deps {
+		if dep.Version != "" {
+			fmt.Fprintf(sb, "- `%s` %s\n", dep.Name, dep.Version)
+		} else {
+			fmt.Fprintf(sb, "- `%s`\n", dep.Name)
+		}
+	}
+}
+
+// generatePkgSummaryFile writes the @pkg: summary for spec ("summary",
+// optionally followed by a directory relative to the working directory) to
+// .grove/pkg and returns its absolute path along with the summarized
+// manifests, relative to the working directory where possible.
+func (m *Manager) generatePkgSummaryFile(spec string) (string, []string, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || fields[0] != "summary" || len(fields) > 2 {
+		return "", nil, fmt.Errorf("expected `@pkg: summary [dir]`, got %q", spec)
+	}
+	target := m.workDir
+	if len(fields) == 2 {
+		target = expandHomeAndDot(strings.Trim(fields[1], `"`))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(m.workDir, target)
+		}
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "", nil, fmt.Errorf("%s is not a directory", target)
+	}
+
+	pkgDir := filepath.Join(m.workDir, GroveDir, "pkg")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("failed to create pkg directory: %w", err)
+	}
+
+	name := "summary.md"
+	if rel, err := filepath.Rel(m.workDir, target); err == nil && rel != "." {
+		name = "summary-" + strings.NewReplacer("/", "-", "\\", "-", "..", "up").Replace(filepath.ToSlash(rel)) + ".md"
+	}
+	manifests := DiscoverManifests(target)
+	outPath := filepath.Join(pkgDir, name)
+	if err := os.WriteFile(outPath, []byte(FormatPackageSummary(target, manifests)), 0o644); err != nil { //nolint:gosec // dependency summary, not sensitive
+		return "", nil, fmt.Errorf("failed to write package summary: %w", err)
+	}
+
+	var replaced []string
+	for _, mf := range manifests {
+		path := filepath.Join(target, mf.File)
+		if rel, err := filepath.Rel(m.workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.ToSlash(rel)
+		}
+		replaced = append(replaced, path)
+	}
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return outPath, replaced, nil
+	}
+	return absPath, replaced, nil
+}
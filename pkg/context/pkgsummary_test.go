@@ -0,0 +1,123 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverManifests(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "go.mod"), `module github.com/example/tool
+
+go 1.22
+
+toolchain go1.22.4
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/sys v0.20.0 // indirect
+)
+`)
+	fsWriteString(t, filepath.Join(dir, "package.json"), `{
+  "name": "web",
+  "version": "0.3.0",
+  "engines": {"node": ">=20"},
+  "packageManager": "pnpm@9.1.0",
+  "dependencies": {"react": "^18.3.0"},
+  "devDependencies": {"vite": "^5.2.0"}
+}`)
+	fsWriteString(t, filepath.Join(dir, "pyproject.toml"), `[project]
+name = "svc"
+version = "1.0.0"
+requires-python = ">=3.11"
+dependencies = ["requests[socks]>=2.31; python_version > '3.8'", "click"]
+
+[project.optional-dependencies]
+test = ["pytest>=8"]
+`)
+
+	var got []string
+	for _, mf := range DiscoverManifests(dir) {
+		got = append(got, mf.File+" "+mf.Name+" "+mf.Version)
+		for _, d := range mf.Tools {
+			got = append(got, "  tool "+d.Name+" "+d.Version)
+		}
+		for _, d := range mf.Deps {
+			got = append(got, "  dep "+d.Name+" "+d.Version)
+		}
+		for _, d := range mf.DevDeps {
+			got = append(got, "  dev "+d.Name+" "+d.Version)
+		}
+	}
+	want := []string{
+		"go.mod github.com/example/tool ",
+		"  tool go 1.22",
+		"  tool toolchain go1.22.4",
+		"  dep github.com/spf13/cobra v1.8.0",
+		"  dep github.com/stretchr/testify v1.9.0",
+		"package.json web 0.3.0",
+		"  tool node >=20",
+		"  tool pnpm 9.1.0",
+		"  dep react ^18.3.0",
+		"  dev vite ^5.2.0",
+		"pyproject.toml svc 1.0.0",
+		"  tool python >=3.11",
+		"  dep requests >=2.31",
+		"  dep click ",
+		"  dev pytest >=8",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverManifests() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPoetryManifest(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "pyproject.toml"), `[tool.poetry]
+name = "legacy"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+httpx = { version = "^0.27", extras = ["http2"] }
+
+[tool.poetry.group.dev.dependencies]
+ruff = "^0.4"
+`)
+	mfs := DiscoverManifests(dir)
+	if len(mfs) != 1 {
+		t.Fatalf("DiscoverManifests() found %d manifests, want 1", len(mfs))
+	}
+	mf := mfs[0]
+	if mf.Name != "legacy" || !reflect.DeepEqual(mf.Tools, []PackageDep{{Name: "python", Version: "^3.10"}}) {
+		t.Errorf("unexpected name or tools: %+v", mf)
+	}
+	if !reflect.DeepEqual(mf.Deps, []PackageDep{{Name: "httpx", Version: "^0.27"}}) ||
+		!reflect.DeepEqual(mf.DevDeps, []PackageDep{{Name: "ruff", Version: "^0.4"}}) {
+		t.Errorf("unexpected dependencies: %+v", mf)
+	}
+}
+
+func TestGeneratePkgSummaryFile(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "tools", "go.mod"), "module example.com/tools\n\ngo 1.22\n")
+	m := &Manager{workDir: dir}
+
+	path, manifests, err := m.generatePkgSummaryFile("summary tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, GroveDir, "pkg", "summary-tools.md"); path != want {
+		t.Errorf("summary path = %s, want %s", path, want)
+	}
+	if !reflect.DeepEqual(manifests, []string{"tools/go.mod"}) {
+		t.Errorf("replaced manifests = %v, want [tools/go.mod]", manifests)
+	}
+	if _, _, err := m.generatePkgSummaryFile("deps"); err == nil {
+		t.Error("expected an error for an unknown @pkg: mode")
+	}
+}
@@ -0,0 +1,20 @@
+package context
+
+import "syscall"
+
+// ioprioIdle is IOPRIO_CLASS_IDLE shifted into the class bits of an I/O
+// priority (see ioprio_set(2)).
+const ioprioIdle = 3 << 13
+
+// lowerPriority gives the process the lowest CPU priority and the idle I/O
+// class, so it only gets the disk when nothing else wants it.
+func lowerPriority() error {
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, 19); err != nil {
+		return err
+	}
+	const ioprioWhoProcess = 1
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, 0, ioprioIdle); errno != 0 {
+		return errno
+	}
+	return nil
+}
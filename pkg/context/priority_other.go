@@ -0,0 +1,9 @@
+//go:build !unix
+
+package context
+
+// lowerPriority does nothing off Unix; idle mode only shrinks the worker
+// pools there.
+func lowerPriority() error {
+	return nil
+}
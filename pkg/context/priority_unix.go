@@ -0,0 +1,11 @@
+//go:build unix && !linux
+
+package context
+
+import "syscall"
+
+// lowerPriority gives the process the lowest CPU priority. I/O priority
+// cannot be set portably outside Linux.
+func lowerPriority() error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, 0, 19)
+}
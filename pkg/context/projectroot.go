@@ -0,0 +1,64 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// projectMarkers mark a project root: the grove config names core's
+// config.FindConfigFile recognizes, and the .grove directory cx keeps its
+// rules and state in.
+var projectMarkers = []string{
+	"grove.yml",
+	"grove.yaml",
+	"grove.toml",
+	".grove.yml",
+	".grove.yaml",
+	".grove.toml",
+	".grove",
+}
+
+// workspaceMarkers also count a git checkout, so repos without grove config
+// (and ecosystem worktrees, whose .git is a file) are found too.
+var workspaceMarkers = append(append([]string(nil), projectMarkers...), ".git")
+
+// FindProjectRoot returns the nearest directory at or above dir that holds a
+// grove config file or a .grove directory, or "" when there is none. The
+// home directory is never a project root: it holds user-wide grove files,
+// and anchoring every unrelated directory below it there would be wrong.
+//
+// Commands resolve rules from the project root rather than the current
+// directory, so relative patterns mean the same thing from any subdirectory.
+func FindProjectRoot(dir string) string {
+	return findUp(dir, projectMarkers)
+}
+
+// findWorkspaceRoot is FindProjectRoot, also stopping at a git checkout.
+func findWorkspaceRoot(dir string) string {
+	return findUp(dir, workspaceMarkers)
+}
+
+// findUp returns the nearest directory at or above dir, below the home
+// directory, that contains any of markers.
+func findUp(dir string, markers []string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		if dir == home {
+			return ""
+		}
+		for _, name := range markers {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,65 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	fsWriteString(t, filepath.Join(root, "grove.yml"), "version: 1.0\n")
+	sub := filepath.Join(root, "pkg", "api")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectRoot(sub); got != root {
+		t.Errorf("FindProjectRoot(%s) = %q, want %q", sub, got, root)
+	}
+
+	// A nested .grove directory marks a closer root.
+	if err := os.MkdirAll(filepath.Join(root, "pkg", ".grove"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := FindProjectRoot(sub), filepath.Join(root, "pkg"); got != want {
+		t.Errorf("FindProjectRoot(%s) = %q, want %q", sub, got, want)
+	}
+
+	if got := FindProjectRoot(t.TempDir()); got != "" {
+		t.Errorf("expected no root outside a project, got %q", got)
+	}
+}
+
+func TestWorkspaceDirAtEveryLevel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	eco := t.TempDir()
+	fsWriteString(t, filepath.Join(eco, "grove.yml"), "name: eco\n")
+	fsWriteString(t, filepath.Join(eco, ".git", "HEAD"), "ref: refs/heads/main\n")
+	fsWriteString(t, filepath.Join(eco, "api", "grove.yml"), "name: api\n")
+	fsWriteString(t, filepath.Join(eco, "api", ".git"), "gitdir: ../.git/worktrees/api\n")
+	fsWriteString(t, filepath.Join(eco, "api", "internal", "server.go"), "package internal\n")
+	fsWriteString(t, filepath.Join(eco, "tools", ".git", "HEAD"), "ref: refs/heads/main\n")
+	fsWriteString(t, filepath.Join(eco, "tools", "cmd", "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(eco, "docs", "guide", "intro.md"), "# intro\n")
+
+	for _, tc := range []struct{ dir, want string }{
+		{".", "."},
+		{"docs", "."},
+		{"docs/guide", "."},
+		{"api", "api"},
+		{"api/internal", "api"},
+		{"tools", "tools"},
+		{"tools/cmd", "tools"},
+	} {
+		m := NewManager(filepath.Join(eco, tc.dir), WithNoState())
+		want := filepath.Join(eco, tc.want)
+		if got := m.workspaceDir(); got != want {
+			t.Errorf("workspaceDir from %s = %s, want %s", tc.dir, got, want)
+		}
+		if got := m.aliasResolutionDir(); got != want {
+			t.Errorf("aliasResolutionDir from %s = %s, want %s", tc.dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package context
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Rule provenance. With cx.provenance, or `cx generate --provenance`, each
+// file of a generated artifact is preceded by a comment naming the rules
+// file line that included it and its tier, so an unexpected file can be
+// traced back to its rule from the artifact itself:
+//
+//	<!-- included by .cx/default.rules:12 (hot) -->
+//	<file path="api/server.go">
+//
+// Line numbers are those of the root rules file; a file pulled in by an
+// import is attributed to the import line. Templated formats get the same
+// text as OutputFile.Rule. Attribution resolves the rules a second time, so
+// provenance is off by default.
+
+// ruleProvenance is the attribution of the artifact being written.
+type ruleProvenance struct {
+	tier      string
+	rulesPath string         // as displayed
+	lineOf    map[string]int // snapshotKey -> rules line
+}
+
+// SetProvenance turns rule provenance comments on for this manager's
+// generations, in addition to cx.provenance. Plain state like
+// stripComments.
+func (m *Manager) SetProvenance(v bool) {
+	m.provenance = v
+}
+
+func (m *Manager) provenanceEnabled() bool {
+	return m.provenance || m.cxConfig().Provenance
+}
+
+// useProvenance makes the writers annotate the files of tier with the rule
+// that included them until the returned function is called. It does nothing
+// when provenance is off or the rules cannot be attributed.
+func (m *Manager) useProvenance(tier string) func() {
+	saved := m.fileProvenance
+	restore := func() { m.fileProvenance = saved }
+	if !m.provenanceEnabled() {
+		return restore
+	}
+	rulesContent, rulesPath := m.generatingRules()
+	if len(rulesContent) == 0 {
+		return restore
+	}
+	attribution, _, _, _, _, err := m.ResolveFilesWithAttribution(string(rulesContent))
+	if err != nil {
+		Warnf("could not attribute files to rules for provenance: %v", err)
+		return restore
+	}
+	p := &ruleProvenance{tier: tier, rulesPath: rulesPath, lineOf: make(map[string]int)}
+	if rel, err := filepath.Rel(m.workDir, rulesPath); err == nil && !strings.HasPrefix(rel, "..") {
+		p.rulesPath = filepath.ToSlash(rel)
+	}
+	for line, files := range attribution {
+		for _, f := range files {
+			key := m.snapshotKey(f)
+			// A file matched by several rules is credited to the first.
+			if prev, ok := p.lineOf[key]; !ok || line < prev {
+				p.lineOf[key] = line
+			}
+		}
+	}
+	m.fileProvenance = p
+	return restore
+}
+
+// ruleOf returns "<rules file>:<line>" for the rule that included file, or
+// "" when provenance is off or the file is not attributed to a rule.
+func (m *Manager) ruleOf(file string) string {
+	p := m.fileProvenance
+	if p == nil {
+		return ""
+	}
+	line, ok := p.lineOf[m.snapshotKey(file)]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", p.rulesPath, line)
+}
+
+// writeProvenance writes the provenance comment of file, if any.
+func (m *Manager) writeProvenance(w io.Writer, file string, useXMLFormat bool) {
+	rule := m.ruleOf(file)
+	if rule == "" {
+		return
+	}
+	if useXMLFormat {
+		fmt.Fprintf(w, "    <!-- included by %s (%s) -->\n", rule, m.fileProvenance.tier)
+	} else {
+		fmt.Fprintf(w, "# included by %s (%s)\n", rule, m.fileProvenance.tier)
+	}
+}
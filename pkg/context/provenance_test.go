@@ -0,0 +1,52 @@
+package context
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProvenanceComments verifies that each streamed file is preceded by
+// the rules line that included it, and only when provenance is on.
+func TestProvenanceComments(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "api", "a.go"), "package api\n")
+	fsWriteString(t, filepath.Join(dir, "docs", "b.md"), "# B\n")
+	rulesPath := filepath.Join(dir, "prov.rules")
+	fsWriteString(t, rulesPath, "# sources\napi/*.go\ndocs/*.md\n")
+
+	grove := filepath.Join(dir, ".grove")
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(grove, "context"), filepath.Join(grove, "cached-context"),
+		filepath.Join(grove, "context-files"), filepath.Join(grove, "cached-context-files"))
+	stream := func() string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := m.StreamContext(&buf, rulesPath, true); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	if out := stream(); strings.Contains(out, "included by") {
+		t.Errorf("provenance is off by default:\n%s", out)
+	}
+
+	m.SetProvenance(true)
+	out := stream()
+	for _, want := range []string{
+		"    <!-- included by prov.rules:2 (hot) -->\n    <file path=\"api/a.go\">",
+		"    <!-- included by prov.rules:3 (hot) -->\n    <file path=\"docs/b.md\">",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stream missing %q:\n%s", want, out)
+		}
+	}
+
+	m.SetOutputFormat(FormatJSONL)
+	if out := stream(); !strings.Contains(out, `"rule":"prov.rules:2"`) {
+		t.Errorf("jsonl records should carry the rule:\n%s", out)
+	}
+}
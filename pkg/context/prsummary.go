@@ -0,0 +1,280 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PRFile is one context file in a PR summary, with the rules line that
+// included it.
+type PRFile struct {
+	Path    string `json:"path"`
+	Tier    string `json:"tier"` // "hot" or "cold"
+	Tokens  int    `json:"tokens"`
+	LineNum int    `json:"line,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+}
+
+// PRExcerpt is the head of one context file, quoted in a PR summary.
+type PRExcerpt struct {
+	Path      string `json:"path"`
+	Language  string `json:"language,omitempty"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated"`
+}
+
+// PRSummary describes the context a change was generated from, for posting
+// on its pull request (cx annotate-pr): which files, how they were
+// selected, how much of the token budget they used, and where the context
+// came from.
+type PRSummary struct {
+	Workspace   string      `json:"workspace,omitempty"`
+	RulesFile   string      `json:"rulesFile,omitempty"`
+	Rules       string      `json:"rules,omitempty"`
+	Commit      string      `json:"commit,omitempty"`
+	Artifact    string      `json:"artifact,omitempty"`
+	GeneratedAt *time.Time  `json:"generatedAt,omitempty"` // modification time of the hot artifact
+	Checksum    string      `json:"checksum"`              // "verified", "modified", or "none"
+	HotTokens   int         `json:"hotTokens"`
+	ColdTokens  int         `json:"coldTokens"`
+	TokenBudget int         `json:"tokenBudget,omitempty"`
+	Files       []PRFile    `json:"files"`
+	Excerpts    []PRExcerpt `json:"excerpts,omitempty"`
+}
+
+// PRSummaryOptions controls the excerpts in a PR summary.
+type PRSummaryOptions struct {
+	Excerpts     int // largest hot files to quote
+	ExcerptLines int // lines quoted from each
+}
+
+// BuildPRSummary summarizes the hot and cold files resolved from the rules
+// at rulesPath (rulesContent). Files are sorted hot first, then by tokens.
+func (m *Manager) BuildPRSummary(rulesPath string, rulesContent []byte, hot, cold []string, opts PRSummaryOptions) *PRSummary {
+	s := &PRSummary{
+		RulesFile:   m.snapshotKey(rulesPath),
+		Rules:       string(rulesContent),
+		Artifact:    m.ResolveContextPath(),
+		Checksum:    "none",
+		TokenBudget: m.cxConfig().TokenBudget,
+		Files:       []PRFile{},
+	}
+	if rulesPath == "" {
+		s.RulesFile = ""
+	}
+	if out, err := exec.Command("git", "-C", m.workDir, "rev-parse", "--short", "HEAD").Output(); err == nil {
+		s.Commit = strings.TrimSpace(string(out))
+	}
+	if info, err := os.Stat(s.Artifact); err == nil {
+		t := info.ModTime().UTC()
+		s.GeneratedAt = &t
+		switch err := VerifyArtifactChecksum(s.Artifact); {
+		case err == nil:
+			s.Checksum = "verified"
+		case !errors.Is(err, ErrNoChecksum):
+			s.Checksum = "modified"
+		}
+		s.Artifact = m.snapshotKey(s.Artifact)
+	} else {
+		s.Artifact = ""
+	}
+
+	lineOf := make(map[string]int)
+	if len(rulesContent) > 0 {
+		if attribution, _, _, _, _, err := m.ResolveFilesWithAttribution(string(rulesContent)); err == nil {
+			for line, files := range attribution {
+				for _, f := range files {
+					lineOf[m.snapshotKey(f)] = line
+				}
+			}
+		}
+	}
+	rulesLines := strings.Split(string(rulesContent), "\n")
+
+	provider := GetStatsProvider()
+	for tier, files := range map[string][]string{"hot": hot, "cold": cold} {
+		for _, file := range files {
+			f := PRFile{Path: m.snapshotKey(file), Tier: tier}
+			abs := file
+			if !filepath.IsAbs(abs) {
+				abs = filepath.Join(m.workDir, file)
+			}
+			if info, err := provider.GetFileStats(abs); err == nil {
+				f.Tokens = info.Tokens
+			}
+			if line := lineOf[f.Path]; line > 0 && line <= len(rulesLines) {
+				f.LineNum = line
+				f.Rule, _ = SplitRuleAnnotation(strings.TrimSpace(rulesLines[line-1]))
+			}
+			if tier == "hot" {
+				s.HotTokens += f.Tokens
+			} else {
+				s.ColdTokens += f.Tokens
+			}
+			s.Files = append(s.Files, f)
+		}
+	}
+	sort.SliceStable(s.Files, func(i, j int) bool {
+		a, b := s.Files[i], s.Files[j]
+		if a.Tier != b.Tier {
+			return a.Tier == "hot"
+		}
+		if a.Tokens != b.Tokens {
+			return a.Tokens > b.Tokens
+		}
+		return a.Path < b.Path
+	})
+
+	detector := m.LanguageDetector()
+	for _, f := range s.Files {
+		if len(s.Excerpts) >= opts.Excerpts || f.Tier != "hot" {
+			break
+		}
+		path := f.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		text, truncated, err := fileHead(path, opts.ExcerptLines)
+		if err != nil || strings.TrimSpace(text) == "" {
+			continue
+		}
+		s.Excerpts = append(s.Excerpts, PRExcerpt{Path: f.Path, Language: detector.DetectFile(path), Text: text, Truncated: truncated})
+	}
+	return s
+}
+
+// fileHead returns up to n lines from the start of the file at path.
+func fileHead(path string, n int) (string, bool, error) {
+	content, err := readRegularFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	var b strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lines := 0
+	for scanner.Scan() {
+		if lines == n {
+			return b.String(), true, nil
+		}
+		b.WriteString(scanner.Text())
+		b.WriteByte('\n')
+		lines++
+	}
+	return b.String(), false, nil
+}
+
+// Markdown renders the summary as a pull request comment: provenance and
+// token usage, a table of up to maxFiles files, then the rules and excerpts
+// in collapsed sections.
+func (s *PRSummary) Markdown(maxFiles int) string {
+	var b strings.Builder
+	b.WriteString("### Context used for this change\n\n")
+
+	var facts []string
+	if s.Workspace != "" {
+		facts = append(facts, fmt.Sprintf("**Workspace:** `%s`", s.Workspace))
+	}
+	if s.RulesFile != "" {
+		facts = append(facts, fmt.Sprintf("**Rules:** `%s`", s.RulesFile))
+	}
+	if s.Commit != "" {
+		facts = append(facts, fmt.Sprintf("**Commit:** `%s`", s.Commit))
+	}
+	if s.GeneratedAt != nil {
+		generated := fmt.Sprintf("**Generated:** %s", s.GeneratedAt.Format("2006-01-02 15:04 UTC"))
+		switch s.Checksum {
+		case "verified":
+			generated += " (checksum verified)"
+		case "modified":
+			generated += " (**artifact edited since generation**)"
+		}
+		facts = append(facts, generated)
+	}
+	if len(facts) > 0 {
+		b.WriteString(strings.Join(facts, " · "))
+		b.WriteString("\n\n")
+	}
+
+	hotFiles := 0
+	for _, f := range s.Files {
+		if f.Tier == "hot" {
+			hotFiles++
+		}
+	}
+	fmt.Fprintf(&b, "**Tokens:** ~%s hot (%d files)", FormatTokenCount(s.HotTokens), hotFiles)
+	if cold := len(s.Files) - hotFiles; cold > 0 {
+		fmt.Fprintf(&b, " · ~%s cold (%d files)", FormatTokenCount(s.ColdTokens), cold)
+	}
+	if s.TokenBudget > 0 {
+		fmt.Fprintf(&b, " · %d%% of the %s budget", s.HotTokens*100/s.TokenBudget, FormatTokenCount(s.TokenBudget))
+		if s.HotTokens > s.TokenBudget {
+			b.WriteString(" (**over budget**)")
+		}
+	}
+	b.WriteString("\n\n")
+
+	if len(s.Files) == 0 {
+		b.WriteString("_No files in context._\n")
+	} else {
+		b.WriteString("| File | Tier | Tokens | Included by |\n|---|---|---:|---|\n")
+		shown := s.Files
+		if maxFiles > 0 && len(shown) > maxFiles {
+			shown = shown[:maxFiles]
+		}
+		for _, f := range shown {
+			rule := ""
+			if f.LineNum > 0 {
+				rule = fmt.Sprintf("L%d `%s`", f.LineNum, strings.ReplaceAll(f.Rule, "|", `\|`))
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", f.Path, f.Tier, FormatTokenCount(f.Tokens), rule)
+		}
+		if rest := s.Files[len(shown):]; len(rest) > 0 {
+			tokens := 0
+			for _, f := range rest {
+				tokens += f.Tokens
+			}
+			fmt.Fprintf(&b, "\n_…and %d more files (~%s tokens)._\n", len(rest), FormatTokenCount(tokens))
+		}
+	}
+
+	if strings.TrimSpace(s.Rules) != "" {
+		b.WriteString("\n<details><summary>Rules</summary>\n\n")
+		writeFenced(&b, "gitignore", s.Rules)
+		b.WriteString("</details>\n")
+	}
+	if len(s.Excerpts) > 0 {
+		b.WriteString("\n<details><summary>Excerpts</summary>\n\n")
+		for _, e := range s.Excerpts {
+			fmt.Fprintf(&b, "**`%s`**", e.Path)
+			if e.Truncated {
+				b.WriteString(" (opening lines)")
+			}
+			b.WriteString("\n\n")
+			writeFenced(&b, e.Language, e.Text)
+		}
+		b.WriteString("</details>\n")
+	}
+	return b.String()
+}
+
+// writeFenced writes text as a fenced code block, lengthening the fence
+// past any backtick run inside it.
+func writeFenced(b *strings.Builder, language, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	fmt.Fprintf(b, "%s%s\n%s%s\n\n", fence, language, text, fence)
+}
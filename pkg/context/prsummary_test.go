@@ -0,0 +1,59 @@
+package context
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPRSummaryMarkdown(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	resetTokenRatios()
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  token_budget: 100\n")
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n\n// ```fenced```\n"+strings.Repeat("x", 80)+"\n")
+	fsWriteString(t, filepath.Join(dir, "util.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "README.md"), "# readme\n")
+	rules := "*.go\n---\n*.md\n"
+	fsWriteString(t, filepath.Join(dir, GroveDir, "rules"), rules)
+
+	m := NewManager(dir)
+	s := m.BuildPRSummary(filepath.Join(dir, GroveDir, "rules"), []byte(rules), []string{"util.go", "main.go"}, []string{"README.md"}, PRSummaryOptions{Excerpts: 1, ExcerptLines: 2})
+	if len(s.Files) != 3 || s.Files[0].Path != "main.go" || s.Files[2].Tier != "cold" {
+		t.Fatalf("files = %+v, want main.go first and README.md last", s.Files)
+	}
+	if s.Files[0].LineNum != 1 || s.Files[0].Rule != "*.go" {
+		t.Errorf("main.go attributed to L%d %q, want L1 *.go", s.Files[0].LineNum, s.Files[0].Rule)
+	}
+	if s.RulesFile != ".grove/rules" || s.TokenBudget != 100 {
+		t.Errorf("rules file %q, budget %d", s.RulesFile, s.TokenBudget)
+	}
+	if len(s.Excerpts) != 1 || !s.Excerpts[0].Truncated || s.Excerpts[0].Text != "package main\n\n" {
+		t.Errorf("excerpts = %+v", s.Excerpts)
+	}
+
+	md := s.Markdown(1)
+	for _, part := range []string{
+		"**Rules:** `.grove/rules`",
+		"| `main.go` | hot |",
+		"L1 `*.go`",
+		"of the 100 budget",
+		"_…and 2 more files",
+		"<details><summary>Rules</summary>",
+	} {
+		if !strings.Contains(md, part) {
+			t.Errorf("markdown lacks %q:\n%s", part, md)
+		}
+	}
+	if strings.Contains(md, "| `util.go`") {
+		t.Error("--max-files 1 should list only the largest file")
+	}
+}
+
+func TestWriteFenced(t *testing.T) {
+	var b strings.Builder
+	writeFenced(&b, "go", "a ``` b")
+	if got := b.String(); got != "````go\na ``` b\n````\n\n" {
+		t.Errorf("writeFenced = %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// requireDirective is a single `@require: <path>` line. A requirement
+// declared above the `---` separator must end up in the hot context; one
+// declared below it must end up in the cold context.
+type requireDirective struct {
+	Path    string
+	LineNum int
+	Section string // rules section the directive appears in
+}
+
+// RequiredFileIssue describes a `@require:` path that did not make it into
+// the resolved context.
+type RequiredFileIssue struct {
+	Path    string `json:"path"`
+	LineNum int    `json:"line"`
+	Section string `json:"section"` // "hot" or "cold"
+	Reason  string `json:"reason"`
+}
+
+// parseRequireDirectives collects the `@require:` lines of a rules file.
+func parseRequireDirectives(content []byte) []requireDirective {
+	var reqs []requireDirective
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	section := HotSection
+	seenBare := false
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text())))
+		if name, ok := ParseSectionSeparator(line); ok {
+			if name == "" {
+				if seenBare {
+					continue
+				}
+				seenBare, name = true, ColdSection
+			}
+			section = name
+			continue
+		}
+		if !strings.HasPrefix(line, "@require:") {
+			continue
+		}
+		path := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "@require:")), `"`)
+		if path == "" {
+			continue
+		}
+		reqs = append(reqs, requireDirective{Path: expandHomeAndDot(path), LineNum: lineNum, Section: section})
+	}
+	return reqs
+}
+
+// RequiredPaths returns every path declared with `@require:` in rulesContent,
+// regardless of section.
+func RequiredPaths(rulesContent []byte) []string {
+	var paths []string
+	for _, req := range parseRequireDirectives(rulesContent) {
+		paths = append(paths, req.Path)
+	}
+	return paths
+}
+
+// RequiredFileIssues checks the `@require:` directives of rulesContent that
+// belong to section ("hot" or "cold") against the resolved files of that
+// section, reporting each required path that is missing on disk or was not
+// included (unmatched, excluded by a later rule, or moved to the other
+// section).
+func (m *Manager) RequiredFileIssues(rulesContent []byte, section string, files []string) []RequiredFileIssue {
+	reqs := parseRequireDirectives(rulesContent)
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	included := make(map[string]bool, len(files))
+	for _, f := range files {
+		included[m.requirePathKey(f)] = true
+	}
+
+	var issues []RequiredFileIssue
+	for _, req := range reqs {
+		if m.SectionIsHot(req.Section) != (section == "hot") {
+			continue
+		}
+		key := m.requirePathKey(req.Path)
+		if included[key] {
+			continue
+		}
+		reason := "not included in the " + section + " context (unmatched or excluded by a later rule)"
+		if _, err := os.Stat(key); os.IsNotExist(err) {
+			reason = "file does not exist"
+		}
+		issues = append(issues, RequiredFileIssue{Path: req.Path, LineNum: req.LineNum, Section: section, Reason: reason})
+	}
+	return issues
+}
+
+// requirePathKey maps a resolved or required path to a cleaned absolute path
+// anchored at the rules base directory.
+func (m *Manager) requirePathKey(p string) string {
+	if !filepath.IsAbs(p) {
+		base := m.rulesBaseDir
+		if base == "" {
+			base = m.workDir
+		}
+		p = filepath.Join(base, p)
+	}
+	return filepath.Clean(p)
+}
+
+// checkRequiredFiles returns an error listing every unmet `@require:`
+// directive for section, or nil when all requirements are satisfied.
+func (m *Manager) checkRequiredFiles(rulesContent []byte, section string, files []string) error {
+	issues := m.RequiredFileIssues(rulesContent, section, files)
+	if len(issues) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d required file(s) missing from the %s context:", len(issues), section)
+	for _, issue := range issues {
+		fmt.Fprintf(&sb, "\n  line %d: @require: %s — %s", issue.LineNum, issue.Path, issue.Reason)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
@@ -0,0 +1,51 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRequiredFileIssues verifies that @require: is checked per section and
+// distinguishes missing files from files that resolution dropped.
+func TestRequiredFileIssues(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"README.md", "docs/ARCHITECTURE.md", "docs/API.md"} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules := []byte(`@require: README.md
+@require: ./docs/ARCHITECTURE.md # moved last sprint
+@require: docs/GONE.md
+**/*.md
+!docs/ARCHITECTURE.md
+---
+@require: docs/API.md
+`)
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+
+	hot := m.RequiredFileIssues(rules, "hot", []string{"README.md", "docs/API.md"})
+	if len(hot) != 2 {
+		t.Fatalf("got %d hot issues, want 2: %+v", len(hot), hot)
+	}
+	if hot[0].Path != "docs/ARCHITECTURE.md" || hot[0].LineNum != 2 || !strings.Contains(hot[0].Reason, "not included") {
+		t.Errorf("unexpected excluded-file issue: %+v", hot[0])
+	}
+	if hot[1].Path != "docs/GONE.md" || hot[1].Reason != "file does not exist" {
+		t.Errorf("unexpected missing-file issue: %+v", hot[1])
+	}
+
+	if cold := m.RequiredFileIssues(rules, "cold", []string{filepath.Join(dir, "docs/API.md")}); len(cold) != 0 {
+		t.Errorf("cold requirement satisfied by absolute path, got issues: %+v", cold)
+	}
+	if err := m.checkRequiredFiles(rules, "cold", nil); err == nil {
+		t.Errorf("expected an error when the cold requirement is unmet")
+	}
+}
@@ -1,10 +1,11 @@
 package context
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -28,7 +29,12 @@ func IsRelativeExternalPath(pattern string) bool {
 // patternInfo holds information about a pattern including any associated directives
 
 // expandAllRules recursively resolves rules, including those from @default directives.
-func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, importLineNum int) (hotRules, coldRules []RuleInfo, viewPaths, treePaths []string, err error) {
+// A rules file already on the import stack is skipped (a cycle) and nesting is
+// bounded by maxRulesImportDepth. Results are memoized per rules file and
+// import line, validated against the content hash of every rules file the
+// expansion read, so a ruleset imported from several parents, or expanded
+// again for the cold section, is only resolved once.
+func (m *Manager) expandAllRules(rulesPath string, run *expansionRun, importLineNum int) (hotRules, coldRules []RuleInfo, viewPaths, treePaths []string, err error) {
 	defer profiling.Start("context.expandAllRules").Stop()
 	// Resolve relative paths against workDir, not process CWD.
 	if !filepath.IsAbs(rulesPath) {
@@ -38,22 +44,75 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to get absolute path for rules: %w", err)
 	}
+	if run.depth == 0 {
+		// @allow-path grants last until the next top-level expansion, so an
+		// untrusted request or a rules file no longer in use stops granting.
+		defer func() { m.setGrantedRoots(run.grants) }()
+	}
 
-	if visited[absRulesPath] {
-		// Circular dependency detected, return to prevent infinite loop.
+	if run.visited[absRulesPath] {
+		// Circular dependency detected, return to prevent infinite loop. The
+		// importers' results now depend on the import stack, so they are not
+		// memoized.
+		run.tainted++
 		return nil, nil, nil, nil, nil
 	}
-	visited[absRulesPath] = true
+	if run.depth >= maxRulesImportDepth {
+		return nil, nil, nil, nil, fmt.Errorf("rules imports nested more than %d levels deep at %s", maxRulesImportDepth, absRulesPath)
+	}
 
-	rulesContent, err := os.ReadFile(absRulesPath)
+	rulesContent, err := m.readRulesFile(absRulesPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// If a default rules file doesn't exist, it's not an error, just return empty.
+			run.recordDeps(map[string]string{absRulesPath: ""})
 			return nil, nil, nil, nil, nil
 		}
 		return nil, nil, nil, nil, fmt.Errorf("reading rules file %s: %w", absRulesPath, err)
 	}
 
+	key := m.expandMemoKey(absRulesPath, importLineNum)
+	entry, ok := m.lookupExpandMemo(key)
+	m.cacheStats.expand.record(ok)
+	if ok {
+		run.recordDeps(entry.deps)
+		for path, content := range entry.allowPaths {
+			m.applyAllowPaths(run, path, content)
+		}
+		r := entry.result
+		return r.hot, r.cold, r.view, r.tree, nil
+	}
+
+	run.visited[absRulesPath] = true
+	run.depth++
+	deps := make(map[string]string)
+	run.deps = append(run.deps, deps)
+	allowPaths := make(map[string][]byte)
+	run.allowPaths = append(run.allowPaths, allowPaths)
+	run.recordDeps(map[string]string{absRulesPath: rulesContentHash(rulesContent)})
+	tainted := run.tainted
+	if hasVolatileRules(rulesContent) {
+		run.tainted++
+	}
+	// Skipped rules are reported as a side effect of expansion, so an
+	// expansion that skipped any must run again to report them again.
+	skipped := m.skippedRuleTotal()
+	defer func() {
+		delete(run.visited, absRulesPath)
+		run.depth--
+		run.deps = run.deps[:len(run.deps)-1]
+		run.allowPaths = run.allowPaths[:len(run.allowPaths)-1]
+		if err == nil && run.tainted == tainted && m.skippedRuleTotal() == skipped {
+			m.storeExpandMemo(key, expandResult{hot: hotRules, cold: coldRules, view: viewPaths, tree: treePaths}, deps, allowPaths)
+		}
+	}()
+	return m.expandRulesContent(absRulesPath, rulesContent, run, importLineNum)
+}
+
+// expandRulesContent expands the parsed content of the rules file at
+// absRulesPath; see expandAllRules.
+func (m *Manager) expandRulesContent(absRulesPath string, rulesContent []byte, run *expansionRun, importLineNum int) (hotRules, coldRules []RuleInfo, viewPaths, treePaths []string, err error) {
+	m.applyAllowPaths(run, absRulesPath, rulesContent)
 	parsed, err := m.parseRulesFileContent(rulesContent)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("parsing rules file %s: %w", absRulesPath, err)
@@ -96,9 +155,9 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 	// Process @include: directives before local rules so local rules can override them
 	for _, includeInfo := range parsed.mainIncludes {
-		includedHot, includedCold, includedView, includeErr := m.resolveInclude(includeInfo, rulesDir, visited)
+		includedHot, includedCold, includedView, includeErr := m.resolveInclude(includeInfo, rulesDir, run)
 		if includeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve included ruleset '%s': %v\n", includeInfo.ImportIdentifier, includeErr)
+			Warnf("could not resolve included ruleset '%s': %v", includeInfo.ImportIdentifier, includeErr)
 			continue
 		}
 		hotRules = append(hotRules, includedHot...)
@@ -107,9 +166,9 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 	}
 
 	for _, includeInfo := range parsed.coldIncludes {
-		includedHot, includedCold, includedView, includeErr := m.resolveInclude(includeInfo, rulesDir, visited)
+		includedHot, includedCold, includedView, includeErr := m.resolveInclude(includeInfo, rulesDir, run)
 		if includeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve included ruleset '%s': %v\n", includeInfo.ImportIdentifier, includeErr)
+			Warnf("could not resolve included ruleset '%s': %v", includeInfo.ImportIdentifier, includeErr)
 			continue
 		}
 		// For cold includes, all nested rules go to cold
@@ -139,9 +198,14 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 	// Process concept directives
 	for _, concept := range parsed.conceptIDs {
-		resolvedFiles, err := m.resolveConcept(concept.ID, visited)
+		if run.visited[concept.ID] {
+			// Already resolved earlier in this run, so it contributes nothing
+			// here; that depends on the run, not this file.
+			run.tainted++
+		}
+		resolvedFiles, err := m.resolveConcept(concept.ID, run.visited)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve concept '%s': %v\n", concept.ID, err)
+			Warnf("could not resolve concept '%s': %v", concept.ID, err)
 			continue
 		}
 		// Attribute the synthesized rules to the @concept: line that produced
@@ -161,7 +225,7 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 			// Format: git::repoURL@version::ruleset
 			gitImportParts := strings.SplitN(strings.TrimPrefix(importInfo.ImportIdentifier, "git::"), "::", 2)
 			if len(gitImportParts) != 2 {
-				fmt.Fprintf(os.Stderr, "Warning: invalid git ruleset import format '%s'\n", importInfo.ImportIdentifier)
+				Warnf("invalid git ruleset import format '%s'", importInfo.ImportIdentifier)
 				continue
 			}
 			repoAndVersion, rulesetName := gitImportParts[0], gitImportParts[1]
@@ -197,14 +261,14 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 						EffectiveLineNum: importInfo.LineNum,
 					})
 				} else {
-					fmt.Fprintf(os.Stderr, "Warning: could not find named ruleset '%s' in repository %s: %v\n", rulesetName, repoURL, err)
+					Warnf("could not find named ruleset '%s' in repository %s: %v", rulesetName, repoURL, err)
 				}
 				continue
 			}
 
-			nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, visited, importInfo.LineNum)
+			nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, run, importInfo.LineNum)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not resolve ruleset '%s' from repository %s: %v\n", rulesetName, repoURL, err)
+				Warnf("could not resolve ruleset '%s' from repository %s: %v", rulesetName, repoURL, err)
 				continue
 			}
 
@@ -254,33 +318,33 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 		parts := strings.SplitN(importInfo.ImportIdentifier, "::", 2)
 		if len(parts) != 2 {
-			fmt.Fprintf(os.Stderr, "Warning: invalid ruleset import format '%s'\n", importInfo.ImportIdentifier)
+			Warnf("invalid ruleset import format '%s'", importInfo.ImportIdentifier)
 			continue
 		}
 		projectAlias, rulesetName := parts[0], parts[1]
 
 		projectPath, resolveErr := m.resolveProjectAlias(projectAlias)
 		if resolveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve project alias '%s' for rule import: %v\n", projectAlias, resolveErr)
+			Warnf("could not resolve project alias '%s' for rule import: %v", projectAlias, resolveErr)
 			continue
 		}
 
 		// Validate that the resolved project path is allowed
 		if allowed, reason := m.IsPathAllowed(projectPath); !allowed {
-			fmt.Fprintf(os.Stderr, "Warning: skipping import from '%s': %s\n", projectAlias, reason)
+			Warnf("skipping import from '%s': %s", projectAlias, reason)
 			continue
 		}
 
 		// Find the ruleset file (notebook presets, .cx.work/, .cx/)
 		rulesFilePath, err := m.FindRulesetFile(projectPath, rulesetName)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not find ruleset '%s' from project '%s': %v\n", rulesetName, projectAlias, err)
+			Warnf("could not find ruleset '%s' from project '%s': %v", rulesetName, projectAlias, err)
 			continue
 		}
 
-		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, visited, importInfo.LineNum)
+		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, run, importInfo.LineNum)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve ruleset '%s' from project '%s': %v\n", rulesetName, projectAlias, err)
+			Warnf("could not resolve ruleset '%s' from project '%s': %v", rulesetName, projectAlias, err)
 			continue
 		}
 
@@ -349,7 +413,7 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 			// Format: git::repoURL@version::ruleset
 			gitImportParts := strings.SplitN(strings.TrimPrefix(importInfo.ImportIdentifier, "git::"), "::", 2)
 			if len(gitImportParts) != 2 {
-				fmt.Fprintf(os.Stderr, "Warning: invalid git ruleset import format '%s'\n", importInfo.ImportIdentifier)
+				Warnf("invalid git ruleset import format '%s'", importInfo.ImportIdentifier)
 				continue
 			}
 			repoAndVersion, rulesetName := gitImportParts[0], gitImportParts[1]
@@ -382,14 +446,14 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 						EffectiveLineNum: importInfo.LineNum,
 					})
 				} else {
-					fmt.Fprintf(os.Stderr, "Warning: could not find named ruleset '%s' in repository %s: %v\n", rulesetName, repoURL, err)
+					Warnf("could not find named ruleset '%s' in repository %s: %v", rulesetName, repoURL, err)
 				}
 				continue
 			}
 
-			nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, visited, importInfo.LineNum)
+			nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, run, importInfo.LineNum)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not resolve ruleset '%s' from repository %s: %v\n", rulesetName, repoURL, err)
+				Warnf("could not resolve ruleset '%s' from repository %s: %v", rulesetName, repoURL, err)
 				continue
 			}
 
@@ -429,33 +493,33 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 		parts := strings.SplitN(importInfo.ImportIdentifier, "::", 2)
 		if len(parts) != 2 {
-			fmt.Fprintf(os.Stderr, "Warning: invalid ruleset import format '%s'\n", importInfo.ImportIdentifier)
+			Warnf("invalid ruleset import format '%s'", importInfo.ImportIdentifier)
 			continue
 		}
 		projectAlias, rulesetName := parts[0], parts[1]
 
 		projectPath, resolveErr := m.resolveProjectAlias(projectAlias)
 		if resolveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve project alias '%s' for rule import: %v\n", projectAlias, resolveErr)
+			Warnf("could not resolve project alias '%s' for rule import: %v", projectAlias, resolveErr)
 			continue
 		}
 
 		// Validate that the resolved project path is allowed
 		if allowed, reason := m.IsPathAllowed(projectPath); !allowed {
-			fmt.Fprintf(os.Stderr, "Warning: skipping import from '%s': %s\n", projectAlias, reason)
+			Warnf("skipping import from '%s': %s", projectAlias, reason)
 			continue
 		}
 
 		// Find the ruleset file (notebook presets, .cx.work/, .cx/)
 		rulesFilePath, err := m.FindRulesetFile(projectPath, rulesetName)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not find ruleset '%s' from project '%s': %v\n", rulesetName, projectAlias, err)
+			Warnf("could not find ruleset '%s' from project '%s': %v", rulesetName, projectAlias, err)
 			continue
 		}
 
-		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, visited, importInfo.LineNum)
+		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(rulesFilePath, run, importInfo.LineNum)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not resolve ruleset '%s' from project '%s': %v\n", rulesetName, projectAlias, err)
+			Warnf("could not resolve ruleset '%s' from project '%s': %v", rulesetName, projectAlias, err)
 			continue
 		}
 
@@ -503,7 +567,8 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 	}
 
 	// Process hot defaults
-	for _, defaultPath := range mainDefaults {
+	for _, def := range mainDefaults {
+		defaultPath := def.Path
 		resolvedPath := defaultPath
 		if !filepath.IsAbs(resolvedPath) {
 			resolvedPath = filepath.Join(rulesDir, resolvedPath)
@@ -517,20 +582,20 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 		// Validate that the default path is within an allowed workspace
 		if allowed, reason := m.IsPathAllowed(realPath); !allowed {
-			fmt.Fprintf(os.Stderr, "Warning: skipping @default for '%s': %s\n", defaultPath, reason)
+			Warnf("skipping @default for '%s': %s", defaultPath, reason)
 			continue
 		}
 
 		// Load the config from the grove config file in that directory
 		configFile, err := config.FindConfigFile(realPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: no grove config found at %s for @default path %s\n", realPath, defaultPath)
+			Warnf("no grove config found at %s for @default path %s", realPath, defaultPath)
 			continue
 		}
 
 		cfg, err := config.Load(configFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not load config for @default path %s (file: %s): %v\n", defaultPath, configFile, err)
+			Warnf("could not load config for @default path %s (file: %s): %v", defaultPath, configFile, err)
 			continue
 		}
 
@@ -546,19 +611,19 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 			if resolved, findErr := m.FindRulesetFile(realPath, defaultRules); findErr == nil {
 				defaultRulesFile = resolved
 			} else {
-				fmt.Fprintf(os.Stderr, "Warning: could not find default_rules preset '%s' for @default path %s\n", defaultRules, defaultPath)
+				Warnf("could not find default_rules preset '%s' for @default path %s", defaultRules, defaultPath)
 				continue
 			}
 		} else if defaultRulesPath != "" {
 			defaultRulesFile = filepath.Join(realPath, defaultRulesPath)
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: no default_rules or default_rules_path found for @default path %s\n", defaultPath)
+			Warnf("no default_rules or default_rules_path found for @default path %s", defaultPath)
 			continue
 		}
 
 		// Recursively resolve patterns from the default rules file
 		// ALL patterns from the default (hot and cold) are added to the current HOT context.
-		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(defaultRulesFile, visited, 0)
+		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(defaultRulesFile, run, 0)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -588,6 +653,10 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 				}
 			}
 		}
+		// The nested expansion runs as a root (line 0) so presets are
+		// re-rooted; attribute its rules to the @default line afterwards.
+		attributeToLine(nestedHot, def.LineNum)
+		attributeToLine(nestedCold, def.LineNum)
 		hotRules = append(hotRules, nestedHot...)
 		hotRules = append(hotRules, nestedCold...)
 
@@ -607,7 +676,8 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 	}
 
 	// Process cold defaults
-	for _, defaultPath := range coldDefaults {
+	for _, def := range coldDefaults {
+		defaultPath := def.Path
 		resolvedPath := defaultPath
 		if !filepath.IsAbs(resolvedPath) {
 			resolvedPath = filepath.Join(rulesDir, resolvedPath)
@@ -621,20 +691,20 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 
 		// Validate that the default path is within an allowed workspace
 		if allowed, reason := m.IsPathAllowed(realPath); !allowed {
-			fmt.Fprintf(os.Stderr, "Warning: skipping @default for '%s': %s\n", defaultPath, reason)
+			Warnf("skipping @default for '%s': %s", defaultPath, reason)
 			continue
 		}
 
 		// Load the config from the grove config file in that directory
 		configFile, err := config.FindConfigFile(realPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: no grove config found at %s for @default path %s\n", realPath, defaultPath)
+			Warnf("no grove config found at %s for @default path %s", realPath, defaultPath)
 			continue
 		}
 
 		cfg, err := config.Load(configFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not load config for @default path %s (file: %s): %v\n", defaultPath, configFile, err)
+			Warnf("could not load config for @default path %s (file: %s): %v", defaultPath, configFile, err)
 			continue
 		}
 
@@ -650,19 +720,19 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 			if resolved, findErr := m.FindRulesetFile(realPath, defaultRules); findErr == nil {
 				defaultRulesFile = resolved
 			} else {
-				fmt.Fprintf(os.Stderr, "Warning: could not find default_rules preset '%s' for @default path %s\n", defaultRules, defaultPath)
+				Warnf("could not find default_rules preset '%s' for @default path %s", defaultRules, defaultPath)
 				continue
 			}
 		} else if defaultRulesPath != "" {
 			defaultRulesFile = filepath.Join(realPath, defaultRulesPath)
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: no default_rules or default_rules_path found for @default path %s\n", defaultPath)
+			Warnf("no default_rules or default_rules_path found for @default path %s", defaultPath)
 			continue
 		}
 
 		// Recursively resolve patterns from the default rules file
 		// ALL patterns from the default are added to the current COLD context.
-		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(defaultRulesFile, visited, 0)
+		nestedHot, nestedCold, nestedView, nestedTree, err := m.expandAllRules(defaultRulesFile, run, 0)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -691,6 +761,8 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 				}
 			}
 		}
+		attributeToLine(nestedHot, def.LineNum)
+		attributeToLine(nestedCold, def.LineNum)
 		coldRules = append(coldRules, nestedHot...)
 		coldRules = append(coldRules, nestedCold...)
 
@@ -713,20 +785,23 @@ func (m *Manager) expandAllRules(rulesPath string, visited map[string]bool, impo
 	// parent import's line number so the top-level AST resolver sees a
 	// consistent EffectiveLineNum for the entire import.
 	if importLineNum > 0 {
-		for i := range hotRules {
-			hotRules[i].EffectiveLineNum = importLineNum
-		}
-		for i := range coldRules {
-			coldRules[i].EffectiveLineNum = importLineNum
-		}
+		attributeToLine(hotRules, importLineNum)
+		attributeToLine(coldRules, importLineNum)
 	}
 
 	return hotRules, coldRules, viewPaths, treePaths, nil
 }
 
+// attributeToLine sets the EffectiveLineNum of every rule to lineNum.
+func attributeToLine(rules []RuleInfo, lineNum int) {
+	for i := range rules {
+		rules[i].EffectiveLineNum = lineNum
+	}
+}
+
 // resolveInclude resolves a single @include: directive to its constituent rules.
 // It locates the named ruleset file and recursively expands it.
-func (m *Manager) resolveInclude(includeInfo ImportInfo, rulesDir string, visited map[string]bool) (hotRules, coldRules []RuleInfo, viewPaths []string, err error) {
+func (m *Manager) resolveInclude(includeInfo ImportInfo, rulesDir string, run *expansionRun) (hotRules, coldRules []RuleInfo, viewPaths []string, err error) {
 	includeName := includeInfo.ImportIdentifier
 	var rulesFilePath string
 
@@ -746,7 +821,7 @@ func (m *Manager) resolveInclude(includeInfo ImportInfo, rulesDir string, visite
 		rulesFilePath = resolvedPath
 	}
 
-	nestedHot, nestedCold, nestedView, _, err := m.expandAllRules(rulesFilePath, visited, includeInfo.LineNum)
+	nestedHot, nestedCold, nestedView, _, err := m.expandAllRules(rulesFilePath, run, includeInfo.LineNum)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -777,49 +852,54 @@ func (m *Manager) ResolveFilesFromRules() ([]string, error) {
 // ResolveFilesAndTreesFromRules dynamically resolves the list of files and tree paths from the active rules file
 func (m *Manager) ResolveFilesAndTreesFromRules() ([]string, []string, error) {
 	defer profiling.Start("context.ResolveFilesAndTreesFromRules").Stop()
-	// Load the active rules content (respects state-based rules)
-	rulesContent, activeRulesFile, err := m.LoadRulesContent()
+	s, err := m.NewResolutionSession()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load rules: %w", err)
-	}
-	if rulesContent == nil || activeRulesFile == "" {
-		// No active or default rules found
-		return []string{}, nil, nil
+		return nil, nil, err
 	}
+	return s.Hot, s.Trees, nil
+}
 
-	// Resolve all patterns recursively from the active rules file
-	hotRules, coldRules, _, treePaths, err := m.expandAllRules(activeRulesFile, make(map[string]bool), 0)
+// resolveHotCold resolves hot and cold rules to files. Cold wins: a file
+// matched by both sections is only returned as cold.
+func (m *Manager) resolveHotCold(hotRules, coldRules []RuleInfo) (hotFiles, coldFiles []string, err error) {
+	hotFiles, coldFiles, _, err = m.resolveSections(hotRules, coldRules, newStatCache())
+	return hotFiles, coldFiles, err
+}
+
+// resolveSections is resolveHotCold that also returns the files removed by
+// an exclusion rule and not included by the cold section after it, which
+// is what resolving hot and cold rules as one list would exclude.
+func (m *Manager) resolveSections(hotRules, coldRules []RuleInfo, stats *statCache) (hotFiles, coldFiles, excluded []string, err error) {
+	hotFiles, hotExcluded, err := m.resolveRulesViaAST(hotRules, stats)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to resolve patterns: %w", err)
+		return nil, nil, nil, fmt.Errorf("error resolving hot context files: %w", err)
+	}
+	if len(coldRules) == 0 {
+		return hotFiles, nil, hotExcluded, nil
 	}
 
-	hotFiles, err := m.resolveFilesViaAST(hotRules)
+	coldFiles, coldExcluded, err := m.resolveRulesViaAST(coldRules, stats)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error resolving hot context files: %w", err)
+		return nil, nil, nil, fmt.Errorf("error resolving cold context files: %w", err)
 	}
 
-	if len(coldRules) > 0 {
-		coldFiles, err := m.resolveFilesViaAST(coldRules)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error resolving cold context files: %w", err)
-		}
+	coldFilesMap := make(map[string]bool)
+	for _, file := range coldFiles {
+		coldFilesMap[file] = true
+	}
 
-		coldFilesMap := make(map[string]bool)
-		for _, file := range coldFiles {
-			coldFilesMap[file] = true
+	var finalHotFiles []string
+	for _, file := range hotFiles {
+		if !coldFilesMap[file] {
+			finalHotFiles = append(finalHotFiles, file)
 		}
-
-		var finalHotFiles []string
-		for _, file := range hotFiles {
-			if !coldFilesMap[file] {
-				finalHotFiles = append(finalHotFiles, file)
-			}
+	}
+	for _, file := range hotExcluded {
+		if !coldFilesMap[file] {
+			coldExcluded = append(coldExcluded, file)
 		}
-
-		return finalHotFiles, deduplicateStrings(treePaths), nil
 	}
-
-	return hotFiles, deduplicateStrings(treePaths), nil
+	return finalHotFiles, coldFiles, deduplicateStrings(coldExcluded), nil
 }
 
 // deduplicateStrings returns a new slice with duplicate entries removed, preserving order.
@@ -838,6 +918,53 @@ func deduplicateStrings(items []string) []string {
 	return result
 }
 
+// dedupeRules canonicalizes rule patterns and drops every rule that a later
+// identical rule (same pattern, polarity and directives) makes redundant.
+// Recursive @default: chains and overlapping imports can expand to thousands
+// of copies of the same pattern, each of which would otherwise be walked and
+// matched. Keeping the last copy preserves last-match-wins attribution: any
+// file an earlier copy matches, the later one matches too.
+func dedupeRules(rules []RuleInfo) []RuleInfo {
+	last := make(map[string]int, len(rules))
+	keys := make([]string, len(rules))
+	for i := range rules {
+		rules[i].Pattern = canonicalPattern(rules[i].Pattern)
+		var key strings.Builder
+		if rules[i].IsExclude {
+			key.WriteByte('!')
+		}
+		key.WriteString(rules[i].Pattern)
+		for _, d := range rules[i].Directives {
+			fmt.Fprintf(&key, "\x00%s\x00%s", d.Name, d.Query)
+		}
+		keys[i] = key.String()
+		last[keys[i]] = i
+	}
+	if len(last) == len(rules) {
+		return rules
+	}
+	out := make([]RuleInfo, 0, len(last))
+	for i, r := range rules {
+		if last[keys[i]] == i {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// canonicalPattern normalizes spellings of the same pattern ("./a//b.go",
+// "a/./b.go", "a/b.go") to one form. Trailing slashes are significant
+// (directory patterns) and are left alone.
+func canonicalPattern(pattern string) string {
+	for strings.HasPrefix(pattern, "./") {
+		pattern = pattern[2:]
+	}
+	if pattern == "" || strings.HasSuffix(pattern, "/") {
+		return pattern
+	}
+	return filepath.Clean(pattern)
+}
+
 // ResolveFilesFromCustomRulesFile resolves both hot and cold files from a custom rules file path.
 func (m *Manager) ResolveFilesFromCustomRulesFile(rulesFilePath string) (hotFiles, coldFiles []string, err error) {
 	// Resolve relative paths against workDir, not process CWD.
@@ -855,35 +982,16 @@ func (m *Manager) ResolveFilesFromCustomRulesFile(rulesFilePath string) (hotFile
 	}
 
 	// Resolve all patterns recursively from the custom rules file
-	hotRules, coldRules, _, _, err := m.expandAllRules(absRulesFilePath, make(map[string]bool), 0)
+	hotRules, coldRules, _, _, err := m.expandAllRules(absRulesFilePath, newExpansionRun(), 0)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to resolve patterns from rules file: %w", err)
 	}
 
-	hotFiles, err = m.resolveFilesViaAST(hotRules)
+	hotFiles, coldFiles, err = m.resolveHotCold(hotRules, coldRules)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error resolving hot context files: %w", err)
-	}
-
-	if len(coldRules) > 0 {
-		coldFiles, err = m.resolveFilesViaAST(coldRules)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error resolving cold context files: %w", err)
-		}
-
-		coldFilesMap := make(map[string]bool)
-		for _, file := range coldFiles {
-			coldFilesMap[file] = true
-		}
-
-		var finalHotFiles []string
-		for _, file := range hotFiles {
-			if !coldFilesMap[file] {
-				finalHotFiles = append(finalHotFiles, file)
-			}
-		}
-		hotFiles = finalHotFiles
+		return nil, nil, err
 	}
+	hotFiles, coldFiles = m.applyFileDirectives(hotFiles, coldFiles)
 
 	return hotFiles, coldFiles, nil
 }
@@ -899,7 +1007,7 @@ func (m *Manager) Intersects(rulesPath string, changedFiles []string) (bool, err
 		return false, fmt.Errorf("rules file not found: %s", rulesPath)
 	}
 
-	hotRules, coldRules, _, _, err := m.expandAllRules(rulesPath, make(map[string]bool), 0)
+	hotRules, coldRules, _, _, err := m.expandAllRules(rulesPath, newExpansionRun(), 0)
 	if err != nil {
 		return false, fmt.Errorf("failed to expand rules: %w", err)
 	}
@@ -930,65 +1038,41 @@ func (m *Manager) Intersects(rulesPath string, changedFiles []string) (bool, err
 // ResolveColdContextFiles resolves the list of files from the "cold" section of a rules file.
 func (m *Manager) ResolveColdContextFiles() ([]string, error) {
 	defer profiling.Start("context.ResolveColdContextFiles").Stop()
-	// Load the active rules content (respects state-based rules)
-	rulesContent, activeRulesFile, err := m.LoadRulesContent()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load rules: %w", err)
-	}
-	if rulesContent == nil || activeRulesFile == "" {
-		// No active or default rules found
-		return []string{}, nil
-	}
-
-	// Resolve all patterns recursively from the active rules file
-	_, coldRules, _, _, err := m.expandAllRules(activeRulesFile, make(map[string]bool), 0)
+	s, err := m.NewResolutionSession()
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve patterns for cold context: %w", err)
+		return nil, err
 	}
-
-	coldFiles, err := m.resolveFilesViaAST(coldRules)
-	if err != nil {
-		return nil, fmt.Errorf("error resolving cold context files: %w", err)
-	}
-
-	return coldFiles, nil
+	return s.Cold, nil
 }
 
-// preProcessPatterns transforms plain directory patterns into recursive globs.
-func (m *Manager) preProcessPatterns(patterns []string) []string {
-	// Pre-process patterns to transform directory patterns into recursive globs
-	processedPatterns := make([]string, 0, len(patterns))
-	for _, pattern := range patterns {
-		isExclude := strings.HasPrefix(pattern, "!")
-		cleanPattern := pattern
-		if isExclude {
-			cleanPattern = strings.TrimPrefix(pattern, "!")
-		}
-
-		// Check if pattern contains glob characters
-		hasGlob := strings.Contains(cleanPattern, "*") || strings.Contains(cleanPattern, "?")
-
-		// Only transform plain directory patterns for INCLUSION patterns
-		// Exclusion patterns like !tests should remain as-is for gitignore compatibility
-		if !hasGlob && !isExclude {
-			// Resolve the path to check if it exists and is a directory
-			checkPath := cleanPattern
-			if !filepath.IsAbs(cleanPattern) {
-				checkPath = filepath.Join(m.rulesBaseDir, cleanPattern)
-			}
-			checkPath = filepath.Clean(checkPath)
+// walkPatterns returns the patterns classification derives its walk roots
+// from: every hot and cold rule, with directives encoded and plain directory
+// inclusions turned into recursive globs. It runs once over the expanded
+// rules, stat'ing through stats.
+func (m *Manager) walkPatterns(rules []RuleInfo, stats *statCache) []string {
+	patterns := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		pattern := encodeDirectives(rule.Pattern, rule.Directives)
+		if rule.IsExclude {
+			// Exclusion patterns like !tests stay as-is for gitignore compatibility
+			patterns = append(patterns, "!"+pattern)
+			continue
+		}
 
-			if info, err := os.Stat(checkPath); err == nil && info.IsDir() {
-				// Transform directory pattern to recursive glob
-				processedPatterns = append(processedPatterns, cleanPattern+"/**")
+		// Only plain (non-glob) inclusion patterns can name a directory
+		if !strings.Contains(pattern, "*") && !strings.Contains(pattern, "?") {
+			checkPath := pattern
+			if !filepath.IsAbs(pattern) {
+				checkPath = filepath.Join(m.rulesBaseDir, pattern)
+			}
+			if info, err := stats.stat(filepath.Clean(checkPath)); err == nil && info.IsDir() {
+				patterns = append(patterns, pattern+"/**")
 				continue
 			}
 		}
-
-		// Keep pattern as-is
-		processedPatterns = append(processedPatterns, pattern)
+		patterns = append(patterns, pattern)
 	}
-	return processedPatterns
+	return patterns
 }
 
 // decodeDirectives extracts directives information from an encoded pattern
@@ -1007,32 +1091,16 @@ func decodeDirectives(pattern string) (string, []SearchDirective, bool) {
 }
 
 // matchDirective checks if a single file matches a directive filter.
-// For "find", it checks if the path contains the query string.
-// For "grep", it reads the file and checks if the content matches the query as a regex (or literal fallback).
+// For "find", it matches the path against the query's terms (see matchFindQuery).
+// For "grep", it checks if the content matches the query as a regex (or literal fallback);
+// "grep-re" is strictly a regex.
 func (m *Manager) matchDirective(file, directive, query string) bool {
 	// Handle inverted directives (@find!:, @grep!:) by stripping the ! and inverting result
 	if strings.HasSuffix(directive, "!") {
 		return !m.matchDirective(file, strings.TrimSuffix(directive, "!"), query)
 	}
 	if directive == "find" {
-		// @find: filter by filename/path using substring, glob, or regex
-		// 1. Substring match (original behavior)
-		if strings.Contains(file, query) {
-			return true
-		}
-		// 2. Basename glob match
-		if globMatch, _ := filepath.Match(query, filepath.Base(file)); globMatch {
-			return true
-		}
-		// 3. Full path/recursive glob match
-		if matchDoubleStarPattern(query, file) {
-			return true
-		}
-		// 4. Regex match
-		if re, err := regexp.Compile(query); err == nil && re.MatchString(filepath.ToSlash(file)) {
-			return true
-		}
-		return false
+		return matchFindQuery(file, query)
 	}
 	if directive == "changed" {
 		// @changed: filter files to only those in the git changed set
@@ -1047,28 +1115,9 @@ func (m *Manager) matchDirective(file, directive, query string) bool {
 		relPath = filepath.ToSlash(relPath)
 		return changedMap[relPath]
 	}
-	if directive == "grep" || directive == "grep-i" {
-		filePath := file
-		if !filepath.IsAbs(file) {
-			filePath = filepath.Join(m.rulesBaseDir, file)
-		}
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			return false
-		}
-		caseInsensitive := directive == "grep-i"
-		if caseInsensitive {
-			compiled, err := regexp.Compile("(?i)" + query)
-			if err != nil {
-				return bytes.Contains(bytes.ToLower(content), []byte(strings.ToLower(query)))
-			}
-			return compiled.Match(content)
-		}
-		compiled, err := regexp.Compile(query)
-		if err != nil {
-			return strings.Contains(string(content), query)
-		}
-		return compiled.Match(content)
+	if isGrepDirective(directive) {
+		// Streaming, binary-aware scan with a cached matcher (see grep.go).
+		return m.matchDirectives(file, []SearchDirective{{Name: directive, Query: query}})
 	}
 	if directive == "recent" {
 		// @recent: filter by modification time
@@ -1084,6 +1133,141 @@ func (m *Manager) matchDirective(file, directive, query string) bool {
 		stat, err := os.Stat(filePath)
 		return err == nil && stat.ModTime().After(cutoff)
 	}
+	if directive == directiveText || directive == directiveBinary {
+		// Classification modifiers, applied by the walk (see binary.go)
+		return true
+	}
+	if metadataDirectives[directive] {
+		// @newer-than:, @size:, @executable, ... (see metafilter.go)
+		filePath := file
+		if !filepath.IsAbs(file) {
+			filePath = filepath.Join(m.rulesBaseDir, file)
+		}
+		info, err := os.Stat(filePath)
+		return err == nil && matchMetadata(info, directive, query, time.Now())
+	}
+	return false
+}
+
+// matchFindQuery reports whether file satisfies an @find: query. A query is
+// one or more comma-separated terms, any of which may match:
+//
+//	@find: "api"                   substring of the path
+//	@find: "*_handler.go"          glob against the basename
+//	@find: "handlers/*.go"         glob against trailing path segments
+//	@find: "**/handlers/*.{go,ts}" recursive glob (braces expand)
+//	@find: "name:user"             basename only
+//	@find: "path:api/*/user.go"    path only, no regex fallback
+//	@find: "api, v1"               either term
+//
+// Terms that are valid regular expressions are also tried as one, as before.
+func matchFindQuery(file, query string) bool {
+	for _, term := range findTerms(query) {
+		if matchFindTerm(filepath.ToSlash(file), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// findTerms splits an @find: query at top-level commas; commas inside {...}
+// and [...] belong to a glob and are left alone.
+func findTerms(query string) []string {
+	var terms []string
+	depth, start := 0, 0
+	add := func(t string) {
+		if t = strings.TrimSpace(t); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	for i, r := range query {
+		switch r {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				add(query[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(query[start:])
+	return terms
+}
+
+// matchFindTerm matches one @find: term against a slash-separated path.
+func matchFindTerm(file, term string) bool {
+	base := path.Base(file)
+	if name, ok := strings.CutPrefix(term, "name:"); ok {
+		if isFindGlob(name) {
+			return matchFindGlob(name, func(g string) bool {
+				ok, _ := path.Match(g, base)
+				return ok
+			})
+		}
+		return strings.Contains(base, name)
+	}
+	if p, ok := strings.CutPrefix(term, "path:"); ok {
+		if isFindGlob(p) {
+			return matchFindGlob(p, func(g string) bool { return matchPathSegments(g, file) })
+		}
+		return strings.Contains(file, p)
+	}
+
+	// Substring match (original behavior)
+	if strings.Contains(file, term) {
+		return true
+	}
+	if isFindGlob(term) && matchFindGlob(term, func(g string) bool {
+		if !strings.Contains(g, "/") {
+			ok, _ := path.Match(g, base)
+			return ok
+		}
+		return matchPathSegments(g, file)
+	}) {
+		return true
+	}
+	// Regex match
+	if re, err := regexp.Compile(term); err == nil && re.MatchString(file) {
+		return true
+	}
+	return false
+}
+
+func isFindGlob(term string) bool {
+	return strings.ContainsAny(term, "*?[{")
+}
+
+// matchFindGlob reports whether match accepts any brace expansion of glob.
+func matchFindGlob(glob string, match func(string) bool) bool {
+	for _, g := range ExpandBraces(glob) {
+		if match(g) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments matches a path glob against file. A glob with ** is
+// matched recursively; otherwise it must match the whole path or a run of
+// trailing segments ("handlers/*.go" matches "pkg/api/handlers/user.go").
+func matchPathSegments(glob, file string) bool {
+	if strings.Contains(glob, "**") {
+		if !strings.HasPrefix(glob, "/") && !strings.HasPrefix(glob, "**") {
+			glob = "**/" + glob
+		}
+		return matchDoubleStarPattern(glob, file)
+	}
+	segments := strings.Split(file, "/")
+	for i := range segments {
+		if ok, _ := path.Match(glob, strings.Join(segments[i:], "/")); ok {
+			return true
+		}
+	}
 	return false
 }
 
@@ -1250,9 +1434,10 @@ func isBinaryFile(path string) bool {
 	}
 
 	// Only check content for files without extensions (like Go binaries)
-	file, err := os.Open(path)
+	file, err := openRegular(path)
 	if err != nil {
-		return false
+		// Special files are never text context
+		return errors.Is(err, errSpecialFile)
 	}
 	defer file.Close()
 
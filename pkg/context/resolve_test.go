@@ -1,6 +1,9 @@
 package context
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -99,3 +102,93 @@ func TestIsRelativeExternalPath(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchFindQuery(t *testing.T) {
+	tests := []struct {
+		file, query string
+		want        bool
+	}{
+		{"pkg/api/user.go", "api", true},
+		{"pkg/api/user.go", "*_handler.go", false},
+		{"pkg/api/user_handler.go", "*_handler.go", true},
+		{"pkg/api/handlers/user.go", "handlers/*.go", true},
+		{"pkg/api/handlers/v1/user.go", "handlers/*.go", false},
+		{"/abs/pkg/api/handlers/user.ts", "**/handlers/*.{go,ts}", true},
+		{"pkg/api/handlers/user.rs", "**/handlers/*.{go,ts}", false},
+		{"pkg/user/model.go", "name:user", false},
+		{"pkg/models/user.go", "name:user", true},
+		{"pkg/models/user.go", "name:*.{go,rs}", true},
+		{"pkg/api/v2/user.go", "path:api/*/user.go", true},
+		{"pkg/api/user.go", "path:^pkg", false},
+		{"pkg/api/user.go", "^pkg/", true},
+		{"pkg/web/v1.go", "api, v1", true},
+		{"pkg/web/v2.go", "api, v1", false},
+		{"pkg/web/a.go", "*.{ts,go}", true},
+	}
+	for _, tt := range tests {
+		if got := matchFindQuery(tt.file, tt.query); got != tt.want {
+			t.Errorf("matchFindQuery(%q, %q) = %v, want %v", tt.file, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeRules(t *testing.T) {
+	rules := []RuleInfo{
+		{Pattern: "./pkg//a.go", EffectiveLineNum: 1},
+		{Pattern: "docs/", EffectiveLineNum: 2},
+		{Pattern: "pkg/a.go", IsExclude: true, EffectiveLineNum: 3},
+		{Pattern: "pkg/./a.go", EffectiveLineNum: 4},
+		{Pattern: "**/*.go", Directives: []SearchDirective{{Name: "grep", Query: "x"}}, EffectiveLineNum: 5},
+		{Pattern: "**/*.go", EffectiveLineNum: 6},
+		{Pattern: "docs/", EffectiveLineNum: 7},
+	}
+	got := dedupeRules(rules)
+	want := []RuleInfo{
+		{Pattern: "pkg/a.go", IsExclude: true, EffectiveLineNum: 3},
+		{Pattern: "pkg/a.go", EffectiveLineNum: 4},
+		{Pattern: "**/*.go", Directives: []SearchDirective{{Name: "grep", Query: "x"}}, EffectiveLineNum: 5},
+		{Pattern: "**/*.go", EffectiveLineNum: 6},
+		{Pattern: "docs/", EffectiveLineNum: 7},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("dedupeRules = %+v", got)
+	}
+	for i := range want {
+		if got[i].Pattern != want[i].Pattern || got[i].IsExclude != want[i].IsExclude ||
+			got[i].EffectiveLineNum != want[i].EffectiveLineNum || len(got[i].Directives) != len(want[i].Directives) {
+			t.Errorf("rule %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWalkPatterns(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	rules := []RuleInfo{
+		{Pattern: "pkg"},
+		{Pattern: "pkg", IsExclude: true},
+		{Pattern: "missing"},
+		{Pattern: "pkg/*.go"},
+	}
+
+	stats := newStatCache()
+	got := m.walkPatterns(rules, stats)
+	want := []string{"pkg/**", "!pkg", "missing", "pkg/*.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("walkPatterns = %v, want %v", got, want)
+	}
+
+	// Answers come from the cache for the rest of the invocation.
+	if err := os.Remove(filepath.Join(dir, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.walkPatterns(rules, stats); !reflect.DeepEqual(got, want) {
+		t.Errorf("walkPatterns with shared cache = %v, want %v", got, want)
+	}
+	if got := m.walkPatterns(rules, newStatCache())[0]; got != "pkg" {
+		t.Errorf("fresh cache should re-stat, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // Resolve emits FileAttributions for files matching this node. The Match
@@ -63,6 +64,9 @@ func (n *ImportNode) Resolve(ctx ResolutionContext) []FileAttribution {
 func (n *CommandNode) Resolve(ctx ResolutionContext) []FileAttribution {
 	files, err := ctx.ExecCommand(n.Command)
 	if err != nil {
+		if r, ok := ctx.(commandFailureRecorder); ok {
+			r.RecordCommandFailure(n.LineNum, n.Command, err)
+		}
 		return nil
 	}
 	var attrs []FileAttribution
@@ -76,28 +80,103 @@ func (n *FilterNode) Resolve(ctx ResolutionContext) []FileAttribution {
 	if n.Child == nil {
 		return nil
 	}
-	raw := n.Child.Resolve(ctx)
-	out := make([]FileAttribution, 0, len(raw))
-	for _, attr := range raw {
+	childCtx := ctx
+	if bi, ok := ctx.(binaryIncluder); ok && hasDirective(n.Directives, directiveText) {
+		childCtx = bi.withBinaryFiles()
+	}
+	raw := n.Child.Resolve(childCtx)
+	keep := make([]bool, len(raw))
+	check := func(i int) {
+		attr := raw[i]
 		if attr.IsExclude {
-			out = append(out, attr)
-			continue
+			keep[i] = true
+			return
+		}
+		if dm, ok := ctx.(directiveSetMatcher); ok {
+			keep[i] = dm.MatchDirectives(attr.Path, n.Directives)
+			return
 		}
-		ok := true
-		for _, d := range n.Directives {
-			if !ctx.MatchDirective(attr.Path, d.Name, d.Query) {
-				ok = false
-				break
+		clauses := directiveClauses(n.Directives)
+		keep[i] = len(clauses) == 0
+		for _, clause := range clauses {
+			if clauseHolds(ctx, attr.Path, clause) {
+				keep[i] = true
+				return
 			}
 		}
-		if ok {
+	}
+
+	// Content directives are I/O bound; fan the candidates out over a
+	// bounded worker pool (see concurrency.go). Results land by index, so
+	// output order is unchanged.
+	workers := workerLimit(0)
+	if gl, ok := ctx.(grepLimiter); ok {
+		workers = gl.grepWorkers()
+	}
+	if len(raw) < 2*workers {
+		for i := range raw {
+			check(i)
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					check(i)
+				}
+			}()
+		}
+		for i := range raw {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	out := make([]FileAttribution, 0, len(raw))
+	for i, attr := range raw {
+		if !keep[i] {
+			continue
+		}
+		if !attr.IsExclude {
 			attr.EffectiveLineNum = n.LineNum
-			out = append(out, attr)
 		}
+		out = append(out, attr)
 	}
 	return out
 }
 
+func clauseHolds(ctx ResolutionContext, file string, clause []SearchDirective) bool {
+	for _, d := range clause {
+		if !ctx.MatchDirective(file, d.Name, d.Query) {
+			return false
+		}
+	}
+	return true
+}
+
+// directiveSetMatcher is implemented by resolution contexts that can check
+// all of a filter's directives in one pass (sharing a single file read).
+// Contexts without it fall back to MatchDirective per directive.
+type directiveSetMatcher interface {
+	MatchDirectives(file string, directives []SearchDirective) bool
+}
+
+// grepLimiter is implemented by resolution contexts with a configured
+// content directive pool size.
+type grepLimiter interface {
+	grepWorkers() int
+}
+
+// commandFailureRecorder is implemented by resolution contexts that keep
+// track of failed `@cmd:` rules (see cmdfailures.go).
+type commandFailureRecorder interface {
+	RecordCommandFailure(lineNum int, command string, err error)
+}
+
 // walkAndEmit iterates the context's walk root and emits a FileAttribution
 // for any file whose path matches `pattern` per ctx.MatchPattern semantics.
 // Uses the same float-vs-anchored matching the legacy classify path uses.
@@ -109,43 +188,58 @@ func walkAndEmit(ctx ResolutionContext, pattern string, line int, excluded bool)
 		root = walkRootForPattern(pattern, ctx.BaseDir())
 	}
 
-	var attrs []FileAttribution
-	_ = ctx.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d == nil {
-			return nil
-		}
-		if d.IsDir() {
-			// Skip well-known junk directories reached via implicit
-			// directory-glob expansion, unless this pattern names the
-			// directory explicitly (an intentional re-include).
-			if isJunkDir(d.Name()) && !patternReferencesDir(pattern, d.Name()) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		matchPath := relForMatch(path, ctx.BaseDir())
-		if filepath.IsAbs(pattern) {
-			matchPath = filepath.ToSlash(path)
-		} else if IsRelativeExternalPath(pattern) {
-			if rel, err2 := filepath.Rel(ctx.BaseDir(), path); err2 == nil {
-				matchPath = filepath.ToSlash(rel)
+	collect := func(walk func(string, fs.WalkDirFunc) error) []string {
+		var paths []string
+		_ = walk(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d == nil {
+				return nil
 			}
-		} else if floating {
-			isExternal := strings.HasPrefix(matchPath, "..")
-			if isExternal && !excluded {
+			if d.IsDir() {
+				// Skip well-known junk directories reached via implicit
+				// directory-glob expansion, unless this pattern names the
+				// directory explicitly (an intentional re-include).
+				if isJunkDir(d.Name()) && !patternReferencesDir(pattern, d.Name()) {
+					return filepath.SkipDir
+				}
 				return nil
 			}
-		}
-		if !ctx.MatchPattern(pattern, matchPath) {
+			matchPath := relForMatch(path, ctx.BaseDir())
+			if filepath.IsAbs(pattern) {
+				matchPath = filepath.ToSlash(path)
+			} else if IsRelativeExternalPath(pattern) {
+				if rel, err2 := filepath.Rel(ctx.BaseDir(), path); err2 == nil {
+					matchPath = filepath.ToSlash(rel)
+				}
+			} else if floating {
+				isExternal := strings.HasPrefix(matchPath, "..")
+				if isExternal && !excluded {
+					return nil
+				}
+			}
+			if ctx.MatchPattern(pattern, matchPath) {
+				paths = append(paths, path)
+			}
 			return nil
-		}
+		})
+		return paths
+	}
+
+	// Contexts with a walk index answer unchanged patterns from it; see
+	// walkindex.go.
+	var paths []string
+	if iw, ok := ctx.(indexedWalker); ok {
+		paths = iw.indexedMatches(root, pattern, excluded, collect)
+	} else {
+		paths = collect(ctx.WalkDir)
+	}
+	var attrs []FileAttribution
+	for _, path := range paths {
 		attrs = append(attrs, FileAttribution{
 			Path:             path,
 			EffectiveLineNum: line,
 			IsExclude:        excluded,
 		})
-		return nil
-	})
+	}
 	return attrs
 }
 
@@ -172,10 +266,57 @@ func walkRootForPattern(pattern, base string) string {
 	return candidate
 }
 
+// walkRoots returns the distinct roots the pattern nodes among nodes walk,
+// in rule order. Imports and commands resolve their paths while they run
+// and are left out, as are patterns naming a junk directory, which bypass
+// the walk index.
+func walkRoots(nodes []RuleNode, base string) []string {
+	var roots []string
+	seen := make(map[string]bool)
+	add := func(pattern string) {
+		if referencesJunkDir(pattern) {
+			return
+		}
+		root := base
+		if filepath.IsAbs(pattern) || IsRelativeExternalPath(pattern) {
+			root = walkRootForPattern(pattern, base)
+		}
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	var visit func(RuleNode)
+	visit = func(n RuleNode) {
+		switch n := n.(type) {
+		case *GlobNode:
+			add(n.Pattern)
+		case *LiteralNode:
+			add(n.ExpectedPath)
+		case *FilterNode:
+			if n.Child != nil {
+				visit(n.Child)
+			}
+		}
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+	return roots
+}
+
 // ResolveAST evaluates the AST in a single pass. The reducer applies
 // last-write-wins to inclusion vs. exclusion decisions and tracks superseded
-// inclusion rules in FilteredResult.
+// inclusion rules in FilteredResult. Rules marked @binary are evaluated as
+// exclusions after the others (see binary.go). Contexts that keep a walk index record
+// the walks of independent roots concurrently first; nodes are still
+// resolved in order against those recordings, so the result does not depend
+// on which walk finished first.
 func ResolveAST(nodes []RuleNode, ctx ResolutionContext) (AttributionResult, ExclusionResult, FilteredResult, ExcludedByResult) {
+	nodes = hoistBinaryRules(nodes)
+	if p, ok := ctx.(walkPrefetcher); ok {
+		p.prefetchWalks(walkRoots(nodes, ctx.BaseDir()))
+	}
 	perFile := map[string][]FileAttribution{}
 	for _, n := range nodes {
 		for _, a := range n.Resolve(ctx) {
@@ -270,8 +411,16 @@ func ruleInfosToNodes(rules []RuleInfo) []RuleNode {
 // multiple roots for external patterns), then all rules are re-evaluated
 // against the discovered file set so exclusions see the full cross-root set.
 func (m *Manager) resolveFilesViaAST(rules []RuleInfo) ([]string, error) {
+	files, _, err := m.resolveRulesViaAST(rules, newStatCache())
+	return files, err
+}
+
+// resolveRulesViaAST is resolveFilesViaAST that also returns the files an
+// exclusion rule removed, in the same form as the included files. Both
+// passes stat patterns through stats.
+func (m *Manager) resolveRulesViaAST(rules []RuleInfo, stats *statCache) (files, excluded []string, err error) {
 	if len(rules) == 0 {
-		return []string{}, nil
+		return []string{}, nil, nil
 	}
 
 	// Validate: reject unauthorized external paths, check directive regexes.
@@ -298,43 +447,66 @@ func (m *Manager) resolveFilesViaAST(rules []RuleInfo) ([]string, error) {
 				if r.IsExclude {
 					pattern = "!" + pattern
 				}
-				fmt.Fprintf(os.Stderr, "Warning: skipping rule '%s': %s\n", pattern, reason)
+				Warnf("skipping rule '%s': %s", pattern, reason)
 				m.addSkippedRule(0, pattern, reason)
 				continue
 			}
 		}
 		for _, d := range r.Directives {
 			switch d.Name {
-			case "grep", "grep!", "grep-i":
+			case "grep", "grep!", "grep-i", "grep-re", "grep-re!":
 				q := d.Query
 				if d.Name == "grep-i" {
 					q = "(?i)" + q
 				}
 				if _, err := regexp.Compile(q); err != nil {
-					return nil, fmt.Errorf("invalid regex %q in @%s directive: %w", d.Query, d.Name, err)
+					if r.LineNum > 0 {
+						return nil, nil, fmt.Errorf("line %d: invalid regex %q in @%s directive: %w", r.LineNum, d.Query, d.Name, err)
+					}
+					return nil, nil, fmt.Errorf("invalid regex %q in @%s directive: %w", d.Query, d.Name, err)
+				}
+			default:
+				if metadataDirectives[strings.TrimSuffix(d.Name, "!")] {
+					if err := validateMetadataDirective(d.Name, d.Query); err != nil {
+						return nil, nil, err
+					}
 				}
 			}
 		}
 		validated = append(validated, r)
 	}
 	rules = validated
+	if before := len(rules); before > 0 {
+		rules = dedupeRules(rules)
+		if len(rules) < before {
+			m.ulog.Debug("Deduplicated expanded rules").
+				Field("before", before).
+				Field("after", len(rules)).
+				Log(m.Context())
+		}
+	}
 
 	hasExclusion := false
 	for _, r := range rules {
-		if r.IsExclude {
+		if r.IsExclude || hasDirective(r.Directives, directiveBinary) {
 			hasExclusion = true
 			break
 		}
 	}
 
 	nodes := ruleInfosToNodes(rules)
-	ctx := newProdResolutionContext(m)
+	ctx := newProdResolutionContext(m).withStatCache(stats)
+	defer m.saveWalkIndexes()
+	walkWarnings := m.walkWarningCount()
 
 	if !hasExclusion {
 		attr, _, filt, eby := ResolveAST(nodes, ctx)
+		if err := m.strictWalkFailure(walkWarnings); err != nil {
+			return nil, nil, err
+		}
 		warnZeroMatchRules(rules, attr, filt, eby)
 		warnOversizedRules(rules, attr)
-		return m.flattenAttrResult(attr), nil
+		return m.dropOwnArtifacts(m.flattenAttrResult(attr)), nil, nil
 	}
 
 	// Phase 1: resolve inclusion-only nodes to discover the full file set.
@@ -345,6 +517,9 @@ func (m *Manager) resolveFilesViaAST(rules []RuleInfo) ([]string, error) {
 		}
 	}
 	inclAttr, _, _, _ := ResolveAST(ruleInfosToNodes(inclRules), ctx)
+	if err := m.strictWalkFailure(walkWarnings); err != nil {
+		return nil, nil, err
+	}
 	var discovered []string
 	for _, paths := range inclAttr {
 		discovered = append(discovered, paths...)
@@ -352,11 +527,11 @@ func (m *Manager) resolveFilesViaAST(rules []RuleInfo) ([]string, error) {
 
 	// Phase 2: re-evaluate all rules against the discovered set so
 	// exclusions see files from every walk root.
-	primedCtx := newProdResolutionContext(m).withFileSet(discovered)
-	attr, _, filt, eby := ResolveAST(nodes, primedCtx)
+	primedCtx := newProdResolutionContext(m).withStatCache(stats).withFileSet(discovered)
+	attr, excl, filt, eby := ResolveAST(nodes, primedCtx)
 	warnZeroMatchRules(rules, attr, filt, eby)
 	warnOversizedRules(rules, attr)
-	return m.flattenAttrResult(attr), nil
+	return m.dropOwnArtifacts(m.flattenAttrResult(attr)), m.flattenAttrResult(AttributionResult(excl)), nil
 }
 
 func (m *Manager) flattenAttrResult(attr AttributionResult) []string {
@@ -410,7 +585,7 @@ func warnZeroMatchRules(rules []RuleInfo, attr AttributionResult, filt FilteredR
 		if len(attr[eln]) > 0 || len(filt[eln]) > 0 || len(eby[eln]) > 0 {
 			continue
 		}
-		fmt.Fprintf(os.Stderr, "Warning: path '%s' (line %d) matched 0 files\n", info.pattern, info.lineNum)
+		Warnf("path '%s' (line %d) matched 0 files", info.pattern, info.lineNum)
 	}
 }
 
@@ -4,6 +4,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/grovetools/core/util/pathutil"
 )
@@ -37,10 +39,21 @@ type ResolutionContext interface {
 type prodResolutionContext struct {
 	m       *Manager
 	fileSet map[string]bool
+	stats   *statCache
+
+	binary     *binaryPolicy // cx.binary
+	keepBinary bool          // walk binary files too, for @text rules
 }
 
 func newProdResolutionContext(m *Manager) *prodResolutionContext {
-	return &prodResolutionContext{m: m}
+	return &prodResolutionContext{m: m, stats: newStatCache(), binary: m.binaryPolicy()}
+}
+
+// withStatCache shares a stat cache with other contexts of the same
+// invocation, so the passes over one rule list stat each pattern once.
+func (c *prodResolutionContext) withStatCache(stats *statCache) *prodResolutionContext {
+	c.stats = stats
+	return c
 }
 
 // withFileSet primes the context with a pre-discovered file list. WalkDir
@@ -56,7 +69,68 @@ func (c *prodResolutionContext) withFileSet(files []string) *prodResolutionConte
 }
 
 func (c *prodResolutionContext) Stat(path string) (os.FileInfo, error) {
-	return os.Stat(path)
+	return c.stats.stat(path)
+}
+
+// statCache memoizes os.Stat for one invocation. Every plain pattern is
+// stat'ed by each resolution pass (inclusion discovery, attribution, hot
+// and cold) and again when classification expands directory patterns;
+// within an invocation the answer does not change.
+type statCache struct {
+	mu      sync.Mutex
+	entries map[string]statEntry
+	indexes map[string]*walkIndex // walk indexes already checked; see walkindex.go
+}
+
+type statEntry struct {
+	info os.FileInfo
+	err  error
+}
+
+func newStatCache() *statCache {
+	return &statCache{entries: make(map[string]statEntry)}
+}
+
+// stat is os.Stat through the cache. A nil cache stats directly.
+func (c *statCache) stat(path string) (os.FileInfo, error) {
+	if c == nil {
+		return os.Stat(path)
+	}
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok {
+		return e.info, e.err
+	}
+	info, err := os.Stat(path)
+	c.mu.Lock()
+	c.entries[path] = statEntry{info: info, err: err}
+	c.mu.Unlock()
+	return info, err
+}
+
+// checkedIndex returns the walk index of root already checked against the
+// disk in this invocation, if any. A nil cache has none.
+func (c *statCache) checkedIndex(root string) *walkIndex {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.indexes[root]
+}
+
+// markIndex records that idx is current for root in this invocation.
+func (c *statCache) markIndex(root string, idx *walkIndex) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.indexes == nil {
+		c.indexes = make(map[string]*walkIndex)
+	}
+	c.indexes[root] = idx
 }
 
 func (c *prodResolutionContext) WalkDir(root string, fn fs.WalkDirFunc) error {
@@ -74,8 +148,28 @@ func (c *prodResolutionContext) WalkDir(root string, fn fs.WalkDirFunc) error {
 		gitIgnored = make(map[string]bool)
 	}
 
-	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	// filepath.WalkDir does not descend into a symlinked root, so a
+	// workspace entered through a symlink would resolve nothing. Walk the
+	// target and hand paths back under the caller's spelling of root.
+	walkRoot := root
+	if real, err := filepath.EvalSymlinks(root); err == nil && real != filepath.Clean(root) {
+		walkRoot = real
+	}
+	return filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+		if walkRoot != root {
+			if path == walkRoot {
+				path = root
+			} else {
+				path = filepath.Join(root, strings.TrimPrefix(path, walkRoot+string(filepath.Separator)))
+			}
+		}
 		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return nil // a missing root is a zero-match rule, not a walk error
+			}
+			if werr := c.m.walkError(path, err); werr != nil {
+				return werr
+			}
 			if d != nil && d.IsDir() {
 				return filepath.SkipDir
 			}
@@ -100,8 +194,14 @@ func (c *prodResolutionContext) WalkDir(root string, fn fs.WalkDirFunc) error {
 			return nil
 		}
 
-		if !d.IsDir() && isBinaryFile(path) {
-			return nil
+		if !d.IsDir() {
+			if kind := specialEntryKind(path, d); kind != "" {
+				c.m.skipSpecialEntry(path, kind)
+				return nil
+			}
+			if !c.keepBinary && c.binary.isBinary(path, d) {
+				return nil
+			}
 		}
 
 		return fn(path, d, err)
@@ -119,6 +219,12 @@ func (c *prodResolutionContext) MatchDirective(file, directive, query string) bo
 	return c.m.matchDirective(file, directive, query)
 }
 
+// MatchDirectives evaluates all of a filter's directives with one read of
+// the file (see directiveSetMatcher).
+func (c *prodResolutionContext) MatchDirectives(file string, directives []SearchDirective) bool {
+	return c.m.matchDirectives(file, directives)
+}
+
 func (c *prodResolutionContext) MatchPattern(pattern, path string) bool {
 	return c.m.matchPattern(pattern, path)
 }
@@ -142,6 +248,12 @@ func (c *prodResolutionContext) ExecCommand(cmd string) ([]string, error) {
 	return c.m.executeCommandExpression(cmd)
 }
 
+// RecordCommandFailure records a failed `@cmd:` rule on the manager (see
+// commandFailureRecorder).
+func (c *prodResolutionContext) RecordCommandFailure(lineNum int, command string, err error) {
+	c.m.recordCommandFailure(lineNum, command, err)
+}
+
 func (c *prodResolutionContext) ResolveAliasLine(line string) (string, error) {
 	resolver := c.m.getAliasResolver()
 	if resolver == nil {
@@ -14,7 +14,6 @@ import (
 	"github.com/grovetools/core/config"
 	"github.com/grovetools/core/logging"
 	"github.com/grovetools/core/pkg/repo"
-	"github.com/grovetools/core/pkg/workspace"
 	"github.com/grovetools/core/state"
 )
 
@@ -54,13 +53,21 @@ type conceptDirective struct {
 	LineNum int
 }
 
+// defaultDirective records a single @default: line and its source line
+// number, so rules pulled in from the default preset are attributed to the
+// line that imported them.
+type defaultDirective struct {
+	Path    string
+	LineNum int
+}
+
 // parsedRules holds the fully parsed contents of a single rules file,
 // including all rules, directives, and import statements.
 type parsedRules struct {
 	hotRules             []RuleInfo
 	coldRules            []RuleInfo
-	mainDefaultPaths     []string
-	coldDefaultPaths     []string
+	mainDefaultPaths     []defaultDirective
+	coldDefaultPaths     []defaultDirective
 	mainImportedRuleSets []ImportInfo
 	coldImportedRuleSets []ImportInfo
 	mainIncludes         []ImportInfo
@@ -108,6 +115,10 @@ type SkippedRule struct {
 func (m *Manager) LoadDefaultRulesContent() (content []byte, rulesPath string) {
 	rulesPath = m.ResolveRulesWritePath()
 
+	if m.noState {
+		return nil, rulesPath
+	}
+
 	// Load grove.yml to check for default rules
 	cfg, err := config.LoadFrom(m.workDir)
 	if err != nil || cfg == nil {
@@ -134,7 +145,7 @@ func (m *Manager) LoadDefaultRulesContent() (content []byte, rulesPath string) {
 				return content, rulesPath
 			}
 		}
-		fmt.Fprintf(os.Stderr, "Warning: could not find default_rules preset '%s'\n", cfg.Context.DefaultRules)
+		Warnf("could not find default_rules preset '%s'", cfg.Context.DefaultRules)
 		return nil, rulesPath
 	}
 
@@ -154,7 +165,7 @@ func (m *Manager) LoadDefaultRulesContent() (content []byte, rulesPath string) {
 		defaultRulesPath := filepath.Join(projectRoot, cfg.Context.DefaultRulesPath)
 		content, err := os.ReadFile(defaultRulesPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not read default_rules_path %s: %v\n", defaultRulesPath, err)
+			Warnf("could not read default_rules_path %s: %v", defaultRulesPath, err)
 			return nil, rulesPath
 		}
 		return content, rulesPath
@@ -168,6 +179,9 @@ func (m *Manager) LoadDefaultRulesContent() (content []byte, rulesPath string) {
 // For legacy default_rules_path like ".cx/dev-no-tests.rules", it extracts "dev-no-tests".
 // Returns empty string if no default is configured.
 func (m *Manager) GetDefaultRuleName() string {
+	if m.noState {
+		return ""
+	}
 	// Load grove.yml to check for default rules
 	cfg, err := config.LoadFrom(m.workDir)
 	if err != nil || cfg == nil {
@@ -251,8 +265,8 @@ func (m *Manager) ListGitRules() ([]GitRule, error) {
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
-		if line == "---" {
-			inColdSection = true
+		if name, ok := ParseSectionSeparator(line); ok {
+			inColdSection = !m.SectionIsHot(name)
 			continue
 		}
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -359,6 +373,28 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 		return nil, "", nil // Override file doesn't exist yet — match existing fallback behavior
 	}
 
+	// Library mode: supplied content replaces discovery entirely, and a
+	// stateless manager only looks at workDir's own rules files.
+	if m.hasSuppliedRules {
+		return m.suppliedRules, m.suppliedRulesPath(), nil
+	}
+	if m.noState {
+		return m.loadLocalRulesContent()
+	}
+
+	// CX_RULES_FILE / CX_PROFILE take precedence over the rule set in state.
+	envPath, err := m.envRulesFile()
+	if err != nil {
+		return nil, "", err
+	}
+	if envPath != "" {
+		content, err := os.ReadFile(envPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading rules file %s from environment: %w", envPath, err)
+		}
+		return content, envPath, nil
+	}
+
 	// 1. Check state for an active rule set from .cx/
 	activeSource, _ := state.GetString(m.workDir, StateSourceKey)
 	if activeSource != "" {
@@ -397,7 +433,7 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 	// This must be checked BEFORE local .grove/rules so the notebook is the
 	// single source of truth — .grove/rules is legacy and should only be
 	// consulted as a fallback for projects that haven't migrated yet.
-	if node, wsErr := workspace.GetProjectByPath(m.workDir); wsErr == nil {
+	if node, wsErr := m.projectNode(); wsErr == nil {
 		if nbRulesFile, locErr := m.locator.GetContextRulesFile(node); locErr == nil {
 			if _, statErr := os.Stat(nbRulesFile); statErr == nil {
 				content, err := os.ReadFile(nbRulesFile)
@@ -419,25 +455,11 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 		}
 	}
 
-	// 4. Look for local .grove/rules (legacy fallback)
-	localRulesPath := filepath.Join(m.workDir, ActiveRulesFile)
-	if _, err := os.Stat(localRulesPath); err == nil {
-		content, err := os.ReadFile(localRulesPath)
-		if err != nil {
-			return nil, "", fmt.Errorf("reading local rules file %s: %w", localRulesPath, err)
-		}
-		return content, localRulesPath, nil
-	}
-
-	// 5. If not found, look for legacy .grovectx
-	legacyRulesPath := filepath.Join(m.workDir, RulesFile)
-	if _, err := os.Stat(legacyRulesPath); err == nil {
-		content, err := os.ReadFile(legacyRulesPath)
-		if err != nil {
-			return nil, "", fmt.Errorf("reading legacy rules file %s: %w", legacyRulesPath, err)
-		}
-		return content, legacyRulesPath, nil
+	// 4-5. Look for local .grove/rules, then legacy .grovectx
+	if content, path, err := m.loadLocalRulesContent(); err != nil || content != nil {
+		return content, path, err
 	}
+	localRulesPath := filepath.Join(m.workDir, ActiveRulesFile)
 
 	// 4. If not found, check grove.yml for a default
 	cfg, err := config.LoadFrom(m.workDir)
@@ -465,7 +487,7 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 				return content, localRulesPath, nil
 			}
 		}
-		fmt.Fprintf(os.Stderr, "Warning: could not find default_rules preset '%s'\n", cfg.Context.DefaultRules)
+		Warnf("could not find default_rules preset '%s'", cfg.Context.DefaultRules)
 		return nil, "", nil
 	}
 
@@ -485,7 +507,7 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 		defaultRulesPath := filepath.Join(projectRoot, cfg.Context.DefaultRulesPath)
 		content, err := os.ReadFile(defaultRulesPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not read default_rules_path %s: %v\n", defaultRulesPath, err)
+			Warnf("could not read default_rules_path %s: %v", defaultRulesPath, err)
 			return nil, "", nil
 		}
 		return content, localRulesPath, nil
@@ -495,6 +517,30 @@ func (m *Manager) LoadRulesContent() (content []byte, path string, err error) {
 	return nil, "", nil
 }
 
+// loadLocalRulesContent reads the rules files kept in workDir itself: local
+// .grove/rules (legacy fallback), then legacy .grovectx. It returns nil
+// content when neither exists.
+func (m *Manager) loadLocalRulesContent() (content []byte, path string, err error) {
+	localRulesPath := filepath.Join(m.workDir, ActiveRulesFile)
+	if _, err := os.Stat(localRulesPath); err == nil {
+		content, err := os.ReadFile(localRulesPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading local rules file %s: %w", localRulesPath, err)
+		}
+		return content, localRulesPath, nil
+	}
+
+	legacyRulesPath := filepath.Join(m.workDir, RulesFile)
+	if _, err := os.Stat(legacyRulesPath); err == nil {
+		content, err := os.ReadFile(legacyRulesPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading legacy rules file %s: %w", legacyRulesPath, err)
+		}
+		return content, legacyRulesPath, nil
+	}
+	return nil, "", nil
+}
+
 // ExpandBraces recursively expands shell-style brace patterns.
 // Example: "path/{a,b}/{c,d}" -> ["path/a/c", "path/a/d", "path/b/c", "path/b/d"]
 // expandHomeAndDot expands a leading ~ and strips a leading ./ on a rule line,
@@ -596,10 +642,12 @@ func splitByComma(s string) []string {
 	return results
 }
 
-// parseSearchDirectives parses a line for search directives (@find:, @grep:, @grep-i:, or @changed:)
+// parseSearchDirectives parses a line for search directives (@find:, @grep:, @grep-i:, @grep-re:, or @changed:)
 // Returns: basePattern, directives, hasDirectives
 // Supports multiple directives on the same line acting as AND filters.
 // Example: "pkg/**/*.go @find: \"api\" @grep: \"User\"" -> "pkg/**/*.go", [{Name: "find", Query: "api"}, {Name: "grep", Query: "User"}], true
+// The @and, @or, @any-of and @all-of operators compose directives explicitly;
+// see compose.go for how the result encodes them.
 func parseSearchDirectives(line string) (basePattern string, directives []SearchDirective, hasDirectives bool) {
 	// Known directive markers
 	dirMarkers := []struct {
@@ -607,16 +655,26 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 		name   string
 	}{
 		{" @grep-i: ", "grep-i"},
+		{" @grep-re!: ", "grep-re!"},
+		{" @grep-re: ", "grep-re"},
 		{" @find!: ", "find!"},
 		{" @grep!: ", "grep!"},
 		{" @find: ", "find"},
 		{" @grep: ", "grep"},
 		{" @changed: ", "changed"},
 		{" @recent: ", "recent"},
+		{" @newer-than: ", "newer-than"},
+		{" @older-than: ", "older-than"},
+		{" @size: ", "size"},
+		{" @executable: ", "executable"},
+		{" @owner-uid: ", "owner-uid"},
+		{" @text: ", directiveText},
+		{" @binary: ", directiveBinary},
 	}
+	line = expandBareDirectives(line)
 
 	// Find the position of the first directive across all markers
-	firstDirIdx := -1
+	firstDirIdx, _ := indexDirectiveOperator(line)
 	for _, dm := range dirMarkers {
 		idx := strings.Index(line, dm.marker)
 		if idx != -1 && (firstDirIdx == -1 || idx < firstDirIdx) {
@@ -631,6 +689,28 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 	basePattern = strings.TrimSpace(line[:firstDirIdx])
 	remainder := line[firstDirIdx:]
 
+	// Directives accumulate into @or-separated clauses. Under @any-of each
+	// further directive starts a new clause that shares the directives
+	// preceding the operator.
+	var (
+		leading   string
+		clauses   [][]SearchDirective
+		cur       []SearchDirective
+		anyOf     bool
+		anyOfSeen bool
+		anyOfBase []SearchDirective
+	)
+	add := func(d SearchDirective) {
+		if anyOf && anyOfSeen {
+			clauses = append(clauses, cur)
+			cur = append(append([]SearchDirective{}, anyOfBase...), d)
+			return
+		}
+		anyOfSeen = anyOf
+		cur = append(cur, d)
+	}
+	started := func() bool { return len(cur) > 0 || len(clauses) > 0 || anyOf }
+
 	for {
 		// Find the earliest directive in remainder
 		bestIdx := -1
@@ -643,6 +723,27 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 				bestMarker = dm.marker
 			}
 		}
+		if opIdx, op := indexDirectiveOperator(remainder); opIdx != -1 && (bestIdx == -1 || opIdx < bestIdx) {
+			switch op {
+			case "@or":
+				if !started() {
+					leading = directiveOr
+				}
+				if len(cur) > 0 {
+					clauses = append(clauses, cur)
+				}
+				cur, anyOf = nil, false
+			case "@and":
+				if !started() {
+					leading = directiveAnd
+				}
+			case "@any-of":
+				anyOf, anyOfSeen = true, false
+				anyOfBase = append([]SearchDirective{}, cur...)
+			}
+			remainder = remainder[opIdx+1+len(op):]
+			continue
+		}
 		if bestIdx == -1 {
 			break
 		}
@@ -659,10 +760,13 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 					break
 				}
 			}
+			if idx, _ := indexDirectiveOperator(query); idx != -1 {
+				query = strings.TrimSpace(query[:idx])
+			}
 			if len(query) >= 2 && query[0] == '"' && query[len(query)-1] == '"' {
 				query = query[1 : len(query)-1]
 			}
-			directives = append(directives, SearchDirective{Name: bestName, Query: query})
+			add(SearchDirective{Name: bestName, Query: query})
 			advance := bestIdx + len(bestMarker) + len(query)
 			if advance < len(remainder) {
 				remainder = remainder[advance:]
@@ -677,7 +781,7 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 			endQuote := strings.Index(queryPart[1:], "\"")
 			if endQuote != -1 {
 				query := queryPart[1 : endQuote+1]
-				directives = append(directives, SearchDirective{Name: bestName, Query: query})
+				add(SearchDirective{Name: bestName, Query: query})
 				advance := bestIdx + len(bestMarker) + endQuote + 2
 				if advance < len(remainder) {
 					remainder = remainder[advance:]
@@ -696,8 +800,11 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 				break
 			}
 		}
+		if idx, _ := indexDirectiveOperator(query); idx != -1 {
+			query = strings.TrimSpace(query[:idx])
+		}
 		if query != "" {
-			directives = append(directives, SearchDirective{Name: bestName, Query: query})
+			add(SearchDirective{Name: bestName, Query: query})
 			advance := bestIdx + len(bestMarker) + len(query)
 			if advance < len(remainder) {
 				remainder = remainder[advance:]
@@ -709,7 +816,22 @@ func parseSearchDirectives(line string) (basePattern string, directives []Search
 		}
 	}
 
-	return basePattern, directives, len(directives) > 0
+	if len(cur) > 0 {
+		clauses = append(clauses, cur)
+	}
+	if len(clauses) == 0 {
+		return basePattern, nil, false
+	}
+	if leading != "" {
+		directives = append(directives, SearchDirective{Name: leading})
+	}
+	for i, clause := range clauses {
+		if i > 0 {
+			directives = append(directives, SearchDirective{Name: directiveOr})
+		}
+		directives = append(directives, clause...)
+	}
+	return basePattern, directives, true
 }
 
 // encodeDirectives appends directives to a pattern in |||name|||query format.
@@ -727,6 +849,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 	if len(rulesContent) == 0 {
 		return results, nil
 	}
+	rulesContent = m.preprocessRules(rulesContent)
 
 	// Surface ParseError issues from the pure parser (Phase 2 shim).
 	if _, parseErrs := ParseToAST(rulesContent); len(parseErrs) > 0 {
@@ -738,15 +861,24 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 	// Create repo manager for processing Git URLs
 	repoManager, repoErr := repo.NewManager()
 	if repoErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not create repository manager: %v\n", repoErr)
+		Warnf("could not create repository manager: %v", repoErr)
+	}
+	var cloneCache *CloneCache
+	if repoManager != nil {
+		if cloneCache, repoErr = NewCloneCache(repoManager); repoErr != nil {
+			Warnf("could not open shared clone cache: %v", repoErr)
+		}
 	}
 
 	// Initialize alias resolver for @alias: directives
 	resolver := m.getAliasResolver()
 
 	inColdSection := false
-	// Track global search directives
+	seenBareSeparator := false
+	// Track global search directives, and the ones to restore when each
+	// enclosing @with block closes
 	var globalDirectives []SearchDirective
+	var scopes [][]SearchDirective
 	lineNum := 0 // Track line numbers
 
 	scanner := bufio.NewScanner(bytes.NewReader(rulesContent))
@@ -790,7 +922,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 				// Resolve the rule part, which can be a complex rule itself
 				resolvedPatterns, err := m.ResolveLineForRulePreview(rulePart)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: could not resolve view rule '%s': %v\n", rulePart, err)
+					Warnf("could not resolve view rule '%s': %v", rulePart, err)
 					results.viewPaths = append(results.viewPaths, rulePart) // Fallback to unresolved
 				} else {
 					// A ruleset import can return multiple patterns
@@ -813,7 +945,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 					aliasPart = strings.TrimPrefix(aliasPart, "@a:")
 					projectPath, resolveErr := resolver.Resolve(aliasPart)
 					if resolveErr != nil {
-						fmt.Fprintf(os.Stderr, "Warning: could not resolve alias for tree rule '%s': %v\n", rulePart, resolveErr)
+						Warnf("could not resolve alias for tree rule '%s': %v", rulePart, resolveErr)
 						results.treePaths = append(results.treePaths, rulePart)
 					} else {
 						results.treePaths = append(results.treePaths, projectPath)
@@ -824,17 +956,56 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			}
 			continue
 		}
+		// Handle @tree-only: — a sized directory listing included as one file
+		if strings.HasPrefix(line, "@tree-only:") {
+			rulePart := strings.TrimSpace(stripInlineComments(strings.TrimPrefix(line, "@tree-only:")))
+			if rulePart != "" {
+				dir := expandHomeAndDot(rulePart)
+				if resolver != nil && (strings.HasPrefix(rulePart, "@alias:") || strings.HasPrefix(rulePart, "@a:")) {
+					aliasPart := strings.TrimPrefix(rulePart, "@alias:")
+					aliasPart = strings.TrimPrefix(aliasPart, "@a:")
+					projectPath, resolveErr := resolver.Resolve(aliasPart)
+					if resolveErr != nil {
+						Warnf("could not resolve alias for tree-only rule '%s': %v", rulePart, resolveErr)
+						continue
+					}
+					dir = projectPath
+				}
+				treeFile, err := m.generateTreeOnlyFile(dir)
+				if err != nil {
+					Warnf("@tree-only: %s: %v", rulePart, err)
+				} else {
+					ruleInfo := RuleInfo{Pattern: treeFile, IsExclude: false, LineNum: lineNum}
+					if inColdSection {
+						results.coldRules = append(results.coldRules, ruleInfo)
+					} else {
+						results.hotRules = append(results.hotRules, ruleInfo)
+					}
+				}
+			}
+			continue
+		}
 		if strings.HasPrefix(line, "@default:") {
 			path := strings.TrimSpace(strings.TrimPrefix(line, "@default:"))
 			if path != "" {
 				if inColdSection {
-					results.coldDefaultPaths = append(results.coldDefaultPaths, path)
+					results.coldDefaultPaths = append(results.coldDefaultPaths, defaultDirective{Path: path, LineNum: lineNum})
 				} else {
-					results.mainDefaultPaths = append(results.mainDefaultPaths, path)
+					results.mainDefaultPaths = append(results.mainDefaultPaths, defaultDirective{Path: path, LineNum: lineNum})
 				}
 			}
 			continue
 		}
+		// @require: and @max-age: are post-resolution checks (see require.go
+		// and freshness.go), @budget: trims the resolved files (see
+		// budget.go), @allow-path: is applied before expansion (see
+		// allowpath.go) and the tier transforms and @symbol-index when
+		// writing (see transforms.go and symbols.go); none are patterns.
+		if strings.HasPrefix(line, "@require:") || strings.HasPrefix(line, "@max-age:") || strings.HasPrefix(line, "@budget:") ||
+			strings.HasPrefix(line, "@allow-path:") || strings.HasPrefix(line, "@hot-transform:") || strings.HasPrefix(line, "@cold-transform:") ||
+			line == symbolIndexDirective {
+			continue
+		}
 		if strings.HasPrefix(line, "@concept:") {
 			conceptID := strings.TrimSpace(strings.TrimPrefix(line, "@concept:"))
 			if conceptID != "" {
@@ -860,6 +1031,26 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			}
 			continue
 		}
+		// Scoped global directives (see scope.go). Malformed or unbalanced
+		// blocks are reported by ParseToAST.
+		if line == clearFiltersDirective {
+			globalDirectives = nil
+			continue
+		}
+		if scoped, ok, err := parseWithBlock(line); ok {
+			scopes = append(scopes, globalDirectives)
+			if err == nil {
+				globalDirectives = composeWithGlobals(globalDirectives, scoped)
+			}
+			continue
+		}
+		if line == scopeEnd {
+			if n := len(scopes); n > 0 {
+				globalDirectives = scopes[n-1]
+				scopes = scopes[:n-1]
+			}
+			continue
+		}
 		// Handle global @recent: directive
 		if strings.HasPrefix(line, "@recent:") {
 			queryPart := strings.TrimSpace(strings.TrimPrefix(line, "@recent:"))
@@ -926,6 +1117,13 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			}
 			continue
 		}
+		// Handle global @grep-re: and @grep-re!: directives
+		if strings.HasPrefix(line, "@grep-re:") || strings.HasPrefix(line, "@grep-re!:") {
+			if _, ds, ok := parseSearchDirectives(" " + line); ok {
+				globalDirectives = append(globalDirectives, ds...)
+			}
+			continue
+		}
 		// Handle global @grep: directive
 		if strings.HasPrefix(line, "@grep:") {
 			queryPart := strings.TrimSpace(strings.TrimPrefix(line, "@grep:"))
@@ -949,7 +1147,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			}
 			files, err := m.getChangedFiles(ref)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to get changed files for %q: %v\n", ref, err)
+				Warnf("failed to get changed files for %q: %v", ref, err)
 			} else {
 				for _, file := range files {
 					ruleInfo := RuleInfo{Pattern: file, IsExclude: false, LineNum: lineNum}
@@ -967,7 +1165,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			ref := strings.TrimSpace(strings.TrimPrefix(line, "@diff:"))
 			diffFile, err := m.generateDiffFile(ref)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to generate diff for %q: %v\n", ref, err)
+				Warnf("failed to generate diff for %q: %v", ref, err)
 			} else if diffFile != "" {
 				ruleInfo := RuleInfo{Pattern: diffFile, IsExclude: false, LineNum: lineNum}
 				if inColdSection {
@@ -978,12 +1176,88 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 			}
 			continue
 		}
-		if line == "---" {
-			if inColdSection {
-				// Already past one separator; ignore additional ones (warning emitted by ParseToAST shim).
-				continue
+		// Handle standalone @git: directive — writes git log/status/branches to a file
+		if strings.HasPrefix(line, "@git:") {
+			spec := strings.TrimSpace(stripInlineComments(strings.TrimPrefix(line, "@git:")))
+			gitFile, err := m.generateGitMetadataFile(spec)
+			if err != nil {
+				Warnf("@git: %s: %v", spec, err)
+			} else if gitFile != "" {
+				ruleInfo := RuleInfo{Pattern: gitFile, IsExclude: false, LineNum: lineNum}
+				if inColdSection {
+					results.coldRules = append(results.coldRules, ruleInfo)
+				} else {
+					results.hotRules = append(results.hotRules, ruleInfo)
+				}
+			}
+			continue
+		}
+		// Handle standalone @tasks directive — summarizes build/test entry points
+		if line == "@tasks" || strings.HasPrefix(line, "@tasks:") || strings.HasPrefix(line, "@tasks ") {
+			dir := strings.TrimSpace(stripInlineComments(strings.TrimPrefix(strings.TrimPrefix(line, "@tasks"), ":")))
+			tasksFile, err := m.generateTasksFile(dir)
+			if err != nil {
+				Warnf("@tasks: %v", err)
+			} else {
+				ruleInfo := RuleInfo{Pattern: tasksFile, IsExclude: false, LineNum: lineNum}
+				if inColdSection {
+					results.coldRules = append(results.coldRules, ruleInfo)
+				} else {
+					results.hotRules = append(results.hotRules, ruleInfo)
+				}
+			}
+			continue
+		}
+		// Handle standalone @pkg: directive — summarizes package manifests in
+		// place of the manifests themselves
+		if strings.HasPrefix(line, "@pkg:") {
+			spec := strings.TrimSpace(stripInlineComments(strings.TrimPrefix(line, "@pkg:")))
+			pkgFile, manifests, err := m.generatePkgSummaryFile(spec)
+			if err != nil {
+				Warnf("@pkg: %v", err)
+			} else {
+				ruleInfos := []RuleInfo{{Pattern: pkgFile, IsExclude: false, LineNum: lineNum}}
+				for _, manifest := range manifests {
+					ruleInfos = append(ruleInfos, RuleInfo{Pattern: manifest, IsExclude: true, LineNum: lineNum})
+				}
+				if inColdSection {
+					results.coldRules = append(results.coldRules, ruleInfos...)
+				} else {
+					results.hotRules = append(results.hotRules, ruleInfos...)
+				}
+			}
+			continue
+		}
+		// Handle standalone @fixtures: directive — lists a fixtures directory
+		// in place of its contents
+		if strings.HasPrefix(line, "@fixtures:") {
+			spec := strings.TrimSpace(stripInlineComments(strings.TrimPrefix(line, "@fixtures:")))
+			listFile, exclude, err := m.generateFixturesFile(spec)
+			if err != nil {
+				Warnf("@fixtures: %v", err)
+			} else {
+				// The exclusion comes first so last-match-wins keeps the listing.
+				ruleInfos := []RuleInfo{
+					{Pattern: exclude, IsExclude: true, LineNum: lineNum},
+					{Pattern: listFile, IsExclude: false, LineNum: lineNum},
+				}
+				if inColdSection {
+					results.coldRules = append(results.coldRules, ruleInfos...)
+				} else {
+					results.hotRules = append(results.hotRules, ruleInfos...)
+				}
+			}
+			continue
+		}
+		if name, ok := ParseSectionSeparator(line); ok {
+			if name == "" {
+				if seenBareSeparator {
+					// Already past one bare separator; ignore additional ones (warning emitted by ParseToAST shim).
+					continue
+				}
+				seenBareSeparator = true
 			}
-			inColdSection = true
+			inColdSection = !m.SectionIsHot(name)
 			continue
 		}
 		if line != "" && !strings.HasPrefix(line, "#") {
@@ -1136,7 +1410,8 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 							}
 						}
 					} else {
-						fmt.Fprintf(os.Stderr, "Warning: command expression failed: %s: %v\n", cmdExpr, cmdErr)
+						m.recordCommandFailure(lineNum, cmdExpr, cmdErr)
+						Warnf("line %d: command expression failed: %s: %v", lineNum, cmdExpr, cmdErr)
 					}
 					continue
 				}
@@ -1193,7 +1468,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 					}
 					resolvedLine, resolveErr := resolver.ResolveLine(rulePart)
 					if resolveErr != nil {
-						fmt.Fprintf(os.Stderr, "Warning: could not resolve alias in line '%s': %v\n", line, resolveErr)
+						Warnf("could not resolve alias in line '%s': %v", line, resolveErr)
 						continue // Skip this line if alias resolution fails
 					}
 
@@ -1221,7 +1496,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 
 					// Validate that the resolved project path is allowed
 					if allowed, reason := m.IsPathAllowed(projectPath); !allowed {
-						fmt.Fprintf(os.Stderr, "Warning: skipping rule '%s': %s\n", line, reason)
+						Warnf("skipping rule '%s': %s", line, reason)
 						m.addSkippedRule(lineNum, line, reason)
 						continue
 					}
@@ -1265,7 +1540,7 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 							importIdentifier := fmt.Sprintf("git::%s@%s::%s", repoURL, version, ruleset)
 							if isExclude {
 								// Exclusions on git ruleset imports are not yet supported.
-								fmt.Fprintf(os.Stderr, "Warning: exclusion prefix '!' on git ruleset import is not supported: %s\n", processedLine)
+								Warnf("exclusion prefix '!' on git ruleset import is not supported: %s", processedLine)
 							} else {
 								if inColdSection {
 									results.coldImportedRuleSets = append(results.coldImportedRuleSets, ImportInfo{
@@ -1285,9 +1560,18 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 						}
 
 						// Ensure the repository worktree exists for the specified version
-						localPath, _, cloneErr := repoManager.EnsureVersion(m.Context(), repoURL, version)
+						// Go through the shared clone cache when available so
+						// concurrent generations across workspaces serialize on
+						// the same URL+version and the checkout is ref-counted.
+						var localPath string
+						var cloneErr error
+						if cloneCache != nil {
+							localPath, _, cloneErr = cloneCache.Ensure(m.Context(), m.workDir, repoURL, version)
+						} else {
+							localPath, _, cloneErr = repoManager.EnsureVersion(m.Context(), repoURL, version)
+						}
 						if cloneErr != nil {
-							fmt.Fprintf(os.Stderr, "Warning: could not ensure repository version %s: %v\n", repoURL, cloneErr)
+							Warnf("could not ensure repository version %s: %v", repoURL, cloneErr)
 							continue
 						}
 
@@ -1319,10 +1603,11 @@ func (m *Manager) parseRulesFileContent(rulesContent []byte) (*parsedRules, erro
 				// Now we check if we should apply a global directive.
 				basePattern := processedLine
 
-				// If no inline directives but there are global directives, use them
-				if !hasInlineDirectives && len(globalDirectives) > 0 {
-					directives = globalDirectives
-					hasInlineDirectives = true // Mark that directives are now active
+				// Inline directives replace the global ones unless they start
+				// with @and/@or, which composes them (see composeWithGlobals).
+				if hasInlineDirectives || len(globalDirectives) > 0 {
+					directives = composeWithGlobals(globalDirectives, directives)
+					hasInlineDirectives = len(directives) > 0 // Mark that directives are now active
 				}
 
 				// Apply brace expansion to the base pattern
@@ -1377,7 +1662,7 @@ func (m *Manager) findActiveRulesFile() string {
 	}
 
 	// Check notebook location
-	if node, err := workspace.GetProjectByPath(m.workDir); err == nil {
+	if node, err := m.projectNode(); err == nil {
 		if nbRulesFile, err := m.locator.GetContextRulesFile(node); err == nil {
 			if _, statErr := os.Stat(nbRulesFile); statErr == nil {
 				return nbRulesFile
@@ -1551,7 +1836,7 @@ func (m *Manager) removeGitRulesForRepo(repoURL string) error {
 	var newLines []string
 
 	for _, line := range lines {
-		isGit, lineRepoURL, _, _ := m.parseGitRuleForModification(strings.TrimSpace(line))
+		isGit, lineRepoURL, _, _ := m.parseGitRuleForModification(ruleText(line))
 		if isGit && lineRepoURL == repoURL {
 			// This is a rule for the repo we want to remove, so skip it.
 			continue
@@ -1582,20 +1867,24 @@ func (m *Manager) AppendRule(rulePath, contextType string) error {
 		return fmt.Errorf("safety validation failed: %w", err)
 	}
 
+	// Carry any `# why:` annotation over from the rule being replaced, so
+	// toggling a rule between hot/cold/excluded never drops its rationale.
+	annotation := m.existingRuleAnnotation(rulePath)
+
 	// Check if the new rule is a Git rule. If so, remove all existing rules for that repo.
 	// Otherwise, use the existing path-based removal logic.
 	isGit, repoURL, _, _ := m.parseGitRuleForModification(rulePath)
 	if isGit {
 		if err := m.removeGitRulesForRepo(repoURL); err != nil {
 			// Non-fatal error, log and continue
-			fmt.Fprintf(os.Stderr, "Warning: could not remove existing git rules for %s: %v\n", repoURL, err)
+			Warnf("could not remove existing git rules for %s: %v", repoURL, err)
 		}
 	} else {
 		// First, remove any existing rules for this path to prevent duplicates
 		// This makes the function idempotent and handles state changes
 		if err := m.RemoveRuleForPath(rulePath); err != nil {
 			// Non-fatal error, log and continue
-			fmt.Fprintf(os.Stderr, "Warning: could not remove existing rules: %v\n", err)
+			Warnf("could not remove existing rules: %v", err)
 		}
 	}
 
@@ -1622,11 +1911,12 @@ func (m *Manager) AppendRule(rulePath, contextType string) error {
 	default:
 		newRule = rulePath
 	}
+	newRule = withAnnotation(newRule, annotation)
 
 	// Find separator line index
 	separatorIndex := -1
 	for i, line := range lines {
-		if strings.TrimSpace(line) == "---" {
+		if _, ok := ParseSectionSeparator(line); ok {
 			separatorIndex = i
 			break
 		}
@@ -1660,6 +1950,70 @@ func (m *Manager) AppendRule(rulePath, contextType string) error {
 	return os.WriteFile(rulesFilePath, []byte(content), 0o644) //nolint:gosec // rules file, not sensitive
 }
 
+// AppendRuleGroup adds rules to the active rules file as one block headed by
+// a `# comment` line, in the "hot" or "cold" section. Existing copies of the
+// rules are removed first, so re-adding a group moves it instead of
+// duplicating it.
+func (m *Manager) AppendRuleGroup(comment string, rules []string, contextType string) error {
+	if IsZombieWorktree(m.workDir) {
+		return fmt.Errorf("cannot create rules file: worktree has been deleted")
+	}
+	if contextType != "hot" && contextType != "cold" {
+		return fmt.Errorf("invalid context type %q: must be hot or cold", contextType)
+	}
+	for _, rule := range rules {
+		if err := m.validateRuleSafety(rule); err != nil {
+			return fmt.Errorf("safety validation failed for %s: %w", rule, err)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	for _, rule := range rules {
+		if err := m.RemoveRule(rule); err != nil {
+			Warnf("could not remove existing rule %s: %v", rule, err)
+		}
+	}
+
+	rulesFilePath := m.findActiveRulesFile()
+	if rulesFilePath == "" {
+		rulesFilePath = m.ResolveRulesWritePath()
+	}
+
+	var lines []string
+	if content, err := os.ReadFile(rulesFilePath); err == nil && len(content) > 0 {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	}
+
+	block := make([]string, 0, len(rules)+2)
+	if comment != "" {
+		block = append(block, "# "+comment)
+	}
+	block = append(block, rules...)
+
+	separatorIndex := -1
+	for i, line := range lines {
+		if _, ok := ParseSectionSeparator(line); ok {
+			separatorIndex = i
+			break
+		}
+	}
+	switch {
+	case contextType == "cold" && separatorIndex < 0:
+		lines = append(append(lines, "---"), block...)
+	case contextType == "cold":
+		lines = append(lines, block...)
+	case separatorIndex < 0:
+		lines = append(lines, block...)
+	default:
+		rest := append([]string{}, lines[separatorIndex:]...)
+		lines = append(append(lines[:separatorIndex], block...), rest...)
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	return os.WriteFile(rulesFilePath, []byte(content), 0o644) //nolint:gosec // rules file, not sensitive
+}
+
 // ToggleViewDirective adds or removes a `@view:` directive from the rules file.
 func (m *Manager) ToggleViewDirective(path string) error {
 	// Check for zombie worktree - refuse to create rules in deleted worktrees
@@ -1683,7 +2037,7 @@ func (m *Manager) ToggleViewDirective(path string) error {
 	viewDirective := "@view: " + path
 
 	for _, line := range lines {
-		if strings.TrimSpace(line) == viewDirective {
+		if ruleText(line) == viewDirective {
 			found = true
 			continue // Remove the line
 		}
@@ -1729,9 +2083,9 @@ func (m *Manager) GetRuleStatus(rulePath string) RuleStatus {
 	inColdSection := false
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "---" {
-			inColdSection = true
+		line = ruleText(line)
+		if name, ok := ParseSectionSeparator(line); ok {
+			inColdSection = !m.SectionIsHot(name)
 			continue
 		}
 
@@ -1772,7 +2126,7 @@ func (m *Manager) RemoveRule(rulePath string) error {
 	normalRule := rulePath
 
 	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
+		trimmedLine := ruleText(line)
 		// Skip the lines that match our rule (either normal or exclude form)
 		if trimmedLine == excludeRule || trimmedLine == normalRule {
 			continue
@@ -1809,7 +2163,40 @@ func (m *Manager) RemoveRuleForPath(path string) error {
 
 	lines := strings.Split(string(content), "\n")
 	var newLines []string
+	patternsToRemove := rulePatternsForPath(path)
+
+	// Check each line and skip if it matches any of our patterns
+	for _, line := range lines {
+		trimmedLine := ruleText(line)
+		shouldRemove := false
+
+		for _, pattern := range patternsToRemove {
+			if trimmedLine == pattern {
+				shouldRemove = true
+				break
+			}
+		}
 
+		if !shouldRemove {
+			newLines = append(newLines, line)
+		}
+	}
+
+	// Clean up empty lines and unnecessary separators
+	newLines = cleanupRulesLines(newLines)
+
+	// Write back to file
+	newContent := strings.Join(newLines, "\n")
+	if len(newLines) > 0 && !strings.HasSuffix(newContent, "\n") {
+		newContent += "\n"
+	}
+
+	return os.WriteFile(rulesFilePath, []byte(newContent), 0o644) //nolint:gosec // rules file, not sensitive
+}
+
+// rulePatternsForPath returns every rule spelling (path, !path, path/**, ...)
+// that RemoveRuleForPath treats as referring to path.
+func rulePatternsForPath(path string) []string {
 	// Clean the input path
 	path = strings.TrimSpace(path)
 	path = strings.TrimSuffix(path, "/")
@@ -1835,33 +2222,41 @@ func (m *Manager) RemoveRuleForPath(path string) error {
 		)
 	}
 
-	// Check each line and skip if it matches any of our patterns
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		shouldRemove := false
+	return patternsToRemove
+}
 
-		for _, pattern := range patternsToRemove {
-			if trimmedLine == pattern {
-				shouldRemove = true
-				break
+// existingRuleAnnotation returns the `# why:` annotation of the rule in the
+// active rules file that AppendRule is about to replace for rulePath, if any.
+func (m *Manager) existingRuleAnnotation(rulePath string) string {
+	rulesFilePath := m.findActiveRulesFile()
+	if rulesFilePath == "" {
+		return ""
+	}
+	content, err := os.ReadFile(rulesFilePath)
+	if err != nil {
+		return ""
+	}
+
+	isGit, repoURL, _, _ := m.parseGitRuleForModification(rulePath)
+	patterns := rulePatternsForPath(strings.TrimPrefix(rulePath, "!"))
+	for _, line := range strings.Split(string(content), "\n") {
+		rule, annotation := SplitRuleAnnotation(line)
+		if annotation == "" {
+			continue
+		}
+		if isGit {
+			if lineIsGit, lineRepoURL, _, _ := m.parseGitRuleForModification(rule); lineIsGit && lineRepoURL == repoURL {
+				return annotation
 			}
+			continue
 		}
-
-		if !shouldRemove {
-			newLines = append(newLines, line)
+		for _, pattern := range patterns {
+			if rule == pattern {
+				return annotation
+			}
 		}
 	}
-
-	// Clean up empty lines and unnecessary separators
-	newLines = cleanupRulesLines(newLines)
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	if len(newLines) > 0 && !strings.HasSuffix(newContent, "\n") {
-		newContent += "\n"
-	}
-
-	return os.WriteFile(rulesFilePath, []byte(newContent), 0o644) //nolint:gosec // rules file, not sensitive
+	return ""
 }
 
 // validateRuleSafety checks if a rule is safe to add
@@ -1925,6 +2320,42 @@ func (m *Manager) validateRuleSafety(rulePath string) error {
 	return nil
 }
 
+// ValidateRulesContent checks rules content submitted to replace a rules
+// file, such as over `cx serve`: it must parse, and each pattern must pass
+// the safety checks applied to rules added from the command line. @cmd:
+// directives are rejected unless allowCommands is set, since the content
+// does not come from the user's own editor.
+func (m *Manager) ValidateRulesContent(content []byte, allowCommands bool) error {
+	nodes, errs := ParseToAST(content)
+	if len(errs) > 0 {
+		return fmt.Errorf("line %d: %s", errs[0].Line, errs[0].Msg)
+	}
+	var check func(node RuleNode) error
+	check = func(node RuleNode) error {
+		switch n := node.(type) {
+		case *GlobNode:
+			return m.validateRuleSafety(n.Pattern)
+		case *LiteralNode:
+			return m.validateRuleSafety(n.ExpectedPath)
+		case *FilterNode:
+			if n.Child != nil {
+				return check(n.Child)
+			}
+		case *CommandNode:
+			if !allowCommands {
+				return fmt.Errorf("@cmd: directives are not allowed here")
+			}
+		}
+		return nil
+	}
+	for _, node := range nodes {
+		if err := check(node); err != nil {
+			return fmt.Errorf("line %d: %w", node.Line(), err)
+		}
+	}
+	return nil
+}
+
 // insertAt inserts a string at the specified index in a slice
 func insertAt(slice []string, index int, value string) []string {
 	if index < 0 || index > len(slice) {
@@ -1946,8 +2377,10 @@ func cleanupRulesLines(lines []string) []string {
 	}
 
 	// If only separator remains, remove it
-	if len(lines) == 1 && strings.TrimSpace(lines[0]) == "---" {
-		return []string{}
+	if len(lines) == 1 {
+		if _, ok := ParseSectionSeparator(lines[0]); ok {
+			return []string{}
+		}
 	}
 
 	// Remove separator if there are no cold context rules after it
@@ -1955,7 +2388,7 @@ func cleanupRulesLines(lines []string) []string {
 	separatorIndex := -1
 
 	for i, line := range lines {
-		if strings.TrimSpace(line) == "---" {
+		if _, ok := ParseSectionSeparator(line); ok {
 			separatorIndex = i
 		} else if separatorIndex >= 0 && strings.TrimSpace(line) != "" {
 			hasColdRules = true
@@ -1983,10 +2416,12 @@ func (m *Manager) executeCommandExpression(cmdExpr string) ([]string, error) {
 	cmd := exec.Command("sh", "-c", cmdExpr)
 	cmd.Dir = m.workDir
 
-	// Capture output
+	// Capture output, and stderr for the failure report
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("command failed: %w", err)
+		return nil, newCommandError(cmdExpr, err, stderr.Bytes())
 	}
 
 	// Parse output into file paths
@@ -33,6 +33,7 @@ const (
 	LineTypeGrepIDirective
 	LineTypeChangedDirective
 	LineTypeDiffDirective
+	LineTypeGitDirective
 	LineTypeRecentDirective
 	LineTypeOtherDirective
 	LineTypePattern
@@ -50,9 +51,6 @@ var (
 	// Comment: lines starting with #
 	commentRegex = regexp.MustCompile(`^\s*#.*$`)
 
-	// Separator: --- for hot/cold context
-	separatorRegex = regexp.MustCompile(`^---\s*$`)
-
 	// Exclusion: lines starting with !
 	excludeRegex = regexp.MustCompile(`^\s*!.*$`)
 
@@ -105,8 +103,11 @@ var (
 	// Diff directive: @diff: (standalone)
 	diffDirectiveRegex = regexp.MustCompile(`^\s*@diff:`)
 
-	// Other directives: @default, @freeze-cache, @no-expire, @disable-cache, @expire-time
-	otherDirectiveRegex = regexp.MustCompile(`^\s*@(default|freeze-cache|no-expire|disable-cache|expire-time):?`)
+	// Git metadata directive: @git: (standalone)
+	gitDirectiveRegex = regexp.MustCompile(`^\s*@git:`)
+
+	// Other directives: @default, @freeze-cache, @no-expire, @disable-cache, @expire-time, @require, @max-age, @budget, @allow-path, @hot-transform, @cold-transform, @symbol-index, @tasks, @tree-only, @pkg
+	otherDirectiveRegex = regexp.MustCompile(`^\s*@(default|freeze-cache|no-expire|disable-cache|expire-time|require|max-age|budget|allow-path|hot-transform|cold-transform|symbol-index|tasks|tree-only|pkg|fixtures):?`)
 )
 
 // ParseRulesLine parses a single line from a rules file and returns its type and parsed components
@@ -132,11 +133,15 @@ func ParseRulesLine(line string) ParsedLine {
 	}
 
 	// Separator
-	if separatorRegex.MatchString(line) {
+	if name, ok := ParseSectionSeparator(line); ok {
+		parts := make(map[string]string)
+		if name != "" {
+			parts["section"] = name
+		}
 		return ParsedLine{
 			Type:    LineTypeSeparator,
 			Content: line,
-			Parts:   make(map[string]string),
+			Parts:   parts,
 		}
 	}
 
@@ -330,6 +335,16 @@ func ParseRulesLine(line string) ParsedLine {
 		}
 	}
 
+	// Git metadata directive (standalone)
+	if gitDirectiveRegex.MatchString(line) {
+		parts := map[string]string{"spec": strings.TrimSpace(strings.TrimPrefix(trimmed, "@git:"))}
+		return ParsedLine{
+			Type:    LineTypeGitDirective,
+			Content: line,
+			Parts:   parts,
+		}
+	}
+
 	// Other directives
 	if otherDirectiveRegex.MatchString(line) {
 		return ParsedLine{
@@ -506,16 +521,8 @@ func parseSearchDirectiveLine(line, prefix string) map[string]string {
 // stripInlineComments removes a trailing " # ..." comment unless the # is
 // inside a double-quoted span (preserves @grep: "foo # bar").
 func stripInlineComments(line string) string {
-	inQuote := false
-	for i := 0; i < len(line); i++ {
-		c := line[i]
-		if c == '"' {
-			inQuote = !inQuote
-			continue
-		}
-		if !inQuote && c == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t') {
-			return strings.TrimRight(line[:i], " \t")
-		}
+	if i := inlineCommentIndex(line); i >= 0 {
+		return strings.TrimRight(line[:i], " \t")
 	}
 	return line
 }
@@ -568,6 +575,7 @@ func ParseToAST(content []byte) ([]RuleNode, []ParseError) {
 	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
 	lineNum := 0
 	seenSeparator := false
+	var openScopes []int // lines of unclosed @with blocks
 
 	for scanner.Scan() {
 		lineNum++
@@ -576,9 +584,30 @@ func ParseToAST(content []byte) ([]RuleNode, []ParseError) {
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
 			continue
 		}
-		if trimmed == "---" {
+		if trimmed == clearFiltersDirective {
+			continue
+		}
+		if _, ok, err := parseWithBlock(trimmed); ok {
+			if err != nil {
+				errs = append(errs, ParseError{Line: lineNum, Msg: err.Error()})
+			}
+			openScopes = append(openScopes, lineNum)
+			continue
+		}
+		if trimmed == scopeEnd {
+			if len(openScopes) == 0 {
+				errs = append(errs, ParseError{Line: lineNum, Msg: "unmatched '}' without an @with block"})
+			} else {
+				openScopes = openScopes[:len(openScopes)-1]
+			}
+			continue
+		}
+		if name, ok := ParseSectionSeparator(trimmed); ok {
+			if name != "" {
+				continue
+			}
 			if seenSeparator {
-				errs = append(errs, ParseError{Line: lineNum, Msg: "multiple '---' separators found; rules support exactly one hot/cold separator"})
+				errs = append(errs, ParseError{Line: lineNum, Msg: "multiple '---' separators found; name further sections ('--- <name>') instead"})
 				continue
 			}
 			seenSeparator = true
@@ -676,6 +705,9 @@ func ParseToAST(content []byte) ([]RuleNode, []ParseError) {
 			nodes = append(nodes, node)
 		}
 	}
+	for _, line := range openScopes {
+		errs = append(errs, ParseError{Line: line, Msg: "@with block is never closed with '}'"})
+	}
 
 	return nodes, errs
 }
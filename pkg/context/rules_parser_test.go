@@ -85,6 +85,15 @@ func TestParseToAST(t *testing.T) {
 				{kind: "literal", path: "c.go", line: 5},
 			},
 		},
+		{
+			name:  "named sections after separator",
+			input: "a.go\n---\nb.go\n--- reference\nc.go\n",
+			wantNodes: []expectedNode{
+				{kind: "literal", path: "a.go", line: 1},
+				{kind: "literal", path: "b.go", line: 3},
+				{kind: "literal", path: "c.go", line: 5},
+			},
+		},
 		{
 			name:     "capital alias errors",
 			input:    "@A:foo\n",
@@ -127,6 +136,34 @@ func TestParseToAST(t *testing.T) {
 				{kind: "import", path: "eco:b/main.go", line: 1},
 			},
 		},
+		{
+			name:  "with block lines are not patterns",
+			input: "@with @grep: \"x\" {\nfoo.go\n}\n@clear-filters\n",
+			wantNodes: []expectedNode{
+				{kind: "literal", path: "foo.go", line: 2},
+			},
+		},
+		{
+			name:     "unclosed with block",
+			input:    "@with @grep: \"x\" {\nfoo.go\n",
+			wantErrs: []int{1},
+			wantNodes: []expectedNode{
+				{kind: "literal", path: "foo.go", line: 2},
+			},
+		},
+		{
+			name:     "unmatched closing brace",
+			input:    "foo.go\n}\n",
+			wantErrs: []int{2},
+			wantNodes: []expectedNode{
+				{kind: "literal", path: "foo.go", line: 1},
+			},
+		},
+		{
+			name:     "with block without directives",
+			input:    "@with {\n}\n",
+			wantErrs: []int{1},
+		},
 	}
 
 	for _, tt := range tests {
@@ -143,6 +143,36 @@ func TestAppendRule_CreatesFileAtCorrectPath(t *testing.T) {
 	}
 }
 
+func TestAppendRuleGroup(t *testing.T) {
+	testDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(testDir, ".grove"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(testDir)
+	rulesPath := mgr.ResolveRulesWritePath()
+	if err := os.WriteFile(rulesPath, []byte("main.go\n@a:eco:api/**\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := []string{"@a:eco:api/**", "@a:eco:web/**"}
+	if err := mgr.AppendRuleGroup("eco sub-projects", rules, "cold"); err != nil {
+		t.Fatalf("AppendRuleGroup: %v", err)
+	}
+	content, _ := os.ReadFile(rulesPath)
+	want := "main.go\n---\n# eco sub-projects\n@a:eco:api/**\n@a:eco:web/**\n"
+	if string(content) != want {
+		t.Errorf("rules = %q, want %q", content, want)
+	}
+
+	if err := mgr.AppendRuleGroup("hot group", []string{"docs/**"}, "hot"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(rulesPath)
+	if !strings.HasPrefix(string(content), "main.go\n# hot group\ndocs/**\n---\n") {
+		t.Errorf("hot group should be inserted before the separator: %q", content)
+	}
+}
+
 func TestToggleViewDirective_CreatesFileAtCorrectPath(t *testing.T) {
 	testDir := t.TempDir()
 
@@ -0,0 +1,129 @@
+package context
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// RulesetSummary is the resolved size of one named rule set.
+type RulesetSummary struct {
+	Name       string `json:"name"`
+	Path       string `json:"path,omitempty"`
+	HotFiles   int    `json:"hotFiles"`
+	HotTokens  int    `json:"hotTokens"`
+	ColdFiles  int    `json:"coldFiles"`
+	ColdTokens int    `json:"coldTokens"`
+	Error      string `json:"error,omitempty"`
+
+	files map[string]int // resolved path (hot and cold) -> tokens
+}
+
+// RulesetOverlap is the set of files two rule sets both include, in either
+// section.
+type RulesetOverlap struct {
+	A            string `json:"a"`
+	B            string `json:"b"`
+	SharedFiles  int    `json:"sharedFiles"`
+	SharedTokens int    `json:"sharedTokens"`
+}
+
+// RulesetComparison is the result of CompareRulesets.
+type RulesetComparison struct {
+	Rulesets []RulesetSummary `json:"rulesets"`
+	Overlaps []RulesetOverlap `json:"overlaps"`
+}
+
+// CompareRulesets resolves every named rule set whose name matches the glob
+// pattern ("*" for all) and reports each one's hot and cold size plus the
+// pairwise overlap between them. A rule set that fails to resolve is
+// reported with Error set rather than aborting the comparison. Overlaps
+// with no shared files are omitted; the rest are sorted by shared tokens.
+func (m *Manager) CompareRulesets(pattern string) (*RulesetComparison, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid rule set pattern %q: %w", pattern, err)
+	}
+	var names []string
+	for _, name := range m.ListRulesetNames() {
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no rule sets match %q", pattern)
+	}
+
+	provider := GetStatsProvider()
+	tokens := make(map[string]int)
+	tokensOf := func(file string) int {
+		if t, ok := tokens[file]; ok {
+			return t
+		}
+		t := 0
+		if info, err := provider.GetFileStats(file); err == nil {
+			t = info.Tokens
+		}
+		tokens[file] = t
+		return t
+	}
+	abs := func(file string) string {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(m.workDir, file)
+		}
+		return filepath.Clean(file)
+	}
+
+	result := &RulesetComparison{}
+	for _, name := range names {
+		summary := RulesetSummary{Name: name, files: make(map[string]int)}
+		rulesPath, err := m.FindRulesetFile(m.workDir, name)
+		if err != nil {
+			summary.Error = err.Error()
+			result.Rulesets = append(result.Rulesets, summary)
+			continue
+		}
+		summary.Path = rulesPath
+		hot, cold, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+		if err != nil {
+			summary.Error = err.Error()
+			result.Rulesets = append(result.Rulesets, summary)
+			continue
+		}
+		for _, f := range hot {
+			f = abs(f)
+			t := tokensOf(f)
+			summary.HotFiles++
+			summary.HotTokens += t
+			summary.files[f] = t
+		}
+		for _, f := range cold {
+			f = abs(f)
+			t := tokensOf(f)
+			summary.ColdFiles++
+			summary.ColdTokens += t
+			summary.files[f] = t
+		}
+		result.Rulesets = append(result.Rulesets, summary)
+	}
+
+	for i := range result.Rulesets {
+		for j := i + 1; j < len(result.Rulesets); j++ {
+			a, b := result.Rulesets[i], result.Rulesets[j]
+			overlap := RulesetOverlap{A: a.Name, B: b.Name}
+			for f, t := range a.files {
+				if _, ok := b.files[f]; ok {
+					overlap.SharedFiles++
+					overlap.SharedTokens += t
+				}
+			}
+			if overlap.SharedFiles > 0 {
+				result.Overlaps = append(result.Overlaps, overlap)
+			}
+		}
+	}
+	sort.SliceStable(result.Overlaps, func(i, j int) bool {
+		return result.Overlaps[i].SharedTokens > result.Overlaps[j].SharedTokens
+	})
+	return result, nil
+}
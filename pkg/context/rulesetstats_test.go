@@ -0,0 +1,53 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareRulesets(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".cx/api.rules", "api/*.go\nshared/*.go\n")
+	write(".cx/web.rules", "web/*.ts\n---\nshared/*.go\n")
+	write(".cx/docs.rules", "*.md\n")
+	write("api/a.go", "package api\n")
+	write("web/b.ts", "export {}\n")
+	write("shared/c.go", "package shared\n")
+	write("README.md", "# readme\n")
+
+	m := NewManager(dir)
+	cmp, err := m.CompareRulesets("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmp.Rulesets) != 3 {
+		t.Fatalf("expected three rule sets, got %+v", cmp.Rulesets)
+	}
+	byName := make(map[string]RulesetSummary)
+	for _, rs := range cmp.Rulesets {
+		byName[rs.Name] = rs
+	}
+	if rs := byName["web"]; rs.HotFiles != 1 || rs.ColdFiles != 1 || rs.Error != "" {
+		t.Errorf("unexpected web summary: %+v", rs)
+	}
+	if len(cmp.Overlaps) != 1 || cmp.Overlaps[0].A != "api" || cmp.Overlaps[0].B != "web" || cmp.Overlaps[0].SharedFiles != 1 {
+		t.Errorf("expected api and web to share shared/c.go, got %+v", cmp.Overlaps)
+	}
+
+	if cmp, err := m.CompareRulesets("a*"); err != nil || len(cmp.Rulesets) != 1 {
+		t.Errorf("pattern should select only api, got %+v, %v", cmp, err)
+	}
+	if _, err := m.CompareRulesets("nothing-*"); err == nil {
+		t.Error("expected an error when no rule set matches")
+	}
+}
@@ -0,0 +1,328 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Content safety. Included files are screened for content that can derail
+// a model reading the generated context: terminal escape sequences, other
+// control characters, invisible and bidi-override characters, extremely
+// long lines (minified bundles, embedded blobs) and, in third-party files,
+// text that reads like an instruction to the model. The policy decides
+// whether findings are only warned about or also stripped from the output;
+// source files are never modified.
+//
+//	cx:
+//	  content_safety:
+//	    policy: strip          # warn (default), strip or off
+//	    max_line_length: 2000  # default 4000; -1 disables the check
+//	    injection: all         # third-party (default), all or off
+//	    markers: ["(?i)as an ai model"]
+
+// Content safety policies.
+const (
+	SafetyWarn  = "warn"
+	SafetyStrip = "strip"
+	SafetyOff   = "off"
+)
+
+// Content safety finding kinds.
+const (
+	FindingANSI       = "ansi-escape"
+	FindingControl    = "control-char"
+	FindingInvisible  = "invisible-char"
+	FindingLongLine   = "long-line"
+	FindingInjection  = "prompt-injection"
+	defaultMaxLineLen = 4000
+)
+
+// ContentSafetyEnvVar overrides cx.content_safety.policy.
+const ContentSafetyEnvVar = "CX_CONTENT_SAFETY"
+
+// ContentSafetyConfig configures content safety screening.
+type ContentSafetyConfig struct {
+	// Policy is "warn" (the default), "strip" or "off".
+	Policy string `yaml:"policy,omitempty" toml:"policy,omitempty"`
+	// MaxLineLength is the longest line, in characters, not flagged.
+	// Zero means the default of 4000; negative disables the check.
+	MaxLineLength int `yaml:"max_line_length,omitempty" toml:"max_line_length,omitempty"`
+	// Injection is which files are checked for prompt-injection markers:
+	// "third-party" (the default: files outside the working directory or
+	// under vendor/, third_party/ or node_modules/), "all" or "off".
+	Injection string `yaml:"injection,omitempty" toml:"injection,omitempty"`
+	// Markers are extra prompt-injection markers, as regular expressions.
+	Markers []string `yaml:"markers,omitempty" toml:"markers,omitempty"`
+}
+
+// defaultInjectionMarkers match text addressed to a model rather than to a
+// reader of the code.
+var defaultInjectionMarkers = []string{
+	`(?i)\b(ignore|disregard|forget)\s+(all\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier)\s+(instructions|prompts?|context|rules)`,
+	`(?i)\byou\s+are\s+now\s+(a|an|in)\b`,
+	`(?i)\b(reveal|print|output|repeat)\s+(your|the)\s+system\s+prompt`,
+	`(?i)\bnew\s+instructions\s*:`,
+	`<\|(im_start|im_end|system|endoftext)\|>`,
+	`\[/?INST\]`,
+	`(?i)<\s*/?\s*(system|system-prompt|instructions)\s*>`,
+}
+
+var (
+	ansiEscapeRegex = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+	thirdPartyDirs  = map[string]bool{"vendor": true, "third_party": true, "third-party": true, "node_modules": true}
+)
+
+// SafetyFinding is one kind of suspicious content in a file.
+type SafetyFinding struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+	Line   int    `json:"line"`             // first occurrence
+	Sample string `json:"sample,omitempty"` // the first prompt-injection match
+}
+
+// String describes the finding for a warning.
+func (f SafetyFinding) String() string {
+	s := fmt.Sprintf("%s: %d %s (first on line %d)", f.Path, f.Count, f.Kind, f.Line)
+	if f.Sample != "" {
+		s += fmt.Sprintf(": %q", f.Sample)
+	}
+	return s
+}
+
+// contentScreen is a compiled ContentSafetyConfig.
+type contentScreen struct {
+	policy    string
+	maxLine   int
+	injection string
+	markers   []*regexp.Regexp
+	workDir   string
+}
+
+// newContentScreen compiles cfg, warning about and skipping invalid
+// settings.
+func newContentScreen(cfg ContentSafetyConfig, workDir string) *contentScreen {
+	s := &contentScreen{policy: cfg.Policy, maxLine: cfg.MaxLineLength, injection: cfg.Injection, workDir: workDir}
+	switch s.policy {
+	case "":
+		s.policy = SafetyWarn
+	case SafetyWarn, SafetyStrip, SafetyOff:
+	default:
+		Warnf("unknown content safety policy %q; using %s", s.policy, SafetyWarn)
+		s.policy = SafetyWarn
+	}
+	if s.maxLine == 0 {
+		s.maxLine = defaultMaxLineLen
+	}
+	if s.injection == "" {
+		s.injection = "third-party"
+	}
+	for _, expr := range append(append([]string{}, defaultInjectionMarkers...), cfg.Markers...) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			Warnf("ignoring content safety marker %q: %v", expr, err)
+			continue
+		}
+		s.markers = append(s.markers, re)
+	}
+	return s
+}
+
+// contentScreen returns the manager's compiled content safety settings.
+func (m *Manager) contentScreen() *contentScreen {
+	m.safetyOnce.Do(func() {
+		cfg := m.cxConfig().ContentSafety
+		if v := os.Getenv(ContentSafetyEnvVar); v != "" && !m.noState {
+			cfg.Policy = v
+		}
+		m.safety = newContentScreen(cfg, m.workDir)
+	})
+	return m.safety
+}
+
+// screenContent applies the content safety policy to a file about to be
+// written into a context artifact: findings are warned about, and with the
+// strip policy removed (or, for long lines and injection markers,
+// truncated and redacted) from the returned content.
+func (m *Manager) screenContent(file string, content []byte) []byte {
+	s := m.contentScreen()
+	if s.policy == SafetyOff {
+		return content
+	}
+	findings := s.scan(file, content)
+	if len(findings) == 0 {
+		return content
+	}
+	verb := "found"
+	if s.policy == SafetyStrip {
+		content = s.strip(file, content)
+		verb = "stripped"
+	}
+	for _, f := range findings {
+		Warnf("content safety (%s): %s", verb, f)
+	}
+	return content
+}
+
+// ScanContentSafety screens files (as resolved, relative to the working
+// directory or absolute) and returns every finding, whatever the policy.
+func (m *Manager) ScanContentSafety(files []string) []SafetyFinding {
+	s := m.contentScreen()
+	var findings []SafetyFinding
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		content, err := readRegularFile(path)
+		if err != nil || isBinaryContent(content) {
+			continue
+		}
+		findings = append(findings, s.scan(file, content)...)
+	}
+	return findings
+}
+
+// isBinaryContent reports whether content looks binary (a NUL in the first
+// 8 KB), in which case control characters are expected.
+func isBinaryContent(content []byte) bool {
+	if len(content) > 8192 {
+		content = content[:8192]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// checksInjection reports whether file is screened for injection markers.
+func (s *contentScreen) checksInjection(file string) bool {
+	switch s.injection {
+	case "all":
+		return true
+	case SafetyOff:
+		return false
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.workDir, path)
+	}
+	if rel, err := filepath.Rel(s.workDir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return true
+	}
+	for _, part := range strings.Split(filepath.ToSlash(file), "/") {
+		if thirdPartyDirs[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// scan returns the findings in content, one per kind, sorted by kind.
+func (s *contentScreen) scan(file string, content []byte) []SafetyFinding {
+	byKind := make(map[string]*SafetyFinding)
+	note := func(kind string, line, n int, sample string) {
+		f := byKind[kind]
+		if f == nil {
+			f = &SafetyFinding{Path: file, Kind: kind, Line: line, Sample: sample}
+			byKind[kind] = f
+		}
+		f.Count += n
+	}
+	injection := s.checksInjection(file)
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		lineNum := i + 1
+		if n := len(ansiEscapeRegex.FindAllIndex(line, -1)); n > 0 {
+			note(FindingANSI, lineNum, n, "")
+		}
+		control, invisible := 0, 0
+		for _, r := range string(ansiEscapeRegex.ReplaceAll(line, nil)) {
+			switch {
+			case isUnsafeControl(r):
+				control++
+			case isInvisible(r):
+				invisible++
+			}
+		}
+		if control > 0 {
+			note(FindingControl, lineNum, control, "")
+		}
+		if invisible > 0 && !(lineNum == 1 && invisible == 1 && bytes.HasPrefix(line, []byte("\ufeff"))) {
+			note(FindingInvisible, lineNum, invisible, "")
+		}
+		if s.maxLine > 0 && utf8.RuneCount(line) > s.maxLine {
+			note(FindingLongLine, lineNum, 1, "")
+		}
+		if injection {
+			for _, re := range s.markers {
+				if loc := re.FindIndex(line); loc != nil {
+					note(FindingInjection, lineNum, 1, string(line[loc[0]:loc[1]]))
+					break
+				}
+			}
+		}
+	}
+	findings := make([]SafetyFinding, 0, len(byKind))
+	for _, f := range byKind {
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Kind < findings[j].Kind })
+	return findings
+}
+
+// strip removes escape sequences, control and invisible characters,
+// truncates long lines and redacts injection markers.
+func (s *contentScreen) strip(file string, content []byte) []byte {
+	injection := s.checksInjection(file)
+	lines := bytes.Split(ansiEscapeRegex.ReplaceAll(content, nil), []byte("\n"))
+	for i, line := range lines {
+		line = bytes.Map(func(r rune) rune {
+			if isUnsafeControl(r) || (isInvisible(r) && !(i == 0 && r == '\ufeff')) {
+				return -1
+			}
+			return r
+		}, line)
+		if s.maxLine > 0 {
+			if n := utf8.RuneCount(line); n > s.maxLine {
+				runes := []rune(string(line))
+				line = []byte(fmt.Sprintf("%s… [truncated by cx: %d more characters]", string(runes[:s.maxLine]), n-s.maxLine))
+			}
+		}
+		if injection {
+			for _, re := range s.markers {
+				line = re.ReplaceAll(line, []byte("[removed by cx: possible prompt injection]"))
+			}
+		}
+		lines[i] = line
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// isUnsafeControl reports C0 and C1 control characters other than tab,
+// newline and carriage return.
+func isUnsafeControl(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return false
+	case r < 0x20, r == 0x7f, r >= 0x80 && r <= 0x9f:
+		return true
+	}
+	return false
+}
+
+// isInvisible reports zero-width, invisible formatting and bidi control
+// characters, which can hide text from a human reviewer or reorder it.
+func isInvisible(r rune) bool {
+	switch {
+	case r == 0x200b, r == 0x200e, r == 0x200f, // zero-width space, LRM/RLM (not the joiners emoji and scripts need)
+		r >= 0x202a && r <= 0x202e, // bidi embeddings and overrides
+		r >= 0x2060 && r <= 0x2064, // word joiner, invisible operators
+		r >= 0x2066 && r <= 0x2069, // bidi isolates
+		r == 0xfeff, r == 0x00ad, r == 0x180e:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scoped global directives.
+//
+// A global @find:/@grep: line filters every pattern after it. Two forms
+// limit that reach:
+//
+//	@clear-filters                 drops every active global directive
+//
+//	@with @grep: "x" {             applies @grep: "x" (AND-ed with the
+//	    pkg/**/*.go                active globals) to the patterns inside
+//	}                              the block only
+//
+// Global directives declared inside a block, and @clear-filters used inside
+// one, also end with the block. A block may open with @or to widen rather
+// than narrow the active globals (see composeWithGlobals).
+const (
+	clearFiltersDirective = "@clear-filters"
+	withDirective         = "@with"
+	scopeEnd              = "}"
+)
+
+// parseWithBlock parses an `@with <directives> {` line. ok reports whether
+// line is a block opener at all; err describes a malformed one.
+func parseWithBlock(line string) (directives []SearchDirective, ok bool, err error) {
+	if line != withDirective && !strings.HasPrefix(line, withDirective+" ") {
+		return nil, false, nil
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, withDirective))
+	if !strings.HasSuffix(rest, "{") {
+		return nil, true, fmt.Errorf("%s block must end with '{'", withDirective)
+	}
+	rest = strings.TrimSpace(strings.TrimSuffix(rest, "{"))
+	base, directives, has := parseSearchDirectives(" " + rest)
+	if !has || base != "" {
+		return nil, true, fmt.Errorf("%s expects search directives, got %q", withDirective, rest)
+	}
+	if directives[0].Name != directiveAnd && directives[0].Name != directiveOr {
+		directives = append([]SearchDirective{{Name: directiveAnd}}, directives...)
+	}
+	return directives, true, nil
+}
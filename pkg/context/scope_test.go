@@ -0,0 +1,40 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedGlobalDirectives(t *testing.T) {
+	m := NewManager(t.TempDir())
+	rules := `@grep: "TODO"
+a.go
+@with @find: "api" {
+b.go
+@clear-filters
+c.go
+}
+d.go
+@clear-filters
+e.go
+`
+	parsed, err := m.parseRulesFileContent([]byte(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	todo := SearchDirective{Name: "grep", Query: "TODO"}
+	api := SearchDirective{Name: "find", Query: "api"}
+	want := map[string][]SearchDirective{
+		"a.go": {todo},
+		"b.go": {todo, api},
+		"c.go": nil,    // cleared inside the block
+		"d.go": {todo}, // restored when the block closes
+		"e.go": nil,
+	}
+	assert.Len(t, parsed.hotRules, len(want))
+	for _, r := range parsed.hotRules {
+		assert.Equal(t, want[r.Pattern], r.Directives, r.Pattern)
+	}
+}
@@ -0,0 +1,105 @@
+package context
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ScratchFiles resolves the file arguments of `cx scratch`: existing files
+// are used as given, directories contribute every file below them, and
+// anything else is treated as a rules pattern. Explicitly named files are
+// not subject to the workspace sandbox; directories and patterns are. The
+// result keeps argument order without duplicates, with paths inside the
+// working directory made relative to it.
+func (m *Manager) ScratchFiles(args []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		key := m.snapshotKey(file)
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, key)
+		}
+	}
+
+	for _, arg := range args {
+		abs := arg
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(m.workDir, arg)
+		}
+		pattern := arg
+		if info, err := os.Stat(abs); err == nil {
+			if !info.IsDir() {
+				add(abs)
+				continue
+			}
+			pattern = filepath.ToSlash(filepath.Join(m.snapshotKey(abs), "**"))
+		}
+		matched, err := m.ResolveFilesFromPatterns([]string{pattern})
+		if err != nil {
+			return nil, fmt.Errorf("error resolving '%s': %w", arg, err)
+		}
+		if len(matched) == 0 {
+			Warnf("'%s' matched no files", arg)
+		}
+		for _, file := range matched {
+			add(file)
+		}
+	}
+	return files, nil
+}
+
+// WriteScratchContext writes a throwaway context to w: note (pasted text,
+// possibly empty) followed by files, in the same format as the hot context
+// artifact. No rules file, state or artifact is read or written.
+func (m *Manager) WriteScratchContext(w io.Writer, note string, files []string, useXMLFormat bool) error {
+	if note != "" && !strings.HasSuffix(note, "\n") {
+		note += "\n"
+	}
+	if !useXMLFormat {
+		if note != "" {
+			fmt.Fprintf(w, "=== NOTE ===\n%s=== END NOTE ===\n\n", note)
+		}
+		for _, file := range files {
+			m.writeFileClassic(w, file)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<context>\n")
+	if note != "" {
+		fmt.Fprintf(w, "  <note>\n%s  </note>\n", note)
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(w, "  <hot-context files=\"%d\" description=\"Files to be used for reference/background context to carry out the user's question/task to be provided later\">\n", len(files))
+		for _, file := range files {
+			if err := m.writeFileToXML(w, file, "    "); err != nil {
+				Warnf("could not read %s: %v", file, err)
+			}
+		}
+		fmt.Fprintf(w, "  </hot-context>\n")
+	}
+	_, err := fmt.Fprintf(w, "</context>\n")
+	return err
+}
+
+// ScratchSnapshotPrefix names the snapshots SaveScratchSnapshot writes.
+// Unlike snapshots saved under a chosen name, `cx clean` removes them.
+const ScratchSnapshotPrefix = "scratch-"
+
+// SaveScratchSnapshot records files as an unnamed snapshot under
+// SnapshotsDir, named scratch-<timestamp>, so a one-off context can later
+// be compared against with `cx stats --compare`. The file list stands in
+// for the rules. It returns the path written.
+func (m *Manager) SaveScratchSnapshot(files []string) (string, error) {
+	rules := strings.Join(files, "\n")
+	if rules != "" {
+		rules += "\n"
+	}
+	snap := m.snapshotOf([]byte(rules), files)
+	return m.SaveSnapshot(snap, ScratchSnapshotPrefix+snap.CreatedAt.Local().Format("20060102-150405"))
+}
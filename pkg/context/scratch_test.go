@@ -0,0 +1,65 @@
+package context
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScratchContext(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "main.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "docs/a.md"), "# A\n")
+	fsWriteString(t, filepath.Join(dir, "docs/b.md"), "# B\n")
+	fsWriteString(t, filepath.Join(dir, "pkg/x.go"), "package pkg\n")
+
+	m := NewManager(dir)
+	files, err := m.ScratchFiles([]string{filepath.Join(dir, "main.go"), "docs", "pkg/*.go", "main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"main.go", "docs/a.md", "docs/b.md", "pkg/x.go"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ScratchFiles = %v, want %v", files, want)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteScratchContext(&buf, "why does this panic?", files[:1], true); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, part := range []string{"<note>\nwhy does this panic?\n  </note>", `<hot-context files="1"`, `<file path="main.go">`, "package main"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("XML scratch context lacks %q:\n%s", part, out)
+		}
+	}
+
+	buf.Reset()
+	if err := m.WriteScratchContext(&buf, "note\n", files[:1], false); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "=== NOTE ===\nnote\n=== END NOTE ===\n\n=== FILE: main.go ===\n") {
+		t.Errorf("classic scratch context = %q", got)
+	}
+
+	path, err := m.SaveScratchSnapshot(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, SnapshotsDir) || !strings.HasPrefix(filepath.Base(path), "scratch-") {
+		t.Errorf("snapshot saved to %s", path)
+	}
+	snap, err := m.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Files) != 4 {
+		t.Errorf("snapshot files = %v, want 4", snap.Files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, GroveDir, "rules")); !os.IsNotExist(err) {
+		t.Error("scratch must not create a rules file")
+	}
+}
@@ -0,0 +1,195 @@
+package context
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SearchOptions configures Search.
+type SearchOptions struct {
+	// Directives every file must satisfy, exactly as on a rules line
+	// (@find:, @grep:, @grep-i:, their negations, @recent:, @changed:, the
+	// metadata filters of metafilter.go, with @or clauses).
+	Directives []SearchDirective
+	// Roots limits the search to these directories, each of which must be
+	// inside an allowed root. Empty means every allowed root.
+	Roots []string
+	// InContext limits the search to files in the current hot and cold
+	// context.
+	InContext bool
+	// Lines reports each line matching a positive @grep:/@grep-i: directive
+	// instead of one match per file.
+	Lines bool
+}
+
+// SearchMatch is one file, or one line of a file, that a search matched.
+type SearchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// Search runs the rules directive engine over the allowed roots, outside
+// of any rules file: every file a rule walking those roots could include
+// (gitignored, binary and junk-directory files excluded) is checked against
+// opts.Directives. Matches are sorted by path and line.
+func (m *Manager) Search(opts SearchOptions) ([]SearchMatch, error) {
+	roots, err := m.searchRoots(opts.Roots)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			candidates = append(candidates, path)
+		}
+	}
+	if opts.InContext {
+		s, err := m.NewResolutionSession()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range append(append([]string{}, s.Hot...), s.Cold...) {
+			if !filepath.IsAbs(f) {
+				f = filepath.Join(m.rulesBaseDir, f)
+			}
+			for _, root := range roots {
+				if f == root || strings.HasPrefix(f, root+string(filepath.Separator)) {
+					add(f)
+					break
+				}
+			}
+		}
+	} else {
+		ctx := newProdResolutionContext(m)
+		for _, root := range roots {
+			err := ctx.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d == nil {
+					return nil
+				}
+				if d.IsDir() {
+					if path != root && isJunkDir(d.Name()) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				add(path)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Content directives are I/O bound; check candidates on a bounded
+	// pool, as FilterNode does.
+	keep := make([]bool, len(candidates))
+	workers := m.grepWorkers()
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				keep[i] = m.matchDirectives(candidates[i], opts.Directives)
+			}
+		}()
+	}
+	for i := range candidates {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var lineMatchers []*grepMatcher
+	if opts.Lines {
+		for _, d := range opts.Directives {
+			if isGrepDirective(d.Name) {
+				if g := m.grepMatcherFor(d.Name, d.Query); !g.multiline {
+					lineMatchers = append(lineMatchers, g)
+				}
+			}
+		}
+	}
+
+	var matches []SearchMatch
+	for i, path := range candidates {
+		if !keep[i] {
+			continue
+		}
+		lines := matchingLines(path, lineMatchers)
+		if len(lines) == 0 {
+			matches = append(matches, SearchMatch{Path: path})
+		}
+		matches = append(matches, lines...)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Path != matches[j].Path {
+			return matches[i].Path < matches[j].Path
+		}
+		return matches[i].Line < matches[j].Line
+	})
+	return matches, nil
+}
+
+// searchRoots returns the directories a search covers: the allowed roots,
+// or the requested directories after checking each is allowed.
+func (m *Manager) searchRoots(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		roots, err := m.GetAllowedRoots()
+		if len(roots) == 0 {
+			if err != nil {
+				return nil, fmt.Errorf("no allowed roots to search: %w", err)
+			}
+			return []string{m.workDir}, nil
+		}
+		return roots, nil
+	}
+	roots := make([]string, 0, len(requested))
+	for _, dir := range requested {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(m.workDir, dir)
+		}
+		dir = filepath.Clean(dir)
+		if allowed, reason := m.IsPathAllowed(dir); !allowed {
+			return nil, fmt.Errorf("cannot search %s: %s", dir, reason)
+		}
+		roots = append(roots, dir)
+	}
+	return roots, nil
+}
+
+// matchingLines returns the lines of path that any of matchers match.
+func matchingLines(path string, matchers []*grepMatcher) []SearchMatch {
+	if len(matchers) == 0 {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []SearchMatch
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		for _, g := range matchers {
+			if g.match(scanner.Bytes()) {
+				lines = append(lines, SearchMatch{Path: path, Line: n, Text: scanner.Text()})
+				break
+			}
+		}
+	}
+	return lines
+}
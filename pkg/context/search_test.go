@@ -0,0 +1,61 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManager_Search(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	outside := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "api", "handler.go"), "package api\n\n// TODO: validate input\nfunc Handle() {}\n")
+	fsWriteString(t, filepath.Join(dir, "api", "handler_test.go"), "package api\n// todo later\n")
+	fsWriteString(t, filepath.Join(dir, "README.md"), "TODO: docs\n")
+	fsWriteString(t, filepath.Join(dir, "node_modules", "dep", "index.js"), "// TODO\n")
+
+	m := NewManager(dir, WithNoState(), WithRules([]byte("api/handler.go\n")))
+	rel := func(matches []SearchMatch) []string {
+		var out []string
+		for _, match := range matches {
+			r, _ := filepath.Rel(dir, match.Path)
+			if match.Line > 0 {
+				r += ":" + match.Text
+			}
+			out = append(out, filepath.ToSlash(r))
+		}
+		return out
+	}
+
+	matches, err := m.Search(SearchOptions{Directives: []SearchDirective{{Name: "grep", Query: "TODO"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rel(matches), []string{"README.md", "api/handler.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("grep TODO = %v, want %v", got, want)
+	}
+
+	matches, err = m.Search(SearchOptions{
+		Directives: []SearchDirective{{Name: "find", Query: "*.go"}, {Name: "grep-i", Query: "todo"}},
+		Lines:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rel(matches), []string{"api/handler.go:// TODO: validate input", "api/handler_test.go:// todo later"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("find *.go + grep-i todo = %v, want %v", got, want)
+	}
+
+	matches, err = m.Search(SearchOptions{Directives: []SearchDirective{{Name: "grep-i", Query: "todo"}}, InContext: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rel(matches), []string{"api/handler.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("in-context search = %v, want %v", got, want)
+	}
+
+	if _, err := m.Search(SearchOptions{Roots: []string{outside}}); err == nil {
+		t.Error("searching outside the allowed roots should fail")
+	}
+}
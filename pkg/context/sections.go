@@ -0,0 +1,254 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Named sections.
+//
+// A bare `---` splits a rules file into hot rules and cold (cached) rules.
+// Separators may also carry a name, starting a section of that name:
+//
+//	src/**/*.go
+//	--- cold
+//	vendor/github.com/spf13/cobra/**
+//	--- reference
+//	docs/**/*.md
+//
+// The rules before the first separator form the "hot" section, and a bare
+// `---` is the same as `--- cold`. Every other section is cold unless
+// cx.sections gives it tier: hot, and may be written to an artifact of its
+// own as well (cx.sections.<name>.output). Only the first bare separator
+// counts; later ones are ignored, as before sections had names.
+const (
+	HotSection  = "hot"
+	ColdSection = "cold"
+)
+
+var sectionSeparatorRegex = regexp.MustCompile(`^---(?:\s+([A-Za-z0-9_.-]+))?\s*$`)
+
+// SectionConfig configures a named rules section under cx.sections.
+type SectionConfig struct {
+	// Tier is "hot" or "cold" (the default): which context the section's
+	// files go to, and so whether they are cached.
+	Tier string `yaml:"tier,omitempty" toml:"tier,omitempty"`
+	// Output, when set, also writes the section's files to this artifact,
+	// relative to the working directory.
+	Output string `yaml:"output,omitempty" toml:"output,omitempty"`
+}
+
+// RuleSection is one named section of a rules file and the files its rules
+// contributed to its tier.
+type RuleSection struct {
+	Name   string   `json:"name"`
+	Tier   string   `json:"tier"`
+	Output string   `json:"output,omitempty"`
+	Lines  [][2]int `json:"lines"` // first and last line of each run; 0 ends at EOF
+	Files  []string `json:"files"`
+}
+
+// ParseSectionSeparator reports whether line is a section separator and
+// the section it starts: "" for a bare `---`.
+func ParseSectionSeparator(line string) (name string, ok bool) {
+	m := sectionSeparatorRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// SectionIsHot reports whether the rules of section name go to the hot
+// context: the leading "hot" section, and any named section configured
+// with tier: hot.
+func (m *Manager) SectionIsHot(name string) bool {
+	switch name {
+	case HotSection:
+		return true
+	case "", ColdSection:
+		return false
+	}
+	return strings.EqualFold(m.cxConfig().Sections[name].Tier, HotSection)
+}
+
+// RuleSections lists the sections of rules content in order of first
+// appearance, with the line ranges each covers. A section named more than
+// once gets one entry with several ranges. Files are not resolved.
+func (m *Manager) RuleSections(content []byte) []RuleSection {
+	cfg := m.cxConfig().Sections
+	sections := []RuleSection{{Name: HotSection, Tier: HotSection, Lines: [][2]int{{1, 0}}}}
+	index := map[string]int{HotSection: 0}
+	current := 0
+	seenBare := false
+	for i, line := range strings.Split(string(content), "\n") {
+		name, ok := ParseSectionSeparator(line)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if seenBare {
+				continue
+			}
+			seenBare = true
+			name = ColdSection
+		}
+		lineNum := i + 1
+		sections[current].Lines[len(sections[current].Lines)-1][1] = lineNum - 1
+		idx, seen := index[name]
+		if !seen {
+			tier := ColdSection
+			if m.SectionIsHot(name) {
+				tier = HotSection
+			}
+			sections = append(sections, RuleSection{Name: name, Tier: tier, Output: cfg[name].Output})
+			idx = len(sections) - 1
+			index[name] = idx
+		}
+		sections[idx].Lines = append(sections[idx].Lines, [2]int{lineNum + 1, 0})
+		current = idx
+	}
+	return sections
+}
+
+// contains reports whether line falls in one of the section's ranges.
+func (s *RuleSection) contains(line int) bool {
+	for _, r := range s.Lines {
+		if line >= r[0] && (r[1] == 0 || line <= r[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSectionFiles resolves the rules file at rulesPath (the active one
+// when empty) and returns its sections with their files. A section's files
+// are those its own rules match that ended up in its tier, so a file a
+// cold section also claims is not listed under a hot one. Rules pulled in
+// by imports belong to the section of the importing line.
+func (m *Manager) ResolveSectionFiles(rulesPath string) ([]RuleSection, error) {
+	var content []byte
+	var err error
+	if rulesPath == "" {
+		if content, rulesPath, err = m.LoadRulesContent(); err != nil {
+			return nil, err
+		}
+		if content == nil || rulesPath == "" {
+			return nil, nil
+		}
+	} else {
+		if !filepath.IsAbs(rulesPath) {
+			rulesPath = filepath.Join(m.workDir, rulesPath)
+		}
+		if content, err = m.readRulesFile(rulesPath); err != nil {
+			return nil, fmt.Errorf("failed to read rules file: %w", err)
+		}
+	}
+
+	hotRules, coldRules, _, _, err := m.expandAllRules(rulesPath, newExpansionRun(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve patterns: %w", err)
+	}
+	stats := newStatCache()
+	hotFiles, coldFiles, _, err := m.resolveSections(hotRules, coldRules, stats)
+	if err != nil {
+		return nil, err
+	}
+	hotFiles, coldFiles = m.applyFileDirectives(hotFiles, coldFiles)
+	inTier := map[string]map[string]bool{HotSection: {}, ColdSection: {}}
+	for _, f := range hotFiles {
+		inTier[HotSection][f] = true
+	}
+	for _, f := range coldFiles {
+		inTier[ColdSection][f] = true
+	}
+
+	sections := m.RuleSections(content)
+	for i := range sections {
+		s := &sections[i]
+		var rules []RuleInfo
+		for _, r := range append(append([]RuleInfo{}, hotRules...), coldRules...) {
+			if s.contains(r.EffectiveLineNum) {
+				rules = append(rules, r)
+			}
+		}
+		files, _, err := m.resolveRulesViaAST(rules, stats)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving section %s: %w", s.Name, err)
+		}
+		s.Files = []string{}
+		for _, f := range files {
+			if inTier[s.Tier][f] {
+				s.Files = append(s.Files, f)
+			}
+		}
+	}
+	return sections, nil
+}
+
+// generateSectionArtifacts writes the artifact of every section of the
+// rules at rulesPath (the active ones when empty) that has an output
+// configured in cx.sections.
+func (m *Manager) generateSectionArtifacts(rulesPath string) error {
+	configured := false
+	for _, sc := range m.cxConfig().Sections {
+		configured = configured || sc.Output != ""
+	}
+	if !configured {
+		return nil
+	}
+	sections, err := m.ResolveSectionFiles(rulesPath)
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if s.Output == "" {
+			continue
+		}
+		path := s.Output
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		if err := m.writeSectionArtifact(path, s); err != nil {
+			return err
+		}
+		m.ulog.Success("Generated section context").
+			Field("section", s.Name).
+			Field("path", path).
+			Field("file_count", len(s.Files)).
+			Log(context.Background())
+	}
+	return nil
+}
+
+// writeSectionArtifact writes one section's files to path in the XML
+// format of the hot and cold artifacts.
+func (m *Manager) writeSectionArtifact(path string, s RuleSection) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	defer f.Close()
+	defer m.useTierTransforms(s.Tier)()
+
+	fmt.Fprintf(f, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(f, "<context>\n")
+	fmt.Fprintf(f, "  <section name=\"%s\" tier=\"%s\" files=\"%d\">\n", s.Name, s.Tier, len(s.Files))
+	for _, file := range s.Files {
+		if err := m.writeFileToXML(f, file, "    "); err != nil {
+			Warnf("could not read %s: %v", file, err)
+		}
+	}
+	fmt.Fprintf(f, "  </section>\n")
+	fmt.Fprintf(f, "</context>\n")
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return m.sealArtifact(path)
+}
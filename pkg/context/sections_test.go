@@ -0,0 +1,58 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSectionSeparator(t *testing.T) {
+	tests := []struct {
+		line string
+		name string
+		ok   bool
+	}{
+		{line: "---", ok: true},
+		{line: "  ---  ", ok: true},
+		{line: "--- cold", name: "cold", ok: true},
+		{line: "---   reference", name: "reference", ok: true},
+		{line: "--- two words"},
+		{line: "----"},
+		{line: "---reference"},
+		{line: "src/---/x.go"},
+	}
+	for _, tt := range tests {
+		name, ok := ParseSectionSeparator(tt.line)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("ParseSectionSeparator(%q) = %q, %v; want %q, %v", tt.line, name, ok, tt.name, tt.ok)
+		}
+	}
+}
+
+// TestRuleSections verifies that a bare separator starts the cold section,
+// named separators start their own, a repeated name resumes its section,
+// and later bare separators are ignored.
+func TestRuleSections(t *testing.T) {
+	m := &Manager{workDir: t.TempDir()}
+	content := []byte("a.go\n---\nb.go\n--- reference\nc.go\n--- cold\nd.go\n---\ne.go\n")
+	got := m.RuleSections(content)
+	want := []RuleSection{
+		{Name: HotSection, Tier: HotSection, Lines: [][2]int{{1, 1}}},
+		{Name: ColdSection, Tier: ColdSection, Lines: [][2]int{{3, 3}, {7, 0}}},
+		{Name: "reference", Tier: ColdSection, Lines: [][2]int{{5, 5}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RuleSections() = %+v, want %+v", got, want)
+	}
+	if !got[1].contains(9) || got[2].contains(7) || !got[0].contains(1) {
+		t.Errorf("unexpected section membership: %+v", got)
+	}
+}
+
+func TestSectionIsHot(t *testing.T) {
+	m := &Manager{workDir: t.TempDir()}
+	for name, want := range map[string]bool{HotSection: true, ColdSection: false, "": false, "reference": false} {
+		if got := m.SectionIsHot(name); got != want {
+			t.Errorf("SectionIsHot(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package context
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/grovetools/core/pkg/profiling"
+	"github.com/grovetools/core/util/pathutil"
+)
+
+// ResolutionSession is one resolution of the active rules file. The rules are
+// loaded, expanded and walked once, and the hot and cold lists, tree paths and
+// the project classification behind cx list, cx stats and cx view are all read
+// from it. Commands that need more than one of these should build a single
+// session per invocation instead of calling ResolveFilesFromRules,
+// ResolveColdContextFiles and ClassifyAllProjectFiles, each of which resolves
+// the rules from scratch.
+type ResolutionSession struct {
+	RulesPath    string
+	RulesContent []byte   // nil when there is no active or default rules file
+	Hot          []string // hot files, after cx: frontmatter tiers
+	Cold         []string // cold files, after cx: frontmatter tiers
+	Trees        []string // @tree: paths
+
+	m         *Manager
+	hotRules  []RuleInfo
+	coldRules []RuleInfo
+	excluded  []string // files an exclusion rule removed from the final context
+	stats     *statCache
+
+	// Canonical paths (see canonicalFilePath) of Hot, Cold and excluded,
+	// index for index.
+	hotKeys, coldKeys, excludedKeys []string
+
+	classifyMu sync.Mutex
+	classified map[bool]map[string]NodeStatus // by showGitIgnored
+}
+
+// NewResolutionSession resolves the active rules file once.
+func (m *Manager) NewResolutionSession() (*ResolutionSession, error) {
+	defer profiling.Start("context.NewResolutionSession").Stop()
+	s := &ResolutionSession{m: m, Hot: []string{}, Cold: []string{}, stats: newStatCache()}
+
+	rulesContent, activeRulesFile, err := m.LoadRulesContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+	if rulesContent == nil || activeRulesFile == "" {
+		// No active or default rules found
+		return s, nil
+	}
+	s.RulesPath, s.RulesContent = activeRulesFile, rulesContent
+
+	hotRules, coldRules, _, treePaths, err := m.expandAllRules(activeRulesFile, newExpansionRun(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve patterns: %w", err)
+	}
+	s.hotRules, s.coldRules = hotRules, coldRules
+	s.Trees = deduplicateStrings(treePaths)
+
+	hotFiles, coldFiles, excluded, err := m.resolveSections(hotRules, coldRules, s.stats)
+	if err != nil {
+		return nil, err
+	}
+	hotFiles, coldFiles = m.applyFileDirectives(hotFiles, coldFiles)
+	s.unifySpellings(hotFiles, coldFiles, excluded)
+	return s, nil
+}
+
+// unifySpellings sets the session's files, keeping one spelling per
+// canonical path. A project reachable both through a symlinked workspace
+// root and its real path resolves some files under both spellings, possibly
+// one in each tier; as in resolveSections, cold wins over hot, and a file
+// in either is not reported as excluded. The first spelling seen is kept.
+func (s *ResolutionSession) unifySpellings(hot, cold, excluded []string) {
+	seen := make(map[string]bool, len(hot)+len(cold)+len(excluded))
+	unify := func(files []string) (kept, keys []string) {
+		kept = []string{}
+		for _, f := range files {
+			key := s.m.canonicalFilePath(f)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			kept = append(kept, f)
+			keys = append(keys, key)
+		}
+		return kept, keys
+	}
+	s.Cold, s.coldKeys = unify(cold)
+	s.Hot, s.hotKeys = unify(hot)
+	s.excluded, s.excludedKeys = unify(excluded)
+}
+
+// canonicalFilePath makes a resolved file path absolute (relative ones are
+// under workDir) and normalizes it for lookup: symlinks resolved and, on
+// case-insensitive filesystems, case folded.
+func (m *Manager) canonicalFilePath(f string) string {
+	if !filepath.IsAbs(f) {
+		f = filepath.Join(m.workDir, f)
+	}
+	if canonical, err := pathutil.NormalizeForLookup(f); err == nil {
+		return canonical
+	}
+	return f
+}
+
+// Manager returns the manager the session was resolved with.
+func (s *ResolutionSession) Manager() *Manager {
+	return s.m
+}
+
+// Classify returns the status of every file and directory under the roots
+// the rules walk; see ClassifyAllProjectFiles. The result is computed once
+// per showGitIgnored value and shared, so callers must not modify it.
+func (s *ResolutionSession) Classify(showGitIgnored bool) (map[string]NodeStatus, error) {
+	s.classifyMu.Lock()
+	defer s.classifyMu.Unlock()
+	if result, ok := s.classified[showGitIgnored]; ok {
+		return result, nil
+	}
+	result, err := s.m.classifyProjectFiles(s, showGitIgnored)
+	if err != nil {
+		return nil, err
+	}
+	if s.classified == nil {
+		s.classified = make(map[bool]map[string]NodeStatus)
+	}
+	s.classified[showGitIgnored] = result
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grovetools/core/util/pathutil"
+)
+
+func TestResolutionSession(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("pkg/a.go", "package pkg\n")
+	write("pkg/a_test.go", "package pkg\n")
+	write("docs/guide.md", "# guide\n")
+	write("notes.txt", "unmatched\n")
+
+	m := NewManager(dir, WithRules([]byte("pkg/**/*.go\n!**/*_test.go\n---\ndocs/*.md\n")), WithNoState())
+	s, err := m.NewResolutionSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hot, err := m.ResolveFilesFromRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cold, err := m.ResolveColdContextFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Hot, hot) || !reflect.DeepEqual(s.Cold, cold) {
+		t.Errorf("session hot/cold = %v/%v, want %v/%v", s.Hot, s.Cold, hot, cold)
+	}
+	if !reflect.DeepEqual(s.Hot, []string{"pkg/a.go"}) || !reflect.DeepEqual(s.Cold, []string{"docs/guide.md"}) {
+		t.Errorf("unexpected session files: hot %v, cold %v", s.Hot, s.Cold)
+	}
+
+	statuses, err := s.Classify(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(rel string) string {
+		p, err := pathutil.NormalizeForLookup(filepath.Join(dir, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for rel, want := range map[string]NodeStatus{
+		"pkg/a.go":      StatusIncludedHot,
+		"pkg/a_test.go": StatusExcludedByRule,
+		"docs/guide.md": StatusIncludedCold,
+	} {
+		if got := statuses[key(rel)]; got != want {
+			t.Errorf("status of %s = %v, want %v", rel, got, want)
+		}
+	}
+
+	again, err := s.Classify(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.ValueOf(again).Pointer() != reflect.ValueOf(statuses).Pointer() {
+		t.Error("Classify should be computed once per session")
+	}
+}
+
+// TestResolutionSession_SymlinkedEcosystem covers a project entered through
+// a symlinked workspace root whose rules also name files by their real
+// path: each file must be listed, and classified, once.
+func TestResolutionSession_SymlinkedEcosystem(t *testing.T) {
+	root := t.TempDir()
+	realDir := filepath.Join(root, "eco")
+	link := filepath.Join(root, "eco-link")
+	fsWriteString(t, filepath.Join(realDir, "proj", "a.go"), "package proj\n")
+	fsWriteString(t, filepath.Join(realDir, "proj", "b.go"), "package proj\n")
+	fsWriteString(t, filepath.Join(realDir, "README.md"), "# eco\n")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(realDir, "proj"), filepath.Join(realDir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := "proj/a.go\n*.md\n---\n" + filepath.Join(realDir, "proj", "a.go") + "\n" + filepath.Join(realDir, "proj", "b.go") + "\n"
+	m := NewManager(link, WithRules([]byte(rules)), WithNoState(), WithAllowedRoots(realDir))
+	s, err := m.NewResolutionSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Hot, []string{"README.md"}) {
+		t.Errorf("hot = %v, want [README.md]: proj/a.go is cold under its real path", s.Hot)
+	}
+	if len(s.Cold) != 2 {
+		t.Errorf("cold = %v, want a.go and b.go once each", s.Cold)
+	}
+
+	statuses, err := s.Classify(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(path string) string {
+		p, err := pathutil.NormalizeForLookup(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for path, want := range map[string]NodeStatus{
+		filepath.Join(link, "proj", "a.go"):    StatusIncludedCold,
+		filepath.Join(realDir, "proj", "b.go"): StatusIncludedCold,
+		filepath.Join(link, "README.md"):       StatusIncludedHot,
+		filepath.Join(realDir, "linked"):       StatusDirectory, // walked before proj/, it must not turn proj/ into a file
+	} {
+		if got := statuses[key(path)]; got != want {
+			t.Errorf("status of %s = %v, want %v", path, got, want)
+		}
+	}
+	for path := range statuses {
+		if strings.HasPrefix(path, link+string(filepath.Separator)) {
+			t.Errorf("classification holds the symlinked spelling %s", path)
+		}
+	}
+}
+
+func TestUnifySpellings(t *testing.T) {
+	root := t.TempDir()
+	realDir := filepath.Join(root, "real")
+	link := filepath.Join(root, "link")
+	for _, f := range []string{"a.go", "b.go", "c.go"} {
+		fsWriteString(t, filepath.Join(realDir, f), "package x\n")
+	}
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	s := &ResolutionSession{m: &Manager{workDir: realDir}}
+	s.unifySpellings(
+		[]string{"a.go", filepath.Join(link, "b.go"), "b.go"},
+		[]string{filepath.Join(link, "a.go")},
+		[]string{filepath.Join(realDir, "b.go"), "c.go"},
+	)
+	if !reflect.DeepEqual(s.Cold, []string{filepath.Join(link, "a.go")}) ||
+		!reflect.DeepEqual(s.Hot, []string{filepath.Join(link, "b.go")}) ||
+		!reflect.DeepEqual(s.excluded, []string{"c.go"}) {
+		t.Errorf("unifySpellings() hot %v, cold %v, excluded %v", s.Hot, s.Cold, s.excluded)
+	}
+	if len(s.hotKeys) != len(s.Hot) || len(s.coldKeys) != len(s.Cold) || len(s.excludedKeys) != len(s.excluded) {
+		t.Error("canonical keys out of step with the file lists")
+	}
+}
@@ -0,0 +1,96 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// errSpecialFile is returned when a context file turns out to be a socket,
+// named pipe or device node. Reading one can block forever (a FIFO with no
+// writer) or never reach EOF (/dev/zero), so they are never opened.
+var errSpecialFile = errors.New("not a regular file")
+
+// specialFileKind names the kind of special file mode describes, or returns
+// "" for regular files, directories and symlinks.
+func specialFileKind(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&fs.ModeCharDevice != 0:
+		return "character device"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	case mode&fs.ModeIrregular != 0:
+		return "irregular file"
+	}
+	return ""
+}
+
+// specialEntryKind is specialFileKind for a walk entry. Symlinks are
+// followed, since opening one opens its target.
+func specialEntryKind(path string, d fs.DirEntry) string {
+	mode := d.Type()
+	if mode&fs.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return ""
+		}
+		mode = info.Mode()
+	}
+	return specialFileKind(mode)
+}
+
+// openRegular opens path for reading, refusing special files.
+func openRegular(path string) (*os.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, describePathError(path, err)
+	}
+	if kind := specialFileKind(info.Mode()); kind != "" {
+		return nil, fmt.Errorf("%s is a %s: %w", path, kind, errSpecialFile)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, describePathError(path, err)
+	}
+	return f, nil
+}
+
+// readRegularFile is os.ReadFile that refuses special files.
+func readRegularFile(path string) ([]byte, error) {
+	f, err := openRegular(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// maxPathLength is the longest path the platform reliably accepts: MAX_PATH
+// on Windows (without long-path support enabled), PATH_MAX elsewhere.
+func maxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		return 260
+	case "darwin":
+		return 1024
+	}
+	return 4096
+}
+
+// describePathError points out when err is down to the length of path, which
+// the raw error ("file name too long", or a bare "not found" on Windows)
+// does not make obvious.
+func describePathError(path string, err error) error {
+	if errors.Is(err, syscall.ENAMETOOLONG) || len(path) >= maxPathLength() {
+		return fmt.Errorf("path is %d characters, over the %s limit of %d: %w", len(path), runtime.GOOS, maxPathLength(), err)
+	}
+	return err
+}
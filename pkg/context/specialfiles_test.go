@@ -0,0 +1,55 @@
+package context
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestSpecialFilesAreSkipped(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer l.Close()
+
+	m := NewManager(dir, WithNoState())
+	files, err := m.resolveFilesViaAST([]RuleInfo{{Pattern: "*", LineNum: 1, EffectiveLineNum: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, []string{"a.txt"}) {
+		t.Errorf("files = %v, want [a.txt]", files)
+	}
+	if w := m.GetWalkWarnings(); len(w) != 1 || !strings.Contains(w[0].Err, "socket") {
+		t.Errorf("walk warnings = %+v, want one for the socket", w)
+	}
+
+	if _, err := readRegularFile(sock); !errors.Is(err, errSpecialFile) {
+		t.Errorf("readRegularFile(socket) error = %v, want errSpecialFile", err)
+	}
+	if grepFile(sock, []grepClause{{matcher: &grepMatcher{literal: []byte("x")}}}) {
+		t.Error("a socket should match no grep directive")
+	}
+}
+
+func TestDescribePathError(t *testing.T) {
+	long := "/" + strings.Repeat("a", maxPathLength())
+	err := describePathError(long, syscall.ENAMETOOLONG)
+	if !errors.Is(err, syscall.ENAMETOOLONG) || !strings.Contains(err.Error(), "characters") {
+		t.Errorf("describePathError = %v", err)
+	}
+	if err := describePathError("short", os.ErrNotExist); err != os.ErrNotExist {
+		t.Errorf("short paths should keep their error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Partial regeneration (cx generate --only) re-renders just the files that
+// match the given patterns and splices every other file's block verbatim
+// from the existing artifact, which doubles as the manifest of what was
+// rendered last time. Rules are still resolved in full so the file set and
+// order stay exact; only file reads (and comment stripping) are skipped.
+
+// SetOnlyPatterns restricts the next generation to re-rendering files that
+// match one of patterns; other files are copied from the existing artifact
+// when it has them. Nil restores full regeneration. Like SetStripComments,
+// set this only on an instance you own exclusively.
+func (m *Manager) SetOnlyPatterns(patterns []string) {
+	m.onlyPatterns = patterns
+}
+
+// matchesOnly reports whether file must be re-rendered under the current
+// --only patterns.
+func (m *Manager) matchesOnly(file string) bool {
+	rel := file
+	if filepath.IsAbs(file) {
+		if r, err := filepath.Rel(m.workDir, file); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range m.onlyPatterns {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		if !strings.ContainsAny(p, "*?[") {
+			// Plain path: the file itself or anything under the directory.
+			if rel == p || strings.HasPrefix(rel, p+"/") {
+				return true
+			}
+			continue
+		}
+		if matchRulePattern(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// spliceSource returns the reusable file blocks of the artifact at path, or
+// nil when partial regeneration is off or the artifact cannot be reused (in
+// which case every file is rendered afresh).
+func (m *Manager) spliceSource(path string) map[string][]byte {
+	if len(m.onlyPatterns) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.ulog.Warn("No existing artifact to splice into; regenerating in full").
+			Field("path", path).
+			Log(m.Context())
+		return nil
+	}
+	blocks, err := artifactFileBlocks(data)
+	if err != nil {
+		m.ulog.Warn("Existing artifact cannot be spliced; regenerating in full").
+			Field("path", path).
+			Err(err).
+			Log(m.Context())
+		return nil
+	}
+	return blocks
+}
+
+// artifactFileBlocks indexes the <file path="..."> blocks of an XML-format
+// artifact by path. Each block includes its opening and closing tag lines.
+func artifactFileBlocks(data []byte) (map[string][]byte, error) {
+	blocks := make(map[string][]byte)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	var current string
+	var block bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if current == "" {
+			if m := xmlFileOpenRe.FindStringSubmatch(line); m != nil {
+				current = m[1]
+				block.Reset()
+				block.WriteString(line + "\n")
+			}
+			continue
+		}
+		block.WriteString(line + "\n")
+		if xmlFileCloseRe.MatchString(line) {
+			blocks[current] = append([]byte(nil), block.Bytes()...)
+			current = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != "" {
+		return nil, fmt.Errorf("unterminated block for %s", current)
+	}
+	return blocks, nil
+}
+
+// splicedBlock returns the existing block to reuse for file, if any.
+func (m *Manager) splicedBlock(blocks map[string][]byte, file string) ([]byte, bool) {
+	if blocks == nil || m.matchesOnly(file) {
+		return nil, false
+	}
+	block, ok := blocks[file]
+	return block, ok
+}
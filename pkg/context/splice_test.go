@@ -0,0 +1,86 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateOnlySplicesUnmatchedFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("api/a.go", "package api // v1\n")
+	write("web/b.go", "package web // v1\n")
+	write("docs/c.md", "cold v1\n")
+	write(".grove/.keep", "")
+	rulesPath := filepath.Join(dir, "rules")
+	write("rules", "**/*.go\n---\ndocs/*.md\n")
+
+	grove := filepath.Join(dir, ".grove")
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(grove, "context"), filepath.Join(grove, "cached-context"),
+		filepath.Join(grove, "context-files"), filepath.Join(grove, "cached-context-files"))
+	if err := m.GenerateContextFromRulesFile(rulesPath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	write("api/a.go", "package api // v2\n")
+	write("web/b.go", "package web // v2\n")
+	write("docs/c.md", "cold v2\n")
+	m.SetOnlyPatterns([]string{"api"})
+	if err := m.GenerateContextFromRulesFile(rulesPath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	hot, err := os.ReadFile(m.ResolveContextPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(hot), "package api // v2") {
+		t.Error("files matching --only should be re-rendered")
+	}
+	if !strings.Contains(string(hot), "package web // v1") {
+		t.Error("files outside --only should be spliced from the existing artifact")
+	}
+	cold, err := os.ReadFile(m.ResolveCachedContextPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(cold), "cold v1") {
+		t.Error("cold files outside --only should be spliced too")
+	}
+
+	m.SetOnlyPatterns(nil)
+	if err := m.GenerateContextFromRulesFile(rulesPath, true); err != nil {
+		t.Fatal(err)
+	}
+	if hot, _ := os.ReadFile(m.ResolveContextPath()); !strings.Contains(string(hot), "package web // v2") {
+		t.Error("full regeneration should re-render every file")
+	}
+}
+
+func TestArtifactFileBlocks(t *testing.T) {
+	data := "<context>\n    <file path=\"a.go\">\nline\n    </file>\n    <file path=\"b.go\">\n    </file>\n</context>\n"
+	blocks, err := artifactFileBlocks([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(blocks["a.go"]); got != "    <file path=\"a.go\">\nline\n    </file>\n" {
+		t.Errorf("unexpected block for a.go: %q", got)
+	}
+	if _, ok := blocks["b.go"]; !ok {
+		t.Error("empty files should still produce a block")
+	}
+	if _, err := artifactFileBlocks([]byte("<file path=\"x\">\nno end\n")); err == nil {
+		t.Error("expected an error for an unterminated block")
+	}
+}
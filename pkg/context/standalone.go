@@ -0,0 +1,61 @@
+package context
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// Standalone mode.
+//
+// A standalone cx is a plain local context generator for repos and CI images
+// without a grove install: every Manager the CLI builds is a WithNoState one,
+// so grove.yml/grove.toml, .grove/state, plans, notebooks, the daemon and
+// workspace discovery are never consulted. Rules come from CX_RULES_FILE
+// when set and otherwise from the working directory's own .grove/rules (or
+// a legacy .grovectx), context is drawn from the working directory only,
+// and @a: aliases are errors.
+//
+// It is enabled by --standalone, by CX_STANDALONE=1, or at build time with
+// `make build-standalone`, which links a static binary with
+// standaloneBuild set. CX_STANDALONE=0 turns it off again in such a build.
+
+// standaloneBuild is set at link time (-X) by `make build-standalone`.
+var standaloneBuild string
+
+// standaloneDefault is the --standalone flag; see SetStandalone.
+var standaloneDefault atomic.Bool
+
+// ErrStandalone is returned by operations that need grove state, such as
+// switching the active rule set, when cx runs standalone.
+var ErrStandalone = errors.New("not available in standalone mode (grove state is not used)")
+
+// SetStandalone sets the process-wide standalone default. The CLI sets it
+// from --standalone.
+func SetStandalone(standalone bool) {
+	standaloneDefault.Store(standalone)
+}
+
+// Standalone reports whether cx runs standalone: CX_STANDALONE when set,
+// otherwise --standalone or a standalone build.
+func Standalone() bool {
+	if v, ok := envBool(StandaloneEnvVar); ok {
+		return v
+	}
+	return standaloneDefault.Load() || standaloneBuild != ""
+}
+
+// newStandaloneManager builds the Manager NewManager returns in standalone
+// mode: a WithNoState one that still honours CX_RULES_FILE, the one way to
+// pick a rules file without state.
+func newStandaloneManager(workDir string) *Manager {
+	m := newManagerWithOptions(workDir, []ManagerOption{WithNoState()})
+	if path := os.Getenv(RulesFileEnvVar); path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		m.rulesFileOverride = path
+	}
+	return m
+}
@@ -0,0 +1,45 @@
+package context
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStandaloneMode(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(StandaloneEnvVar, "1")
+	t.Setenv(RulesFileEnvVar, "")
+
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "grove.yml"), "version: 1.0\ncx:\n  token_budget: 50000\n")
+	fsWriteString(t, filepath.Join(dir, ActiveRulesFile), "*.go\n")
+	fsWriteString(t, filepath.Join(dir, "ci.rules"), "*.md\n")
+
+	m := NewManager(dir)
+	if !m.noState {
+		t.Fatal("standalone NewManager should build a WithNoState manager")
+	}
+	if budget := LoadCxConfig(dir).TokenBudget; budget != 0 {
+		t.Errorf("standalone LoadCxConfig read grove.yml: token_budget = %d", budget)
+	}
+	if _, path, err := m.LoadRulesContent(); err != nil || path != filepath.Join(dir, ActiveRulesFile) {
+		t.Errorf("LoadRulesContent() path = %q, %v; want the local rules file", path, err)
+	}
+
+	t.Setenv(RulesFileEnvVar, "ci.rules")
+	if _, path, err := NewManager(dir).LoadRulesContent(); err != nil || path != filepath.Join(dir, "ci.rules") {
+		t.Errorf("LoadRulesContent() path = %q, %v; want CX_RULES_FILE", path, err)
+	}
+
+	if err := SetGroupEnabled(dir, "tests", false); !errors.Is(err, ErrStandalone) {
+		t.Errorf("SetGroupEnabled() = %v, want ErrStandalone", err)
+	}
+
+	t.Setenv(StandaloneEnvVar, "0")
+	SetStandalone(true)
+	defer SetStandalone(false)
+	if Standalone() {
+		t.Error("CX_STANDALONE=0 should override --standalone")
+	}
+}
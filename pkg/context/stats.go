@@ -11,12 +11,14 @@ import (
 	grovelogging "github.com/grovetools/core/logging"
 	"github.com/grovetools/core/pkg/profiling"
 	core_theme "github.com/grovetools/core/tui/theme"
+	"github.com/grovetools/cx/pkg/lang"
 	"github.com/sirupsen/logrus"
 )
 
 // LanguageStats contains statistics for a programming language
 type LanguageStats struct {
 	Name        string   `json:"name"`
+	Language    string   `json:"language,omitempty"` // Detected fence tag (see pkg/lang), e.g. "go"
 	FileCount   int      `json:"file_count"`
 	TotalTokens int      `json:"total_tokens"`
 	Percentage  float64  `json:"percentage"`
@@ -52,6 +54,9 @@ type ContextStats struct {
 	Distribution  []TokenDistribution       `json:"distribution"`
 	AvgTokens     int                       `json:"avg_tokens"`
 	MedianTokens  int                       `json:"median_tokens"`
+	// Thresholds are the project's token thresholds (see thresholds.go),
+	// used to flag large files.
+	Thresholds TokenThresholds `json:"thresholds"`
 }
 
 // GetStats analyzes the context and returns comprehensive statistics
@@ -64,19 +69,21 @@ func (m *Manager) GetStats(contextType string, files []string, topN int) (*Conte
 	}).Debug("Calculating context statistics")
 
 	if len(files) == 0 {
-		return &ContextStats{ContextType: contextType}, nil
+		return &ContextStats{ContextType: contextType, Thresholds: m.TokenThresholds()}, nil
 	}
 
 	stats := &ContextStats{
 		ContextType: contextType,
 		TotalFiles:  len(files),
 		Languages:   make(map[string]*LanguageStats),
+		Thresholds:  m.TokenThresholds(),
 	}
 
 	var allFiles []FileStats
 	var tokenCounts []int
 
 	statsProvider := GetStatsProvider()
+	detector := m.LanguageDetector()
 
 	// Collect file information
 	for _, file := range files {
@@ -114,7 +121,7 @@ func (m *Manager) GetStats(contextType string, files []string, topN int) (*Conte
 		}
 
 		if _, exists := stats.Languages[lang]; !exists {
-			stats.Languages[lang] = &LanguageStats{Name: lang}
+			stats.Languages[lang] = &LanguageStats{Name: lang, Language: detector.DetectFile(statsPath)}
 		}
 		stats.Languages[lang].FileCount++
 		stats.Languages[lang].TotalTokens += fs.Tokens
@@ -187,6 +194,12 @@ func GetExtsFromLanguage(extName string) []string {
 	return []string{extName}
 }
 
+// LanguageDetector returns the language detector for this workspace: the
+// built-in tables extended by the `cx.languages` mapping in grove.yml.
+func (m *Manager) LanguageDetector() *lang.Detector {
+	return lang.New(m.cxConfig().Languages)
+}
+
 // getLanguageFromExt returns the file extension as-is for grouping
 func getLanguageFromExt(ext string) string {
 	if ext == "" {
@@ -282,7 +295,7 @@ func (s *ContextStats) String(title string) string {
 	})
 
 	for _, lang := range languages {
-		langName := theme.Info.Render(fmt.Sprintf("%-12s", lang.Name))
+		langName := theme.Info.Render(PadWidth(lang.Name, 12))
 		percentage := theme.Highlight.Render(fmt.Sprintf("%5.1f%%", lang.Percentage))
 		details := theme.Muted.Render(fmt.Sprintf(
 			"(%s tokens, %d files)",
@@ -293,24 +306,28 @@ func (s *ContextStats) String(title string) string {
 	}
 
 	// Largest files
+	thresholds := s.Thresholds
+	if thresholds == (TokenThresholds{}) {
+		thresholds = DefaultTokenThresholds
+	}
 	b.WriteString("\n" + theme.Header.Render("Largest Files (by tokens):") + "\n")
 	for i, file := range s.LargestFiles {
-		displayPath := file.Path
-		if len(displayPath) > 50 {
-			displayPath = "..." + displayPath[len(displayPath)-47:]
-		}
+		displayPath := PadWidth(TruncateWidthLeft(file.Path, 50, "..."), 50)
 
 		var tokenStyle lipgloss.Style
-		if file.Tokens > 10000 {
+		switch thresholds.Level(file.Tokens) {
+		case TokenLevelError:
 			tokenStyle = theme.Error
-		} else if file.Tokens > 5000 {
+		case TokenLevelWarn:
 			tokenStyle = theme.Warning
-		} else {
+		case TokenLevelNotice:
+			tokenStyle = theme.Highlight
+		default:
 			tokenStyle = theme.Info
 		}
 
 		line := fmt.Sprintf(
-			"  %2d. %-50s %s (%4.1f%%)",
+			"  %2d. %s %s (%4.1f%%)",
 			i+1,
 			displayPath,
 			tokenStyle.Render(FormatTokenCount(file.Tokens)+" tokens"),
@@ -319,6 +336,16 @@ func (s *ContextStats) String(title string) string {
 		b.WriteString(line + "\n")
 	}
 
+	large := 0
+	for _, file := range s.AllFiles {
+		if thresholds.Level(file.Tokens) >= TokenLevelWarn {
+			large++
+		}
+	}
+	if large > 0 {
+		b.WriteString(theme.Warning.Render(fmt.Sprintf("  ⚠ %d file(s) at or over %s tokens", large, FormatTokenCount(thresholds.Warn))) + "\n")
+	}
+
 	// Token distribution
 	b.WriteString("\n" + theme.Header.Render("Token Distribution:") + "\n")
 	for _, dist := range s.Distribution {
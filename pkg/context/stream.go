@@ -0,0 +1,18 @@
+package context
+
+import "io"
+
+// StreamContext writes the hot context that a generation from rulesFilePath
+// (or the active rules, when empty) would produce to w, instead of to the
+// context file. No artifact, files list, checksum or cold context is written,
+// so `cx generate --stdout` can feed another tool without leaving
+// intermediate files behind. Resolution, @require: checks and the token
+// budget apply as for a normal generation.
+func (m *Manager) StreamContext(w io.Writer, rulesFilePath string, useXMLFormat bool) error {
+	m.stream = w
+	defer func() { m.stream = nil }()
+	if rulesFilePath != "" {
+		return m.GenerateContextFromRulesFile(rulesFilePath, useXMLFormat)
+	}
+	return m.GenerateContext(useXMLFormat)
+}
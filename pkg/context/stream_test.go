@@ -0,0 +1,44 @@
+package context
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStreamContext verifies that streaming writes the hot context to the
+// writer and leaves no artifacts behind.
+func TestStreamContext(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "api", "a.go"), "package api\n")
+	fsWriteString(t, filepath.Join(dir, "docs", "c.md"), "cold\n")
+	rulesPath := filepath.Join(dir, "stream.rules")
+	fsWriteString(t, rulesPath, "**/*.go\n---\ndocs/*.md\n")
+
+	grove := filepath.Join(dir, ".grove")
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(grove, "context"), filepath.Join(grove, "cached-context"),
+		filepath.Join(grove, "context-files"), filepath.Join(grove, "cached-context-files"))
+
+	var buf bytes.Buffer
+	if err := m.StreamContext(&buf, rulesPath, true); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<hot-context files="1"`) || !strings.Contains(out, "package api") {
+		t.Errorf("unexpected stream:\n%s", out)
+	}
+	if strings.Contains(out, "docs/c.md") {
+		t.Errorf("cold files should not be streamed:\n%s", out)
+	}
+	for _, name := range []string{"context", "cached-context", "context-files", "cached-context-files"} {
+		if _, err := os.Stat(filepath.Join(grove, name)); !os.IsNotExist(err) {
+			t.Errorf("streaming should not write .grove/%s", name)
+		}
+	}
+	if got := m.LastGeneration().HotFiles; got != 1 {
+		t.Errorf("LastGeneration().HotFiles = %d, want 1", got)
+	}
+}
@@ -0,0 +1,213 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Symbol index. `@symbol-index` in the rules appends a compact index of the
+// types and functions declared in the hot files to the hot context, one
+// `kind name -> file:line` entry per symbol, so a model can find a
+// definition without reading every file body:
+//
+//	<symbol-index symbols="2">
+//	  type Manager -> pkg/context/manager.go:62
+//	  func NewManager -> pkg/context/manager.go:210
+//	</symbol-index>
+//
+// Go files are indexed with go/parser. Other files are indexed with
+// universal-ctags when it is on the PATH and skipped otherwise.
+
+const symbolIndexDirective = "@symbol-index"
+
+// Symbol is one entry of the symbol index.
+type Symbol struct {
+	Kind string `json:"kind"` // func, method, type, or the ctags kind
+	Name string `json:"name"` // methods are Recv.Name
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// String formats s as an index entry.
+func (s Symbol) String() string {
+	return fmt.Sprintf("%s %s -> %s:%d", s.Kind, s.Name, s.File, s.Line)
+}
+
+// hasSymbolIndexDirective reports whether the rules ask for a symbol index.
+func hasSymbolIndexDirective(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text()))) == symbolIndexDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// SymbolIndex returns the symbols declared in files, in file order and then
+// by line.
+func (m *Manager) SymbolIndex(files []string) []Symbol {
+	var symbols []Symbol
+	var other []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			other = append(other, file)
+			continue
+		}
+		symbols = append(symbols, goSymbols(m.absPath(file), file)...)
+	}
+	symbols = append(symbols, m.ctagsSymbols(other)...)
+
+	order := make(map[string]int, len(files))
+	for i, f := range files {
+		order[f] = i
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].File != symbols[j].File {
+			return order[symbols[i].File] < order[symbols[j].File]
+		}
+		return symbols[i].Line < symbols[j].Line
+	})
+	return symbols
+}
+
+// absPath resolves file against the working directory.
+func (m *Manager) absPath(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(m.workDir, file)
+}
+
+// goSymbols returns the top-level types and functions of the Go file at
+// path, reported under name. Files that do not parse have no symbols.
+func goSymbols(path, name string) []Symbol {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var symbols []Symbol
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s := Symbol{Kind: "func", Name: d.Name.Name, File: name, Line: fset.Position(d.Pos()).Line}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				s.Kind, s.Name = "method", receiverName(d.Recv.List[0].Type)+"."+d.Name.Name
+			}
+			symbols = append(symbols, s)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				symbols = append(symbols, Symbol{Kind: "type", Name: ts.Name.Name, File: name, Line: fset.Position(ts.Pos()).Line})
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverName returns the base type name of a method receiver.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+// ctagsSymbols indexes files with universal-ctags. It returns nothing when
+// ctags is not installed or is not universal-ctags (only it emits JSON).
+func (m *Manager) ctagsSymbols(files []string) []Symbol {
+	if len(files) == 0 {
+		return nil
+	}
+	ctags, err := exec.LookPath("ctags")
+	if err != nil {
+		return nil
+	}
+	if out, err := exec.Command(ctags, "--version").Output(); err != nil || !bytes.Contains(out, []byte("Universal Ctags")) {
+		return nil
+	}
+	byPath := make(map[string]string, len(files))
+	args := []string{"--output-format=json", "--fields=+n", "-f", "-"}
+	for _, f := range files {
+		abs := m.absPath(f)
+		byPath[abs] = f
+		args = append(args, abs)
+	}
+	out, err := exec.Command(ctags, args...).Output()
+	if err != nil {
+		m.log.WithError(err).Warn("ctags failed; symbol index covers Go files only")
+		return nil
+	}
+	return parseCtagsJSON(bytes.NewReader(out), byPath)
+}
+
+// parseCtagsJSON reads universal-ctags JSON lines, keeping the tags of the
+// paths in byPath under their display names.
+func parseCtagsJSON(r io.Reader, byPath map[string]string) []Symbol {
+	var symbols []Symbol
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var tag struct {
+			Type  string `json:"_type"`
+			Name  string `json:"name"`
+			Path  string `json:"path"`
+			Line  int    `json:"line"`
+			Kind  string `json:"kind"`
+			Scope string `json:"scope"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &tag); err != nil || tag.Type != "tag" {
+			continue
+		}
+		file, ok := byPath[tag.Path]
+		if !ok {
+			continue
+		}
+		name := tag.Name
+		if tag.Scope != "" {
+			name = tag.Scope + "." + name
+		}
+		symbols = append(symbols, Symbol{Kind: tag.Kind, Name: name, File: file, Line: tag.Line})
+	}
+	return symbols
+}
+
+// writeSymbolIndex appends the symbol index of files to a hot context.
+func (m *Manager) writeSymbolIndex(w io.Writer, files []string, useXMLFormat bool) {
+	symbols := m.SymbolIndex(files)
+	if useXMLFormat {
+		fmt.Fprintf(w, "    <symbol-index symbols=\"%d\">\n", len(symbols))
+		for _, s := range symbols {
+			fmt.Fprintf(w, "      %s\n", s)
+		}
+		fmt.Fprintf(w, "    </symbol-index>\n")
+		return
+	}
+	fmt.Fprintf(w, "=== SYMBOL INDEX ===\n")
+	for _, s := range symbols {
+		fmt.Fprintf(w, "%s\n", s)
+	}
+	fmt.Fprintf(w, "=== END SYMBOL INDEX ===\n\n")
+}
@@ -0,0 +1,67 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoSymbols(t *testing.T) {
+	dir := t.TempDir()
+	fsWriteString(t, filepath.Join(dir, "store.go"), `package store
+
+type Store[T any] struct{}
+
+type (
+	Key   string
+	Value []byte
+)
+
+func New() *Store[int] { return nil }
+
+func (s *Store[T]) Get(k Key) Value { return nil }
+
+var unused = 1
+`)
+	m := NewManager(dir, WithNoState())
+	var got []string
+	for _, s := range m.SymbolIndex([]string{"store.go"}) {
+		got = append(got, s.String())
+	}
+	want := []string{
+		"type Store -> store.go:3",
+		"type Key -> store.go:6",
+		"type Value -> store.go:7",
+		"func New -> store.go:10",
+		"method Store.Get -> store.go:12",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SymbolIndex =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseCtagsJSON(t *testing.T) {
+	out := `{"_type": "ptag", "name": "JSON_OUTPUT_VERSION"}
+{"_type": "tag", "name": "greet", "path": "/w/app.py", "line": 4, "kind": "function"}
+{"_type": "tag", "name": "run", "path": "/w/app.py", "line": 9, "kind": "member", "scope": "App"}
+{"_type": "tag", "name": "other", "path": "/elsewhere.py", "line": 1, "kind": "function"}
+`
+	got := parseCtagsJSON(strings.NewReader(out), map[string]string{"/w/app.py": "app.py"})
+	want := []Symbol{
+		{Kind: "function", Name: "greet", File: "app.py", Line: 4},
+		{Kind: "member", Name: "App.run", File: "app.py", Line: 9},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCtagsJSON = %+v, want %+v", got, want)
+	}
+}
+
+func TestHasSymbolIndexDirective(t *testing.T) {
+	if !hasSymbolIndexDirective([]byte("src/**\n@symbol-index # for navigation\n")) {
+		t.Error("directive with a trailing comment not found")
+	}
+	if hasSymbolIndexDirective([]byte("src/**\n# @symbol-index\n")) {
+		t.Error("commented-out directive should not count")
+	}
+}
@@ -0,0 +1,231 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The @tasks directive summarizes a project's entry points — Makefile,
+// Taskfile, package.json scripts and justfile recipes — into one generated
+// markdown file, so the model learns how to build, test and run the repo
+// without the build files themselves:
+//
+//	@tasks            # tasks defined in the project root
+//	@tasks: tools/cli # tasks defined in a subdirectory
+
+// ProjectTask is one runnable target discovered in a build file.
+type ProjectTask struct {
+	Name        string
+	Description string
+}
+
+// TaskSource is the set of tasks defined by one build file.
+type TaskSource struct {
+	File   string // build file name, e.g. "Makefile"
+	Runner string // how to invoke a task, e.g. "make <task>"
+	Tasks  []ProjectTask
+}
+
+var (
+	makeTargetRe   = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=]|$)`)
+	justRecipeRe   = regexp.MustCompile(`^@?([A-Za-z0-9][A-Za-z0-9_-]*)(\s+[^:]*)?:([^=]|$)`)
+	trailingHelpRe = regexp.MustCompile(`##\s*(.+)$`)
+)
+
+// DiscoverTasks finds the build files in dir and returns the tasks each
+// defines, in a fixed order (make, task, npm, just). Unreadable or
+// malformed files are skipped.
+func DiscoverTasks(dir string) []TaskSource {
+	var sources []TaskSource
+	for _, name := range []string{"Makefile", "makefile", "GNUmakefile"} {
+		if tasks := parseMakefileTasks(filepath.Join(dir, name)); len(tasks) > 0 {
+			sources = append(sources, TaskSource{File: name, Runner: "make <task>", Tasks: tasks})
+			break
+		}
+	}
+	for _, name := range []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"} {
+		if tasks := parseTaskfileTasks(filepath.Join(dir, name)); len(tasks) > 0 {
+			sources = append(sources, TaskSource{File: name, Runner: "task <task>", Tasks: tasks})
+			break
+		}
+	}
+	if tasks := parsePackageJSONScripts(filepath.Join(dir, "package.json")); len(tasks) > 0 {
+		sources = append(sources, TaskSource{File: "package.json", Runner: "npm run <task>", Tasks: tasks})
+	}
+	for _, name := range []string{"justfile", "Justfile", ".justfile"} {
+		if tasks := parseJustfileTasks(filepath.Join(dir, name)); len(tasks) > 0 {
+			sources = append(sources, TaskSource{File: name, Runner: "just <task>", Tasks: tasks})
+			break
+		}
+	}
+	return sources
+}
+
+// parseMakefileTasks lists explicit targets, skipping special (.PHONY) and
+// pattern (%) targets. A description comes from a trailing `## help`
+// comment or the comment line directly above the target.
+func parseMakefileTasks(path string) []ProjectTask {
+	return scanRecipeFile(path, makeTargetRe, func(line string) bool {
+		return strings.HasPrefix(line, "\t") || strings.Contains(line, "%")
+	})
+}
+
+// parseJustfileTasks lists justfile recipes, skipping private (_) ones.
+func parseJustfileTasks(path string) []ProjectTask {
+	return scanRecipeFile(path, justRecipeRe, func(line string) bool {
+		return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "_")
+	})
+}
+
+// scanRecipeFile collects the names matched by re in a make-like file,
+// pairing each with a trailing `## ...` or preceding `# ...` comment.
+func scanRecipeFile(path string, re *regexp.Regexp, skip func(string) bool) []ProjectTask {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var tasks []ProjectTask
+	seen := make(map[string]bool)
+	comment := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(line, "\t") {
+			comment = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		if skip(line) {
+			comment = ""
+			continue
+		}
+		m := re.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			comment = ""
+			continue
+		}
+		desc := comment
+		if help := trailingHelpRe.FindStringSubmatch(line); help != nil {
+			desc = strings.TrimSpace(help[1])
+		}
+		seen[m[1]] = true
+		tasks = append(tasks, ProjectTask{Name: m[1], Description: desc})
+		comment = ""
+	}
+	return tasks
+}
+
+// parseTaskfileTasks lists the tasks of a go-task Taskfile with their desc.
+func parseTaskfileTasks(path string) []ProjectTask {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var taskfile struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &taskfile); err != nil || taskfile.Tasks.Kind != yaml.MappingNode {
+		return nil
+	}
+	var tasks []ProjectTask
+	content := taskfile.Tasks.Content
+	for i := 0; i+1 < len(content); i += 2 {
+		var body struct {
+			Desc     string `yaml:"desc"`
+			Internal bool   `yaml:"internal"`
+		}
+		_ = content[i+1].Decode(&body) // shorthand tasks (a bare command list) have no desc
+		if body.Internal {
+			continue
+		}
+		tasks = append(tasks, ProjectTask{Name: content[i].Value, Description: body.Desc})
+	}
+	return tasks
+}
+
+// parsePackageJSONScripts lists package.json scripts with their command as
+// the description, sorted by name.
+func parsePackageJSONScripts(path string) []ProjectTask {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	var tasks []ProjectTask
+	for name, command := range pkg.Scripts {
+		tasks = append(tasks, ProjectTask{Name: name, Description: command})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// FormatTasks renders discovered tasks as a condensed markdown summary.
+func FormatTasks(dir string, sources []TaskSource) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# How to build, test and run %s\n", filepath.Base(dir))
+	if len(sources) == 0 {
+		sb.WriteString("\nNo Makefile, Taskfile, package.json scripts or justfile found.\n")
+		return sb.String()
+	}
+	for _, src := range sources {
+		fmt.Fprintf(&sb, "\n## %s (`%s`)\n\n", src.File, src.Runner)
+		for _, task := range src.Tasks {
+			if task.Description != "" {
+				fmt.Fprintf(&sb, "- `%s`: %s\n", task.Name, task.Description)
+			} else {
+				fmt.Fprintf(&sb, "- `%s`\n", task.Name)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// generateTasksFile writes the @tasks summary for dir (relative to the
+// working directory) to .grove/tasks and returns its absolute path.
+func (m *Manager) generateTasksFile(dir string) (string, error) {
+	dir = strings.Trim(strings.TrimSpace(dir), `"`)
+	target := m.workDir
+	if dir != "" {
+		target = expandHomeAndDot(dir)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(m.workDir, target)
+		}
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", target)
+	}
+
+	tasksDir := filepath.Join(m.workDir, GroveDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	name := "tasks.md"
+	if rel, err := filepath.Rel(m.workDir, target); err == nil && rel != "." {
+		name = "tasks-" + strings.NewReplacer("/", "-", "\\", "-", "..", "up").Replace(filepath.ToSlash(rel)) + ".md"
+	}
+	outPath := filepath.Join(tasksDir, name)
+	if err := os.WriteFile(outPath, []byte(FormatTasks(target, DiscoverTasks(target))), 0o644); err != nil { //nolint:gosec // task summary, not sensitive
+		return "", fmt.Errorf("failed to write tasks file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return outPath, nil
+	}
+	return absPath, nil
+}
@@ -0,0 +1,73 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverTasks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Makefile", `BIN := cx
+.PHONY: build test
+
+# Build the binary
+build: deps
+	go build -o bin/$(BIN) .
+
+test: ## Run unit tests
+	go test ./...
+
+%.o: %.c
+	cc -c $<
+`)
+	write("Taskfile.yml", `version: '3'
+tasks:
+  lint:
+    desc: Run golangci-lint
+    cmds: [golangci-lint run]
+  helper:
+    internal: true
+  fmt: gofmt -w .
+`)
+	write("package.json", `{"scripts": {"test": "vitest", "dev": "vite"}}`)
+	write("justfile", `set shell := ["bash", "-c"]
+
+# Tag a release
+release version='patch':
+    ./scripts/release {{version}}
+
+_private:
+    echo hidden
+`)
+
+	var got []string
+	for _, src := range DiscoverTasks(dir) {
+		for _, task := range src.Tasks {
+			got = append(got, src.File+":"+task.Name+"="+task.Description)
+		}
+	}
+	want := []string{
+		"Makefile:build=Build the binary",
+		"Makefile:test=Run unit tests",
+		"Taskfile.yml:lint=Run golangci-lint",
+		"Taskfile.yml:fmt=",
+		"package.json:dev=vite",
+		"package.json:test=vitest",
+		"justfile:release=Tag a release",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	summary := FormatTasks(dir, DiscoverTasks(dir))
+	if !strings.Contains(summary, "## Makefile (`make <task>`)") || !strings.Contains(summary, "- `test`: Run unit tests") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
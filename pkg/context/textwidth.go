@@ -0,0 +1,65 @@
+package context
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TruncateWidth shortens s to at most width terminal cells, ending it with
+// tail when anything was cut. Widths are measured with lipgloss.Width, so
+// wide characters (CJK, emoji) count as two cells, and a multi-byte
+// character is never split.
+func TruncateWidth(s string, width int, tail string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	budget := width - lipgloss.Width(tail)
+	if budget < 0 {
+		return TruncateWidth(tail, width, "")
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > budget {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + tail
+}
+
+// TruncateWidthLeft is TruncateWidth keeping the end of s, prefixed by
+// head, for paths where the file name matters most.
+func TruncateWidthLeft(s string, width int, head string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	budget := width - lipgloss.Width(head)
+	if budget < 0 {
+		return TruncateWidth(head, width, "")
+	}
+	runes := []rune(s)
+	start, used := len(runes), 0
+	for start > 0 {
+		w := lipgloss.Width(string(runes[start-1]))
+		if used+w > budget {
+			break
+		}
+		start--
+		used += w
+	}
+	return head + string(runes[start:])
+}
+
+// PadWidth right-pads s with spaces to width terminal cells. Unlike
+// fmt's %-*s, which counts runes, it keeps columns aligned when s holds
+// wide characters or ANSI styling.
+func PadWidth(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package context
+
+import "testing"
+
+func TestTruncateWidth(t *testing.T) {
+	for _, tt := range []struct {
+		name, got, want string
+	}{
+		{"fits", TruncateWidth("abc", 3, "…"), "abc"},
+		{"accented", TruncateWidth("héllo wörld", 7, "…"), "héllo …"},
+		{"wide", TruncateWidth("日本語のファイル.go", 9, "…"), "日本語の…"},
+		{"emoji", TruncateWidth("🎉🎉🎉", 4, "…"), "🎉…"},
+		{"no room", TruncateWidth("abcdef", 0, "…"), ""},
+		{"left", TruncateWidthLeft("src/日本語/ファイル.go", 12, "..."), "...ァイル.go"},
+		{"pad wide", PadWidth("日本", 6) + "|", "日本  |"},
+		{"path", TruncatePath("ドキュメント/ガイド.md", 12), "ドキュメ..."},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
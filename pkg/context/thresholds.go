@@ -0,0 +1,138 @@
+package context
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Token thresholds. The token counts at which a file or directory is flagged,
+// as a colored badge in the TUI and as warnings in `cx stats` and
+// `cx generate`, are configurable per project:
+//
+//	cx:
+//	  token_thresholds:
+//	    notice: 10000
+//	    warn: 50000
+//	    error: 100000
+//
+// With `relative: true` the values are percentages of the active token
+// budget (--max-tokens, @budget: or cx.token_budget) instead, and the
+// absolute defaults apply while no budget is active. Unset thresholds
+// default to 10k/50k/100k tokens, or to 5%/25%/50% of the budget when a
+// budget is active and the thresholds are not configured as absolute counts.
+
+// TokenThresholdsConfig is cx.token_thresholds.
+type TokenThresholdsConfig struct {
+	Notice   int  `yaml:"notice,omitempty" toml:"notice,omitempty"`
+	Warn     int  `yaml:"warn,omitempty" toml:"warn,omitempty"`
+	Error    int  `yaml:"error,omitempty" toml:"error,omitempty"`
+	Relative bool `yaml:"relative,omitempty" toml:"relative,omitempty"` // values are percentages of the budget
+}
+
+// TokenThresholds are resolved token counts, ascending.
+type TokenThresholds struct {
+	Notice int `json:"notice"`
+	Warn   int `json:"warn"`
+	Error  int `json:"error"`
+}
+
+// DefaultTokenThresholds apply when no budget is active and none are
+// configured.
+var DefaultTokenThresholds = TokenThresholds{Notice: 10000, Warn: 50000, Error: 100000}
+
+// defaultThresholdPercents apply relative to an active budget.
+var defaultThresholdPercents = TokenThresholds{Notice: 5, Warn: 25, Error: 50}
+
+// TokenLevel is how a token count compares with the thresholds.
+type TokenLevel int
+
+const (
+	TokenLevelNone TokenLevel = iota
+	TokenLevelNotice
+	TokenLevelWarn
+	TokenLevelError
+)
+
+// Level returns the highest threshold tokens reaches.
+func (t TokenThresholds) Level(tokens int) TokenLevel {
+	switch {
+	case t.Error > 0 && tokens >= t.Error:
+		return TokenLevelError
+	case t.Warn > 0 && tokens >= t.Warn:
+		return TokenLevelWarn
+	case t.Notice > 0 && tokens >= t.Notice:
+		return TokenLevelNotice
+	}
+	return TokenLevelNone
+}
+
+// ResolveTokenThresholds resolves cfg against budget, the active token
+// budget (zero for none).
+func ResolveTokenThresholds(cfg TokenThresholdsConfig, budget int) TokenThresholds {
+	configured := cfg.Notice > 0 || cfg.Warn > 0 || cfg.Error > 0
+	if budget <= 0 && cfg.Relative {
+		// Percentages of no budget; fall back to the absolute defaults.
+		return DefaultTokenThresholds
+	}
+	if budget <= 0 || (configured && !cfg.Relative) {
+		return TokenThresholds{
+			Notice: orDefault(cfg.Notice, DefaultTokenThresholds.Notice),
+			Warn:   orDefault(cfg.Warn, DefaultTokenThresholds.Warn),
+			Error:  orDefault(cfg.Error, DefaultTokenThresholds.Error),
+		}
+	}
+	percent := func(p int) int { return budget * p / 100 }
+	return TokenThresholds{
+		Notice: percent(orDefault(cfg.Notice, defaultThresholdPercents.Notice)),
+		Warn:   percent(orDefault(cfg.Warn, defaultThresholdPercents.Warn)),
+		Error:  percent(orDefault(cfg.Error, defaultThresholdPercents.Error)),
+	}
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// TokenThresholds returns the project's token thresholds, relative to the
+// active budget: --max-tokens, the active rules' @budget:, or
+// cx.token_budget, in that order.
+func (m *Manager) TokenThresholds() TokenThresholds {
+	cfg := m.cxConfig()
+	budget := cfg.TokenBudget
+	if m.maxTokens > 0 {
+		budget = m.maxTokens
+	} else if rules, _, err := m.LoadRulesContent(); err == nil {
+		if d := parseBudgetDirective(rules); d != nil && d.Err == nil {
+			budget = d.Tokens
+		}
+	}
+	return ResolveTokenThresholds(cfg.TokenThresholds, budget)
+}
+
+// LargeGeneratedFiles returns the files of the most recent generation whose
+// tokens reach the Warn threshold, largest first.
+func (m *Manager) LargeGeneratedFiles(t TokenThresholds) []FileStats {
+	m.genMu.Lock()
+	var files []string
+	for _, tier := range []string{"hot", "cold"} {
+		files = append(files, m.lastGenerated[tier]...)
+	}
+	m.genMu.Unlock()
+
+	var large []FileStats
+	provider := GetStatsProvider()
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.workDir, path)
+		}
+		if info, err := provider.GetFileStats(path); err == nil && t.Level(info.Tokens) >= TokenLevelWarn {
+			large = append(large, FileStats{Path: file, Tokens: info.Tokens, Size: info.Size})
+		}
+	}
+	sort.SliceStable(large, func(i, j int) bool { return large[i].Tokens > large[j].Tokens })
+	return large
+}
@@ -0,0 +1,37 @@
+package context
+
+import "testing"
+
+func TestResolveTokenThresholds(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cfg    TokenThresholdsConfig
+		budget int
+		want   TokenThresholds
+	}{
+		{"defaults", TokenThresholdsConfig{}, 0, DefaultTokenThresholds},
+		{"defaults relative to a budget", TokenThresholdsConfig{}, 200000, TokenThresholds{10000, 50000, 100000}},
+		{"absolute ignores the budget", TokenThresholdsConfig{Warn: 20000}, 200000, TokenThresholds{10000, 20000, 100000}},
+		{"relative", TokenThresholdsConfig{Notice: 10, Warn: 20, Error: 40, Relative: true}, 100000, TokenThresholds{10000, 20000, 40000}},
+		{"relative without a budget", TokenThresholdsConfig{Warn: 20, Relative: true}, 0, DefaultTokenThresholds},
+	} {
+		if got := ResolveTokenThresholds(tc.cfg, tc.budget); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTokenThresholdsLevel(t *testing.T) {
+	th := DefaultTokenThresholds
+	for tokens, want := range map[int]TokenLevel{
+		0:      TokenLevelNone,
+		9999:   TokenLevelNone,
+		10000:  TokenLevelNotice,
+		50000:  TokenLevelWarn,
+		250000: TokenLevelError,
+	} {
+		if got := th.Level(tokens); got != want {
+			t.Errorf("Level(%d) = %d, want %d", tokens, got, want)
+		}
+	}
+}
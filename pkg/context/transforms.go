@@ -0,0 +1,160 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// Tier transforms. `@hot-transform:` and `@cold-transform:` declare, once
+// per rules file, content transforms applied to every file written into
+// that tier's artifact, so the cold context can be shrunk aggressively
+// while hot files stay verbatim:
+//
+//	@cold-transform: strip-comments, collapse-blank-lines
+//	src/**/*.go
+//	---
+//	vendor/**/*.go
+//
+// Section artifacts follow their section's tier. --strip-comments still
+// applies to every tier.
+
+// Tier transform names.
+const (
+	// TransformStripComments removes code comments, doc comments included
+	// (see strip.go).
+	TransformStripComments = "strip-comments"
+	// TransformCollapseBlankLines squeezes runs of blank lines into one.
+	TransformCollapseBlankLines = "collapse-blank-lines"
+)
+
+var knownTransforms = map[string]bool{
+	TransformStripComments:      true,
+	TransformCollapseBlankLines: true,
+}
+
+var tierTransformDirectives = map[string]string{
+	"@hot-transform:":  HotSection,
+	"@cold-transform:": ColdSection,
+}
+
+// tierTransformDirective is one `@hot-transform:` or `@cold-transform:`
+// line.
+type tierTransformDirective struct {
+	LineNum    int
+	Tier       string
+	Transforms []string
+	Unknown    []string
+}
+
+// parseTierTransformDirectives collects the tier transform lines of a rules
+// file.
+func parseTierTransformDirectives(content []byte) []tierTransformDirective {
+	var dirs []tierTransformDirective
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripInlineComments(strings.TrimSpace(scanner.Text())))
+		for prefix, tier := range tierTransformDirectives {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			d := tierTransformDirective{LineNum: lineNum, Tier: tier}
+			for _, name := range strings.FieldsFunc(strings.TrimPrefix(line, prefix), func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			}) {
+				if knownTransforms[name] {
+					d.Transforms = append(d.Transforms, name)
+				} else {
+					d.Unknown = append(d.Unknown, name)
+				}
+			}
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// TierTransforms returns the transforms rulesContent declares for tier
+// ("hot" or "cold"), in declaration order and without duplicates.
+func TierTransforms(rulesContent []byte, tier string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, d := range parseTierTransformDirectives(rulesContent) {
+		if d.Tier != tier {
+			continue
+		}
+		for _, t := range d.Transforms {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+// useTierTransforms makes the writers apply the transforms declared for
+// tier by the rules being generated until the returned function is called.
+func (m *Manager) useTierTransforms(tier string) func() {
+	rulesContent, _ := m.generatingRules()
+	saved := m.transforms
+	m.transforms = TierTransforms(rulesContent, tier)
+	for _, d := range parseTierTransformDirectives(rulesContent) {
+		if d.Tier == tier && len(d.Unknown) > 0 {
+			Warnf("line %d: ignoring unknown %s transform(s): %s", d.LineNum, tier, strings.Join(d.Unknown, ", "))
+		}
+	}
+	return func() { m.transforms = saved }
+}
+
+// generatingRules returns the content and path of the rules being
+// generated: the explicit rules file of GenerateContextFromRulesFile, or
+// the active rules.
+func (m *Manager) generatingRules() ([]byte, string) {
+	if m.explicitRulesPath != "" {
+		return m.explicitRules, m.explicitRulesPath
+	}
+	content, path, _ := m.LoadRulesContent()
+	return content, path
+}
+
+// transformContent applies comment stripping (--strip-comments or a tier
+// transform) and the other active tier transforms to file's content.
+func (m *Manager) transformContent(file string, content []byte) []byte {
+	strip := m.stripComments
+	for _, t := range m.transforms {
+		strip = strip || t == TransformStripComments
+	}
+	if strip {
+		content = StripComments(file, content)
+	}
+	for _, t := range m.transforms {
+		if t == TransformCollapseBlankLines {
+			content = collapseBlankLines(content)
+		}
+	}
+	return content
+}
+
+// collapseBlankLines replaces each run of blank (or whitespace-only) lines
+// with a single empty line.
+func collapseBlankLines(content []byte) []byte {
+	lines := bytes.Split(content, []byte("\n"))
+	out := lines[:0]
+	blank := false
+	for i, line := range lines {
+		isBlank := len(bytes.TrimSpace(line)) == 0
+		if isBlank && blank && i != len(lines)-1 {
+			continue
+		}
+		if isBlank && i != len(lines)-1 {
+			line = nil
+		}
+		blank = isBlank
+		out = append(out, line)
+	}
+	return bytes.Join(out, []byte("\n"))
+}
@@ -0,0 +1,38 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTierTransforms(t *testing.T) {
+	rules := []byte("@cold-transform: strip-comments, collapse-blank-lines\n" +
+		"@hot-transform: collapse-blank-lines  # keep docs\n" +
+		"@cold-transform: strip-comments minify\n" +
+		"src/**/*.go\n---\nvendor/**\n")
+
+	if got, want := TierTransforms(rules, ColdSection), []string{TransformStripComments, TransformCollapseBlankLines}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cold transforms = %v, want %v", got, want)
+	}
+	if got, want := TierTransforms(rules, HotSection), []string{TransformCollapseBlankLines}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hot transforms = %v, want %v", got, want)
+	}
+	dirs := parseTierTransformDirectives(rules)
+	if len(dirs) != 3 || dirs[2].LineNum != 3 || !reflect.DeepEqual(dirs[2].Unknown, []string{"minify"}) {
+		t.Errorf("directives = %+v, want unknown minify on line 3", dirs)
+	}
+}
+
+func TestTransformContent(t *testing.T) {
+	src := []byte("package main\n\n// Doc comment.\nfunc main() {}\n\n\n\nvar x = 1 // trailing\n")
+	m := &Manager{}
+
+	if got := m.transformContent("main.go", src); string(got) != string(src) {
+		t.Errorf("no transforms changed content:\n%s", got)
+	}
+
+	m.transforms = []string{TransformStripComments, TransformCollapseBlankLines}
+	if got, want := string(m.transformContent("main.go", src)), "package main\n\nfunc main() {}\n\nvar x = 1\n"; got != want {
+		t.Errorf("transformed content = %q, want %q", got, want)
+	}
+}
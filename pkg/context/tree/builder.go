@@ -38,11 +38,22 @@ type FileNode struct {
 // AnalyzeProjectTree walks the entire project and creates a tree structure showing
 // which files are included, excluded, or ignored based on context rules
 func AnalyzeProjectTree(m *context.Manager, showGitIgnored bool) (*FileNode, error) {
+	s, err := m.NewResolutionSession()
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeSessionTree(s, showGitIgnored)
+}
+
+// AnalyzeSessionTree is AnalyzeProjectTree over an existing resolution
+// session, so views that already resolved the rules do not resolve them again.
+func AnalyzeSessionTree(s *context.ResolutionSession, showGitIgnored bool) (*FileNode, error) {
 	defer profiling.Start("tree.AnalyzeProjectTree").Stop()
+	m := s.Manager()
 
 	// Use the new unified classification engine to get all file classifications
 	classifyStopper := profiling.Start("tree.ClassifyAllProjectFiles")
-	fileStatuses, err := m.ClassifyAllProjectFiles(showGitIgnored)
+	fileStatuses, err := s.Classify(showGitIgnored)
 	classifyStopper.Stop()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,185 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// treemapFills are the block characters used to tell adjacent rectangles
+// apart when color is unavailable (pipes, NO_COLOR).
+var treemapFills = []rune{'█', '▓', '▒', '░', '▚', '▞', '▙', '▟', '▜', '▛'}
+
+var treemapColors = []lipgloss.Color{"39", "208", "70", "170", "220", "45", "203", "141", "114", "179"}
+
+// cellAspect is the height:width ratio of a terminal character cell. Layout
+// happens in square units and rows are compressed by this factor, so
+// rectangles look square on screen rather than tall and thin.
+const cellAspect = 2.0
+
+// treemapRect is one laid-out rectangle on the character grid.
+type treemapRect struct {
+	node       *DirTokens
+	x, y, w, h float64
+}
+
+// RenderTreemap draws the children of root as a squarified treemap of
+// width x height character cells, followed by a legend with each entry's
+// share of root's tokens. A root with a single directory child is descended
+// into first so the map shows something more useful than one solid block.
+// Entries too small to occupy a cell may not show in the map but are
+// still listed in the legend.
+func RenderTreemap(root *DirTokens, width, height int) string {
+	for len(root.Children) == 1 && root.Children[0].IsDir {
+		root = root.Children[0]
+	}
+	if root.Tokens == 0 || width <= 0 || height <= 0 {
+		return "No tokens to map.\n"
+	}
+
+	children := make([]*DirTokens, 0, len(root.Children))
+	for _, c := range root.Children {
+		if c.Tokens > 0 {
+			children = append(children, c)
+		}
+	}
+	// Keep the legend (and the distinct fills) bounded; the long tail is
+	// rendered as a single "other" rectangle.
+	if len(children) > len(treemapFills) {
+		other := &DirTokens{Name: fmt.Sprintf("(%d more)", len(children)-len(treemapFills)+1)}
+		for _, c := range children[len(treemapFills)-1:] {
+			other.Tokens += c.Tokens
+			other.Files += c.Files
+		}
+		other.Path = other.Name
+		children = append(children[:len(treemapFills)-1:len(treemapFills)-1], other)
+	}
+
+	layoutH := float64(height) * cellAspect
+	scale := float64(width) * layoutH / float64(root.Tokens)
+	var rects []treemapRect
+	squarifyTreemap(children, scale, 0, 0, float64(width), layoutH, &rects)
+
+	grid := make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+		for x := range grid[y] {
+			grid[y][x] = -1
+		}
+	}
+	index := make(map[*DirTokens]int, len(children))
+	for i, c := range children {
+		index[c] = i
+	}
+	labels := make(map[[2]int]rune)
+	for _, r := range rects {
+		i := index[r.node]
+		x0, y0 := int(r.x+0.5), int(r.y/cellAspect+0.5)
+		x1, y1 := int(r.x+r.w+0.5), int((r.y+r.h)/cellAspect+0.5)
+		for y := y0; y < y1 && y < height; y++ {
+			for x := x0; x < x1 && x < width; x++ {
+				grid[y][x] = i
+			}
+		}
+		// Label the rectangle in its top-left corner when there is room.
+		label := []rune(" " + r.node.Name + " ")
+		if x1-x0 >= len(label) && y1 > y0 {
+			for k, ch := range label {
+				labels[[2]int{x0 + k, y0}] = ch
+			}
+		}
+	}
+
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := grid[y][x]
+			if i < 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			style := lipgloss.NewStyle().Foreground(treemapColors[i])
+			if ch, ok := labels[[2]int{x, y}]; ok {
+				b.WriteString(style.Reverse(true).Render(string(ch)))
+				continue
+			}
+			b.WriteString(style.Render(string(treemapFills[i])))
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteByte('\n')
+	for i, c := range children {
+		name := c.Path
+		if c.IsDir {
+			name += "/"
+		}
+		pct := float64(c.Tokens) / float64(root.Tokens) * 100
+		swatch := lipgloss.NewStyle().Foreground(treemapColors[i]).Render(strings.Repeat(string(treemapFills[i]), 2))
+		fmt.Fprintf(&b, "%s %s %5.1f%%  (~%s tokens, %d files)\n", swatch, PadWidth(name, 40), pct, FormatTokenCount(c.Tokens), c.Files)
+	}
+	return b.String()
+}
+
+// squarifyTreemap lays nodes (sorted largest first) into the rectangle
+// (x, y, w, h) using the squarified algorithm, which keeps aspect ratios
+// close to 1 so labels have room.
+func squarifyTreemap(nodes []*DirTokens, scale, x, y, w, h float64, out *[]treemapRect) {
+	if len(nodes) == 0 || w <= 0 || h <= 0 {
+		return
+	}
+	if len(nodes) == 1 {
+		*out = append(*out, treemapRect{node: nodes[0], x: x, y: y, w: w, h: h})
+		return
+	}
+
+	short := w
+	if h < short {
+		short = h
+	}
+	area := func(n *DirTokens) float64 { return float64(n.Tokens) * scale }
+	worst := func(row []*DirTokens, sum float64) float64 {
+		length := sum / short
+		var worst float64
+		for _, n := range row {
+			side := area(n) / length
+			ratio := side / length
+			if length > side {
+				ratio = length / side
+			}
+			if ratio > worst {
+				worst = ratio
+			}
+		}
+		return worst
+	}
+
+	n := 1
+	sum := area(nodes[0])
+	for n < len(nodes) {
+		next := sum + area(nodes[n])
+		if worst(nodes[:n+1], next) > worst(nodes[:n], sum) {
+			break
+		}
+		sum = next
+		n++
+	}
+
+	length := sum / short
+	offset := 0.0
+	for _, node := range nodes[:n] {
+		side := area(node) / length
+		if w >= h {
+			*out = append(*out, treemapRect{node: node, x: x, y: y + offset, w: length, h: side})
+		} else {
+			*out = append(*out, treemapRect{node: node, x: x + offset, y: y, w: side, h: length})
+		}
+		offset += side
+	}
+	if w >= h {
+		squarifyTreemap(nodes[n:], scale, x+length, y, w-length, h, out)
+	} else {
+		squarifyTreemap(nodes[n:], scale, x, y+length, w, h-length, out)
+	}
+}
@@ -0,0 +1,34 @@
+package context
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTreemap(t *testing.T) {
+	root := &DirTokens{Name: ".", Path: ".", IsDir: true, Tokens: 1000, Files: 3, Children: []*DirTokens{
+		{Name: "pkg", Path: "pkg", IsDir: true, Tokens: 700, Files: 2},
+		{Name: "main.go", Path: "main.go", Tokens: 300, Files: 1},
+	}}
+
+	out := RenderTreemap(root, 40, 10)
+	lines := strings.Split(out, "\n")
+	if len(lines) < 12 {
+		t.Fatalf("expected 10 map rows plus legend, got:\n%s", out)
+	}
+	big, small := 0, 0
+	for _, line := range lines[:10] {
+		big += strings.Count(line, "█")
+		small += strings.Count(line, "▓")
+	}
+	if big <= small*2 {
+		t.Errorf("pkg (70%%) should cover well over twice main.go's cells, got %d vs %d", big, small)
+	}
+	if !strings.Contains(out, "pkg/") || !strings.Contains(out, "70.0%") {
+		t.Errorf("legend missing pkg share:\n%s", out)
+	}
+
+	if got := RenderTreemap(&DirTokens{}, 40, 10); !strings.Contains(got, "No tokens") {
+		t.Errorf("empty tree rendered %q", got)
+	}
+}
@@ -11,3 +11,23 @@ const (
 	StatusIgnoredByGit                     // Ignored by .gitignore (not used in final result)
 	StatusDirectory                        // A directory containing other nodes
 )
+
+// String returns the machine-readable name of a status, as used in JSON output.
+func (s NodeStatus) String() string {
+	switch s {
+	case StatusIncludedHot:
+		return "hot"
+	case StatusIncludedCold:
+		return "cold"
+	case StatusExcludedByRule:
+		return "excluded"
+	case StatusOmittedNoMatch:
+		return "omitted"
+	case StatusIgnoredByGit:
+		return "gitignored"
+	case StatusDirectory:
+		return "directory"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,143 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// untilDateLayout is the date format of @until: annotations.
+const untilDateLayout = "2006-01-02"
+
+// untilRegex matches a trailing `@until: YYYY-MM-DD` on a rule line.
+var untilRegex = regexp.MustCompile(`\s+@until:\s*(\S+)\s*$`)
+
+// warnedExpired keeps each expired rule's warning to once per process.
+var warnedExpired sync.Map
+
+// ExpiredRule is a rule line whose @until: date has passed.
+type ExpiredRule struct {
+	LineNum int       `json:"line"`
+	Line    string    `json:"rule"`
+	Until   time.Time `json:"until"`
+}
+
+// splitUntil separates a trailing `@until: YYYY-MM-DD` from line. ok is
+// false when the line has none; err is set when the date does not parse.
+// A trailing `# comment` after the date is tolerated.
+func splitUntil(line string) (rule string, until time.Time, ok bool, err error) {
+	body, comment := line, ""
+	if i := strings.Index(line, " #"); i >= 0 && strings.Contains(line[:i], "@until:") {
+		body, comment = line[:i], line[i:]
+	}
+	match := untilRegex.FindStringSubmatchIndex(body)
+	if match == nil {
+		return line, time.Time{}, false, nil
+	}
+	rule = body[:match[0]] + comment
+	until, err = time.ParseInLocation(untilDateLayout, body[match[2]:match[3]], time.Local)
+	if err != nil {
+		return rule, time.Time{}, true, fmt.Errorf("invalid @until: date %q, expected YYYY-MM-DD", body[match[2]:match[3]])
+	}
+	return rule, until, true, nil
+}
+
+// untilExpired reports whether a rule marked @until: until no longer
+// applies at now. The rule is live through the whole of its last day.
+func untilExpired(until, now time.Time) bool {
+	return !now.Before(until.AddDate(0, 0, 1))
+}
+
+// applyUntil strips @until: annotations from rules content before it is
+// parsed. Lines past their date are blanked, keeping line numbers, with a
+// warning; a date that does not parse leaves the rule in effect.
+func applyUntil(content []byte) []byte {
+	return stripUntil(content, false)
+}
+
+// stripUntil removes @until: annotations from content, blanking expired
+// rules unless keepExpired is set (lint reports those itself).
+func stripUntil(content []byte, keepExpired bool) []byte {
+	if !bytes.Contains(content, []byte("@until:")) {
+		return content
+	}
+	now := time.Now()
+	lines := strings.Split(string(content), "\n")
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		rule, until, ok, err := splitUntil(trimmed)
+		if !ok {
+			continue
+		}
+		if err == nil && !keepExpired && untilExpired(until, now) {
+			if _, warned := warnedExpired.LoadOrStore(trimmed, true); !warned {
+				Warnf("ignoring rule '%s': expired on %s (remove with 'cx rules prune')", rule, until.Format(untilDateLayout))
+			}
+			lines[i] = ""
+			continue
+		}
+		lines[i] = rule
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// ExpiredRules lists the rules in content whose @until: date has passed.
+func ExpiredRules(content []byte, now time.Time) []ExpiredRule {
+	var expired []ExpiredRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if _, until, ok, err := splitUntil(trimmed); ok && err == nil && untilExpired(until, now) {
+			expired = append(expired, ExpiredRule{LineNum: lineNum, Line: trimmed, Until: until})
+		}
+	}
+	return expired
+}
+
+// RemoveExpiredRules rewrites the rules file at path without the given
+// expired rules (as returned by ExpiredRules). A line that no longer holds
+// the same rule is kept.
+func RemoveExpiredRules(path string, rules []ExpiredRule) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, WithoutExpiredRules(content, rules), info.Mode().Perm())
+}
+
+// WithoutExpiredRules returns content without the given expired rules, as
+// RemoveExpiredRules would write it.
+func WithoutExpiredRules(content []byte, rules []ExpiredRule) []byte {
+	lines := strings.Split(string(content), "\n")
+	drop := make(map[int]bool, len(rules))
+	for _, r := range rules {
+		if r.LineNum >= 1 && r.LineNum <= len(lines) && strings.TrimSpace(lines[r.LineNum-1]) == r.Line {
+			drop[r.LineNum] = true
+		}
+	}
+	kept := lines[:0]
+	for i, line := range lines {
+		if !drop[i+1] {
+			kept = append(kept, line)
+		}
+	}
+	return []byte(strings.Join(kept, "\n"))
+}
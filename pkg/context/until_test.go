@@ -0,0 +1,77 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitUntil(t *testing.T) {
+	tests := []struct {
+		line    string
+		rule    string
+		date    string
+		ok      bool
+		wantErr bool
+	}{
+		{line: "src/**/*.go", rule: "src/**/*.go"},
+		{line: "tmp-debug/** @until: 2025-07-01", rule: "tmp-debug/**", date: "2025-07-01", ok: true},
+		{line: "tmp-debug/** @until:2025-07-01", rule: "tmp-debug/**", date: "2025-07-01", ok: true},
+		{line: "tmp-debug/** @until: 2025-07-01 # flaky test", rule: "tmp-debug/** # flaky test", date: "2025-07-01", ok: true},
+		{line: "src/** @grep: TODO @until: 2025-07-01", rule: "src/** @grep: TODO", date: "2025-07-01", ok: true},
+		{line: "tmp/** @until: next-week", rule: "tmp/**", ok: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		rule, until, ok, err := splitUntil(tt.line)
+		if rule != tt.rule || ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("splitUntil(%q) = %q, %v, %v; want %q, %v, err=%v", tt.line, rule, ok, err, tt.rule, tt.ok, tt.wantErr)
+		}
+		if tt.date != "" && until.Format(untilDateLayout) != tt.date {
+			t.Errorf("splitUntil(%q) date = %s, want %s", tt.line, until.Format(untilDateLayout), tt.date)
+		}
+	}
+}
+
+func TestUntilExpired(t *testing.T) {
+	until := time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)
+	if untilExpired(until, time.Date(2025, 7, 1, 23, 59, 0, 0, time.Local)) {
+		t.Error("rule should still apply on its last day")
+	}
+	if !untilExpired(until, time.Date(2025, 7, 2, 0, 0, 0, 0, time.Local)) {
+		t.Error("rule should expire the day after its date")
+	}
+}
+
+func TestApplyUntil(t *testing.T) {
+	content := "src/**\ntmp-debug/** @until: 2000-01-01\nnotes/** @until: 2999-01-01\n"
+	got := string(applyUntil([]byte(content)))
+	want := "src/**\n\nnotes/**\n"
+	if got != want {
+		t.Errorf("applyUntil() = %q, want %q", got, want)
+	}
+}
+
+func TestRemoveExpiredRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules")
+	content := "src/**\ntmp-debug/** @until: 2000-01-01\nnotes/** @until: 2999-01-01\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	expired := ExpiredRules([]byte(content), time.Now())
+	if len(expired) != 1 || expired[0].LineNum != 2 {
+		t.Fatalf("ExpiredRules() = %+v, want line 2 only", expired)
+	}
+	if err := RemoveExpiredRules(path, expired); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "tmp-debug") || !strings.Contains(string(data), "notes/**") {
+		t.Errorf("rules after removal = %q", data)
+	}
+}
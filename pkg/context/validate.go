@@ -4,16 +4,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // ValidationResult contains the results of context validation
 type ValidationResult struct {
-	TotalFiles       int
-	AccessibleFiles  int
-	MissingFiles     []string
-	Duplicates       map[string]int
-	PermissionIssues []string
+	TotalFiles       int            `json:"total_files"`
+	AccessibleFiles  int            `json:"accessible_files"`
+	MissingFiles     []string       `json:"missing_files"`
+	Duplicates       map[string]int `json:"duplicates"`
+	PermissionIssues []string       `json:"permission_issues"`
+	// CaseCollisions groups files on case-sensitive filesystems whose paths
+	// differ only by case; they collapse into one file on a case-insensitive
+	// checkout or when lowercased for lookup.
+	CaseCollisions [][]string `json:"case_collisions"`
 }
 
 // ValidateContext checks the integrity of all files in the context
@@ -27,8 +32,10 @@ func (m *Manager) ValidateContext(files []string) (*ValidationResult, error) {
 		return result, nil
 	}
 
-	// Track file occurrences
+	// Track file occurrences, counting case variants as one file where the
+	// filesystem does
 	fileCount := make(map[string]int)
+	caseVariants := make(map[string][]string)
 
 	for _, file := range files {
 		// Normalize path
@@ -39,7 +46,12 @@ func (m *Manager) ValidateContext(files []string) (*ValidationResult, error) {
 		}
 
 		// Count occurrences
-		fileCount[absPath]++
+		key := m.foldKey(absPath)
+		if fileCount[key] == 0 && key == absPath {
+			lower := strings.ToLower(absPath)
+			caseVariants[lower] = append(caseVariants[lower], absPath)
+		}
+		fileCount[key]++
 
 		// Check if file exists
 		info, err := os.Stat(absPath)
@@ -88,6 +100,15 @@ func (m *Manager) ValidateContext(files []string) (*ValidationResult, error) {
 		}
 	}
 
+	for _, variants := range caseVariants {
+		if len(variants) > 1 {
+			result.CaseCollisions = append(result.CaseCollisions, variants)
+		}
+	}
+	sort.Slice(result.CaseCollisions, func(i, j int) bool {
+		return result.CaseCollisions[i][0] < result.CaseCollisions[j][0]
+	})
+
 	return result, nil
 }
 
@@ -129,11 +150,20 @@ func (r *ValidationResult) Print() {
 		fmt.Println()
 	}
 
+	// Case collisions
+	if len(r.CaseCollisions) > 0 {
+		fmt.Printf("Case collisions (%d):\n", len(r.CaseCollisions))
+		for _, variants := range r.CaseCollisions {
+			fmt.Printf("  - %s (differ only by case; rename one to use this context on case-insensitive filesystems)\n", strings.Join(variants, ", "))
+		}
+		fmt.Println()
+	}
+
 	// Summary
 	fmt.Printf("Accessible files: %d/%d\n", r.AccessibleFiles, r.TotalFiles)
 
 	// Total issues
-	totalIssues := len(r.MissingFiles) + len(r.Duplicates) + len(r.PermissionIssues)
+	totalIssues := len(r.MissingFiles) + len(r.Duplicates) + len(r.PermissionIssues) + len(r.CaseCollisions)
 	if totalIssues > 0 {
 		fmt.Printf("Issues found: %d\n", totalIssues)
 		fmt.Println("\nCheck your rules file and ensure all referenced files exist.")
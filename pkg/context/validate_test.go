@@ -2,6 +2,7 @@ package context
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -52,3 +53,97 @@ func TestManager_ValidateContext(t *testing.T) {
 		t.Errorf("Expected 1 duplicate, got %d", len(result.Duplicates))
 	}
 }
+
+func TestManager_ValidateContextCaseCollisions(t *testing.T) {
+	tempDir := t.TempDir()
+	if !CaseSensitiveAt(tempDir) {
+		t.Skip("filesystem is case-insensitive")
+	}
+	mgr := NewManager(tempDir)
+	upper := filepath.Join(tempDir, "Util.go")
+	lower := filepath.Join(tempDir, "util.go")
+	fsWriteString(t, upper, "package a")
+	fsWriteString(t, lower, "package b")
+
+	result, err := mgr.ValidateContext([]string{upper, lower})
+	if err != nil {
+		t.Fatalf("Failed to validate context: %v", err)
+	}
+	if result.AccessibleFiles != 2 || len(result.Duplicates) != 0 {
+		t.Errorf("case variants should be two distinct files, got %d accessible and duplicates %v", result.AccessibleFiles, result.Duplicates)
+	}
+	if len(result.CaseCollisions) != 1 || len(result.CaseCollisions[0]) != 2 {
+		t.Errorf("Expected one collision of two files, got %v", result.CaseCollisions)
+	}
+}
+
+// TestManager_ValidateCachedContext verifies that the cached artifact is
+// compared against its files list and that vanished files are reported.
+func TestManager_ValidateCachedContext(t *testing.T) {
+	dir := t.TempDir()
+	grove := filepath.Join(dir, GroveDir)
+	m := NewManagerWithPathsOverride(dir,
+		filepath.Join(grove, "context"), filepath.Join(grove, "cached-context"),
+		filepath.Join(grove, "context-files"), filepath.Join(grove, "cached-context-files"))
+
+	v, err := m.ValidateCachedContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Exists || v.Issues() != 0 {
+		t.Fatalf("expected no artifact and no issues, got %+v", v)
+	}
+
+	fsWriteString(t, filepath.Join(dir, "a.md"), "a\n")
+	fsWriteString(t, filepath.Join(grove, "cached-context"), `<context>
+  <cold-context files="2">
+    <file path="a.md">
+a
+    </file>
+    <file path="b.md">
+b
+    </file>
+  </cold-context>
+</context>
+`)
+	fsWriteString(t, filepath.Join(grove, "cached-context-files"), "a.md\ngone.md\n")
+
+	v, err = m.ValidateCachedContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Exists || v.Expired || v.Frozen {
+		t.Errorf("unexpected state: %+v", v)
+	}
+	if len(v.NotInArtifact) != 1 || v.NotInArtifact[0] != "gone.md" {
+		t.Errorf("NotInArtifact = %v, want [gone.md]", v.NotInArtifact)
+	}
+	if len(v.NotInList) != 1 || v.NotInList[0] != "b.md" {
+		t.Errorf("NotInList = %v, want [b.md]", v.NotInList)
+	}
+	if len(v.MissingFiles) != 1 || v.MissingFiles[0] != "gone.md" {
+		t.Errorf("MissingFiles = %v, want [gone.md]", v.MissingFiles)
+	}
+	if v.Issues() != 3 {
+		t.Errorf("Issues() = %d, want 3", v.Issues())
+	}
+}
+
+func TestDeletedCheckouts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GROVE_HOME", t.TempDir())
+	base := t.TempDir()
+	live := filepath.Join(base, "repo.git", ".grove-worktrees", "abc123")
+	fsWriteString(t, filepath.Join(live, "kept.go"), "package kept\n")
+	gone := filepath.Join(base, "other.git", ".grove-worktrees", "def456")
+
+	repos := deletedCheckouts([]string{
+		filepath.Join(live, "missing.go"),
+		filepath.Join(gone, "a.go"),
+		filepath.Join(gone, "pkg", "b.go"),
+		filepath.Join(base, "not-a-checkout.go"),
+	})
+	if len(repos) != 1 || repos[0].Path != gone || repos[0].Files != 2 {
+		t.Errorf("deletedCheckouts() = %+v, want one entry for %s with 2 files", repos, gone)
+	}
+}
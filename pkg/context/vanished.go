@@ -0,0 +1,145 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Vanished-path statuses, from most to least actionable.
+const (
+	VanishedRenamed     = "renamed"     // moved in a commit; RenamedTo is the new location
+	VanishedDeleted     = "deleted"     // deleted in a commit
+	VanishedUncommitted = "uncommitted" // in git history, missing from the working tree
+	VanishedUnknown     = "unknown"     // never in git history: a typo or a path yet to be created
+)
+
+// VanishedRulePath is a rule whose non-glob path prefix does not exist,
+// with what git history says happened to it.
+type VanishedRulePath struct {
+	LineNum   int    `json:"line"`
+	Pattern   string `json:"pattern"`
+	Path      string `json:"path"` // the missing non-glob prefix
+	Status    string `json:"status"`
+	Commit    string `json:"commit,omitempty"` // commit that deleted or renamed Path
+	RenamedTo string `json:"renamed_to,omitempty"`
+}
+
+// Describe explains the finding in one line.
+func (v VanishedRulePath) Describe() string {
+	switch v.Status {
+	case VanishedRenamed:
+		return fmt.Sprintf("%s was renamed to %s in %s (cx fix --paths --since %s^ rewrites it)", v.Path, v.RenamedTo, v.Commit, v.Commit)
+	case VanishedDeleted:
+		return fmt.Sprintf("%s was deleted in %s", v.Path, v.Commit)
+	case VanishedUncommitted:
+		return fmt.Sprintf("%s is in git history but missing from the working tree", v.Path)
+	default:
+		return fmt.Sprintf("%s has never existed in git history: a typo, or a path yet to be created", v.Path)
+	}
+}
+
+// VanishedRulePaths checks each path rule of rulesContent whose non-glob
+// prefix does not exist under the rules base directory against git
+// history, telling renamed and deleted paths (with the new location of a
+// renamed one) apart from paths git has never seen. Rules starting with a
+// glob, absolute rules and rules outside the base directory are skipped,
+// as is everything when the base directory is not in a git repository.
+func (m *Manager) VanishedRulePaths(rulesContent []byte) []VanishedRulePath {
+	if exec.Command("git", "-C", m.rulesBaseDir, "rev-parse", "--git-dir").Run() != nil {
+		return nil
+	}
+
+	var vanished []VanishedRulePath
+	known := make(map[string]VanishedRulePath)
+	scanner := bufio.NewScanner(bytes.NewReader(rulesContent))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(strings.TrimPrefix(trimmed, "!"), "@") {
+			continue
+		}
+		if _, ok := ParseSectionSeparator(trimmed); ok {
+			continue
+		}
+		pattern := strings.Fields(trimmed)[0]
+		prefix := nonGlobPrefix(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./"))
+		if prefix == "" || filepath.IsAbs(prefix) || prefix == ".." || strings.HasPrefix(prefix, "../") {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(m.rulesBaseDir, filepath.FromSlash(prefix))); err == nil {
+			continue
+		}
+
+		v, ok := known[prefix]
+		if !ok {
+			v = m.pathHistory(prefix)
+			known[prefix] = v
+		}
+		v.LineNum, v.Pattern = lineNum, pattern
+		vanished = append(vanished, v)
+	}
+	return vanished
+}
+
+// nonGlobPrefix returns the leading path components of pattern that hold
+// no glob metacharacters.
+func nonGlobPrefix(pattern string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSuffix(pattern, "/"), "/") {
+		if hasGlobMeta(part) || strings.Contains(part, "{") {
+			break
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// pathHistory reports what git history says happened to the missing path
+// prefix (relative to the rules base directory).
+func (m *Manager) pathHistory(prefix string) VanishedRulePath {
+	v := VanishedRulePath{Path: prefix, Status: VanishedUnknown}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = m.rulesBaseDir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	pathspec := ":(literal)" + prefix
+
+	// --no-renames so a rename shows up as the deletion of the old path.
+	commit := git("log", "-1", "--no-renames", "--diff-filter=D", "--format=%h", "--", pathspec)
+	if commit == "" {
+		if git("log", "-1", "--format=%h", "--", pathspec) != "" {
+			v.Status = VanishedUncommitted
+		}
+		return v
+	}
+	v.Status, v.Commit = VanishedDeleted, commit
+
+	changes := git("show", "-M", "--name-status", "--relative", "--format=", commit)
+	for _, line := range strings.Split(changes, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || !strings.HasPrefix(fields[0], "R") {
+			continue
+		}
+		oldPath, newPath := fields[1], fields[2]
+		if oldPath == prefix {
+			v.Status, v.RenamedTo = VanishedRenamed, newPath
+			break
+		}
+		if rest, ok := strings.CutPrefix(oldPath, prefix+"/"); ok {
+			if dir, ok := strings.CutSuffix(newPath, "/"+rest); ok {
+				v.Status, v.RenamedTo = VanishedRenamed, dir
+				break
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package context
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestVanishedRulePaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	fsWriteString(t, filepath.Join(dir, "pkg", "old", "api.go"), "package old\n\nfunc API() {}\n")
+	fsWriteString(t, filepath.Join(dir, "legacy.go"), "package main\n")
+	fsWriteString(t, filepath.Join(dir, "local.go"), "package main\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-qm", "initial")
+	git("mv", "pkg/old", "pkg/new")
+	git("rm", "-q", "legacy.go")
+	git("commit", "-qm", "reorganize")
+	if err := os.Remove(filepath.Join(dir, "local.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{workDir: dir, rulesBaseDir: dir}
+	rules := "pkg/old/**/*.go\n@find: pkg/old\nlegacy.go\n!local.go\npkg/new/*.go\n**/*.md\npkg/planned/\n"
+	got := m.VanishedRulePaths([]byte(rules))
+
+	want := []VanishedRulePath{
+		{LineNum: 1, Pattern: "pkg/old/**/*.go", Path: "pkg/old", Status: VanishedRenamed, RenamedTo: "pkg/new"},
+		{LineNum: 3, Pattern: "legacy.go", Path: "legacy.go", Status: VanishedDeleted},
+		{LineNum: 4, Pattern: "!local.go", Path: "local.go", Status: VanishedUncommitted},
+		{LineNum: 7, Pattern: "pkg/planned/", Path: "pkg/planned", Status: VanishedUnknown},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		got[i].Commit = ""
+		if got[i] != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
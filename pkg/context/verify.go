@@ -0,0 +1,334 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output verification policies. Verification runs against the final generated
+// artifact (after comment stripping and any other transforms), unlike
+// ValidateContext which checks the resolved file list before generation.
+const (
+	PolicySecrets  = "secrets"
+	PolicyDenied   = "denied-path"
+	PolicyBudget   = "budget"
+	PolicyRequired = "required"
+)
+
+// Violation is a single policy failure found in a generated artifact.
+type Violation struct {
+	Policy  string `json:"policy"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"` // 1-based line within the artifact
+	Message string `json:"message"`
+}
+
+// VerifyOptions configures the policies applied by VerifyOutput.
+type VerifyOptions struct {
+	// TokenBudget fails verification when the artifact's estimated token
+	// count exceeds it. Zero disables the budget check.
+	TokenBudget int
+	// Require lists paths that must appear as files in the artifact.
+	Require []string
+}
+
+// OutputVerification is the result of verifying one generated artifact.
+type OutputVerification struct {
+	ArtifactPath string      `json:"artifact_path"`
+	Files        []string    `json:"files"`
+	Tokens       int         `json:"tokens"`
+	Violations   []Violation `json:"violations"`
+
+	fileTokens []int // estimated tokens of each of Files
+}
+
+// OK reports whether the artifact passed every policy.
+func (v *OutputVerification) OK() bool { return len(v.Violations) == 0 }
+
+// secretPatterns are high-signal credential shapes. They deliberately favor
+// precision over recall: a noisy secrets check gets disabled, not fixed.
+var secretPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN (RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"Anthropic/OpenAI API key", regexp.MustCompile(`\bsk-(ant-)?[A-Za-z0-9_-]{32,}\b`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(api[_-]?key|secret[_-]?key|access[_-]?token|password)\s*[:=]\s*["'][^"'\s]{12,}["']`)},
+}
+
+var (
+	xmlFileOpenRe    = regexp.MustCompile(`^\s*<file path="([^"]+)">\s*$`)
+	xmlFileCloseRe   = regexp.MustCompile(`^\s*</file>\s*$`)
+	plainFileOpenRe  = regexp.MustCompile(`^=== FILE: (.+) ===$`)
+	plainFileCloseRe = regexp.MustCompile(`^=== END FILE: (.+) ===$`)
+	mdFileHeadingRe  = regexp.MustCompile(`^## (.+)$`)
+	mdFenceRe        = regexp.MustCompile("^(`{3,})[^`]*$")
+)
+
+// VerifyOutput scans a generated context artifact and checks it against the
+// secrets, denied-path, budget, and required-file policies.
+func (m *Manager) VerifyOutput(artifactPath string, opts VerifyOptions) (*OutputVerification, error) {
+	if err := m.artifactsReadable(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+
+	result, err := m.verifyArtifact(f, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", artifactPath, err)
+	}
+	result.ArtifactPath = artifactPath
+	return result, nil
+}
+
+// ArtifactFile is one file block of a generated artifact.
+type ArtifactFile struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+}
+
+// ArtifactFiles lists the files in the generated artifact at path, in
+// artifact order, with the estimated tokens of each block.
+func ArtifactFiles(path string) ([]ArtifactFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := scanArtifact(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	files := make([]ArtifactFile, len(result.Files))
+	for i, file := range result.Files {
+		files[i] = ArtifactFile{Path: file, Tokens: result.fileTokens[i]}
+	}
+	return files, nil
+}
+
+// artifactFileSet returns the set of files in artifact data.
+func artifactFileSet(data []byte) (map[string]bool, error) {
+	result, err := scanArtifact(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]bool, len(result.Files))
+	for _, file := range result.Files {
+		files[file] = true
+	}
+	return files, nil
+}
+
+func (m *Manager) verifyArtifact(r io.Reader, opts VerifyOptions) (*OutputVerification, error) {
+	result, err := scanArtifact(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range result.Files {
+		if allowed, reason := m.IsPathAllowed(file); !allowed {
+			result.Violations = append(result.Violations, Violation{
+				Policy:  PolicyDenied,
+				File:    file,
+				Message: reason,
+			})
+		}
+	}
+
+	if opts.TokenBudget > 0 && result.Tokens > opts.TokenBudget {
+		result.Violations = append(result.Violations, Violation{
+			Policy: PolicyBudget,
+			Message: fmt.Sprintf("artifact is ~%s tokens, over the %s budget",
+				FormatTokenCount(result.Tokens), FormatTokenCount(opts.TokenBudget)),
+		})
+	}
+
+	result.Violations = append(result.Violations, m.MissingRequiredFiles([]*OutputVerification{result}, opts.Require)...)
+
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Policy < result.Violations[j].Policy
+	})
+	return result, nil
+}
+
+// scanArtifact splits an artifact in the XML, classic-delimiter, markdown or
+// jsonl format into its files, estimates its token count, and flags lines
+// matching secretPatterns.
+func scanArtifact(r io.Reader) (*OutputVerification, error) {
+	result := &OutputVerification{Files: []string{}, Violations: []Violation{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNum := 0
+	current := ""
+	var currentBytes int64
+	mdHeading := "" // a "## path" heading waiting for its code fence
+	mdFence := ""   // the fence closing the current markdown file
+	closeFile := func() {
+		if current != "" {
+			tokens := EstimateTokens(current, currentBytes)
+			result.Tokens += tokens
+			result.fileTokens = append(result.fileTokens, tokens)
+		}
+		current, currentBytes = "", 0
+	}
+	addFile := func(file string, tokens int) {
+		result.Files = append(result.Files, file)
+		result.Tokens += tokens
+		result.fileTokens = append(result.fileTokens, tokens)
+	}
+	flagSecrets := func(file, line string) {
+		for _, p := range secretPatterns {
+			if p.re.MatchString(line) {
+				result.Violations = append(result.Violations, Violation{
+					Policy:  PolicySecrets,
+					File:    file,
+					Line:    lineNum,
+					Message: "possible " + p.name,
+				})
+				return
+			}
+		}
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		if mdFence != "" {
+			if line == mdFence {
+				closeFile()
+				mdFence = ""
+				continue
+			}
+		} else {
+			if match := xmlFileOpenRe.FindStringSubmatch(line); match != nil {
+				closeFile()
+				current = match[1]
+				result.Files = append(result.Files, current)
+				continue
+			}
+			if match := plainFileOpenRe.FindStringSubmatch(line); match != nil {
+				closeFile()
+				current = match[1]
+				result.Files = append(result.Files, current)
+				continue
+			}
+			if current != "" && (xmlFileCloseRe.MatchString(line) || plainFileCloseRe.MatchString(line)) {
+				closeFile()
+				continue
+			}
+		}
+		if current == "" {
+			if record, ok := jsonlFileRecord(line); ok {
+				addFile(record.Path, EstimateTokens(record.Path, int64(len(record.Content))))
+				for _, contentLine := range strings.Split(record.Content, "\n") {
+					flagSecrets(record.Path, contentLine)
+				}
+				continue
+			}
+			heading := mdFileHeadingRe.FindStringSubmatch(line)
+			switch {
+			case heading != nil && !strings.HasPrefix(heading[1], "Tree: "):
+				mdHeading = heading[1]
+			case mdHeading != "" && line == "":
+			case mdHeading != "" && mdFenceRe.MatchString(line):
+				current, mdFence, mdHeading = mdHeading, mdFenceRe.FindStringSubmatch(line)[1], ""
+				result.Files = append(result.Files, current)
+			case mdHeading != "" && strings.HasPrefix(line, "Error reading file: "):
+				addFile(mdHeading, 0)
+				mdHeading = ""
+			default:
+				mdHeading = ""
+			}
+			continue
+		}
+
+		currentBytes += int64(len(line)) + 1
+		flagSecrets(current, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	closeFile()
+	return result, nil
+}
+
+// jsonlFileRecord decodes line as a file record of a jsonl artifact.
+func jsonlFileRecord(line string) (OutputFile, bool) {
+	if !strings.HasPrefix(line, `{"type":"file"`) {
+		return OutputFile{}, false
+	}
+	var record OutputFile
+	if err := json.Unmarshal([]byte(line), &record); err != nil || record.Path == "" {
+		return OutputFile{}, false
+	}
+	return record, true
+}
+
+// MissingRequiredFiles returns a PolicyRequired violation for each path in
+// require that appears in none of the verified artifacts.
+func (m *Manager) MissingRequiredFiles(results []*OutputVerification, require []string) []Violation {
+	present := make(map[string]bool)
+	for _, r := range results {
+		for _, file := range r.Files {
+			present[m.normalizeArtifactPath(file)] = true
+		}
+	}
+	var violations []Violation
+	for _, req := range require {
+		if !present[m.normalizeArtifactPath(req)] {
+			violations = append(violations, Violation{
+				Policy:  PolicyRequired,
+				File:    req,
+				Message: "required file is missing from the generated context",
+			})
+		}
+	}
+	return violations
+}
+
+// normalizeArtifactPath resolves an artifact or required path to a cleaned
+// absolute path so relative and absolute spellings compare equal.
+func (m *Manager) normalizeArtifactPath(p string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(m.workDir, p)
+	}
+	return filepath.Clean(p)
+}
+
+// Print writes a human-readable violation report to w.
+func (v *OutputVerification) Print(w io.Writer) {
+	fmt.Fprintf(w, "Verified %s (%d files, ~%s tokens)\n", v.ArtifactPath, len(v.Files), FormatTokenCount(v.Tokens))
+	if v.OK() {
+		fmt.Fprintln(w, "  no policy violations")
+		return
+	}
+	for _, violation := range v.Violations {
+		location := violation.File
+		if violation.Line > 0 {
+			location = fmt.Sprintf("%s (artifact line %d)", violation.File, violation.Line)
+		}
+		if location == "" {
+			fmt.Fprintf(w, "  [%s] %s\n", violation.Policy, violation.Message)
+		} else {
+			fmt.Fprintf(w, "  [%s] %s: %s\n", violation.Policy, location, strings.TrimSpace(violation.Message))
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// On-disk walk cache. The walk index (walkindex.go) lives only as long as
+// its Manager, so every `cx list` or `cx stats` on a large monorepo still
+// starts by walking each root. Recordings and their pattern memos are also
+// saved under .grove/walk-cache, one file per root, and a Manager without an
+// in-memory recording of a root loads the saved one. It is used only while
+// every directory it entered has its recorded mtime and the root's
+// gitignored set and binary classification (binary.go) are the ones it was
+// recorded under; otherwise the root is walked again and the file replaced.
+// CX_NO_CACHE and --no-cache bypass it.
+
+// WalkCacheDir holds the saved walk recordings, relative to the working
+// directory.
+const WalkCacheDir = ".grove/walk-cache"
+
+// walkCacheVersion changes whenever walkCacheFile or the walk filters do.
+const walkCacheVersion = 1
+
+type walkCacheFile struct {
+	Version int                  `json:"version"`
+	Root    string               `json:"root"`
+	Ignored string               `json:"ignored"` // fingerprint of the root's gitignored set
+	Binary  string               `json:"binary"`  // binaryPolicy key
+	Dirs    map[string]time.Time `json:"dirs"`
+	Entries []walkCacheEntry     `json:"entries"`
+	Matches map[string][]string  `json:"matches,omitempty"`
+}
+
+type walkCacheEntry struct {
+	Path   string      `json:"p"` // relative to the root
+	Mode   fs.FileMode `json:"m"` // type bits only
+	Pruned bool        `json:"x,omitempty"`
+}
+
+// cachedDirEntry stands in for the fs.DirEntry of a saved walk entry.
+type cachedDirEntry struct {
+	path string
+	mode fs.FileMode
+}
+
+func (e cachedDirEntry) Name() string               { return filepath.Base(e.path) }
+func (e cachedDirEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e cachedDirEntry) Type() fs.FileMode          { return e.mode }
+func (e cachedDirEntry) Info() (fs.FileInfo, error) { return os.Lstat(e.path) }
+
+func (m *Manager) walkCacheEnabled() bool {
+	return !m.noState && !cachesDisabled()
+}
+
+// walkCachePath returns the cache file of root.
+func (m *Manager) walkCachePath(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(m.workDir, WalkCacheDir, hex.EncodeToString(sum[:])[:16]+".json")
+}
+
+// ignoredFingerprint hashes the gitignored paths that apply to root.
+func (m *Manager) ignoredFingerprint(root string) string {
+	ignored, _ := m.getGitIgnoredFiles(root)
+	paths := make([]string, 0, len(ignored))
+	for p := range ignored {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// loadWalkIndex returns the saved recording of root, or nil when there is
+// none or it was recorded under another gitignored set. The caller checks
+// the directory mtimes.
+func (m *Manager) loadWalkIndex(root string) *walkIndex {
+	if !m.walkCacheEnabled() {
+		return nil
+	}
+	data, err := os.ReadFile(m.walkCachePath(root))
+	if err != nil {
+		return nil
+	}
+	var f walkCacheFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != walkCacheVersion || f.Root != root {
+		return nil
+	}
+	if f.Ignored != m.ignoredFingerprint(root) {
+		return nil
+	}
+	idx := &walkIndex{dirs: f.Dirs, matches: f.Matches, binary: f.Binary, entries: make([]walkIndexEntry, 0, len(f.Entries))}
+	if idx.dirs == nil {
+		idx.dirs = make(map[string]time.Time)
+	}
+	if idx.matches == nil {
+		idx.matches = make(map[string][]string)
+	}
+	for _, e := range f.Entries {
+		path := filepath.Join(root, e.Path)
+		idx.entries = append(idx.entries, walkIndexEntry{path: path, d: cachedDirEntry{path: path, mode: e.Mode}, pruned: e.Pruned})
+	}
+	return idx
+}
+
+// saveWalkIndexes writes the recordings that changed since they were last
+// saved. Failures only cost the next invocation a walk, so they are
+// ignored.
+func (m *Manager) saveWalkIndexes() {
+	if !m.walkCacheEnabled() {
+		return
+	}
+	m.walkIndexMu.Lock()
+	var files []walkCacheFile
+	for root, idx := range m.walkIndexes {
+		if !idx.dirty {
+			continue
+		}
+		idx.dirty = false
+		f := walkCacheFile{
+			Version: walkCacheVersion,
+			Root:    root,
+			Ignored: idx.ignored,
+			Binary:  idx.binary,
+			Dirs:    idx.dirs,
+			Entries: make([]walkCacheEntry, 0, len(idx.entries)),
+			Matches: make(map[string][]string, len(idx.matches)),
+		}
+		for key, paths := range idx.matches {
+			f.Matches[key] = paths
+		}
+		for _, e := range idx.entries {
+			rel, err := filepath.Rel(root, e.path)
+			if err != nil {
+				continue
+			}
+			f.Entries = append(f.Entries, walkCacheEntry{Path: rel, Mode: e.d.Type(), Pruned: e.pruned})
+		}
+		files = append(files, f)
+	}
+	m.walkIndexMu.Unlock()
+
+	for _, f := range files {
+		path := m.walkCachePath(f.Root)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return
+		}
+		data, err := json.Marshal(f)
+		if err != nil {
+			continue
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil { //nolint:gosec // cache file, not sensitive
+			continue
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package context
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWalkCache_ReusedAcrossManagers(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(NoCacheEnvVar, "")
+	dir := t.TempDir()
+	for _, rel := range []string{"a.go", "b.md", "sub/c.go"} {
+		fsWriteString(t, filepath.Join(dir, rel), "package x\n")
+	}
+	// Creating .grove moves the root's mtime; projects normally have it.
+	if err := os.MkdirAll(filepath.Join(dir, GroveDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rulesPath := filepath.Join(dir, "test.rules")
+	fsWriteString(t, rulesPath, "**/*.go\n")
+
+	// Each resolution gets a new Manager, so the saved walk is its only
+	// source of a recording.
+	resolve := func() ([]string, int64) {
+		t.Helper()
+		ClearManagerCache()
+		m := NewManagerWithOverride(dir, rulesPath)
+		files, _, err := m.ResolveFilesFromCustomRulesFile(rulesPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files, m.cacheStats.walk.hits.Load()
+	}
+	// saved returns the .go files in the walk cache file.
+	saved := func() []string {
+		t.Helper()
+		entries, err := os.ReadDir(filepath.Join(dir, WalkCacheDir))
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("walk cache entries = %v, %v; want one file", entries, err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, WalkCacheDir, entries[0].Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f walkCacheFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			t.Fatal(err)
+		}
+		if f.Root != dir {
+			t.Errorf("walk cache root = %s, want %s", f.Root, dir)
+		}
+		var files []string
+		for _, e := range f.Entries {
+			if filepath.Ext(e.Path) == ".go" {
+				files = append(files, filepath.ToSlash(e.Path))
+			}
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	if got, hits := resolve(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go"}) || hits != 0 {
+		t.Fatalf("first resolution = %v (%d walk hits)", got, hits)
+	}
+	if got := saved(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go"}) {
+		t.Errorf("saved walk = %v", got)
+	}
+
+	// A fresh Manager replays the saved walk.
+	if got, hits := resolve(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go"}) || hits != 1 {
+		t.Errorf("second resolution = %v (%d walk hits), want the saved walk", got, hits)
+	}
+
+	// A new file moves its directory's mtime, forces a walk and replaces
+	// the saved one.
+	fsWriteString(t, filepath.Join(dir, "sub", "d.go"), "package x\n")
+	if got, hits := resolve(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go", "sub/d.go"}) || hits != 0 {
+		t.Errorf("after new file = %v (%d walk hits)", got, hits)
+	}
+	if got := saved(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go", "sub/d.go"}) {
+		t.Errorf("saved walk after new file = %v", got)
+	}
+
+	// CX_NO_CACHE neither reads nor writes the saved walk.
+	t.Setenv(NoCacheEnvVar, "1")
+	fsWriteString(t, filepath.Join(dir, "e.go"), "package x\n")
+	if got, hits := resolve(); !reflect.DeepEqual(got, []string{"a.go", "e.go", "sub/c.go", "sub/d.go"}) || hits != 0 {
+		t.Errorf("CX_NO_CACHE resolution = %v (%d walk hits)", got, hits)
+	}
+	if got := saved(); !reflect.DeepEqual(got, []string{"a.go", "sub/c.go", "sub/d.go"}) {
+		t.Errorf("CX_NO_CACHE rewrote the saved walk: %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package context
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// WalkWarning is a file or directory a walk skipped: one it could not read
+// (a permission-denied system directory under an allowed root, a temp file
+// that vanished mid-walk, a path over the platform length limit) or a
+// socket, named pipe or device node.
+type WalkWarning struct {
+	Path string `json:"path"`
+	Err  string `json:"error"`
+}
+
+// strictWalkDefault makes every Manager fail fast on walk errors; see
+// SetStrictWalk.
+var strictWalkDefault atomic.Bool
+
+// SetStrictWalk sets the process-wide default for strict walks, under which
+// the first unreadable file or directory aborts resolution instead of being
+// skipped with a warning. The CLI sets it from --strict-walk; library users
+// can opt single managers in with WithStrictWalk.
+func SetStrictWalk(strict bool) {
+	strictWalkDefault.Store(strict)
+}
+
+func (m *Manager) strictWalkEnabled() bool {
+	return m.strictWalk || strictWalkDefault.Load()
+}
+
+// walkError records an error a walk hit at path. It returns the error to
+// abort the walk under strict walks and nil otherwise, in which case the
+// caller skips the entry.
+func (m *Manager) walkError(path string, err error) error {
+	err = describePathError(path, err)
+	first := m.recordWalkWarning(path, err.Error())
+	if m.strictWalkEnabled() {
+		return fmt.Errorf("walking %s: %w (--strict-walk)", path, err)
+	}
+	if first {
+		Warnf("skipping unreadable path %s: %v", path, err)
+	}
+	return nil
+}
+
+// skipSpecialEntry records a socket, named pipe or device node a walk
+// passed over. These are skipped even under strict walks.
+func (m *Manager) skipSpecialEntry(path, kind string) {
+	if m.recordWalkWarning(path, fmt.Sprintf("%s: %v", kind, errSpecialFile)) {
+		Warnf("skipping %s %s", kind, path)
+	}
+}
+
+// recordWalkWarning adds a warning for path and reports whether it is the
+// first for that path. Each path is reported once per Manager, however many
+// passes walk it.
+func (m *Manager) recordWalkWarning(path, msg string) bool {
+	m.walkMu.Lock()
+	defer m.walkMu.Unlock()
+	if m.walkSeen[path] {
+		return false
+	}
+	if m.walkSeen == nil {
+		m.walkSeen = make(map[string]bool)
+	}
+	m.walkSeen[path] = true
+	m.walkWarnings = append(m.walkWarnings, WalkWarning{Path: path, Err: msg})
+	return true
+}
+
+// GetWalkWarnings returns the entries walks have skipped since the last
+// ClearWalkWarnings.
+func (m *Manager) GetWalkWarnings() []WalkWarning {
+	m.walkMu.Lock()
+	defer m.walkMu.Unlock()
+	return append([]WalkWarning(nil), m.walkWarnings...)
+}
+
+// ClearWalkWarnings forgets skipped walk entries, so they are reported
+// again the next time a walk reaches them.
+func (m *Manager) ClearWalkWarnings() {
+	m.walkMu.Lock()
+	defer m.walkMu.Unlock()
+	m.walkWarnings = nil
+	m.walkSeen = nil
+}
+
+// strictWalkFailure returns the first walk error recorded after the first
+// `since` warnings, when strict walks are on. Node resolution swallows
+// walk errors, so resolveRulesViaAST checks for them after each pass.
+func (m *Manager) strictWalkFailure(since int) error {
+	if !m.strictWalkEnabled() {
+		return nil
+	}
+	m.walkMu.Lock()
+	defer m.walkMu.Unlock()
+	if len(m.walkWarnings) <= since {
+		return nil
+	}
+	w := m.walkWarnings[since]
+	return fmt.Errorf("walking %s: %s (--strict-walk)", w.Path, w.Err)
+}
+
+// walkWarningCount is len(GetWalkWarnings()) without the copy.
+func (m *Manager) walkWarningCount() int {
+	m.walkMu.Lock()
+	defer m.walkMu.Unlock()
+	return len(m.walkWarnings)
+}
@@ -0,0 +1,50 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalkSkipsUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits are not enforced for root")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "locked/b.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	locked := filepath.Join(dir, "locked")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	rules := []RuleInfo{{Pattern: "**/*.go", LineNum: 1, EffectiveLineNum: 1}}
+
+	m := NewManager(dir, WithNoState())
+	files, err := m.resolveFilesViaAST(rules)
+	if err != nil {
+		t.Fatalf("unreadable directory should be skipped, got %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"a.go"}) {
+		t.Errorf("files = %v, want [a.go]", files)
+	}
+	warnings := m.GetWalkWarnings()
+	if len(warnings) != 1 || filepath.Base(warnings[0].Path) != "locked" {
+		t.Errorf("walk warnings = %+v, want one for locked/", warnings)
+	}
+
+	strict := NewManager(dir, WithNoState(), WithStrictWalk())
+	if _, err := strict.resolveFilesViaAST(rules); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("strict walk should fail on locked/, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package context
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Walk index.
+//
+// Every pattern resolves by walking its root, so a rules file with forty
+// patterns under one project walks that project forty times, and the
+// long-running modes (cx watch, cx serve, the TUI) repeat all of it on every
+// rules edit. The walk index records a root's filtered walk once per Manager
+// (gitignored, special and binary entries dropped, junk directories listed
+// but not entered) together with the mtime of every directory it entered,
+// and later walks of that root replay the recording. A moved directory mtime
+// (an entry created, removed or renamed in it) discards the recording.
+//
+// Each recording also remembers the files every pattern matched. After a
+// rules edit the unchanged lines are answered from that memo and only new or
+// edited patterns are matched, against the recording rather than the disk,
+// which keeps re-resolution on a rules save well under a second in large
+// ecosystems. File contents are never indexed: @grep:/@find: filters and
+// generated directives are evaluated afresh every time. Recordings are also
+// saved across invocations (walkcache.go). CX_NO_CACHE bypasses the index.
+
+// walkIndex is the recorded walk of one root.
+type walkIndex struct {
+	entries []walkIndexEntry
+	dirs    map[string]time.Time // every directory entered, with its mtime
+	matches map[string][]string  // base dir, pattern and polarity -> matched paths; guarded by Manager.walkIndexMu
+	ignored string               // fingerprint of the gitignored set the walk was filtered by
+	binary  string               // binaryPolicy key the walk was filtered by
+	dirty   bool                 // changed since last saved; guarded by Manager.walkIndexMu
+}
+
+type walkIndexEntry struct {
+	path   string
+	d      fs.DirEntry
+	pruned bool // a junk directory, recorded without its contents
+}
+
+// current reports whether no directory the index entered has changed.
+func (idx *walkIndex) current(stats *statCache) bool {
+	for dir, mtime := range idx.dirs {
+		info, err := stats.stat(dir)
+		if err != nil || !info.IsDir() || !info.ModTime().Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// replay feeds the recorded entries to fn as filepath.WalkDir would,
+// honouring SkipDir and SkipAll. A pruned directory is never entered, which
+// is what walkAndEmit asks for unless the pattern names a junk directory;
+// such patterns do not use the index.
+func (idx *walkIndex) replay(_ string, fn fs.WalkDirFunc) error {
+	skip := ""
+	for _, e := range idx.entries {
+		if skip != "" && strings.HasPrefix(e.path, skip) {
+			continue
+		}
+		skip = ""
+		err := fn(e.path, e.d, nil)
+		switch {
+		case err == nil:
+			if e.pruned {
+				skip = e.path + string(filepath.Separator)
+			}
+		case errors.Is(err, filepath.SkipDir):
+			if e.d.IsDir() {
+				skip = e.path + string(filepath.Separator)
+			} else {
+				skip = filepath.Dir(e.path) + string(filepath.Separator)
+			}
+		case errors.Is(err, filepath.SkipAll):
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// indexedWalker is implemented by resolution contexts that keep a walk
+// index. indexedMatches returns the paths collect finds under root for
+// pattern, from the pattern memo when the root is unchanged.
+type indexedWalker interface {
+	indexedMatches(root, pattern string, excluded bool, collect func(walk func(string, fs.WalkDirFunc) error) []string) []string
+}
+
+func (c *prodResolutionContext) indexedMatches(root, pattern string, excluded bool, collect func(walk func(string, fs.WalkDirFunc) error) []string) []string {
+	if c.fileSet != nil || c.keepBinary || cachesDisabled() || referencesJunkDir(pattern) {
+		return collect(c.WalkDir)
+	}
+	idx := c.walkIndexFor(root)
+	if idx == nil {
+		return collect(c.WalkDir)
+	}
+
+	key := c.BaseDir() + "\x00" + pattern + "\x00"
+	if excluded {
+		key += "!"
+	}
+	c.m.walkIndexMu.Lock()
+	paths, ok := idx.matches[key]
+	c.m.walkIndexMu.Unlock()
+	c.m.cacheStats.patterns.record(ok)
+	if ok {
+		return paths
+	}
+	paths = collect(idx.replay)
+	c.m.walkIndexMu.Lock()
+	idx.matches[key] = paths
+	idx.dirty = true
+	c.m.walkIndexMu.Unlock()
+	return paths
+}
+
+// walkIndexFor returns a current index of root, recording one when there is
+// none or the last one is stale. A root is checked once per invocation (per
+// stat cache). It returns nil when the walk cannot be recorded.
+func (c *prodResolutionContext) walkIndexFor(root string) *walkIndex {
+	if idx := c.stats.checkedIndex(root); idx != nil {
+		return idx
+	}
+	c.m.walkIndexMu.Lock()
+	idx := c.m.walkIndexes[root]
+	c.m.walkIndexMu.Unlock()
+	loaded := false
+	if idx == nil {
+		idx = c.m.loadWalkIndex(root)
+		loaded = idx != nil
+	}
+
+	// An edit to .gitignore moves no directory mtime, so the gitignored set
+	// the recording was filtered by is compared too.
+	current := idx != nil && idx.binary == c.binary.key && idx.ignored == c.m.ignoredFingerprint(root) && idx.current(c.stats)
+	c.m.cacheStats.walk.record(current)
+	if !current {
+		if idx = c.recordWalk(root); idx == nil {
+			return nil
+		}
+	}
+	if !current || loaded {
+		c.m.walkIndexMu.Lock()
+		if c.m.walkIndexes == nil {
+			c.m.walkIndexes = make(map[string]*walkIndex)
+		}
+		c.m.walkIndexes[root] = idx
+		c.m.walkIndexMu.Unlock()
+	}
+	c.stats.markIndex(root, idx)
+	return idx
+}
+
+// walkPrefetcher is implemented by resolution contexts that can record the
+// walks of several roots ahead of resolution.
+type walkPrefetcher interface {
+	prefetchWalks(roots []string)
+}
+
+// prefetchWalks brings the walk index of every root up to date, walking the
+// roots that need it on a bounded pool of workers. Rules spanning sibling
+// projects or absolute roots otherwise walk them one after another. Walks
+// that cannot be recorded are left to resolution, which reports them.
+func (c *prodResolutionContext) prefetchWalks(roots []string) {
+	if c.fileSet != nil || cachesDisabled() || len(roots) < 2 {
+		return
+	}
+	workers := c.m.walkWorkers()
+	if workers > len(roots) {
+		workers = len(roots)
+	}
+	next := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for root := range next {
+				c.walkIndexFor(root)
+			}
+		}()
+	}
+	for _, root := range roots {
+		next <- root
+	}
+	close(next)
+	wg.Wait()
+}
+
+// recordWalk walks root through the production filters and records it. It
+// returns nil when the root is not a directory or an entry could not be
+// read, so that the walk warning recurs on the next resolution.
+func (c *prodResolutionContext) recordWalk(root string) *walkIndex {
+	idx := &walkIndex{dirs: make(map[string]time.Time), matches: make(map[string][]string), binary: c.binary.key, dirty: true}
+	idx.ignored = c.m.ignoredFingerprint(root)
+	warnings := c.m.walkWarningCount()
+	err := c.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		entry := walkIndexEntry{path: path, d: d}
+		if d.IsDir() {
+			if path != root && isJunkDir(d.Name()) {
+				entry.pruned = true
+				idx.entries = append(idx.entries, entry)
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			idx.dirs[path] = info.ModTime()
+		}
+		idx.entries = append(idx.entries, entry)
+		return nil
+	})
+	if _, ok := idx.dirs[root]; err != nil || !ok || c.m.walkWarningCount() != warnings {
+		return nil
+	}
+	return idx
+}
+
+// referencesJunkDir reports whether pattern names a junk directory, which
+// its walk then enters and the index does not record.
+func referencesJunkDir(pattern string) bool {
+	for name := range junkDirNames {
+		if patternReferencesDir(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
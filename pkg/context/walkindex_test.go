@@ -0,0 +1,172 @@
+package context
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWalkIndex_ReuseAndInvalidate(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(NoCacheEnvVar, "")
+	dir := t.TempDir()
+	for _, rel := range []string{"a.go", "b.md", "sub/c.go", "node_modules/dep/x.go"} {
+		fsWriteString(t, filepath.Join(dir, rel), "package x\n")
+	}
+	rulesPath := filepath.Join(dir, "test.rules")
+	fsWriteString(t, rulesPath, "*.go\n")
+	m := NewManagerWithOverride(dir, rulesPath)
+
+	resolve := func(rules string) []string {
+		t.Helper()
+		nodes, perrs := ParseToAST([]byte(rules))
+		if len(perrs) != 0 {
+			t.Fatalf("ParseToAST(%q): %+v", rules, perrs)
+		}
+		attr, _, _, _ := ResolveAST(nodes, newProdResolutionContext(m))
+		var files []string
+		for _, paths := range attr {
+			for _, p := range paths {
+				rel, _ := filepath.Rel(dir, p)
+				files = append(files, filepath.ToSlash(rel))
+			}
+		}
+		sort.Strings(files)
+		return files
+	}
+	index := func() *walkIndex {
+		m.walkIndexMu.Lock()
+		defer m.walkIndexMu.Unlock()
+		return m.walkIndexes[m.rulesBaseDir]
+	}
+
+	if got, want := resolve("*.go\n"), []string{"a.go", "sub/c.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("first resolution = %v, want %v", got, want)
+	}
+	first := index()
+	if first == nil {
+		t.Fatal("no walk index recorded")
+	}
+
+	// A rules edit reuses the recording; the new line is matched against it.
+	if got, want := resolve("*.go\n*.md\n"), []string{"a.go", "b.md", "sub/c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after rules edit = %v, want %v", got, want)
+	}
+	if index() != first {
+		t.Error("unchanged tree was walked again")
+	}
+	if n := len(first.matches); n != 2 {
+		t.Errorf("pattern memo has %d entries, want 2", n)
+	}
+
+	// A file created in a subdirectory invalidates the recording.
+	fsWriteString(t, filepath.Join(dir, "sub", "d.go"), "package x\n")
+	if got, want := resolve("*.go\n"), []string{"a.go", "sub/c.go", "sub/d.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after new file = %v, want %v", got, want)
+	}
+	if index() == first {
+		t.Error("stale walk index reused after a directory changed")
+	}
+
+	// Patterns naming a junk directory walk into it, bypassing the index.
+	if got, want := resolve("node_modules/**\n"), []string{"node_modules/dep/x.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("junk re-include = %v, want %v", got, want)
+	}
+}
+
+// TestWalkIndex_GitignoreEdit verifies that an in-place .gitignore edit,
+// which moves no directory mtime, invalidates a manager's recording.
+func TestWalkIndex_GitignoreEdit(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(NoCacheEnvVar, "")
+	dir := t.TempDir()
+	if err := exec.Command("git", "-C", dir, "init", "-q").Run(); err != nil {
+		t.Skipf("git init: %v", err)
+	}
+	fsWriteString(t, filepath.Join(dir, ".gitignore"), "# nothing\n")
+	fsWriteString(t, filepath.Join(dir, "a.go"), "package x\n")
+	fsWriteString(t, filepath.Join(dir, "b.go"), "package x\n")
+	m := NewManagerWithOverride(dir, filepath.Join(dir, "test.rules"))
+
+	resolve := func() []string {
+		t.Helper()
+		nodes, _ := ParseToAST([]byte("*.go\n"))
+		attr, _, _, _ := ResolveAST(nodes, newProdResolutionContext(m))
+		var files []string
+		for _, paths := range attr {
+			for _, p := range paths {
+				files = append(files, filepath.Base(p))
+			}
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	if got, want := resolve(), []string{"a.go", "b.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("first resolution = %v, want %v", got, want)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("b.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resolve(), []string{"a.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after .gitignore edit = %v, want %v", got, want)
+	}
+}
+
+func TestWalkRoots(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	for _, rel := range []string{"a/x.go", "b/y.go"} {
+		fsWriteString(t, filepath.Join(dir, rel), "package x\n")
+	}
+	nodes, perrs := ParseToAST([]byte("*.go\n" + a + "/**/*.go\n" + b + "/**\n" + a + "/x.go\nnode_modules/**\n"))
+	if len(perrs) != 0 {
+		t.Fatalf("ParseToAST: %+v", perrs)
+	}
+	if got, want := walkRoots(nodes, dir), []string{dir, a, b}; !reflect.DeepEqual(got, want) {
+		t.Errorf("walkRoots = %v, want %v", got, want)
+	}
+}
+
+// TestPrefetchWalks verifies that the roots of a multi-root rule list are
+// recorded before resolution and resolve to the same files.
+func TestPrefetchWalks(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv(NoCacheEnvVar, "")
+	dir := t.TempDir()
+	roots := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	var rules string
+	for _, root := range roots {
+		fsWriteString(t, filepath.Join(root, "main.go"), "package main\n")
+		fsWriteString(t, filepath.Join(root, "sub", "util.go"), "package sub\n")
+		rules += root + "/**/*.go\n"
+	}
+	m := NewManager(dir, WithNoState())
+	nodes, perrs := ParseToAST([]byte(rules))
+	if len(perrs) != 0 {
+		t.Fatalf("ParseToAST: %+v", perrs)
+	}
+	attr, _, _, _ := ResolveAST(nodes, newProdResolutionContext(m))
+	var files []string
+	for _, paths := range attr {
+		files = append(files, paths...)
+	}
+	sort.Strings(files)
+	var want []string
+	for _, root := range roots {
+		want = append(want, filepath.Join(root, "main.go"), filepath.Join(root, "sub", "util.go"))
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("resolved %v, want %v", files, want)
+	}
+	m.walkIndexMu.Lock()
+	defer m.walkIndexMu.Unlock()
+	for _, root := range roots {
+		if m.walkIndexes[root] == nil {
+			t.Errorf("no walk index recorded for %s", root)
+		}
+	}
+}
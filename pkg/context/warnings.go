@@ -0,0 +1,96 @@
+package context
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Warnings. Resolution reports recoverable problems (an unresolvable
+// import, a rule that matched nothing, an unreadable path) from deep inside
+// the rules engine, and printed on the spot they end up interleaved with a
+// command's output. Once the CLI calls SetCollectWarnings, Warnf holds them
+// instead, folding repeats into one entry, and the command prints them
+// together when it finishes (and adds them to --json output). Library
+// users and long-running commands keep the immediate printing.
+
+// Warning is a collected warning and the number of times it was raised.
+type Warning struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+var warnings struct {
+	mu         sync.Mutex
+	collecting bool
+	list       []Warning
+	index      map[string]int // message -> position in list
+}
+
+// SetCollectWarnings turns warning collection on or off for the process.
+// Turning it off prints and discards whatever was collected.
+func SetCollectWarnings(collect bool) {
+	warnings.mu.Lock()
+	warnings.collecting = collect
+	warnings.mu.Unlock()
+	if !collect {
+		PrintWarnings(os.Stderr, TakeWarnings())
+	}
+}
+
+// Warnf reports a warning. It prints "Warning: <message>" to stderr, or
+// records it when collection is on.
+func Warnf(format string, args ...any) {
+	msg := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	warnings.mu.Lock()
+	defer warnings.mu.Unlock()
+	if !warnings.collecting {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+		return
+	}
+	if i, ok := warnings.index[msg]; ok {
+		warnings.list[i].Count++
+		return
+	}
+	if warnings.index == nil {
+		warnings.index = make(map[string]int)
+	}
+	warnings.index[msg] = len(warnings.list)
+	warnings.list = append(warnings.list, Warning{Message: msg, Count: 1})
+}
+
+// TakeWarnings returns the collected warnings in the order they were first
+// raised and clears them.
+func TakeWarnings() []Warning {
+	warnings.mu.Lock()
+	defer warnings.mu.Unlock()
+	list := warnings.list
+	warnings.list, warnings.index = nil, nil
+	return list
+}
+
+// PrintWarnings writes a summary of list to w. It writes nothing for an
+// empty list.
+func PrintWarnings(w io.Writer, list []Warning) {
+	if len(list) == 0 {
+		return
+	}
+	if len(list) == 1 {
+		fmt.Fprintf(w, "Warning: %s%s\n", list[0].Message, repeatSuffix(list[0].Count))
+		return
+	}
+	fmt.Fprintf(w, "%d warnings:\n", len(list))
+	for _, warning := range list {
+		msg := strings.ReplaceAll(warning.Message, "\n", "\n    ")
+		fmt.Fprintf(w, "  - %s%s\n", msg, repeatSuffix(warning.Count))
+	}
+}
+
+func repeatSuffix(count int) string {
+	if count < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (x%d)", count)
+}
@@ -0,0 +1,39 @@
+package context
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWarnfCollects(t *testing.T) {
+	SetCollectWarnings(true)
+	t.Cleanup(func() {
+		TakeWarnings()
+		SetCollectWarnings(false)
+	})
+
+	Warnf("rule '%s' matched 0 files", "a/**")
+	Warnf("could not read %s\n", "b.go")
+	Warnf("rule '%s' matched 0 files", "a/**")
+
+	got := TakeWarnings()
+	want := []Warning{{Message: "rule 'a/**' matched 0 files", Count: 2}, {Message: "could not read b.go", Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("TakeWarnings() = %v, want %v", got, want)
+	}
+	if rest := TakeWarnings(); len(rest) != 0 {
+		t.Errorf("second TakeWarnings() = %v, want none", rest)
+	}
+
+	var out bytes.Buffer
+	PrintWarnings(&out, want)
+	if got, want := out.String(), "2 warnings:\n  - rule 'a/**' matched 0 files (x2)\n  - could not read b.go\n"; got != want {
+		t.Errorf("PrintWarnings wrote %q, want %q", got, want)
+	}
+	out.Reset()
+	PrintWarnings(&out, []Warning{{Message: "stale:\n  a.go", Count: 1}})
+	if got, want := out.String(), "Warning: stale:\n  a.go\n"; got != want {
+		t.Errorf("PrintWarnings wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package context
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WatchTargets returns what `cx watch` observes to notice that the last
+// generated context is out of date: the directories resolution walked
+// (where a new file can start matching, from the walk index; see
+// walkindex.go) together with the directories of the generated files, and
+// the generated files themselves. Paths are absolute. Directories holding
+// generated artifacts are left out, or every regeneration would trigger the
+// next one.
+func (m *Manager) WatchTargets() (dirs []string, files map[string]bool) {
+	skip := []string{filepath.Join(m.workDir, GroveDir)}
+	for _, p := range []string{m.ResolveContextPath(), m.ResolveCachedContextPath()} {
+		skip = append(skip, filepath.Dir(p))
+	}
+	skipped := func(dir string) bool {
+		for _, s := range skip {
+			if dir == s || strings.HasPrefix(dir, s+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	dirSet := make(map[string]bool)
+	m.walkIndexMu.Lock()
+	for _, idx := range m.walkIndexes {
+		for dir := range idx.dirs {
+			dirSet[dir] = true
+		}
+	}
+	m.walkIndexMu.Unlock()
+
+	files = make(map[string]bool)
+	m.genMu.Lock()
+	for _, tierFiles := range m.lastGenerated {
+		for _, f := range tierFiles {
+			path := f
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(m.workDir, path)
+			}
+			files[path] = true
+			dirSet[filepath.Dir(path)] = true
+		}
+	}
+	m.genMu.Unlock()
+
+	for dir := range dirSet {
+		if !skipped(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, files
+}
+
+// IsEditorTempFile reports whether path looks like an editor's swap, backup
+// or atomic-save temporary file, whose creation says nothing about the
+// context.
+func IsEditorTempFile(path string) bool {
+	base := filepath.Base(path)
+	switch {
+	case base == "4913", // vim's write-permission probe
+		strings.HasSuffix(base, "~"),
+		strings.HasPrefix(base, ".#"),
+		strings.HasSuffix(base, ".swp"), strings.HasSuffix(base, ".swx"), strings.HasSuffix(base, ".swo"),
+		strings.HasSuffix(base, ".tmp"),
+		strings.HasPrefix(base, "#") && strings.HasSuffix(base, "#"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package context
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchTargets(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+	m := NewManager(dir, WithNoState())
+	m.walkIndexes = map[string]*walkIndex{dir: {dirs: map[string]time.Time{
+		dir:                               {},
+		filepath.Join(dir, "pkg"):         {},
+		filepath.Join(dir, GroveDir):      {},
+		filepath.Join(dir, GroveDir, "x"): {},
+	}}}
+	m.lastGenerated = map[string][]string{
+		"hot":  {"pkg/a.go"},
+		"cold": {"/elsewhere/lib/b.go"},
+	}
+
+	dirs, files := m.WatchTargets()
+	if want := []string{"/elsewhere/lib", dir, filepath.Join(dir, "pkg")}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("dirs = %v, want %v", dirs, want)
+	}
+	if !files[filepath.Join(dir, "pkg", "a.go")] || !files["/elsewhere/lib/b.go"] || len(files) != 2 {
+		t.Errorf("files = %v", files)
+	}
+}
+
+func TestIsEditorTempFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"main.go": false, ".main.go.swp": true, "main.go~": true, "4913": true,
+		".#main.go": true, "#main.go#": true, "notes.tmp": true, ".gitignore": false,
+	} {
+		if got := IsEditorTempFile(path); got != want {
+			t.Errorf("IsEditorTempFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package lang detects the language of a file from its name and first bytes.
+// It is shared by stats (language distribution) and by anything that renders
+// file content as markdown, so both agree on what a file is and which
+// code-fence tag it gets.
+//
+// A language is identified by its code-fence tag ("go", "python", "bash").
+// Detection tries, in order: the user mapping, an editor modeline (vim or
+// emacs), well-known filenames, the extension, and finally the shebang line.
+package lang
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// HeadSize is how much of a file Detect needs to see for shebang and
+// modeline detection.
+const HeadSize = 1024
+
+// modelineLines is how many leading lines are searched for a modeline.
+const modelineLines = 5
+
+var extensions = map[string]string{
+	".go": "go", ".py": "python", ".pyi": "python", ".rb": "ruby", ".rs": "rust",
+	".js": "javascript", ".mjs": "javascript", ".cjs": "javascript", ".jsx": "jsx",
+	".ts": "typescript", ".mts": "typescript", ".cts": "typescript", ".tsx": "tsx",
+	".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".cxx": "cpp", ".hpp": "cpp", ".hh": "cpp",
+	".m": "objectivec", ".mm": "objectivec", ".java": "java", ".kt": "kotlin", ".kts": "kotlin",
+	".scala": "scala", ".groovy": "groovy", ".gradle": "groovy", ".swift": "swift", ".dart": "dart",
+	".cs": "csharp", ".fs": "fsharp", ".fsx": "fsharp", ".vb": "vbnet",
+	".php": "php", ".pl": "perl", ".pm": "perl", ".lua": "lua", ".r": "r", ".jl": "julia",
+	".ex": "elixir", ".exs": "elixir", ".erl": "erlang", ".hrl": "erlang", ".gleam": "gleam",
+	".hs": "haskell", ".ml": "ocaml", ".mli": "ocaml", ".clj": "clojure", ".cljs": "clojure",
+	".el": "elisp", ".lisp": "lisp", ".scm": "scheme", ".rkt": "racket",
+	".zig": "zig", ".nim": "nim", ".v": "v", ".d": "d", ".odin": "odin", ".sol": "solidity",
+	".sh": "bash", ".bash": "bash", ".zsh": "zsh", ".fish": "fish", ".ps1": "powershell",
+	".bat": "batch", ".cmd": "batch", ".awk": "awk", ".tcl": "tcl", ".vim": "vim", ".nix": "nix",
+	".sql": "sql", ".graphql": "graphql", ".gql": "graphql", ".proto": "protobuf",
+	".tf": "hcl", ".hcl": "hcl", ".cue": "cue", ".dhall": "dhall",
+	".html": "html", ".htm": "html", ".xml": "xml", ".svg": "xml", ".css": "css",
+	".scss": "scss", ".sass": "sass", ".less": "less", ".vue": "vue", ".svelte": "svelte",
+	".md": "markdown", ".markdown": "markdown", ".mdx": "mdx", ".rst": "rst", ".adoc": "asciidoc",
+	".tex": "latex", ".json": "json", ".jsonc": "jsonc", ".yaml": "yaml", ".yml": "yaml",
+	".toml": "toml", ".ini": "ini", ".cfg": "ini", ".csv": "csv", ".diff": "diff", ".patch": "diff",
+	".mk": "makefile", ".cmake": "cmake", ".dockerfile": "dockerfile", ".rules": "gitignore",
+}
+
+var filenames = map[string]string{
+	"Makefile": "makefile", "GNUmakefile": "makefile", "makefile": "makefile",
+	"Dockerfile": "dockerfile", "Containerfile": "dockerfile", "Justfile": "just", "justfile": "just",
+	"CMakeLists.txt": "cmake", "Jenkinsfile": "groovy", "Rakefile": "ruby", "Gemfile": "ruby",
+	"Vagrantfile": "ruby", "Podfile": "ruby", "BUILD": "starlark", "BUILD.bazel": "starlark",
+	"WORKSPACE": "starlark", "Tiltfile": "starlark", "go.mod": "go.mod", "go.sum": "text",
+	".gitignore": "gitignore", ".dockerignore": "gitignore", ".grovectx": "gitignore",
+	".bashrc": "bash", ".bash_profile": "bash", ".zshrc": "zsh", ".profile": "sh",
+}
+
+// interpreters maps a shebang interpreter (after any /usr/bin/env) to a language.
+var interpreters = map[string]string{
+	"sh": "sh", "dash": "sh", "ash": "sh", "bash": "bash", "ksh": "bash", "zsh": "zsh", "fish": "fish",
+	"python": "python", "python2": "python", "python3": "python", "pypy": "python", "uv": "python",
+	"node": "javascript", "nodejs": "javascript", "deno": "typescript", "bun": "typescript",
+	"ts-node": "typescript", "tsx": "typescript", "ruby": "ruby", "perl": "perl", "php": "php",
+	"lua": "lua", "luajit": "lua", "Rscript": "r", "julia": "julia", "tclsh": "tcl", "wish": "tcl",
+	"awk": "awk", "gawk": "awk", "elixir": "elixir", "escript": "erlang", "guile": "scheme",
+	"racket": "racket", "runhaskell": "haskell", "stack": "haskell", "pwsh": "powershell",
+	"osascript": "applescript", "make": "makefile", "nix-shell": "nix",
+}
+
+// modeline aliases that differ from the fence tag they denote.
+var modeAliases = map[string]string{
+	"sh": "bash", "shell-script": "bash", "js": "javascript", "ts": "typescript",
+	"py": "python", "rb": "ruby", "emacs-lisp": "elisp", "c++": "cpp", "yml": "yaml",
+	"make": "makefile", "md": "markdown", "text": "text", "conf": "ini",
+}
+
+var (
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vim?|ex):.*?\b(?:ft|filetype|syntax)=([A-Za-z0-9_+.-]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-\s*(?:.*?\bmode:\s*([A-Za-z0-9_+-]+)|([A-Za-z0-9_+-]+)\s*-\*-)`)
+)
+
+// Detector detects languages with an optional user mapping layered over the
+// built-in tables. The zero value uses the built-in tables only.
+type Detector struct {
+	// mapping keys are extensions (".tpl") or exact filenames ("Brewfile");
+	// values are fence tags.
+	mapping map[string]string
+}
+
+// New returns a Detector whose mapping overrides the built-in tables. Keys
+// are extensions (with the leading dot, matched case-insensitively) or exact
+// base filenames; values are fence tags.
+func New(mapping map[string]string) *Detector {
+	d := &Detector{mapping: make(map[string]string, len(mapping))}
+	for k, v := range mapping {
+		if strings.HasPrefix(k, ".") && filepath.Ext(k) == k {
+			k = strings.ToLower(k)
+		}
+		d.mapping[k] = v
+	}
+	return d
+}
+
+// Detect returns the fence tag for the file at path, given up to HeadSize
+// leading bytes of its content (head may be nil). It returns "" when the
+// language is unknown.
+func (d *Detector) Detect(path string, head []byte) string {
+	base := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(base))
+	if d != nil && d.mapping != nil {
+		if l, ok := d.mapping[base]; ok {
+			return l
+		}
+		if l, ok := d.mapping[ext]; ok && ext != "" {
+			return l
+		}
+	}
+	if l := modeline(head); l != "" {
+		return l
+	}
+	if l, ok := filenames[base]; ok {
+		return l
+	}
+	if l, ok := extensions[ext]; ok {
+		return l
+	}
+	if strings.HasPrefix(base, "Dockerfile.") || strings.HasPrefix(base, "Containerfile.") {
+		return "dockerfile"
+	}
+	return shebang(head)
+}
+
+// DetectFile is Detect reading the head of the file itself, only when the
+// name alone is not enough.
+func (d *Detector) DetectFile(path string) string {
+	if l := d.Detect(path, nil); l != "" {
+		return l
+	}
+	head, err := ReadHead(path)
+	if err != nil {
+		return ""
+	}
+	return d.Detect(path, head)
+}
+
+// Detect detects a language with the built-in tables only.
+func Detect(path string, head []byte) string {
+	return (*Detector)(nil).Detect(path, head)
+}
+
+// Fence returns the code-fence info string for the file at path: its fence
+// tag, or "" (a plain fence) when unknown.
+func (d *Detector) Fence(path string, head []byte) string {
+	if l := d.Detect(path, head); l != "text" {
+		return l
+	}
+	return ""
+}
+
+// ReadHead reads up to HeadSize leading bytes of the file at path.
+func ReadHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, HeadSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// shebang maps a "#!" interpreter line to a language.
+func shebang(head []byte) string {
+	if !bytes.HasPrefix(head, []byte("#!")) {
+		return ""
+	}
+	line := head[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" {
+		interp = ""
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue // env flags (-S) and VAR=value assignments
+			}
+			interp = filepath.Base(f)
+			break
+		}
+	}
+	if l, ok := interpreters[interp]; ok {
+		return l
+	}
+	// python3.12, ruby3.2, perl5.36 ...
+	if trimmed := strings.TrimRight(interp, "0123456789."); trimmed != interp {
+		return interpreters[trimmed]
+	}
+	return ""
+}
+
+// modeline finds a vim or emacs modeline in the first few lines of head.
+func modeline(head []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	for i := 0; i < modelineLines && scanner.Scan(); i++ {
+		line := scanner.Text()
+		var mode string
+		if m := vimModeline.FindStringSubmatch(line); m != nil {
+			mode = m[1]
+		} else if m := emacsModeline.FindStringSubmatch(line); m != nil {
+			mode = m[1] + m[2]
+		}
+		if mode == "" {
+			continue
+		}
+		mode = strings.ToLower(strings.TrimSuffix(mode, "-mode"))
+		if alias, ok := modeAliases[mode]; ok {
+			return alias
+		}
+		return mode
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package lang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		head string
+		want string
+	}{
+		{"extension", "pkg/main.go", "", "go"},
+		{"extension case-insensitive", "lib/Util.PY", "", "python"},
+		{"filename", "build/Makefile", "", "makefile"},
+		{"dockerfile variant", "Dockerfile.dev", "", "dockerfile"},
+		{"shebang", "bin/deploy", "#!/bin/bash\nset -e\n", "bash"},
+		{"env shebang", "scripts/run", "#!/usr/bin/env -S python3 -u\n", "python"},
+		{"versioned interpreter", "tool", "#!/usr/bin/python3.12\n", "python"},
+		{"vim modeline", "conf/app.in", "# vim: set ft=nginx :\n", "nginx"},
+		{"emacs modeline", "x.txt", "# -*- mode: ruby -*-\n", "ruby"},
+		{"emacs short modeline", "build.inc", ";; -*- emacs-lisp -*-\n", "elisp"},
+		{"emacs coding only", "notes", "# -*- coding: utf-8 -*-\n", ""},
+		{"unknown", "data.xyz", "hello\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.path, []byte(tt.head)); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectorMapping(t *testing.T) {
+	d := New(map[string]string{".TPL": "gotmpl", "Brewfile": "ruby", ".go": "golang"})
+	for path, want := range map[string]string{
+		"web/index.tpl": "gotmpl",
+		"Brewfile":      "ruby",
+		"main.go":       "golang",
+		"main.rs":       "rust",
+	} {
+		if got := d.Detect(path, nil); got != want {
+			t.Errorf("Detect(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if got := d.Fence("go.sum", nil); got != "" {
+		t.Errorf("plain-text files should get a bare fence, got %q", got)
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "release")
+	if err := os.WriteFile(script, []byte("#!/usr/bin/env node\nconsole.log(1)\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := New(nil).DetectFile(script); got != "javascript" {
+		t.Errorf("DetectFile = %q, want javascript", got)
+	}
+	if got := New(nil).DetectFile(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("missing file should be unknown, got %q", got)
+	}
+}
@@ -127,7 +127,7 @@ func (m *rulesPickerModel) loadRulesCmd() tea.Msg {
 	planRules, err := m.manager.ListPlanRules()
 	if err != nil {
 		// Non-fatal error, just log to stderr for debugging
-		fmt.Fprintf(os.Stderr, "Warning: could not load plan-specific rules: %v\n", err)
+		context.Warnf("could not load plan-specific rules: %v", err)
 	} else {
 		activePlan := m.manager.GetActivePlanName()
 		for _, rule := range planRules {
@@ -15,6 +15,9 @@ type pickerKeyMap struct {
 	Edit    key.Binding
 	Save    key.Binding
 	Delete  key.Binding
+
+	SwitchFocus key.Binding
+	Fullscreen  key.Binding
 }
 
 func (k pickerKeyMap) ShortHelp() []key.Binding {
@@ -23,7 +26,7 @@ func (k pickerKeyMap) ShortHelp() []key.Binding {
 
 func (k pickerKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Confirm, k.Load},
+		{k.Up, k.Down, k.SwitchFocus, k.Fullscreen, k.Confirm, k.Load},
 		{k.Save, k.Edit, k.Delete, k.Help, k.Quit},
 	}
 }
@@ -34,7 +37,7 @@ func (k pickerKeyMap) Sections() []keymap.Section {
 	return []keymap.Section{
 		{
 			Name:     "Navigation",
-			Bindings: []key.Binding{k.Up, k.Down},
+			Bindings: []key.Binding{k.Up, k.Down, k.SwitchFocus, k.Fullscreen},
 		},
 		{
 			Name:     "Rules",
@@ -67,6 +70,14 @@ func newPickerKeyMap(cfg *config.Config) pickerKeyMap {
 			key.WithKeys("dd"),
 			key.WithHelp("dd", "delete"),
 		),
+		SwitchFocus: key.NewBinding(
+			key.WithKeys("tab", "left", "right"),
+			key.WithHelp("tab/←/→", "switch list/preview"),
+		),
+		Fullscreen: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "full-screen preview"),
+		),
 	}
 
 	keymap.ApplyTUIOverrides(cfg, "cx", "rules", &km)
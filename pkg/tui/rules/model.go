@@ -71,6 +71,11 @@ type rulesPickerModel struct {
 	deleteConfirmNeeded bool
 	deleteConfirmIdx    int
 
+	// previewFocused routes navigation keys to the preview instead of the
+	// list; previewFullscreen gives the preview the whole screen.
+	previewFocused    bool
+	previewFullscreen bool
+
 	hosted bool
 }
 
@@ -95,6 +100,17 @@ func (m *rulesPickerModel) IsSaveMode() bool {
 }
 
 func (m *rulesPickerModel) updatePreviewSize() {
+	m.preview.Width = m.width - 4 // Account for padding/border
+
+	if m.previewFullscreen {
+		// Only the preview box (border, title) and the help footer remain
+		m.preview.Height = m.height - 5
+		if m.preview.Height < 3 {
+			m.preview.Height = 3
+		}
+		return
+	}
+
 	// Calculate space needed for fixed elements
 	headerHeight := 3               // Header + empty line
 	tableHeight := len(m.items) + 3 // Table with borders
@@ -130,6 +146,5 @@ func (m *rulesPickerModel) updatePreviewSize() {
 		previewHeight = 3 // Minimum height
 	}
 
-	m.preview.Width = m.width - 4 // Account for padding/border
 	m.preview.Height = previewHeight
 }
@@ -230,7 +230,30 @@ func (m *rulesPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.help.Toggle()
 			return m, nil
 		}
+
+		// In full-screen preview every key scrolls the preview; quit and
+		// the toggle return to the list.
+		if m.previewFullscreen {
+			if key.Matches(msg, m.keys.Quit) || key.Matches(msg, m.keys.Fullscreen) {
+				m.previewFullscreen = false
+				m.updatePreviewSize()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.preview, cmd = m.preview.Update(msg)
+			return m, cmd
+		}
+
 		switch {
+		case key.Matches(msg, m.keys.SwitchFocus):
+			m.previewFocused = !m.previewFocused
+			return m, nil
+		case key.Matches(msg, m.keys.Fullscreen):
+			m.previewFullscreen = true
+			m.updatePreviewSize()
+			return m, nil
+		case m.previewFocused && (key.Matches(msg, m.keys.Up) || key.Matches(msg, m.keys.Down)):
+			// Scroll the preview below instead of moving the selection
 		case key.Matches(msg, m.keys.Quit):
 			m.quitting = true
 			return m, func() tea.Msg { return embed.CloseRequestMsg{} }
@@ -336,6 +359,12 @@ func (m *rulesPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Keys only scroll the preview while it has focus, so list navigation
+	// doesn't also move the preview.
+	if _, ok := msg.(tea.KeyMsg); ok && !m.previewFocused {
+		return m, nil
+	}
+
 	// Update preview viewport
 	var cmd tea.Cmd
 	m.preview, cmd = m.preview.Update(msg)
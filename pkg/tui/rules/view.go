@@ -47,6 +47,11 @@ func (m *rulesPickerModel) View() string {
 		)
 	}
 
+	if m.previewFullscreen {
+		helpFooter := theme.DefaultTheme.Muted.Render("j/k • scroll • z/q • back to list")
+		return lipgloss.JoinVertical(lipgloss.Left, m.previewView(), helpFooter)
+	}
+
 	// Build table data - separate general and plan rules
 	var generalRows [][]string
 	var planRows [][]string
@@ -143,21 +148,7 @@ func (m *rulesPickerModel) View() string {
 		)
 	}
 
-	// Render preview pane
-	selectedItem := m.items[m.selectedIndex]
-	previewTitle := fmt.Sprintf("Preview: %s", selectedItem.path)
-	previewStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(theme.DefaultTheme.Colors.MutedText).
-		Padding(0, 1)
-
-	previewView := previewStyle.Render(
-		lipgloss.JoinVertical(
-			lipgloss.Left,
-			theme.DefaultTheme.Bold.Render(previewTitle),
-			m.preview.View(),
-		),
-	)
+	previewView := m.previewView()
 
 	// Render status message
 	statusView := ""
@@ -166,7 +157,7 @@ func (m *rulesPickerModel) View() string {
 	}
 
 	// Render minimal help footer
-	helpFooter := theme.DefaultTheme.Muted.Render("? • help • q/esc • quit")
+	helpFooter := theme.DefaultTheme.Muted.Render("? • help • tab • switch pane • z • full-screen preview • q/esc • quit")
 
 	// Final layout
 	parts := []string{header, "", tableView}
@@ -180,3 +171,36 @@ func (m *rulesPickerModel) View() string {
 
 	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
+
+// previewView renders the bordered preview of the selected rule set. The
+// border is highlighted while the preview has focus, and the title shows the
+// visible line range when the content does not fit.
+func (m *rulesPickerModel) previewView() string {
+	selectedItem := m.items[m.selectedIndex]
+	previewTitle := fmt.Sprintf("Preview: %s", selectedItem.path)
+	if total := m.preview.TotalLineCount(); total > m.preview.Height && m.preview.Height > 0 {
+		last := m.preview.YOffset + m.preview.Height
+		if last > total {
+			last = total
+		}
+		previewTitle += theme.DefaultTheme.Muted.Render(
+			fmt.Sprintf(" (lines %d-%d of %d)", m.preview.YOffset+1, last, total))
+	}
+
+	borderColor := theme.DefaultTheme.Colors.MutedText
+	if m.previewFocused || m.previewFullscreen {
+		borderColor = theme.DefaultTheme.Colors.Violet
+	}
+	previewStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1)
+
+	return previewStyle.Render(
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			theme.DefaultTheme.Bold.Render(previewTitle),
+			m.preview.View(),
+		),
+	)
+}
@@ -0,0 +1,107 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	core_theme "github.com/grovetools/core/tui/theme"
+
+	"github.com/grovetools/cx/pkg/context"
+)
+
+// generateSummary describes a finished in-TUI generation for the result modal.
+type generateSummary struct {
+	hotFiles   int
+	hotTokens  int
+	hotPath    string
+	coldFiles  int
+	coldTokens int
+	coldPath   string
+	warnings   []string
+	elapsed    time.Duration
+}
+
+// generateDoneMsg is sent when a generation started from the TUI finishes.
+type generateDoneMsg struct {
+	summary generateSummary
+	err     error
+}
+
+// generateContextCmd runs the same full generation as `cx generate` (hot,
+// then cold) and summarizes what was written.
+func generateContextCmd(mgr *context.Manager) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		mgr.ClearSkippedRules()
+		if err := mgr.GenerateContext(true); err != nil {
+			return generateDoneMsg{err: err}
+		}
+		if err := mgr.GenerateCachedContext(); err != nil {
+			return generateDoneMsg{err: err}
+		}
+
+		s := generateSummary{
+			hotPath:  mgr.ResolveContextPath(),
+			coldPath: mgr.ResolveCachedContextPath(),
+		}
+		if session, err := mgr.NewResolutionSession(); err == nil {
+			if len(session.Hot) > 0 {
+				if stats, err := mgr.GetStats("hot", session.Hot, 0); err == nil {
+					s.hotFiles, s.hotTokens = stats.TotalFiles, stats.TotalTokens
+				}
+			}
+			if len(session.Cold) > 0 {
+				if stats, err := mgr.GetStats("cold", session.Cold, 0); err == nil {
+					s.coldFiles, s.coldTokens = stats.TotalFiles, stats.TotalTokens
+				}
+			}
+		}
+		for _, r := range mgr.GetSkippedRules() {
+			s.warnings = append(s.warnings, fmt.Sprintf("line %d: %s (%s)", r.LineNum, r.Rule, r.Reason))
+		}
+		s.elapsed = time.Since(start)
+		if err := mgr.RecordUsage("generate", start); err != nil {
+			s.warnings = append(s.warnings, fmt.Sprintf("failed to record usage metrics: %v", err))
+		}
+		return generateDoneMsg{summary: s}
+	}
+}
+
+// renderGenerateResult renders the modal shown after an in-TUI generation,
+// centered in a width x height area.
+func renderGenerateResult(res generateDoneMsg, workDir string, width, height int) string {
+	theme := core_theme.DefaultTheme
+	var b strings.Builder
+	if res.err != nil {
+		b.WriteString(theme.Error.Render("Generation failed"))
+		b.WriteString("\n\n")
+		b.WriteString(res.err.Error())
+	} else {
+		s := res.summary
+		b.WriteString(theme.Success.Render(fmt.Sprintf("Context generated in %s", s.elapsed.Round(time.Millisecond))))
+		b.WriteString("\n\n")
+		fmt.Fprintf(&b, "%s %d files, ~%s tokens\n", theme.Bold.Render("Hot: "), s.hotFiles, context.FormatTokenCount(s.hotTokens))
+		fmt.Fprintf(&b, "      %s\n", theme.Muted.Render(abbreviateRulesPath(s.hotPath, workDir)))
+		fmt.Fprintf(&b, "%s %d files, ~%s tokens\n", theme.Bold.Render("Cold:"), s.coldFiles, context.FormatTokenCount(s.coldTokens))
+		fmt.Fprintf(&b, "      %s", theme.Muted.Render(abbreviateRulesPath(s.coldPath, workDir)))
+		if len(s.warnings) > 0 {
+			b.WriteString("\n\n")
+			b.WriteString(theme.Warning.Render(fmt.Sprintf("%d warnings", len(s.warnings))))
+			for _, w := range s.warnings {
+				b.WriteString("\n  " + w)
+			}
+		}
+	}
+	b.WriteString("\n\n")
+	b.WriteString(theme.Muted.Render("press any key to close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Colors.Violet).
+		Padding(1, 2).
+		Render(b.String())
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
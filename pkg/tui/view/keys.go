@@ -26,6 +26,7 @@ type pagerKeyMap struct {
 	Exclude     key.Binding
 	ExcludeDir  key.Binding
 	ToggleSort  key.Binding
+	Generate    key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the footer.
@@ -43,7 +44,7 @@ func (k pagerKeyMap) Sections() []keymap.Section {
 	return []keymap.Section{
 		keymap.NavigationSection(k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom),
 		keymap.NewSection("Pages", k.NextTab, k.PrevTab, k.Tab1, k.Tab2, k.Tab3, k.Tab4, k.Tab5, k.Tab6, k.Tab7, k.Tab8, k.Tab9),
-		keymap.NewSection(keymap.SectionRules, k.Edit, k.SelectRules, k.Exclude, k.ExcludeDir, k.Base.Refresh),
+		keymap.NewSection(keymap.SectionRules, k.Edit, k.SelectRules, k.Exclude, k.ExcludeDir, k.Generate, k.Base.Refresh),
 		keymap.NewSection("Display", k.ToggleSort),
 		k.Base.FoldSection(),
 		k.Base.SystemSection(),
@@ -73,6 +74,11 @@ func newPagerKeyMap(cfg *config.Config) pagerKeyMap {
 			key.WithKeys("X"),
 			key.WithHelp("X", "exclude dir"),
 		),
+		// Not plain g: that starts the gg (top) chord.
+		Generate: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "generate context"),
+		),
 	}
 	keymap.ApplyTUIOverrides(cfg, "cx", "view", &km)
 
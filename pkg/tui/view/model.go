@@ -13,6 +13,7 @@ import (
 	"github.com/grovetools/core/tui/components/nvim"
 	"github.com/grovetools/core/tui/components/pager"
 	"github.com/grovetools/core/tui/embed"
+	core_theme "github.com/grovetools/core/tui/theme"
 
 	"github.com/grovetools/cx/pkg/context"
 	rulestui "github.com/grovetools/cx/pkg/tui/rules"
@@ -51,7 +52,7 @@ func NewWithStartPage(startPage, workDir, rulesFile string, cfg *config.Config,
 	// NewManagerWithOverride is memoized, so refreshSharedStateCmd will
 	// safely reuse the same instance from the cache.
 	mgr := context.NewManagerWithOverride(workDir, rulesFile)
-	state := &sharedState{workDir: workDir, rulesFileOverride: rulesFile, manager: mgr, loading: true}
+	state := &sharedState{workDir: workDir, rulesFileOverride: rulesFile, manager: mgr, loading: true, thresholds: context.DefaultTokenThresholds}
 
 	pages := []Page{
 		NewRulesPage(state),
@@ -89,6 +90,7 @@ func NewWithStartPage(startPage, workDir, rulesFile string, cfg *config.Config,
 
 	return &pagerModel{
 		pager: p,
+		pages: pages,
 		state: state,
 		keys:  keys,
 		// The single container-level `?` overlay renders the merged,
@@ -106,6 +108,7 @@ func NewWithStartPage(startPage, workDir, rulesFile string, cfg *config.Config,
 
 type pagerModel struct {
 	pager      pager.Model
+	pages      []Page
 	state      *sharedState
 	currentSeq uint64 // monotonic counter for discarding stale refreshes
 	width      int
@@ -133,6 +136,11 @@ type pagerModel struct {
 	// File watcher for the active rules file — triggers refresh on external edits.
 	watcher *RulesWatcher
 
+	// In-TUI generation: generating while it runs, genResult until the
+	// summary modal is dismissed.
+	generating bool
+	genResult  *generateDoneMsg
+
 	hosted bool // True when running inside groveterm; use SplitEditorRequestMsg
 }
 
@@ -152,6 +160,16 @@ func (m *pagerModel) Init() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// persistPages lets every page that remembers per-project state save it,
+// before the view exits or switches to another workspace.
+func (m *pagerModel) persistPages() {
+	for _, p := range m.pages {
+		if sp, ok := p.(statePersister); ok {
+			sp.persistState()
+		}
+	}
+}
+
 // activePageName returns the Name() of whichever cx page is currently
 // focused in the pager. Used by key handlers that only apply to
 // specific tabs (e.g. SelectRules only fires on the rules page).
@@ -172,6 +190,7 @@ func (m *pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case embed.SetWorkspaceMsg:
 		if msg.Node != nil {
+			m.persistPages()
 			m.state.workDir = msg.Node.Path
 		}
 		m.state.loading = true
@@ -297,6 +316,7 @@ func (m *pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// If help is showing, let it handle all keys except quit
 		if m.help.ShowAll {
 			if key.Matches(msg, m.keys.Quit) {
+				m.persistPages()
 				return m, tea.Quit
 			}
 			var cmd tea.Cmd
@@ -304,9 +324,22 @@ func (m *pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		// Any key dismisses the generation summary.
+		if m.genResult != nil {
+			m.genResult = nil
+			return m, nil
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
+			m.persistPages()
 			return m, tea.Quit
+		case key.Matches(msg, m.keys.Generate):
+			if !m.generating {
+				m.generating = true
+				return m, generateContextCmd(m.state.manager)
+			}
+			return m, nil
 		case key.Matches(msg, m.keys.Edit):
 			if m.activePageName() == "rules" {
 				rulesPath := m.state.rulesPath
@@ -358,6 +391,7 @@ func (m *pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if os.Getenv("GROVE_NVIM_PLUGIN") == "true" {
 					m.ExitForNvimEdit = true
 					m.NvimEditPath = rulesPath
+					m.persistPages()
 					return m, tea.Quit
 				}
 
@@ -391,6 +425,11 @@ func (m *pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case generateDoneMsg:
+		m.generating = false
+		m.genResult = &msg
+		return m, nil
+
 	case rulesFileChangedMsg:
 		var cmds []tea.Cmd
 		cmds = append(cmds, m.dispatchRefresh())
@@ -474,10 +513,18 @@ func (m *pagerModel) View() string {
 		return lipgloss.NewStyle().Padding(0, 2).Render(bodyContent)
 	}
 
+	if m.genResult != nil {
+		return renderGenerateResult(*m.genResult, m.state.workDir, m.width, m.height)
+	}
+
 	// Build footer and delegate to pager which pins it at the
 	// bottom of the pane. The pager's OuterPadding provides the
 	// horizontal indent so no extra padding is needed here.
-	m.pager.SetFooter(m.help.View())
+	footer := m.help.View()
+	if m.generating {
+		footer = core_theme.DefaultTheme.Info.Render(core_theme.IconSparkle+" Generating context…") + "  " + footer
+	}
+	m.pager.SetFooter(footer)
 
 	return m.pager.View()
 }
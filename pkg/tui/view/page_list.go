@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -51,34 +52,51 @@ func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 // highlight returns a styled string with occurrences of substr highlighted.
+// Matching is case-insensitive and works on whole characters, so offsets
+// stay valid when lowercasing would change a character's byte length.
 func highlight(s, substr string, baseStyle, highlightStyle lipgloss.Style) string {
-	if substr == "" || !strings.Contains(strings.ToLower(s), strings.ToLower(substr)) {
+	if substr == "" {
 		return baseStyle.Render(s)
 	}
 
-	// Case-insensitive search
-	lowerS := strings.ToLower(s)
-	lowerSubstr := strings.ToLower(substr)
-
 	var result strings.Builder
 	lastIndex := 0
-
-	for {
-		index := strings.Index(lowerS[lastIndex:], lowerSubstr)
-		if index == -1 {
-			result.WriteString(baseStyle.Render(s[lastIndex:]))
-			break
+	for i := range s {
+		if i < lastIndex {
+			continue
 		}
-
-		actualIndex := lastIndex + index
-		result.WriteString(baseStyle.Render(s[lastIndex:actualIndex]))
-		result.WriteString(highlightStyle.Render(s[actualIndex : actualIndex+len(substr)]))
-		lastIndex = actualIndex + len(substr)
+		end, ok := foldPrefixLen(s[i:], substr)
+		if !ok {
+			continue
+		}
+		result.WriteString(baseStyle.Render(s[lastIndex:i]))
+		result.WriteString(highlightStyle.Render(s[i : i+end]))
+		lastIndex = i + end
 	}
-
+	if lastIndex == 0 {
+		return baseStyle.Render(s)
+	}
+	result.WriteString(baseStyle.Render(s[lastIndex:]))
 	return result.String()
 }
 
+// foldPrefixLen reports whether s starts with prefix under Unicode case
+// folding, and the byte length of the matching part of s.
+func foldPrefixLen(s, prefix string) (int, bool) {
+	n := 0
+	for _, pr := range prefix {
+		if n >= len(s) {
+			return 0, false
+		}
+		sr, size := utf8.DecodeRuneInString(s[n:])
+		if !strings.EqualFold(string(sr), string(pr)) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
+
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
 	i, ok := item.(listItem)
 	if !ok {
@@ -0,0 +1,16 @@
+package view
+
+import "testing"
+
+func TestFoldPrefixLen(t *testing.T) {
+	// É is two bytes; the match length is measured in s, not the query.
+	if n, ok := foldPrefixLen("ÉCOLE.md", "éco"); !ok || n != 4 {
+		t.Errorf("foldPrefixLen(ÉCOLE.md, éco) = %d, %v; want 4, true", n, ok)
+	}
+	if _, ok := foldPrefixLen("ab", "abc"); ok {
+		t.Error("a query longer than the text should not match")
+	}
+	if _, ok := foldPrefixLen("日本語", "日本x"); ok {
+		t.Error("mismatched characters should not match")
+	}
+}
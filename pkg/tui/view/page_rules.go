@@ -1,6 +1,7 @@
 package view
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -72,7 +73,7 @@ func (p *rulesPage) Focus() tea.Cmd {
 	header := label + path
 
 	// Apply styling to rules content - make comments muted
-	styledContent := styleRulesContent(p.sharedState.rulesContent)
+	styledContent := styleRulesContent(p.sharedState.rulesContent, p.sharedState.lineStats)
 	content := header + "\n\n" + styledContent
 	p.viewport.SetContent(content)
 	return nil
@@ -80,8 +81,9 @@ func (p *rulesPage) Focus() tea.Cmd {
 
 func (p *rulesPage) Blur() {}
 
-// styleRulesContent applies syntax-aware styling to rules content using the parser
-func styleRulesContent(content string) string {
+// styleRulesContent applies syntax-aware styling to rules content using the parser.
+// Lines with an entry in lineStats are suffixed with what they contributed.
+func styleRulesContent(content string, lineStats map[int]ruleLineStat) string {
 	if content == "" {
 		return content
 	}
@@ -90,13 +92,54 @@ func styleRulesContent(content string) string {
 	styledLines := make([]string, len(lines))
 
 	for i, line := range lines {
+		// Annotated rules render the rule by type and the `# why:` rationale
+		// as inline text after it.
+		if rule, annotation := context.SplitRuleAnnotation(line); annotation != "" {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			styledLines[i] = indent + styleLineByType(rule, context.ParseRulesLine(rule)) +
+				styleAnnotation(annotation) + styleLineStat(lineStats, i+1)
+			continue
+		}
 		parsed := context.ParseRulesLine(line)
-		styledLines[i] = styleLineByType(line, parsed)
+		styledLines[i] = styleLineByType(line, parsed) + styleLineStat(lineStats, i+1)
 	}
 
 	return strings.Join(styledLines, "\n")
 }
 
+// styleAnnotation renders a rule's `# why:` annotation as muted inline text.
+func styleAnnotation(annotation string) string {
+	theme := core_theme.DefaultTheme
+	return theme.Muted.Render("  # why: ") + theme.Info.Render(annotation)
+}
+
+// styleLineStat renders the live contribution of a rules line as a muted
+// suffix, or "" when the line has no attribution.
+func styleLineStat(lineStats map[int]ruleLineStat, lineNum int) string {
+	stat, ok := lineStats[lineNum]
+	if !ok {
+		return ""
+	}
+	theme := core_theme.DefaultTheme
+	switch {
+	case stat.superseded:
+		return theme.Muted.Render("  · ") + theme.Warning.Render("superseded")
+	case stat.files > 0:
+		return theme.Muted.Render(fmt.Sprintf("  · %d %s, ~%s tokens",
+			stat.files, pluralFiles(stat.files), context.FormatTokenCount(stat.tokens)))
+	case stat.excluded > 0:
+		return theme.Muted.Render(fmt.Sprintf("  · -%d %s", stat.excluded, pluralFiles(stat.excluded)))
+	}
+	return theme.Muted.Render("  · 0 files")
+}
+
+func pluralFiles(n int) string {
+	if n == 1 {
+		return "file"
+	}
+	return "files"
+}
+
 // styleLineByType applies appropriate styling based on line type
 func styleLineByType(line string, parsed context.ParsedLine) string {
 	theme := core_theme.DefaultTheme
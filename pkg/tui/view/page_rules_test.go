@@ -0,0 +1,40 @@
+package view
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStyleRulesContentLineStats(t *testing.T) {
+	content := "# hot\n*.go\n!*_test.go\ndocs/**\ndocs/*.md"
+	stats := map[int]ruleLineStat{
+		2: {files: 12, tokens: 3400},
+		3: {excluded: 1},
+		4: {superseded: true},
+		5: {files: 1, tokens: 20},
+	}
+	lines := strings.Split(styleRulesContent(content, stats), "\n")
+	want := map[int]string{
+		1: "",
+		2: "12 files, ~3.4k tokens",
+		3: "-1 file",
+		4: "superseded",
+		5: "1 file, ~20 tokens",
+	}
+	for lineNum, suffix := range want {
+		got := lines[lineNum-1]
+		if suffix == "" {
+			if strings.Contains(got, "·") {
+				t.Errorf("line %d should not be annotated: %q", lineNum, got)
+			}
+			continue
+		}
+		if !strings.Contains(got, suffix) {
+			t.Errorf("line %d = %q, want suffix %q", lineNum, got, suffix)
+		}
+	}
+
+	if got := styleRulesContent(content, nil); strings.Contains(got, "·") {
+		t.Errorf("no stats should leave lines unannotated: %q", got)
+	}
+}
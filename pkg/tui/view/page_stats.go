@@ -32,13 +32,21 @@ type languageItem struct {
 }
 
 func (i languageItem) Title() string       { return i.Name }
-func (i languageItem) Description() string { return "language" }
 func (i languageItem) FilterValue() string { return i.Name }
 
+// Description shows the detected language (e.g. "go" for .go) when known.
+func (i languageItem) Description() string {
+	if i.Language != "" {
+		return i.Language
+	}
+	return "language"
+}
+
 type fileItem struct {
 	context.FileStats
-	contextType     string // "hot", "cold", "both"
-	showContextType bool   // only show indicator if cold context exists
+	contextType     string             // "hot", "cold", "both"
+	showContextType bool               // only show indicator if cold context exists
+	level           context.TokenLevel // size against the project's token thresholds
 }
 
 func (i fileItem) Title() string       { return i.Path }
@@ -72,7 +80,7 @@ func (d languageDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	}
 
 	var parts []string
-	name := fmt.Sprintf("%-12s", i.Name)
+	name := context.PadWidth(i.Name, 12)
 	percentage := fmt.Sprintf("%5.1f%%", i.Percentage)
 	details := fmt.Sprintf(
 		"  (~%s tokens, %d files)",
@@ -126,16 +134,17 @@ func (d fileDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	if i.showContextType {
 		pathWidth = 41 // Make room for indicator
 	}
-	if len(displayPath) > pathWidth {
-		displayPath = "..." + displayPath[len(displayPath)-(pathWidth-3):]
-	}
+	displayPath = context.PadWidth(context.TruncateWidthLeft(displayPath, pathWidth, "..."), pathWidth)
 
 	var tokenStyle lipgloss.Style
-	if i.Tokens > 10000 {
+	switch i.level {
+	case context.TokenLevelError:
 		tokenStyle = theme.Error
-	} else if i.Tokens > 5000 {
+	case context.TokenLevelWarn:
 		tokenStyle = theme.Warning
-	} else {
+	case context.TokenLevelNotice:
+		tokenStyle = theme.Highlight
+	default:
 		tokenStyle = theme.Info
 	}
 
@@ -148,15 +157,15 @@ func (d fileDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	var line string
 	if index == m.Index() {
 		if indicator != "" {
-			line = fmt.Sprintf("%s %s%-*s %s", core_theme.IconArrowRightBold, theme.Success.Render(indicator), pathWidth, displayPath, tokenStyle.Render(details))
+			line = fmt.Sprintf("%s %s%s %s", core_theme.IconArrowRightBold, theme.Success.Render(indicator), displayPath, tokenStyle.Render(details))
 		} else {
-			line = fmt.Sprintf("%s %-*s %s", core_theme.IconArrowRightBold, pathWidth, displayPath, tokenStyle.Render(details))
+			line = fmt.Sprintf("%s %s %s", core_theme.IconArrowRightBold, displayPath, tokenStyle.Render(details))
 		}
 	} else {
 		if indicator != "" {
-			line = fmt.Sprintf("  %s%-*s %s", theme.Success.Render(indicator), pathWidth, displayPath, tokenStyle.Render(details))
+			line = fmt.Sprintf("  %s%s %s", theme.Success.Render(indicator), displayPath, tokenStyle.Render(details))
 		} else {
-			line = fmt.Sprintf("  %-*s %s", pathWidth, displayPath, tokenStyle.Render(details))
+			line = fmt.Sprintf("  %s %s", displayPath, tokenStyle.Render(details))
 		}
 	}
 	fmt.Fprint(w, line)
@@ -234,7 +243,7 @@ func (p *statsPage) Focus() tea.Cmd {
 		aggStats.TotalSize += hot.TotalSize
 		for name, lang := range hot.Languages {
 			if _, ok := aggStats.Languages[name]; !ok {
-				aggStats.Languages[name] = &context.LanguageStats{Name: name}
+				aggStats.Languages[name] = &context.LanguageStats{Name: name, Language: lang.Language}
 				langContext[name] = make(map[string]bool)
 			}
 			aggStats.Languages[name].FileCount += lang.FileCount
@@ -257,7 +266,7 @@ func (p *statsPage) Focus() tea.Cmd {
 		aggStats.TotalSize += cold.TotalSize
 		for name, lang := range cold.Languages {
 			if _, ok := aggStats.Languages[name]; !ok {
-				aggStats.Languages[name] = &context.LanguageStats{Name: name}
+				aggStats.Languages[name] = &context.LanguageStats{Name: name, Language: lang.Language}
 				langContext[name] = make(map[string]bool)
 			}
 			aggStats.Languages[name].FileCount += lang.FileCount
@@ -350,6 +359,7 @@ func (p *statsPage) Focus() tea.Cmd {
 			FileStats:       file,
 			contextType:     contextType,
 			showContextType: hasColdContext,
+			level:           p.sharedState.thresholds.Level(file.Tokens),
 		})
 	}
 	p.fileList.SetItems(fileItems)
@@ -110,7 +110,12 @@ func (p *suggestionsPage) refreshSuggestionsCmd() tea.Cmd {
 		}
 
 		// Get included files map to filter out already-included results.
-		classified, err := p.sharedState.manager.ClassifyAllProjectFiles(false)
+		var classified map[string]context.NodeStatus
+		if session := p.sharedState.session; session != nil {
+			classified, err = session.Classify(false)
+		} else {
+			classified, err = p.sharedState.manager.ClassifyAllProjectFiles(false)
+		}
 		if err != nil {
 			return suggestionsRefreshedMsg{err: err}
 		}
@@ -245,11 +250,8 @@ func (p *suggestionsPage) View() string {
 
 		score := lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("%.2f", s.Score))
 
-		// Snippet: first 60 chars of content, single line
-		snippet := strings.ReplaceAll(s.Content, "\n", " ")
-		if len(snippet) > 60 {
-			snippet = snippet[:60] + "…"
-		}
+		// Snippet: first 60 cells of content, single line
+		snippet := context.TruncateWidth(strings.ReplaceAll(s.Content, "\n", " "), 61, "…")
 		snippet = lipgloss.NewStyle().Faint(true).Render(snippet)
 
 		if i == p.cursor {
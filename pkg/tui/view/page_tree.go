@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -51,6 +52,9 @@ type treePage struct {
 
 	// Cursor restoration state
 	pathToRestore string
+
+	// stateDir is the workDir whose persisted tree state has been applied
+	stateDir string
 }
 
 // --- Messages ---
@@ -109,6 +113,7 @@ func (p *treePage) Keys() interface{} {
 }
 
 func (p *treePage) Init() tea.Cmd {
+	p.restoreState()
 	return p.loadTreeCmd()
 }
 
@@ -125,12 +130,14 @@ func (p *treePage) Focus() tea.Cmd {
 	// tree is not the start page this lazy load is the only initial load;
 	// without it the tab stays on "Loading tree..." forever.
 	if p.tree == nil {
+		p.restoreState()
 		return p.loadTreeCmd()
 	}
 	return nil
 }
 
 func (p *treePage) Blur() {
+	p.persistState()
 	p.isSearching = false
 	p.searchQuery = ""
 	p.searchResults = nil
@@ -147,8 +154,13 @@ func (p *treePage) SetSize(width, height int) {
 
 func (p *treePage) loadTreeCmd() tea.Cmd {
 	return func() tea.Msg {
-		manager := p.sharedState.manager
-		projectTree, err := tree.AnalyzeProjectTree(manager, p.showGitIgnored)
+		var projectTree *tree.FileNode
+		var err error
+		if session := p.sharedState.session; session != nil {
+			projectTree, err = tree.AnalyzeSessionTree(session, p.showGitIgnored)
+		} else {
+			projectTree, err = tree.AnalyzeProjectTree(p.sharedState.manager, p.showGitIgnored)
+		}
 		return treeLoadedMsg{tree: projectTree, err: err}
 	}
 }
@@ -334,6 +346,7 @@ func (p *treePage) getAliasForRule(node *tree.FileNode) (string, bool) {
 func (p *treePage) Update(msg tea.Msg) (Page, tea.Cmd) {
 	switch msg := msg.(type) {
 	case stateRefreshedMsg:
+		p.restoreState()
 		return p, p.loadTreeCmd()
 	case treeLoadedMsg:
 		p.statusMessage = ""
@@ -461,14 +474,14 @@ func (p *treePage) Update(msg tea.Msg) (Page, tea.Cmd) {
 				return p, nil
 			case "backspace":
 				// Remove last character from search query
-				if len(p.searchQuery) > 0 {
-					p.searchQuery = p.searchQuery[:len(p.searchQuery)-1]
+				if _, size := utf8.DecodeLastRuneInString(p.searchQuery); size > 0 {
+					p.searchQuery = p.searchQuery[:len(p.searchQuery)-size]
 				}
 				return p, nil
 			default:
-				// Add character to search query
-				if len(msg.String()) == 1 {
-					p.searchQuery += msg.String()
+				// Add typed characters (including multi-byte ones) to search query
+				if (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) && !msg.Alt {
+					p.searchQuery += string(msg.Runes)
 				}
 				return p, nil
 			}
@@ -953,21 +966,97 @@ func (p *treePage) renderNode(index int) string {
 	tokenStr := ""
 	if node.TokenCount > 0 {
 		var tokenStyle lipgloss.Style
-		if node.TokenCount >= 100000 {
-			tokenStyle = core_theme.DefaultTheme.Error // Red for 100K+
-		} else if node.TokenCount >= 50000 {
-			tokenStyle = core_theme.DefaultTheme.Warning // Orange for 50K+
-		} else if node.TokenCount >= 10000 {
-			tokenStyle = core_theme.DefaultTheme.Highlight // Yellow for 10K+
-		} else {
-			tokenStyle = core_theme.DefaultTheme.Muted // Dim gray for < 10K
+		switch p.sharedState.thresholds.Level(node.TokenCount) {
+		case context.TokenLevelError:
+			tokenStyle = core_theme.DefaultTheme.Error // Red
+		case context.TokenLevelWarn:
+			tokenStyle = core_theme.DefaultTheme.Warning // Orange
+		case context.TokenLevelNotice:
+			tokenStyle = core_theme.DefaultTheme.Highlight // Yellow
+		default:
+			tokenStyle = core_theme.DefaultTheme.Muted // Dim gray
 		}
 		tokenStr = tokenStyle.Render(fmt.Sprintf(" (%s)", context.FormatTokenCount(node.TokenCount)))
 	}
 
+	// Pinned note, shown after everything else
+	noteText := p.noteFor(node)
+
+	// Truncate the name, not the status and token suffix, when the line
+	// would overflow the page; the note gets whatever room is left
+	if p.width > 0 {
+		prefix := cursor + indent + icon + " "
+		suffix := statusSymbol + dangerSymbol + tokenStr
+		avail := p.width - lipgloss.Width(prefix) - lipgloss.Width(suffix)
+		if avail > 0 {
+			name = context.TruncateWidth(name, avail, "…")
+		}
+		if noteAvail := avail - lipgloss.Width(name) - 4; noteText != "" && noteAvail > 0 {
+			noteText = context.TruncateWidth(noteText, noteAvail, "…")
+		} else {
+			noteText = ""
+		}
+	}
+	noteStr := ""
+	if noteText != "" {
+		noteStr = core_theme.DefaultTheme.Muted.Render("  # " + noteText)
+	}
+
 	// Combine all parts (no expansion indicator - folder icon shows open/closed state)
 	line := fmt.Sprintf("%s%s%s %s%s%s%s", cursor, indent, icon, name, statusSymbol, dangerSymbol, tokenStr)
-	return style.Render(line)
+	return style.Render(line) + noteStr
+}
+
+// noteFor returns the first line of the note pinned to node, or "".
+func (p *treePage) noteFor(node *tree.FileNode) string {
+	if len(p.sharedState.notes) == 0 {
+		return ""
+	}
+	rel, err := filepath.Rel(p.sharedState.workDir, node.Path)
+	if err != nil {
+		return ""
+	}
+	note, ok := p.sharedState.notes[filepath.ToSlash(rel)]
+	if !ok {
+		return ""
+	}
+	text, _, _ := strings.Cut(note.Text, "\n")
+	return text
+}
+
+// restoreState applies the tree state saved for the current workDir, once per
+// workDir, so reopening `cx view` resumes with the same folders expanded,
+// gitignored visibility and cursor.
+func (p *treePage) restoreState() {
+	workDir := p.sharedState.workDir
+	if workDir == "" || workDir == p.stateDir {
+		return
+	}
+	p.stateDir = workDir
+	p.expandedPaths = make(map[string]bool)
+	saved, ok := loadTreeState(workDir)
+	if !ok {
+		return
+	}
+	for _, path := range saved.Expanded {
+		p.expandedPaths[fromStatePath(workDir, path)] = true
+	}
+	p.showGitIgnored = saved.ShowGitIgnored
+	if saved.Cursor != "" {
+		p.pathToRestore = fromStatePath(workDir, saved.Cursor)
+	}
+}
+
+// persistState saves the tree state for the workDir it was restored for.
+func (p *treePage) persistState() {
+	if p.tree == nil || p.stateDir == "" {
+		return
+	}
+	var cursorPath string
+	if p.cursor >= 0 && p.cursor < len(p.visibleNodes) {
+		cursorPath = p.visibleNodes[p.cursor].node.Path
+	}
+	saveTreeState(p.stateDir, snapshotTreeState(p.stateDir, p.expandedPaths, cursorPath, p.showGitIgnored))
 }
 
 // restoreCursorPosition finds the new index for the cursor after a refresh.
@@ -0,0 +1,84 @@
+package view
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/state"
+)
+
+// treeStateKey is the per-project state key (stored under .grove/state) that
+// remembers the tree page between `cx view` sessions.
+const treeStateKey = "cx.view.tree"
+
+// persistedTreeState is the tree page state restored when `cx view` reopens
+// in the same project. Paths are relative to the project root so the state
+// survives the project being moved.
+type persistedTreeState struct {
+	Expanded       []string `json:"expanded,omitempty"`
+	Cursor         string   `json:"cursor,omitempty"`
+	ShowGitIgnored bool     `json:"show_gitignored,omitempty"`
+}
+
+// statePersister is implemented by pages that save their state per project
+// when they lose focus and when the view exits or switches workspace.
+type statePersister interface {
+	persistState()
+}
+
+// loadTreeState reads the saved tree state for workDir.
+func loadTreeState(workDir string) (persistedTreeState, bool) {
+	var s persistedTreeState
+	raw, err := state.GetString(workDir, treeStateKey)
+	if err != nil || raw == "" {
+		return s, false
+	}
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return s, false
+	}
+	return s, true
+}
+
+// saveTreeState writes s as the tree state for workDir. Persistence is
+// best-effort; a failure only means the next session starts fresh.
+func saveTreeState(workDir string, s persistedTreeState) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	_ = state.Set(workDir, treeStateKey, string(data))
+}
+
+// toStatePath makes path relative to workDir for storage; paths outside the
+// project are kept absolute.
+func toStatePath(workDir, path string) string {
+	if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// fromStatePath reverses toStatePath.
+func fromStatePath(workDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workDir, filepath.FromSlash(path))
+}
+
+// snapshotTreeState captures the parts of the tree page worth restoring.
+func snapshotTreeState(workDir string, expanded map[string]bool, cursorPath string, showGitIgnored bool) persistedTreeState {
+	s := persistedTreeState{ShowGitIgnored: showGitIgnored}
+	for path, open := range expanded {
+		if open {
+			s.Expanded = append(s.Expanded, toStatePath(workDir, path))
+		}
+	}
+	sort.Strings(s.Expanded)
+	if cursorPath != "" {
+		s.Cursor = toStatePath(workDir, cursorPath)
+	}
+	return s
+}
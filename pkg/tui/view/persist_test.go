@@ -0,0 +1,34 @@
+package view
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotTreeStateRoundTrip(t *testing.T) {
+	workDir := filepath.FromSlash("/work/project")
+	outside := filepath.FromSlash("/elsewhere/lib")
+	expanded := map[string]bool{
+		workDir:                                  true,
+		filepath.Join(workDir, "pkg", "context"): true,
+		filepath.Join(workDir, "cmd"):            false,
+		outside:                                  true,
+	}
+	cursor := filepath.Join(workDir, "pkg", "context", "manager.go")
+
+	s := snapshotTreeState(workDir, expanded, cursor, true)
+	if want := []string{".", outside, "pkg/context"}; len(s.Expanded) != len(want) ||
+		s.Expanded[0] != want[0] || s.Expanded[1] != want[1] || s.Expanded[2] != want[2] {
+		t.Errorf("Expanded = %v, want %v", s.Expanded, want)
+	}
+	if s.Cursor != "pkg/context/manager.go" || !s.ShowGitIgnored {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+
+	if got := fromStatePath(workDir, s.Cursor); got != cursor {
+		t.Errorf("fromStatePath(%q) = %q, want %q", s.Cursor, got, cursor)
+	}
+	if got := fromStatePath(workDir, outside); got != outside {
+		t.Errorf("absolute paths should be kept, got %q", got)
+	}
+}
@@ -17,8 +17,9 @@ import (
 // sharedState holds all the data that is shared across different pages of the TUI.
 type sharedState struct {
 	workDir           string
-	rulesFileOverride string           // Instance-level override for rules file (absolute path)
-	manager           *context.Manager // Shared manager instance for all pages
+	rulesFileOverride string                     // Instance-level override for rules file (absolute path)
+	manager           *context.Manager           // Shared manager instance for all pages
+	session           *context.ResolutionSession // Resolution behind hotFiles/coldFiles, shared with the tree page
 	loading           bool
 	err               error
 	hotFiles          []string
@@ -27,12 +28,24 @@ type sharedState struct {
 	rulesPath         string // Path to the active rules file
 	hotStats          *context.ContextStats
 	coldStats         *context.ContextStats
+	thresholds        context.TokenThresholds  // Token counts at which badges change color
+	notes             map[string]*context.Note // Pinned notes, keyed by workspace-relative path
 	projects          []*workspace.WorkspaceNode
 	projectProvider   *workspace.Provider
 	// Parsed rules
 	hotRules  []string
 	coldRules []string
 	viewPaths []string
+	// Per-line attribution for the rules panel, keyed by 1-based line number
+	lineStats map[int]ruleLineStat
+}
+
+// ruleLineStat summarizes what a single rules line contributed to the context.
+type ruleLineStat struct {
+	files      int  // files attributed to this line
+	tokens     int  // tokens of the attributed files
+	excluded   int  // files removed by this line (exclusion rules)
+	superseded bool // every match was claimed by a later line
 }
 
 // stateRefreshedMsg is sent when the sharedState has been updated.
@@ -49,6 +62,10 @@ func refreshSharedStateCmd(workDir, rulesFileOverride string, seq uint64) tea.Cm
 	return func() tea.Msg {
 		mgr := context.NewManagerWithOverride(workDir, rulesFileOverride)
 		newState := sharedState{workDir: workDir, rulesFileOverride: rulesFileOverride, manager: mgr, loading: false}
+		newState.thresholds = mgr.TokenThresholds()
+		if notes, err := mgr.LoadNotes(); err == nil {
+			newState.notes = notes.Files
+		}
 
 		// Load rules content
 		rulesBytes, rulesPath, err := mgr.LoadRulesContent()
@@ -71,20 +88,20 @@ func refreshSharedStateCmd(workDir, rulesFileOverride string, seq uint64) tea.Cm
 		parseRules(&newState, string(rulesBytes), mgr)
 
 		// Resolve hot and cold files
-		hotFiles, err := mgr.ResolveFilesFromRules()
+		session, err := mgr.NewResolutionSession()
 		if err != nil {
 			newState.err = err
 			return stateRefreshedMsg{state: newState, seq: seq}
 		}
+		hotFiles, coldFiles := session.Hot, session.Cold
+		newState.session = session
 		newState.hotFiles = hotFiles
-
-		coldFiles, err := mgr.ResolveColdContextFiles()
-		if err != nil {
-			newState.err = err
-			return stateRefreshedMsg{state: newState, seq: seq}
-		}
 		newState.coldFiles = coldFiles
 
+		// Attribute files to individual rules lines for the rules panel.
+		// Failures here only cost the annotations, not the refresh.
+		newState.lineStats = computeRuleLineStats(mgr, newState.rulesContent)
+
 		// Get stats for both
 		if len(hotFiles) > 0 {
 			hotStats, err := mgr.GetStats("hot", hotFiles, 10)
@@ -121,6 +138,51 @@ func refreshSharedStateCmd(workDir, rulesFileOverride string, seq uint64) tea.Cm
 	}
 }
 
+// computeRuleLineStats runs the attribution engine over rulesContent and
+// returns per-line file and token counts, the same numbers `cx stats
+// --per-line` reports.
+func computeRuleLineStats(mgr *context.Manager, rulesContent string) map[int]ruleLineStat {
+	if rulesContent == "" {
+		return nil
+	}
+	attribution, _, exclusions, filteredMatches, _, err := mgr.ResolveFilesWithAttribution(rulesContent)
+	if err != nil {
+		return nil
+	}
+
+	statsProvider := context.GetStatsProvider()
+	stats := make(map[int]ruleLineStat)
+	for lineNum, files := range attribution {
+		stat := ruleLineStat{files: len(files)}
+		for _, file := range files {
+			if info, err := statsProvider.GetFileStats(file); err == nil {
+				stat.tokens += info.Tokens
+			}
+		}
+		stats[lineNum] = stat
+	}
+	for lineNum, files := range exclusions {
+		stat := stats[lineNum]
+		stat.excluded = len(files)
+		stats[lineNum] = stat
+	}
+	for lineNum, infos := range filteredMatches {
+		if stat, ok := stats[lineNum]; ok && stat.files > 0 {
+			continue
+		}
+		// Skip self-references (when a ruleset import's rules supersede each other)
+		for _, info := range infos {
+			if info.WinningLineNum != lineNum {
+				stat := stats[lineNum]
+				stat.superseded = true
+				stats[lineNum] = stat
+				break
+			}
+		}
+	}
+	return stats
+}
+
 // displayPathInfo holds information about how to display a file path
 type displayPathInfo struct {
 	ecosystem string // Ecosystem context (e.g., "grove-ecosystem/")
@@ -224,9 +286,9 @@ func parseRules(state *sharedState, content string, mgr *context.Manager) {
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		// Check for separator
-		if trimmed == "---" {
-			inColdSection = true
+		// Check for a section separator; named sections may be hot
+		if name, ok := context.ParseSectionSeparator(trimmed); ok {
+			inColdSection = !mgr.SectionIsHot(name)
 			continue
 		}
 